@@ -1,13 +1,16 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"pos-final/internal/config"
+	"pos-final/internal/domain"
 	"pos-final/internal/handler"
 	"pos-final/internal/middleware"
 	"pos-final/internal/repository"
 	"pos-final/internal/service"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -36,35 +39,159 @@ func main() {
 	sparePartRepo := repository.NewSparePartRepository(db.GetDB())
 	workOrderPartRepo := repository.NewWorkOrderPartRepository(db.GetDB())
 	notificationRepo := repository.NewNotificationRepository(db.GetDB())
+	warrantyRepo := repository.NewWarrantyRepository(db.GetDB())
+	warrantyClaimRepo := repository.NewWarrantyClaimRepository(db.GetDB())
+	supplierRepo := repository.NewSupplierRepository(db.GetDB())
+	purchaseOrderRepo := repository.NewPurchaseOrderRepository(db.GetDB())
+	goodsReceiptRepo := repository.NewGoodsReceiptRepository(db.GetDB())
+	stockCostLayerRepo := repository.NewStockCostLayerRepository(db.GetDB())
+	payableRepo := repository.NewPayableRepository(db.GetDB())
+	payablePaymentRepo := repository.NewPayablePaymentRepository(db.GetDB())
+	consignmentRepo := repository.NewConsignmentRepository(db.GetDB())
+	vehicleDocumentRepo := repository.NewVehicleDocumentRepository(db.GetDB())
+	mechanicRepo := repository.NewMechanicRepository(db.GetDB())
+	workOrderTaskRepo := repository.NewWorkOrderTaskRepository(db.GetDB())
+	laborRepo := repository.NewLaborRepository(db.GetDB())
+	partRequestRepo := repository.NewPartRequestRepository(db.GetDB())
+	customerVehicleRepo := repository.NewCustomerVehicleRepository(db.GetDB())
+	serviceInvoiceRepo := repository.NewServiceInvoiceRepository(db.GetDB())
+	serviceInvoicePaymentRepo := repository.NewServiceInvoicePaymentRepository(db.GetDB())
+	workOrderAttachmentRepo := repository.NewWorkOrderAttachmentRepository(db.GetDB())
+	workshopBayRepo := repository.NewWorkshopBayRepository(db.GetDB())
+	workOrderScheduleRepo := repository.NewWorkOrderScheduleRepository(db.GetDB())
+	vehicleCostRepo := repository.NewVehicleCostRepository(db.GetDB())
+	workOrderSubletRepo := repository.NewWorkOrderSubletRepository(db.GetDB())
+	stockCountRepo := repository.NewStockCountRepository(db.GetDB())
+	stockLocationRepo := repository.NewStockLocationRepository(db.GetDB())
+	fitmentRepo := repository.NewSparePartFitmentRepository(db.GetDB())
+	serviceKitRepo := repository.NewServiceKitRepository(db.GetDB())
+	stockTransferRepo := repository.NewStockTransferRepository(db.GetDB())
+	replenishmentRepo := repository.NewReplenishmentRepository(db.GetDB())
+	sparePartBatchRepo := repository.NewSparePartBatchRepository(db.GetDB())
+	sparePartSerialRepo := repository.NewSparePartSerialRepository(db.GetDB())
+
+	costingMethod := domain.CostingMethod(cfg.Inventory.CostingMethod)
+	var requiredDocuments []domain.VehicleDocumentType
+	for _, documentType := range cfg.Documents.RequiredForSale {
+		requiredDocuments = append(requiredDocuments, domain.VehicleDocumentType(documentType))
+	}
+
+	// Default shift for mechanics without their own working hours
+	var defaultWorkingHours []*domain.MechanicWorkingHours
+	for _, day := range cfg.Workshop.WorkDays {
+		defaultWorkingHours = append(defaultWorkingHours, &domain.MechanicWorkingHours{
+			DayOfWeek: day,
+			StartTime: cfg.Workshop.OpenTime,
+			EndTime:   cfg.Workshop.CloseTime,
+		})
+	}
+
+	replenishmentPolicy := domain.ReplenishmentPolicy{
+		UsageWindowDays:     cfg.Inventory.UsageWindowDays,
+		SafetyStockDays:     cfg.Inventory.SafetyStockDays,
+		CoverDays:           cfg.Inventory.ReorderCoverDays,
+		DefaultLeadTimeDays: cfg.Inventory.DefaultLeadTimeDays,
+	}
+
+	barcodeSettings := domain.BarcodeSettings{
+		Symbology:   domain.BarcodeSymbology(cfg.Labels.BarcodeSymbology),
+		EAN13Prefix: cfg.Labels.EAN13Prefix,
+		AutoAssign:  cfg.Labels.AutoAssignBarcode,
+	}
 
 	// Initialize services
 	authService := service.NewAuthService(userRepo, cfg.JWT.Secret, cfg.GetJWTDuration())
 	userService := service.NewUserService(userRepo)
 	fileService := service.NewFileService("./static/uploads")
-	customerService := service.NewCustomerService(customerRepo)
-	vehicleService := service.NewVehicleService(vehicleRepo)
-	sparePartService := service.NewSparePartService(sparePartRepo)
+	customerService := service.NewCustomerService(customerRepo, customerVehicleRepo)
+	vehicleService := service.NewVehicleService(vehicleRepo, vehicleCostRepo)
+	sparePartService := service.NewSparePartService(sparePartRepo, stockCostLayerRepo, stockLocationRepo, costingMethod, barcodeSettings)
 	notificationService := service.NewNotificationService(notificationRepo, userRepo)
-	purchaseService := service.NewPurchaseService(purchaseRepo, vehicleRepo, workOrderRepo, userRepo)
-	salesService := service.NewSalesService(salesRepo, vehicleRepo)
-	workOrderService := service.NewWorkOrderService(workOrderRepo, vehicleRepo, sparePartRepo, workOrderPartRepo, userRepo)
-	invoiceService := service.NewInvoiceService(salesService, purchaseService, workOrderService)
+	payableService := service.NewPayableService(payableRepo, payablePaymentRepo, notificationService)
+	mechanicService := service.NewMechanicService(mechanicRepo, userRepo, domain.AssignmentStrategy(cfg.Workshop.AssignmentStrategy), defaultWorkingHours)
+	purchaseService := service.NewPurchaseService(purchaseRepo, vehicleRepo, mechanicService, payableService)
+	consignmentService := service.NewConsignmentService(consignmentRepo, customerRepo, vehicleService, payableService)
+	vehicleDocumentService := service.NewVehicleDocumentService(vehicleDocumentRepo, vehicleRepo, salesRepo, requiredDocuments, cfg.Documents.BlockMissing)
+	salesService := service.NewSalesService(salesRepo, vehicleRepo, warrantyRepo, consignmentService, vehicleDocumentService)
+	workOrderService := service.NewWorkOrderService(workOrderRepo, vehicleRepo, customerRepo, customerVehicleRepo, sparePartRepo, workOrderPartRepo, workOrderTaskRepo, laborRepo, userRepo, stockCostLayerRepo, workOrderSubletRepo, stockLocationRepo, fitmentRepo, serviceKitRepo, mechanicService, vehicleService, costingMethod)
+	laborService := service.NewLaborService(laborRepo, workOrderRepo, workOrderTaskRepo, userRepo, float64(cfg.Workshop.DefaultHourlyRate))
+	partRequestService := service.NewPartRequestService(partRequestRepo, workOrderRepo, sparePartRepo, workOrderService, notificationService)
+	serviceInvoiceService := service.NewServiceInvoiceService(serviceInvoiceRepo, serviceInvoicePaymentRepo, workOrderRepo, workOrderPartRepo)
+	workOrderAttachmentService := service.NewWorkOrderAttachmentService(workOrderAttachmentRepo, workOrderRepo, workOrderTaskRepo, fileService)
+	workOrderSubletService := service.NewWorkOrderSubletService(workOrderSubletRepo, workOrderRepo, supplierRepo, payableService, fileService)
+	stockCountService := service.NewStockCountService(stockCountRepo, stockLocationRepo, costingMethod)
+	stockLocationService := service.NewStockLocationService(stockLocationRepo, sparePartRepo)
+	stockTransferService := service.NewStockTransferService(stockTransferRepo, stockLocationRepo, sparePartRepo)
+	scheduleService := service.NewScheduleService(workshopBayRepo, workOrderScheduleRepo, workOrderRepo, workOrderTaskRepo, mechanicRepo, mechanicService)
+	invoiceService := service.NewInvoiceService(salesService, purchaseService, workOrderService, consignmentService, workOrderAttachmentService, fileService)
 	reportService := service.NewReportService(salesRepo, purchaseRepo, workOrderRepo, vehicleRepo, sparePartRepo, customerRepo, userRepo)
+	warrantyService := service.NewWarrantyService(warrantyRepo, warrantyClaimRepo, salesRepo, workOrderService)
+	purchaseOrderService := service.NewPurchaseOrderService(purchaseOrderRepo, goodsReceiptRepo, supplierRepo, sparePartRepo, stockLocationRepo, costingMethod)
+	replenishmentService := service.NewReplenishmentService(replenishmentRepo, supplierRepo, userRepo, purchaseOrderService, notificationService, replenishmentPolicy)
+	labelService := service.NewLabelService(sparePartRepo, vehicleRepo, cfg.Labels.VehicleURL)
+	fitmentService := service.NewFitmentService(fitmentRepo, sparePartRepo, workOrderRepo, vehicleRepo, customerVehicleRepo)
+	serviceKitService := service.NewServiceKitService(serviceKitRepo, sparePartRepo)
+	sparePartTrackingService := service.NewSparePartTrackingService(sparePartRepo, sparePartBatchRepo, sparePartSerialRepo, notificationService, cfg.Inventory.ExpiryAlertDays)
 
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(authService)
 	adminHandler := handler.NewAdminHandler(userService)
-	fileHandler := handler.NewFileHandler(fileService, vehicleService, salesService, purchaseService)
+	fileHandler := handler.NewFileHandler(fileService, vehicleService, salesService, purchaseService, payableService, vehicleDocumentService)
 	customerHandler := handler.NewCustomerHandler(customerService)
 	vehicleHandler := handler.NewVehicleHandler(vehicleService)
 	sparePartHandler := handler.NewSparePartHandler(sparePartService)
-	dashboardHandler := handler.NewDashboardHandler(customerService, vehicleService, sparePartService, salesService, purchaseService, workOrderService)
+	dashboardHandler := handler.NewDashboardHandler(customerService, vehicleService, sparePartService, salesService, purchaseService, workOrderService, sparePartTrackingService)
 	purchaseHandler := handler.NewPurchaseHandler(purchaseService)
 	salesHandler := handler.NewSalesHandler(salesService)
 	workOrderHandler := handler.NewWorkOrderHandler(workOrderService)
 	pdfHandler := handler.NewPDFHandler(invoiceService)
 	notificationHandler := handler.NewNotificationHandler(notificationService)
 	reportHandler := handler.NewReportHandler(reportService)
+	warrantyHandler := handler.NewWarrantyHandler(warrantyService)
+	purchaseOrderHandler := handler.NewPurchaseOrderHandler(purchaseOrderService)
+	payableHandler := handler.NewPayableHandler(payableService)
+	consignmentHandler := handler.NewConsignmentHandler(consignmentService)
+	vehicleDocumentHandler := handler.NewVehicleDocumentHandler(vehicleDocumentService)
+	mechanicHandler := handler.NewMechanicHandler(mechanicService)
+	laborHandler := handler.NewLaborHandler(laborService)
+	partRequestHandler := handler.NewPartRequestHandler(partRequestService)
+	serviceInvoiceHandler := handler.NewServiceInvoiceHandler(serviceInvoiceService)
+	workOrderAttachmentHandler := handler.NewWorkOrderAttachmentHandler(workOrderAttachmentService, fileService)
+	scheduleHandler := handler.NewScheduleHandler(scheduleService)
+	workOrderSubletHandler := handler.NewWorkOrderSubletHandler(workOrderSubletService, fileService)
+	stockCountHandler := handler.NewStockCountHandler(stockCountService)
+	stockLocationHandler := handler.NewStockLocationHandler(stockLocationService)
+	stockTransferHandler := handler.NewStockTransferHandler(stockTransferService)
+	replenishmentHandler := handler.NewReplenishmentHandler(replenishmentService)
+	labelHandler := handler.NewLabelHandler(labelService)
+	fitmentHandler := handler.NewFitmentHandler(fitmentService)
+	serviceKitHandler := handler.NewServiceKitHandler(serviceKitService)
+	sparePartTrackingHandler := handler.NewSparePartTrackingHandler(sparePartTrackingService)
+
+	// Draft purchase orders from reorder suggestions on a schedule
+	if cfg.Inventory.ReorderIntervalHours > 0 {
+		go service.StartReorderScheduler(context.Background(), replenishmentService, time.Duration(cfg.Inventory.ReorderIntervalHours)*time.Hour)
+	}
+
+	// Keep work order completion estimates current as work overruns its slots
+	if cfg.Workshop.EstimateMinutes > 0 {
+		go service.StartEstimateScheduler(context.Background(), scheduleService, time.Duration(cfg.Workshop.EstimateMinutes)*time.Minute)
+	}
+
+	// Notify overdue payables on a schedule
+	if cfg.Payables.OverdueCheckHours > 0 {
+		go service.StartOverduePayableScheduler(context.Background(), payableService, time.Duration(cfg.Payables.OverdueCheckHours)*time.Hour)
+	}
+
+	// Expire warranties past their end date on a schedule
+	if cfg.Warranty.ExpiryCheckHours > 0 {
+		go service.StartWarrantyExpiryScheduler(context.Background(), warrantyService, time.Duration(cfg.Warranty.ExpiryCheckHours)*time.Hour)
+	}
+
+	// Alert on expired and expiring batches on a schedule
+	if cfg.Inventory.ExpiryAlertHours > 0 {
+		go service.StartExpiryAlertScheduler(context.Background(), sparePartTrackingService, time.Duration(cfg.Inventory.ExpiryAlertHours)*time.Hour)
+	}
 
 	// Initialize Gin router
 	router := gin.New()
@@ -75,7 +202,7 @@ func main() {
 	router.Use(middleware.CORS())
 
 	// Setup routes
-	setupRoutes(router, authHandler, adminHandler, fileHandler, customerHandler, vehicleHandler, sparePartHandler, dashboardHandler, purchaseHandler, salesHandler, workOrderHandler, pdfHandler, notificationHandler, reportHandler, cfg)
+	setupRoutes(router, authHandler, adminHandler, fileHandler, customerHandler, vehicleHandler, sparePartHandler, dashboardHandler, purchaseHandler, salesHandler, workOrderHandler, pdfHandler, notificationHandler, reportHandler, warrantyHandler, purchaseOrderHandler, payableHandler, consignmentHandler, vehicleDocumentHandler, mechanicHandler, laborHandler, partRequestHandler, serviceInvoiceHandler, workOrderAttachmentHandler, scheduleHandler, workOrderSubletHandler, stockCountHandler, stockLocationHandler, stockTransferHandler, replenishmentHandler, labelHandler, fitmentHandler, serviceKitHandler, sparePartTrackingHandler, cfg)
 
 	// Start server
 	serverAddr := fmt.Sprintf(":%d", cfg.Server.Port)
@@ -101,6 +228,26 @@ func setupRoutes(
 	pdfHandler *handler.PDFHandler,
 	notificationHandler *handler.NotificationHandler,
 	reportHandler *handler.ReportHandler,
+	warrantyHandler *handler.WarrantyHandler,
+	purchaseOrderHandler *handler.PurchaseOrderHandler,
+	payableHandler *handler.PayableHandler,
+	consignmentHandler *handler.ConsignmentHandler,
+	vehicleDocumentHandler *handler.VehicleDocumentHandler,
+	mechanicHandler *handler.MechanicHandler,
+	laborHandler *handler.LaborHandler,
+	partRequestHandler *handler.PartRequestHandler,
+	serviceInvoiceHandler *handler.ServiceInvoiceHandler,
+	workOrderAttachmentHandler *handler.WorkOrderAttachmentHandler,
+	scheduleHandler *handler.ScheduleHandler,
+	workOrderSubletHandler *handler.WorkOrderSubletHandler,
+	stockCountHandler *handler.StockCountHandler,
+	stockLocationHandler *handler.StockLocationHandler,
+	stockTransferHandler *handler.StockTransferHandler,
+	replenishmentHandler *handler.ReplenishmentHandler,
+	labelHandler *handler.LabelHandler,
+	fitmentHandler *handler.FitmentHandler,
+	serviceKitHandler *handler.ServiceKitHandler,
+	sparePartTrackingHandler *handler.SparePartTrackingHandler,
 	cfg *config.Config,
 ) {
 	// Health check
@@ -158,6 +305,97 @@ func setupRoutes(
 			sales.GET("/reports/daily", salesHandler.GetDailySalesReport)
 		}
 
+		// Spare part purchase order routes (admin + kasir)
+		purchaseOrders := protected.Group("/purchase-orders")
+		purchaseOrders.Use(middleware.RequireAdminOrKasir())
+		{
+			purchaseOrders.POST("/", purchaseOrderHandler.CreatePurchaseOrder)
+			purchaseOrders.GET("/", purchaseOrderHandler.ListPurchaseOrders)
+			purchaseOrders.GET("/backorders", purchaseOrderHandler.ListBackorders)
+			purchaseOrders.GET("/:id", purchaseOrderHandler.GetPurchaseOrder)
+			purchaseOrders.PUT("/:id/order", purchaseOrderHandler.SubmitPurchaseOrder)
+			purchaseOrders.PUT("/:id/cancel", purchaseOrderHandler.CancelPurchaseOrder)
+			purchaseOrders.POST("/:id/receipts", purchaseOrderHandler.ReceiveGoods)
+			purchaseOrders.GET("/:id/receipts", purchaseOrderHandler.ListGoodsReceipts)
+		}
+
+		// Reorder suggestions and draft purchase orders (admin + kasir)
+		replenishment := protected.Group("/replenishment")
+		replenishment.Use(middleware.RequireAdminOrKasir())
+		{
+			replenishment.GET("/suggestions", replenishmentHandler.ListSuggestions)
+			replenishment.POST("/generate", replenishmentHandler.GenerateDraftPurchaseOrders)
+			replenishment.PUT("/spare-parts/:id", replenishmentHandler.UpdateSettings)
+			replenishment.PUT("/suppliers/:id/lead-time", middleware.RequireAdmin(), replenishmentHandler.UpdateSupplierLeadTime)
+		}
+
+		// Barcode and QR label printing (admin + kasir)
+		labels := protected.Group("/labels")
+		labels.Use(middleware.RequireAdminOrKasir())
+		{
+			labels.GET("/formats", labelHandler.ListFormats)
+			labels.POST("/spare-parts", labelHandler.PrintSparePartLabels)
+			labels.POST("/vehicles", labelHandler.PrintVehicleLabels)
+		}
+
+		// Accounts payable routes (admin + kasir)
+		payables := protected.Group("/payables")
+		payables.Use(middleware.RequireAdminOrKasir())
+		{
+			payables.GET("/", payableHandler.ListPayables)
+			payables.POST("/notify-overdue", payableHandler.NotifyOverduePayables)
+			payables.GET("/purchase-invoice/:invoice_id", payableHandler.GetPayableByPurchaseInvoice)
+			payables.GET("/:id", payableHandler.GetPayable)
+			payables.POST("/:id/payments", payableHandler.RecordPayment)
+		}
+
+		// Customer service invoice routes (admin + kasir)
+		serviceInvoices := protected.Group("/service-invoices")
+		serviceInvoices.Use(middleware.RequireAdminOrKasir())
+		{
+			serviceInvoices.GET("/", serviceInvoiceHandler.ListInvoices)
+			serviceInvoices.GET("/:id", serviceInvoiceHandler.GetInvoice)
+			serviceInvoices.POST("/:id/payments", serviceInvoiceHandler.RecordPayment)
+		}
+
+		// Consignment (titip jual) routes (admin + kasir)
+		consignments := protected.Group("/consignments")
+		consignments.Use(middleware.RequireAdminOrKasir())
+		{
+			consignments.POST("/", consignmentHandler.CreateConsignment)
+			consignments.GET("/", consignmentHandler.ListConsignments)
+			consignments.GET("/:id", consignmentHandler.GetConsignment)
+		}
+
+		// Vehicle legal document routes (admin + kasir)
+		vehicleDocuments := protected.Group("/vehicle-documents")
+		vehicleDocuments.Use(middleware.RequireAdminOrKasir())
+		{
+			vehicleDocuments.POST("/", vehicleDocumentHandler.CreateDocument)
+			vehicleDocuments.GET("/expiring", vehicleDocumentHandler.ListExpiringDocuments)
+			vehicleDocuments.GET("/vehicle/:vehicle_id", vehicleDocumentHandler.ListVehicleDocuments)
+			vehicleDocuments.GET("/:id", vehicleDocumentHandler.GetDocument)
+			vehicleDocuments.PUT("/:id", vehicleDocumentHandler.UpdateDocument)
+			vehicleDocuments.DELETE("/:id", vehicleDocumentHandler.DeleteDocument)
+			vehicleDocuments.POST("/:id/handover", vehicleDocumentHandler.HandOverDocument)
+		}
+
+		// Warranty routes (all authenticated users can look up, admin + kasir can manage)
+		warranties := protected.Group("/warranties")
+		{
+			warranties.GET("/", warrantyHandler.ListWarranties)
+			warranties.GET("/:id", warrantyHandler.GetWarranty)
+			warranties.GET("/:id/claims", warrantyHandler.ListClaims)
+		}
+
+		warrantiesManage := protected.Group("/warranties")
+		warrantiesManage.Use(middleware.RequireAdminOrKasir())
+		{
+			warrantiesManage.POST("/", warrantyHandler.CreateWarranty)
+			warrantiesManage.POST("/:id/claims", warrantyHandler.CreateClaim)
+			warrantiesManage.PUT("/:id/void", warrantyHandler.VoidWarranty)
+		}
+
 		// Work Order routes (admin + mechanic)
 		workOrders := protected.Group("/work-orders")
 		{
@@ -167,9 +405,89 @@ func setupRoutes(
 			workOrders.GET("/:id", workOrderHandler.GetWorkOrder)
 			workOrders.PUT("/:id/start", workOrderHandler.StartWorkOrder)
 			workOrders.PUT("/:id/complete", workOrderHandler.CompleteWorkOrder)
+			workOrders.PUT("/:id/status", workOrderHandler.UpdateStatus)
+			workOrders.GET("/:id/history", workOrderHandler.GetStatusHistory)
 			workOrders.PUT("/:id/progress", workOrderHandler.UpdateProgress)
 			workOrders.PUT("/:id/assign", middleware.RequireAdmin(), workOrderHandler.AssignMechanic)
-			workOrders.POST("/:id/use-part", workOrderHandler.UsePart)
+			workOrders.GET("/:id/compatible-parts", fitmentHandler.ListWorkOrderCompatibleParts)
+			workOrders.POST("/:id/use-part", middleware.RequireAdminOrKasir(), workOrderHandler.UsePart)
+			workOrders.POST("/:id/service-kits", middleware.RequireAdminOrKasir(), workOrderHandler.ApplyServiceKit)
+			workOrders.POST("/parts/:part_id/return", middleware.RequireAdminOrKasir(), workOrderHandler.ReturnPart)
+			workOrders.POST("/:id/service-invoice", middleware.RequireAdminOrKasir(), serviceInvoiceHandler.CreateInvoice)
+			workOrders.GET("/:id/part-requests", partRequestHandler.ListWorkOrderRequests)
+			workOrders.POST("/:id/part-requests", partRequestHandler.CreateRequest)
+			workOrders.GET("/:id/tasks", workOrderHandler.ListTasks)
+			workOrders.POST("/:id/tasks", workOrderHandler.AddTask)
+			workOrders.PUT("/tasks/:task_id", workOrderHandler.UpdateTask)
+			workOrders.PUT("/tasks/:task_id/status", workOrderHandler.UpdateTaskStatus)
+			workOrders.DELETE("/tasks/:task_id", workOrderHandler.DeleteTask)
+			workOrders.POST("/:id/timers", laborHandler.StartTimer)
+			workOrders.GET("/:id/time-entries", laborHandler.ListWorkOrderEntries)
+			workOrders.GET("/:id/attachments", workOrderAttachmentHandler.ListAttachments)
+			workOrders.POST("/:id/attachments", workOrderAttachmentHandler.UploadAttachment)
+			workOrders.DELETE("/attachments/:attachment_id", workOrderAttachmentHandler.DeleteAttachment)
+			workOrders.GET("/:id/schedule", scheduleHandler.ListWorkOrderSchedule)
+			workOrders.POST("/:id/schedule", middleware.RequireAdmin(), scheduleHandler.ScheduleWork)
+			workOrders.GET("/:id/sublets", workOrderSubletHandler.ListSublets)
+			workOrders.POST("/:id/sublets", middleware.RequireAdminOrKasir(), workOrderSubletHandler.CreateSublet)
+			workOrders.GET("/sublets/:sublet_id", workOrderSubletHandler.GetSublet)
+			workOrders.PUT("/sublets/:sublet_id/complete", middleware.RequireAdminOrKasir(), workOrderSubletHandler.CompleteSublet)
+			workOrders.PUT("/sublets/:sublet_id/cancel", middleware.RequireAdminOrKasir(), workOrderSubletHandler.CancelSublet)
+			workOrders.POST("/sublets/:sublet_id/invoice", middleware.RequireAdminOrKasir(), workOrderSubletHandler.UploadInvoice)
+		}
+
+		// Workshop scheduling routes (calendar for all, planning by admin)
+		workshop := protected.Group("/workshop")
+		{
+			workshop.GET("/bays", scheduleHandler.ListBays)
+			workshop.POST("/bays", middleware.RequireAdmin(), scheduleHandler.CreateBay)
+			workshop.PUT("/bays/:id", middleware.RequireAdmin(), scheduleHandler.UpdateBay)
+			workshop.DELETE("/bays/:id", middleware.RequireAdmin(), scheduleHandler.DeleteBay)
+		}
+
+		schedules := protected.Group("/schedules")
+		{
+			schedules.GET("/calendar", scheduleHandler.GetCalendar)
+			schedules.PUT("/:id", middleware.RequireAdmin(), scheduleHandler.UpdateSchedule)
+			schedules.DELETE("/:id", middleware.RequireAdmin(), scheduleHandler.DeleteSchedule)
+			schedules.POST("/recalculate", middleware.RequireAdmin(), scheduleHandler.RecalculateEstimates)
+		}
+
+		// Labor time tracking routes (admin + mechanic)
+		labor := protected.Group("/labor")
+		labor.Use(middleware.RequireRole(domain.RoleAdmin, domain.RoleMekanik))
+		{
+			labor.PUT("/timers/:id/pause", laborHandler.PauseTimer)
+			labor.PUT("/timers/:id/resume", laborHandler.ResumeTimer)
+			labor.PUT("/timers/:id/stop", laborHandler.StopTimer)
+			labor.GET("/timesheet", laborHandler.GetTimesheet)
+			labor.GET("/rates", laborHandler.ListRates)
+			labor.PUT("/rates", middleware.RequireAdmin(), laborHandler.SetRate)
+		}
+
+		// Part request queue (storekeepers: admin + kasir)
+		partRequests := protected.Group("/part-requests")
+		partRequests.Use(middleware.RequireAdminOrKasir())
+		{
+			partRequests.GET("/", partRequestHandler.ListRequests)
+			partRequests.GET("/:id", partRequestHandler.GetRequest)
+			partRequests.PUT("/:id/approve", partRequestHandler.ApproveRequest)
+			partRequests.PUT("/:id/reject", partRequestHandler.RejectRequest)
+		}
+
+		// Mechanic availability and assignment routes (admin only)
+		mechanics := protected.Group("/mechanics")
+		mechanics.Use(middleware.RequireAdmin())
+		{
+			mechanics.GET("/workload", mechanicHandler.ListWorkloads)
+			mechanics.DELETE("/leaves/:leave_id", mechanicHandler.DeleteLeave)
+			mechanics.PUT("/:id/skills", mechanicHandler.SetSkills)
+			mechanics.GET("/:id/leaves", mechanicHandler.ListLeaves)
+			mechanics.POST("/:id/leaves", mechanicHandler.CreateLeave)
+			mechanics.GET("/:id/labor-profile", laborHandler.GetMechanicProfile)
+			mechanics.PUT("/:id/labor-profile", laborHandler.SetMechanicProfile)
+			mechanics.GET("/:id/working-hours", mechanicHandler.GetWorkingHours)
+			mechanics.PUT("/:id/working-hours", mechanicHandler.SetWorkingHours)
 		}
 
 		// Admin-only routes
@@ -208,6 +526,7 @@ func setupRoutes(
 		{
 			customers.GET("/", customerHandler.ListCustomers)
 			customers.GET("/:id", customerHandler.GetCustomer)
+			customers.GET("/:id/vehicles", customerHandler.ListCustomerVehicles)
 		}
 		
 		customersManage := protected.Group("/customers")
@@ -216,6 +535,9 @@ func setupRoutes(
 			customersManage.POST("/", customerHandler.CreateCustomer)
 			customersManage.PUT("/:id", customerHandler.UpdateCustomer)
 			customersManage.DELETE("/:id", customerHandler.DeleteCustomer)
+			customersManage.POST("/:id/vehicles", customerHandler.AddCustomerVehicle)
+			customersManage.PUT("/vehicles/:vehicle_id", customerHandler.UpdateCustomerVehicle)
+			customersManage.DELETE("/vehicles/:vehicle_id", customerHandler.DeleteCustomerVehicle)
 		}
 		
 		// Vehicle routes (all authenticated users can view, admin + kasir can manage)
@@ -232,6 +554,9 @@ func setupRoutes(
 			vehiclesManage.PUT("/:id", vehicleHandler.UpdateVehicle)
 			vehiclesManage.PUT("/:id/status", vehicleHandler.UpdateVehicleStatus)
 			vehiclesManage.DELETE("/:id", vehicleHandler.DeleteVehicle)
+			vehiclesManage.GET("/:id/cost-breakdown", vehicleHandler.GetCostBreakdown)
+			vehiclesManage.POST("/:id/costs", vehicleHandler.AddCost)
+			vehiclesManage.DELETE("/costs/:cost_id", vehicleHandler.DeleteCost)
 		}
 		
 		// Spare Parts routes (all authenticated users can view, admin + kasir can manage)
@@ -242,6 +567,14 @@ func setupRoutes(
 			spareParts.GET("/:id", sparePartHandler.GetSparePart)
 			spareParts.GET("/code/:code", sparePartHandler.GetSparePartByCode)
 			spareParts.GET("/barcode/:barcode", sparePartHandler.GetSparePartByBarcode)
+			spareParts.GET("/:id/cost-layers", sparePartHandler.GetCostLayers)
+			spareParts.GET("/compatible", fitmentHandler.ListCompatibleParts)
+			spareParts.GET("/:id/stock", stockLocationHandler.GetSparePartStock)
+			spareParts.GET("/:id/fitments", fitmentHandler.ListFitments)
+			spareParts.GET("/batches/expiring", sparePartTrackingHandler.ListExpiringBatches)
+			spareParts.GET("/:id/batches", sparePartTrackingHandler.ListBatches)
+			spareParts.GET("/serials/:serial_number", sparePartTrackingHandler.FindSerial)
+			spareParts.GET("/:id/serials", sparePartTrackingHandler.ListSerials)
 		}
 		
 		sparePartsManage := protected.Group("/spare-parts")
@@ -249,10 +582,70 @@ func setupRoutes(
 		{
 			sparePartsManage.POST("/", sparePartHandler.CreateSparePart)
 			sparePartsManage.PUT("/:id", sparePartHandler.UpdateSparePart)
+			sparePartsManage.POST("/barcodes/assign", sparePartHandler.AssignMissingBarcodes)
+			sparePartsManage.POST("/:id/barcode", sparePartHandler.AssignBarcode)
 			sparePartsManage.POST("/:id/adjust-stock", sparePartHandler.AdjustStock)
+			sparePartsManage.PUT("/:id/stock/:location_id", stockLocationHandler.UpdateSparePartStock)
+			sparePartsManage.POST("/:id/fitments", fitmentHandler.AddFitment)
+			sparePartsManage.PUT("/fitments/:fitment_id", fitmentHandler.UpdateFitment)
+			sparePartsManage.DELETE("/fitments/:fitment_id", fitmentHandler.DeleteFitment)
+			sparePartsManage.PUT("/:id/tracking", sparePartTrackingHandler.UpdateTracking)
+			sparePartsManage.POST("/:id/serials", sparePartTrackingHandler.RegisterSerials)
 			sparePartsManage.DELETE("/:id", sparePartHandler.DeleteSparePart)
 		}
 
+		// Service kits (all authenticated users can view, admin + kasir can manage)
+		serviceKits := protected.Group("/service-kits")
+		{
+			serviceKits.GET("/", serviceKitHandler.ListKits)
+			serviceKits.GET("/:id", serviceKitHandler.GetKit)
+			serviceKits.POST("/", middleware.RequireAdminOrKasir(), serviceKitHandler.CreateKit)
+			serviceKits.PUT("/:id", middleware.RequireAdminOrKasir(), serviceKitHandler.UpdateKit)
+			serviceKits.DELETE("/:id", middleware.RequireAdminOrKasir(), serviceKitHandler.DeleteKit)
+		}
+
+		// Stock opname sessions (admin + kasir count, admin approves)
+		stockCounts := protected.Group("/stock-counts")
+		stockCounts.Use(middleware.RequireAdminOrKasir())
+		{
+			stockCounts.GET("/", stockCountHandler.ListCounts)
+			stockCounts.POST("/", stockCountHandler.CreateCount)
+			stockCounts.GET("/:id", stockCountHandler.GetCount)
+			stockCounts.GET("/:id/items", stockCountHandler.ListItems)
+			stockCounts.POST("/:id/scan", stockCountHandler.ScanItem)
+			stockCounts.PUT("/:id/items/:item_id", stockCountHandler.SetItemCount)
+			stockCounts.PUT("/:id/submit", stockCountHandler.SubmitCount)
+			stockCounts.PUT("/:id/reopen", stockCountHandler.ReopenCount)
+			stockCounts.PUT("/:id/approve", middleware.RequireAdmin(), stockCountHandler.ApproveCount)
+			stockCounts.PUT("/:id/cancel", stockCountHandler.CancelCount)
+		}
+
+		// Stock locations (admin + kasir view, admin manages)
+		stockLocations := protected.Group("/stock-locations")
+		stockLocations.Use(middleware.RequireAdminOrKasir())
+		{
+			stockLocations.GET("/", stockLocationHandler.ListLocations)
+			stockLocations.GET("/low-stock", stockLocationHandler.ListLowStock)
+			stockLocations.GET("/:id", stockLocationHandler.GetLocation)
+			stockLocations.GET("/:id/stock", stockLocationHandler.GetLocationStock)
+			stockLocations.POST("/", middleware.RequireAdmin(), stockLocationHandler.CreateLocation)
+			stockLocations.PUT("/:id", middleware.RequireAdmin(), stockLocationHandler.UpdateLocation)
+			stockLocations.PUT("/:id/default", middleware.RequireAdmin(), stockLocationHandler.SetDefaultLocation)
+			stockLocations.DELETE("/:id", middleware.RequireAdmin(), stockLocationHandler.DeleteLocation)
+		}
+
+		// Stock transfers between locations (admin + kasir)
+		stockTransfers := protected.Group("/stock-transfers")
+		stockTransfers.Use(middleware.RequireAdminOrKasir())
+		{
+			stockTransfers.GET("/", stockTransferHandler.ListTransfers)
+			stockTransfers.POST("/", stockTransferHandler.CreateTransfer)
+			stockTransfers.GET("/:id", stockTransferHandler.GetTransfer)
+			stockTransfers.PUT("/:id/ship", stockTransferHandler.ShipTransfer)
+			stockTransfers.PUT("/:id/receive", stockTransferHandler.ReceiveTransfer)
+			stockTransfers.PUT("/:id/cancel", stockTransferHandler.CancelTransfer)
+		}
+
 		// File upload routes (admin + kasir)
 		files := protected.Group("/files")
 		files.Use(middleware.RequireAdminOrKasir())
@@ -260,6 +653,8 @@ func setupRoutes(
 			files.POST("/vehicles/:id/photo", fileHandler.UploadVehiclePhoto)
 			files.POST("/sales/:id/transfer-proof", fileHandler.UploadSalesTransferProof)
 			files.POST("/purchases/:id/transfer-proof", fileHandler.UploadPurchaseTransferProof)
+			files.POST("/payable-payments/:id/transfer-proof", fileHandler.UploadPayablePaymentTransferProof)
+			files.POST("/vehicle-documents/:id/scan", fileHandler.UploadVehicleDocumentScan)
 			files.DELETE("/delete", fileHandler.DeleteFile)
 		}
 
@@ -270,6 +665,7 @@ func setupRoutes(
 			pdf.GET("/sales/:id", pdfHandler.GenerateSalesInvoicePDF)
 			pdf.GET("/purchases/:id", pdfHandler.GeneratePurchaseInvoicePDF)
 			pdf.GET("/work-orders/:id", pdfHandler.GenerateWorkOrderPDF)
+			pdf.GET("/consignments/:id", pdfHandler.GenerateConsignmentStatementPDF)
 			pdf.GET("/reports", pdfHandler.GenerateReportPDF)
 		}
 
@@ -297,6 +693,8 @@ func setupRoutes(
 			reports.GET("/work-orders", reportHandler.GetWorkOrderReport)
 			reports.GET("/daily", reportHandler.GetDailyReport)
 			reports.GET("/overview", reportHandler.GetBusinessOverview)
+			reports.GET("/warranty-costs", warrantyHandler.GetWarrantyCostReport)
+			reports.GET("/payables-aging", payableHandler.GetAgingReport)
 		}
 	}
 }
\ No newline at end of file
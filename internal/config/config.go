@@ -5,18 +5,25 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Database DatabaseConfig
-	Server   ServerConfig
-	JWT      JWTConfig
-	Upload   UploadConfig
-	Invoice  InvoiceConfig
-	Log      LogConfig
+	Database  DatabaseConfig
+	Server    ServerConfig
+	JWT       JWTConfig
+	Upload    UploadConfig
+	Invoice   InvoiceConfig
+	Log       LogConfig
+	Inventory InventoryConfig
+	Documents DocumentConfig
+	Workshop  WorkshopConfig
+	Labels    LabelConfig
+	Payables  PayableConfig
+	Warranty  WarrantyConfig
 }
 
 type DatabaseConfig struct {
@@ -52,6 +59,46 @@ type LogConfig struct {
 	File  string
 }
 
+type InventoryConfig struct {
+	CostingMethod        string // average or fifo
+	UsageWindowDays      int    // days of usage history reorder points are based on
+	SafetyStockDays      int    // default days of usage kept as safety stock
+	ReorderCoverDays     int    // days of usage a reorder should cover
+	DefaultLeadTimeDays  int    // lead time for parts without a supplier
+	ReorderIntervalHours int    // draft purchase orders from reorder suggestions every N hours, 0 = off
+	ExpiryAlertDays      int    // batches expiring within N days are reported as expiring stock
+	ExpiryAlertHours     int    // notify expiring stock every N hours, 0 = off
+}
+
+type DocumentConfig struct {
+	RequiredForSale []string // document types that must be in custody before a sale
+	BlockMissing    bool     // reject the sale instead of warning
+}
+
+type WorkshopConfig struct {
+	AssignmentStrategy string // least_workload, round_robin or skill_match
+	DefaultHourlyRate  int    // labor rate for mechanics without a rate or skill level
+	OpenTime           string // HH:MM, default shift start for mechanics without own hours
+	CloseTime          string // HH:MM, default shift end
+	WorkDays           []int  // days of the week the workshop is open, 0 = Sunday
+	EstimateMinutes    int    // refresh completion estimates every N minutes, 0 = off
+}
+
+type LabelConfig struct {
+	BarcodeSymbology  string // ean13 or code128, used when assigning barcodes
+	EAN13Prefix       string // in-store prefix for generated EAN-13 codes
+	AutoAssignBarcode bool   // give new spare parts without a barcode one on create
+	VehicleURL        string // base URL the vehicle QR code points to, the vehicle ID is appended
+}
+
+type PayableConfig struct {
+	OverdueCheckHours int // send overdue payable notifications every N hours, 0 = off
+}
+
+type WarrantyConfig struct {
+	ExpiryCheckHours int // mark warranties past their end date expired every N hours, 0 = off
+}
+
 func LoadConfig() *Config {
 	// Load .env file if it exists
 	if err := godotenv.Load(); err != nil {
@@ -86,6 +133,40 @@ func LoadConfig() *Config {
 			Level: getEnv("LOG_LEVEL", "debug"),
 			File:  getEnv("LOG_FILE", "./logs/app.log"),
 		},
+		Inventory: InventoryConfig{
+			CostingMethod:        getEnv("INVENTORY_COSTING_METHOD", "average"),
+			UsageWindowDays:      getEnvInt("INVENTORY_USAGE_WINDOW_DAYS", 90),
+			SafetyStockDays:      getEnvInt("INVENTORY_SAFETY_STOCK_DAYS", 7),
+			ReorderCoverDays:     getEnvInt("INVENTORY_REORDER_COVER_DAYS", 30),
+			DefaultLeadTimeDays:  getEnvInt("INVENTORY_DEFAULT_LEAD_TIME_DAYS", 7),
+			ReorderIntervalHours: getEnvInt("INVENTORY_REORDER_INTERVAL_HOURS", 0),
+			ExpiryAlertDays:      getEnvInt("INVENTORY_EXPIRY_ALERT_DAYS", 30),
+			ExpiryAlertHours:     getEnvInt("INVENTORY_EXPIRY_ALERT_HOURS", 24),
+		},
+		Documents: DocumentConfig{
+			RequiredForSale: getEnvList("SALE_REQUIRED_DOCUMENTS", "bpkb,stnk"),
+			BlockMissing:    getEnv("SALE_DOCUMENT_CHECK", "warn") == "block",
+		},
+		Workshop: WorkshopConfig{
+			AssignmentStrategy: getEnv("MECHANIC_ASSIGNMENT_STRATEGY", "least_workload"),
+			DefaultHourlyRate:  getEnvInt("LABOR_DEFAULT_HOURLY_RATE", 50000),
+			OpenTime:           getEnv("WORKSHOP_OPEN_TIME", "08:00"),
+			CloseTime:          getEnv("WORKSHOP_CLOSE_TIME", "17:00"),
+			WorkDays:           getEnvIntList("WORKSHOP_WORK_DAYS", "1,2,3,4,5,6"),
+			EstimateMinutes:    getEnvInt("WORKSHOP_ESTIMATE_REFRESH_MINUTES", 15),
+		},
+		Labels: LabelConfig{
+			BarcodeSymbology:  getEnv("BARCODE_SYMBOLOGY", "ean13"),
+			EAN13Prefix:       getEnv("BARCODE_EAN13_PREFIX", "200"),
+			AutoAssignBarcode: getEnv("BARCODE_AUTO_ASSIGN", "true") == "true",
+			VehicleURL:        getEnv("VEHICLE_LABEL_URL", "http://localhost:8080/api/v1/vehicles/"),
+		},
+		Payables: PayableConfig{
+			OverdueCheckHours: getEnvInt("PAYABLE_OVERDUE_CHECK_HOURS", 24),
+		},
+		Warranty: WarrantyConfig{
+			ExpiryCheckHours: getEnvInt("WARRANTY_EXPIRY_CHECK_HOURS", 1),
+		},
 	}
 
 	return config
@@ -120,4 +201,24 @@ func getEnvInt(key string, defaultValue int) int {
 		}
 	}
 	return defaultValue
+}
+
+func getEnvList(key, defaultValue string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key, defaultValue), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func getEnvIntList(key, defaultValue string) []int {
+	var values []int
+	for _, value := range getEnvList(key, defaultValue) {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			values = append(values, intValue)
+		}
+	}
+	return values
 }
\ No newline at end of file
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"database/sql/driver"
+	"strings"
 	"time"
 )
 
@@ -65,6 +66,21 @@ type Customer struct {
 	Address      *string `json:"address" db:"address"`
 }
 
+// CustomerVehicle entity (a customer's own vehicle serviced in the workshop)
+type CustomerVehicle struct {
+	BaseModel
+	CustomerID    int       `json:"customer_id" db:"customer_id"`
+	PlateNumber   string    `json:"plate_number" db:"plate_number"`
+	Brand         string    `json:"brand" db:"brand"`
+	Model         string    `json:"model" db:"model"`
+	Year          *int      `json:"year" db:"year"`
+	Color         *string   `json:"color" db:"color"`
+	ChassisNumber *string   `json:"chassis_number" db:"chassis_number"`
+	EngineNumber  *string   `json:"engine_number" db:"engine_number"`
+	Notes         *string   `json:"notes" db:"notes"`
+	Customer      *Customer `json:"customer,omitempty" db:"customer"`
+}
+
 // Supplier entity
 type Supplier struct {
 	BaseModel
@@ -74,6 +90,7 @@ type Supplier struct {
 	Phone         *string `json:"phone" db:"phone"`
 	Email         *string `json:"email" db:"email"`
 	Address       *string `json:"address" db:"address"`
+	LeadTimeDays  int     `json:"lead_time_days" db:"lead_time_days"` // days from ordering to delivery
 }
 
 // VehicleCategory entity
@@ -135,9 +152,79 @@ type Vehicle struct {
 	PrimaryPhoto   *string        `json:"primary_photo" db:"primary_photo"`
 	PurchasedDate  *time.Time     `json:"purchased_date" db:"purchased_date"`
 	SoldDate       *time.Time     `json:"sold_date" db:"sold_date"`
+	IsConsignment  bool           `json:"is_consignment" db:"is_consignment"`
 	Category       *VehicleCategory `json:"category,omitempty"`
 }
 
+// Vehicle cost type. Purchase and reconditioning come from the vehicle and
+// its work orders; the others are entered in the cost ledger.
+type VehicleCostType string
+
+const (
+	VehicleCostTypePurchase        VehicleCostType = "purchase"
+	VehicleCostTypeReconditioning  VehicleCostType = "reconditioning"
+	VehicleCostTypeTransport       VehicleCostType = "transport"
+	VehicleCostTypeDocumentFee     VehicleCostType = "document_fee"
+	VehicleCostTypeRegistrationFee VehicleCostType = "registration_fee"
+	VehicleCostTypeBrokerFee       VehicleCostType = "broker_fee"
+	VehicleCostTypeSublet          VehicleCostType = "sublet"
+	VehicleCostTypeOther           VehicleCostType = "other"
+)
+
+func (vct VehicleCostType) String() string {
+	return string(vct)
+}
+
+// IsLedgerType reports whether costs of this type are entered in the ledger
+func (vct VehicleCostType) IsLedgerType() bool {
+	switch vct {
+	case VehicleCostTypeTransport, VehicleCostTypeDocumentFee, VehicleCostTypeRegistrationFee,
+		VehicleCostTypeBrokerFee, VehicleCostTypeSublet, VehicleCostTypeOther:
+		return true
+	}
+	return false
+}
+
+// VehicleCost entity (a landed cost of a stock vehicle outside work orders)
+type VehicleCost struct {
+	BaseModel
+	VehicleID   int             `json:"vehicle_id" db:"vehicle_id"`
+	CostType    VehicleCostType `json:"cost_type" db:"cost_type"`
+	Amount      float64         `json:"amount" db:"amount"`
+	CostDate    time.Time       `json:"cost_date" db:"cost_date"`
+	Description *string         `json:"description" db:"description"`
+	Reference   *string         `json:"reference" db:"reference"`
+	CreatedBy   *int            `json:"created_by" db:"created_by"` // nil for opening balances
+}
+
+// VehicleCostComponent is the total of one cost type of a vehicle
+type VehicleCostComponent struct {
+	CostType VehicleCostType `json:"cost_type" db:"cost_type"`
+	Amount   float64         `json:"amount" db:"amount"`
+	Entries  int             `json:"entries" db:"entries"`
+}
+
+// VehicleCostWorkOrder is a completed reconditioning work order counted in HPP
+type VehicleCostWorkOrder struct {
+	ID          int        `json:"id" db:"id"`
+	WONumber    string     `json:"wo_number" db:"wo_number"`
+	TotalCost   float64    `json:"total_cost" db:"total_cost"`
+	CompletedAt *time.Time `json:"completed_at" db:"completed_at"`
+}
+
+// VehicleCostBreakdown shows how a vehicle's HPP is built up. RepairCost is
+// everything on top of the purchase price.
+type VehicleCostBreakdown struct {
+	VehicleID     int                     `json:"vehicle_id"`
+	VehicleCode   string                  `json:"vehicle_code"`
+	PurchasePrice float64                 `json:"purchase_price"`
+	RepairCost    float64                 `json:"repair_cost"`
+	HPP           float64                 `json:"hpp"`
+	Components    []*VehicleCostComponent `json:"components"`
+	WorkOrders    []*VehicleCostWorkOrder `json:"work_orders"`
+	Costs         []*VehicleCost          `json:"costs"`
+}
+
 // VehiclePhoto types
 type VehiclePhotoType string
 
@@ -246,9 +333,11 @@ type PurchaseInvoice struct {
 	TransactionType   TransactionType  `json:"transaction_type" db:"transaction_type"`
 	CustomerID        *int             `json:"customer_id" db:"customer_id"`
 	SupplierID        *int             `json:"supplier_id" db:"supplier_id"`
-	VehicleID         int              `json:"vehicle_id" db:"vehicle_id"`
+	VehicleID         *int             `json:"vehicle_id" db:"vehicle_id"` // nil for bulk purchases
 	PurchasePrice     float64          `json:"purchase_price" db:"purchase_price"`
 	NegotiatedPrice   *float64         `json:"negotiated_price" db:"negotiated_price"`
+	SharedCost        float64          `json:"shared_cost" db:"shared_cost"`
+	AllocationMethod  AllocationMethod `json:"allocation_method" db:"allocation_method"`
 	FinalPrice        float64          `json:"final_price" db:"final_price"`
 	PaymentMethod     PaymentMethod    `json:"payment_method" db:"payment_method"`
 	TransferProof     *string          `json:"transfer_proof" db:"transfer_proof"`
@@ -259,6 +348,48 @@ type PurchaseInvoice struct {
 	Supplier          *Supplier        `json:"supplier,omitempty"`
 	Vehicle           *Vehicle         `json:"vehicle,omitempty"`
 	Creator           *User            `json:"creator,omitempty"`
+	Payable           *Payable         `json:"payable,omitempty" db:"-"`
+	Items             []*PurchaseInvoiceItem `json:"items,omitempty" db:"-"`
+}
+
+// Shared cost allocation methods for bulk purchases
+type AllocationMethod string
+
+const (
+	AllocationMethodValue AllocationMethod = "value" // pro rata to vehicle price
+	AllocationMethodEqual AllocationMethod = "equal" // same share per vehicle
+)
+
+func (am AllocationMethod) String() string {
+	return string(am)
+}
+
+func (am *AllocationMethod) Scan(value interface{}) error {
+	if value == nil {
+		*am = ""
+		return nil
+	}
+	if s, ok := value.(string); ok {
+		*am = AllocationMethod(s)
+	}
+	return nil
+}
+
+func (am AllocationMethod) Value() (driver.Value, error) {
+	return string(am), nil
+}
+
+// PurchaseInvoiceItem entity (one vehicle on a purchase invoice)
+type PurchaseInvoiceItem struct {
+	ID                int        `json:"id" db:"id"`
+	PurchaseInvoiceID int        `json:"purchase_invoice_id" db:"purchase_invoice_id"`
+	VehicleID         int        `json:"vehicle_id" db:"vehicle_id"`
+	Price             float64    `json:"price" db:"price"`
+	AllocatedCost     float64    `json:"allocated_cost" db:"allocated_cost"`
+	TotalCost         float64    `json:"total_cost" db:"total_cost"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	Vehicle           *Vehicle   `json:"vehicle,omitempty"`
+	WorkOrder         *WorkOrder `json:"work_order,omitempty" db:"-"` // reconditioning opened when the vehicle is bought
 }
 
 // SalesInvoice entity
@@ -277,6 +408,7 @@ type SalesInvoice struct {
 	CreatedBy          int           `json:"created_by" db:"created_by"`
 	TransactionDate    time.Time     `json:"transaction_date" db:"transaction_date"`
 	ProfitAmount       float64       `json:"profit_amount" db:"profit_amount"`
+	DocumentWarnings   []string      `json:"document_warnings,omitempty" db:"-"`
 	Customer           *Customer     `json:"customer,omitempty"`
 	Vehicle            *Vehicle      `json:"vehicle,omitempty"`
 	Creator            *User         `json:"creator,omitempty"`
@@ -286,12 +418,32 @@ type SalesInvoice struct {
 type WorkOrderStatus string
 
 const (
-	WorkOrderStatusPending    WorkOrderStatus = "pending"
-	WorkOrderStatusInProgress WorkOrderStatus = "in_progress"
-	WorkOrderStatusCompleted  WorkOrderStatus = "completed"
-	WorkOrderStatusCancelled  WorkOrderStatus = "cancelled"
+	WorkOrderStatusPending      WorkOrderStatus = "pending"
+	WorkOrderStatusInProgress   WorkOrderStatus = "in_progress"
+	WorkOrderStatusOnHold       WorkOrderStatus = "on_hold"
+	WorkOrderStatusWaitingParts WorkOrderStatus = "waiting_parts"
+	WorkOrderStatusCompleted    WorkOrderStatus = "completed"
+	WorkOrderStatusCancelled    WorkOrderStatus = "cancelled"
 )
 
+// Allowed work order status transitions; completed and cancelled are final
+var workOrderTransitions = map[WorkOrderStatus][]WorkOrderStatus{
+	WorkOrderStatusPending:      {WorkOrderStatusInProgress, WorkOrderStatusOnHold, WorkOrderStatusWaitingParts, WorkOrderStatusCancelled},
+	WorkOrderStatusInProgress:   {WorkOrderStatusOnHold, WorkOrderStatusWaitingParts, WorkOrderStatusCompleted, WorkOrderStatusCancelled},
+	WorkOrderStatusOnHold:       {WorkOrderStatusInProgress, WorkOrderStatusWaitingParts, WorkOrderStatusCancelled},
+	WorkOrderStatusWaitingParts: {WorkOrderStatusInProgress, WorkOrderStatusOnHold, WorkOrderStatusCancelled},
+}
+
+// CanTransitionTo reports whether a work order may move from this status to next
+func (wos WorkOrderStatus) CanTransitionTo(next WorkOrderStatus) bool {
+	for _, allowed := range workOrderTransitions[wos] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
 func (wos WorkOrderStatus) String() string {
 	return string(wos)
 }
@@ -311,25 +463,300 @@ func (wos WorkOrderStatus) Value() (driver.Value, error) {
 	return string(wos), nil
 }
 
+// Work order type
+type WorkOrderType string
+
+const (
+	WorkOrderTypeReconditioning  WorkOrderType = "reconditioning"   // our own stock vehicle
+	WorkOrderTypeCustomerService WorkOrderType = "customer_service" // a customer's vehicle, billed to the customer
+)
+
+func (wot WorkOrderType) String() string {
+	return string(wot)
+}
+
 // WorkOrder entity
 type WorkOrder struct {
 	BaseModel
-	WONumber            string          `json:"wo_number" db:"wo_number"`
-	VehicleID           int             `json:"vehicle_id" db:"vehicle_id"`
-	Description         string          `json:"description" db:"description"`
-	AssignedMechanicID  int             `json:"assigned_mechanic_id" db:"assigned_mechanic_id"`
-	Status              WorkOrderStatus `json:"status" db:"status"`
-	ProgressPercentage  int             `json:"progress_percentage" db:"progress_percentage"`
-	TotalPartsCost      float64         `json:"total_parts_cost" db:"total_parts_cost"`
-	LaborCost           float64         `json:"labor_cost" db:"labor_cost"`
-	TotalCost           float64         `json:"total_cost" db:"total_cost"`
-	Notes               *string         `json:"notes" db:"notes"`
-	CreatedBy           int             `json:"created_by" db:"created_by"`
-	StartedAt           *time.Time      `json:"started_at" db:"started_at"`
-	CompletedAt         *time.Time      `json:"completed_at" db:"completed_at"`
-	Vehicle             *Vehicle        `json:"vehicle,omitempty"`
-	AssignedMechanic    *User           `json:"assigned_mechanic,omitempty"`
-	Creator             *User           `json:"creator,omitempty"`
+	WONumber              string                 `json:"wo_number" db:"wo_number"`
+	OrderType             WorkOrderType          `json:"order_type" db:"order_type"`
+	VehicleID             *int                   `json:"vehicle_id" db:"vehicle_id"`
+	CustomerID            *int                   `json:"customer_id" db:"customer_id"`
+	CustomerVehicleID     *int                   `json:"customer_vehicle_id" db:"customer_vehicle_id"`
+	Description           string                 `json:"description" db:"description"`
+	AssignedMechanicID    int                    `json:"assigned_mechanic_id" db:"assigned_mechanic_id"`
+	Status                WorkOrderStatus        `json:"status" db:"status"`
+	ProgressPercentage    int                    `json:"progress_percentage" db:"progress_percentage"`
+	TotalPartsCost        float64                `json:"total_parts_cost" db:"total_parts_cost"`
+	LaborCost             float64                `json:"labor_cost" db:"labor_cost"`
+	SubletCost            float64                `json:"sublet_cost" db:"sublet_cost"`
+	TotalCost             float64                `json:"total_cost" db:"total_cost"`
+	LaborPrice            float64                `json:"labor_price" db:"labor_price"` // labor billed to the customer
+	Notes                 *string                `json:"notes" db:"notes"`
+	CreatedBy             int                    `json:"created_by" db:"created_by"`
+	StartedAt             *time.Time             `json:"started_at" db:"started_at"`
+	CompletedAt           *time.Time             `json:"completed_at" db:"completed_at"`
+	IsWarranty            bool                   `json:"is_warranty" db:"is_warranty"`
+	AssignmentReason      *string                `json:"assignment_reason" db:"assignment_reason"`
+	EstimatedCompletionAt *time.Time             `json:"estimated_completion_at" db:"estimated_completion_at"`
+	Vehicle               *Vehicle               `json:"vehicle,omitempty"`
+	AssignedMechanic      *User                  `json:"assigned_mechanic,omitempty"`
+	Creator               *User                  `json:"creator,omitempty"`
+	Customer              *Customer              `json:"customer,omitempty" db:"-"`
+	CustomerVehicle       *CustomerVehicle       `json:"customer_vehicle,omitempty" db:"-"`
+	Tasks                 []*WorkOrderTask       `json:"tasks,omitempty" db:"-"`
+	Parts                 []*WorkOrderPart       `json:"parts,omitempty" db:"-"`
+	PartReturns           []*WorkOrderPartReturn `json:"part_returns,omitempty" db:"-"`
+	Sublets               []*WorkOrderSublet     `json:"sublets,omitempty" db:"-"`
+}
+
+// IsCustomerService reports whether the work order repairs a customer's vehicle
+func (wo *WorkOrder) IsCustomerService() bool {
+	return wo.OrderType == WorkOrderTypeCustomerService
+}
+
+// CountsTowardHPP reports whether the work order cost is part of the stock
+// vehicle's HPP. Warranty repairs and customer jobs are not.
+func (wo *WorkOrder) CountsTowardHPP() bool {
+	return !wo.IsCustomerService() && !wo.IsWarranty && wo.VehicleID != nil
+}
+
+// Work order task status
+type WorkOrderTaskStatus string
+
+const (
+	WorkOrderTaskStatusPending    WorkOrderTaskStatus = "pending"
+	WorkOrderTaskStatusInProgress WorkOrderTaskStatus = "in_progress"
+	WorkOrderTaskStatusCompleted  WorkOrderTaskStatus = "completed"
+	WorkOrderTaskStatusSkipped    WorkOrderTaskStatus = "skipped"
+)
+
+func (ts WorkOrderTaskStatus) String() string {
+	return string(ts)
+}
+
+// IsOpen reports whether the task still has work left
+func (ts WorkOrderTaskStatus) IsOpen() bool {
+	return ts == WorkOrderTaskStatusPending || ts == WorkOrderTaskStatusInProgress
+}
+
+// WorkOrderTask entity (one job on a work order checklist)
+type WorkOrderTask struct {
+	BaseModel
+	WorkOrderID        int                 `json:"work_order_id" db:"work_order_id"`
+	Title              string              `json:"title" db:"title"`
+	EstimatedHours     float64             `json:"estimated_hours" db:"estimated_hours"`
+	AssignedMechanicID *int                `json:"assigned_mechanic_id" db:"assigned_mechanic_id"`
+	Status             WorkOrderTaskStatus `json:"status" db:"status"`
+	Notes              *string             `json:"notes" db:"notes"`
+	SortOrder          int                 `json:"sort_order" db:"sort_order"`
+	CompletedAt        *time.Time          `json:"completed_at" db:"completed_at"`
+	CompletedBy        *int                `json:"completed_by" db:"completed_by"`
+	CreatedBy          int                 `json:"created_by" db:"created_by"`
+	WorkOrder          *WorkOrder          `json:"work_order,omitempty" db:"work_order"`
+}
+
+// WorkOrderStatusHistory entity (one status change of a work order)
+type WorkOrderStatusHistory struct {
+	ID            int             `json:"id" db:"id"`
+	WorkOrderID   int             `json:"work_order_id" db:"work_order_id"`
+	FromStatus    WorkOrderStatus `json:"from_status" db:"from_status"`
+	ToStatus      WorkOrderStatus `json:"to_status" db:"to_status"`
+	Reason        *string         `json:"reason" db:"reason"`
+	ChangedBy     int             `json:"changed_by" db:"changed_by"`
+	ChangedAt     time.Time       `json:"changed_at" db:"changed_at"`
+	ChangedByUser *User           `json:"changed_by_user,omitempty" db:"changed_by_user"`
+}
+
+// Mechanic auto-assignment strategies
+type AssignmentStrategy string
+
+const (
+	AssignmentStrategyLeastWorkload AssignmentStrategy = "least_workload"
+	AssignmentStrategyRoundRobin    AssignmentStrategy = "round_robin"
+	AssignmentStrategySkillMatch    AssignmentStrategy = "skill_match"
+)
+
+func (as AssignmentStrategy) String() string {
+	return string(as)
+}
+
+// MechanicSkill entity (vehicle brand a mechanic specialises in)
+type MechanicSkill struct {
+	ID         int       `json:"id" db:"id"`
+	MechanicID int       `json:"mechanic_id" db:"mechanic_id"`
+	Brand      string    `json:"brand" db:"brand"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// MechanicLeave entity (mechanic is not assigned new work orders)
+type MechanicLeave struct {
+	BaseModel
+	MechanicID int       `json:"mechanic_id" db:"mechanic_id"`
+	StartDate  time.Time `json:"start_date" db:"start_date"`
+	EndDate    time.Time `json:"end_date" db:"end_date"`
+	Reason     *string   `json:"reason" db:"reason"`
+	CreatedBy  int       `json:"created_by" db:"created_by"`
+}
+
+// MechanicWorkload is an active mechanic with their open work orders, used
+// to pick who gets a new work order
+type MechanicWorkload struct {
+	MechanicID     int        `json:"mechanic_id" db:"mechanic_id"`
+	Username       string     `json:"username" db:"username"`
+	FullName       string     `json:"full_name" db:"full_name"`
+	OpenWorkOrders int        `json:"open_work_orders" db:"open_work_orders"`
+	LastAssignedAt *time.Time `json:"last_assigned_at" db:"last_assigned_at"`
+	OnLeave        bool       `json:"on_leave" db:"on_leave"`
+	Brands         []string   `json:"brands" db:"-"`
+}
+
+// MechanicAssignment is the outcome of an automatic assignment
+type MechanicAssignment struct {
+	MechanicID int                `json:"mechanic_id"`
+	Strategy   AssignmentStrategy `json:"strategy"`
+	Reason     string             `json:"reason"`
+}
+
+// MechanicWorkingHours entity (a mechanic's shift on one day of the week)
+type MechanicWorkingHours struct {
+	ID         int       `json:"id" db:"id"`
+	MechanicID int       `json:"mechanic_id" db:"mechanic_id"`
+	DayOfWeek  int       `json:"day_of_week" db:"day_of_week"` // 0 = Sunday
+	StartTime  string    `json:"start_time" db:"start_time"`   // HH:MM
+	EndTime    string    `json:"end_time" db:"end_time"`       // HH:MM
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// Covers reports whether the clock time of t falls within the shift
+func (h *MechanicWorkingHours) Covers(t time.Time) bool {
+	clock := t.Format("15:04")
+	return clock >= h.StartTime && clock <= h.EndTime
+}
+
+// LaborRate entity (hourly labor rate for a skill level)
+type LaborRate struct {
+	ID         int        `json:"id" db:"id"`
+	SkillLevel string     `json:"skill_level" db:"skill_level"`
+	HourlyRate float64    `json:"hourly_rate" db:"hourly_rate"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  *time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// MechanicLaborProfile entity (skill level and optional own hourly rate)
+type MechanicLaborProfile struct {
+	ID         int        `json:"id" db:"id"`
+	MechanicID int        `json:"mechanic_id" db:"mechanic_id"`
+	SkillLevel *string    `json:"skill_level" db:"skill_level"`
+	HourlyRate *float64   `json:"hourly_rate" db:"hourly_rate"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  *time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Labor timer status
+type LaborTimerStatus string
+
+const (
+	LaborTimerStatusRunning LaborTimerStatus = "running"
+	LaborTimerStatusPaused  LaborTimerStatus = "paused"
+	LaborTimerStatusStopped LaborTimerStatus = "stopped"
+)
+
+func (lts LaborTimerStatus) String() string {
+	return string(lts)
+}
+
+// LaborTimeEntry entity (a mechanic's timer on a work order or task)
+type LaborTimeEntry struct {
+	ID               int              `json:"id" db:"id"`
+	WorkOrderID      int              `json:"work_order_id" db:"work_order_id"`
+	WorkOrderTaskID  *int             `json:"work_order_task_id" db:"work_order_task_id"`
+	MechanicID       int              `json:"mechanic_id" db:"mechanic_id"`
+	Status           LaborTimerStatus `json:"status" db:"status"`
+	StartedAt        time.Time        `json:"started_at" db:"started_at"`
+	SegmentStartedAt *time.Time       `json:"segment_started_at" db:"segment_started_at"`
+	EndedAt          *time.Time       `json:"ended_at" db:"ended_at"`
+	WorkedSeconds    int              `json:"worked_seconds" db:"worked_seconds"`
+	HourlyRate       float64          `json:"hourly_rate" db:"hourly_rate"`
+	LaborCost        float64          `json:"labor_cost" db:"labor_cost"`
+	Notes            *string          `json:"notes" db:"notes"`
+	CreatedAt        time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt        *time.Time       `json:"updated_at" db:"updated_at"`
+	WONumber         string           `json:"wo_number,omitempty" db:"wo_number"`
+	TaskTitle        *string          `json:"task_title,omitempty" db:"task_title"`
+}
+
+// ElapsedSeconds returns the worked time including the running segment
+func (e *LaborTimeEntry) ElapsedSeconds(now time.Time) int {
+	if e.Status == LaborTimerStatusRunning && e.SegmentStartedAt != nil {
+		return e.WorkedSeconds + int(now.Sub(*e.SegmentStartedAt).Seconds())
+	}
+	return e.WorkedSeconds
+}
+
+// Warranty status
+type WarrantyStatus string
+
+const (
+	WarrantyStatusActive  WarrantyStatus = "active"
+	WarrantyStatusExpired WarrantyStatus = "expired"
+	WarrantyStatusVoid    WarrantyStatus = "void"
+)
+
+func (ws WarrantyStatus) String() string {
+	return string(ws)
+}
+
+func (ws *WarrantyStatus) Scan(value interface{}) error {
+	if value == nil {
+		*ws = ""
+		return nil
+	}
+	if s, ok := value.(string); ok {
+		*ws = WarrantyStatus(s)
+	}
+	return nil
+}
+
+func (ws WarrantyStatus) Value() (driver.Value, error) {
+	return string(ws), nil
+}
+
+// Warranty entity (post-sale warranty terms for a sold vehicle)
+type Warranty struct {
+	BaseModel
+	WarrantyNumber string         `json:"warranty_number" db:"warranty_number"`
+	SalesInvoiceID int            `json:"sales_invoice_id" db:"sales_invoice_id"`
+	VehicleID      int            `json:"vehicle_id" db:"vehicle_id"`
+	CustomerID     int            `json:"customer_id" db:"customer_id"`
+	DurationMonths int            `json:"duration_months" db:"duration_months"`
+	MileageLimit   *int           `json:"mileage_limit" db:"mileage_limit"`
+	StartMileage   *int           `json:"start_mileage" db:"start_mileage"`
+	Coverage       string         `json:"coverage" db:"coverage"`
+	StartDate      time.Time      `json:"start_date" db:"start_date"`
+	EndDate        time.Time      `json:"end_date" db:"end_date"`
+	Status         WarrantyStatus `json:"status" db:"status"`
+	Notes          *string        `json:"notes" db:"notes"`
+	CreatedBy      int            `json:"created_by" db:"created_by"`
+	Vehicle        *Vehicle       `json:"vehicle,omitempty"`
+	Customer       *Customer      `json:"customer,omitempty"`
+}
+
+// WarrantyClaim entity
+type WarrantyClaim struct {
+	ID             int        `json:"id" db:"id"`
+	ClaimNumber    string     `json:"claim_number" db:"claim_number"`
+	WarrantyID     int        `json:"warranty_id" db:"warranty_id"`
+	WorkOrderID    *int       `json:"work_order_id" db:"work_order_id"`
+	ClaimDate      time.Time  `json:"claim_date" db:"claim_date"`
+	Complaint      string     `json:"complaint" db:"complaint"`
+	CurrentMileage *int       `json:"current_mileage" db:"current_mileage"`
+	CreatedBy      int        `json:"created_by" db:"created_by"`
+	DeletedAt      *time.Time `json:"deleted_at" db:"deleted_at"`
+	DeletedBy      *int       `json:"deleted_by" db:"deleted_by"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	Warranty       *Warranty  `json:"warranty,omitempty"`
+	WorkOrder      *WorkOrder `json:"work_order,omitempty" db:"work_order"`
 }
 
 // SparePart entity
@@ -346,23 +773,326 @@ type SparePart struct {
 	StockQuantity int     `json:"stock_quantity" db:"stock_quantity"`
 	MinStockLevel int     `json:"min_stock_level" db:"min_stock_level"`
 	Unit          string  `json:"unit" db:"unit"`
+	TrackBatches  bool    `json:"track_batches" db:"track_batches"` // stock kept per batch with expiry, issued FEFO
+	TrackSerials  bool    `json:"track_serials" db:"track_serials"` // serial numbers captured on receipt and usage
+}
+
+// SparePartFitment maps a spare part to the vehicles it fits. Empty fields
+// match anything: no brand fits every vehicle, no model every model of the
+// brand, and no year bound leaves that end of the range open.
+type SparePartFitment struct {
+	BaseModel
+	SparePartID int     `json:"spare_part_id" db:"spare_part_id"`
+	Brand       *string `json:"brand" db:"brand"`
+	Model       *string `json:"model" db:"model"`
+	YearFrom    *int    `json:"year_from" db:"year_from"`
+	YearTo      *int    `json:"year_to" db:"year_to"`
+	EngineCode  *string `json:"engine_code" db:"engine_code"` // engine number prefix
+	Notes       *string `json:"notes" db:"notes"`
+	CreatedBy   int     `json:"created_by" db:"created_by"`
+}
+
+// FitmentVehicle is the vehicle a part is matched against: a stock vehicle
+// or a customer's vehicle
+type FitmentVehicle struct {
+	Brand        string  `json:"brand"`
+	Model        string  `json:"model"`
+	Year         *int    `json:"year"`
+	EngineNumber *string `json:"engine_number"`
+}
+
+// CompatiblePartFilter narrows the parts listed for a vehicle
+type CompatiblePartFilter struct {
+	Vehicle          FitmentVehicle
+	Search           string // part code, name or brand
+	InStockOnly      bool
+	IncludeUniversal bool // parts with a fitment for every vehicle
+}
+
+// Fits reports whether the fitment covers the vehicle. Details the vehicle
+// record does not have (year, engine number) are not held against it.
+func (f *SparePartFitment) Fits(vehicle *FitmentVehicle) bool {
+	if f.Brand != nil && !strings.EqualFold(strings.TrimSpace(*f.Brand), strings.TrimSpace(vehicle.Brand)) {
+		return false
+	}
+	if f.Model != nil && !strings.EqualFold(strings.TrimSpace(*f.Model), strings.TrimSpace(vehicle.Model)) {
+		return false
+	}
+	if vehicle.Year != nil {
+		if f.YearFrom != nil && *vehicle.Year < *f.YearFrom {
+			return false
+		}
+		if f.YearTo != nil && *vehicle.Year > *f.YearTo {
+			return false
+		}
+	}
+	if f.EngineCode != nil && vehicle.EngineNumber != nil && *vehicle.EngineNumber != "" {
+		if !strings.HasPrefix(NormalizeEngineCode(*vehicle.EngineNumber), NormalizeEngineCode(*f.EngineCode)) {
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizeEngineCode upper-cases an engine code or number and drops spaces
+// and dashes so "k15b-123" matches "K15B"
+func NormalizeEngineCode(code string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(strings.ToUpper(strings.TrimSpace(code)))
 }
 
 // WorkOrderPart entity
 type WorkOrderPart struct {
-	ID           int       `json:"id" db:"id"`
-	WorkOrderID  int       `json:"work_order_id" db:"work_order_id"`
-	SparePartID  int       `json:"spare_part_id" db:"spare_part_id"`
-	QuantityUsed int       `json:"quantity_used" db:"quantity_used"`
-	UnitCost     float64   `json:"unit_cost" db:"unit_cost"`
-	TotalCost    float64   `json:"total_cost" db:"total_cost"`
-	UsedBy       int       `json:"used_by" db:"used_by"`
-	UsageDate    time.Time `json:"usage_date" db:"usage_date"`
-	DeletedAt    *time.Time `json:"deleted_at" db:"deleted_at"`
-	DeletedBy    *int      `json:"deleted_by" db:"deleted_by"`
-	UsedAt       time.Time `json:"used_at" db:"used_at"`
-	SparePart    *SparePart `json:"spare_part,omitempty"`
-	User         *User     `json:"user,omitempty"`
+	ID               int                   `json:"id" db:"id"`
+	WorkOrderID      int                   `json:"work_order_id" db:"work_order_id"`
+	SparePartID      int                   `json:"spare_part_id" db:"spare_part_id"`
+	QuantityUsed     int                   `json:"quantity_used" db:"quantity_used"`
+	UnitCost         float64               `json:"unit_cost" db:"unit_cost"`
+	TotalCost        float64               `json:"total_cost" db:"total_cost"`
+	UnitPrice        float64               `json:"unit_price" db:"unit_price"` // selling price when used, billed on customer jobs
+	UsedBy           int                   `json:"used_by" db:"used_by"`
+	UsageDate        time.Time             `json:"usage_date" db:"usage_date"`
+	DeletedAt        *time.Time            `json:"deleted_at" db:"deleted_at"`
+	DeletedBy        *int                  `json:"deleted_by" db:"deleted_by"`
+	UsedAt           time.Time             `json:"used_at" db:"used_at"`
+	QuantityReturned int                   `json:"quantity_returned" db:"quantity_returned"` // TotalCost is net of returns
+	LocationID       *int                  `json:"location_id" db:"location_id"`             // stock location the part was issued from
+	ServiceKitID     *int                  `json:"service_kit_id" db:"service_kit_id"`       // kit the part was issued with
+	FitmentWarning   *string               `json:"fitment_warning,omitempty" db:"-"`         // part is not listed as fitting the vehicle
+	SerialNumbers    []string              `json:"serial_numbers,omitempty" db:"-"`          // serials issued, for serial-tracked parts
+	Batches          []*WorkOrderPartBatch `json:"batches,omitempty" db:"-"`                 // batches picked, for batch-tracked parts
+	SparePart        *SparePart            `json:"spare_part,omitempty" db:"spare_part"`
+	User             *User                 `json:"user,omitempty" db:"user"`
+}
+
+// WorkOrderPartBatch is the quantity of a work order part taken from one batch
+type WorkOrderPartBatch struct {
+	ID               int        `json:"id" db:"id"`
+	WorkOrderPartID  int        `json:"work_order_part_id" db:"work_order_part_id"`
+	BatchID          int        `json:"batch_id" db:"batch_id"`
+	Quantity         int        `json:"quantity" db:"quantity"`
+	QuantityReturned int        `json:"quantity_returned" db:"quantity_returned"`
+	BatchNumber      string     `json:"batch_number" db:"batch_number"`
+	ExpiryDate       *time.Time `json:"expiry_date" db:"expiry_date"`
+}
+
+// ServiceKit entity (a bundle of parts and standard labor for a routine job
+// such as a tune-up or brake package)
+type ServiceKit struct {
+	BaseModel
+	KitCode     string            `json:"kit_code" db:"kit_code"`
+	Name        string            `json:"name" db:"name"`
+	Description *string           `json:"description" db:"description"`
+	LaborHours  float64           `json:"labor_hours" db:"labor_hours"` // added to the work order as a task
+	LaborPrice  float64           `json:"labor_price" db:"labor_price"` // added to the labor billed on customer jobs
+	IsActive    bool              `json:"is_active" db:"is_active"`
+	CreatedBy   int               `json:"created_by" db:"created_by"`
+	Items       []*ServiceKitItem `json:"items,omitempty" db:"-"`
+}
+
+// ServiceKitItem entity (one part of a service kit)
+type ServiceKitItem struct {
+	ID           int        `json:"id" db:"id"`
+	ServiceKitID int        `json:"service_kit_id" db:"service_kit_id"`
+	SparePartID  int        `json:"spare_part_id" db:"spare_part_id"`
+	Quantity     int        `json:"quantity" db:"quantity"`
+	SparePart    *SparePart `json:"spare_part,omitempty" db:"spare_part"`
+}
+
+// ServiceKitApplication is the result of applying a kit to a work order
+type ServiceKitApplication struct {
+	WorkOrderID     int              `json:"work_order_id"`
+	ServiceKit      *ServiceKit      `json:"service_kit"`
+	Parts           []*WorkOrderPart `json:"parts"`
+	Task            *WorkOrderTask   `json:"task,omitempty"`
+	LaborPriceAdded float64          `json:"labor_price_added"`
+	FitmentWarnings []string         `json:"fitment_warnings,omitempty"`
+}
+
+// WorkOrderPartReturn entity (unused parts sent back to stock at their original cost)
+type WorkOrderPartReturn struct {
+	ID              int        `json:"id" db:"id"`
+	WorkOrderPartID int        `json:"work_order_part_id" db:"work_order_part_id"`
+	WorkOrderID     int        `json:"work_order_id" db:"work_order_id"`
+	SparePartID     int        `json:"spare_part_id" db:"spare_part_id"`
+	Quantity        int        `json:"quantity" db:"quantity"`
+	UnitCost        float64    `json:"unit_cost" db:"unit_cost"`
+	TotalCost       float64    `json:"total_cost" db:"total_cost"`
+	Reason          string     `json:"reason" db:"reason"`
+	ReturnedBy      int        `json:"returned_by" db:"returned_by"`
+	ReturnedAt      time.Time  `json:"returned_at" db:"returned_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	SerialNumbers   []string   `json:"serial_numbers,omitempty" db:"-"` // serials coming back, for serial-tracked parts
+	SparePart       *SparePart `json:"spare_part,omitempty" db:"spare_part"`
+	ReturnedByUser  *User      `json:"returned_by_user,omitempty" db:"returned_by_user"`
+}
+
+// Work order sublet status
+type SubletStatus string
+
+const (
+	SubletStatusSent      SubletStatus = "sent"      // with the vendor
+	SubletStatusCompleted SubletStatus = "completed" // work returned and vendor invoice received
+	SubletStatusCancelled SubletStatus = "cancelled"
+)
+
+func (ss SubletStatus) String() string {
+	return string(ss)
+}
+
+// WorkOrderSublet entity (work sent to an outside vendor, e.g. body paint or upholstery)
+type WorkOrderSublet struct {
+	BaseModel
+	WorkOrderID         int          `json:"work_order_id" db:"work_order_id"`
+	SupplierID          int          `json:"supplier_id" db:"supplier_id"`
+	Description         string       `json:"description" db:"description"`
+	VendorInvoiceNumber *string      `json:"vendor_invoice_number" db:"vendor_invoice_number"`
+	Cost                float64      `json:"cost" db:"cost"`
+	Status              SubletStatus `json:"status" db:"status"`
+	AttachmentPath      *string      `json:"attachment_path" db:"attachment_path"`
+	AttachmentURL       *string      `json:"attachment_url" db:"-"`
+	Notes               *string      `json:"notes" db:"notes"`
+	CreatedBy           int          `json:"created_by" db:"created_by"`
+	CompletedAt         *time.Time   `json:"completed_at" db:"completed_at"`
+	CompletedBy         *int         `json:"completed_by" db:"completed_by"`
+	SupplierName        string       `json:"supplier_name" db:"supplier_name"`
+	WONumber            string       `json:"wo_number" db:"wo_number"`
+	Payable             *Payable     `json:"payable,omitempty" db:"-"`
+}
+
+// CountsTowardCost reports whether the sublet cost is part of the work order cost
+func (s *WorkOrderSublet) CountsTowardCost() bool {
+	return s.Status != SubletStatusCancelled
+}
+
+// Work order attachment stages
+type AttachmentStage string
+
+const (
+	AttachmentStageBefore AttachmentStage = "before"
+	AttachmentStageDuring AttachmentStage = "during"
+	AttachmentStageAfter  AttachmentStage = "after"
+)
+
+func (as AttachmentStage) String() string {
+	return string(as)
+}
+
+// Work order attachment file types
+type AttachmentFileType string
+
+const (
+	AttachmentFileTypePhoto    AttachmentFileType = "photo"
+	AttachmentFileTypeDocument AttachmentFileType = "document"
+)
+
+// WorkOrderAttachment entity (photo or document documenting the work, optionally on one task)
+type WorkOrderAttachment struct {
+	ID              int                `json:"id" db:"id"`
+	WorkOrderID     int                `json:"work_order_id" db:"work_order_id"`
+	WorkOrderTaskID *int               `json:"work_order_task_id" db:"work_order_task_id"`
+	Stage           AttachmentStage    `json:"stage" db:"stage"`
+	FileType        AttachmentFileType `json:"file_type" db:"file_type"`
+	FilePath        string             `json:"file_path" db:"file_path"`
+	FileURL         string             `json:"file_url" db:"-"`
+	Caption         *string            `json:"caption" db:"caption"`
+	UploadedBy      int                `json:"uploaded_by" db:"uploaded_by"`
+	DeletedAt       *time.Time         `json:"deleted_at" db:"deleted_at"`
+	DeletedBy       *int               `json:"deleted_by" db:"deleted_by"`
+	CreatedAt       time.Time          `json:"created_at" db:"created_at"`
+	Uploader        *User              `json:"uploader,omitempty" db:"uploader"`
+}
+
+// WorkshopBay entity (a repair bay or lift work is scheduled on)
+type WorkshopBay struct {
+	BaseModel
+	Code     string  `json:"code" db:"code"`
+	Name     string  `json:"name" db:"name"`
+	IsActive bool    `json:"is_active" db:"is_active"`
+	Notes    *string `json:"notes" db:"notes"`
+}
+
+// WorkOrderSchedule entity (planned time for a work order or one of its
+// tasks, on a bay and with a mechanic)
+type WorkOrderSchedule struct {
+	BaseModel
+	WorkOrderID        int                  `json:"work_order_id" db:"work_order_id"`
+	WorkOrderTaskID    *int                 `json:"work_order_task_id" db:"work_order_task_id"`
+	BayID              *int                 `json:"bay_id" db:"bay_id"`
+	MechanicID         *int                 `json:"mechanic_id" db:"mechanic_id"`
+	PlannedStart       time.Time            `json:"planned_start" db:"planned_start"`
+	PlannedEnd         time.Time            `json:"planned_end" db:"planned_end"`
+	Notes              *string              `json:"notes" db:"notes"`
+	CreatedBy          int                  `json:"created_by" db:"created_by"`
+	WONumber           string               `json:"wo_number" db:"wo_number"`
+	WorkOrderStatus    WorkOrderStatus      `json:"work_order_status" db:"work_order_status"`
+	ProgressPercentage int                  `json:"progress_percentage" db:"progress_percentage"`
+	VehicleLabel       string               `json:"vehicle_label" db:"vehicle_label"`
+	TaskTitle          *string              `json:"task_title" db:"task_title"`
+	TaskStatus         *WorkOrderTaskStatus `json:"task_status" db:"task_status"`
+	BayName            *string              `json:"bay_name" db:"bay_name"`
+	MechanicName       *string              `json:"mechanic_name" db:"mechanic_name"`
+	IsDelayed          bool                 `json:"is_delayed" db:"-"`
+}
+
+// IsOpen reports whether the scheduled work still has to be done
+func (ws *WorkOrderSchedule) IsOpen() bool {
+	if ws.WorkOrderStatus == WorkOrderStatusCompleted || ws.WorkOrderStatus == WorkOrderStatusCancelled {
+		return false
+	}
+	return ws.TaskStatus == nil || ws.TaskStatus.IsOpen()
+}
+
+// Overlaps reports whether the schedule overlaps the period [start, end)
+func (ws *WorkOrderSchedule) Overlaps(start, end time.Time) bool {
+	return ws.PlannedStart.Before(end) && ws.PlannedEnd.After(start)
+}
+
+// ScheduleCalendar is the planned work in a day or week
+type ScheduleCalendar struct {
+	View      string                 `json:"view"`
+	StartDate time.Time              `json:"start_date"`
+	EndDate   time.Time              `json:"end_date"`
+	Days      []*ScheduleCalendarDay `json:"days"`
+}
+
+// ScheduleCalendarDay is one day of a calendar with the work planned on it
+type ScheduleCalendarDay struct {
+	Date    time.Time            `json:"date"`
+	Entries []*WorkOrderSchedule `json:"entries"`
+}
+
+// Part request status
+type PartRequestStatus string
+
+const (
+	PartRequestStatusPending           PartRequestStatus = "pending"
+	PartRequestStatusApproved          PartRequestStatus = "approved"
+	PartRequestStatusPartiallyApproved PartRequestStatus = "partially_approved"
+	PartRequestStatusRejected          PartRequestStatus = "rejected"
+)
+
+func (prs PartRequestStatus) String() string {
+	return string(prs)
+}
+
+// PartRequest entity (mechanic asks the parts counter for stock)
+type PartRequest struct {
+	BaseModel
+	RequestNumber     string            `json:"request_number" db:"request_number"`
+	WorkOrderID       int               `json:"work_order_id" db:"work_order_id"`
+	SparePartID       int               `json:"spare_part_id" db:"spare_part_id"`
+	QuantityRequested int               `json:"quantity_requested" db:"quantity_requested"`
+	QuantityIssued    int               `json:"quantity_issued" db:"quantity_issued"`
+	Status            PartRequestStatus `json:"status" db:"status"`
+	Notes             *string           `json:"notes" db:"notes"`
+	RequestedBy       int               `json:"requested_by" db:"requested_by"`
+	ReviewedBy        *int              `json:"reviewed_by" db:"reviewed_by"`
+	ReviewedAt        *time.Time        `json:"reviewed_at" db:"reviewed_at"`
+	ReviewNotes       *string           `json:"review_notes" db:"review_notes"`
+	FitmentWarning    *string           `json:"fitment_warning,omitempty" db:"-"`
+	WorkOrder         *WorkOrder        `json:"work_order,omitempty" db:"work_order"`
+	SparePart         *SparePart        `json:"spare_part,omitempty" db:"spare_part"`
+	Requester         *User             `json:"requester,omitempty" db:"requester"`
 }
 
 // Stock movement types and references
@@ -376,6 +1106,8 @@ const (
 	ReferenceTypeWorkOrder  ReferenceType = "work_order"
 	ReferenceTypePurchase   ReferenceType = "purchase"
 	ReferenceTypeAdjustment ReferenceType = "adjustment"
+	ReferenceTypeStockCount ReferenceType = "stock_count"
+	ReferenceTypeTransfer   ReferenceType = "transfer"
 )
 
 func (mt MovementType) String() string {
@@ -424,6 +1156,7 @@ type StockMovement struct {
 	Quantity      int           `json:"quantity" db:"quantity"`
 	ReferenceType ReferenceType `json:"reference_type" db:"reference_type"`
 	ReferenceID   *int          `json:"reference_id" db:"reference_id"`
+	LocationID    *int          `json:"location_id" db:"location_id"`
 	Notes         *string       `json:"notes" db:"notes"`
 	CreatedBy     int           `json:"created_by" db:"created_by"`
 	MovementDate  time.Time     `json:"movement_date" db:"movement_date"`
@@ -436,14 +1169,748 @@ type StockMovement struct {
 	Creator       *User         `json:"creator,omitempty"`
 }
 
+// StockAdjustment is a manual stock correction at one location. A negative
+// quantity takes stock out.
+type StockAdjustment struct {
+	SparePartID   int
+	LocationID    *int // default location when nil
+	Quantity      int
+	SerialNumbers []string // one per unit added or removed, for serial-tracked parts
+	Notes         string
+	AdjustedBy    int
+	AdjustedAt    time.Time
+}
+
+// Inventory costing methods
+type CostingMethod string
+
+const (
+	CostingMethodAverage CostingMethod = "average"
+	CostingMethodFIFO    CostingMethod = "fifo"
+)
+
+func (cm CostingMethod) String() string {
+	return string(cm)
+}
+
+// StockCostLayer entity (received quantity still valued at its own unit cost)
+type StockCostLayer struct {
+	ID                int           `json:"id" db:"id"`
+	SparePartID       int           `json:"spare_part_id" db:"spare_part_id"`
+	ReferenceType     ReferenceType `json:"reference_type" db:"reference_type"`
+	ReferenceID       *int          `json:"reference_id" db:"reference_id"`
+	ReceivedDate      time.Time     `json:"received_date" db:"received_date"`
+	QuantityReceived  int           `json:"quantity_received" db:"quantity_received"`
+	QuantityRemaining int           `json:"quantity_remaining" db:"quantity_remaining"`
+	UnitCost          float64       `json:"unit_cost" db:"unit_cost"`
+	CreatedAt         time.Time     `json:"created_at" db:"created_at"`
+}
+
+// Stock count (stock opname) status
+type StockCountStatus string
+
+const (
+	StockCountStatusOpen      StockCountStatus = "open"
+	StockCountStatusSubmitted StockCountStatus = "submitted"
+	StockCountStatusApproved  StockCountStatus = "approved"
+	StockCountStatusCancelled StockCountStatus = "cancelled"
+)
+
+func (scs StockCountStatus) String() string {
+	return string(scs)
+}
+
+// StockCount entity (physical count session; frozen once approved)
+type StockCount struct {
+	BaseModel
+	CountNumber   string            `json:"count_number" db:"count_number"`
+	Status        StockCountStatus  `json:"status" db:"status"`
+	LocationID    int               `json:"location_id" db:"location_id"`
+	Location      *string           `json:"location" db:"location"` // area or shelf label within the location
+	Category      *string           `json:"category" db:"category"`
+	Notes         *string           `json:"notes" db:"notes"`
+	CreatedBy     int               `json:"created_by" db:"created_by"`
+	SubmittedBy   *int              `json:"submitted_by" db:"submitted_by"`
+	SubmittedAt   *time.Time        `json:"submitted_at" db:"submitted_at"`
+	ApprovedBy    *int              `json:"approved_by" db:"approved_by"`
+	ApprovedAt    *time.Time        `json:"approved_at" db:"approved_at"`
+	TotalItems    int               `json:"total_items" db:"total_items"`
+	CountedItems  int               `json:"counted_items" db:"counted_items"`
+	VarianceItems int               `json:"variance_items" db:"variance_items"`
+	GainValue     float64           `json:"gain_value" db:"gain_value"` // counted above expected, at snapshot cost
+	LossValue     float64           `json:"loss_value" db:"loss_value"` // counted below expected, at snapshot cost
+	NetValue      float64           `json:"net_value" db:"net_value"`   // gain less loss
+	StockLocation *StockLocation    `json:"stock_location,omitempty" db:"stock_location"`
+	Items         []*StockCountItem `json:"items,omitempty"`
+}
+
+// StockCountItem entity (expected quantity snapshot and the physical count)
+type StockCountItem struct {
+	ID               int        `json:"id" db:"id"`
+	StockCountID     int        `json:"stock_count_id" db:"stock_count_id"`
+	SparePartID      int        `json:"spare_part_id" db:"spare_part_id"`
+	ExpectedQuantity int        `json:"expected_quantity" db:"expected_quantity"`
+	UnitCost         float64    `json:"unit_cost" db:"unit_cost"`
+	CountedQuantity  *int       `json:"counted_quantity" db:"counted_quantity"` // nil until counted
+	CountedBy        *int       `json:"counted_by" db:"counted_by"`
+	CountedAt        *time.Time `json:"counted_at" db:"counted_at"`
+	VarianceQuantity int        `json:"variance_quantity" db:"variance_quantity"`
+	VarianceValue    float64    `json:"variance_value" db:"variance_value"`
+	PostedValue      *float64   `json:"posted_value" db:"posted_value"` // set when the count is approved
+	Notes            *string    `json:"notes" db:"notes"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+	SparePart        *SparePart `json:"spare_part,omitempty" db:"spare_part"`
+}
+
+// Stock location types
+type StockLocationType string
+
+const (
+	StockLocationTypeWarehouse StockLocationType = "warehouse"
+	StockLocationTypeWorkshop  StockLocationType = "workshop"
+	StockLocationTypeBranch    StockLocationType = "branch"
+	StockLocationTypeTransit   StockLocationType = "transit" // holds stock on transfers that are on the way
+)
+
+func (slt StockLocationType) String() string {
+	return string(slt)
+}
+
+// StockLocation entity (store, workshop floor, branch)
+type StockLocation struct {
+	BaseModel
+	Code         string            `json:"code" db:"code"`
+	Name         string            `json:"name" db:"name"`
+	LocationType StockLocationType `json:"location_type" db:"location_type"`
+	IsDefault    bool              `json:"is_default" db:"is_default"` // used when a stock transaction names no location
+	IsActive     bool              `json:"is_active" db:"is_active"`
+	Notes        *string           `json:"notes" db:"notes"`
+}
+
+// SparePartBatch entity (stock of one received lot of a part at one
+// location). Batch-tracked parts are issued first-expired-first-out.
+type SparePartBatch struct {
+	ID                int            `json:"id" db:"id"`
+	SparePartID       int            `json:"spare_part_id" db:"spare_part_id"`
+	LocationID        int            `json:"location_id" db:"location_id"`
+	BatchNumber       string         `json:"batch_number" db:"batch_number"`
+	ExpiryDate        *time.Time     `json:"expiry_date" db:"expiry_date"` // nil = does not expire, picked last
+	ReceivedDate      time.Time      `json:"received_date" db:"received_date"`
+	QuantityReceived  int            `json:"quantity_received" db:"quantity_received"`
+	QuantityRemaining int            `json:"quantity_remaining" db:"quantity_remaining"`
+	ReferenceType     ReferenceType  `json:"reference_type" db:"reference_type"`
+	ReferenceID       *int           `json:"reference_id" db:"reference_id"`
+	CreatedAt         time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at" db:"updated_at"`
+	SparePart         *SparePart     `json:"spare_part,omitempty" db:"spare_part"`
+	Location          *StockLocation `json:"location,omitempty" db:"location"`
+}
+
+// IsExpired reports whether the batch expired before the given day
+func (b *SparePartBatch) IsExpired(on time.Time) bool {
+	if b.ExpiryDate == nil {
+		return false
+	}
+	day := time.Date(on.Year(), on.Month(), on.Day(), 0, 0, 0, 0, b.ExpiryDate.Location())
+	return b.ExpiryDate.Before(day)
+}
+
+// Spare part serial status
+type SerialStatus string
+
+const (
+	SerialStatusInStock    SerialStatus = "in_stock"
+	SerialStatusIssued     SerialStatus = "issued"
+	SerialStatusWrittenOff SerialStatus = "written_off" // taken out of stock by an adjustment
+)
+
+func (ss SerialStatus) String() string {
+	return string(ss)
+}
+
+// SparePartSerial entity (one serial-numbered unit of a part). Issued
+// serials point at the work order part line; the work order and vehicle
+// fields are filled from it when listing.
+type SparePartSerial struct {
+	ID                int           `json:"id" db:"id"`
+	SparePartID       int           `json:"spare_part_id" db:"spare_part_id"`
+	SerialNumber      string        `json:"serial_number" db:"serial_number"`
+	Status            SerialStatus  `json:"status" db:"status"`
+	ReferenceType     ReferenceType `json:"reference_type" db:"reference_type"` // how the unit was received
+	ReferenceID       *int          `json:"reference_id" db:"reference_id"`
+	ReceivedDate      time.Time     `json:"received_date" db:"received_date"`
+	LocationID        *int          `json:"location_id" db:"location_id"` // stock location while in stock
+	WorkOrderPartID   *int          `json:"work_order_part_id" db:"work_order_part_id"`
+	IssuedAt          *time.Time    `json:"issued_at" db:"issued_at"`
+	CreatedAt         time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time     `json:"updated_at" db:"updated_at"`
+	WorkOrderID       *int          `json:"work_order_id,omitempty" db:"work_order_id"`
+	WONumber          *string       `json:"wo_number,omitempty" db:"wo_number"`
+	VehicleID         *int          `json:"vehicle_id,omitempty" db:"vehicle_id"`                   // stock vehicle the unit went into
+	CustomerVehicleID *int          `json:"customer_vehicle_id,omitempty" db:"customer_vehicle_id"` // customer vehicle the unit went into
+	PlateNumber       *string       `json:"plate_number,omitempty" db:"plate_number"`
+	VehicleBrand      *string       `json:"vehicle_brand,omitempty" db:"vehicle_brand"`
+	VehicleModel      *string       `json:"vehicle_model,omitempty" db:"vehicle_model"`
+	SparePart         *SparePart    `json:"spare_part,omitempty" db:"spare_part"`
+}
+
+// SparePartStock entity (stock of one part at one location)
+type SparePartStock struct {
+	ID            int            `json:"id" db:"id"`
+	SparePartID   int            `json:"spare_part_id" db:"spare_part_id"`
+	LocationID    int            `json:"location_id" db:"location_id"`
+	Bin           *string        `json:"bin" db:"bin"`
+	Quantity      int            `json:"quantity" db:"quantity"`
+	MinStockLevel *int           `json:"min_stock_level" db:"min_stock_level"` // nil = not monitored at this location
+	CreatedAt     time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at" db:"updated_at"`
+	SparePart     *SparePart     `json:"spare_part,omitempty" db:"spare_part"`
+	Location      *StockLocation `json:"location,omitempty" db:"location"`
+}
+
+// IsLowStock reports whether the location is at or below its own minimum
+func (sps *SparePartStock) IsLowStock() bool {
+	return sps.MinStockLevel != nil && sps.Quantity <= *sps.MinStockLevel
+}
+
+// Stock transfer status
+type StockTransferStatus string
+
+const (
+	StockTransferStatusDraft     StockTransferStatus = "draft"
+	StockTransferStatusInTransit StockTransferStatus = "in_transit"
+	StockTransferStatusReceived  StockTransferStatus = "received"
+	StockTransferStatusCancelled StockTransferStatus = "cancelled"
+)
+
+func (sts StockTransferStatus) String() string {
+	return string(sts)
+}
+
+// StockTransfer entity (document moving stock between locations)
+type StockTransfer struct {
+	BaseModel
+	TransferNumber string               `json:"transfer_number" db:"transfer_number"`
+	FromLocationID int                  `json:"from_location_id" db:"from_location_id"`
+	ToLocationID   int                  `json:"to_location_id" db:"to_location_id"`
+	Status         StockTransferStatus  `json:"status" db:"status"`
+	Notes          *string              `json:"notes" db:"notes"`
+	CreatedBy      int                  `json:"created_by" db:"created_by"`
+	ShippedBy      *int                 `json:"shipped_by" db:"shipped_by"`
+	ShippedAt      *time.Time           `json:"shipped_at" db:"shipped_at"`
+	ReceivedBy     *int                 `json:"received_by" db:"received_by"`
+	ReceivedAt     *time.Time           `json:"received_at" db:"received_at"`
+	FromLocation   *StockLocation       `json:"from_location,omitempty" db:"from_location"`
+	ToLocation     *StockLocation       `json:"to_location,omitempty" db:"to_location"`
+	Items          []*StockTransferItem `json:"items,omitempty"`
+}
+
+// StockTransferItem entity
+type StockTransferItem struct {
+	ID              int        `json:"id" db:"id"`
+	StockTransferID int        `json:"stock_transfer_id" db:"stock_transfer_id"`
+	SparePartID     int        `json:"spare_part_id" db:"spare_part_id"`
+	Quantity        int        `json:"quantity" db:"quantity"`
+	SerialNumbers   []string   `json:"serial_numbers,omitempty" db:"-"` // one per unit for serial-tracked parts
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	SparePart       *SparePart `json:"spare_part,omitempty" db:"spare_part"`
+}
+
+// Purchase order status
+type PurchaseOrderStatus string
+
+const (
+	PurchaseOrderStatusDraft             PurchaseOrderStatus = "draft"
+	PurchaseOrderStatusOrdered           PurchaseOrderStatus = "ordered"
+	PurchaseOrderStatusPartiallyReceived PurchaseOrderStatus = "partially_received"
+	PurchaseOrderStatusReceived          PurchaseOrderStatus = "received"
+	PurchaseOrderStatusCancelled         PurchaseOrderStatus = "cancelled"
+)
+
+func (pos PurchaseOrderStatus) String() string {
+	return string(pos)
+}
+
+func (pos *PurchaseOrderStatus) Scan(value interface{}) error {
+	if value == nil {
+		*pos = ""
+		return nil
+	}
+	if s, ok := value.(string); ok {
+		*pos = PurchaseOrderStatus(s)
+	}
+	return nil
+}
+
+func (pos PurchaseOrderStatus) Value() (driver.Value, error) {
+	return string(pos), nil
+}
+
+// PurchaseOrder entity (spare part order to a supplier)
+type PurchaseOrder struct {
+	BaseModel
+	PONumber      string               `json:"po_number" db:"po_number"`
+	SupplierID    int                  `json:"supplier_id" db:"supplier_id"`
+	Status        PurchaseOrderStatus  `json:"status" db:"status"`
+	OrderDate     *time.Time           `json:"order_date" db:"order_date"`
+	ExpectedDate  *time.Time           `json:"expected_date" db:"expected_date"`
+	TotalAmount   float64              `json:"total_amount" db:"total_amount"`
+	Notes         *string              `json:"notes" db:"notes"`
+	AutoGenerated bool                 `json:"auto_generated" db:"auto_generated"` // drafted from reorder suggestions
+	CreatedBy     int                  `json:"created_by" db:"created_by"`
+	Supplier      *Supplier            `json:"supplier,omitempty"`
+	Items         []*PurchaseOrderItem `json:"items,omitempty"`
+}
+
+// PurchaseOrderItem entity
+type PurchaseOrderItem struct {
+	ID               int            `json:"id" db:"id"`
+	PurchaseOrderID  int            `json:"purchase_order_id" db:"purchase_order_id"`
+	SparePartID      int            `json:"spare_part_id" db:"spare_part_id"`
+	QuantityOrdered  int            `json:"quantity_ordered" db:"quantity_ordered"`
+	QuantityReceived int            `json:"quantity_received" db:"quantity_received"`
+	UnitCost         float64        `json:"unit_cost" db:"unit_cost"`
+	TotalCost        float64        `json:"total_cost" db:"total_cost"`
+	DeletedAt        *time.Time     `json:"deleted_at" db:"deleted_at"`
+	DeletedBy        *int           `json:"deleted_by" db:"deleted_by"`
+	CreatedAt        time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt        *time.Time     `json:"updated_at" db:"updated_at"`
+	SparePart        *SparePart     `json:"spare_part,omitempty" db:"spare_part"`
+	PurchaseOrder    *PurchaseOrder `json:"purchase_order,omitempty" db:"purchase_order"`
+}
+
+// GoodsReceipt entity (one delivery against a purchase order)
+type GoodsReceipt struct {
+	ID                    int                 `json:"id" db:"id"`
+	ReceiptNumber         string              `json:"receipt_number" db:"receipt_number"`
+	PurchaseOrderID       int                 `json:"purchase_order_id" db:"purchase_order_id"`
+	SupplierInvoiceNumber *string             `json:"supplier_invoice_number" db:"supplier_invoice_number"`
+	ReceivedDate          time.Time           `json:"received_date" db:"received_date"`
+	Notes                 *string             `json:"notes" db:"notes"`
+	ReceivedBy            int                 `json:"received_by" db:"received_by"`
+	LocationID            *int                `json:"location_id" db:"location_id"` // receiving location, nil = default
+	DeletedAt             *time.Time          `json:"deleted_at" db:"deleted_at"`
+	DeletedBy             *int                `json:"deleted_by" db:"deleted_by"`
+	CreatedAt             time.Time           `json:"created_at" db:"created_at"`
+	Items                 []*GoodsReceiptItem `json:"items,omitempty"`
+}
+
+// GoodsReceiptItem entity
+type GoodsReceiptItem struct {
+	ID                  int        `json:"id" db:"id"`
+	GoodsReceiptID      int        `json:"goods_receipt_id" db:"goods_receipt_id"`
+	PurchaseOrderItemID int        `json:"purchase_order_item_id" db:"purchase_order_item_id"`
+	SparePartID         int        `json:"spare_part_id" db:"spare_part_id"`
+	Quantity            int        `json:"quantity" db:"quantity"`
+	UnitCost            float64    `json:"unit_cost" db:"unit_cost"`
+	TotalCost           float64    `json:"total_cost" db:"total_cost"`
+	BatchNumber         *string    `json:"batch_number" db:"batch_number"` // batch-tracked parts, default the receipt number
+	ExpiryDate          *time.Time `json:"expiry_date" db:"expiry_date"`
+	SerialNumbers       []string   `json:"serial_numbers,omitempty" db:"-"` // one per unit for serial-tracked parts
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+	SparePart           *SparePart `json:"spare_part,omitempty" db:"spare_part"`
+}
+
+// ReplenishmentPolicy holds the settings reorder points are derived from
+type ReplenishmentPolicy struct {
+	UsageWindowDays     int `json:"usage_window_days"`      // days of history average daily usage is taken over
+	SafetyStockDays     int `json:"safety_stock_days"`      // default days of usage kept as safety stock
+	CoverDays           int `json:"cover_days"`             // days of usage an order should cover beyond the reorder point
+	DefaultLeadTimeDays int `json:"default_lead_time_days"` // lead time for parts without a supplier
+}
+
+// ReorderSuggestion is the replenishment view of one spare part
+type ReorderSuggestion struct {
+	SparePartID         int     `json:"spare_part_id" db:"spare_part_id"`
+	PartCode            string  `json:"part_code" db:"part_code"`
+	Name                string  `json:"name" db:"name"`
+	Unit                string  `json:"unit" db:"unit"`
+	CostPrice           float64 `json:"cost_price" db:"cost_price"`
+	StockQuantity       int     `json:"stock_quantity" db:"stock_quantity"`
+	MinStockLevel       int     `json:"min_stock_level" db:"min_stock_level"`
+	OnOrderQuantity     int     `json:"on_order_quantity" db:"on_order_quantity"` // outstanding on draft and open purchase orders
+	UsageQuantity       int     `json:"usage_quantity" db:"usage_quantity"`       // net usage over the usage window
+	AutoReorder         bool    `json:"auto_reorder" db:"auto_reorder"`
+	PreferredSupplierID *int    `json:"preferred_supplier_id" db:"preferred_supplier_id"`
+	SupplierID          *int    `json:"supplier_id" db:"supplier_id"` // preferred supplier, else the last one ordered from
+	SupplierName        *string `json:"supplier_name" db:"supplier_name"`
+	LeadTimeDays        int     `json:"lead_time_days" db:"lead_time_days"`
+	SafetyStockDays     int     `json:"safety_stock_days" db:"safety_stock_days"`
+	AverageDailyUsage   float64 `json:"average_daily_usage"`
+	SafetyStock         int     `json:"safety_stock"`
+	ReorderPoint        int     `json:"reorder_point"`
+	ReorderQuantity     int     `json:"reorder_quantity"`
+	NeedsReorder        bool    `json:"needs_reorder"`
+}
+
+// ReorderSettings are the per-part replenishment settings
+type ReorderSettings struct {
+	SparePartID         int  `json:"spare_part_id"`
+	PreferredSupplierID *int `json:"preferred_supplier_id"`
+	SafetyStockDays     *int `json:"safety_stock_days"` // nil = policy default
+	AutoReorder         bool `json:"auto_reorder"`
+}
+
+// ReorderRun is the result of generating draft purchase orders
+type ReorderRun struct {
+	PurchaseOrders []*PurchaseOrder     `json:"purchase_orders"`
+	Unassigned     []*ReorderSuggestion `json:"unassigned"` // parts to reorder that have no supplier
+	Failed         []*ReorderFailure    `json:"failed"`     // suppliers whose draft could not be created
+}
+
+// ReorderFailure is a supplier whose draft purchase order failed in a
+// reorder run; its parts stay in the suggestions for the next run
+type ReorderFailure struct {
+	SupplierID   int     `json:"supplier_id"`
+	SupplierName *string `json:"supplier_name"`
+	Error        string  `json:"error"`
+}
+
+// BarcodeSymbology enum
+type BarcodeSymbology string
+
+const (
+	BarcodeSymbologyEAN13   BarcodeSymbology = "ean13"   // 13 digits with check digit, in-store prefix
+	BarcodeSymbologyCode128 BarcodeSymbology = "code128" // the part code itself
+)
+
+func (bs BarcodeSymbology) String() string {
+	return string(bs)
+}
+
+// BarcodeSettings controls how barcodes are assigned to spare parts
+type BarcodeSettings struct {
+	Symbology   BarcodeSymbology
+	EAN13Prefix string // 2-3 digit in-store prefix (20-29 are reserved for internal use)
+	AutoAssign  bool   // assign a barcode to new parts created without one
+}
+
+// LabelFormat describes label stock: a sheet of labels or a roll of single
+// labels for a thermal printer. Sizes are in millimetres.
+type LabelFormat struct {
+	Code        string  `json:"code"`
+	Name        string  `json:"name"`
+	PageWidth   float64 `json:"page_width"`
+	PageHeight  float64 `json:"page_height"`
+	LabelWidth  float64 `json:"label_width"`
+	LabelHeight float64 `json:"label_height"`
+	Columns     int     `json:"columns"`
+	Rows        int     `json:"rows"`
+	MarginLeft  float64 `json:"margin_left"`
+	MarginTop   float64 `json:"margin_top"`
+	GapX        float64 `json:"gap_x"`
+	GapY        float64 `json:"gap_y"`
+}
+
+// LabelsPerPage returns how many labels fit on one page
+func (lf *LabelFormat) LabelsPerPage() int {
+	return lf.Columns * lf.Rows
+}
+
+// Vehicle label type
+type VehicleLabelType string
+
+const (
+	VehicleLabelTypeKeyTag     VehicleLabelType = "key_tag"
+	VehicleLabelTypeWindshield VehicleLabelType = "windshield"
+)
+
+func (vlt VehicleLabelType) String() string {
+	return string(vlt)
+}
+
+// LabelItem is one record to print and how many copies
+type LabelItem struct {
+	ID     int `json:"id"`
+	Copies int `json:"copies"`
+}
+
+// LabelPrintRequest describes a label sheet to print
+type LabelPrintRequest struct {
+	Format        string  // label format code
+	LabelWidth    float64 // custom thermal label size, used with the "thermal" format
+	LabelHeight   float64
+	StartPosition int // first free label on a partly used sheet, 1-based
+	Items         []LabelItem
+}
+
+// PayableStatus enum
+type PayableStatus string
+
+const (
+	PayableStatusUnpaid        PayableStatus = "unpaid"
+	PayableStatusPartiallyPaid PayableStatus = "partially_paid"
+	PayableStatusPaid          PayableStatus = "paid"
+)
+
+func (ps PayableStatus) String() string {
+	return string(ps)
+}
+
+func (ps *PayableStatus) Scan(value interface{}) error {
+	if value == nil {
+		*ps = ""
+		return nil
+	}
+	if s, ok := value.(string); ok {
+		*ps = PayableStatus(s)
+	}
+	return nil
+}
+
+func (ps PayableStatus) Value() (driver.Value, error) {
+	return string(ps), nil
+}
+
+// Payable entity (amount owed on a purchase invoice)
+type Payable struct {
+	BaseModel
+	PurchaseInvoiceID *int              `json:"purchase_invoice_id" db:"purchase_invoice_id"`
+	ConsignmentID     *int              `json:"consignment_id" db:"consignment_id"`
+	WorkOrderSubletID *int              `json:"work_order_sublet_id" db:"work_order_sublet_id"`
+	Amount            float64           `json:"amount" db:"amount"`
+	PaidAmount        float64           `json:"paid_amount" db:"paid_amount"`
+	DueDate           time.Time         `json:"due_date" db:"due_date"`
+	Status            PayableStatus     `json:"status" db:"status"`
+	OverdueNotifiedAt *time.Time        `json:"overdue_notified_at" db:"overdue_notified_at"`
+	Notes             *string           `json:"notes" db:"notes"`
+	InvoiceNumber     string            `json:"invoice_number" db:"invoice_number"`
+	CounterpartyType  TransactionType   `json:"counterparty_type" db:"counterparty_type"`
+	CounterpartyID    int               `json:"counterparty_id" db:"counterparty_id"`
+	CounterpartyName  string            `json:"counterparty_name" db:"counterparty_name"`
+	Payments          []*PayablePayment `json:"payments,omitempty"`
+}
+
+// OutstandingAmount returns the unpaid balance of the payable
+func (p *Payable) OutstandingAmount() float64 {
+	return p.Amount - p.PaidAmount
+}
+
+// PayablePayment entity (one transfer or cash payment against a payable)
+type PayablePayment struct {
+	BaseModel
+	PaymentNumber string        `json:"payment_number" db:"payment_number"`
+	PayableID     int           `json:"payable_id" db:"payable_id"`
+	Amount        float64       `json:"amount" db:"amount"`
+	PaymentDate   time.Time     `json:"payment_date" db:"payment_date"`
+	PaymentMethod PaymentMethod `json:"payment_method" db:"payment_method"`
+	TransferProof *string       `json:"transfer_proof" db:"transfer_proof"`
+	Notes         *string       `json:"notes" db:"notes"`
+	CreatedBy     int           `json:"created_by" db:"created_by"`
+}
+
+// Service invoice status
+type ServiceInvoiceStatus string
+
+const (
+	ServiceInvoiceStatusUnpaid        ServiceInvoiceStatus = "unpaid"
+	ServiceInvoiceStatusPartiallyPaid ServiceInvoiceStatus = "partially_paid"
+	ServiceInvoiceStatusPaid          ServiceInvoiceStatus = "paid"
+)
+
+func (sis ServiceInvoiceStatus) String() string {
+	return string(sis)
+}
+
+// Service invoice item types
+type ServiceInvoiceItemType string
+
+const (
+	ServiceInvoiceItemTypePart  ServiceInvoiceItemType = "part"
+	ServiceInvoiceItemTypeLabor ServiceInvoiceItemType = "labor"
+)
+
+// ServiceInvoice entity (bill to the customer for a customer service work order)
+type ServiceInvoice struct {
+	BaseModel
+	InvoiceNumber  string                   `json:"invoice_number" db:"invoice_number"`
+	WorkOrderID    int                      `json:"work_order_id" db:"work_order_id"`
+	CustomerID     int                      `json:"customer_id" db:"customer_id"`
+	InvoiceDate    time.Time                `json:"invoice_date" db:"invoice_date"`
+	DueDate        time.Time                `json:"due_date" db:"due_date"`
+	PartsAmount    float64                  `json:"parts_amount" db:"parts_amount"`
+	LaborAmount    float64                  `json:"labor_amount" db:"labor_amount"`
+	DiscountAmount float64                  `json:"discount_amount" db:"discount_amount"`
+	TotalAmount    float64                  `json:"total_amount" db:"total_amount"`
+	PaidAmount     float64                  `json:"paid_amount" db:"paid_amount"`
+	Status         ServiceInvoiceStatus     `json:"status" db:"status"`
+	Notes          *string                  `json:"notes" db:"notes"`
+	CreatedBy      int                      `json:"created_by" db:"created_by"`
+	WONumber       string                   `json:"wo_number" db:"wo_number"`
+	CustomerName   string                   `json:"customer_name" db:"customer_name"`
+	Items          []*ServiceInvoiceItem    `json:"items,omitempty" db:"-"`
+	Payments       []*ServiceInvoicePayment `json:"payments,omitempty" db:"-"`
+}
+
+// OutstandingAmount returns the unpaid balance of the service invoice
+func (si *ServiceInvoice) OutstandingAmount() float64 {
+	return si.TotalAmount - si.PaidAmount
+}
+
+// ServiceInvoiceItem entity (one part or labor line on a service invoice)
+type ServiceInvoiceItem struct {
+	ID               int                    `json:"id" db:"id"`
+	ServiceInvoiceID int                    `json:"service_invoice_id" db:"service_invoice_id"`
+	ItemType         ServiceInvoiceItemType `json:"item_type" db:"item_type"`
+	SparePartID      *int                   `json:"spare_part_id" db:"spare_part_id"`
+	Description      string                 `json:"description" db:"description"`
+	Quantity         int                    `json:"quantity" db:"quantity"`
+	UnitPrice        float64                `json:"unit_price" db:"unit_price"`
+	TotalPrice       float64                `json:"total_price" db:"total_price"`
+	CreatedAt        time.Time              `json:"created_at" db:"created_at"`
+}
+
+// ServiceInvoicePayment entity (one customer payment against a service invoice)
+type ServiceInvoicePayment struct {
+	BaseModel
+	PaymentNumber    string        `json:"payment_number" db:"payment_number"`
+	ServiceInvoiceID int           `json:"service_invoice_id" db:"service_invoice_id"`
+	Amount           float64       `json:"amount" db:"amount"`
+	PaymentDate      time.Time     `json:"payment_date" db:"payment_date"`
+	PaymentMethod    PaymentMethod `json:"payment_method" db:"payment_method"`
+	TransferProof    *string       `json:"transfer_proof" db:"transfer_proof"`
+	Notes            *string       `json:"notes" db:"notes"`
+	CreatedBy        int           `json:"created_by" db:"created_by"`
+}
+
+// ConsignmentStatus enum
+type ConsignmentStatus string
+
+const (
+	ConsignmentStatusActive ConsignmentStatus = "active"
+	ConsignmentStatusSold   ConsignmentStatus = "sold"
+)
+
+func (cs ConsignmentStatus) String() string {
+	return string(cs)
+}
+
+func (cs *ConsignmentStatus) Scan(value interface{}) error {
+	if value == nil {
+		*cs = ""
+		return nil
+	}
+	if s, ok := value.(string); ok {
+		*cs = ConsignmentStatus(s)
+	}
+	return nil
+}
+
+func (cs ConsignmentStatus) Value() (driver.Value, error) {
+	return string(cs), nil
+}
+
+// Consignment entity (vehicle sold on behalf of its owner for a commission)
+type Consignment struct {
+	BaseModel
+	ConsignmentNumber string            `json:"consignment_number" db:"consignment_number"`
+	VehicleID         int               `json:"vehicle_id" db:"vehicle_id"`
+	OwnerID           int               `json:"owner_id" db:"owner_id"`
+	AgreedNetPrice    float64           `json:"agreed_net_price" db:"agreed_net_price"`
+	PayoutDays        int               `json:"payout_days" db:"payout_days"`
+	Status            ConsignmentStatus `json:"status" db:"status"`
+	IntakeDate        time.Time         `json:"intake_date" db:"intake_date"`
+	SalesInvoiceID    *int              `json:"sales_invoice_id" db:"sales_invoice_id"`
+	SalePrice         *float64          `json:"sale_price" db:"sale_price"`
+	CommissionAmount  *float64          `json:"commission_amount" db:"commission_amount"`
+	Notes             *string           `json:"notes" db:"notes"`
+	CreatedBy         int               `json:"created_by" db:"created_by"`
+	Vehicle           *Vehicle          `json:"vehicle,omitempty"`
+	Owner             *Customer         `json:"owner,omitempty"`
+	Payable           *Payable          `json:"payable,omitempty" db:"-"`
+}
+
+// VehicleDocumentType enum
+type VehicleDocumentType string
+
+const (
+	VehicleDocumentTypeBPKB   VehicleDocumentType = "bpkb"
+	VehicleDocumentTypeSTNK   VehicleDocumentType = "stnk"
+	VehicleDocumentTypeFaktur VehicleDocumentType = "faktur"
+	VehicleDocumentTypeOther  VehicleDocumentType = "other"
+)
+
+func (vdt VehicleDocumentType) String() string {
+	return string(vdt)
+}
+
+func (vdt *VehicleDocumentType) Scan(value interface{}) error {
+	if value == nil {
+		*vdt = ""
+		return nil
+	}
+	if s, ok := value.(string); ok {
+		*vdt = VehicleDocumentType(s)
+	}
+	return nil
+}
+
+func (vdt VehicleDocumentType) Value() (driver.Value, error) {
+	return string(vdt), nil
+}
+
+// DocumentCustodyStatus enum
+type DocumentCustodyStatus string
+
+const (
+	DocumentCustodyStatusPending    DocumentCustodyStatus = "pending"
+	DocumentCustodyStatusInCustody  DocumentCustodyStatus = "in_custody"
+	DocumentCustodyStatusHandedOver DocumentCustodyStatus = "handed_over"
+)
+
+func (dcs DocumentCustodyStatus) String() string {
+	return string(dcs)
+}
+
+func (dcs *DocumentCustodyStatus) Scan(value interface{}) error {
+	if value == nil {
+		*dcs = ""
+		return nil
+	}
+	if s, ok := value.(string); ok {
+		*dcs = DocumentCustodyStatus(s)
+	}
+	return nil
+}
+
+func (dcs DocumentCustodyStatus) Value() (driver.Value, error) {
+	return string(dcs), nil
+}
+
+// VehicleDocument entity (legal paperwork held for a vehicle)
+type VehicleDocument struct {
+	BaseModel
+	VehicleID       int                   `json:"vehicle_id" db:"vehicle_id"`
+	DocumentType    VehicleDocumentType   `json:"document_type" db:"document_type"`
+	DocumentNumber  *string               `json:"document_number" db:"document_number"`
+	CustodyStatus   DocumentCustodyStatus `json:"custody_status" db:"custody_status"`
+	CustodyLocation *string               `json:"custody_location" db:"custody_location"`
+	ExpiryDate      *time.Time            `json:"expiry_date" db:"expiry_date"`
+	TaxDueDate      *time.Time            `json:"tax_due_date" db:"tax_due_date"`
+	ScanFile        *string               `json:"scan_file" db:"scan_file"`
+	ReceivedDate    *time.Time            `json:"received_date" db:"received_date"`
+	SalesInvoiceID  *int                  `json:"sales_invoice_id" db:"sales_invoice_id"`
+	HandedOverAt    *time.Time            `json:"handed_over_at" db:"handed_over_at"`
+	HandedOverTo    *string               `json:"handed_over_to" db:"handed_over_to"`
+	HandedOverBy    *int                  `json:"handed_over_by" db:"handed_over_by"`
+	Notes           *string               `json:"notes" db:"notes"`
+	CreatedBy       int                   `json:"created_by" db:"created_by"`
+	Vehicle         *Vehicle              `json:"vehicle,omitempty"`
+}
+
 // Notification types
 type NotificationType string
 
 const (
-	NotificationTypeWorkOrderAssigned NotificationType = "work_order_assigned"
-	NotificationTypeLowStock          NotificationType = "low_stock"
-	NotificationTypeWorkOrderUpdate   NotificationType = "work_order_update"
-	NotificationTypeDailyReport       NotificationType = "daily_report"
+	NotificationTypeWorkOrderAssigned   NotificationType = "work_order_assigned"
+	NotificationTypeLowStock            NotificationType = "low_stock"
+	NotificationTypeWorkOrderUpdate     NotificationType = "work_order_update"
+	NotificationTypeDailyReport         NotificationType = "daily_report"
+	NotificationTypePayableOverdue      NotificationType = "payable_overdue"
+	NotificationTypePartRequestReviewed NotificationType = "part_request_reviewed"
+	NotificationTypeReorderDraft        NotificationType = "reorder_draft"
+	NotificationTypeExpiringStock       NotificationType = "expiring_stock"
 )
 
 func (nt NotificationType) String() string {
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"net/http"
+	"pos-final/internal/domain"
+	"pos-final/internal/service"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ConsignmentHandler struct {
+	consignmentService service.ConsignmentService
+}
+
+// NewConsignmentHandler creates a new consignment handler
+func NewConsignmentHandler(consignmentService service.ConsignmentService) *ConsignmentHandler {
+	return &ConsignmentHandler{
+		consignmentService: consignmentService,
+	}
+}
+
+type CreateConsignmentRequest struct {
+	OwnerID        int      `json:"owner_id" binding:"required"`
+	AgreedNetPrice float64  `json:"agreed_net_price" binding:"required,gt=0"`
+	PayoutDays     int      `json:"payout_days" binding:"omitempty,gt=0"`
+	IntakeDate     *string  `json:"intake_date"` // YYYY-MM-DD, defaults to today
+	Notes          *string  `json:"notes"`
+	CategoryID     int      `json:"category_id" binding:"required"`
+	Brand          string   `json:"brand" binding:"required"`
+	Model          string   `json:"model" binding:"required"`
+	Year           int      `json:"year" binding:"required"`
+	ChassisNumber  *string  `json:"chassis_number"`
+	EngineNumber   *string  `json:"engine_number"`
+	PlateNumber    *string  `json:"plate_number"`
+	Color          *string  `json:"color"`
+	FuelType       *string  `json:"fuel_type"`
+	Transmission   *string  `json:"transmission"`
+	SellingPrice   *float64 `json:"selling_price"`
+	ConditionNotes *string  `json:"condition_notes"`
+}
+
+func (h *ConsignmentHandler) CreateConsignment(c *gin.Context) {
+	var req CreateConsignmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	consignment := &domain.Consignment{
+		OwnerID:        req.OwnerID,
+		AgreedNetPrice: req.AgreedNetPrice,
+		PayoutDays:     req.PayoutDays,
+		Notes:          req.Notes,
+		CreatedBy:      userID.(int),
+		Vehicle: &domain.Vehicle{
+			CategoryID:     req.CategoryID,
+			Brand:          req.Brand,
+			Model:          req.Model,
+			Year:           req.Year,
+			ChassisNumber:  req.ChassisNumber,
+			EngineNumber:   req.EngineNumber,
+			PlateNumber:    req.PlateNumber,
+			Color:          req.Color,
+			FuelType:       req.FuelType,
+			Transmission:   req.Transmission,
+			SellingPrice:   req.SellingPrice,
+			ConditionNotes: req.ConditionNotes,
+		},
+	}
+
+	if req.IntakeDate != nil {
+		intakeDate, err := time.Parse("2006-01-02", *req.IntakeDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid intake_date format. Use YYYY-MM-DD"})
+			return
+		}
+		consignment.IntakeDate = intakeDate
+	}
+
+	if err := h.consignmentService.CreateConsignment(c.Request.Context(), consignment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to create consignment",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Consignment created successfully",
+		"data":    consignment,
+	})
+}
+
+func (h *ConsignmentHandler) ListConsignments(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	status := c.Query("status")
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	var consignments []*domain.Consignment
+	var total int
+	var err error
+
+	if status != "" {
+		consignments, total, err = h.consignmentService.ListConsignmentsByStatus(c.Request.Context(), domain.ConsignmentStatus(status), page, limit)
+	} else {
+		consignments, total, err = h.consignmentService.ListConsignments(c.Request.Context(), page, limit)
+	}
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve consignments",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Consignments retrieved successfully",
+		"data":    consignments,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+func (h *ConsignmentHandler) GetConsignment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid consignment ID"})
+		return
+	}
+
+	consignment, err := h.consignmentService.GetConsignmentByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Consignment not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Consignment retrieved successfully",
+		"data":    consignment,
+	})
+}
@@ -298,4 +298,160 @@ func (h *CustomerHandler) DeleteCustomer(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Customer deleted successfully",
 	})
-}
\ No newline at end of file
+}
+type CustomerVehicleRequest struct {
+	PlateNumber   string  `json:"plate_number" binding:"required"`
+	Brand         string  `json:"brand" binding:"required"`
+	Model         string  `json:"model" binding:"required"`
+	Year          *int    `json:"year,omitempty"`
+	Color         *string `json:"color,omitempty"`
+	ChassisNumber *string `json:"chassis_number,omitempty"`
+	EngineNumber  *string `json:"engine_number,omitempty"`
+	Notes         *string `json:"notes,omitempty"`
+}
+
+// ListCustomerVehicles lists the vehicles a customer brings in for service
+func (h *CustomerHandler) ListCustomerVehicles(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid customer ID",
+			"message": "Customer ID must be a number",
+		})
+		return
+	}
+
+	vehicles, err := h.customerService.ListCustomerVehicles(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Failed to get customer vehicles",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Customer vehicles retrieved successfully",
+		"data":    vehicles,
+	})
+}
+
+// AddCustomerVehicle registers a vehicle under a customer
+func (h *CustomerHandler) AddCustomerVehicle(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid customer ID",
+			"message": "Customer ID must be a number",
+		})
+		return
+	}
+
+	var req CustomerVehicleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	vehicle := req.toCustomerVehicle()
+	vehicle.CustomerID = id
+
+	if err := h.customerService.AddCustomerVehicle(c.Request.Context(), vehicle); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to add customer vehicle",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Customer vehicle added successfully",
+		"data":    vehicle,
+	})
+}
+
+// UpdateCustomerVehicle updates a customer vehicle
+func (h *CustomerHandler) UpdateCustomerVehicle(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("vehicle_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid customer vehicle ID",
+			"message": "Customer vehicle ID must be a number",
+		})
+		return
+	}
+
+	var req CustomerVehicleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	vehicle := req.toCustomerVehicle()
+	vehicle.ID = id
+
+	if err := h.customerService.UpdateCustomerVehicle(c.Request.Context(), vehicle); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to update customer vehicle",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Customer vehicle updated successfully",
+		"data":    vehicle,
+	})
+}
+
+// DeleteCustomerVehicle deletes a customer vehicle
+func (h *CustomerHandler) DeleteCustomerVehicle(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("vehicle_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid customer vehicle ID",
+			"message": "Customer vehicle ID must be a number",
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in token",
+		})
+		return
+	}
+
+	if err := h.customerService.DeleteCustomerVehicle(c.Request.Context(), id, userID.(int)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to delete customer vehicle",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Customer vehicle deleted successfully",
+	})
+}
+
+func (req CustomerVehicleRequest) toCustomerVehicle() *domain.CustomerVehicle {
+	return &domain.CustomerVehicle{
+		PlateNumber:   req.PlateNumber,
+		Brand:         req.Brand,
+		Model:         req.Model,
+		Year:          req.Year,
+		Color:         req.Color,
+		ChassisNumber: req.ChassisNumber,
+		EngineNumber:  req.EngineNumber,
+		Notes:         req.Notes,
+	}
+}
@@ -16,6 +16,7 @@ type DashboardHandler struct {
 	salesService     service.SalesService
 	purchaseService  service.PurchaseService
 	workOrderService service.WorkOrderService
+	trackingService  service.SparePartTrackingService
 }
 
 // NewDashboardHandler creates a new dashboard handler
@@ -26,6 +27,7 @@ func NewDashboardHandler(
 	salesService service.SalesService,
 	purchaseService service.PurchaseService,
 	workOrderService service.WorkOrderService,
+	trackingService service.SparePartTrackingService,
 ) *DashboardHandler {
 	return &DashboardHandler{
 		customerService:  customerService,
@@ -34,6 +36,7 @@ func NewDashboardHandler(
 		salesService:     salesService,
 		purchaseService:  purchaseService,
 		workOrderService: workOrderService,
+		trackingService:  trackingService,
 	}
 }
 
@@ -43,6 +46,7 @@ type DashboardStats struct {
 	VehiclesByStatus      map[string]int          `json:"vehicles_by_status"`
 	TotalSpareParts       int                     `json:"total_spare_parts"`
 	LowStockPartsCount    int                     `json:"low_stock_parts_count"`
+	ExpiringBatchesCount  int                     `json:"expiring_batches_count"`
 	TodaySalesAmount      float64                 `json:"today_sales_amount"`
 	TodaySalesCount       int                     `json:"today_sales_count"`
 	TodayPurchaseAmount   float64                 `json:"today_purchase_amount"`
@@ -102,6 +106,10 @@ func (h *DashboardHandler) GetDashboardStats(c *gin.Context) {
 		stats.LowStockPartsCount = len(lowStockParts)
 	}
 
+	if batches, err := h.trackingService.ListExpiringBatches(ctx, 0, nil); err == nil {
+		stats.ExpiringBatchesCount = len(batches)
+	}
+
 	// Get today's sales stats
 	if amount, profit, count, err := h.salesService.GetDailySalesReport(ctx, today); err == nil {
 		stats.TodaySalesAmount = amount
@@ -225,17 +233,44 @@ func (h *DashboardHandler) GetMekanikDashboard(c *gin.Context) {
 		return
 	}
 
-	// Mechanic-specific dashboard with focus on work orders
+	// Mechanic-specific dashboard with focus on open tasks and work orders
 	stats := map[string]interface{}{
+		"open_tasks":      make([]interface{}, 0),
 		"my_work_orders":  make(map[string]interface{}),
 		"spare_parts":     make(map[string]interface{}),
 		"recent_work":     make([]interface{}, 0),
 	}
 
+	// Get mechanic's open checklist tasks
+	if tasks, err := h.workOrderService.ListOpenTasksByMechanic(ctx, userIDInt); err == nil {
+		openTasks := make([]map[string]interface{}, 0)
+		for _, task := range tasks {
+			woNumber := ""
+			vehicleCode := ""
+			if task.WorkOrder != nil {
+				woNumber = task.WorkOrder.WONumber
+				if task.WorkOrder.Vehicle != nil {
+					vehicleCode = task.WorkOrder.Vehicle.VehicleCode
+				}
+			}
+			openTasks = append(openTasks, map[string]interface{}{
+				"id":              task.ID,
+				"title":           task.Title,
+				"status":          task.Status,
+				"estimated_hours": task.EstimatedHours,
+				"wo_number":       woNumber,
+				"vehicle_code":    vehicleCode,
+			})
+		}
+		stats["open_tasks"] = openTasks
+	}
+
 	// Get mechanic's work orders
 	if workOrders, _, err := h.workOrderService.ListWorkOrdersByMechanic(ctx, userIDInt, 1, 100); err == nil {
 		pending := 0
 		inProgress := 0
+		onHold := 0
+		waitingParts := 0
 		completed := 0
 
 		for _, wo := range workOrders {
@@ -244,16 +279,22 @@ func (h *DashboardHandler) GetMekanikDashboard(c *gin.Context) {
 				pending++
 			case domain.WorkOrderStatusInProgress:
 				inProgress++
+			case domain.WorkOrderStatusOnHold:
+				onHold++
+			case domain.WorkOrderStatusWaitingParts:
+				waitingParts++
 			case domain.WorkOrderStatusCompleted:
 				completed++
 			}
 		}
 
 		stats["my_work_orders"] = map[string]interface{}{
-			"total":       len(workOrders),
-			"pending":     pending,
-			"in_progress": inProgress,
-			"completed":   completed,
+			"total":         len(workOrders),
+			"pending":       pending,
+			"in_progress":   inProgress,
+			"on_hold":       onHold,
+			"waiting_parts": waitingParts,
+			"completed":     completed,
 		}
 
 		// Get recent work orders (last 5)
@@ -13,6 +13,8 @@ type FileHandler struct {
 	vehicleService service.VehicleService
 	salesService   service.SalesService
 	purchaseService service.PurchaseService
+	payableService  service.PayableService
+	documentService service.VehicleDocumentService
 }
 
 // NewFileHandler creates a new file handler
@@ -21,12 +23,16 @@ func NewFileHandler(
 	vehicleService service.VehicleService,
 	salesService service.SalesService,
 	purchaseService service.PurchaseService,
+	payableService service.PayableService,
+	documentService service.VehicleDocumentService,
 ) *FileHandler {
 	return &FileHandler{
 		fileService:     fileService,
 		vehicleService:  vehicleService,
 		salesService:    salesService,
 		purchaseService: purchaseService,
+		payableService:  payableService,
+		documentService: documentService,
 	}
 }
 
@@ -267,6 +273,160 @@ func (h *FileHandler) UploadPurchaseTransferProof(c *gin.Context) {
 	})
 }
 
+// UploadPayablePaymentTransferProof uploads transfer proof for a payable payment
+func (h *FileHandler) UploadPayablePaymentTransferProof(c *gin.Context) {
+	// Get payment ID from URL
+	paymentIDStr := c.Param("id")
+	paymentID, err := strconv.Atoi(paymentIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid payment ID",
+			"message": "Payment ID must be a number",
+		})
+		return
+	}
+
+	// Check if payment exists
+	_, err = h.payableService.GetPaymentByID(c.Request.Context(), paymentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Payment not found",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// Get uploaded file
+	file, err := c.FormFile("transfer_proof")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "No file uploaded",
+			"message": "Please select a transfer proof file",
+		})
+		return
+	}
+
+	// Validate document file
+	if err := h.fileService.ValidateDocument(file); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid document file",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// Save file
+	filePath, err := h.fileService.SaveFile(c.Request.Context(), file, "transfer_proofs/payables")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to upload transfer proof",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// Link transfer proof to the payment
+	if err := h.payableService.AttachTransferProof(c.Request.Context(), paymentID, filePath); err != nil {
+		c.JSON(http.StatusCreated, gin.H{
+			"message":   "Transfer proof uploaded successfully, but failed to link to payment",
+			"file_path": filePath,
+			"file_url":  h.fileService.GetFileURL(filePath),
+			"warning":   "Please update the payment manually",
+		})
+		return
+	}
+
+	fileURL := h.fileService.GetFileURL(filePath)
+
+	response := UploadResponse{
+		FilePath: filePath,
+		FileURL:  fileURL,
+		Message:  "Transfer proof uploaded successfully",
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": response.Message,
+		"data":    response,
+	})
+}
+
+// UploadVehicleDocumentScan uploads the scan of a vehicle legal document
+func (h *FileHandler) UploadVehicleDocumentScan(c *gin.Context) {
+	// Get document ID from URL
+	documentIDStr := c.Param("id")
+	documentID, err := strconv.Atoi(documentIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid document ID",
+			"message": "Document ID must be a number",
+		})
+		return
+	}
+
+	// Check if document exists
+	_, err = h.documentService.GetDocumentByID(c.Request.Context(), documentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Vehicle document not found",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// Get uploaded file
+	file, err := c.FormFile("scan")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "No file uploaded",
+			"message": "Please select a document scan file",
+		})
+		return
+	}
+
+	// Validate document file
+	if err := h.fileService.ValidateDocument(file); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid document file",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// Save file
+	filePath, err := h.fileService.SaveFile(c.Request.Context(), file, "vehicle_documents")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to upload document scan",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// Link scan to the document
+	if err := h.documentService.AttachScan(c.Request.Context(), documentID, filePath); err != nil {
+		c.JSON(http.StatusCreated, gin.H{
+			"message":   "Document scan uploaded successfully, but failed to link to document",
+			"file_path": filePath,
+			"file_url":  h.fileService.GetFileURL(filePath),
+			"warning":   "Please update the document manually",
+		})
+		return
+	}
+
+	fileURL := h.fileService.GetFileURL(filePath)
+
+	response := UploadResponse{
+		FilePath: filePath,
+		FileURL:  fileURL,
+		Message:  "Document scan uploaded successfully",
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": response.Message,
+		"data":    response,
+	})
+}
+
 // DeleteFile deletes an uploaded file
 func (h *FileHandler) DeleteFile(c *gin.Context) {
 	var req struct {
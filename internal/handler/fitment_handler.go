@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"net/http"
+	"pos-final/internal/domain"
+	"pos-final/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FitmentHandler struct {
+	fitmentService service.FitmentService
+}
+
+// NewFitmentHandler creates a new spare part fitment handler
+func NewFitmentHandler(fitmentService service.FitmentService) *FitmentHandler {
+	return &FitmentHandler{
+		fitmentService: fitmentService,
+	}
+}
+
+type FitmentRequest struct {
+	Brand      *string `json:"brand"` // empty = every vehicle
+	Model      *string `json:"model"` // empty = every model of the brand
+	YearFrom   *int    `json:"year_from"`
+	YearTo     *int    `json:"year_to"`
+	EngineCode *string `json:"engine_code"` // engine number prefix
+	Notes      *string `json:"notes"`
+}
+
+func (r *FitmentRequest) toDomain() *domain.SparePartFitment {
+	return &domain.SparePartFitment{
+		Brand:      r.Brand,
+		Model:      r.Model,
+		YearFrom:   r.YearFrom,
+		YearTo:     r.YearTo,
+		EngineCode: r.EngineCode,
+		Notes:      r.Notes,
+	}
+}
+
+// ListFitments lists the vehicles a spare part fits
+func (h *FitmentHandler) ListFitments(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid spare part ID"})
+		return
+	}
+
+	fitments, err := h.fitmentService.ListFitments(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Failed to retrieve fitments",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": fitments,
+	})
+}
+
+func (h *FitmentHandler) AddFitment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid spare part ID"})
+		return
+	}
+
+	var req FitmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	fitment := req.toDomain()
+	fitment.SparePartID = id
+	fitment.CreatedBy = userID.(int)
+
+	if err := h.fitmentService.AddFitment(c.Request.Context(), fitment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to add fitment",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Fitment added successfully",
+		"data":    fitment,
+	})
+}
+
+func (h *FitmentHandler) UpdateFitment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("fitment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid fitment ID"})
+		return
+	}
+
+	var req FitmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	fitment := req.toDomain()
+	fitment.ID = id
+
+	if err := h.fitmentService.UpdateFitment(c.Request.Context(), fitment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to update fitment",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Fitment updated successfully",
+		"data":    fitment,
+	})
+}
+
+func (h *FitmentHandler) DeleteFitment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("fitment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid fitment ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	if err := h.fitmentService.DeleteFitment(c.Request.Context(), id, userID.(int)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to delete fitment",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Fitment deleted successfully",
+	})
+}
+
+// ListCompatibleParts lists parts that fit a vehicle given by brand, model,
+// year and engine number
+func (h *FitmentHandler) ListCompatibleParts(c *gin.Context) {
+	vehicle := domain.FitmentVehicle{
+		Brand: c.Query("brand"),
+		Model: c.Query("model"),
+	}
+	if raw := c.Query("year"); raw != "" {
+		year, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+			return
+		}
+		vehicle.Year = &year
+	}
+	if engine := c.Query("engine"); engine != "" {
+		vehicle.EngineNumber = &engine
+	}
+
+	h.listCompatibleParts(c, vehicle)
+}
+
+// ListWorkOrderCompatibleParts lists parts that fit the work order's vehicle.
+// Only parts in stock are listed unless in_stock=false.
+func (h *FitmentHandler) ListWorkOrderCompatibleParts(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid work order ID"})
+		return
+	}
+
+	vehicle, err := h.fitmentService.GetWorkOrderVehicle(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to resolve work order vehicle",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	h.listCompatibleParts(c, *vehicle)
+}
+
+func (h *FitmentHandler) listCompatibleParts(c *gin.Context, vehicle domain.FitmentVehicle) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	filter := &domain.CompatiblePartFilter{
+		Vehicle:          vehicle,
+		Search:           c.Query("search"),
+		InStockOnly:      c.DefaultQuery("in_stock", "true") == "true",
+		IncludeUniversal: c.DefaultQuery("universal", "true") == "true",
+	}
+
+	spareParts, total, err := h.fitmentService.ListCompatibleParts(c.Request.Context(), filter, page, limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to retrieve compatible parts",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Compatible parts retrieved successfully",
+		"data":    spareParts,
+		"vehicle": vehicle,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"net/http"
+	"pos-final/internal/domain"
+	"pos-final/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LabelHandler struct {
+	labelService service.LabelService
+}
+
+// NewLabelHandler creates a new label handler
+func NewLabelHandler(labelService service.LabelService) *LabelHandler {
+	return &LabelHandler{
+		labelService: labelService,
+	}
+}
+
+type PrintLabelsRequest struct {
+	Format        string             `json:"format"`         // label format code, see GET /labels/formats
+	LabelWidth    float64            `json:"label_width"`    // mm, for the custom "thermal" format
+	LabelHeight   float64            `json:"label_height"`   // mm, for the custom "thermal" format
+	StartPosition int                `json:"start_position"` // first free label on a partly used sheet
+	Items         []LabelItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type LabelItemRequest struct {
+	ID     int `json:"id" binding:"required"`
+	Copies int `json:"copies"` // default 1
+}
+
+type PrintVehicleLabelsRequest struct {
+	PrintLabelsRequest
+	Type string `json:"type" binding:"required,oneof=key_tag windshield"`
+}
+
+// ListFormats returns the label sheets and thermal label sizes available
+func (h *LabelHandler) ListFormats(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"data": h.labelService.ListFormats(),
+	})
+}
+
+// PrintSparePartLabels returns a PDF of barcode labels for spare parts
+func (h *LabelHandler) PrintSparePartLabels(c *gin.Context) {
+	var req PrintLabelsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	pdfBytes, err := h.labelService.GenerateSparePartLabels(c.Request.Context(), req.toDomain())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to generate labels",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=spare_part_labels.pdf")
+	c.Header("Content-Length", strconv.Itoa(len(pdfBytes)))
+	c.Data(http.StatusOK, "application/pdf", pdfBytes)
+}
+
+// PrintVehicleLabels returns a PDF of key tags or windshield labels with a
+// QR code linking to each vehicle
+func (h *LabelHandler) PrintVehicleLabels(c *gin.Context) {
+	var req PrintVehicleLabelsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	labelType := domain.VehicleLabelType(req.Type)
+	pdfBytes, err := h.labelService.GenerateVehicleLabels(c.Request.Context(), labelType, req.toDomain())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to generate labels",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=vehicle_"+labelType.String()+"_labels.pdf")
+	c.Header("Content-Length", strconv.Itoa(len(pdfBytes)))
+	c.Data(http.StatusOK, "application/pdf", pdfBytes)
+}
+
+func (r *PrintLabelsRequest) toDomain() *domain.LabelPrintRequest {
+	req := &domain.LabelPrintRequest{
+		Format:        r.Format,
+		LabelWidth:    r.LabelWidth,
+		LabelHeight:   r.LabelHeight,
+		StartPosition: r.StartPosition,
+	}
+	for _, item := range r.Items {
+		req.Items = append(req.Items, domain.LabelItem{ID: item.ID, Copies: item.Copies})
+	}
+	return req
+}
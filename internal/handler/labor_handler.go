@@ -0,0 +1,320 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"pos-final/internal/domain"
+	"pos-final/internal/service"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LaborHandler struct {
+	laborService service.LaborService
+}
+
+// NewLaborHandler creates a new labor handler
+func NewLaborHandler(laborService service.LaborService) *LaborHandler {
+	return &LaborHandler{
+		laborService: laborService,
+	}
+}
+
+type StartTimerRequest struct {
+	TaskID     *int    `json:"task_id"`
+	MechanicID *int    `json:"mechanic_id"` // admin only; mechanics always clock in themselves
+	Notes      *string `json:"notes"`
+}
+
+type SetLaborRateRequest struct {
+	SkillLevel string  `json:"skill_level" binding:"required"`
+	HourlyRate float64 `json:"hourly_rate" binding:"min=0"`
+}
+
+type SetLaborProfileRequest struct {
+	SkillLevel *string  `json:"skill_level"`
+	HourlyRate *float64 `json:"hourly_rate"`
+}
+
+// StartTimer clocks a mechanic in on a work order or one of its tasks
+func (h *LaborHandler) StartTimer(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid work order ID"})
+		return
+	}
+
+	// The body is optional: an empty one starts the caller's own timer
+	var req StartTimerRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	mechanicID := userID.(int)
+	if req.MechanicID != nil {
+		if role, _ := c.Get("role"); role != domain.RoleAdmin && *req.MechanicID != mechanicID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Mechanics can only start their own timer"})
+			return
+		}
+		mechanicID = *req.MechanicID
+	}
+
+	entry := &domain.LaborTimeEntry{
+		WorkOrderID:     id,
+		WorkOrderTaskID: req.TaskID,
+		MechanicID:      mechanicID,
+		Notes:           req.Notes,
+	}
+
+	if err := h.laborService.StartTimer(c.Request.Context(), entry); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to start timer",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Timer started successfully",
+		"data":    entry,
+	})
+}
+
+func (h *LaborHandler) PauseTimer(c *gin.Context) {
+	h.changeTimer(c, "pause", h.laborService.PauseTimer)
+}
+
+func (h *LaborHandler) ResumeTimer(c *gin.Context) {
+	h.changeTimer(c, "resume", h.laborService.ResumeTimer)
+}
+
+// StopTimer clocks out and books the labor cost on the work order
+func (h *LaborHandler) StopTimer(c *gin.Context) {
+	h.changeTimer(c, "stop", h.laborService.StopTimer)
+}
+
+func (h *LaborHandler) changeTimer(c *gin.Context, action string, change func(ctx context.Context, id int, userID int, role domain.UserRole) (*domain.LaborTimeEntry, error)) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid timer ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	role, _ := c.Get("role")
+	userRole, _ := role.(domain.UserRole)
+
+	entry, err := change(c.Request.Context(), id, userID.(int), userRole)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to " + action + " timer",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Timer updated successfully",
+		"data":    entry,
+	})
+}
+
+func (h *LaborHandler) ListWorkOrderEntries(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid work order ID"})
+		return
+	}
+
+	entries, err := h.laborService.ListWorkOrderEntries(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get labor time entries",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": entries,
+	})
+}
+
+// GetTimesheet returns a mechanic's logged time between start_date and
+// end_date (default: the last 7 days). Mechanics only see their own.
+func (h *LaborHandler) GetTimesheet(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	mechanicID := userID.(int)
+	if mechanicIDStr := c.Query("mechanic_id"); mechanicIDStr != "" {
+		requested, err := strconv.Atoi(mechanicIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mechanic_id"})
+			return
+		}
+		if role, _ := c.Get("role"); role == domain.RoleMekanik && requested != mechanicID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Mechanics can only view their own timesheet"})
+			return
+		}
+		mechanicID = requested
+	}
+
+	today := time.Now().Truncate(24 * time.Hour)
+	startDate := today.AddDate(0, 0, -6)
+	endDate := today
+
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		parsed, err := time.Parse("2006-01-02", startDateStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format. Use YYYY-MM-DD"})
+			return
+		}
+		startDate = parsed
+	}
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		parsed, err := time.Parse("2006-01-02", endDateStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format. Use YYYY-MM-DD"})
+			return
+		}
+		endDate = parsed
+	}
+
+	timesheet, err := h.laborService.GetTimesheet(c.Request.Context(), mechanicID, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to get timesheet",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": timesheet,
+	})
+}
+
+func (h *LaborHandler) ListRates(c *gin.Context) {
+	rates, err := h.laborService.ListRates(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get labor rates",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": rates,
+	})
+}
+
+// SetRate creates or updates the hourly rate of a skill level
+func (h *LaborHandler) SetRate(c *gin.Context) {
+	var req SetLaborRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	rate := &domain.LaborRate{
+		SkillLevel: req.SkillLevel,
+		HourlyRate: req.HourlyRate,
+	}
+
+	if err := h.laborService.SetRate(c.Request.Context(), rate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to save labor rate",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Labor rate saved successfully",
+		"data":    rate,
+	})
+}
+
+func (h *LaborHandler) GetMechanicProfile(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mechanic ID"})
+		return
+	}
+
+	profile, err := h.laborService.GetMechanicProfile(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Labor profile not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": profile,
+	})
+}
+
+// SetMechanicProfile sets a mechanic's skill level and optional own hourly rate
+func (h *LaborHandler) SetMechanicProfile(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mechanic ID"})
+		return
+	}
+
+	var req SetLaborProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	profile := &domain.MechanicLaborProfile{
+		MechanicID: id,
+		SkillLevel: req.SkillLevel,
+		HourlyRate: req.HourlyRate,
+	}
+
+	if err := h.laborService.SetMechanicProfile(c.Request.Context(), profile); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to save labor profile",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Labor profile saved successfully",
+		"data":    profile,
+	})
+}
@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"net/http"
+	"pos-final/internal/domain"
+	"pos-final/internal/service"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MechanicHandler struct {
+	mechanicService service.MechanicService
+}
+
+// NewMechanicHandler creates a new mechanic handler
+func NewMechanicHandler(mechanicService service.MechanicService) *MechanicHandler {
+	return &MechanicHandler{
+		mechanicService: mechanicService,
+	}
+}
+
+type SetMechanicSkillsRequest struct {
+	Brands []string `json:"brands"`
+}
+
+type CreateMechanicLeaveRequest struct {
+	StartDate string  `json:"start_date" binding:"required"` // YYYY-MM-DD
+	EndDate   string  `json:"end_date" binding:"required"`   // YYYY-MM-DD
+	Reason    *string `json:"reason"`
+}
+
+type SetMechanicWorkingHoursRequest struct {
+	Hours []struct {
+		DayOfWeek int    `json:"day_of_week"` // 0 = Sunday
+		StartTime string `json:"start_time"`  // HH:MM
+		EndTime   string `json:"end_time"`    // HH:MM
+	} `json:"hours"`
+}
+
+// ListWorkloads lists active mechanics with open work orders, leave and skills
+func (h *MechanicHandler) ListWorkloads(c *gin.Context) {
+	workloads, err := h.mechanicService.ListWorkloads(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get mechanic workloads",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": workloads,
+	})
+}
+
+// SetSkills replaces the vehicle brands a mechanic specialises in
+func (h *MechanicHandler) SetSkills(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mechanic ID"})
+		return
+	}
+
+	var req SetMechanicSkillsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.mechanicService.SetSkills(c.Request.Context(), id, req.Brands); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to set mechanic skills",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Mechanic skills updated successfully",
+	})
+}
+
+func (h *MechanicHandler) CreateLeave(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mechanic ID"})
+		return
+	}
+
+	var req CreateMechanicLeaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format. Use YYYY-MM-DD"})
+		return
+	}
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format. Use YYYY-MM-DD"})
+		return
+	}
+
+	leave := &domain.MechanicLeave{
+		MechanicID: id,
+		StartDate:  startDate,
+		EndDate:    endDate,
+		Reason:     req.Reason,
+		CreatedBy:  userID.(int),
+	}
+
+	if err := h.mechanicService.CreateLeave(c.Request.Context(), leave); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to create mechanic leave",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Mechanic leave created successfully",
+		"data":    leave,
+	})
+}
+
+func (h *MechanicHandler) ListLeaves(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mechanic ID"})
+		return
+	}
+
+	leaves, err := h.mechanicService.ListLeaves(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get mechanic leaves",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": leaves,
+	})
+}
+
+func (h *MechanicHandler) DeleteLeave(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("leave_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid leave ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	if err := h.mechanicService.DeleteLeave(c.Request.Context(), id, userID.(int)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to delete mechanic leave",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Mechanic leave deleted successfully",
+	})
+}
+
+// GetWorkingHours returns the mechanic's weekly shifts used for scheduling
+func (h *MechanicHandler) GetWorkingHours(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mechanic ID"})
+		return
+	}
+
+	hours, err := h.mechanicService.GetWorkingHours(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get mechanic working hours",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": hours,
+	})
+}
+
+// SetWorkingHours replaces the mechanic's weekly shifts. An empty list puts
+// the mechanic back on the workshop's default hours.
+func (h *MechanicHandler) SetWorkingHours(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mechanic ID"})
+		return
+	}
+
+	var req SetMechanicWorkingHoursRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	hours := make([]*domain.MechanicWorkingHours, 0, len(req.Hours))
+	for _, shift := range req.Hours {
+		hours = append(hours, &domain.MechanicWorkingHours{
+			DayOfWeek: shift.DayOfWeek,
+			StartTime: shift.StartTime,
+			EndTime:   shift.EndTime,
+		})
+	}
+
+	if err := h.mechanicService.SetWorkingHours(c.Request.Context(), id, hours); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to set mechanic working hours",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Mechanic working hours updated successfully",
+		"data":    hours,
+	})
+}
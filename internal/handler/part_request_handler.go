@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"net/http"
+	"pos-final/internal/domain"
+	"pos-final/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PartRequestHandler struct {
+	partRequestService service.PartRequestService
+}
+
+// NewPartRequestHandler creates a new part request handler
+func NewPartRequestHandler(partRequestService service.PartRequestService) *PartRequestHandler {
+	return &PartRequestHandler{
+		partRequestService: partRequestService,
+	}
+}
+
+type CreatePartRequestRequest struct {
+	SparePartID int     `json:"spare_part_id" binding:"required"`
+	Quantity    int     `json:"quantity" binding:"required,min=1"`
+	Notes       *string `json:"notes"`
+}
+
+type ApprovePartRequestRequest struct {
+	Quantity      int      `json:"quantity" binding:"required,min=1"`
+	LocationID    *int     `json:"location_id"`
+	SerialNumbers []string `json:"serial_numbers"` // one per unit issued, for serial-tracked parts
+	Notes         *string  `json:"notes"`
+}
+
+type RejectPartRequestRequest struct {
+	Notes *string `json:"notes"`
+}
+
+// CreateRequest raises a part request against a work order. Stock is only
+// issued once a storekeeper approves it.
+func (h *PartRequestHandler) CreateRequest(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid work order ID"})
+		return
+	}
+
+	var req CreatePartRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	request := &domain.PartRequest{
+		WorkOrderID:       id,
+		SparePartID:       req.SparePartID,
+		QuantityRequested: req.Quantity,
+		Notes:             req.Notes,
+		RequestedBy:       userID.(int),
+	}
+
+	if err := h.partRequestService.CreateRequest(c.Request.Context(), request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to create part request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Part request created successfully",
+		"data":    request,
+	})
+}
+
+func (h *PartRequestHandler) ListWorkOrderRequests(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid work order ID"})
+		return
+	}
+
+	requests, err := h.partRequestService.ListRequestsByWorkOrder(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve part requests",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": requests,
+	})
+}
+
+// ListRequests is the parts counter queue. It shows pending requests unless
+// another status is given.
+func (h *PartRequestHandler) ListRequests(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	status := c.DefaultQuery("status", string(domain.PartRequestStatusPending))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	requests, total, err := h.partRequestService.ListRequestsByStatus(c.Request.Context(), domain.PartRequestStatus(status), page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve part requests",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Part requests retrieved successfully",
+		"data":    requests,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+func (h *PartRequestHandler) GetRequest(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid part request ID"})
+		return
+	}
+
+	request, err := h.partRequestService.GetRequestByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Part request not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": request,
+	})
+}
+
+// ApproveRequest issues the given quantity; less than requested is a
+// partial approval
+func (h *PartRequestHandler) ApproveRequest(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid part request ID"})
+		return
+	}
+
+	var req ApprovePartRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	request, err := h.partRequestService.ApproveRequest(c.Request.Context(), id, req.LocationID, req.Quantity, req.SerialNumbers, userID.(int), req.Notes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to approve part request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Part request approved successfully",
+		"data":    request,
+	})
+}
+
+func (h *PartRequestHandler) RejectRequest(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid part request ID"})
+		return
+	}
+
+	var req RejectPartRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	request, err := h.partRequestService.RejectRequest(c.Request.Context(), id, userID.(int), req.Notes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to reject part request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Part request rejected successfully",
+		"data":    request,
+	})
+}
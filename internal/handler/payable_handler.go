@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"net/http"
+	"pos-final/internal/domain"
+	"pos-final/internal/service"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PayableHandler struct {
+	payableService service.PayableService
+}
+
+// NewPayableHandler creates a new payable handler
+func NewPayableHandler(payableService service.PayableService) *PayableHandler {
+	return &PayableHandler{
+		payableService: payableService,
+	}
+}
+
+type RecordPayablePaymentRequest struct {
+	Amount        float64 `json:"amount" binding:"required,gt=0"`
+	PaymentDate   *string `json:"payment_date"` // YYYY-MM-DD, defaults to today
+	PaymentMethod string  `json:"payment_method" binding:"required,oneof=cash transfer"`
+	Notes         *string `json:"notes"`
+}
+
+func (h *PayableHandler) ListPayables(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	status := c.Query("status")
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	var payables []*domain.Payable
+	var total int
+	var err error
+
+	if status != "" {
+		payables, total, err = h.payableService.ListPayablesByStatus(c.Request.Context(), domain.PayableStatus(status), page, limit)
+	} else {
+		payables, total, err = h.payableService.ListPayables(c.Request.Context(), page, limit)
+	}
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve payables",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Payables retrieved successfully",
+		"data":    payables,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+func (h *PayableHandler) GetPayable(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payable ID"})
+		return
+	}
+
+	payable, err := h.payableService.GetPayableByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Payable not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Payable retrieved successfully",
+		"data":    payable,
+	})
+}
+
+func (h *PayableHandler) GetPayableByPurchaseInvoice(c *gin.Context) {
+	invoiceID, err := strconv.Atoi(c.Param("invoice_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid purchase invoice ID"})
+		return
+	}
+
+	payable, err := h.payableService.GetPayableByPurchaseInvoiceID(c.Request.Context(), invoiceID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Payable not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Payable retrieved successfully",
+		"data":    payable,
+	})
+}
+
+func (h *PayableHandler) RecordPayment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payable ID"})
+		return
+	}
+
+	var req RecordPayablePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	payment := &domain.PayablePayment{
+		PayableID:     id,
+		Amount:        req.Amount,
+		PaymentMethod: domain.PaymentMethod(req.PaymentMethod),
+		Notes:         req.Notes,
+		CreatedBy:     userID.(int),
+	}
+
+	if req.PaymentDate != nil && *req.PaymentDate != "" {
+		paymentDate, err := time.Parse("2006-01-02", *req.PaymentDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payment_date format. Use YYYY-MM-DD"})
+			return
+		}
+		payment.PaymentDate = paymentDate
+	}
+
+	if err := h.payableService.RecordPayment(c.Request.Context(), payment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to record payment",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Payment recorded successfully",
+		"data":    payment,
+	})
+}
+
+func (h *PayableHandler) NotifyOverduePayables(c *gin.Context) {
+	notified, err := h.payableService.NotifyOverduePayables(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to notify overdue payables",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Overdue payables checked successfully",
+		"data": gin.H{
+			"notified": notified,
+		},
+	})
+}
+
+func (h *PayableHandler) GetAgingReport(c *gin.Context) {
+	asOf, _ := time.Parse("2006-01-02", time.Now().Format("2006-01-02"))
+	if asOfStr := c.Query("as_of"); asOfStr != "" {
+		var err error
+		asOf, err = time.Parse("2006-01-02", asOfStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid as_of format. Use YYYY-MM-DD"})
+			return
+		}
+	}
+
+	counterpartyType := c.Query("counterparty_type")
+	if counterpartyType != "" &&
+		counterpartyType != string(domain.TransactionTypeSupplier) &&
+		counterpartyType != string(domain.TransactionTypeCustomer) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "counterparty_type must be supplier or customer"})
+		return
+	}
+
+	report, err := h.payableService.GetAgingReport(c.Request.Context(), asOf, counterpartyType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to generate payable aging report",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": report,
+	})
+}
@@ -15,7 +15,8 @@ type PDFHandler struct {
 type PDFService interface {
 	GenerateSalesInvoicePDF(ctx *gin.Context, invoiceID int) ([]byte, error)
 	GeneratePurchaseInvoicePDF(ctx *gin.Context, invoiceID int) ([]byte, error)
-	GenerateWorkOrderPDF(ctx *gin.Context, workOrderID int) ([]byte, error)
+	GenerateWorkOrderPDF(ctx *gin.Context, workOrderID int, includePhotos bool) ([]byte, error)
+	GenerateConsignmentStatementPDF(ctx *gin.Context, consignmentID int) ([]byte, error)
 	GenerateReportPDF(ctx *gin.Context, reportType string, data interface{}) ([]byte, error)
 }
 
@@ -38,8 +39,12 @@ func (a *pdfServiceAdapter) GeneratePurchaseInvoicePDF(ctx *gin.Context, invoice
 	return a.invoiceService.GeneratePurchaseInvoicePDF(ctx.Request.Context(), invoiceID)
 }
 
-func (a *pdfServiceAdapter) GenerateWorkOrderPDF(ctx *gin.Context, workOrderID int) ([]byte, error) {
-	return a.invoiceService.GenerateWorkOrderPDF(ctx.Request.Context(), workOrderID)
+func (a *pdfServiceAdapter) GenerateWorkOrderPDF(ctx *gin.Context, workOrderID int, includePhotos bool) ([]byte, error) {
+	return a.invoiceService.GenerateWorkOrderPDF(ctx.Request.Context(), workOrderID, includePhotos)
+}
+
+func (a *pdfServiceAdapter) GenerateConsignmentStatementPDF(ctx *gin.Context, consignmentID int) ([]byte, error) {
+	return a.invoiceService.GenerateConsignmentStatementPDF(ctx.Request.Context(), consignmentID)
 }
 
 func (a *pdfServiceAdapter) GenerateReportPDF(ctx *gin.Context, reportType string, data interface{}) ([]byte, error) {
@@ -109,7 +114,10 @@ func (h *PDFHandler) GenerateWorkOrderPDF(c *gin.Context) {
 		return
 	}
 
-	pdfBytes, err := h.pdfService.GenerateWorkOrderPDF(c, workOrderID)
+	// ?photos=true appends the work order photo attachments
+	includePhotos := c.Query("photos") == "true"
+
+	pdfBytes, err := h.pdfService.GenerateWorkOrderPDF(c, workOrderID, includePhotos)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to generate PDF: " + err.Error(),
@@ -124,6 +132,32 @@ func (h *PDFHandler) GenerateWorkOrderPDF(c *gin.Context) {
 	c.Data(http.StatusOK, "application/pdf", pdfBytes)
 }
 
+// GenerateConsignmentStatementPDF generates the settlement statement for a sold consignment vehicle
+func (h *PDFHandler) GenerateConsignmentStatementPDF(c *gin.Context) {
+	idParam := c.Param("id")
+	consignmentID, err := strconv.Atoi(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid consignment ID",
+		})
+		return
+	}
+
+	pdfBytes, err := h.pdfService.GenerateConsignmentStatementPDF(c, consignmentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to generate PDF: " + err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", "attachment; filename=consignment_statement_"+idParam+".pdf")
+	c.Header("Content-Length", strconv.Itoa(len(pdfBytes)))
+
+	c.Data(http.StatusOK, "application/pdf", pdfBytes)
+}
+
 // GenerateReportPDF generates a PDF for various reports
 func (h *PDFHandler) GenerateReportPDF(c *gin.Context) {
 	reportType := c.Query("type")
@@ -22,15 +22,26 @@ func NewPurchaseHandler(purchaseService service.PurchaseService) *PurchaseHandle
 }
 
 type CreatePurchaseRequest struct {
-	TransactionType   string   `json:"transaction_type" binding:"required,oneof=customer supplier"`
-	CustomerID        *int     `json:"customer_id"`
-	SupplierID        *int     `json:"supplier_id"`
-	VehicleID         int      `json:"vehicle_id" binding:"required"`
-	PurchasePrice     float64  `json:"purchase_price" binding:"required,min=0"`
-	NegotiatedPrice   *float64 `json:"negotiated_price"`
-	PaymentMethod     string   `json:"payment_method" binding:"required,oneof=cash transfer"`
-	Notes             *string  `json:"notes"`
-	TransactionDate   *string  `json:"transaction_date"`
+	TransactionType string   `json:"transaction_type" binding:"required,oneof=customer supplier"`
+	CustomerID      *int     `json:"customer_id"`
+	SupplierID      *int     `json:"supplier_id"`
+	VehicleID       *int     `json:"vehicle_id"` // single vehicle purchase
+	PurchasePrice   float64  `json:"purchase_price" binding:"min=0"`
+	NegotiatedPrice *float64 `json:"negotiated_price"`
+	PaymentMethod   string   `json:"payment_method" binding:"required,oneof=cash transfer"`
+	Notes           *string  `json:"notes"`
+	TransactionDate *string  `json:"transaction_date"`
+	DueDate         *string  `json:"due_date"`        // YYYY-MM-DD, omit for immediate payment
+	InitialPayment  float64  `json:"initial_payment"` // paid on the transaction date when due_date is set
+	// Bulk purchase: several vehicles on one invoice instead of vehicle_id
+	Items            []PurchaseItemRequest `json:"items" binding:"omitempty,dive"`
+	SharedCost       float64               `json:"shared_cost" binding:"min=0"`                             // transport, auction fees, etc.
+	AllocationMethod string                `json:"allocation_method" binding:"omitempty,oneof=value equal"` // how shared_cost is spread
+}
+
+type PurchaseItemRequest struct {
+	VehicleID int     `json:"vehicle_id" binding:"required"`
+	Price     float64 `json:"price" binding:"required,gt=0"`
 }
 
 func (h *PurchaseHandler) CreatePurchaseInvoice(c *gin.Context) {
@@ -43,6 +54,25 @@ func (h *PurchaseHandler) CreatePurchaseInvoice(c *gin.Context) {
 		return
 	}
 
+	if req.VehicleID == nil && len(req.Items) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "vehicle_id or items is required",
+		})
+		return
+	}
+	if req.VehicleID != nil && len(req.Items) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "use either vehicle_id or items, not both",
+		})
+		return
+	}
+	if req.VehicleID != nil && req.PurchasePrice <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "purchase_price is required for single vehicle purchases",
+		})
+		return
+	}
+
 	// Validate transaction type and corresponding ID
 	transactionType := domain.TransactionType(req.TransactionType)
 	if transactionType == domain.TransactionTypeCustomer && req.CustomerID == nil {
@@ -82,16 +112,40 @@ func (h *PurchaseHandler) CreatePurchaseInvoice(c *gin.Context) {
 
 	// Create invoice
 	invoice := &domain.PurchaseInvoice{
-		TransactionType: transactionType,
-		CustomerID:      req.CustomerID,
-		SupplierID:      req.SupplierID,
-		VehicleID:       req.VehicleID,
-		PurchasePrice:   req.PurchasePrice,
-		NegotiatedPrice: req.NegotiatedPrice,
-		PaymentMethod:   domain.PaymentMethod(req.PaymentMethod),
-		Notes:           req.Notes,
-		CreatedBy:       userID.(int),
-		TransactionDate: transactionDate,
+		TransactionType:  transactionType,
+		CustomerID:       req.CustomerID,
+		SupplierID:       req.SupplierID,
+		VehicleID:        req.VehicleID,
+		PurchasePrice:    req.PurchasePrice,
+		NegotiatedPrice:  req.NegotiatedPrice,
+		SharedCost:       req.SharedCost,
+		AllocationMethod: domain.AllocationMethod(req.AllocationMethod),
+		PaymentMethod:    domain.PaymentMethod(req.PaymentMethod),
+		Notes:            req.Notes,
+		CreatedBy:        userID.(int),
+		TransactionDate:  transactionDate,
+	}
+
+	for _, item := range req.Items {
+		invoice.Items = append(invoice.Items, &domain.PurchaseInvoiceItem{
+			VehicleID: item.VehicleID,
+			Price:     item.Price,
+		})
+	}
+
+	// Payment terms
+	if req.DueDate != nil && *req.DueDate != "" {
+		dueDate, err := time.Parse("2006-01-02", *req.DueDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid due date format, use YYYY-MM-DD",
+			})
+			return
+		}
+		invoice.Payable = &domain.Payable{
+			DueDate:    dueDate,
+			PaidAmount: req.InitialPayment,
+		}
 	}
 
 	if err := h.purchaseService.CreatePurchaseInvoice(c.Request.Context(), invoice); err != nil {
@@ -0,0 +1,323 @@
+package handler
+
+import (
+	"net/http"
+	"pos-final/internal/domain"
+	"pos-final/internal/service"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PurchaseOrderHandler struct {
+	purchaseOrderService service.PurchaseOrderService
+}
+
+// NewPurchaseOrderHandler creates a new purchase order handler
+func NewPurchaseOrderHandler(purchaseOrderService service.PurchaseOrderService) *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{
+		purchaseOrderService: purchaseOrderService,
+	}
+}
+
+type PurchaseOrderItemRequest struct {
+	SparePartID int     `json:"spare_part_id" binding:"required"`
+	Quantity    int     `json:"quantity" binding:"required,min=1"`
+	UnitCost    float64 `json:"unit_cost" binding:"min=0"`
+}
+
+type CreatePurchaseOrderRequest struct {
+	SupplierID   int                        `json:"supplier_id" binding:"required"`
+	ExpectedDate *string                    `json:"expected_date"` // YYYY-MM-DD
+	Notes        *string                    `json:"notes"`
+	Submit       bool                       `json:"submit"` // create directly as ordered
+	Items        []PurchaseOrderItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type GoodsReceiptItemRequest struct {
+	PurchaseOrderItemID int      `json:"purchase_order_item_id" binding:"required"`
+	Quantity            int      `json:"quantity" binding:"required,min=1"`
+	UnitCost            float64  `json:"unit_cost" binding:"min=0"`
+	BatchNumber         *string  `json:"batch_number"`   // batch-tracked parts, default the receipt number
+	ExpiryDate          *string  `json:"expiry_date"`    // YYYY-MM-DD, batch-tracked parts
+	SerialNumbers       []string `json:"serial_numbers"` // one per unit for serial-tracked parts
+}
+
+type ReceiveGoodsRequest struct {
+	SupplierInvoiceNumber *string                   `json:"supplier_invoice_number"`
+	ReceivedDate          *string                   `json:"received_date"` // YYYY-MM-DD
+	LocationID            *int                      `json:"location_id"`
+	Notes                 *string                   `json:"notes"`
+	Items                 []GoodsReceiptItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+func (h *PurchaseOrderHandler) CreatePurchaseOrder(c *gin.Context) {
+	var req CreatePurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	po := &domain.PurchaseOrder{
+		SupplierID: req.SupplierID,
+		Status:     domain.PurchaseOrderStatusDraft,
+		Notes:      req.Notes,
+		CreatedBy:  userID.(int),
+	}
+	if req.Submit {
+		po.Status = domain.PurchaseOrderStatusOrdered
+	}
+
+	if req.ExpectedDate != nil && *req.ExpectedDate != "" {
+		expectedDate, err := time.Parse("2006-01-02", *req.ExpectedDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid expected_date format. Use YYYY-MM-DD"})
+			return
+		}
+		po.ExpectedDate = &expectedDate
+	}
+
+	for _, item := range req.Items {
+		po.Items = append(po.Items, &domain.PurchaseOrderItem{
+			SparePartID:     item.SparePartID,
+			QuantityOrdered: item.Quantity,
+			UnitCost:        item.UnitCost,
+		})
+	}
+
+	if err := h.purchaseOrderService.CreatePurchaseOrder(c.Request.Context(), po); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to create purchase order",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Purchase order created successfully",
+		"data":    po,
+	})
+}
+
+func (h *PurchaseOrderHandler) GetPurchaseOrder(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid purchase order ID"})
+		return
+	}
+
+	po, err := h.purchaseOrderService.GetPurchaseOrderByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Purchase order not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Purchase order retrieved successfully",
+		"data":    po,
+	})
+}
+
+func (h *PurchaseOrderHandler) ListPurchaseOrders(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	status := c.Query("status")
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	var orders []*domain.PurchaseOrder
+	var total int
+	var err error
+
+	if status != "" {
+		orders, total, err = h.purchaseOrderService.ListPurchaseOrdersByStatus(c.Request.Context(), domain.PurchaseOrderStatus(status), page, limit)
+	} else {
+		orders, total, err = h.purchaseOrderService.ListPurchaseOrders(c.Request.Context(), page, limit)
+	}
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve purchase orders",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Purchase orders retrieved successfully",
+		"data":    orders,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+func (h *PurchaseOrderHandler) SubmitPurchaseOrder(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid purchase order ID"})
+		return
+	}
+
+	if err := h.purchaseOrderService.SubmitPurchaseOrder(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to submit purchase order",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Purchase order submitted successfully",
+	})
+}
+
+func (h *PurchaseOrderHandler) CancelPurchaseOrder(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid purchase order ID"})
+		return
+	}
+
+	if err := h.purchaseOrderService.CancelPurchaseOrder(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to cancel purchase order",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Purchase order cancelled successfully",
+	})
+}
+
+func (h *PurchaseOrderHandler) ReceiveGoods(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid purchase order ID"})
+		return
+	}
+
+	var req ReceiveGoodsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	receipt := &domain.GoodsReceipt{
+		PurchaseOrderID:       id,
+		SupplierInvoiceNumber: req.SupplierInvoiceNumber,
+		LocationID:            req.LocationID,
+		Notes:                 req.Notes,
+		ReceivedBy:            userID.(int),
+	}
+
+	if req.ReceivedDate != nil && *req.ReceivedDate != "" {
+		receivedDate, err := time.Parse("2006-01-02", *req.ReceivedDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid received_date format. Use YYYY-MM-DD"})
+			return
+		}
+		receipt.ReceivedDate = receivedDate
+	}
+
+	for _, item := range req.Items {
+		receiptItem := &domain.GoodsReceiptItem{
+			PurchaseOrderItemID: item.PurchaseOrderItemID,
+			Quantity:            item.Quantity,
+			UnitCost:            item.UnitCost,
+			BatchNumber:         item.BatchNumber,
+			SerialNumbers:       item.SerialNumbers,
+		}
+		if item.ExpiryDate != nil && *item.ExpiryDate != "" {
+			expiryDate, err := time.Parse("2006-01-02", *item.ExpiryDate)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid expiry_date format. Use YYYY-MM-DD"})
+				return
+			}
+			receiptItem.ExpiryDate = &expiryDate
+		}
+		receipt.Items = append(receipt.Items, receiptItem)
+	}
+
+	if err := h.purchaseOrderService.ReceiveGoods(c.Request.Context(), receipt); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to receive goods",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Goods received successfully",
+		"data":    receipt,
+	})
+}
+
+func (h *PurchaseOrderHandler) ListGoodsReceipts(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid purchase order ID"})
+		return
+	}
+
+	receipts, err := h.purchaseOrderService.ListGoodsReceipts(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve goods receipts",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Goods receipts retrieved successfully",
+		"data":    receipts,
+	})
+}
+
+func (h *PurchaseOrderHandler) ListBackorders(c *gin.Context) {
+	items, err := h.purchaseOrderService.ListBackorders(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve backorders",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Backorders retrieved successfully",
+		"data":    items,
+	})
+}
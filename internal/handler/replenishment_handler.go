@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"net/http"
+	"pos-final/internal/domain"
+	"pos-final/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReplenishmentHandler struct {
+	replenishmentService service.ReplenishmentService
+}
+
+// NewReplenishmentHandler creates a new replenishment handler
+func NewReplenishmentHandler(replenishmentService service.ReplenishmentService) *ReplenishmentHandler {
+	return &ReplenishmentHandler{
+		replenishmentService: replenishmentService,
+	}
+}
+
+type GenerateReorderRequest struct {
+	SupplierID *int `json:"supplier_id"` // empty = every supplier
+}
+
+type UpdateReorderSettingsRequest struct {
+	PreferredSupplierID *int  `json:"preferred_supplier_id"`
+	SafetyStockDays     *int  `json:"safety_stock_days"` // null = policy default
+	AutoReorder         *bool `json:"auto_reorder"`      // default true
+}
+
+type UpdateLeadTimeRequest struct {
+	LeadTimeDays *int `json:"lead_time_days" binding:"required"`
+}
+
+// ListSuggestions returns the parts at or below their reorder point with the
+// quantity to order. all=true returns every part with its figures.
+func (h *ReplenishmentHandler) ListSuggestions(c *gin.Context) {
+	var supplierID *int
+	if raw := c.Query("supplier_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid supplier_id"})
+			return
+		}
+		supplierID = &id
+	}
+
+	suggestions, err := h.replenishmentService.ListSuggestions(c.Request.Context(), supplierID, c.Query("all") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve reorder suggestions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   suggestions,
+		"policy": h.replenishmentService.GetPolicy(),
+	})
+}
+
+// GenerateDraftPurchaseOrders creates one draft purchase order per supplier
+// from the current suggestions
+func (h *ReplenishmentHandler) GenerateDraftPurchaseOrders(c *gin.Context) {
+	var req GenerateReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	run, err := h.replenishmentService.GenerateDraftPurchaseOrders(c.Request.Context(), req.SupplierID, userID.(int))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to generate draft purchase orders",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if len(run.Failed) > 0 {
+		status := http.StatusCreated
+		if len(run.PurchaseOrders) == 0 {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"message": "Some draft purchase orders could not be generated",
+			"data":    run,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Draft purchase orders generated successfully",
+		"data":    run,
+	})
+}
+
+// UpdateSettings sets a part's preferred supplier, safety stock and whether
+// it is reordered automatically
+func (h *ReplenishmentHandler) UpdateSettings(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid spare part ID"})
+		return
+	}
+
+	var req UpdateReorderSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	settings := &domain.ReorderSettings{
+		SparePartID:         id,
+		PreferredSupplierID: req.PreferredSupplierID,
+		SafetyStockDays:     req.SafetyStockDays,
+		AutoReorder:         true,
+	}
+	if req.AutoReorder != nil {
+		settings.AutoReorder = *req.AutoReorder
+	}
+
+	if err := h.replenishmentService.UpdateSettings(c.Request.Context(), settings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to update reorder settings",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reorder settings updated successfully",
+		"data":    settings,
+	})
+}
+
+// UpdateSupplierLeadTime sets how many days a supplier takes to deliver
+func (h *ReplenishmentHandler) UpdateSupplierLeadTime(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid supplier ID"})
+		return
+	}
+
+	var req UpdateLeadTimeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.replenishmentService.UpdateSupplierLeadTime(c.Request.Context(), id, *req.LeadTimeDays); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to update supplier lead time",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Supplier lead time updated successfully",
+	})
+}
@@ -0,0 +1,352 @@
+package handler
+
+import (
+	"net/http"
+	"pos-final/internal/domain"
+	"pos-final/internal/service"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ScheduleHandler struct {
+	scheduleService service.ScheduleService
+}
+
+// NewScheduleHandler creates a new schedule handler
+func NewScheduleHandler(scheduleService service.ScheduleService) *ScheduleHandler {
+	return &ScheduleHandler{
+		scheduleService: scheduleService,
+	}
+}
+
+type WorkshopBayRequest struct {
+	Code     string  `json:"code" binding:"required"`
+	Name     string  `json:"name" binding:"required"`
+	IsActive *bool   `json:"is_active"`
+	Notes    *string `json:"notes"`
+}
+
+type ScheduleWorkRequest struct {
+	WorkOrderTaskID *int      `json:"work_order_task_id"`
+	BayID           *int      `json:"bay_id"`
+	MechanicID      *int      `json:"mechanic_id"`
+	PlannedStart    time.Time `json:"planned_start" binding:"required"` // RFC3339
+	PlannedEnd      time.Time `json:"planned_end" binding:"required"`   // RFC3339
+	Notes           *string   `json:"notes"`
+}
+
+func (h *ScheduleHandler) ListBays(c *gin.Context) {
+	bays, err := h.scheduleService.ListBays(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve workshop bays",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": bays,
+	})
+}
+
+func (h *ScheduleHandler) CreateBay(c *gin.Context) {
+	var req WorkshopBayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	bay := &domain.WorkshopBay{
+		Code:     req.Code,
+		Name:     req.Name,
+		IsActive: req.IsActive == nil || *req.IsActive,
+		Notes:    req.Notes,
+	}
+
+	if err := h.scheduleService.CreateBay(c.Request.Context(), bay); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to create workshop bay",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Workshop bay created successfully",
+		"data":    bay,
+	})
+}
+
+func (h *ScheduleHandler) UpdateBay(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workshop bay ID"})
+		return
+	}
+
+	var req WorkshopBayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	bay := &domain.WorkshopBay{
+		Code:     req.Code,
+		Name:     req.Name,
+		IsActive: req.IsActive == nil || *req.IsActive,
+		Notes:    req.Notes,
+	}
+	bay.ID = id
+
+	if err := h.scheduleService.UpdateBay(c.Request.Context(), bay); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to update workshop bay",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Workshop bay updated successfully",
+		"data":    bay,
+	})
+}
+
+func (h *ScheduleHandler) DeleteBay(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workshop bay ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	if err := h.scheduleService.DeleteBay(c.Request.Context(), id, userID.(int)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to delete workshop bay",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Workshop bay deleted successfully",
+	})
+}
+
+// ScheduleWork plans a work order, or one of its tasks, on a bay and/or a
+// mechanic. Conflicting plans are rejected with the reasons.
+func (h *ScheduleHandler) ScheduleWork(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid work order ID"})
+		return
+	}
+
+	var req ScheduleWorkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	schedule := &domain.WorkOrderSchedule{
+		WorkOrderID:     id,
+		WorkOrderTaskID: req.WorkOrderTaskID,
+		BayID:           req.BayID,
+		MechanicID:      req.MechanicID,
+		PlannedStart:    req.PlannedStart,
+		PlannedEnd:      req.PlannedEnd,
+		Notes:           req.Notes,
+		CreatedBy:       userID.(int),
+	}
+
+	if err := h.scheduleService.ScheduleWork(c.Request.Context(), schedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to schedule work",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Work scheduled successfully",
+		"data":    schedule,
+	})
+}
+
+func (h *ScheduleHandler) ListWorkOrderSchedule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid work order ID"})
+		return
+	}
+
+	schedules, err := h.scheduleService.ListWorkOrderSchedule(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve work order schedule",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": schedules,
+	})
+}
+
+func (h *ScheduleHandler) UpdateSchedule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+		return
+	}
+
+	var req ScheduleWorkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	schedule := &domain.WorkOrderSchedule{
+		WorkOrderTaskID: req.WorkOrderTaskID,
+		BayID:           req.BayID,
+		MechanicID:      req.MechanicID,
+		PlannedStart:    req.PlannedStart,
+		PlannedEnd:      req.PlannedEnd,
+		Notes:           req.Notes,
+	}
+	schedule.ID = id
+
+	if err := h.scheduleService.UpdateSchedule(c.Request.Context(), schedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to update schedule",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Schedule updated successfully",
+		"data":    schedule,
+	})
+}
+
+func (h *ScheduleHandler) DeleteSchedule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	if err := h.scheduleService.DeleteSchedule(c.Request.Context(), id, userID.(int)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to delete schedule",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Schedule deleted successfully",
+	})
+}
+
+// GetCalendar returns the planned work per day for a day or a week view,
+// optionally for one bay or one mechanic
+func (h *ScheduleHandler) GetCalendar(c *gin.Context) {
+	view := c.DefaultQuery("view", "week")
+
+	date := time.Now()
+	if dateStr := c.Query("date"); dateStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", dateStr, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid date format",
+				"details": "Use YYYY-MM-DD format",
+			})
+			return
+		}
+		date = parsed
+	}
+
+	var bayID, mechanicID *int
+	if bayStr := c.Query("bay_id"); bayStr != "" {
+		id, err := strconv.Atoi(bayStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bay ID"})
+			return
+		}
+		bayID = &id
+	}
+	if mechanicStr := c.Query("mechanic_id"); mechanicStr != "" {
+		id, err := strconv.Atoi(mechanicStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mechanic ID"})
+			return
+		}
+		mechanicID = &id
+	}
+
+	calendar, err := h.scheduleService.GetCalendar(c.Request.Context(), view, date, bayID, mechanicID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to retrieve schedule calendar",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": calendar,
+	})
+}
+
+func (h *ScheduleHandler) RecalculateEstimates(c *gin.Context) {
+	updated, err := h.scheduleService.RecalculateEstimates(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to recalculate estimated completion",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Estimated completion recalculated successfully",
+		"data": gin.H{
+			"updated": updated,
+		},
+	})
+}
@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"net/http"
+	"pos-final/internal/domain"
+	"pos-final/internal/service"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ServiceInvoiceHandler struct {
+	serviceInvoiceService service.ServiceInvoiceService
+}
+
+// NewServiceInvoiceHandler creates a new service invoice handler
+func NewServiceInvoiceHandler(serviceInvoiceService service.ServiceInvoiceService) *ServiceInvoiceHandler {
+	return &ServiceInvoiceHandler{
+		serviceInvoiceService: serviceInvoiceService,
+	}
+}
+
+type CreateServiceInvoiceRequest struct {
+	LaborAmount    *float64 `json:"labor_amount" binding:"omitempty,min=0"` // defaults to the work order labor price
+	DiscountAmount float64  `json:"discount_amount" binding:"min=0"`
+	DueDate        *string  `json:"due_date"` // YYYY-MM-DD, defaults to today
+	Notes          *string  `json:"notes"`
+}
+
+type RecordServicePaymentRequest struct {
+	Amount        float64 `json:"amount" binding:"required,gt=0"`
+	PaymentDate   *string `json:"payment_date"` // YYYY-MM-DD, defaults to today
+	PaymentMethod string  `json:"payment_method" binding:"required,oneof=cash transfer"`
+	Notes         *string `json:"notes"`
+}
+
+// CreateInvoice bills the customer for a completed customer service work order
+func (h *ServiceInvoiceHandler) CreateInvoice(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid work order ID"})
+		return
+	}
+
+	var req CreateServiceInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	var dueDate *time.Time
+	if req.DueDate != nil && *req.DueDate != "" {
+		parsed, err := time.Parse("2006-01-02", *req.DueDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid due_date format. Use YYYY-MM-DD"})
+			return
+		}
+		dueDate = &parsed
+	}
+
+	invoice, err := h.serviceInvoiceService.CreateInvoice(c.Request.Context(), id, req.LaborAmount, req.DiscountAmount, dueDate, req.Notes, userID.(int))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to create service invoice",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Service invoice created successfully",
+		"data":    invoice,
+	})
+}
+
+func (h *ServiceInvoiceHandler) ListInvoices(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	status := c.Query("status")
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	var invoices []*domain.ServiceInvoice
+	var total int
+	var err error
+
+	if status != "" {
+		invoices, total, err = h.serviceInvoiceService.ListInvoicesByStatus(c.Request.Context(), domain.ServiceInvoiceStatus(status), page, limit)
+	} else {
+		invoices, total, err = h.serviceInvoiceService.ListInvoices(c.Request.Context(), page, limit)
+	}
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve service invoices",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Service invoices retrieved successfully",
+		"data":    invoices,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+func (h *ServiceInvoiceHandler) GetInvoice(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid service invoice ID"})
+		return
+	}
+
+	invoice, err := h.serviceInvoiceService.GetInvoiceByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Service invoice not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": invoice,
+	})
+}
+
+func (h *ServiceInvoiceHandler) RecordPayment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid service invoice ID"})
+		return
+	}
+
+	var req RecordServicePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	payment := &domain.ServiceInvoicePayment{
+		ServiceInvoiceID: id,
+		Amount:           req.Amount,
+		PaymentMethod:    domain.PaymentMethod(req.PaymentMethod),
+		Notes:            req.Notes,
+		CreatedBy:        userID.(int),
+	}
+
+	if req.PaymentDate != nil && *req.PaymentDate != "" {
+		paymentDate, err := time.Parse("2006-01-02", *req.PaymentDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payment_date format. Use YYYY-MM-DD"})
+			return
+		}
+		payment.PaymentDate = paymentDate
+	}
+
+	if err := h.serviceInvoiceService.RecordPayment(c.Request.Context(), payment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to record payment",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Payment recorded successfully",
+		"data":    payment,
+	})
+}
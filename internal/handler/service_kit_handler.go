@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"net/http"
+	"pos-final/internal/domain"
+	"pos-final/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ServiceKitHandler struct {
+	serviceKitService service.ServiceKitService
+}
+
+// NewServiceKitHandler creates a new service kit handler
+func NewServiceKitHandler(serviceKitService service.ServiceKitService) *ServiceKitHandler {
+	return &ServiceKitHandler{
+		serviceKitService: serviceKitService,
+	}
+}
+
+type ServiceKitRequest struct {
+	KitCode     string                  `json:"kit_code"` // generated when empty, cannot be changed
+	Name        string                  `json:"name" binding:"required"`
+	Description *string                 `json:"description"`
+	LaborHours  float64                 `json:"labor_hours" binding:"min=0"`
+	LaborPrice  float64                 `json:"labor_price" binding:"min=0"`
+	IsActive    *bool                   `json:"is_active"` // default true
+	Items       []ServiceKitItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type ServiceKitItemRequest struct {
+	SparePartID int `json:"spare_part_id" binding:"required"`
+	Quantity    int `json:"quantity" binding:"required,min=1"`
+}
+
+func (r *ServiceKitRequest) toDomain() *domain.ServiceKit {
+	kit := &domain.ServiceKit{
+		KitCode:     r.KitCode,
+		Name:        r.Name,
+		Description: r.Description,
+		LaborHours:  r.LaborHours,
+		LaborPrice:  r.LaborPrice,
+		IsActive:    true,
+	}
+	if r.IsActive != nil {
+		kit.IsActive = *r.IsActive
+	}
+	for _, item := range r.Items {
+		kit.Items = append(kit.Items, &domain.ServiceKitItem{
+			SparePartID: item.SparePartID,
+			Quantity:    item.Quantity,
+		})
+	}
+	return kit
+}
+
+func (h *ServiceKitHandler) CreateKit(c *gin.Context) {
+	var req ServiceKitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	kit := req.toDomain()
+	kit.CreatedBy = userID.(int)
+
+	if err := h.serviceKitService.CreateKit(c.Request.Context(), kit); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to create service kit",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	created, err := h.serviceKitService.GetKit(c.Request.Context(), kit.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve service kit",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Service kit created successfully",
+		"data":    created,
+	})
+}
+
+// ListKits lists service kits. Inactive kits are hidden unless all=true.
+func (h *ServiceKitHandler) ListKits(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	kits, total, err := h.serviceKitService.ListKits(c.Request.Context(), c.Query("all") != "true", page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve service kits",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Service kits retrieved successfully",
+		"data":    kits,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+func (h *ServiceKitHandler) GetKit(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid service kit ID"})
+		return
+	}
+
+	kit, err := h.serviceKitService.GetKit(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Service kit not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": kit,
+	})
+}
+
+// UpdateKit saves the kit details and replaces its parts
+func (h *ServiceKitHandler) UpdateKit(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid service kit ID"})
+		return
+	}
+
+	var req ServiceKitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	kit := req.toDomain()
+	kit.ID = id
+
+	if err := h.serviceKitService.UpdateKit(c.Request.Context(), kit); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to update service kit",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	updated, err := h.serviceKitService.GetKit(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve service kit",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Service kit updated successfully",
+		"data":    updated,
+	})
+}
+
+func (h *ServiceKitHandler) DeleteKit(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid service kit ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	if err := h.serviceKitService.DeleteKit(c.Request.Context(), id, userID.(int)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to delete service kit",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Service kit deleted successfully",
+	})
+}
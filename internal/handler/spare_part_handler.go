@@ -39,7 +39,6 @@ type UpdateSparePartRequest struct {
 	Brand         *string `json:"brand,omitempty"`
 	Category      *string `json:"category,omitempty"`
 	Description   *string `json:"description,omitempty"`
-	CostPrice     float64 `json:"cost_price" binding:"required"`
 	SellingPrice  float64 `json:"selling_price" binding:"required"`
 	StockQuantity int     `json:"stock_quantity"`
 	MinStockLevel int     `json:"min_stock_level"`
@@ -47,8 +46,10 @@ type UpdateSparePartRequest struct {
 }
 
 type AdjustStockRequest struct {
-	Adjustment int    `json:"adjustment" binding:"required"`
-	Notes      string `json:"notes,omitempty"`
+	Adjustment    int      `json:"adjustment" binding:"required"`
+	LocationID    *int     `json:"location_id,omitempty"`
+	SerialNumbers []string `json:"serial_numbers,omitempty"`
+	Notes         string   `json:"notes,omitempty"`
 }
 
 type SparePartResponse struct {
@@ -85,6 +86,15 @@ func (h *SparePartHandler) CreateSparePart(c *gin.Context) {
 		return
 	}
 
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in token",
+		})
+		return
+	}
+
 	sparePart := &domain.SparePart{
 		Barcode:       req.Barcode,
 		Name:          req.Name,
@@ -98,7 +108,7 @@ func (h *SparePartHandler) CreateSparePart(c *gin.Context) {
 		Unit:          req.Unit,
 	}
 
-	if err := h.sparePartService.CreateSparePart(c.Request.Context(), sparePart); err != nil {
+	if err := h.sparePartService.CreateSparePart(c.Request.Context(), sparePart, userID.(int)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to create spare part",
 			"message": err.Error(),
@@ -306,7 +316,6 @@ func (h *SparePartHandler) UpdateSparePart(c *gin.Context) {
 		Brand:         req.Brand,
 		Category:      req.Category,
 		Description:   req.Description,
-		CostPrice:     req.CostPrice,
 		SellingPrice:  req.SellingPrice,
 		StockQuantity: req.StockQuantity,
 		MinStockLevel: req.MinStockLevel,
@@ -370,7 +379,7 @@ func (h *SparePartHandler) AdjustStock(c *gin.Context) {
 		return
 	}
 
-	if err := h.sparePartService.AdjustStock(c.Request.Context(), id, req.Adjustment, req.Notes, userIDInt); err != nil {
+	if err := h.sparePartService.AdjustStock(c.Request.Context(), id, req.LocationID, req.Adjustment, req.SerialNumbers, req.Notes, userIDInt); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to adjust stock",
 			"message": err.Error(),
@@ -383,6 +392,103 @@ func (h *SparePartHandler) AdjustStock(c *gin.Context) {
 	})
 }
 
+// GetCostLayers returns the cost layers of a spare part
+func (h *SparePartHandler) GetCostLayers(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid spare part ID",
+			"message": "Spare part ID must be a number",
+		})
+		return
+	}
+
+	includeClosed := c.Query("all") == "true"
+
+	costLayers, err := h.sparePartService.GetCostLayers(c.Request.Context(), id, includeClosed)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get cost layers",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cost layers retrieved successfully",
+		"data":    costLayers,
+	})
+}
+
+type AssignBarcodeRequest struct {
+	Symbology string `json:"symbology"` // ean13 or code128, empty = configured default
+	Replace   bool   `json:"replace"`   // overwrite an existing barcode
+}
+
+// AssignBarcode generates a barcode for a spare part
+func (h *SparePartHandler) AssignBarcode(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid spare part ID",
+			"message": "Spare part ID must be a number",
+		})
+		return
+	}
+
+	var req AssignBarcodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	sparePart, err := h.sparePartService.AssignBarcode(c.Request.Context(), id, domain.BarcodeSymbology(req.Symbology), req.Replace)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to assign barcode",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Barcode assigned successfully",
+		"data":    sparePart,
+	})
+}
+
+// AssignMissingBarcodes generates barcodes for every spare part without one
+func (h *SparePartHandler) AssignMissingBarcodes(c *gin.Context) {
+	var req AssignBarcodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	spareParts, err := h.sparePartService.AssignMissingBarcodes(c.Request.Context(), domain.BarcodeSymbology(req.Symbology))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to assign barcodes",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Barcodes assigned successfully",
+		"data":    spareParts,
+		"count":   len(spareParts),
+	})
+}
+
 // DeleteSparePart deletes a spare part
 func (h *SparePartHandler) DeleteSparePart(c *gin.Context) {
 	idStr := c.Param("id")
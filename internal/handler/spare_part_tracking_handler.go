@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"net/http"
+	"pos-final/internal/domain"
+	"pos-final/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SparePartTrackingHandler struct {
+	trackingService service.SparePartTrackingService
+}
+
+// NewSparePartTrackingHandler creates a new spare part batch and serial
+// tracking handler
+func NewSparePartTrackingHandler(trackingService service.SparePartTrackingService) *SparePartTrackingHandler {
+	return &SparePartTrackingHandler{
+		trackingService: trackingService,
+	}
+}
+
+type UpdateTrackingRequest struct {
+	TrackBatches bool `json:"track_batches"`
+	TrackSerials bool `json:"track_serials"`
+}
+
+type RegisterSerialsRequest struct {
+	LocationID    *int     `json:"location_id,omitempty"`
+	SerialNumbers []string `json:"serial_numbers" binding:"required,min=1"`
+}
+
+// UpdateTracking switches batch/expiry and serial number tracking of a part.
+// Switching batch tracking on opens a batch per location for current stock.
+func (h *SparePartTrackingHandler) UpdateTracking(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid spare part ID"})
+		return
+	}
+
+	var req UpdateTrackingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	sparePart, err := h.trackingService.UpdateTracking(c.Request.Context(), id, req.TrackBatches, req.TrackSerials)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to update tracking",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tracking updated successfully",
+		"data":    sparePart,
+	})
+}
+
+// ListBatches lists a part's batches in FEFO order. Only batches with stock
+// left are listed unless all=true.
+func (h *SparePartTrackingHandler) ListBatches(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid spare part ID"})
+		return
+	}
+
+	locationID, ok := queryLocationID(c)
+	if !ok {
+		return
+	}
+
+	batches, err := h.trackingService.ListBatches(c.Request.Context(), id, locationID, c.Query("all") != "true")
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Failed to retrieve batches",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": batches,
+	})
+}
+
+// ListExpiringBatches lists batches that have expired or expire within the
+// given number of days
+func (h *SparePartTrackingHandler) ListExpiringBatches(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "0"))
+
+	locationID, ok := queryLocationID(c)
+	if !ok {
+		return
+	}
+
+	batches, err := h.trackingService.ListExpiringBatches(c.Request.Context(), days, locationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve expiring batches",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": batches,
+	})
+}
+
+func (h *SparePartTrackingHandler) ListSerials(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid spare part ID"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	var status *domain.SerialStatus
+	if raw := c.Query("status"); raw != "" {
+		s := domain.SerialStatus(raw)
+		status = &s
+	}
+
+	serials, total, err := h.trackingService.ListSerials(c.Request.Context(), id, status, page, limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to retrieve serial numbers",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": serials,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// RegisterSerials records serial numbers of units already in stock at a
+// location
+func (h *SparePartTrackingHandler) RegisterSerials(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid spare part ID"})
+		return
+	}
+
+	var req RegisterSerialsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.trackingService.RegisterSerials(c.Request.Context(), id, req.LocationID, req.SerialNumbers); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to register serial numbers",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Serial numbers registered successfully",
+	})
+}
+
+// FindSerial shows where a serial number is: in stock, or the work order and
+// vehicle it went into
+func (h *SparePartTrackingHandler) FindSerial(c *gin.Context) {
+	serials, err := h.trackingService.FindSerial(c.Request.Context(), c.Param("serial_number"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Failed to find serial number",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": serials,
+	})
+}
+
+// queryLocationID reads the optional location_id query parameter. It writes
+// the error response and returns false when the value is invalid.
+func queryLocationID(c *gin.Context) (*int, bool) {
+	raw := c.Query("location_id")
+	if raw == "" {
+		return nil, true
+	}
+
+	locationID, err := strconv.Atoi(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid location_id"})
+		return nil, false
+	}
+
+	return &locationID, true
+}
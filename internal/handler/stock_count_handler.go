@@ -0,0 +1,293 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"pos-final/internal/domain"
+	"pos-final/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StockCountHandler struct {
+	stockCountService service.StockCountService
+}
+
+// NewStockCountHandler creates a new stock count handler
+func NewStockCountHandler(stockCountService service.StockCountService) *StockCountHandler {
+	return &StockCountHandler{
+		stockCountService: stockCountService,
+	}
+}
+
+type CreateStockCountRequest struct {
+	LocationID   *int    `json:"location_id"` // empty = default location
+	Location     *string `json:"location"`    // area within the location, e.g. a rack
+	Category     *string `json:"category"`
+	SparePartIDs []int   `json:"spare_part_ids"` // partial count; empty = every part (in the category)
+	Notes        *string `json:"notes"`
+}
+
+type ScanStockCountRequest struct {
+	Barcode  string `json:"barcode" binding:"required"` // barcode or part code
+	Quantity *int   `json:"quantity"`                   // default 1, negative to undo a scan
+}
+
+type SetStockCountItemRequest struct {
+	CountedQuantity *int    `json:"counted_quantity"` // null clears the count
+	Notes           *string `json:"notes"`
+}
+
+// CreateCount opens a count session and snapshots expected stock
+func (h *StockCountHandler) CreateCount(c *gin.Context) {
+	var req CreateStockCountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	count := &domain.StockCount{
+		Location:  req.Location,
+		Category:  req.Category,
+		Notes:     req.Notes,
+		CreatedBy: userID.(int),
+	}
+	if req.LocationID != nil {
+		count.LocationID = *req.LocationID
+	}
+
+	if err := h.stockCountService.CreateCount(c.Request.Context(), count, req.SparePartIDs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to create stock count",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Stock count created successfully",
+		"data":    count,
+	})
+}
+
+func (h *StockCountHandler) ListCounts(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	status := c.Query("status")
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	counts, total, err := h.stockCountService.ListCounts(c.Request.Context(), domain.StockCountStatus(status), page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve stock counts",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Stock counts retrieved successfully",
+		"data":    counts,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+func (h *StockCountHandler) GetCount(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stock count ID"})
+		return
+	}
+
+	count, err := h.stockCountService.GetCountByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Stock count not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": count,
+	})
+}
+
+// ListItems returns the count sheet, optionally filtered to counted,
+// uncounted or variance lines
+func (h *StockCountHandler) ListItems(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stock count ID"})
+		return
+	}
+
+	items, err := h.stockCountService.ListItems(c.Request.Context(), id, c.Query("filter"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to retrieve stock count items",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": items,
+	})
+}
+
+// ScanItem counts one scanned unit (or the given quantity) of a part
+func (h *StockCountHandler) ScanItem(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stock count ID"})
+		return
+	}
+
+	var req ScanStockCountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	quantity := 1
+	if req.Quantity != nil {
+		quantity = *req.Quantity
+	}
+
+	item, err := h.stockCountService.ScanItem(c.Request.Context(), id, req.Barcode, quantity, userID.(int))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to record scan",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Scan recorded successfully",
+		"data":    item,
+	})
+}
+
+// SetItemCount overwrites the count of one line
+func (h *StockCountHandler) SetItemCount(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stock count ID"})
+		return
+	}
+
+	itemID, err := strconv.Atoi(c.Param("item_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stock count item ID"})
+		return
+	}
+
+	var req SetStockCountItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	item, err := h.stockCountService.SetItemCount(c.Request.Context(), id, itemID, req.CountedQuantity, userID.(int), req.Notes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to update stock count item",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Stock count item updated successfully",
+		"data":    item,
+	})
+}
+
+func (h *StockCountHandler) SubmitCount(c *gin.Context) {
+	h.changeStatus(c, h.stockCountService.SubmitCount, "submit", "Stock count submitted for approval")
+}
+
+func (h *StockCountHandler) ReopenCount(c *gin.Context) {
+	h.changeStatus(c, h.stockCountService.ReopenCount, "reopen", "Stock count reopened for counting")
+}
+
+// ApproveCount posts the variances to stock and freezes the count
+func (h *StockCountHandler) ApproveCount(c *gin.Context) {
+	h.changeStatus(c, h.stockCountService.ApproveCount, "approve", "Stock count approved and posted")
+}
+
+func (h *StockCountHandler) CancelCount(c *gin.Context) {
+	h.changeStatus(c, h.stockCountService.CancelCount, "cancel", "Stock count cancelled")
+}
+
+func (h *StockCountHandler) changeStatus(
+	c *gin.Context,
+	action func(ctx context.Context, id int, userID int) (*domain.StockCount, error),
+	verb, message string,
+) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stock count ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	count, err := action(c.Request.Context(), id, userID.(int))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to " + verb + " stock count",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"data":    count,
+	})
+}
@@ -0,0 +1,319 @@
+package handler
+
+import (
+	"net/http"
+	"pos-final/internal/domain"
+	"pos-final/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StockLocationHandler struct {
+	stockLocationService service.StockLocationService
+}
+
+// NewStockLocationHandler creates a new stock location handler
+func NewStockLocationHandler(stockLocationService service.StockLocationService) *StockLocationHandler {
+	return &StockLocationHandler{
+		stockLocationService: stockLocationService,
+	}
+}
+
+type StockLocationRequest struct {
+	Code         string  `json:"code" binding:"required"`
+	Name         string  `json:"name" binding:"required"`
+	LocationType string  `json:"location_type"` // warehouse (default), workshop, branch
+	IsActive     *bool   `json:"is_active"`
+	Notes        *string `json:"notes"`
+}
+
+type UpdateSparePartStockRequest struct {
+	Bin           *string `json:"bin"`
+	MinStockLevel *int    `json:"min_stock_level"` // null = not monitored at this location
+}
+
+func (h *StockLocationHandler) CreateLocation(c *gin.Context) {
+	var req StockLocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	location := &domain.StockLocation{
+		Code:         req.Code,
+		Name:         req.Name,
+		LocationType: domain.StockLocationType(req.LocationType),
+		IsActive:     true,
+		Notes:        req.Notes,
+	}
+	if req.IsActive != nil {
+		location.IsActive = *req.IsActive
+	}
+
+	if err := h.stockLocationService.CreateLocation(c.Request.Context(), location); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to create stock location",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Stock location created successfully",
+		"data":    location,
+	})
+}
+
+func (h *StockLocationHandler) ListLocations(c *gin.Context) {
+	includeInactive := c.Query("include_inactive") == "true"
+
+	locations, err := h.stockLocationService.ListLocations(c.Request.Context(), includeInactive)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve stock locations",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": locations,
+	})
+}
+
+func (h *StockLocationHandler) GetLocation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stock location ID"})
+		return
+	}
+
+	location, err := h.stockLocationService.GetLocationByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Stock location not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": location,
+	})
+}
+
+func (h *StockLocationHandler) UpdateLocation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stock location ID"})
+		return
+	}
+
+	var req StockLocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	location, err := h.stockLocationService.GetLocationByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Stock location not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	location.Code = req.Code
+	location.Name = req.Name
+	location.Notes = req.Notes
+	if req.LocationType != "" {
+		location.LocationType = domain.StockLocationType(req.LocationType)
+	}
+	if req.IsActive != nil {
+		location.IsActive = *req.IsActive
+	}
+
+	if err := h.stockLocationService.UpdateLocation(c.Request.Context(), location); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to update stock location",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Stock location updated successfully",
+		"data":    location,
+	})
+}
+
+// SetDefaultLocation makes the location the one used when a stock
+// transaction does not name one
+func (h *StockLocationHandler) SetDefaultLocation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stock location ID"})
+		return
+	}
+
+	if err := h.stockLocationService.SetDefaultLocation(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to set default stock location",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Default stock location updated successfully",
+	})
+}
+
+func (h *StockLocationHandler) DeleteLocation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stock location ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	if err := h.stockLocationService.DeleteLocation(c.Request.Context(), id, userID.(int)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to delete stock location",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Stock location deleted successfully",
+	})
+}
+
+// GetLocationStock lists every part stocked at the location, by bin
+func (h *StockLocationHandler) GetLocationStock(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stock location ID"})
+		return
+	}
+
+	stocks, err := h.stockLocationService.GetLocationStock(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to retrieve location stock",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": stocks,
+	})
+}
+
+// ListLowStock lists stock at or below each location's own minimum,
+// optionally for one location
+func (h *StockLocationHandler) ListLowStock(c *gin.Context) {
+	var locationID *int
+	if raw := c.Query("location_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid location_id"})
+			return
+		}
+		locationID = &id
+	}
+
+	stocks, err := h.stockLocationService.ListLowStock(c.Request.Context(), locationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve low stock",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": stocks,
+	})
+}
+
+// GetSparePartStock shows where a part is stocked and how much each
+// location holds
+func (h *StockLocationHandler) GetSparePartStock(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid spare part ID"})
+		return
+	}
+
+	stocks, err := h.stockLocationService.GetSparePartStock(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Failed to retrieve spare part stock",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": stocks,
+	})
+}
+
+// UpdateSparePartStock sets the bin and minimum stock of a part at a location
+func (h *StockLocationHandler) UpdateSparePartStock(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid spare part ID"})
+		return
+	}
+
+	locationID, err := strconv.Atoi(c.Param("location_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stock location ID"})
+		return
+	}
+
+	var req UpdateSparePartStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	stock, err := h.stockLocationService.UpdateStockSettings(c.Request.Context(), &domain.SparePartStock{
+		SparePartID:   id,
+		LocationID:    locationID,
+		Bin:           req.Bin,
+		MinStockLevel: req.MinStockLevel,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to update spare part stock",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Spare part stock updated successfully",
+		"data":    stock,
+	})
+}
@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"pos-final/internal/domain"
+	"pos-final/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StockTransferHandler struct {
+	stockTransferService service.StockTransferService
+}
+
+// NewStockTransferHandler creates a new stock transfer handler
+func NewStockTransferHandler(stockTransferService service.StockTransferService) *StockTransferHandler {
+	return &StockTransferHandler{
+		stockTransferService: stockTransferService,
+	}
+}
+
+type CreateStockTransferRequest struct {
+	FromLocationID int                        `json:"from_location_id" binding:"required"`
+	ToLocationID   int                        `json:"to_location_id" binding:"required"`
+	Notes          *string                    `json:"notes"`
+	Items          []StockTransferItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type StockTransferItemRequest struct {
+	SparePartID   int      `json:"spare_part_id" binding:"required"`
+	Quantity      int      `json:"quantity" binding:"required,min=1"`
+	SerialNumbers []string `json:"serial_numbers,omitempty"`
+}
+
+func (h *StockTransferHandler) CreateTransfer(c *gin.Context) {
+	var req CreateStockTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	transfer := &domain.StockTransfer{
+		FromLocationID: req.FromLocationID,
+		ToLocationID:   req.ToLocationID,
+		Notes:          req.Notes,
+		CreatedBy:      userID.(int),
+	}
+	for _, item := range req.Items {
+		transfer.Items = append(transfer.Items, &domain.StockTransferItem{
+			SparePartID:   item.SparePartID,
+			Quantity:      item.Quantity,
+			SerialNumbers: item.SerialNumbers,
+		})
+	}
+
+	if err := h.stockTransferService.CreateTransfer(c.Request.Context(), transfer); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to create stock transfer",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	created, err := h.stockTransferService.GetTransferByID(c.Request.Context(), transfer.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve stock transfer",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Stock transfer created successfully",
+		"data":    created,
+	})
+}
+
+// ListTransfers lists transfers, optionally by status or by a location on
+// either end
+func (h *StockTransferHandler) ListTransfers(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	status := c.Query("status")
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	var locationID *int
+	if raw := c.Query("location_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid location_id"})
+			return
+		}
+		locationID = &id
+	}
+
+	transfers, total, err := h.stockTransferService.ListTransfers(c.Request.Context(), domain.StockTransferStatus(status), locationID, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve stock transfers",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Stock transfers retrieved successfully",
+		"data":    transfers,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+func (h *StockTransferHandler) GetTransfer(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stock transfer ID"})
+		return
+	}
+
+	transfer, err := h.stockTransferService.GetTransferByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Stock transfer not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": transfer,
+	})
+}
+
+// ShipTransfer takes the stock out of the source location
+func (h *StockTransferHandler) ShipTransfer(c *gin.Context) {
+	h.changeStatus(c, h.stockTransferService.ShipTransfer, "ship", "Stock transfer shipped successfully")
+}
+
+// ReceiveTransfer books the stock into the destination location
+func (h *StockTransferHandler) ReceiveTransfer(c *gin.Context) {
+	h.changeStatus(c, h.stockTransferService.ReceiveTransfer, "receive", "Stock transfer received successfully")
+}
+
+// CancelTransfer cancels a draft or in-transit transfer
+func (h *StockTransferHandler) CancelTransfer(c *gin.Context) {
+	h.changeStatus(c, h.stockTransferService.CancelTransfer, "cancel", "Stock transfer cancelled successfully")
+}
+
+func (h *StockTransferHandler) changeStatus(
+	c *gin.Context,
+	action func(ctx context.Context, id int, userID int) (*domain.StockTransfer, error),
+	verb, message string,
+) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stock transfer ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	transfer, err := action(c.Request.Context(), id, userID.(int))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to " + verb + " stock transfer",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"data":    transfer,
+	})
+}
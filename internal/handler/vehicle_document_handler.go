@@ -0,0 +1,321 @@
+package handler
+
+import (
+	"net/http"
+	"pos-final/internal/domain"
+	"pos-final/internal/service"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VehicleDocumentHandler struct {
+	documentService service.VehicleDocumentService
+}
+
+// NewVehicleDocumentHandler creates a new vehicle document handler
+func NewVehicleDocumentHandler(documentService service.VehicleDocumentService) *VehicleDocumentHandler {
+	return &VehicleDocumentHandler{
+		documentService: documentService,
+	}
+}
+
+type CreateVehicleDocumentRequest struct {
+	VehicleID       int     `json:"vehicle_id" binding:"required"`
+	DocumentType    string  `json:"document_type" binding:"required,oneof=bpkb stnk faktur other"`
+	DocumentNumber  *string `json:"document_number"`
+	CustodyStatus   string  `json:"custody_status" binding:"omitempty,oneof=pending in_custody"`
+	CustodyLocation *string `json:"custody_location"`
+	ExpiryDate      *string `json:"expiry_date"`   // YYYY-MM-DD
+	TaxDueDate      *string `json:"tax_due_date"`  // YYYY-MM-DD
+	ReceivedDate    *string `json:"received_date"` // YYYY-MM-DD
+	Notes           *string `json:"notes"`
+}
+
+type UpdateVehicleDocumentRequest struct {
+	DocumentNumber  *string `json:"document_number"`
+	CustodyStatus   string  `json:"custody_status" binding:"required,oneof=pending in_custody"`
+	CustodyLocation *string `json:"custody_location"`
+	ExpiryDate      *string `json:"expiry_date"`   // YYYY-MM-DD
+	TaxDueDate      *string `json:"tax_due_date"`  // YYYY-MM-DD
+	ReceivedDate    *string `json:"received_date"` // YYYY-MM-DD
+	Notes           *string `json:"notes"`
+}
+
+type HandOverVehicleDocumentRequest struct {
+	SalesInvoiceID int     `json:"sales_invoice_id" binding:"required"`
+	HandedOverTo   *string `json:"handed_over_to"`
+	HandoverDate   *string `json:"handover_date"` // YYYY-MM-DD, defaults to now
+}
+
+func (h *VehicleDocumentHandler) CreateDocument(c *gin.Context) {
+	var req CreateVehicleDocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	document := &domain.VehicleDocument{
+		VehicleID:       req.VehicleID,
+		DocumentType:    domain.VehicleDocumentType(req.DocumentType),
+		DocumentNumber:  req.DocumentNumber,
+		CustodyStatus:   domain.DocumentCustodyStatus(req.CustodyStatus),
+		CustodyLocation: req.CustodyLocation,
+		Notes:           req.Notes,
+		CreatedBy:       userID.(int),
+	}
+
+	var err error
+	if document.ExpiryDate, err = parseDocumentDate(req.ExpiryDate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid expiry_date format. Use YYYY-MM-DD"})
+		return
+	}
+	if document.TaxDueDate, err = parseDocumentDate(req.TaxDueDate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tax_due_date format. Use YYYY-MM-DD"})
+		return
+	}
+	if document.ReceivedDate, err = parseDocumentDate(req.ReceivedDate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid received_date format. Use YYYY-MM-DD"})
+		return
+	}
+
+	if err := h.documentService.CreateDocument(c.Request.Context(), document); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to create vehicle document",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Vehicle document created successfully",
+		"data":    document,
+	})
+}
+
+func (h *VehicleDocumentHandler) GetDocument(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID"})
+		return
+	}
+
+	document, err := h.documentService.GetDocumentByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Vehicle document not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Vehicle document retrieved successfully",
+		"data":    document,
+	})
+}
+
+func (h *VehicleDocumentHandler) ListVehicleDocuments(c *gin.Context) {
+	vehicleID, err := strconv.Atoi(c.Param("vehicle_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid vehicle ID"})
+		return
+	}
+
+	documents, err := h.documentService.ListDocumentsByVehicle(c.Request.Context(), vehicleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve vehicle documents",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Vehicle documents retrieved successfully",
+		"data":    documents,
+	})
+}
+
+func (h *VehicleDocumentHandler) UpdateDocument(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID"})
+		return
+	}
+
+	var req UpdateVehicleDocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	document := &domain.VehicleDocument{
+		DocumentNumber:  req.DocumentNumber,
+		CustodyStatus:   domain.DocumentCustodyStatus(req.CustodyStatus),
+		CustodyLocation: req.CustodyLocation,
+		Notes:           req.Notes,
+	}
+	document.ID = id
+
+	if document.ExpiryDate, err = parseDocumentDate(req.ExpiryDate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid expiry_date format. Use YYYY-MM-DD"})
+		return
+	}
+	if document.TaxDueDate, err = parseDocumentDate(req.TaxDueDate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tax_due_date format. Use YYYY-MM-DD"})
+		return
+	}
+	if document.ReceivedDate, err = parseDocumentDate(req.ReceivedDate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid received_date format. Use YYYY-MM-DD"})
+		return
+	}
+
+	if err := h.documentService.UpdateDocument(c.Request.Context(), document); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to update vehicle document",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	updated, err := h.documentService.GetDocumentByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve updated vehicle document",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Vehicle document updated successfully",
+		"data":    updated,
+	})
+}
+
+func (h *VehicleDocumentHandler) DeleteDocument(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	if err := h.documentService.DeleteDocument(c.Request.Context(), id, userID.(int)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to delete vehicle document",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Vehicle document deleted successfully",
+	})
+}
+
+// HandOverDocument records the handover of a document to the buyer
+func (h *VehicleDocumentHandler) HandOverDocument(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID"})
+		return
+	}
+
+	var req HandOverVehicleDocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	handedOverBy := userID.(int)
+
+	document := &domain.VehicleDocument{
+		SalesInvoiceID: &req.SalesInvoiceID,
+		HandedOverTo:   req.HandedOverTo,
+		HandedOverBy:   &handedOverBy,
+	}
+	document.ID = id
+
+	if document.HandedOverAt, err = parseDocumentDate(req.HandoverDate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid handover_date format. Use YYYY-MM-DD"})
+		return
+	}
+
+	if err := h.documentService.HandOverDocument(c.Request.Context(), document); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to hand over vehicle document",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Vehicle document handed over successfully",
+		"data":    document,
+	})
+}
+
+// ListExpiringDocuments lists held documents expiring or with tax due within ?days= (default 30)
+func (h *VehicleDocumentHandler) ListExpiringDocuments(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid days parameter"})
+		return
+	}
+
+	documents, err := h.documentService.ListExpiringDocuments(c.Request.Context(), days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve expiring documents",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Expiring documents retrieved successfully",
+		"data":    documents,
+		"days":    days,
+	})
+}
+
+func parseDocumentDate(value *string) (*time.Time, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+
+	date, err := time.Parse("2006-01-02", *value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &date, nil
+}
@@ -21,6 +21,14 @@ func NewVehicleHandler(vehicleService service.VehicleService) *VehicleHandler {
 	}
 }
 
+type AddVehicleCostRequest struct {
+	CostType    domain.VehicleCostType `json:"cost_type" binding:"required"`
+	Amount      float64                `json:"amount" binding:"required,gt=0"`
+	CostDate    string                 `json:"cost_date"` // YYYY-MM-DD, defaults to today
+	Description *string                `json:"description"`
+	Reference   *string                `json:"reference"`
+}
+
 type CreateVehicleRequest struct {
 	CategoryID      int                     `json:"category_id" binding:"required"`
 	Brand           string                  `json:"brand" binding:"required"`
@@ -84,6 +92,7 @@ type VehicleResponse struct {
 	PrimaryPhoto    *string                 `json:"primary_photo,omitempty"`
 	PurchasedDate   *time.Time              `json:"purchased_date,omitempty"`
 	SoldDate        *time.Time              `json:"sold_date,omitempty"`
+	IsConsignment   bool                    `json:"is_consignment"`
 	CreatedAt       string                  `json:"created_at"`
 	UpdatedAt       string                  `json:"updated_at"`
 }
@@ -375,6 +384,129 @@ func (h *VehicleHandler) DeleteVehicle(c *gin.Context) {
 	})
 }
 
+// GetCostBreakdown shows the components of a vehicle's HPP
+func (h *VehicleHandler) GetCostBreakdown(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid vehicle ID",
+			"message": "Vehicle ID must be a number",
+		})
+		return
+	}
+
+	breakdown, err := h.vehicleService.GetCostBreakdown(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Failed to get vehicle cost breakdown",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": breakdown,
+	})
+}
+
+// AddCost records a landed cost (transport, fees, sublet repairs) against a
+// vehicle and recalculates its HPP
+func (h *VehicleHandler) AddCost(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid vehicle ID",
+			"message": "Vehicle ID must be a number",
+		})
+		return
+	}
+
+	var req AddVehicleCostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in token",
+		})
+		return
+	}
+	createdBy := userID.(int)
+
+	cost := &domain.VehicleCost{
+		VehicleID:   id,
+		CostType:    req.CostType,
+		Amount:      req.Amount,
+		Description: req.Description,
+		Reference:   req.Reference,
+		CreatedBy:   &createdBy,
+	}
+
+	if req.CostDate != "" {
+		costDate, err := time.Parse("2006-01-02", req.CostDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid cost date",
+				"message": "Use YYYY-MM-DD format",
+			})
+			return
+		}
+		cost.CostDate = costDate
+	}
+
+	if err := h.vehicleService.AddCost(c.Request.Context(), cost); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to add vehicle cost",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Vehicle cost added successfully",
+		"data":    cost,
+	})
+}
+
+func (h *VehicleHandler) DeleteCost(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("cost_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid cost ID",
+			"message": "Cost ID must be a number",
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "User ID not found in token",
+		})
+		return
+	}
+
+	if err := h.vehicleService.DeleteCost(c.Request.Context(), id, userID.(int)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to delete vehicle cost",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Vehicle cost deleted successfully",
+	})
+}
+
 // toVehicleResponse converts domain.Vehicle to VehicleResponse
 func (h *VehicleHandler) toVehicleResponse(vehicle *domain.Vehicle) VehicleResponse {
 	response := VehicleResponse{
@@ -399,6 +531,7 @@ func (h *VehicleHandler) toVehicleResponse(vehicle *domain.Vehicle) VehicleRespo
 		PrimaryPhoto:   vehicle.PrimaryPhoto,
 		PurchasedDate:  vehicle.PurchasedDate,
 		SoldDate:       vehicle.SoldDate,
+		IsConsignment:  vehicle.IsConsignment,
 		CreatedAt:      vehicle.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:      vehicle.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
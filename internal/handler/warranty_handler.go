@@ -0,0 +1,286 @@
+package handler
+
+import (
+	"net/http"
+	"pos-final/internal/domain"
+	"pos-final/internal/service"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WarrantyHandler struct {
+	warrantyService service.WarrantyService
+}
+
+// NewWarrantyHandler creates a new warranty handler
+func NewWarrantyHandler(warrantyService service.WarrantyService) *WarrantyHandler {
+	return &WarrantyHandler{
+		warrantyService: warrantyService,
+	}
+}
+
+type CreateWarrantyRequest struct {
+	SalesInvoiceID int     `json:"sales_invoice_id" binding:"required"`
+	DurationMonths int     `json:"duration_months" binding:"min=0"`
+	MileageLimit   *int    `json:"mileage_limit"`
+	StartMileage   *int    `json:"start_mileage"`
+	Coverage       string  `json:"coverage"`
+	StartDate      *string `json:"start_date"` // YYYY-MM-DD, defaults to the sale date
+	Notes          *string `json:"notes"`
+}
+
+type CreateWarrantyClaimRequest struct {
+	Complaint      string `json:"complaint" binding:"required"`
+	CurrentMileage *int   `json:"current_mileage"`
+	MechanicID     int    `json:"mechanic_id"` // 0 = auto-assign
+}
+
+func (h *WarrantyHandler) CreateWarranty(c *gin.Context) {
+	var req CreateWarrantyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	warranty := &domain.Warranty{
+		SalesInvoiceID: req.SalesInvoiceID,
+		DurationMonths: req.DurationMonths,
+		MileageLimit:   req.MileageLimit,
+		StartMileage:   req.StartMileage,
+		Coverage:       req.Coverage,
+		Notes:          req.Notes,
+		CreatedBy:      userID.(int),
+	}
+
+	if req.StartDate != nil && *req.StartDate != "" {
+		startDate, err := time.Parse("2006-01-02", *req.StartDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format. Use YYYY-MM-DD"})
+			return
+		}
+		warranty.StartDate = startDate
+	}
+
+	if err := h.warrantyService.CreateWarranty(c.Request.Context(), warranty); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to create warranty",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Warranty created successfully",
+		"data":    warranty,
+	})
+}
+
+func (h *WarrantyHandler) GetWarranty(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid warranty ID"})
+		return
+	}
+
+	warranty, err := h.warrantyService.GetWarrantyByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Warranty not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Warranty retrieved successfully",
+		"data":    warranty,
+	})
+}
+
+// ListWarranties lists warranties, or looks them up by plate_number / chassis_number
+func (h *WarrantyHandler) ListWarranties(c *gin.Context) {
+	plateNumber := c.Query("plate_number")
+	chassisNumber := c.Query("chassis_number")
+
+	if plateNumber != "" || chassisNumber != "" {
+		warranties, err := h.warrantyService.LookupWarranties(c.Request.Context(), plateNumber, chassisNumber)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to look up warranties",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Warranties retrieved successfully",
+			"data":    warranties,
+		})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	warranties, total, err := h.warrantyService.ListWarranties(c.Request.Context(), page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve warranties",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Warranties retrieved successfully",
+		"data":    warranties,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+func (h *WarrantyHandler) VoidWarranty(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid warranty ID"})
+		return
+	}
+
+	if err := h.warrantyService.VoidWarranty(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to void warranty",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Warranty voided successfully",
+	})
+}
+
+func (h *WarrantyHandler) CreateClaim(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid warranty ID"})
+		return
+	}
+
+	var req CreateWarrantyClaimRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	claim := &domain.WarrantyClaim{
+		WarrantyID:     id,
+		Complaint:      req.Complaint,
+		CurrentMileage: req.CurrentMileage,
+		CreatedBy:      userID.(int),
+	}
+
+	if err := h.warrantyService.CreateClaim(c.Request.Context(), claim, req.MechanicID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to create warranty claim",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Warranty claim created successfully",
+		"data":    claim,
+	})
+}
+
+func (h *WarrantyHandler) ListClaims(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid warranty ID"})
+		return
+	}
+
+	claims, err := h.warrantyService.ListClaims(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve warranty claims",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Warranty claims retrieved successfully",
+		"data":    claims,
+	})
+}
+
+func (h *WarrantyHandler) GetWarrantyCostReport(c *gin.Context) {
+	startDateStr := c.Query("start_date")
+	endDateStr := c.Query("end_date")
+
+	if startDateStr == "" || endDateStr == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "start_date and end_date parameters are required (YYYY-MM-DD format)",
+		})
+		return
+	}
+
+	startDate, err := time.Parse("2006-01-02", startDateStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format. Use YYYY-MM-DD"})
+		return
+	}
+
+	endDate, err := time.Parse("2006-01-02", endDateStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format. Use YYYY-MM-DD"})
+		return
+	}
+
+	// Adjust end date to include the full day
+	endDate = endDate.Add(24 * time.Hour).Add(-time.Second)
+
+	report, err := h.warrantyService.GetWarrantyCostReport(c.Request.Context(), startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to generate warranty cost report",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": report,
+	})
+}
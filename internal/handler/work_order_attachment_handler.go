@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"net/http"
+	"pos-final/internal/domain"
+	"pos-final/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WorkOrderAttachmentHandler struct {
+	attachmentService service.WorkOrderAttachmentService
+	fileService       service.FileService
+}
+
+// NewWorkOrderAttachmentHandler creates a new work order attachment handler
+func NewWorkOrderAttachmentHandler(attachmentService service.WorkOrderAttachmentService, fileService service.FileService) *WorkOrderAttachmentHandler {
+	return &WorkOrderAttachmentHandler{
+		attachmentService: attachmentService,
+		fileService:       fileService,
+	}
+}
+
+// UploadAttachment stores a photo or document against a work order. The
+// multipart form takes the file, its stage, and an optional caption and task.
+func (h *WorkOrderAttachmentHandler) UploadAttachment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid work order ID"})
+		return
+	}
+
+	stage := domain.AttachmentStage(c.PostForm("stage"))
+	if stage != domain.AttachmentStageBefore && stage != domain.AttachmentStageDuring && stage != domain.AttachmentStageAfter {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "stage must be before, during or after"})
+		return
+	}
+
+	var taskID *int
+	if taskIDStr := c.PostForm("task_id"); taskIDStr != "" {
+		parsed, err := strconv.Atoi(taskIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+			return
+		}
+		taskID = &parsed
+	}
+
+	var caption *string
+	if captionStr := c.PostForm("caption"); captionStr != "" {
+		caption = &captionStr
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "No file uploaded",
+			"details": "Please select a photo or document file",
+		})
+		return
+	}
+
+	// Images are stored as photos; anything else must be a valid document
+	fileType := domain.AttachmentFileTypePhoto
+	if err := h.fileService.ValidateImage(file); err != nil {
+		if err := h.fileService.ValidateDocument(file); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid file",
+				"details": err.Error(),
+			})
+			return
+		}
+		fileType = domain.AttachmentFileTypeDocument
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	role, _ := c.Get("role")
+	userRole, _ := role.(domain.UserRole)
+
+	filePath, err := h.fileService.SaveFile(c.Request.Context(), file, "work_orders")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to upload attachment",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	attachment := &domain.WorkOrderAttachment{
+		WorkOrderID:     id,
+		WorkOrderTaskID: taskID,
+		Stage:           stage,
+		FileType:        fileType,
+		FilePath:        filePath,
+		Caption:         caption,
+		UploadedBy:      userID.(int),
+	}
+
+	if err := h.attachmentService.AddAttachment(c.Request.Context(), attachment, userRole); err != nil {
+		// Do not keep files that are not linked to the work order
+		_ = h.fileService.DeleteFile(c.Request.Context(), filePath)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to add attachment",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Attachment uploaded successfully",
+		"data":    attachment,
+	})
+}
+
+func (h *WorkOrderAttachmentHandler) ListAttachments(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid work order ID"})
+		return
+	}
+
+	attachments, err := h.attachmentService.ListAttachments(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve attachments",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": attachments,
+	})
+}
+
+func (h *WorkOrderAttachmentHandler) DeleteAttachment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("attachment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	role, _ := c.Get("role")
+	userRole, _ := role.(domain.UserRole)
+
+	if err := h.attachmentService.DeleteAttachment(c.Request.Context(), id, userID.(int), userRole); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to delete attachment",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Attachment deleted successfully",
+	})
+}
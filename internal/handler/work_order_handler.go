@@ -21,10 +21,12 @@ func NewWorkOrderHandler(workOrderService service.WorkOrderService) *WorkOrderHa
 }
 
 type CreateWorkOrderRequest struct {
-	VehicleID          int     `json:"vehicle_id" binding:"required"`
+	VehicleID          *int    `json:"vehicle_id"`          // stock vehicle for reconditioning
+	CustomerVehicleID  *int    `json:"customer_vehicle_id"` // customer vehicle for a billed service job
 	Description        string  `json:"description" binding:"required"`
-	AssignedMechanicID int     `json:"assigned_mechanic_id" binding:"required"`
+	AssignedMechanicID int     `json:"assigned_mechanic_id"` // 0 = auto-assign
 	LaborCost          float64 `json:"labor_cost" binding:"min=0"`
+	LaborPrice         float64 `json:"labor_price" binding:"min=0"` // labor charged to the customer
 	Notes              *string `json:"notes"`
 }
 
@@ -36,9 +38,39 @@ type AssignMechanicRequest struct {
 	MechanicID int `json:"mechanic_id" binding:"required"`
 }
 
+type UpdateStatusRequest struct {
+	Status string  `json:"status" binding:"required,oneof=pending in_progress on_hold waiting_parts completed cancelled"`
+	Reason *string `json:"reason"`
+}
+
+type WorkOrderTaskRequest struct {
+	Title              string  `json:"title" binding:"required"`
+	EstimatedHours     float64 `json:"estimated_hours" binding:"min=0"`
+	AssignedMechanicID *int    `json:"assigned_mechanic_id"` // defaults to the work order's mechanic
+	Notes              *string `json:"notes"`
+	SortOrder          int     `json:"sort_order"`
+}
+
+type UpdateTaskStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=pending in_progress completed skipped"`
+}
+
 type UsePartRequest struct {
-	SparePartID int `json:"spare_part_id" binding:"required"`
-	Quantity    int `json:"quantity" binding:"required,min=1"`
+	SparePartID   int      `json:"spare_part_id" binding:"required"`
+	LocationID    *int     `json:"location_id,omitempty"`
+	Quantity      int      `json:"quantity" binding:"required,min=1"`
+	SerialNumbers []string `json:"serial_numbers"` // one per unit for serial-tracked parts
+}
+
+type ApplyServiceKitRequest struct {
+	ServiceKitID int  `json:"service_kit_id" binding:"required"`
+	LocationID   *int `json:"location_id,omitempty"`
+}
+
+type ReturnPartRequest struct {
+	Quantity      int      `json:"quantity" binding:"required,min=1"`
+	Reason        string   `json:"reason" binding:"required"`
+	SerialNumbers []string `json:"serial_numbers"` // units coming back, for serial-tracked parts
 }
 
 func (h *WorkOrderHandler) CreateWorkOrder(c *gin.Context) {
@@ -58,15 +90,28 @@ func (h *WorkOrderHandler) CreateWorkOrder(c *gin.Context) {
 		return
 	}
 
+	if (req.VehicleID == nil) == (req.CustomerVehicleID == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Provide exactly one of vehicle_id or customer_vehicle_id"})
+		return
+	}
+
+	orderType := domain.WorkOrderTypeReconditioning
+	if req.CustomerVehicleID != nil {
+		orderType = domain.WorkOrderTypeCustomerService
+	}
+
 	// Create work order
 	workOrder := &domain.WorkOrder{
+		OrderType:          orderType,
 		VehicleID:          req.VehicleID,
+		CustomerVehicleID:  req.CustomerVehicleID,
 		Description:        req.Description,
 		AssignedMechanicID: req.AssignedMechanicID,
 		Status:             domain.WorkOrderStatusPending,
 		ProgressPercentage: 0,
 		TotalPartsCost:     0,
 		LaborCost:          req.LaborCost,
+		LaborPrice:         req.LaborPrice,
 		Notes:              req.Notes,
 		CreatedBy:          userID.(int),
 	}
@@ -196,8 +241,14 @@ func (h *WorkOrderHandler) StartWorkOrder(c *gin.Context) {
 		return
 	}
 
-	if err := h.workOrderService.StartWorkOrder(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	if err := h.workOrderService.StartWorkOrder(c.Request.Context(), id, userID.(int)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Failed to start work order",
 			"details": err.Error(),
 		})
@@ -216,8 +267,14 @@ func (h *WorkOrderHandler) CompleteWorkOrder(c *gin.Context) {
 		return
 	}
 
-	if err := h.workOrderService.CompleteWorkOrder(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	if err := h.workOrderService.CompleteWorkOrder(c.Request.Context(), id, userID.(int)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Failed to complete work order",
 			"details": err.Error(),
 		})
@@ -229,6 +286,64 @@ func (h *WorkOrderHandler) CompleteWorkOrder(c *gin.Context) {
 	})
 }
 
+// UpdateStatus moves a work order to a new status, e.g. on hold or waiting for parts
+func (h *WorkOrderHandler) UpdateStatus(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid work order ID"})
+		return
+	}
+
+	var req UpdateStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	if err := h.workOrderService.UpdateWorkOrderStatus(c.Request.Context(), id, domain.WorkOrderStatus(req.Status), userID.(int), req.Reason); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to update work order status",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Work order status updated successfully",
+	})
+}
+
+// GetStatusHistory lists the status changes of a work order, oldest first
+func (h *WorkOrderHandler) GetStatusHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid work order ID"})
+		return
+	}
+
+	history, err := h.workOrderService.GetStatusHistory(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get work order status history",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": history,
+	})
+}
+
 func (h *WorkOrderHandler) UpdateProgress(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -310,7 +425,8 @@ func (h *WorkOrderHandler) UsePart(c *gin.Context) {
 		return
 	}
 
-	if err := h.workOrderService.UsePartInWorkOrder(c.Request.Context(), id, req.SparePartID, req.Quantity, userID.(int)); err != nil {
+	workOrderPart, err := h.workOrderService.UsePartInWorkOrder(c.Request.Context(), id, req.SparePartID, req.LocationID, req.Quantity, req.SerialNumbers, userID.(int))
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to use part in work order",
 			"details": err.Error(),
@@ -320,5 +436,254 @@ func (h *WorkOrderHandler) UsePart(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Part used successfully",
+		"data":    workOrderPart,
+	})
+}
+
+// ApplyServiceKit issues all parts of a service kit to the work order
+func (h *WorkOrderHandler) ApplyServiceKit(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid work order ID"})
+		return
+	}
+
+	var req ApplyServiceKitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	application, err := h.workOrderService.ApplyServiceKit(c.Request.Context(), id, req.ServiceKitID, req.LocationID, userID.(int))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to apply service kit",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Service kit applied successfully",
+		"data":    application,
+	})
+}
+
+// ReturnPart sends unused quantity of a work order part back to stock
+func (h *WorkOrderHandler) ReturnPart(c *gin.Context) {
+	partID, err := strconv.Atoi(c.Param("part_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid work order part ID"})
+		return
+	}
+
+	var req ReturnPartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	partReturn, err := h.workOrderService.ReturnPart(c.Request.Context(), partID, req.Quantity, req.Reason, req.SerialNumbers, userID.(int))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to return part",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Part returned successfully",
+		"data":    partReturn,
+	})
+}
+
+// ListTasks lists the checklist tasks of a work order
+func (h *WorkOrderHandler) ListTasks(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid work order ID"})
+		return
+	}
+
+	tasks, err := h.workOrderService.ListTasks(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to get work order tasks",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": tasks,
+	})
+}
+
+// AddTask adds a job to the work order checklist
+func (h *WorkOrderHandler) AddTask(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid work order ID"})
+		return
+	}
+
+	var req WorkOrderTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	task := &domain.WorkOrderTask{
+		WorkOrderID:        id,
+		Title:              req.Title,
+		EstimatedHours:     req.EstimatedHours,
+		AssignedMechanicID: req.AssignedMechanicID,
+		Notes:              req.Notes,
+		SortOrder:          req.SortOrder,
+		CreatedBy:          userID.(int),
+	}
+
+	if err := h.workOrderService.AddTask(c.Request.Context(), task); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to add work order task",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Work order task added successfully",
+		"data":    task,
+	})
+}
+
+func (h *WorkOrderHandler) UpdateTask(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("task_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	var req WorkOrderTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	task := &domain.WorkOrderTask{
+		Title:              req.Title,
+		EstimatedHours:     req.EstimatedHours,
+		AssignedMechanicID: req.AssignedMechanicID,
+		Notes:              req.Notes,
+		SortOrder:          req.SortOrder,
+	}
+	task.ID = id
+
+	if err := h.workOrderService.UpdateTask(c.Request.Context(), task); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to update work order task",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Work order task updated successfully",
+		"data":    task,
 	})
-}
\ No newline at end of file
+}
+
+// UpdateTaskStatus marks a task pending, in progress, completed or skipped
+func (h *WorkOrderHandler) UpdateTaskStatus(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("task_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	var req UpdateTaskStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	task, err := h.workOrderService.UpdateTaskStatus(c.Request.Context(), id, domain.WorkOrderTaskStatus(req.Status), userID.(int))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to update work order task status",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Work order task status updated successfully",
+		"data":    task,
+	})
+}
+
+func (h *WorkOrderHandler) DeleteTask(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("task_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	if err := h.workOrderService.DeleteTask(c.Request.Context(), id, userID.(int)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to delete work order task",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Work order task deleted successfully",
+	})
+}
@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"net/http"
+	"pos-final/internal/domain"
+	"pos-final/internal/service"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WorkOrderSubletHandler struct {
+	subletService service.WorkOrderSubletService
+	fileService   service.FileService
+}
+
+// NewWorkOrderSubletHandler creates a new work order sublet handler
+func NewWorkOrderSubletHandler(subletService service.WorkOrderSubletService, fileService service.FileService) *WorkOrderSubletHandler {
+	return &WorkOrderSubletHandler{
+		subletService: subletService,
+		fileService:   fileService,
+	}
+}
+
+type CreateSubletRequest struct {
+	SupplierID          int     `json:"supplier_id" binding:"required"`
+	Description         string  `json:"description" binding:"required"`
+	Cost                float64 `json:"cost" binding:"min=0"` // vendor quote
+	VendorInvoiceNumber *string `json:"vendor_invoice_number"`
+	Notes               *string `json:"notes"`
+}
+
+type CompleteSubletRequest struct {
+	VendorInvoiceNumber string   `json:"vendor_invoice_number" binding:"required"`
+	Cost                *float64 `json:"cost"`     // invoiced cost, defaults to the quote
+	DueDate             string   `json:"due_date"` // YYYY-MM-DD
+}
+
+// CreateSublet sends part of a work order to an outside vendor
+func (h *WorkOrderSubletHandler) CreateSublet(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid work order ID"})
+		return
+	}
+
+	var req CreateSubletRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	sublet := &domain.WorkOrderSublet{
+		WorkOrderID:         id,
+		SupplierID:          req.SupplierID,
+		Description:         req.Description,
+		Cost:                req.Cost,
+		VendorInvoiceNumber: req.VendorInvoiceNumber,
+		Notes:               req.Notes,
+		CreatedBy:           userID.(int),
+	}
+
+	if err := h.subletService.CreateSublet(c.Request.Context(), sublet); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to create sublet",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Sublet created successfully",
+		"data":    sublet,
+	})
+}
+
+func (h *WorkOrderSubletHandler) ListSublets(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid work order ID"})
+		return
+	}
+
+	sublets, err := h.subletService.ListSublets(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve sublets",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": sublets,
+	})
+}
+
+func (h *WorkOrderSubletHandler) GetSublet(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("sublet_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sublet ID"})
+		return
+	}
+
+	sublet, err := h.subletService.GetSubletByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Sublet not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": sublet,
+	})
+}
+
+// CompleteSublet records the vendor invoice and opens the payable to the vendor
+func (h *WorkOrderSubletHandler) CompleteSublet(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("sublet_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sublet ID"})
+		return
+	}
+
+	var req CompleteSubletRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var dueDate *time.Time
+	if req.DueDate != "" {
+		parsed, err := time.Parse("2006-01-02", req.DueDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid due date format",
+				"details": "Use YYYY-MM-DD format",
+			})
+			return
+		}
+		dueDate = &parsed
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	sublet, err := h.subletService.CompleteSublet(c.Request.Context(), id, req.VendorInvoiceNumber, req.Cost, dueDate, userID.(int))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to complete sublet",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Sublet completed successfully",
+		"data":    sublet,
+	})
+}
+
+func (h *WorkOrderSubletHandler) CancelSublet(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("sublet_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sublet ID"})
+		return
+	}
+
+	if err := h.subletService.CancelSublet(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to cancel sublet",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Sublet cancelled successfully",
+	})
+}
+
+// UploadInvoice attaches the scanned vendor invoice to a sublet
+func (h *WorkOrderSubletHandler) UploadInvoice(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("sublet_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sublet ID"})
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "No file uploaded",
+			"details": "Please select the vendor invoice",
+		})
+		return
+	}
+
+	if err := h.fileService.ValidateImage(file); err != nil {
+		if err := h.fileService.ValidateDocument(file); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid file",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	filePath, err := h.fileService.SaveFile(c.Request.Context(), file, "sublets")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to upload vendor invoice",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	sublet, err := h.subletService.AttachInvoice(c.Request.Context(), id, filePath)
+	if err != nil {
+		_ = h.fileService.DeleteFile(c.Request.Context(), filePath)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to attach vendor invoice",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Vendor invoice uploaded successfully",
+		"data":    sublet,
+	})
+}
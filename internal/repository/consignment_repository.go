@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type consignmentRepository struct {
+	db *sqlx.DB
+}
+
+// NewConsignmentRepository creates a new consignment repository
+func NewConsignmentRepository(db *sqlx.DB) ConsignmentRepository {
+	return &consignmentRepository{db: db}
+}
+
+// Create takes the owner's vehicle into stock and opens the consignment in
+// one transaction
+func (r *consignmentRepository) Create(ctx context.Context, consignment *domain.Consignment) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertVehicle(ctx, tx, consignment.Vehicle); err != nil {
+		return err
+	}
+	consignment.VehicleID = consignment.Vehicle.ID
+
+	query := `
+		INSERT INTO consignments (
+			consignment_number, vehicle_id, owner_id, agreed_net_price, payout_days,
+			status, intake_date, notes, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		consignment.ConsignmentNumber, consignment.VehicleID, consignment.OwnerID,
+		consignment.AgreedNetPrice, consignment.PayoutDays, consignment.Status,
+		consignment.IntakeDate, consignment.Notes, consignment.CreatedBy,
+	).Scan(&consignment.ID, &consignment.CreatedAt, &consignment.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create consignment: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit consignment: %w", err)
+	}
+
+	return nil
+}
+
+func (r *consignmentRepository) GetByID(ctx context.Context, id int) (*domain.Consignment, error) {
+	var consignment domain.Consignment
+	query := `
+		SELECT cs.id, cs.consignment_number, cs.vehicle_id, cs.owner_id, cs.agreed_net_price,
+			   cs.payout_days, cs.status, cs.intake_date, cs.sales_invoice_id, cs.sale_price,
+			   cs.commission_amount, cs.notes, cs.created_by,
+			   cs.deleted_at, cs.deleted_by, cs.created_at, cs.updated_at,
+			   -- Vehicle details
+			   v.id as "vehicle.id", v.vehicle_code as "vehicle.vehicle_code",
+			   v.brand as "vehicle.brand", v.model as "vehicle.model", v.year as "vehicle.year",
+			   v.plate_number as "vehicle.plate_number", v.chassis_number as "vehicle.chassis_number",
+			   v.status as "vehicle.status", v.sold_date as "vehicle.sold_date",
+			   -- Owner details
+			   c.id as "owner.id", c.customer_code as "owner.customer_code",
+			   c.name as "owner.name", c.phone as "owner.phone", c.address as "owner.address"
+		FROM consignments cs
+		JOIN vehicles v ON cs.vehicle_id = v.id
+		JOIN customers c ON cs.owner_id = c.id
+		WHERE cs.id = $1 AND cs.deleted_at IS NULL
+	`
+
+	err := r.db.GetContext(ctx, &consignment, query, id)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get consignment: %w", err)
+	}
+
+	return &consignment, nil
+}
+
+func (r *consignmentRepository) GetByVehicleID(ctx context.Context, vehicleID int) (*domain.Consignment, error) {
+	var consignment domain.Consignment
+	query := `
+		SELECT cs.id, cs.consignment_number, cs.vehicle_id, cs.owner_id, cs.agreed_net_price,
+			   cs.payout_days, cs.status, cs.intake_date, cs.sales_invoice_id, cs.sale_price,
+			   cs.commission_amount, cs.notes, cs.created_by,
+			   cs.deleted_at, cs.deleted_by, cs.created_at, cs.updated_at
+		FROM consignments cs
+		WHERE cs.vehicle_id = $1 AND cs.deleted_at IS NULL
+	`
+
+	err := r.db.GetContext(ctx, &consignment, query, vehicleID)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get consignment by vehicle: %w", err)
+	}
+
+	return &consignment, nil
+}
+
+func (r *consignmentRepository) List(ctx context.Context, offset, limit int) ([]*domain.Consignment, error) {
+	var consignments []*domain.Consignment
+	query := `
+		SELECT cs.id, cs.consignment_number, cs.vehicle_id, cs.owner_id, cs.agreed_net_price,
+			   cs.payout_days, cs.status, cs.intake_date, cs.sales_invoice_id, cs.sale_price,
+			   cs.commission_amount, cs.notes, cs.created_by,
+			   cs.deleted_at, cs.deleted_by, cs.created_at, cs.updated_at,
+			   -- Vehicle details
+			   v.vehicle_code as "vehicle.vehicle_code", v.brand as "vehicle.brand",
+			   v.model as "vehicle.model", v.plate_number as "vehicle.plate_number",
+			   -- Owner details
+			   c.name as "owner.name", c.phone as "owner.phone"
+		FROM consignments cs
+		JOIN vehicles v ON cs.vehicle_id = v.id
+		JOIN customers c ON cs.owner_id = c.id
+		WHERE cs.deleted_at IS NULL
+		ORDER BY cs.created_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	err := r.db.SelectContext(ctx, &consignments, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consignments: %w", err)
+	}
+
+	return consignments, nil
+}
+
+func (r *consignmentRepository) ListByStatus(ctx context.Context, status domain.ConsignmentStatus, offset, limit int) ([]*domain.Consignment, error) {
+	var consignments []*domain.Consignment
+	query := `
+		SELECT cs.id, cs.consignment_number, cs.vehicle_id, cs.owner_id, cs.agreed_net_price,
+			   cs.payout_days, cs.status, cs.intake_date, cs.sales_invoice_id, cs.sale_price,
+			   cs.commission_amount, cs.notes, cs.created_by,
+			   cs.deleted_at, cs.deleted_by, cs.created_at, cs.updated_at,
+			   -- Vehicle details
+			   v.vehicle_code as "vehicle.vehicle_code", v.brand as "vehicle.brand",
+			   v.model as "vehicle.model", v.plate_number as "vehicle.plate_number",
+			   -- Owner details
+			   c.name as "owner.name", c.phone as "owner.phone"
+		FROM consignments cs
+		JOIN vehicles v ON cs.vehicle_id = v.id
+		JOIN customers c ON cs.owner_id = c.id
+		WHERE cs.deleted_at IS NULL AND cs.status = $1
+		ORDER BY cs.created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	err := r.db.SelectContext(ctx, &consignments, query, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consignments by status: %w", err)
+	}
+
+	return consignments, nil
+}
+
+func (r *consignmentRepository) Count(ctx context.Context) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM consignments WHERE deleted_at IS NULL`
+
+	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count consignments: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *consignmentRepository) CountByStatus(ctx context.Context, status domain.ConsignmentStatus) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM consignments WHERE deleted_at IS NULL AND status = $1`
+
+	err := r.db.QueryRowContext(ctx, query, status).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count consignments by status: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *consignmentRepository) GenerateConsignmentNumber(ctx context.Context) (string, error) {
+	var count int
+	today := time.Now().Format("20060102")
+
+	query := `
+		SELECT COUNT(*) FROM consignments
+		WHERE consignment_number LIKE $1
+	`
+
+	err := r.db.QueryRowContext(ctx, query, fmt.Sprintf("CSG-%s%%", today)).Scan(&count)
+	if err != nil {
+		return "", fmt.Errorf("failed to count consignments for number generation: %w", err)
+	}
+
+	consignmentNumber := fmt.Sprintf("CSG-%s-%04d", today, count+1)
+	return consignmentNumber, nil
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type customerVehicleRepository struct {
+	db *sqlx.DB
+}
+
+// NewCustomerVehicleRepository creates a new customer vehicle repository
+func NewCustomerVehicleRepository(db *sqlx.DB) CustomerVehicleRepository {
+	return &customerVehicleRepository{db: db}
+}
+
+func (r *customerVehicleRepository) Create(ctx context.Context, vehicle *domain.CustomerVehicle) error {
+	query := `
+		INSERT INTO customer_vehicles (
+			customer_id, plate_number, brand, model, year, color,
+			chassis_number, engine_number, notes
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		vehicle.CustomerID, vehicle.PlateNumber, vehicle.Brand, vehicle.Model, vehicle.Year,
+		vehicle.Color, vehicle.ChassisNumber, vehicle.EngineNumber, vehicle.Notes,
+	).Scan(&vehicle.ID, &vehicle.CreatedAt, &vehicle.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create customer vehicle: %w", err)
+	}
+
+	return nil
+}
+
+func (r *customerVehicleRepository) GetByID(ctx context.Context, id int) (*domain.CustomerVehicle, error) {
+	var vehicle domain.CustomerVehicle
+	query := `
+		SELECT cv.id, cv.customer_id, cv.plate_number, cv.brand, cv.model, cv.year, cv.color,
+			   cv.chassis_number, cv.engine_number, cv.notes,
+			   cv.deleted_at, cv.deleted_by, cv.created_at, cv.updated_at,
+			   -- Owner details
+			   c.id as "customer.id", c.customer_code as "customer.customer_code",
+			   c.name as "customer.name", c.phone as "customer.phone"
+		FROM customer_vehicles cv
+		JOIN customers c ON cv.customer_id = c.id
+		WHERE cv.id = $1 AND cv.deleted_at IS NULL
+	`
+
+	err := r.db.GetContext(ctx, &vehicle, query, id)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get customer vehicle: %w", err)
+	}
+
+	return &vehicle, nil
+}
+
+func (r *customerVehicleRepository) ListByCustomerID(ctx context.Context, customerID int) ([]*domain.CustomerVehicle, error) {
+	var vehicles []*domain.CustomerVehicle
+	query := `
+		SELECT id, customer_id, plate_number, brand, model, year, color,
+			   chassis_number, engine_number, notes,
+			   deleted_at, deleted_by, created_at, updated_at
+		FROM customer_vehicles
+		WHERE customer_id = $1 AND deleted_at IS NULL
+		ORDER BY plate_number
+	`
+
+	err := r.db.SelectContext(ctx, &vehicles, query, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list customer vehicles: %w", err)
+	}
+
+	return vehicles, nil
+}
+
+func (r *customerVehicleRepository) Update(ctx context.Context, vehicle *domain.CustomerVehicle) error {
+	query := `
+		UPDATE customer_vehicles SET
+			plate_number = $2, brand = $3, model = $4, year = $5, color = $6,
+			chassis_number = $7, engine_number = $8, notes = $9
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		vehicle.ID, vehicle.PlateNumber, vehicle.Brand, vehicle.Model, vehicle.Year,
+		vehicle.Color, vehicle.ChassisNumber, vehicle.EngineNumber, vehicle.Notes,
+	).Scan(&vehicle.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to update customer vehicle: %w", err)
+	}
+
+	return nil
+}
+
+func (r *customerVehicleRepository) SoftDelete(ctx context.Context, id int, deletedBy int) error {
+	query := `
+		UPDATE customer_vehicles SET
+			deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, deletedBy)
+	if err != nil {
+		return fmt.Errorf("failed to delete customer vehicle: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("customer vehicle not found or already deleted")
+	}
+
+	return nil
+}
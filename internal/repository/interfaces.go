@@ -39,6 +39,7 @@ type SupplierRepository interface {
 	GetBySupplierCode(ctx context.Context, supplierCode string) (*domain.Supplier, error)
 	List(ctx context.Context, offset, limit int) ([]*domain.Supplier, error)
 	Update(ctx context.Context, supplier *domain.Supplier) error
+	UpdateLeadTime(ctx context.Context, id int, leadTimeDays int) error
 	SoftDelete(ctx context.Context, id int, deletedBy int) error
 	Count(ctx context.Context) (int, error)
 	Search(ctx context.Context, query string, offset, limit int) ([]*domain.Supplier, error)
@@ -70,6 +71,7 @@ type VehicleRepository interface {
 	Search(ctx context.Context, query string, offset, limit int) ([]*domain.Vehicle, error)
 	GenerateVehicleCode(ctx context.Context) (string, error)
 	UpdateStatus(ctx context.Context, id int, status domain.VehicleStatus) error
+	UpdateCosts(ctx context.Context, id int, repairCost float64, hpp float64) error
 }
 
 // VehiclePhotoRepository defines methods for vehicle photo data access
@@ -87,7 +89,9 @@ type VehiclePhotoRepository interface {
 type PurchaseInvoiceRepository interface {
 	Create(ctx context.Context, invoice *domain.PurchaseInvoice) error
 	GetByID(ctx context.Context, id int) (*domain.PurchaseInvoice, error)
+	ListItemsByInvoiceID(ctx context.Context, invoiceID int) ([]*domain.PurchaseInvoiceItem, error)
 	GetByInvoiceNumber(ctx context.Context, invoiceNumber string) (*domain.PurchaseInvoice, error)
+	GetByVehicleID(ctx context.Context, vehicleID int) (*domain.PurchaseInvoice, error)
 	List(ctx context.Context, offset, limit int) ([]*domain.PurchaseInvoice, error)
 	ListByDateRange(ctx context.Context, startDate, endDate time.Time, offset, limit int) ([]*domain.PurchaseInvoice, error)
 	ListByTransactionType(ctx context.Context, transactionType domain.TransactionType, offset, limit int) ([]*domain.PurchaseInvoice, error)
@@ -101,6 +105,8 @@ type PurchaseInvoiceRepository interface {
 // SalesInvoiceRepository defines methods for sales invoice data access
 type SalesInvoiceRepository interface {
 	Create(ctx context.Context, invoice *domain.SalesInvoice) error
+	CreateConsignmentSale(ctx context.Context, invoice *domain.SalesInvoice, consignment *domain.Consignment) error
+	DeleteConsignmentSale(ctx context.Context, id int, deletedBy int) error
 	GetByID(ctx context.Context, id int) (*domain.SalesInvoice, error)
 	GetByInvoiceNumber(ctx context.Context, invoiceNumber string) (*domain.SalesInvoice, error)
 	List(ctx context.Context, offset, limit int) ([]*domain.SalesInvoice, error)
@@ -129,11 +135,18 @@ type WorkOrderRepository interface {
 	GenerateWONumber(ctx context.Context) (string, error)
 	UpdateStatus(ctx context.Context, id int, status domain.WorkOrderStatus) error
 	UpdateProgress(ctx context.Context, id int, progress int) error
+	UpdateEstimatedCompletion(ctx context.Context, id int, estimatedCompletionAt *time.Time) error
+	AssignMechanic(ctx context.Context, id int, mechanicID int, reason string) error
+	UpdatePartsCost(ctx context.Context, id int, partsCost float64) error
+	UpdateLaborCost(ctx context.Context, id int, laborCost float64) error
+	UpdateSubletCost(ctx context.Context, id int, subletCost float64) error
+	TransitionStatus(ctx context.Context, history *domain.WorkOrderStatusHistory) error
+	ListStatusHistory(ctx context.Context, workOrderID int) ([]*domain.WorkOrderStatusHistory, error)
 }
 
 // SparePartRepository defines methods for spare part data access
 type SparePartRepository interface {
-	Create(ctx context.Context, sparePart *domain.SparePart) error
+	Create(ctx context.Context, sparePart *domain.SparePart, createdBy int) error
 	GetByID(ctx context.Context, id int) (*domain.SparePart, error)
 	GetByPartCode(ctx context.Context, partCode string) (*domain.SparePart, error)
 	GetByBarcode(ctx context.Context, barcode string) (*domain.SparePart, error)
@@ -145,8 +158,12 @@ type SparePartRepository interface {
 	CountLowStock(ctx context.Context) (int, error)
 	Search(ctx context.Context, query string, offset, limit int) ([]*domain.SparePart, error)
 	GeneratePartCode(ctx context.Context) (string, error)
-	UpdateStock(ctx context.Context, id int, quantity int) error
-	AdjustStock(ctx context.Context, id int, adjustment int) error
+	AdjustStock(ctx context.Context, adjustment *domain.StockAdjustment, costingMethod domain.CostingMethod) error
+	UpdateCostPrice(ctx context.Context, id int, costPrice float64) error
+	UpdateBarcode(ctx context.Context, id int, barcode string) error
+	UpdateTracking(ctx context.Context, id int, trackBatches, trackSerials bool) error
+	BarcodeExists(ctx context.Context, barcode string, excludeID int) (bool, error)
+	ListWithoutBarcode(ctx context.Context) ([]*domain.SparePart, error)
 }
 
 // WorkOrderPartRepository defines methods for work order part data access
@@ -158,6 +175,11 @@ type WorkOrderPartRepository interface {
 	Update(ctx context.Context, workOrderPart *domain.WorkOrderPart) error
 	SoftDelete(ctx context.Context, id int, deletedBy int) error
 	GetDailyUsage(ctx context.Context, date time.Time) (int, float64, error) // count, value
+	Return(ctx context.Context, partReturn *domain.WorkOrderPartReturn, costingMethod domain.CostingMethod) error
+	ListReturnsByWorkOrderID(ctx context.Context, workOrderID int) ([]*domain.WorkOrderPartReturn, error)
+	IssueBatch(ctx context.Context, workOrderParts []*domain.WorkOrderPart, costingMethod domain.CostingMethod) error
+	IssueServiceKit(ctx context.Context, application *domain.ServiceKitApplication, costingMethod domain.CostingMethod) error
+	IssuePartRequest(ctx context.Context, request *domain.PartRequest, workOrderPart *domain.WorkOrderPart, costingMethod domain.CostingMethod) error
 }
 
 // StockMovementRepository defines methods for stock movement data access
@@ -209,4 +231,326 @@ type DailyReportRepository interface {
 	Delete(ctx context.Context, id int) error
 	Count(ctx context.Context) (int, error)
 	GetLatest(ctx context.Context) (*domain.DailyReport, error)
-}
\ No newline at end of file
+}
+// WarrantyRepository defines methods for warranty data access
+type WarrantyRepository interface {
+	Create(ctx context.Context, warranty *domain.Warranty) error
+	GetByID(ctx context.Context, id int) (*domain.Warranty, error)
+	GetBySalesInvoiceID(ctx context.Context, salesInvoiceID int) (*domain.Warranty, error)
+	List(ctx context.Context, offset, limit int) ([]*domain.Warranty, error)
+	SearchByVehicle(ctx context.Context, plateNumber, chassisNumber string) ([]*domain.Warranty, error)
+	UpdateStatus(ctx context.Context, id int, status domain.WarrantyStatus) error
+	ExpireOverdue(ctx context.Context) (int, error)
+	Count(ctx context.Context) (int, error)
+	GenerateWarrantyNumber(ctx context.Context) (string, error)
+}
+
+// WarrantyClaimRepository defines methods for warranty claim data access
+type WarrantyClaimRepository interface {
+	Create(ctx context.Context, claim *domain.WarrantyClaim) error
+	CreateWithWorkOrder(ctx context.Context, claim *domain.WarrantyClaim, workOrder *domain.WorkOrder) error
+	ListByWarrantyID(ctx context.Context, warrantyID int) ([]*domain.WarrantyClaim, error)
+	ListByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*domain.WarrantyClaim, error)
+	GenerateClaimNumber(ctx context.Context) (string, error)
+}
+
+// PurchaseOrderRepository defines methods for spare part purchase order data access
+type PurchaseOrderRepository interface {
+	Create(ctx context.Context, po *domain.PurchaseOrder) error
+	GetByID(ctx context.Context, id int) (*domain.PurchaseOrder, error)
+	List(ctx context.Context, offset, limit int) ([]*domain.PurchaseOrder, error)
+	ListByStatus(ctx context.Context, status domain.PurchaseOrderStatus, offset, limit int) ([]*domain.PurchaseOrder, error)
+	ListItems(ctx context.Context, purchaseOrderID int) ([]*domain.PurchaseOrderItem, error)
+	ListOutstandingItems(ctx context.Context) ([]*domain.PurchaseOrderItem, error)
+	UpdateStatus(ctx context.Context, id int, status domain.PurchaseOrderStatus) error
+	Count(ctx context.Context) (int, error)
+	CountByStatus(ctx context.Context, status domain.PurchaseOrderStatus) (int, error)
+	GeneratePONumber(ctx context.Context) (string, error)
+}
+
+// GoodsReceiptRepository defines methods for goods receipt data access
+type GoodsReceiptRepository interface {
+	Create(ctx context.Context, receipt *domain.GoodsReceipt, costingMethod domain.CostingMethod) error
+	ListByPurchaseOrderID(ctx context.Context, purchaseOrderID int) ([]*domain.GoodsReceipt, error)
+	GenerateReceiptNumber(ctx context.Context) (string, error)
+}
+
+// StockCostLayerRepository defines methods for inventory cost layer data access
+type StockCostLayerRepository interface {
+	Create(ctx context.Context, layer *domain.StockCostLayer) error
+	ListBySparePartID(ctx context.Context, sparePartID int, openOnly bool) ([]*domain.StockCostLayer, error)
+	Consume(ctx context.Context, sparePartID int, quantity int) (float64, int, error) // cost, quantity covered by layers
+	GetValuation(ctx context.Context, sparePartID int) (int, float64, error)          // quantity, value
+}
+
+// PayableRepository defines methods for accounts payable data access
+type PayableRepository interface {
+	Create(ctx context.Context, payable *domain.Payable) error
+	GetByID(ctx context.Context, id int) (*domain.Payable, error)
+	GetByPurchaseInvoiceID(ctx context.Context, purchaseInvoiceID int) (*domain.Payable, error)
+	GetByConsignmentID(ctx context.Context, consignmentID int) (*domain.Payable, error)
+	GetByWorkOrderSubletID(ctx context.Context, subletID int) (*domain.Payable, error)
+	List(ctx context.Context, offset, limit int) ([]*domain.Payable, error)
+	ListByStatus(ctx context.Context, status domain.PayableStatus, offset, limit int) ([]*domain.Payable, error)
+	ListOutstanding(ctx context.Context) ([]*domain.Payable, error)
+	ListOverdueUnnotified(ctx context.Context, asOf time.Time) ([]*domain.Payable, error)
+	MarkOverdueNotified(ctx context.Context, id int) error
+	Count(ctx context.Context) (int, error)
+	CountByStatus(ctx context.Context, status domain.PayableStatus) (int, error)
+}
+
+// PayablePaymentRepository defines methods for payable payment data access
+type PayablePaymentRepository interface {
+	Create(ctx context.Context, payment *domain.PayablePayment) error
+	GetByID(ctx context.Context, id int) (*domain.PayablePayment, error)
+	ListByPayableID(ctx context.Context, payableID int) ([]*domain.PayablePayment, error)
+	UpdateTransferProof(ctx context.Context, id int, transferProof string) error
+	GeneratePaymentNumber(ctx context.Context) (string, error)
+}
+
+// ConsignmentRepository defines methods for consignment vehicle data access
+type ConsignmentRepository interface {
+	Create(ctx context.Context, consignment *domain.Consignment) error
+	GetByID(ctx context.Context, id int) (*domain.Consignment, error)
+	GetByVehicleID(ctx context.Context, vehicleID int) (*domain.Consignment, error)
+	List(ctx context.Context, offset, limit int) ([]*domain.Consignment, error)
+	ListByStatus(ctx context.Context, status domain.ConsignmentStatus, offset, limit int) ([]*domain.Consignment, error)
+	Count(ctx context.Context) (int, error)
+	CountByStatus(ctx context.Context, status domain.ConsignmentStatus) (int, error)
+	GenerateConsignmentNumber(ctx context.Context) (string, error)
+}
+
+// VehicleDocumentRepository defines methods for vehicle legal document data access
+type VehicleDocumentRepository interface {
+	Create(ctx context.Context, document *domain.VehicleDocument) error
+	GetByID(ctx context.Context, id int) (*domain.VehicleDocument, error)
+	Update(ctx context.Context, document *domain.VehicleDocument) error
+	Delete(ctx context.Context, id int, deletedBy int) error
+	ListByVehicleID(ctx context.Context, vehicleID int) ([]*domain.VehicleDocument, error)
+	ListExpiring(ctx context.Context, before time.Time) ([]*domain.VehicleDocument, error)
+	UpdateScanFile(ctx context.Context, id int, scanFile string) error
+	HandOver(ctx context.Context, document *domain.VehicleDocument) error
+}
+
+// MechanicRepository defines methods for mechanic skill, leave and workload data access
+type MechanicRepository interface {
+	ListWorkloads(ctx context.Context, date time.Time) ([]*domain.MechanicWorkload, error)
+	ListSkills(ctx context.Context) ([]*domain.MechanicSkill, error)
+	ReplaceSkills(ctx context.Context, mechanicID int, brands []string) error
+	CreateLeave(ctx context.Context, leave *domain.MechanicLeave) error
+	GetLeaveByID(ctx context.Context, id int) (*domain.MechanicLeave, error)
+	ListLeavesByMechanicID(ctx context.Context, mechanicID int) ([]*domain.MechanicLeave, error)
+	DeleteLeave(ctx context.Context, id int, deletedBy int) error
+	ListWorkingHours(ctx context.Context, mechanicID int) ([]*domain.MechanicWorkingHours, error)
+	ReplaceWorkingHours(ctx context.Context, mechanicID int, hours []*domain.MechanicWorkingHours) error
+}
+
+// WorkOrderTaskRepository defines methods for work order checklist task data access
+type WorkOrderTaskRepository interface {
+	Create(ctx context.Context, task *domain.WorkOrderTask) error
+	GetByID(ctx context.Context, id int) (*domain.WorkOrderTask, error)
+	Update(ctx context.Context, task *domain.WorkOrderTask) error
+	Delete(ctx context.Context, id int, deletedBy int) error
+	ListByWorkOrderID(ctx context.Context, workOrderID int) ([]*domain.WorkOrderTask, error)
+	ListOpenByMechanic(ctx context.Context, mechanicID int) ([]*domain.WorkOrderTask, error)
+}
+
+// LaborRepository defines methods for labor rate and labor time entry data access
+type LaborRepository interface {
+	ListRates(ctx context.Context) ([]*domain.LaborRate, error)
+	GetRateBySkillLevel(ctx context.Context, skillLevel string) (*domain.LaborRate, error)
+	UpsertRate(ctx context.Context, rate *domain.LaborRate) error
+	GetProfileByMechanicID(ctx context.Context, mechanicID int) (*domain.MechanicLaborProfile, error)
+	UpsertProfile(ctx context.Context, profile *domain.MechanicLaborProfile) error
+	CreateEntry(ctx context.Context, entry *domain.LaborTimeEntry) error
+	GetEntryByID(ctx context.Context, id int) (*domain.LaborTimeEntry, error)
+	UpdateEntry(ctx context.Context, entry *domain.LaborTimeEntry, fromStatus domain.LaborTimerStatus) error
+	GetRunningByMechanic(ctx context.Context, mechanicID int) (*domain.LaborTimeEntry, error)
+	ListByWorkOrderID(ctx context.Context, workOrderID int) ([]*domain.LaborTimeEntry, error)
+	ListByMechanic(ctx context.Context, mechanicID int, startDate, endDate time.Time) ([]*domain.LaborTimeEntry, error)
+	CountOpenByWorkOrder(ctx context.Context, workOrderID int) (int, error)
+	SumLaborCostByWorkOrder(ctx context.Context, workOrderID int) (float64, error)
+}
+
+// PartRequestRepository defines methods for mechanic part request data access
+type PartRequestRepository interface {
+	Create(ctx context.Context, request *domain.PartRequest) error
+	GetByID(ctx context.Context, id int) (*domain.PartRequest, error)
+	ListByWorkOrderID(ctx context.Context, workOrderID int) ([]*domain.PartRequest, error)
+	ListByStatus(ctx context.Context, status domain.PartRequestStatus, offset, limit int) ([]*domain.PartRequest, error)
+	CountByStatus(ctx context.Context, status domain.PartRequestStatus) (int, error)
+	Review(ctx context.Context, request *domain.PartRequest) error
+	GenerateRequestNumber(ctx context.Context) (string, error)
+}
+
+// CustomerVehicleRepository defines methods for customer-owned vehicle data access
+type CustomerVehicleRepository interface {
+	Create(ctx context.Context, vehicle *domain.CustomerVehicle) error
+	GetByID(ctx context.Context, id int) (*domain.CustomerVehicle, error)
+	ListByCustomerID(ctx context.Context, customerID int) ([]*domain.CustomerVehicle, error)
+	Update(ctx context.Context, vehicle *domain.CustomerVehicle) error
+	SoftDelete(ctx context.Context, id int, deletedBy int) error
+}
+
+// ServiceInvoiceRepository defines methods for customer service invoice data access
+type ServiceInvoiceRepository interface {
+	Create(ctx context.Context, invoice *domain.ServiceInvoice) error
+	GetByID(ctx context.Context, id int) (*domain.ServiceInvoice, error)
+	GetByWorkOrderID(ctx context.Context, workOrderID int) (*domain.ServiceInvoice, error)
+	List(ctx context.Context, offset, limit int) ([]*domain.ServiceInvoice, error)
+	ListByStatus(ctx context.Context, status domain.ServiceInvoiceStatus, offset, limit int) ([]*domain.ServiceInvoice, error)
+	Count(ctx context.Context) (int, error)
+	CountByStatus(ctx context.Context, status domain.ServiceInvoiceStatus) (int, error)
+	ListItems(ctx context.Context, invoiceID int) ([]*domain.ServiceInvoiceItem, error)
+	GenerateInvoiceNumber(ctx context.Context) (string, error)
+}
+
+// ServiceInvoicePaymentRepository defines methods for service invoice payment data access
+type ServiceInvoicePaymentRepository interface {
+	Create(ctx context.Context, payment *domain.ServiceInvoicePayment) error
+	ListByInvoiceID(ctx context.Context, invoiceID int) ([]*domain.ServiceInvoicePayment, error)
+	GeneratePaymentNumber(ctx context.Context) (string, error)
+}
+
+// WorkOrderAttachmentRepository defines methods for work order photo and document data access
+type WorkOrderAttachmentRepository interface {
+	Create(ctx context.Context, attachment *domain.WorkOrderAttachment) error
+	GetByID(ctx context.Context, id int) (*domain.WorkOrderAttachment, error)
+	ListByWorkOrderID(ctx context.Context, workOrderID int) ([]*domain.WorkOrderAttachment, error)
+	Delete(ctx context.Context, id int, deletedBy int) error
+}
+
+// WorkshopBayRepository defines methods for workshop bay data access
+type WorkshopBayRepository interface {
+	Create(ctx context.Context, bay *domain.WorkshopBay) error
+	GetByID(ctx context.Context, id int) (*domain.WorkshopBay, error)
+	List(ctx context.Context) ([]*domain.WorkshopBay, error)
+	Update(ctx context.Context, bay *domain.WorkshopBay) error
+	SoftDelete(ctx context.Context, id int, deletedBy int) error
+}
+
+// WorkOrderScheduleRepository defines methods for planned workshop work data access
+type WorkOrderScheduleRepository interface {
+	Create(ctx context.Context, schedule *domain.WorkOrderSchedule) error
+	GetByID(ctx context.Context, id int) (*domain.WorkOrderSchedule, error)
+	Update(ctx context.Context, schedule *domain.WorkOrderSchedule) error
+	Delete(ctx context.Context, id int, deletedBy int) error
+	ListByWorkOrderID(ctx context.Context, workOrderID int) ([]*domain.WorkOrderSchedule, error)
+	ListInRange(ctx context.Context, start, end time.Time, bayID, mechanicID *int) ([]*domain.WorkOrderSchedule, error)
+	ListConflicts(ctx context.Context, schedule *domain.WorkOrderSchedule) ([]*domain.WorkOrderSchedule, error)
+	ListOpen(ctx context.Context) ([]*domain.WorkOrderSchedule, error)
+}
+
+// VehicleCostRepository defines methods for the per-vehicle cost ledger
+type VehicleCostRepository interface {
+	Create(ctx context.Context, cost *domain.VehicleCost) error
+	GetByID(ctx context.Context, id int) (*domain.VehicleCost, error)
+	ListByVehicleID(ctx context.Context, vehicleID int) ([]*domain.VehicleCost, error)
+	SoftDelete(ctx context.Context, id int, deletedBy int) error
+	SumByType(ctx context.Context, vehicleID int) ([]*domain.VehicleCostComponent, error)
+	ListReconditioningWorkOrders(ctx context.Context, vehicleID int) ([]*domain.VehicleCostWorkOrder, error)
+}
+
+// WorkOrderSubletRepository defines methods for sublet repair data access
+type WorkOrderSubletRepository interface {
+	Create(ctx context.Context, sublet *domain.WorkOrderSublet) error
+	GetByID(ctx context.Context, id int) (*domain.WorkOrderSublet, error)
+	ListByWorkOrderID(ctx context.Context, workOrderID int) ([]*domain.WorkOrderSublet, error)
+	Complete(ctx context.Context, sublet *domain.WorkOrderSublet) error
+	Cancel(ctx context.Context, id int) error
+	UpdateAttachment(ctx context.Context, id int, attachmentPath string) error
+	SumCostByWorkOrder(ctx context.Context, workOrderID int) (float64, error)
+	CountSentByWorkOrder(ctx context.Context, workOrderID int) (int, error)
+}
+
+// StockCountRepository defines methods for stock count (stock opname) data access
+type StockCountRepository interface {
+	Create(ctx context.Context, count *domain.StockCount, sparePartIDs []int) error
+	GetByID(ctx context.Context, id int) (*domain.StockCount, error)
+	List(ctx context.Context, status domain.StockCountStatus, offset, limit int) ([]*domain.StockCount, error)
+	Count(ctx context.Context, status domain.StockCountStatus) (int, error)
+	GenerateCountNumber(ctx context.Context) (string, error)
+	ListItems(ctx context.Context, stockCountID int) ([]*domain.StockCountItem, error)
+	GetItemByID(ctx context.Context, id int) (*domain.StockCountItem, error)
+	GetItemByCode(ctx context.Context, stockCountID int, code string) (*domain.StockCountItem, error)
+	AddCount(ctx context.Context, itemID int, quantity int, countedBy int) error
+	SetCount(ctx context.Context, itemID int, quantity *int, countedBy int, notes *string) error
+	UpdateStatus(ctx context.Context, id int, from, to domain.StockCountStatus, userID int) error
+	Approve(ctx context.Context, count *domain.StockCount, costingMethod domain.CostingMethod) error
+}
+
+// StockLocationRepository defines methods for stock locations and per-location stock
+type StockLocationRepository interface {
+	Create(ctx context.Context, location *domain.StockLocation) error
+	GetByID(ctx context.Context, id int) (*domain.StockLocation, error)
+	GetDefault(ctx context.Context) (*domain.StockLocation, error)
+	List(ctx context.Context, includeInactive bool) ([]*domain.StockLocation, error)
+	Update(ctx context.Context, location *domain.StockLocation) error
+	SetDefault(ctx context.Context, id int) error
+	SoftDelete(ctx context.Context, id int, deletedBy int) error
+	SumQuantity(ctx context.Context, locationID int) (int, error)
+	GetStock(ctx context.Context, sparePartID, locationID int) (*domain.SparePartStock, error)
+	ListStockByLocation(ctx context.Context, locationID int) ([]*domain.SparePartStock, error)
+	ListStockBySparePart(ctx context.Context, sparePartID int) ([]*domain.SparePartStock, error)
+	ListLowStock(ctx context.Context, locationID *int) ([]*domain.SparePartStock, error)
+	UpdateStockSettings(ctx context.Context, stock *domain.SparePartStock) error
+}
+
+// StockTransferRepository defines methods for stock transfers between locations
+type StockTransferRepository interface {
+	Create(ctx context.Context, transfer *domain.StockTransfer) error
+	GetByID(ctx context.Context, id int) (*domain.StockTransfer, error)
+	List(ctx context.Context, status domain.StockTransferStatus, locationID *int, offset, limit int) ([]*domain.StockTransfer, error)
+	Count(ctx context.Context, status domain.StockTransferStatus, locationID *int) (int, error)
+	ListItems(ctx context.Context, transferID int) ([]*domain.StockTransferItem, error)
+	GenerateTransferNumber(ctx context.Context) (string, error)
+	Ship(ctx context.Context, transfer *domain.StockTransfer) error
+	Receive(ctx context.Context, transfer *domain.StockTransfer) error
+	Cancel(ctx context.Context, transfer *domain.StockTransfer, cancelledBy int) error
+}
+
+// ReplenishmentRepository defines methods for consumption-based reorder data
+type ReplenishmentRepository interface {
+	ListUsage(ctx context.Context, since time.Time, policy domain.ReplenishmentPolicy, supplierID *int) ([]*domain.ReorderSuggestion, error)
+	UpdateSettings(ctx context.Context, settings *domain.ReorderSettings) error
+}
+
+// SparePartFitmentRepository defines methods for spare part fitment data access
+type SparePartFitmentRepository interface {
+	Create(ctx context.Context, fitment *domain.SparePartFitment) error
+	GetByID(ctx context.Context, id int) (*domain.SparePartFitment, error)
+	ListBySparePart(ctx context.Context, sparePartID int) ([]*domain.SparePartFitment, error)
+	Update(ctx context.Context, fitment *domain.SparePartFitment) error
+	SoftDelete(ctx context.Context, id int, deletedBy int) error
+	ListCompatibleParts(ctx context.Context, filter *domain.CompatiblePartFilter, offset, limit int) ([]*domain.SparePart, error)
+	CountCompatibleParts(ctx context.Context, filter *domain.CompatiblePartFilter) (int, error)
+}
+
+// ServiceKitRepository defines methods for service kit data access
+type ServiceKitRepository interface {
+	Create(ctx context.Context, kit *domain.ServiceKit) error
+	GetByID(ctx context.Context, id int) (*domain.ServiceKit, error)
+	GetByCode(ctx context.Context, kitCode string) (*domain.ServiceKit, error)
+	List(ctx context.Context, activeOnly bool, offset, limit int) ([]*domain.ServiceKit, error)
+	Count(ctx context.Context, activeOnly bool) (int, error)
+	ListItems(ctx context.Context, kitID int) ([]*domain.ServiceKitItem, error)
+	Update(ctx context.Context, kit *domain.ServiceKit) error
+	SoftDelete(ctx context.Context, id int, deletedBy int) error
+	GenerateKitCode(ctx context.Context) (string, error)
+}
+
+// SparePartBatchRepository defines methods for spare part batch data access
+type SparePartBatchRepository interface {
+	ListBySparePart(ctx context.Context, sparePartID int, locationID *int, openOnly bool) ([]*domain.SparePartBatch, error)
+	ListExpiring(ctx context.Context, before time.Time, locationID *int) ([]*domain.SparePartBatch, error)
+	ListByWorkOrderPart(ctx context.Context, workOrderPartID int) ([]*domain.WorkOrderPartBatch, error)
+}
+
+// SparePartSerialRepository defines methods for spare part serial number data access
+type SparePartSerialRepository interface {
+	ListBySparePart(ctx context.Context, sparePartID int, status *domain.SerialStatus, offset, limit int) ([]*domain.SparePartSerial, error)
+	CountBySparePart(ctx context.Context, sparePartID int, status *domain.SerialStatus) (int, error)
+	ListBySerialNumber(ctx context.Context, serialNumber string) ([]*domain.SparePartSerial, error)
+	ListByWorkOrderPart(ctx context.Context, workOrderPartID int) ([]*domain.SparePartSerial, error)
+	Register(ctx context.Context, sparePartID int, locationID *int, serialNumbers []string, registeredAt time.Time) error
+}
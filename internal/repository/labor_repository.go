@@ -0,0 +1,281 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type laborRepository struct {
+	db *sqlx.DB
+}
+
+// NewLaborRepository creates a new labor repository
+func NewLaborRepository(db *sqlx.DB) LaborRepository {
+	return &laborRepository{db: db}
+}
+
+const laborTimeEntryColumns = `
+	e.id, e.work_order_id, e.work_order_task_id, e.mechanic_id, e.status, e.started_at,
+	e.segment_started_at, e.ended_at, e.worked_seconds, e.hourly_rate, e.labor_cost,
+	e.notes, e.created_at, e.updated_at,
+	wo.wo_number, t.title as task_title
+`
+
+func (r *laborRepository) ListRates(ctx context.Context) ([]*domain.LaborRate, error) {
+	var rates []*domain.LaborRate
+	query := `
+		SELECT id, skill_level, hourly_rate, created_at, updated_at
+		FROM labor_rates
+		ORDER BY hourly_rate
+	`
+
+	err := r.db.SelectContext(ctx, &rates, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list labor rates: %w", err)
+	}
+
+	return rates, nil
+}
+
+func (r *laborRepository) GetRateBySkillLevel(ctx context.Context, skillLevel string) (*domain.LaborRate, error) {
+	var rate domain.LaborRate
+	query := `
+		SELECT id, skill_level, hourly_rate, created_at, updated_at
+		FROM labor_rates
+		WHERE skill_level = $1
+	`
+
+	err := r.db.GetContext(ctx, &rate, query, skillLevel)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get labor rate: %w", err)
+	}
+
+	return &rate, nil
+}
+
+func (r *laborRepository) UpsertRate(ctx context.Context, rate *domain.LaborRate) error {
+	query := `
+		INSERT INTO labor_rates (skill_level, hourly_rate)
+		VALUES ($1, $2)
+		ON CONFLICT (skill_level) DO UPDATE SET hourly_rate = EXCLUDED.hourly_rate
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, rate.SkillLevel, rate.HourlyRate).
+		Scan(&rate.ID, &rate.CreatedAt, &rate.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save labor rate: %w", err)
+	}
+
+	return nil
+}
+
+func (r *laborRepository) GetProfileByMechanicID(ctx context.Context, mechanicID int) (*domain.MechanicLaborProfile, error) {
+	var profile domain.MechanicLaborProfile
+	query := `
+		SELECT id, mechanic_id, skill_level, hourly_rate, created_at, updated_at
+		FROM mechanic_labor_profiles
+		WHERE mechanic_id = $1
+	`
+
+	err := r.db.GetContext(ctx, &profile, query, mechanicID)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get mechanic labor profile: %w", err)
+	}
+
+	return &profile, nil
+}
+
+func (r *laborRepository) UpsertProfile(ctx context.Context, profile *domain.MechanicLaborProfile) error {
+	query := `
+		INSERT INTO mechanic_labor_profiles (mechanic_id, skill_level, hourly_rate)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (mechanic_id) DO UPDATE SET
+			skill_level = EXCLUDED.skill_level, hourly_rate = EXCLUDED.hourly_rate
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, profile.MechanicID, profile.SkillLevel, profile.HourlyRate).
+		Scan(&profile.ID, &profile.CreatedAt, &profile.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save mechanic labor profile: %w", err)
+	}
+
+	return nil
+}
+
+func (r *laborRepository) CreateEntry(ctx context.Context, entry *domain.LaborTimeEntry) error {
+	query := `
+		INSERT INTO labor_time_entries (
+			work_order_id, work_order_task_id, mechanic_id, status, started_at,
+			segment_started_at, hourly_rate, notes
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		entry.WorkOrderID, entry.WorkOrderTaskID, entry.MechanicID, entry.Status,
+		entry.StartedAt, entry.SegmentStartedAt, entry.HourlyRate, entry.Notes,
+	).Scan(&entry.ID, &entry.CreatedAt, &entry.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create labor time entry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *laborRepository) GetEntryByID(ctx context.Context, id int) (*domain.LaborTimeEntry, error) {
+	var entry domain.LaborTimeEntry
+	query := `
+		SELECT ` + laborTimeEntryColumns + `
+		FROM labor_time_entries e
+		JOIN work_orders wo ON e.work_order_id = wo.id
+		LEFT JOIN work_order_tasks t ON e.work_order_task_id = t.id
+		WHERE e.id = $1
+	`
+
+	err := r.db.GetContext(ctx, &entry, query, id)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get labor time entry: %w", err)
+	}
+
+	return &entry, nil
+}
+
+// UpdateEntry saves the timer only if it is still in fromStatus, so two
+// concurrent pause/resume/stop calls cannot both apply
+func (r *laborRepository) UpdateEntry(ctx context.Context, entry *domain.LaborTimeEntry, fromStatus domain.LaborTimerStatus) error {
+	query := `
+		UPDATE labor_time_entries SET
+			status = $2, segment_started_at = $3, ended_at = $4, worked_seconds = $5,
+			labor_cost = $6, notes = $7
+		WHERE id = $1 AND status = $8
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		entry.ID, entry.Status, entry.SegmentStartedAt, entry.EndedAt,
+		entry.WorkedSeconds, entry.LaborCost, entry.Notes, fromStatus,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update labor time entry: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("timer is no longer %s", fromStatus)
+	}
+
+	return nil
+}
+
+func (r *laborRepository) GetRunningByMechanic(ctx context.Context, mechanicID int) (*domain.LaborTimeEntry, error) {
+	var entry domain.LaborTimeEntry
+	query := `
+		SELECT ` + laborTimeEntryColumns + `
+		FROM labor_time_entries e
+		JOIN work_orders wo ON e.work_order_id = wo.id
+		LEFT JOIN work_order_tasks t ON e.work_order_task_id = t.id
+		WHERE e.mechanic_id = $1 AND e.status = 'running'
+	`
+
+	err := r.db.GetContext(ctx, &entry, query, mechanicID)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get running labor timer: %w", err)
+	}
+
+	return &entry, nil
+}
+
+func (r *laborRepository) ListByWorkOrderID(ctx context.Context, workOrderID int) ([]*domain.LaborTimeEntry, error) {
+	var entries []*domain.LaborTimeEntry
+	query := `
+		SELECT ` + laborTimeEntryColumns + `
+		FROM labor_time_entries e
+		JOIN work_orders wo ON e.work_order_id = wo.id
+		LEFT JOIN work_order_tasks t ON e.work_order_task_id = t.id
+		WHERE e.work_order_id = $1
+		ORDER BY e.started_at, e.id
+	`
+
+	err := r.db.SelectContext(ctx, &entries, query, workOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list labor time entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// ListByMechanic returns a mechanic's entries started within [startDate, endDate)
+func (r *laborRepository) ListByMechanic(ctx context.Context, mechanicID int, startDate, endDate time.Time) ([]*domain.LaborTimeEntry, error) {
+	var entries []*domain.LaborTimeEntry
+	query := `
+		SELECT ` + laborTimeEntryColumns + `
+		FROM labor_time_entries e
+		JOIN work_orders wo ON e.work_order_id = wo.id
+		LEFT JOIN work_order_tasks t ON e.work_order_task_id = t.id
+		WHERE e.mechanic_id = $1 AND e.started_at >= $2 AND e.started_at < $3
+		ORDER BY e.started_at, e.id
+	`
+
+	err := r.db.SelectContext(ctx, &entries, query, mechanicID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list labor time entries by mechanic: %w", err)
+	}
+
+	return entries, nil
+}
+
+// CountOpenByWorkOrder counts running and paused timers on a work order
+func (r *laborRepository) CountOpenByWorkOrder(ctx context.Context, workOrderID int) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*) FROM labor_time_entries
+		WHERE work_order_id = $1 AND status IN ('running', 'paused')
+	`
+
+	err := r.db.QueryRowContext(ctx, query, workOrderID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count open labor timers: %w", err)
+	}
+
+	return count, nil
+}
+
+// SumLaborCostByWorkOrder totals the labor cost of the stopped timers on a
+// work order
+func (r *laborRepository) SumLaborCostByWorkOrder(ctx context.Context, workOrderID int) (float64, error) {
+	var total float64
+	query := `
+		SELECT COALESCE(SUM(labor_cost), 0) FROM labor_time_entries
+		WHERE work_order_id = $1 AND status = 'stopped'
+	`
+
+	err := r.db.QueryRowContext(ctx, query, workOrderID).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum labor cost: %w", err)
+	}
+
+	return total, nil
+}
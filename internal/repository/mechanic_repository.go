@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type mechanicRepository struct {
+	db *sqlx.DB
+}
+
+// NewMechanicRepository creates a new mechanic repository
+func NewMechanicRepository(db *sqlx.DB) MechanicRepository {
+	return &mechanicRepository{db: db}
+}
+
+// ListWorkloads returns every active mechanic with their open work order
+// count, last assignment and whether they are on leave on the given date
+func (r *mechanicRepository) ListWorkloads(ctx context.Context, date time.Time) ([]*domain.MechanicWorkload, error) {
+	var workloads []*domain.MechanicWorkload
+	query := `
+		SELECT u.id as mechanic_id, u.username, u.full_name,
+			   (SELECT COUNT(*) FROM work_orders wo
+				WHERE wo.assigned_mechanic_id = u.id AND wo.deleted_at IS NULL
+				  AND wo.status NOT IN ('completed', 'cancelled')) as open_work_orders,
+			   (SELECT MAX(wo.created_at) FROM work_orders wo
+				WHERE wo.assigned_mechanic_id = u.id AND wo.deleted_at IS NULL) as last_assigned_at,
+			   EXISTS (SELECT 1 FROM mechanic_leaves ml
+				WHERE ml.mechanic_id = u.id AND ml.deleted_at IS NULL
+				  AND $1::date BETWEEN ml.start_date AND ml.end_date) as on_leave
+		FROM users u
+		WHERE u.role = 'mekanik' AND u.is_active = true AND u.deleted_at IS NULL
+		ORDER BY u.id
+	`
+
+	err := r.db.SelectContext(ctx, &workloads, query, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mechanic workloads: %w", err)
+	}
+
+	return workloads, nil
+}
+
+func (r *mechanicRepository) ListSkills(ctx context.Context) ([]*domain.MechanicSkill, error) {
+	var skills []*domain.MechanicSkill
+	query := `
+		SELECT id, mechanic_id, brand, created_at
+		FROM mechanic_skills
+		ORDER BY mechanic_id, brand
+	`
+
+	err := r.db.SelectContext(ctx, &skills, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mechanic skills: %w", err)
+	}
+
+	return skills, nil
+}
+
+// ReplaceSkills sets the brands a mechanic specialises in
+func (r *mechanicRepository) ReplaceSkills(ctx context.Context, mechanicID int, brands []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM mechanic_skills WHERE mechanic_id = $1`, mechanicID)
+	if err != nil {
+		return fmt.Errorf("failed to clear mechanic skills: %w", err)
+	}
+
+	for _, brand := range brands {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO mechanic_skills (mechanic_id, brand)
+			VALUES ($1, $2)
+			ON CONFLICT (mechanic_id, brand) DO NOTHING
+		`, mechanicID, brand)
+		if err != nil {
+			return fmt.Errorf("failed to create mechanic skill: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *mechanicRepository) CreateLeave(ctx context.Context, leave *domain.MechanicLeave) error {
+	query := `
+		INSERT INTO mechanic_leaves (mechanic_id, start_date, end_date, reason, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		leave.MechanicID, leave.StartDate, leave.EndDate, leave.Reason, leave.CreatedBy,
+	).Scan(&leave.ID, &leave.CreatedAt, &leave.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create mechanic leave: %w", err)
+	}
+
+	return nil
+}
+
+func (r *mechanicRepository) GetLeaveByID(ctx context.Context, id int) (*domain.MechanicLeave, error) {
+	var leave domain.MechanicLeave
+	query := `
+		SELECT id, mechanic_id, start_date, end_date, reason, created_by,
+			   deleted_at, deleted_by, created_at, updated_at
+		FROM mechanic_leaves
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	err := r.db.GetContext(ctx, &leave, query, id)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get mechanic leave: %w", err)
+	}
+
+	return &leave, nil
+}
+
+func (r *mechanicRepository) ListLeavesByMechanicID(ctx context.Context, mechanicID int) ([]*domain.MechanicLeave, error) {
+	var leaves []*domain.MechanicLeave
+	query := `
+		SELECT id, mechanic_id, start_date, end_date, reason, created_by,
+			   deleted_at, deleted_by, created_at, updated_at
+		FROM mechanic_leaves
+		WHERE mechanic_id = $1 AND deleted_at IS NULL
+		ORDER BY start_date DESC
+	`
+
+	err := r.db.SelectContext(ctx, &leaves, query, mechanicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mechanic leaves: %w", err)
+	}
+
+	return leaves, nil
+}
+
+func (r *mechanicRepository) DeleteLeave(ctx context.Context, id int, deletedBy int) error {
+	query := `
+		UPDATE mechanic_leaves SET
+			deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, deletedBy)
+	if err != nil {
+		return fmt.Errorf("failed to delete mechanic leave: %w", err)
+	}
+
+	return nil
+}
+
+func (r *mechanicRepository) ListWorkingHours(ctx context.Context, mechanicID int) ([]*domain.MechanicWorkingHours, error) {
+	var hours []*domain.MechanicWorkingHours
+	query := `
+		SELECT id, mechanic_id, day_of_week,
+			   to_char(start_time, 'HH24:MI') as start_time, to_char(end_time, 'HH24:MI') as end_time,
+			   created_at
+		FROM mechanic_working_hours
+		WHERE mechanic_id = $1
+		ORDER BY day_of_week
+	`
+
+	err := r.db.SelectContext(ctx, &hours, query, mechanicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mechanic working hours: %w", err)
+	}
+
+	return hours, nil
+}
+
+// ReplaceWorkingHours sets the mechanic's weekly shifts. An empty list puts
+// the mechanic back on the default workshop hours.
+func (r *mechanicRepository) ReplaceWorkingHours(ctx context.Context, mechanicID int, hours []*domain.MechanicWorkingHours) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM mechanic_working_hours WHERE mechanic_id = $1`, mechanicID)
+	if err != nil {
+		return fmt.Errorf("failed to clear mechanic working hours: %w", err)
+	}
+
+	for _, shift := range hours {
+		shift.MechanicID = mechanicID
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO mechanic_working_hours (mechanic_id, day_of_week, start_time, end_time)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, created_at
+		`, mechanicID, shift.DayOfWeek, shift.StartTime, shift.EndTime).Scan(&shift.ID, &shift.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create mechanic working hours: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type partRequestRepository struct {
+	db *sqlx.DB
+}
+
+// NewPartRequestRepository creates a new part request repository
+func NewPartRequestRepository(db *sqlx.DB) PartRequestRepository {
+	return &partRequestRepository{db: db}
+}
+
+const partRequestColumns = `
+	pr.id, pr.request_number, pr.work_order_id, pr.spare_part_id, pr.quantity_requested,
+	pr.quantity_issued, pr.status, pr.notes, pr.requested_by, pr.reviewed_by,
+	pr.reviewed_at, pr.review_notes, pr.deleted_at, pr.deleted_by, pr.created_at, pr.updated_at,
+	-- Work order details
+	wo.id as "work_order.id", wo.wo_number as "work_order.wo_number", wo.status as "work_order.status",
+	-- Spare part details
+	sp.id as "spare_part.id", sp.part_code as "spare_part.part_code", sp.name as "spare_part.name",
+	sp.unit as "spare_part.unit", sp.stock_quantity as "spare_part.stock_quantity",
+	-- Requester details
+	u.id as "requester.id", u.username as "requester.username", u.full_name as "requester.full_name"
+`
+
+const partRequestJoins = `
+	FROM part_requests pr
+	JOIN work_orders wo ON pr.work_order_id = wo.id
+	JOIN spare_parts sp ON pr.spare_part_id = sp.id
+	JOIN users u ON pr.requested_by = u.id
+`
+
+func (r *partRequestRepository) Create(ctx context.Context, request *domain.PartRequest) error {
+	query := `
+		INSERT INTO part_requests (
+			request_number, work_order_id, spare_part_id, quantity_requested, status,
+			notes, requested_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		request.RequestNumber, request.WorkOrderID, request.SparePartID,
+		request.QuantityRequested, request.Status, request.Notes, request.RequestedBy,
+	).Scan(&request.ID, &request.CreatedAt, &request.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create part request: %w", err)
+	}
+
+	return nil
+}
+
+func (r *partRequestRepository) GetByID(ctx context.Context, id int) (*domain.PartRequest, error) {
+	var request domain.PartRequest
+	query := `SELECT ` + partRequestColumns + partRequestJoins + `
+		WHERE pr.id = $1 AND pr.deleted_at IS NULL
+	`
+
+	err := r.db.GetContext(ctx, &request, query, id)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get part request: %w", err)
+	}
+
+	return &request, nil
+}
+
+func (r *partRequestRepository) ListByWorkOrderID(ctx context.Context, workOrderID int) ([]*domain.PartRequest, error) {
+	var requests []*domain.PartRequest
+	query := `SELECT ` + partRequestColumns + partRequestJoins + `
+		WHERE pr.work_order_id = $1 AND pr.deleted_at IS NULL
+		ORDER BY pr.created_at DESC
+	`
+
+	err := r.db.SelectContext(ctx, &requests, query, workOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list part requests by work order: %w", err)
+	}
+
+	return requests, nil
+}
+
+// ListByStatus returns requests with the given status, oldest first so the
+// parts counter works through the queue in order
+func (r *partRequestRepository) ListByStatus(ctx context.Context, status domain.PartRequestStatus, offset, limit int) ([]*domain.PartRequest, error) {
+	var requests []*domain.PartRequest
+	query := `SELECT ` + partRequestColumns + partRequestJoins + `
+		WHERE pr.status = $1 AND pr.deleted_at IS NULL
+		ORDER BY pr.created_at, pr.id
+		LIMIT $2 OFFSET $3
+	`
+
+	err := r.db.SelectContext(ctx, &requests, query, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list part requests by status: %w", err)
+	}
+
+	return requests, nil
+}
+
+func (r *partRequestRepository) CountByStatus(ctx context.Context, status domain.PartRequestStatus) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM part_requests WHERE deleted_at IS NULL AND status = $1`
+
+	err := r.db.QueryRowContext(ctx, query, status).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count part requests by status: %w", err)
+	}
+
+	return count, nil
+}
+
+// Review closes a pending request. It fails if the request was already
+// reviewed.
+func (r *partRequestRepository) Review(ctx context.Context, request *domain.PartRequest) error {
+	return reviewPartRequest(ctx, r.db, request)
+}
+
+func reviewPartRequest(ctx context.Context, q sqlx.ExecerContext, request *domain.PartRequest) error {
+	query := `
+		UPDATE part_requests SET
+			status = $2, quantity_issued = $3, reviewed_by = $4, reviewed_at = $5, review_notes = $6
+		WHERE id = $1 AND status = 'pending' AND deleted_at IS NULL
+	`
+
+	result, err := q.ExecContext(ctx, query,
+		request.ID, request.Status, request.QuantityIssued, request.ReviewedBy,
+		request.ReviewedAt, request.ReviewNotes,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to review part request: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to review part request: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("part request is no longer pending")
+	}
+
+	return nil
+}
+
+func (r *partRequestRepository) GenerateRequestNumber(ctx context.Context) (string, error) {
+	var count int
+	today := time.Now().Format("20060102")
+
+	query := `
+		SELECT COUNT(*) FROM part_requests
+		WHERE request_number LIKE $1
+	`
+
+	err := r.db.QueryRowContext(ctx, query, fmt.Sprintf("PRQ-%s%%", today)).Scan(&count)
+	if err != nil {
+		return "", fmt.Errorf("failed to count part requests for number generation: %w", err)
+	}
+
+	requestNumber := fmt.Sprintf("PRQ-%s-%04d", today, count+1)
+	return requestNumber, nil
+}
@@ -0,0 +1,413 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// payableSelect joins the payable source to resolve the counterparty: the
+// supplier or selling customer of a purchase invoice, the owner of a
+// consignment vehicle, or the vendor of a sublet repair.
+const payableSelect = `
+	SELECT p.id, p.purchase_invoice_id, p.consignment_id, p.work_order_sublet_id, p.amount, p.paid_amount,
+		   p.due_date, p.status, p.overdue_notified_at, p.notes,
+		   p.deleted_at, p.deleted_by, p.created_at, p.updated_at,
+		   -- Source document and counterparty details
+		   COALESCE(pi.invoice_number, cs.consignment_number, ws.vendor_invoice_number, '') as invoice_number,
+		   COALESCE(pi.transaction_type, CASE WHEN ws.id IS NOT NULL THEN 'supplier' END, 'customer') as counterparty_type,
+		   COALESCE(pi.supplier_id, pi.customer_id, cs.owner_id, ws.supplier_id, 0) as counterparty_id,
+		   COALESCE(s.name, c.name, '') as counterparty_name
+	FROM payables p
+	LEFT JOIN purchase_invoices pi ON p.purchase_invoice_id = pi.id
+	LEFT JOIN consignments cs ON p.consignment_id = cs.id
+	LEFT JOIN work_order_sublets ws ON p.work_order_sublet_id = ws.id
+	LEFT JOIN suppliers s ON s.id = COALESCE(pi.supplier_id, ws.supplier_id)
+	LEFT JOIN customers c ON c.id = COALESCE(pi.customer_id, cs.owner_id)
+`
+
+type payableRepository struct {
+	db *sqlx.DB
+}
+
+// NewPayableRepository creates a new payable repository
+func NewPayableRepository(db *sqlx.DB) PayableRepository {
+	return &payableRepository{db: db}
+}
+
+// Create saves a new payable together with any payments already made on it
+// in one transaction
+func (r *payableRepository) Create(ctx context.Context, payable *domain.Payable) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := createPayable(ctx, tx, payable); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit payable: %w", err)
+	}
+
+	return nil
+}
+
+// createPayable inserts a payable and its initial payments inside the
+// caller's transaction
+func createPayable(ctx context.Context, tx *sqlx.Tx, payable *domain.Payable) error {
+	if err := insertPayable(ctx, tx, payable); err != nil {
+		return err
+	}
+
+	for _, payment := range payable.Payments {
+		payment.PayableID = payable.ID
+		if err := insertPayablePayment(ctx, tx, payment); err != nil {
+			return err
+		}
+		payable.PaidAmount += payment.Amount
+	}
+
+	if payable.PaidAmount >= payable.Amount && payable.PaidAmount > 0 {
+		payable.Status = domain.PayableStatusPaid
+	} else if payable.PaidAmount > 0 {
+		payable.Status = domain.PayableStatusPartiallyPaid
+	}
+
+	return nil
+}
+
+// insertPayable saves a new payable through the database or the caller's
+// transaction
+func insertPayable(ctx context.Context, q sqlx.QueryerContext, payable *domain.Payable) error {
+	query := `
+		INSERT INTO payables (
+			purchase_invoice_id, consignment_id, work_order_sublet_id, amount, paid_amount,
+			due_date, status, notes
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := q.QueryRowxContext(ctx, query,
+		payable.PurchaseInvoiceID, payable.ConsignmentID, payable.WorkOrderSubletID,
+		payable.Amount, payable.PaidAmount, payable.DueDate, payable.Status, payable.Notes,
+	).Scan(&payable.ID, &payable.CreatedAt, &payable.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create payable: %w", err)
+	}
+
+	return nil
+}
+
+func (r *payableRepository) GetByID(ctx context.Context, id int) (*domain.Payable, error) {
+	var payable domain.Payable
+	query := payableSelect + `WHERE p.id = $1 AND p.deleted_at IS NULL`
+
+	err := r.db.GetContext(ctx, &payable, query, id)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get payable: %w", err)
+	}
+
+	return &payable, nil
+}
+
+func (r *payableRepository) GetByPurchaseInvoiceID(ctx context.Context, purchaseInvoiceID int) (*domain.Payable, error) {
+	var payable domain.Payable
+	query := payableSelect + `WHERE p.purchase_invoice_id = $1 AND p.deleted_at IS NULL`
+
+	err := r.db.GetContext(ctx, &payable, query, purchaseInvoiceID)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get payable by purchase invoice: %w", err)
+	}
+
+	return &payable, nil
+}
+
+func (r *payableRepository) GetByConsignmentID(ctx context.Context, consignmentID int) (*domain.Payable, error) {
+	var payable domain.Payable
+	query := payableSelect + `WHERE p.consignment_id = $1 AND p.deleted_at IS NULL`
+
+	err := r.db.GetContext(ctx, &payable, query, consignmentID)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get payable by consignment: %w", err)
+	}
+
+	return &payable, nil
+}
+
+func (r *payableRepository) GetByWorkOrderSubletID(ctx context.Context, subletID int) (*domain.Payable, error) {
+	var payable domain.Payable
+	query := payableSelect + `WHERE p.work_order_sublet_id = $1 AND p.deleted_at IS NULL`
+
+	err := r.db.GetContext(ctx, &payable, query, subletID)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get payable by work order sublet: %w", err)
+	}
+
+	return &payable, nil
+}
+
+func (r *payableRepository) List(ctx context.Context, offset, limit int) ([]*domain.Payable, error) {
+	var payables []*domain.Payable
+	query := payableSelect + `
+		WHERE p.deleted_at IS NULL
+		ORDER BY p.due_date DESC, p.id DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	err := r.db.SelectContext(ctx, &payables, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payables: %w", err)
+	}
+
+	return payables, nil
+}
+
+func (r *payableRepository) ListByStatus(ctx context.Context, status domain.PayableStatus, offset, limit int) ([]*domain.Payable, error) {
+	var payables []*domain.Payable
+	query := payableSelect + `
+		WHERE p.deleted_at IS NULL AND p.status = $1
+		ORDER BY p.due_date, p.id
+		LIMIT $2 OFFSET $3
+	`
+
+	err := r.db.SelectContext(ctx, &payables, query, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payables by status: %w", err)
+	}
+
+	return payables, nil
+}
+
+// ListOutstanding returns every payable that still has an open balance
+func (r *payableRepository) ListOutstanding(ctx context.Context) ([]*domain.Payable, error) {
+	var payables []*domain.Payable
+	query := payableSelect + `
+		WHERE p.deleted_at IS NULL AND p.status IN ('unpaid', 'partially_paid')
+		ORDER BY p.due_date, p.id
+	`
+
+	err := r.db.SelectContext(ctx, &payables, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outstanding payables: %w", err)
+	}
+
+	return payables, nil
+}
+
+// ListOverdueUnnotified returns open payables past their due date that have
+// not triggered an overdue notification yet
+func (r *payableRepository) ListOverdueUnnotified(ctx context.Context, asOf time.Time) ([]*domain.Payable, error) {
+	var payables []*domain.Payable
+	query := payableSelect + `
+		WHERE p.deleted_at IS NULL AND p.status IN ('unpaid', 'partially_paid')
+		  AND p.due_date < $1 AND p.overdue_notified_at IS NULL
+		ORDER BY p.due_date, p.id
+	`
+
+	err := r.db.SelectContext(ctx, &payables, query, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list overdue payables: %w", err)
+	}
+
+	return payables, nil
+}
+
+func (r *payableRepository) MarkOverdueNotified(ctx context.Context, id int) error {
+	query := `
+		UPDATE payables SET overdue_notified_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark payable overdue notified: %w", err)
+	}
+
+	return nil
+}
+
+func (r *payableRepository) Count(ctx context.Context) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM payables WHERE deleted_at IS NULL`
+
+	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count payables: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *payableRepository) CountByStatus(ctx context.Context, status domain.PayableStatus) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM payables WHERE deleted_at IS NULL AND status = $1`
+
+	err := r.db.QueryRowContext(ctx, query, status).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count payables by status: %w", err)
+	}
+
+	return count, nil
+}
+
+type payablePaymentRepository struct {
+	db *sqlx.DB
+}
+
+// NewPayablePaymentRepository creates a new payable payment repository
+func NewPayablePaymentRepository(db *sqlx.DB) PayablePaymentRepository {
+	return &payablePaymentRepository{db: db}
+}
+
+// Create records a payment and updates the payable balance and status in one
+// transaction. Payments that would exceed the outstanding balance are rejected.
+func (r *payablePaymentRepository) Create(ctx context.Context, payment *domain.PayablePayment) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertPayablePayment(ctx, tx, payment); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit payable payment: %w", err)
+	}
+
+	return nil
+}
+
+// insertPayablePayment records a payment and updates the payable balance and
+// status inside the caller's transaction
+func insertPayablePayment(ctx context.Context, tx *sqlx.Tx, payment *domain.PayablePayment) error {
+	query := `
+		INSERT INTO payable_payments (
+			payment_number, payable_id, amount, payment_date, payment_method,
+			transfer_proof, notes, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := tx.QueryRowContext(ctx, query,
+		payment.PaymentNumber, payment.PayableID, payment.Amount, payment.PaymentDate,
+		payment.PaymentMethod, payment.TransferProof, payment.Notes, payment.CreatedBy,
+	).Scan(&payment.ID, &payment.CreatedAt, &payment.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create payable payment: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE payables SET
+			paid_amount = paid_amount + $2,
+			status = CASE WHEN paid_amount + $2 >= amount THEN 'paid' ELSE 'partially_paid' END
+		WHERE id = $1 AND deleted_at IS NULL AND paid_amount + $2 <= amount
+	`, payment.PayableID, payment.Amount)
+	if err != nil {
+		return fmt.Errorf("failed to update payable balance: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update payable balance: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("payment exceeds outstanding balance")
+	}
+
+	return nil
+}
+
+func (r *payablePaymentRepository) GetByID(ctx context.Context, id int) (*domain.PayablePayment, error) {
+	var payment domain.PayablePayment
+	query := `
+		SELECT id, payment_number, payable_id, amount, payment_date, payment_method,
+			   transfer_proof, notes, created_by,
+			   deleted_at, deleted_by, created_at, updated_at
+		FROM payable_payments
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	err := r.db.GetContext(ctx, &payment, query, id)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get payable payment: %w", err)
+	}
+
+	return &payment, nil
+}
+
+func (r *payablePaymentRepository) ListByPayableID(ctx context.Context, payableID int) ([]*domain.PayablePayment, error) {
+	var payments []*domain.PayablePayment
+	query := `
+		SELECT id, payment_number, payable_id, amount, payment_date, payment_method,
+			   transfer_proof, notes, created_by,
+			   deleted_at, deleted_by, created_at, updated_at
+		FROM payable_payments
+		WHERE payable_id = $1 AND deleted_at IS NULL
+		ORDER BY payment_date, id
+	`
+
+	err := r.db.SelectContext(ctx, &payments, query, payableID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payable payments: %w", err)
+	}
+
+	return payments, nil
+}
+
+func (r *payablePaymentRepository) UpdateTransferProof(ctx context.Context, id int, transferProof string) error {
+	query := `
+		UPDATE payable_payments SET transfer_proof = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, transferProof)
+	if err != nil {
+		return fmt.Errorf("failed to update payment transfer proof: %w", err)
+	}
+
+	return nil
+}
+
+func (r *payablePaymentRepository) GeneratePaymentNumber(ctx context.Context) (string, error) {
+	var count int
+	today := time.Now().Format("20060102")
+
+	query := `
+		SELECT COUNT(*) FROM payable_payments
+		WHERE payment_number LIKE $1
+	`
+
+	err := r.db.QueryRowContext(ctx, query, fmt.Sprintf("PAY-%s%%", today)).Scan(&count)
+	if err != nil {
+		return "", fmt.Errorf("failed to count payable payments for number generation: %w", err)
+	}
+
+	paymentNumber := fmt.Sprintf("PAY-%s-%04d", today, count+1)
+	return paymentNumber, nil
+}
@@ -0,0 +1,505 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type purchaseOrderRepository struct {
+	db *sqlx.DB
+}
+
+// NewPurchaseOrderRepository creates a new purchase order repository
+func NewPurchaseOrderRepository(db *sqlx.DB) PurchaseOrderRepository {
+	return &purchaseOrderRepository{db: db}
+}
+
+func (r *purchaseOrderRepository) Create(ctx context.Context, po *domain.PurchaseOrder) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO purchase_orders (
+			po_number, supplier_id, status, order_date, expected_date,
+			total_amount, notes, auto_generated, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		po.PONumber, po.SupplierID, po.Status, po.OrderDate, po.ExpectedDate,
+		po.TotalAmount, po.Notes, po.AutoGenerated, po.CreatedBy,
+	).Scan(&po.ID, &po.CreatedAt, &po.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create purchase order: %w", err)
+	}
+
+	itemQuery := `
+		INSERT INTO purchase_order_items (
+			purchase_order_id, spare_part_id, quantity_ordered, unit_cost, total_cost
+		)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+
+	for _, item := range po.Items {
+		item.PurchaseOrderID = po.ID
+		err = tx.QueryRowContext(ctx, itemQuery,
+			item.PurchaseOrderID, item.SparePartID, item.QuantityOrdered,
+			item.UnitCost, item.TotalCost,
+		).Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create purchase order item: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit purchase order: %w", err)
+	}
+
+	return nil
+}
+
+func (r *purchaseOrderRepository) GetByID(ctx context.Context, id int) (*domain.PurchaseOrder, error) {
+	var po domain.PurchaseOrder
+	query := `
+		SELECT po.id, po.po_number, po.supplier_id, po.status, po.order_date, po.expected_date,
+			   po.total_amount, po.notes, po.auto_generated, po.created_by,
+			   po.deleted_at, po.deleted_by, po.created_at, po.updated_at,
+			   -- Supplier details
+			   s.id as "supplier.id", s.supplier_code as "supplier.supplier_code",
+			   s.name as "supplier.name", s.phone as "supplier.phone"
+		FROM purchase_orders po
+		JOIN suppliers s ON po.supplier_id = s.id
+		WHERE po.id = $1 AND po.deleted_at IS NULL
+	`
+
+	err := r.db.GetContext(ctx, &po, query, id)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get purchase order: %w", err)
+	}
+
+	return &po, nil
+}
+
+func (r *purchaseOrderRepository) List(ctx context.Context, offset, limit int) ([]*domain.PurchaseOrder, error) {
+	var orders []*domain.PurchaseOrder
+	query := `
+		SELECT po.id, po.po_number, po.supplier_id, po.status, po.order_date, po.expected_date,
+			   po.total_amount, po.notes, po.auto_generated, po.created_by,
+			   po.deleted_at, po.deleted_by, po.created_at, po.updated_at,
+			   -- Supplier details
+			   s.supplier_code as "supplier.supplier_code", s.name as "supplier.name"
+		FROM purchase_orders po
+		JOIN suppliers s ON po.supplier_id = s.id
+		WHERE po.deleted_at IS NULL
+		ORDER BY po.created_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	err := r.db.SelectContext(ctx, &orders, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list purchase orders: %w", err)
+	}
+
+	return orders, nil
+}
+
+func (r *purchaseOrderRepository) ListByStatus(ctx context.Context, status domain.PurchaseOrderStatus, offset, limit int) ([]*domain.PurchaseOrder, error) {
+	var orders []*domain.PurchaseOrder
+	query := `
+		SELECT po.id, po.po_number, po.supplier_id, po.status, po.order_date, po.expected_date,
+			   po.total_amount, po.notes, po.auto_generated, po.created_by,
+			   po.deleted_at, po.deleted_by, po.created_at, po.updated_at,
+			   -- Supplier details
+			   s.supplier_code as "supplier.supplier_code", s.name as "supplier.name"
+		FROM purchase_orders po
+		JOIN suppliers s ON po.supplier_id = s.id
+		WHERE po.deleted_at IS NULL AND po.status = $1
+		ORDER BY po.created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	err := r.db.SelectContext(ctx, &orders, query, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list purchase orders by status: %w", err)
+	}
+
+	return orders, nil
+}
+
+func (r *purchaseOrderRepository) ListItems(ctx context.Context, purchaseOrderID int) ([]*domain.PurchaseOrderItem, error) {
+	var items []*domain.PurchaseOrderItem
+	query := `
+		SELECT poi.id, poi.purchase_order_id, poi.spare_part_id, poi.quantity_ordered,
+			   poi.quantity_received, poi.unit_cost, poi.total_cost,
+			   poi.deleted_at, poi.deleted_by, poi.created_at, poi.updated_at,
+			   -- Spare part details
+			   sp.part_code as "spare_part.part_code", sp.name as "spare_part.name",
+			   sp.unit as "spare_part.unit"
+		FROM purchase_order_items poi
+		JOIN spare_parts sp ON poi.spare_part_id = sp.id
+		WHERE poi.purchase_order_id = $1 AND poi.deleted_at IS NULL
+		ORDER BY poi.id
+	`
+
+	err := r.db.SelectContext(ctx, &items, query, purchaseOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list purchase order items: %w", err)
+	}
+
+	return items, nil
+}
+
+// ListOutstandingItems returns backordered lines of open purchase orders
+func (r *purchaseOrderRepository) ListOutstandingItems(ctx context.Context) ([]*domain.PurchaseOrderItem, error) {
+	var items []*domain.PurchaseOrderItem
+	query := `
+		SELECT poi.id, poi.purchase_order_id, poi.spare_part_id, poi.quantity_ordered,
+			   poi.quantity_received, poi.unit_cost, poi.total_cost,
+			   poi.deleted_at, poi.deleted_by, poi.created_at, poi.updated_at,
+			   -- Spare part details
+			   sp.part_code as "spare_part.part_code", sp.name as "spare_part.name",
+			   sp.unit as "spare_part.unit",
+			   -- Purchase order details
+			   po.po_number as "purchase_order.po_number", po.status as "purchase_order.status",
+			   po.supplier_id as "purchase_order.supplier_id",
+			   po.expected_date as "purchase_order.expected_date"
+		FROM purchase_order_items poi
+		JOIN purchase_orders po ON poi.purchase_order_id = po.id
+		JOIN spare_parts sp ON poi.spare_part_id = sp.id
+		WHERE poi.deleted_at IS NULL AND po.deleted_at IS NULL
+		  AND po.status IN ('ordered', 'partially_received')
+		  AND poi.quantity_received < poi.quantity_ordered
+		ORDER BY po.expected_date NULLS LAST, po.id, poi.id
+	`
+
+	err := r.db.SelectContext(ctx, &items, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outstanding purchase order items: %w", err)
+	}
+
+	return items, nil
+}
+
+func (r *purchaseOrderRepository) UpdateStatus(ctx context.Context, id int, status domain.PurchaseOrderStatus) error {
+	var query string
+
+	if status == domain.PurchaseOrderStatusOrdered {
+		query = `
+			UPDATE purchase_orders SET
+				status = $2, order_date = CURRENT_DATE, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND deleted_at IS NULL
+		`
+	} else {
+		query = `
+			UPDATE purchase_orders SET
+				status = $2, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND deleted_at IS NULL
+		`
+	}
+
+	_, err := r.db.ExecContext(ctx, query, id, status)
+	if err != nil {
+		return fmt.Errorf("failed to update purchase order status: %w", err)
+	}
+
+	return nil
+}
+
+func (r *purchaseOrderRepository) Count(ctx context.Context) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM purchase_orders WHERE deleted_at IS NULL`
+
+	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count purchase orders: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *purchaseOrderRepository) CountByStatus(ctx context.Context, status domain.PurchaseOrderStatus) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM purchase_orders WHERE deleted_at IS NULL AND status = $1`
+
+	err := r.db.QueryRowContext(ctx, query, status).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count purchase orders by status: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *purchaseOrderRepository) GeneratePONumber(ctx context.Context) (string, error) {
+	var count int
+	today := time.Now().Format("20060102")
+
+	query := `
+		SELECT COUNT(*) FROM purchase_orders
+		WHERE po_number LIKE $1
+	`
+
+	err := r.db.QueryRowContext(ctx, query, fmt.Sprintf("PO-%s%%", today)).Scan(&count)
+	if err != nil {
+		return "", fmt.Errorf("failed to count purchase orders for number generation: %w", err)
+	}
+
+	poNumber := fmt.Sprintf("PO-%s-%04d", today, count+1)
+	return poNumber, nil
+}
+
+type goodsReceiptRepository struct {
+	db *sqlx.DB
+}
+
+// NewGoodsReceiptRepository creates a new goods receipt repository
+func NewGoodsReceiptRepository(db *sqlx.DB) GoodsReceiptRepository {
+	return &goodsReceiptRepository{db: db}
+}
+
+// Create posts a goods receipt in one transaction: receipt lines, received
+// quantities on the PO, cost layers, spare part stock and cost price, stock
+// movements and the resulting PO status.
+func (r *goodsReceiptRepository) Create(ctx context.Context, receipt *domain.GoodsReceipt, costingMethod domain.CostingMethod) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	locationID, err := resolveLocationID(ctx, tx, receipt.LocationID)
+	if err != nil {
+		return err
+	}
+	receipt.LocationID = &locationID
+
+	query := `
+		INSERT INTO goods_receipts (
+			receipt_number, purchase_order_id, supplier_invoice_number,
+			received_date, notes, received_by, location_id
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		receipt.ReceiptNumber, receipt.PurchaseOrderID, receipt.SupplierInvoiceNumber,
+		receipt.ReceivedDate, receipt.Notes, receipt.ReceivedBy, receipt.LocationID,
+	).Scan(&receipt.ID, &receipt.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create goods receipt: %w", err)
+	}
+
+	notes := fmt.Sprintf("Goods receipt %s", receipt.ReceiptNumber)
+
+	for _, item := range receipt.Items {
+		item.GoodsReceiptID = receipt.ID
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO goods_receipt_items (
+				goods_receipt_id, purchase_order_item_id, spare_part_id,
+				quantity, unit_cost, total_cost, batch_number, expiry_date
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id, created_at
+		`,
+			item.GoodsReceiptID, item.PurchaseOrderItemID, item.SparePartID,
+			item.Quantity, item.UnitCost, item.TotalCost, item.BatchNumber, item.ExpiryDate,
+		).Scan(&item.ID, &item.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create goods receipt item: %w", err)
+		}
+
+		result, err := tx.ExecContext(ctx, `
+			UPDATE purchase_order_items SET
+				quantity_received = quantity_received + $2, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND deleted_at IS NULL
+			  AND quantity_received + $2 <= quantity_ordered
+		`, item.PurchaseOrderItemID, item.Quantity)
+		if err != nil {
+			return fmt.Errorf("failed to update received quantity: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("received quantity exceeds outstanding quantity for PO item %d", item.PurchaseOrderItemID)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO stock_cost_layers (
+				spare_part_id, reference_type, reference_id, received_date,
+				quantity_received, quantity_remaining, unit_cost
+			)
+			VALUES ($1, $2, $3, $4, $5, $5, $6)
+		`, item.SparePartID, domain.ReferenceTypePurchase, receipt.ID, receipt.ReceivedDate, item.Quantity, item.UnitCost)
+		if err != nil {
+			return fmt.Errorf("failed to create stock cost layer: %w", err)
+		}
+
+		var costQuery string
+		if costingMethod == domain.CostingMethodFIFO {
+			// Cost price = value of open layers per unit
+			costQuery = `
+				UPDATE spare_parts SET
+					stock_quantity = stock_quantity + $2,
+					cost_price = COALESCE((
+						SELECT ROUND(SUM(quantity_remaining * unit_cost) / NULLIF(SUM(quantity_remaining), 0), 2)
+						FROM stock_cost_layers
+						WHERE spare_part_id = $1 AND quantity_remaining > 0
+					), $3),
+					updated_at = CURRENT_TIMESTAMP
+				WHERE id = $1 AND deleted_at IS NULL
+			`
+		} else {
+			// Moving weighted average over stock on hand
+			costQuery = `
+				UPDATE spare_parts SET
+					stock_quantity = stock_quantity + $2,
+					cost_price = CASE
+						WHEN GREATEST(stock_quantity, 0) + $2 > 0 THEN
+							ROUND((GREATEST(stock_quantity, 0) * cost_price + $2 * $3) / (GREATEST(stock_quantity, 0) + $2), 2)
+						ELSE $3
+					END,
+					updated_at = CURRENT_TIMESTAMP
+				WHERE id = $1 AND deleted_at IS NULL
+			`
+		}
+
+		_, err = tx.ExecContext(ctx, costQuery, item.SparePartID, item.Quantity, item.UnitCost)
+		if err != nil {
+			return fmt.Errorf("failed to update spare part stock: %w", err)
+		}
+
+		if _, err := adjustLocationStock(ctx, tx, item.SparePartID, receipt.LocationID, item.Quantity); err != nil {
+			return err
+		}
+
+		batchNumber := receipt.ReceiptNumber
+		if item.BatchNumber != nil {
+			batchNumber = *item.BatchNumber
+		}
+		err = addStockBatch(ctx, tx, &domain.SparePartBatch{
+			SparePartID:      item.SparePartID,
+			LocationID:       locationID,
+			BatchNumber:      batchNumber,
+			ExpiryDate:       item.ExpiryDate,
+			ReceivedDate:     receipt.ReceivedDate,
+			QuantityReceived: item.Quantity,
+			ReferenceType:    domain.ReferenceTypePurchase,
+			ReferenceID:      &receipt.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = receiveSerials(ctx, tx, item.SparePartID, locationID, item.Quantity, item.SerialNumbers,
+			domain.ReferenceTypePurchase, &receipt.ID, receipt.ReceivedDate)
+		if err != nil {
+			return fmt.Errorf("PO item %d: %w", item.PurchaseOrderItemID, err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO stock_movements (
+				spare_part_id, movement_type, quantity, reference_type, reference_id, location_id,
+				notes, created_by, movement_date, unit_cost, total_value
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		`,
+			item.SparePartID, domain.MovementTypeIn, item.Quantity, domain.ReferenceTypePurchase,
+			receipt.ID, receipt.LocationID, notes, receipt.ReceivedBy, receipt.ReceivedDate, item.UnitCost, item.TotalCost,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create stock movement: %w", err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE purchase_orders SET
+			status = CASE
+				WHEN EXISTS (
+					SELECT 1 FROM purchase_order_items
+					WHERE purchase_order_id = $1 AND deleted_at IS NULL
+					  AND quantity_received < quantity_ordered
+				) THEN 'partially_received'
+				ELSE 'received'
+			END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL
+	`, receipt.PurchaseOrderID)
+	if err != nil {
+		return fmt.Errorf("failed to update purchase order status: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit goods receipt: %w", err)
+	}
+
+	return nil
+}
+
+func (r *goodsReceiptRepository) ListByPurchaseOrderID(ctx context.Context, purchaseOrderID int) ([]*domain.GoodsReceipt, error) {
+	var receipts []*domain.GoodsReceipt
+	query := `
+		SELECT id, receipt_number, purchase_order_id, supplier_invoice_number, received_date,
+			   notes, received_by, location_id, deleted_at, deleted_by, created_at
+		FROM goods_receipts
+		WHERE purchase_order_id = $1 AND deleted_at IS NULL
+		ORDER BY received_date, id
+	`
+
+	err := r.db.SelectContext(ctx, &receipts, query, purchaseOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list goods receipts: %w", err)
+	}
+
+	itemQuery := `
+		SELECT gri.id, gri.goods_receipt_id, gri.purchase_order_item_id, gri.spare_part_id,
+			   gri.quantity, gri.unit_cost, gri.total_cost, gri.batch_number, gri.expiry_date, gri.created_at,
+			   -- Spare part details
+			   sp.part_code as "spare_part.part_code", sp.name as "spare_part.name"
+		FROM goods_receipt_items gri
+		JOIN spare_parts sp ON gri.spare_part_id = sp.id
+		WHERE gri.goods_receipt_id = $1
+		ORDER BY gri.id
+	`
+
+	for _, receipt := range receipts {
+		if err := r.db.SelectContext(ctx, &receipt.Items, itemQuery, receipt.ID); err != nil {
+			return nil, fmt.Errorf("failed to list goods receipt items: %w", err)
+		}
+	}
+
+	return receipts, nil
+}
+
+func (r *goodsReceiptRepository) GenerateReceiptNumber(ctx context.Context) (string, error) {
+	var count int
+	today := time.Now().Format("20060102")
+
+	query := `
+		SELECT COUNT(*) FROM goods_receipts
+		WHERE receipt_number LIKE $1
+	`
+
+	err := r.db.QueryRowContext(ctx, query, fmt.Sprintf("GR-%s%%", today)).Scan(&count)
+	if err != nil {
+		return "", fmt.Errorf("failed to count goods receipts for number generation: %w", err)
+	}
+
+	receiptNumber := fmt.Sprintf("GR-%s-%04d", today, count+1)
+	return receiptNumber, nil
+}
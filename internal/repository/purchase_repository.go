@@ -18,37 +18,129 @@ func NewPurchaseInvoiceRepository(db *sqlx.DB) PurchaseInvoiceRepository {
 	return &purchaseInvoiceRepository{db: db}
 }
 
+// Create inserts the invoice together with its per-vehicle items, its
+// payable, the purchase details of every vehicle and their reconditioning
+// work orders in one transaction
 func (r *purchaseInvoiceRepository) Create(ctx context.Context, invoice *domain.PurchaseInvoice) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
 		INSERT INTO purchase_invoices (
 			invoice_number, transaction_type, customer_id, supplier_id, vehicle_id,
-			purchase_price, negotiated_price, final_price, payment_method,
-			transfer_proof, notes, created_by, transaction_date
+			purchase_price, negotiated_price, shared_cost, allocation_method, final_price,
+			payment_method, transfer_proof, notes, created_by, transaction_date
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 		RETURNING id, created_at, updated_at
 	`
 	
-	err := r.db.QueryRowContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		invoice.InvoiceNumber, invoice.TransactionType, invoice.CustomerID,
 		invoice.SupplierID, invoice.VehicleID, invoice.PurchasePrice,
-		invoice.NegotiatedPrice, invoice.FinalPrice, invoice.PaymentMethod,
-		invoice.TransferProof, invoice.Notes, invoice.CreatedBy, invoice.TransactionDate,
+		invoice.NegotiatedPrice, invoice.SharedCost, invoice.AllocationMethod, invoice.FinalPrice,
+		invoice.PaymentMethod, invoice.TransferProof, invoice.Notes, invoice.CreatedBy, invoice.TransactionDate,
 	).Scan(&invoice.ID, &invoice.CreatedAt, &invoice.UpdatedAt)
 	
 	if err != nil {
 		return fmt.Errorf("failed to create purchase invoice: %w", err)
 	}
+
+	for _, item := range invoice.Items {
+		item.PurchaseInvoiceID = invoice.ID
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO purchase_invoice_items (
+				purchase_invoice_id, vehicle_id, price, allocated_cost, total_cost
+			)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, created_at
+		`,
+			item.PurchaseInvoiceID, item.VehicleID, item.Price, item.AllocatedCost, item.TotalCost,
+		).Scan(&item.ID, &item.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create purchase invoice item: %w", err)
+		}
+	}
+
+	if invoice.Payable != nil {
+		invoice.Payable.PurchaseInvoiceID = &invoice.ID
+		if err := createPayable(ctx, tx, invoice.Payable); err != nil {
+			return err
+		}
+	}
+
+	for _, item := range invoice.Items {
+		// A vehicle sold in the meantime is not bought back into stock
+		result, err := tx.ExecContext(ctx, `
+			UPDATE vehicles SET
+				purchase_price = $2, status = $3, purchased_date = $4, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND status <> 'sold' AND deleted_at IS NULL
+		`, item.VehicleID, item.TotalCost, domain.VehicleStatusInRepair, invoice.TransactionDate)
+		if err != nil {
+			return fmt.Errorf("failed to update vehicle: %w", err)
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to update vehicle: %w", err)
+		}
+		if rows == 0 {
+			return fmt.Errorf("vehicle %d is no longer available for purchase", item.VehicleID)
+		}
+
+		if item.WorkOrder == nil {
+			continue
+		}
+		if item.WorkOrder.WONumber == "" {
+			item.WorkOrder.WONumber, err = generateWONumber(ctx, tx)
+			if err != nil {
+				return err
+			}
+		}
+		if err := insertWorkOrder(ctx, tx, item.WorkOrder); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit purchase invoice: %w", err)
+	}
 	
 	return nil
 }
 
+func (r *purchaseInvoiceRepository) ListItemsByInvoiceID(ctx context.Context, invoiceID int) ([]*domain.PurchaseInvoiceItem, error) {
+	var items []*domain.PurchaseInvoiceItem
+	query := `
+		SELECT pii.id, pii.purchase_invoice_id, pii.vehicle_id, pii.price,
+			   pii.allocated_cost, pii.total_cost, pii.created_at,
+			   -- Vehicle details
+			   v.id as "vehicle.id", v.vehicle_code as "vehicle.vehicle_code",
+			   v.brand as "vehicle.brand", v.model as "vehicle.model",
+			   v.year as "vehicle.year", v.status as "vehicle.status"
+		FROM purchase_invoice_items pii
+		JOIN vehicles v ON pii.vehicle_id = v.id
+		WHERE pii.purchase_invoice_id = $1
+		ORDER BY pii.id
+	`
+
+	err := r.db.SelectContext(ctx, &items, query, invoiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list purchase invoice items: %w", err)
+	}
+
+	return items, nil
+}
+
 func (r *purchaseInvoiceRepository) GetByID(ctx context.Context, id int) (*domain.PurchaseInvoice, error) {
 	var invoice domain.PurchaseInvoice
 	query := `
 		SELECT pi.id, pi.invoice_number, pi.transaction_type, pi.customer_id,
 			   pi.supplier_id, pi.vehicle_id, pi.purchase_price, pi.negotiated_price,
-			   pi.final_price, pi.payment_method, pi.transfer_proof, pi.notes,
+			   pi.shared_cost, pi.allocation_method, pi.final_price, pi.payment_method, pi.transfer_proof, pi.notes,
 			   pi.created_by, pi.transaction_date, pi.deleted_at, pi.deleted_by,
 			   pi.created_at, pi.updated_at,
 			   -- Customer details
@@ -87,7 +179,7 @@ func (r *purchaseInvoiceRepository) GetByInvoiceNumber(ctx context.Context, invo
 	query := `
 		SELECT pi.id, pi.invoice_number, pi.transaction_type, pi.customer_id,
 			   pi.supplier_id, pi.vehicle_id, pi.purchase_price, pi.negotiated_price,
-			   pi.final_price, pi.payment_method, pi.transfer_proof, pi.notes,
+			   pi.shared_cost, pi.allocation_method, pi.final_price, pi.payment_method, pi.transfer_proof, pi.notes,
 			   pi.created_by, pi.transaction_date, pi.deleted_at, pi.deleted_by,
 			   pi.created_at, pi.updated_at
 		FROM purchase_invoices pi
@@ -102,12 +194,40 @@ func (r *purchaseInvoiceRepository) GetByInvoiceNumber(ctx context.Context, invo
 	return &invoice, nil
 }
 
+// GetByVehicleID returns the purchase invoice a vehicle was bought on, nil
+// when it has none
+func (r *purchaseInvoiceRepository) GetByVehicleID(ctx context.Context, vehicleID int) (*domain.PurchaseInvoice, error) {
+	var invoice domain.PurchaseInvoice
+	query := `
+		SELECT pi.id, pi.invoice_number, pi.transaction_type, pi.customer_id,
+			   pi.supplier_id, pi.vehicle_id, pi.purchase_price, pi.negotiated_price,
+			   pi.shared_cost, pi.allocation_method, pi.final_price, pi.payment_method, pi.transfer_proof, pi.notes,
+			   pi.created_by, pi.transaction_date, pi.deleted_at, pi.deleted_by,
+			   pi.created_at, pi.updated_at
+		FROM purchase_invoices pi
+		JOIN purchase_invoice_items pii ON pii.purchase_invoice_id = pi.id
+		WHERE pii.vehicle_id = $1 AND pi.deleted_at IS NULL
+		ORDER BY pi.id DESC
+		LIMIT 1
+	`
+	
+	err := r.db.GetContext(ctx, &invoice, query, vehicleID)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get purchase invoice by vehicle: %w", err)
+	}
+	
+	return &invoice, nil
+}
+
 func (r *purchaseInvoiceRepository) List(ctx context.Context, offset, limit int) ([]*domain.PurchaseInvoice, error) {
 	var invoices []*domain.PurchaseInvoice
 	query := `
 		SELECT pi.id, pi.invoice_number, pi.transaction_type, pi.customer_id,
 			   pi.supplier_id, pi.vehicle_id, pi.purchase_price, pi.negotiated_price,
-			   pi.final_price, pi.payment_method, pi.transfer_proof, pi.notes,
+			   pi.shared_cost, pi.allocation_method, pi.final_price, pi.payment_method, pi.transfer_proof, pi.notes,
 			   pi.created_by, pi.transaction_date, pi.deleted_at, pi.deleted_by,
 			   pi.created_at, pi.updated_at,
 			   -- Customer details
@@ -142,7 +262,7 @@ func (r *purchaseInvoiceRepository) ListByDateRange(ctx context.Context, startDa
 	query := `
 		SELECT pi.id, pi.invoice_number, pi.transaction_type, pi.customer_id,
 			   pi.supplier_id, pi.vehicle_id, pi.purchase_price, pi.negotiated_price,
-			   pi.final_price, pi.payment_method, pi.transfer_proof, pi.notes,
+			   pi.shared_cost, pi.allocation_method, pi.final_price, pi.payment_method, pi.transfer_proof, pi.notes,
 			   pi.created_by, pi.transaction_date, pi.deleted_at, pi.deleted_by,
 			   pi.created_at, pi.updated_at
 		FROM purchase_invoices pi
@@ -166,7 +286,7 @@ func (r *purchaseInvoiceRepository) ListByTransactionType(ctx context.Context, t
 	query := `
 		SELECT pi.id, pi.invoice_number, pi.transaction_type, pi.customer_id,
 			   pi.supplier_id, pi.vehicle_id, pi.purchase_price, pi.negotiated_price,
-			   pi.final_price, pi.payment_method, pi.transfer_proof, pi.notes,
+			   pi.shared_cost, pi.allocation_method, pi.final_price, pi.payment_method, pi.transfer_proof, pi.notes,
 			   pi.created_by, pi.transaction_date, pi.deleted_at, pi.deleted_by,
 			   pi.created_at, pi.updated_at
 		FROM purchase_invoices pi
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type replenishmentRepository struct {
+	db *sqlx.DB
+}
+
+// NewReplenishmentRepository creates a new replenishment repository
+func NewReplenishmentRepository(db *sqlx.DB) ReplenishmentRepository {
+	return &replenishmentRepository{db: db}
+}
+
+// ListUsage returns, per active spare part, its net usage since the given
+// date, the quantity still on order and the supplier it is bought from.
+// Usage is parts issued to work orders less returns, plus manual stock
+// issues; transfers and stock count corrections are not usage.
+func (r *replenishmentRepository) ListUsage(ctx context.Context, since time.Time, policy domain.ReplenishmentPolicy, supplierID *int) ([]*domain.ReorderSuggestion, error) {
+	var suggestions []*domain.ReorderSuggestion
+	query := `
+		WITH usage AS (
+			SELECT spare_part_id, SUM(quantity_used - COALESCE(quantity_returned, 0)) AS quantity
+			FROM work_order_parts
+			WHERE usage_date >= $1 AND deleted_at IS NULL
+			GROUP BY spare_part_id
+			UNION ALL
+			SELECT spare_part_id, SUM(quantity) AS quantity
+			FROM stock_movements
+			WHERE movement_type = 'out' AND reference_type = 'adjustment'
+			  AND movement_date >= $1 AND deleted_at IS NULL
+			GROUP BY spare_part_id
+		), usage_total AS (
+			SELECT spare_part_id, SUM(quantity) AS quantity FROM usage GROUP BY spare_part_id
+		), on_order AS (
+			SELECT poi.spare_part_id, SUM(poi.quantity_ordered - poi.quantity_received) AS quantity
+			FROM purchase_order_items poi
+			JOIN purchase_orders po ON poi.purchase_order_id = po.id
+			WHERE po.status IN ('draft', 'ordered', 'partially_received')
+			  AND po.deleted_at IS NULL AND poi.deleted_at IS NULL
+			GROUP BY poi.spare_part_id
+		), last_supplier AS (
+			SELECT DISTINCT ON (poi.spare_part_id) poi.spare_part_id, po.supplier_id
+			FROM purchase_order_items poi
+			JOIN purchase_orders po ON poi.purchase_order_id = po.id
+			WHERE po.status <> 'cancelled' AND po.deleted_at IS NULL AND poi.deleted_at IS NULL
+			ORDER BY poi.spare_part_id, po.created_at DESC
+		)
+		SELECT sp.id AS spare_part_id, sp.part_code, sp.name, sp.unit, sp.cost_price,
+			   sp.stock_quantity, sp.min_stock_level, sp.auto_reorder, sp.preferred_supplier_id,
+			   COALESCE(ut.quantity, 0) AS usage_quantity,
+			   COALESCE(oo.quantity, 0) AS on_order_quantity,
+			   s.id AS supplier_id, s.name AS supplier_name,
+			   COALESCE(s.lead_time_days, $2) AS lead_time_days,
+			   COALESCE(sp.safety_stock_days, $3) AS safety_stock_days
+		FROM spare_parts sp
+		LEFT JOIN usage_total ut ON ut.spare_part_id = sp.id
+		LEFT JOIN on_order oo ON oo.spare_part_id = sp.id
+		LEFT JOIN last_supplier ls ON ls.spare_part_id = sp.id
+		LEFT JOIN suppliers s ON s.id = COALESCE(sp.preferred_supplier_id, ls.supplier_id) AND s.deleted_at IS NULL
+		WHERE sp.deleted_at IS NULL
+		  AND ($4::int IS NULL OR s.id = $4)
+		ORDER BY sp.part_code
+	`
+
+	err := r.db.SelectContext(ctx, &suggestions, query, since, policy.DefaultLeadTimeDays, policy.SafetyStockDays, supplierID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list spare part usage: %w", err)
+	}
+
+	return suggestions, nil
+}
+
+func (r *replenishmentRepository) UpdateSettings(ctx context.Context, settings *domain.ReorderSettings) error {
+	query := `
+		UPDATE spare_parts SET
+			preferred_supplier_id = $2, safety_stock_days = $3, auto_reorder = $4,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		settings.SparePartID, settings.PreferredSupplierID, settings.SafetyStockDays, settings.AutoReorder,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update reorder settings: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update reorder settings: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("spare part not found")
+	}
+
+	return nil
+}
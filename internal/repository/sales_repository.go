@@ -19,6 +19,151 @@ func NewSalesInvoiceRepository(db *sqlx.DB) SalesInvoiceRepository {
 }
 
 func (r *salesInvoiceRepository) Create(ctx context.Context, invoice *domain.SalesInvoice) error {
+	return insertSalesInvoice(ctx, r.db, invoice)
+}
+
+// CreateConsignmentSale saves the sale of a consignment vehicle together with
+// the consignment settlement and the payable to the owner
+func (r *salesInvoiceRepository) CreateConsignmentSale(ctx context.Context, invoice *domain.SalesInvoice, consignment *domain.Consignment) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertSalesInvoice(ctx, tx, invoice); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE consignments SET
+			status = $5, sales_invoice_id = $2, sale_price = $3, commission_amount = $4
+		WHERE id = $1 AND status = $6 AND deleted_at IS NULL
+	`, consignment.ID, invoice.ID, consignment.SalePrice, consignment.CommissionAmount,
+		domain.ConsignmentStatusSold, domain.ConsignmentStatusActive)
+	if err != nil {
+		return fmt.Errorf("failed to mark consignment sold: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to mark consignment sold: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("consignment %s is no longer active", consignment.ConsignmentNumber)
+	}
+	consignment.Status = domain.ConsignmentStatusSold
+	consignment.SalesInvoiceID = &invoice.ID
+
+	if err := insertPayable(ctx, tx, consignment.Payable); err != nil {
+		return fmt.Errorf("failed to create owner payable: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit consignment sale: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteConsignmentSale voids the sale of a consignment vehicle: the invoice
+// and the owner payable are soft deleted and the consignment is active again.
+// A sale whose owner payable has payments cannot be voided.
+func (r *salesInvoiceRepository) DeleteConsignmentSale(ctx context.Context, id int, deletedBy int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var consignmentID int
+	err = tx.QueryRowContext(ctx, `
+		SELECT id FROM consignments WHERE sales_invoice_id = $1 AND deleted_at IS NULL FOR UPDATE
+	`, id).Scan(&consignmentID)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return fmt.Errorf("consignment not found for sales invoice")
+		}
+		return fmt.Errorf("failed to lock consignment: %w", err)
+	}
+
+	var payableID int
+	var paidAmount float64
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, paid_amount FROM payables WHERE consignment_id = $1 AND deleted_at IS NULL FOR UPDATE
+	`, consignmentID).Scan(&payableID, &paidAmount)
+	if err != nil && !IsNoRowsError(err) {
+		return fmt.Errorf("failed to lock owner payable: %w", err)
+	}
+
+	if err == nil {
+		var payments int
+		err = tx.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM payable_payments WHERE payable_id = $1 AND deleted_at IS NULL
+		`, payableID).Scan(&payments)
+		if err != nil {
+			return fmt.Errorf("failed to count owner payable payments: %w", err)
+		}
+		if payments > 0 || paidAmount > 0 {
+			return fmt.Errorf("the owner has already been paid for this sale")
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE payables SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $2 WHERE id = $1
+		`, payableID, deletedBy)
+		if err != nil {
+			return fmt.Errorf("failed to delete owner payable: %w", err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE consignments SET
+			status = $2, sales_invoice_id = NULL, sale_price = NULL, commission_amount = NULL
+		WHERE id = $1
+	`, consignmentID, domain.ConsignmentStatusActive)
+	if err != nil {
+		return fmt.Errorf("failed to reopen consignment: %w", err)
+	}
+
+	if err := softDeleteSalesInvoice(ctx, tx, id, deletedBy); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit sales invoice deletion: %w", err)
+	}
+
+	return nil
+}
+
+// softDeleteSalesInvoice deletes the invoice and takes the vehicle documents
+// handed over under it back into custody
+func softDeleteSalesInvoice(ctx context.Context, tx *sqlx.Tx, id int, deletedBy int) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE sales_invoices SET
+			deleted_at = CURRENT_TIMESTAMP, deleted_by = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL
+	`, id, deletedBy)
+	if err != nil {
+		return fmt.Errorf("failed to soft delete sales invoice: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE vehicle_documents SET
+			custody_status = 'in_custody', sales_invoice_id = NULL, handed_over_at = NULL,
+			handed_over_to = NULL, handed_over_by = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE sales_invoice_id = $1 AND custody_status = 'handed_over' AND deleted_at IS NULL
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to return handed over documents to custody: %w", err)
+	}
+
+	return nil
+}
+
+// insertSalesInvoice saves a new sales invoice through the database or the
+// caller's transaction
+func insertSalesInvoice(ctx context.Context, q sqlx.QueryerContext, invoice *domain.SalesInvoice) error {
 	query := `
 		INSERT INTO sales_invoices (
 			invoice_number, customer_id, vehicle_id, selling_price,
@@ -29,7 +174,7 @@ func (r *salesInvoiceRepository) Create(ctx context.Context, invoice *domain.Sal
 		RETURNING id, created_at, updated_at
 	`
 	
-	err := r.db.QueryRowContext(ctx, query,
+	err := q.QueryRowxContext(ctx, query,
 		invoice.InvoiceNumber, invoice.CustomerID, invoice.VehicleID,
 		invoice.SellingPrice, invoice.DiscountPercentage, invoice.DiscountAmount,
 		invoice.FinalPrice, invoice.PaymentMethod, invoice.TransferProof,
@@ -197,16 +342,21 @@ func (r *salesInvoiceRepository) Update(ctx context.Context, invoice *domain.Sal
 	return nil
 }
 
+// SoftDelete deletes the invoice; documents handed over under it go back into
+// custody in the same transaction
 func (r *salesInvoiceRepository) SoftDelete(ctx context.Context, id int, deletedBy int) error {
-	query := `
-		UPDATE sales_invoices SET 
-			deleted_at = CURRENT_TIMESTAMP, deleted_by = $2, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $1 AND deleted_at IS NULL
-	`
-	
-	_, err := r.db.ExecContext(ctx, query, id, deletedBy)
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to soft delete sales invoice: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	
+	if err := softDeleteSalesInvoice(ctx, tx, id, deletedBy); err != nil {
+		return err
+	}
+	
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit sales invoice deletion: %w", err)
 	}
 	
 	return nil
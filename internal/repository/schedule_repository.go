@@ -0,0 +1,299 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type workshopBayRepository struct {
+	db *sqlx.DB
+}
+
+// NewWorkshopBayRepository creates a new workshop bay repository
+func NewWorkshopBayRepository(db *sqlx.DB) WorkshopBayRepository {
+	return &workshopBayRepository{db: db}
+}
+
+func (r *workshopBayRepository) Create(ctx context.Context, bay *domain.WorkshopBay) error {
+	query := `
+		INSERT INTO workshop_bays (code, name, is_active, notes)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		bay.Code, bay.Name, bay.IsActive, bay.Notes,
+	).Scan(&bay.ID, &bay.CreatedAt, &bay.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create workshop bay: %w", err)
+	}
+
+	return nil
+}
+
+func (r *workshopBayRepository) GetByID(ctx context.Context, id int) (*domain.WorkshopBay, error) {
+	var bay domain.WorkshopBay
+	query := `
+		SELECT id, code, name, is_active, notes, deleted_at, deleted_by, created_at, updated_at
+		FROM workshop_bays
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	err := r.db.GetContext(ctx, &bay, query, id)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get workshop bay: %w", err)
+	}
+
+	return &bay, nil
+}
+
+func (r *workshopBayRepository) List(ctx context.Context) ([]*domain.WorkshopBay, error) {
+	var bays []*domain.WorkshopBay
+	query := `
+		SELECT id, code, name, is_active, notes, deleted_at, deleted_by, created_at, updated_at
+		FROM workshop_bays
+		WHERE deleted_at IS NULL
+		ORDER BY code
+	`
+
+	err := r.db.SelectContext(ctx, &bays, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workshop bays: %w", err)
+	}
+
+	return bays, nil
+}
+
+func (r *workshopBayRepository) Update(ctx context.Context, bay *domain.WorkshopBay) error {
+	query := `
+		UPDATE workshop_bays SET
+			code = $2, name = $3, is_active = $4, notes = $5
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		bay.ID, bay.Code, bay.Name, bay.IsActive, bay.Notes,
+	).Scan(&bay.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to update workshop bay: %w", err)
+	}
+
+	return nil
+}
+
+func (r *workshopBayRepository) SoftDelete(ctx context.Context, id int, deletedBy int) error {
+	query := `
+		UPDATE workshop_bays SET
+			deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, deletedBy)
+	if err != nil {
+		return fmt.Errorf("failed to delete workshop bay: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete workshop bay: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("workshop bay not found or already deleted")
+	}
+
+	return nil
+}
+
+// workOrderScheduleSelect joins what the front office needs to read a plan:
+// the work order, its vehicle, the task, the bay and the mechanic
+const workOrderScheduleSelect = `
+	SELECT s.id, s.work_order_id, s.work_order_task_id, s.bay_id, s.mechanic_id,
+		   s.planned_start, s.planned_end, s.notes, s.created_by,
+		   s.deleted_at, s.deleted_by, s.created_at, s.updated_at,
+		   wo.wo_number, wo.status as work_order_status, wo.progress_percentage,
+		   COALESCE(v.vehicle_code, cv.plate_number, '') as vehicle_label,
+		   t.title as task_title, t.status as task_status,
+		   b.name as bay_name, u.full_name as mechanic_name
+	FROM work_order_schedules s
+	JOIN work_orders wo ON s.work_order_id = wo.id AND wo.deleted_at IS NULL
+	LEFT JOIN vehicles v ON wo.vehicle_id = v.id
+	LEFT JOIN customer_vehicles cv ON wo.customer_vehicle_id = cv.id
+	LEFT JOIN work_order_tasks t ON s.work_order_task_id = t.id
+	LEFT JOIN workshop_bays b ON s.bay_id = b.id
+	LEFT JOIN users u ON s.mechanic_id = u.id
+`
+
+type workOrderScheduleRepository struct {
+	db *sqlx.DB
+}
+
+// NewWorkOrderScheduleRepository creates a new work order schedule repository
+func NewWorkOrderScheduleRepository(db *sqlx.DB) WorkOrderScheduleRepository {
+	return &workOrderScheduleRepository{db: db}
+}
+
+func (r *workOrderScheduleRepository) Create(ctx context.Context, schedule *domain.WorkOrderSchedule) error {
+	query := `
+		INSERT INTO work_order_schedules (
+			work_order_id, work_order_task_id, bay_id, mechanic_id,
+			planned_start, planned_end, notes, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		schedule.WorkOrderID, schedule.WorkOrderTaskID, schedule.BayID, schedule.MechanicID,
+		schedule.PlannedStart, schedule.PlannedEnd, schedule.Notes, schedule.CreatedBy,
+	).Scan(&schedule.ID, &schedule.CreatedAt, &schedule.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create work order schedule: %w", err)
+	}
+
+	return nil
+}
+
+func (r *workOrderScheduleRepository) GetByID(ctx context.Context, id int) (*domain.WorkOrderSchedule, error) {
+	var schedule domain.WorkOrderSchedule
+	query := workOrderScheduleSelect + `WHERE s.id = $1 AND s.deleted_at IS NULL`
+
+	err := r.db.GetContext(ctx, &schedule, query, id)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get work order schedule: %w", err)
+	}
+
+	return &schedule, nil
+}
+
+func (r *workOrderScheduleRepository) Update(ctx context.Context, schedule *domain.WorkOrderSchedule) error {
+	query := `
+		UPDATE work_order_schedules SET
+			work_order_task_id = $2, bay_id = $3, mechanic_id = $4,
+			planned_start = $5, planned_end = $6, notes = $7
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		schedule.ID, schedule.WorkOrderTaskID, schedule.BayID, schedule.MechanicID,
+		schedule.PlannedStart, schedule.PlannedEnd, schedule.Notes,
+	).Scan(&schedule.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to update work order schedule: %w", err)
+	}
+
+	return nil
+}
+
+func (r *workOrderScheduleRepository) Delete(ctx context.Context, id int, deletedBy int) error {
+	query := `
+		UPDATE work_order_schedules SET
+			deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, deletedBy)
+	if err != nil {
+		return fmt.Errorf("failed to delete work order schedule: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete work order schedule: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("work order schedule not found or already deleted")
+	}
+
+	return nil
+}
+
+func (r *workOrderScheduleRepository) ListByWorkOrderID(ctx context.Context, workOrderID int) ([]*domain.WorkOrderSchedule, error) {
+	var schedules []*domain.WorkOrderSchedule
+	query := workOrderScheduleSelect + `
+		WHERE s.work_order_id = $1 AND s.deleted_at IS NULL
+		ORDER BY s.planned_start, s.id
+	`
+
+	err := r.db.SelectContext(ctx, &schedules, query, workOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list work order schedules: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// ListInRange returns the planned work overlapping [start, end), optionally
+// for one bay or one mechanic
+func (r *workOrderScheduleRepository) ListInRange(ctx context.Context, start, end time.Time, bayID, mechanicID *int) ([]*domain.WorkOrderSchedule, error) {
+	var schedules []*domain.WorkOrderSchedule
+	query := workOrderScheduleSelect + `
+		WHERE s.deleted_at IS NULL AND wo.status <> 'cancelled'
+		  AND s.planned_start < $2 AND s.planned_end > $1
+		  AND ($3::int IS NULL OR s.bay_id = $3)
+		  AND ($4::int IS NULL OR s.mechanic_id = $4)
+		ORDER BY s.planned_start, s.id
+	`
+
+	err := r.db.SelectContext(ctx, &schedules, query, start, end, bayID, mechanicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list work order schedules in range: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// ListConflicts returns other open planned work that overlaps the schedule on
+// the same bay or with the same mechanic
+func (r *workOrderScheduleRepository) ListConflicts(ctx context.Context, schedule *domain.WorkOrderSchedule) ([]*domain.WorkOrderSchedule, error) {
+	var schedules []*domain.WorkOrderSchedule
+	query := workOrderScheduleSelect + `
+		WHERE s.deleted_at IS NULL AND s.id <> $1
+		  AND wo.status NOT IN ('completed', 'cancelled')
+		  AND (t.status IS NULL OR t.status IN ('pending', 'in_progress'))
+		  AND s.planned_start < $3 AND s.planned_end > $2
+		  AND ((s.bay_id = $4) OR (s.mechanic_id = $5))
+		ORDER BY s.planned_start, s.id
+	`
+
+	err := r.db.SelectContext(ctx, &schedules, query,
+		schedule.ID, schedule.PlannedStart, schedule.PlannedEnd, schedule.BayID, schedule.MechanicID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedule conflicts: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// ListOpen returns the planned work of every work order that is not
+// completed or cancelled
+func (r *workOrderScheduleRepository) ListOpen(ctx context.Context) ([]*domain.WorkOrderSchedule, error) {
+	var schedules []*domain.WorkOrderSchedule
+	query := workOrderScheduleSelect + `
+		WHERE s.deleted_at IS NULL AND wo.status NOT IN ('completed', 'cancelled')
+		ORDER BY s.work_order_id, s.planned_start, s.id
+	`
+
+	err := r.db.SelectContext(ctx, &schedules, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open work order schedules: %w", err)
+	}
+
+	return schedules, nil
+}
@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// serviceInvoiceSelect joins the work order and customer so listings show
+// which job and who is being billed
+const serviceInvoiceSelect = `
+	SELECT si.id, si.invoice_number, si.work_order_id, si.customer_id, si.invoice_date,
+		   si.due_date, si.parts_amount, si.labor_amount, si.discount_amount, si.total_amount,
+		   si.paid_amount, si.status, si.notes, si.created_by,
+		   si.deleted_at, si.deleted_by, si.created_at, si.updated_at,
+		   wo.wo_number, c.name as customer_name
+	FROM service_invoices si
+	JOIN work_orders wo ON si.work_order_id = wo.id
+	JOIN customers c ON si.customer_id = c.id
+`
+
+type serviceInvoiceRepository struct {
+	db *sqlx.DB
+}
+
+// NewServiceInvoiceRepository creates a new service invoice repository
+func NewServiceInvoiceRepository(db *sqlx.DB) ServiceInvoiceRepository {
+	return &serviceInvoiceRepository{db: db}
+}
+
+// Create inserts the invoice together with its part and labor lines
+func (r *serviceInvoiceRepository) Create(ctx context.Context, invoice *domain.ServiceInvoice) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO service_invoices (
+			invoice_number, work_order_id, customer_id, invoice_date, due_date,
+			parts_amount, labor_amount, discount_amount, total_amount, paid_amount,
+			status, notes, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		invoice.InvoiceNumber, invoice.WorkOrderID, invoice.CustomerID, invoice.InvoiceDate,
+		invoice.DueDate, invoice.PartsAmount, invoice.LaborAmount, invoice.DiscountAmount,
+		invoice.TotalAmount, invoice.PaidAmount, invoice.Status, invoice.Notes, invoice.CreatedBy,
+	).Scan(&invoice.ID, &invoice.CreatedAt, &invoice.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create service invoice: %w", err)
+	}
+
+	itemQuery := `
+		INSERT INTO service_invoice_items (
+			service_invoice_id, item_type, spare_part_id, description,
+			quantity, unit_price, total_price
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	for _, item := range invoice.Items {
+		item.ServiceInvoiceID = invoice.ID
+		err = tx.QueryRowContext(ctx, itemQuery,
+			item.ServiceInvoiceID, item.ItemType, item.SparePartID, item.Description,
+			item.Quantity, item.UnitPrice, item.TotalPrice,
+		).Scan(&item.ID, &item.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create service invoice item: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit service invoice: %w", err)
+	}
+
+	return nil
+}
+
+func (r *serviceInvoiceRepository) GetByID(ctx context.Context, id int) (*domain.ServiceInvoice, error) {
+	var invoice domain.ServiceInvoice
+	query := serviceInvoiceSelect + `WHERE si.id = $1 AND si.deleted_at IS NULL`
+
+	err := r.db.GetContext(ctx, &invoice, query, id)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get service invoice: %w", err)
+	}
+
+	return &invoice, nil
+}
+
+func (r *serviceInvoiceRepository) GetByWorkOrderID(ctx context.Context, workOrderID int) (*domain.ServiceInvoice, error) {
+	var invoice domain.ServiceInvoice
+	query := serviceInvoiceSelect + `WHERE si.work_order_id = $1 AND si.deleted_at IS NULL`
+
+	err := r.db.GetContext(ctx, &invoice, query, workOrderID)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get service invoice by work order: %w", err)
+	}
+
+	return &invoice, nil
+}
+
+func (r *serviceInvoiceRepository) List(ctx context.Context, offset, limit int) ([]*domain.ServiceInvoice, error) {
+	var invoices []*domain.ServiceInvoice
+	query := serviceInvoiceSelect + `
+		WHERE si.deleted_at IS NULL
+		ORDER BY si.invoice_date DESC, si.id DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	err := r.db.SelectContext(ctx, &invoices, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service invoices: %w", err)
+	}
+
+	return invoices, nil
+}
+
+func (r *serviceInvoiceRepository) ListByStatus(ctx context.Context, status domain.ServiceInvoiceStatus, offset, limit int) ([]*domain.ServiceInvoice, error) {
+	var invoices []*domain.ServiceInvoice
+	query := serviceInvoiceSelect + `
+		WHERE si.deleted_at IS NULL AND si.status = $1
+		ORDER BY si.due_date, si.id
+		LIMIT $2 OFFSET $3
+	`
+
+	err := r.db.SelectContext(ctx, &invoices, query, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service invoices by status: %w", err)
+	}
+
+	return invoices, nil
+}
+
+func (r *serviceInvoiceRepository) Count(ctx context.Context) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM service_invoices WHERE deleted_at IS NULL`
+
+	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count service invoices: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *serviceInvoiceRepository) CountByStatus(ctx context.Context, status domain.ServiceInvoiceStatus) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM service_invoices WHERE deleted_at IS NULL AND status = $1`
+
+	err := r.db.QueryRowContext(ctx, query, status).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count service invoices by status: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *serviceInvoiceRepository) ListItems(ctx context.Context, invoiceID int) ([]*domain.ServiceInvoiceItem, error) {
+	var items []*domain.ServiceInvoiceItem
+	query := `
+		SELECT id, service_invoice_id, item_type, spare_part_id, description,
+			   quantity, unit_price, total_price, created_at
+		FROM service_invoice_items
+		WHERE service_invoice_id = $1
+		ORDER BY id
+	`
+
+	err := r.db.SelectContext(ctx, &items, query, invoiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service invoice items: %w", err)
+	}
+
+	return items, nil
+}
+
+func (r *serviceInvoiceRepository) GenerateInvoiceNumber(ctx context.Context) (string, error) {
+	var count int
+	today := time.Now().Format("20060102")
+
+	query := `
+		SELECT COUNT(*) FROM service_invoices
+		WHERE invoice_number LIKE $1
+	`
+
+	err := r.db.QueryRowContext(ctx, query, fmt.Sprintf("SVC-%s%%", today)).Scan(&count)
+	if err != nil {
+		return "", fmt.Errorf("failed to count service invoices for number generation: %w", err)
+	}
+
+	invoiceNumber := fmt.Sprintf("SVC-%s-%04d", today, count+1)
+	return invoiceNumber, nil
+}
+
+type serviceInvoicePaymentRepository struct {
+	db *sqlx.DB
+}
+
+// NewServiceInvoicePaymentRepository creates a new service invoice payment repository
+func NewServiceInvoicePaymentRepository(db *sqlx.DB) ServiceInvoicePaymentRepository {
+	return &serviceInvoicePaymentRepository{db: db}
+}
+
+// Create records a customer payment and updates the invoice balance and status
+// in one transaction. Payments that would exceed the outstanding balance are
+// rejected.
+func (r *serviceInvoicePaymentRepository) Create(ctx context.Context, payment *domain.ServiceInvoicePayment) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO service_invoice_payments (
+			payment_number, service_invoice_id, amount, payment_date, payment_method,
+			transfer_proof, notes, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		payment.PaymentNumber, payment.ServiceInvoiceID, payment.Amount, payment.PaymentDate,
+		payment.PaymentMethod, payment.TransferProof, payment.Notes, payment.CreatedBy,
+	).Scan(&payment.ID, &payment.CreatedAt, &payment.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create service invoice payment: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE service_invoices SET
+			paid_amount = paid_amount + $2,
+			status = CASE WHEN paid_amount + $2 >= total_amount THEN 'paid' ELSE 'partially_paid' END
+		WHERE id = $1 AND deleted_at IS NULL AND paid_amount + $2 <= total_amount
+	`, payment.ServiceInvoiceID, payment.Amount)
+	if err != nil {
+		return fmt.Errorf("failed to update service invoice balance: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update service invoice balance: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("payment exceeds outstanding balance")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit service invoice payment: %w", err)
+	}
+
+	return nil
+}
+
+func (r *serviceInvoicePaymentRepository) ListByInvoiceID(ctx context.Context, invoiceID int) ([]*domain.ServiceInvoicePayment, error) {
+	var payments []*domain.ServiceInvoicePayment
+	query := `
+		SELECT id, payment_number, service_invoice_id, amount, payment_date, payment_method,
+			   transfer_proof, notes, created_by,
+			   deleted_at, deleted_by, created_at, updated_at
+		FROM service_invoice_payments
+		WHERE service_invoice_id = $1 AND deleted_at IS NULL
+		ORDER BY payment_date, id
+	`
+
+	err := r.db.SelectContext(ctx, &payments, query, invoiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service invoice payments: %w", err)
+	}
+
+	return payments, nil
+}
+
+func (r *serviceInvoicePaymentRepository) GeneratePaymentNumber(ctx context.Context) (string, error) {
+	var count int
+	today := time.Now().Format("20060102")
+
+	query := `
+		SELECT COUNT(*) FROM service_invoice_payments
+		WHERE payment_number LIKE $1
+	`
+
+	err := r.db.QueryRowContext(ctx, query, fmt.Sprintf("SVP-%s%%", today)).Scan(&count)
+	if err != nil {
+		return "", fmt.Errorf("failed to count service invoice payments for number generation: %w", err)
+	}
+
+	paymentNumber := fmt.Sprintf("SVP-%s-%04d", today, count+1)
+	return paymentNumber, nil
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type serviceKitRepository struct {
+	db *sqlx.DB
+}
+
+// NewServiceKitRepository creates a new service kit repository
+func NewServiceKitRepository(db *sqlx.DB) ServiceKitRepository {
+	return &serviceKitRepository{db: db}
+}
+
+const serviceKitColumns = `
+	id, kit_code, name, description, labor_hours, labor_price, is_active, created_by,
+	deleted_at, deleted_by, created_at, updated_at
+`
+
+// Create saves a kit with its items
+func (r *serviceKitRepository) Create(ctx context.Context, kit *domain.ServiceKit) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO service_kits (kit_code, name, description, labor_hours, labor_price, is_active, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		kit.KitCode, kit.Name, kit.Description, kit.LaborHours, kit.LaborPrice, kit.IsActive, kit.CreatedBy,
+	).Scan(&kit.ID, &kit.CreatedAt, &kit.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create service kit: %w", err)
+	}
+
+	if err := insertServiceKitItems(ctx, tx, kit); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit service kit: %w", err)
+	}
+
+	return nil
+}
+
+func (r *serviceKitRepository) GetByID(ctx context.Context, id int) (*domain.ServiceKit, error) {
+	var kit domain.ServiceKit
+	query := `SELECT ` + serviceKitColumns + ` FROM service_kits WHERE id = $1 AND deleted_at IS NULL`
+
+	err := r.db.GetContext(ctx, &kit, query, id)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get service kit: %w", err)
+	}
+
+	return &kit, nil
+}
+
+func (r *serviceKitRepository) GetByCode(ctx context.Context, kitCode string) (*domain.ServiceKit, error) {
+	var kit domain.ServiceKit
+	query := `SELECT ` + serviceKitColumns + ` FROM service_kits WHERE kit_code = $1 AND deleted_at IS NULL`
+
+	err := r.db.GetContext(ctx, &kit, query, kitCode)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get service kit by code: %w", err)
+	}
+
+	return &kit, nil
+}
+
+// List returns kits by name. activeOnly hides kits that are switched off.
+func (r *serviceKitRepository) List(ctx context.Context, activeOnly bool, offset, limit int) ([]*domain.ServiceKit, error) {
+	var kits []*domain.ServiceKit
+	query := `
+		SELECT ` + serviceKitColumns + `
+		FROM service_kits
+		WHERE deleted_at IS NULL AND (NOT $1 OR is_active)
+		ORDER BY name
+		LIMIT $2 OFFSET $3
+	`
+
+	err := r.db.SelectContext(ctx, &kits, query, activeOnly, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service kits: %w", err)
+	}
+
+	return kits, nil
+}
+
+func (r *serviceKitRepository) Count(ctx context.Context, activeOnly bool) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM service_kits WHERE deleted_at IS NULL AND (NOT $1 OR is_active)`
+
+	err := r.db.QueryRowContext(ctx, query, activeOnly).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count service kits: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *serviceKitRepository) ListItems(ctx context.Context, kitID int) ([]*domain.ServiceKitItem, error) {
+	var items []*domain.ServiceKitItem
+	query := `
+		SELECT ski.id, ski.service_kit_id, ski.spare_part_id, ski.quantity,
+			   -- Spare part details
+			   sp.id as "spare_part.id", sp.part_code as "spare_part.part_code",
+			   sp.name as "spare_part.name", sp.unit as "spare_part.unit",
+			   sp.selling_price as "spare_part.selling_price", sp.stock_quantity as "spare_part.stock_quantity"
+		FROM service_kit_items ski
+		JOIN spare_parts sp ON ski.spare_part_id = sp.id
+		WHERE ski.service_kit_id = $1
+		ORDER BY ski.id
+	`
+
+	err := r.db.SelectContext(ctx, &items, query, kitID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service kit items: %w", err)
+	}
+
+	return items, nil
+}
+
+// Update saves the kit details and replaces its items
+func (r *serviceKitRepository) Update(ctx context.Context, kit *domain.ServiceKit) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE service_kits SET
+			name = $2, description = $3, labor_hours = $4, labor_price = $5, is_active = $6,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	_, err = tx.ExecContext(ctx, query, kit.ID, kit.Name, kit.Description, kit.LaborHours, kit.LaborPrice, kit.IsActive)
+	if err != nil {
+		return fmt.Errorf("failed to update service kit: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM service_kit_items WHERE service_kit_id = $1`, kit.ID); err != nil {
+		return fmt.Errorf("failed to clear service kit items: %w", err)
+	}
+
+	if err := insertServiceKitItems(ctx, tx, kit); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit service kit: %w", err)
+	}
+
+	return nil
+}
+
+func (r *serviceKitRepository) SoftDelete(ctx context.Context, id int, deletedBy int) error {
+	query := `
+		UPDATE service_kits SET
+			deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, deletedBy)
+	if err != nil {
+		return fmt.Errorf("failed to delete service kit: %w", err)
+	}
+
+	return nil
+}
+
+func (r *serviceKitRepository) GenerateKitCode(ctx context.Context) (string, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM service_kits`
+
+	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	if err != nil {
+		return "", fmt.Errorf("failed to count service kits for code generation: %w", err)
+	}
+
+	return fmt.Sprintf("KIT-%04d", count+1), nil
+}
+
+func insertServiceKitItems(ctx context.Context, tx *sqlx.Tx, kit *domain.ServiceKit) error {
+	for _, item := range kit.Items {
+		item.ServiceKitID = kit.ID
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO service_kit_items (service_kit_id, spare_part_id, quantity)
+			VALUES ($1, $2, $3)
+			RETURNING id
+		`, item.ServiceKitID, item.SparePartID, item.Quantity).Scan(&item.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create service kit item: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,329 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type sparePartBatchRepository struct {
+	db *sqlx.DB
+}
+
+// NewSparePartBatchRepository creates a new spare part batch repository
+func NewSparePartBatchRepository(db *sqlx.DB) SparePartBatchRepository {
+	return &sparePartBatchRepository{db: db}
+}
+
+const sparePartBatchColumns = `
+	spb.id, spb.spare_part_id, spb.location_id, spb.batch_number, spb.expiry_date, spb.received_date,
+	spb.quantity_received, spb.quantity_remaining, spb.reference_type, spb.reference_id,
+	spb.created_at, spb.updated_at,
+	-- Spare part details
+	sp.id as "spare_part.id", sp.part_code as "spare_part.part_code",
+	sp.name as "spare_part.name", sp.unit as "spare_part.unit",
+	-- Location details
+	sl.id as "location.id", sl.code as "location.code", sl.name as "location.name",
+	sl.location_type as "location.location_type"
+`
+
+const sparePartBatchJoins = `
+	FROM spare_part_batches spb
+	JOIN spare_parts sp ON spb.spare_part_id = sp.id
+	JOIN stock_locations sl ON spb.location_id = sl.id
+`
+
+// fefoOrder picks the batch that expires first, batches without expiry last
+const fefoOrder = `spb.expiry_date NULLS LAST, spb.received_date, spb.id`
+
+// ListBySparePart returns a part's batches in picking order. A nil location
+// lists every location; openOnly hides batches that are used up.
+func (r *sparePartBatchRepository) ListBySparePart(ctx context.Context, sparePartID int, locationID *int, openOnly bool) ([]*domain.SparePartBatch, error) {
+	var batches []*domain.SparePartBatch
+	query := `SELECT ` + sparePartBatchColumns + sparePartBatchJoins + `
+		WHERE spb.spare_part_id = $1
+		  AND ($2::int IS NULL OR spb.location_id = $2)
+		  AND (NOT $3 OR spb.quantity_remaining > 0)
+		ORDER BY ` + fefoOrder
+
+	err := r.db.SelectContext(ctx, &batches, query, sparePartID, locationID, openOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list spare part batches: %w", err)
+	}
+
+	return batches, nil
+}
+
+// ListExpiring returns open batches of batch-tracked parts that expire before
+// the given day, already expired ones first. A nil location lists every
+// location.
+func (r *sparePartBatchRepository) ListExpiring(ctx context.Context, before time.Time, locationID *int) ([]*domain.SparePartBatch, error) {
+	var batches []*domain.SparePartBatch
+	query := `SELECT ` + sparePartBatchColumns + sparePartBatchJoins + `
+		WHERE spb.quantity_remaining > 0 AND spb.expiry_date < $1
+		  AND ($2::int IS NULL OR spb.location_id = $2)
+		  AND sp.track_batches AND sp.deleted_at IS NULL AND sl.deleted_at IS NULL
+		ORDER BY spb.expiry_date, sl.code, sp.part_code
+	`
+
+	err := r.db.SelectContext(ctx, &batches, query, before, locationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expiring batches: %w", err)
+	}
+
+	return batches, nil
+}
+
+func (r *sparePartBatchRepository) ListByWorkOrderPart(ctx context.Context, workOrderPartID int) ([]*domain.WorkOrderPartBatch, error) {
+	var batches []*domain.WorkOrderPartBatch
+	query := `
+		SELECT wopb.id, wopb.work_order_part_id, wopb.batch_id, wopb.quantity, wopb.quantity_returned,
+			   spb.batch_number, spb.expiry_date
+		FROM work_order_part_batches wopb
+		JOIN spare_part_batches spb ON wopb.batch_id = spb.id
+		WHERE wopb.work_order_part_id = $1
+		ORDER BY wopb.id
+	`
+
+	err := r.db.SelectContext(ctx, &batches, query, workOrderPartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list work order part batches: %w", err)
+	}
+
+	return batches, nil
+}
+
+// isBatchTracked reports whether a part's stock is kept per batch
+func isBatchTracked(ctx context.Context, tx *sqlx.Tx, sparePartID int) (bool, error) {
+	var tracked bool
+	err := tx.QueryRowContext(ctx, `SELECT track_batches FROM spare_parts WHERE id = $1`, sparePartID).Scan(&tracked)
+	if err != nil {
+		return false, fmt.Errorf("failed to get spare part tracking: %w", err)
+	}
+
+	return tracked, nil
+}
+
+// addStockBatch records stock received into a batch inside the caller's
+// transaction. Parts not tracked by batch are skipped.
+func addStockBatch(ctx context.Context, tx *sqlx.Tx, batch *domain.SparePartBatch) error {
+	tracked, err := isBatchTracked(ctx, tx, batch.SparePartID)
+	if err != nil || !tracked {
+		return err
+	}
+
+	batch.QuantityRemaining = batch.QuantityReceived
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO spare_part_batches (
+			spare_part_id, location_id, batch_number, expiry_date, received_date,
+			quantity_received, quantity_remaining, reference_type, reference_id
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`,
+		batch.SparePartID, batch.LocationID, batch.BatchNumber, batch.ExpiryDate, batch.ReceivedDate,
+		batch.QuantityReceived, batch.ReferenceType, batch.ReferenceID,
+	).Scan(&batch.ID, &batch.CreatedAt, &batch.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create spare part batch: %w", err)
+	}
+
+	return nil
+}
+
+// takeStockBatches takes quantity of a batch-tracked part out of its batches
+// at one location inside the caller's transaction, first expiry first, and
+// returns what was taken from each batch. Expired batches are only taken when
+// includeExpired is set, for write-offs. Parts not tracked by batch return
+// nothing.
+func takeStockBatches(ctx context.Context, tx *sqlx.Tx, sparePartID, locationID, quantity int, includeExpired bool) ([]*domain.WorkOrderPartBatch, error) {
+	tracked, err := isBatchTracked(ctx, tx, sparePartID)
+	if err != nil || !tracked {
+		return nil, err
+	}
+
+	var batches []*domain.SparePartBatch
+	query := `
+		SELECT spb.id, spb.batch_number, spb.expiry_date, spb.quantity_remaining
+		FROM spare_part_batches spb
+		WHERE spb.spare_part_id = $1 AND spb.location_id = $2 AND spb.quantity_remaining > 0
+		  AND ($3 OR spb.expiry_date IS NULL OR spb.expiry_date >= CURRENT_DATE)
+		ORDER BY ` + fefoOrder + `
+		FOR UPDATE
+	`
+	if err := tx.SelectContext(ctx, &batches, query, sparePartID, locationID, includeExpired); err != nil {
+		return nil, fmt.Errorf("failed to lock spare part batches: %w", err)
+	}
+
+	var taken []*domain.WorkOrderPartBatch
+	remaining := quantity
+
+	for _, batch := range batches {
+		if remaining == 0 {
+			break
+		}
+
+		take := batch.QuantityRemaining
+		if take > remaining {
+			take = remaining
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			UPDATE spare_part_batches SET quantity_remaining = quantity_remaining - $2
+			WHERE id = $1
+		`, batch.ID, take)
+		if err != nil {
+			return nil, fmt.Errorf("failed to take from spare part batch: %w", err)
+		}
+
+		taken = append(taken, &domain.WorkOrderPartBatch{
+			BatchID:     batch.ID,
+			Quantity:    take,
+			BatchNumber: batch.BatchNumber,
+			ExpiryDate:  batch.ExpiryDate,
+		})
+		remaining -= take
+	}
+
+	if remaining > 0 {
+		if includeExpired {
+			return nil, fmt.Errorf("insufficient batch stock for spare part %d: available %d, requested %d", sparePartID, quantity-remaining, quantity)
+		}
+		return nil, fmt.Errorf("insufficient unexpired stock for spare part %d: available %d, requested %d", sparePartID, quantity-remaining, quantity)
+	}
+
+	return taken, nil
+}
+
+// postBatchAdjustment books a stock adjustment of a batch-tracked part inside
+// the caller's transaction. Losses are taken first expiry first, expired
+// batches included; gains become a new batch without expiry named after the
+// adjustment. Parts not tracked by batch are skipped.
+func postBatchAdjustment(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	sparePartID, locationID, quantity int,
+	batchNumber string,
+	referenceType domain.ReferenceType,
+	referenceID *int,
+	adjustedAt time.Time,
+) error {
+	if quantity < 0 {
+		_, err := takeStockBatches(ctx, tx, sparePartID, locationID, -quantity, true)
+		return err
+	}
+	if quantity == 0 {
+		return nil
+	}
+
+	return addStockBatch(ctx, tx, &domain.SparePartBatch{
+		SparePartID:      sparePartID,
+		LocationID:       locationID,
+		BatchNumber:      batchNumber,
+		ReceivedDate:     adjustedAt,
+		QuantityReceived: quantity,
+		ReferenceType:    referenceType,
+		ReferenceID:      referenceID,
+	})
+}
+
+// moveStockBatches moves quantity of a batch-tracked part between locations
+// inside the caller's transaction, first expiry first. The moved stock keeps
+// its batch number, expiry and received date. Parts not tracked by batch are
+// skipped.
+func moveStockBatches(ctx context.Context, tx *sqlx.Tx, sparePartID, fromLocationID, toLocationID, quantity int) error {
+	taken, err := takeStockBatches(ctx, tx, sparePartID, fromLocationID, quantity, true)
+	if err != nil {
+		return err
+	}
+
+	for _, batch := range taken {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO spare_part_batches (
+				spare_part_id, location_id, batch_number, expiry_date, received_date,
+				quantity_received, quantity_remaining, reference_type, reference_id
+			)
+			SELECT spare_part_id, $2, batch_number, expiry_date, received_date,
+				   $3, $3, reference_type, reference_id
+			FROM spare_part_batches
+			WHERE id = $1
+		`, batch.BatchID, toLocationID, batch.Quantity)
+		if err != nil {
+			return fmt.Errorf("failed to move spare part batch: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// recordWorkOrderPartBatches links the batches a work order part was taken
+// from to the usage line
+func recordWorkOrderPartBatches(ctx context.Context, tx *sqlx.Tx, workOrderPartID int, batches []*domain.WorkOrderPartBatch) error {
+	for _, batch := range batches {
+		batch.WorkOrderPartID = workOrderPartID
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO work_order_part_batches (work_order_part_id, batch_id, quantity)
+			VALUES ($1, $2, $3)
+			RETURNING id
+		`, batch.WorkOrderPartID, batch.BatchID, batch.Quantity).Scan(&batch.ID)
+		if err != nil {
+			return fmt.Errorf("failed to record work order part batch: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// returnWorkOrderPartBatches puts returned quantity of a work order part back
+// into the batches it was taken from, latest expiry first so the stock that
+// expires soonest stays with the customer's job. Quantity issued before the
+// part was batch-tracked comes back as a new batch without expiry.
+func returnWorkOrderPartBatches(ctx context.Context, tx *sqlx.Tx, partReturn *domain.WorkOrderPartReturn, locationID int) error {
+	workOrderPartID := partReturn.WorkOrderPartID
+	quantity := partReturn.Quantity
+
+	var batches []*domain.WorkOrderPartBatch
+	err := tx.SelectContext(ctx, &batches, `
+		SELECT wopb.id, wopb.work_order_part_id, wopb.batch_id, wopb.quantity, wopb.quantity_returned,
+			   spb.batch_number, spb.expiry_date
+		FROM work_order_part_batches wopb
+		JOIN spare_part_batches spb ON wopb.batch_id = spb.id
+		WHERE wopb.work_order_part_id = $1 AND wopb.quantity_returned < wopb.quantity
+		ORDER BY spb.expiry_date DESC NULLS FIRST, wopb.id DESC
+		FOR UPDATE OF wopb
+	`, workOrderPartID)
+	if err != nil {
+		return fmt.Errorf("failed to lock work order part batches: %w", err)
+	}
+
+	remaining := quantity
+	for _, batch := range batches {
+		if remaining == 0 {
+			break
+		}
+
+		give := batch.Quantity - batch.QuantityReturned
+		if give > remaining {
+			give = remaining
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE work_order_part_batches SET quantity_returned = quantity_returned + $2 WHERE id = $1
+		`, batch.ID, give); err != nil {
+			return fmt.Errorf("failed to update work order part batch: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE spare_part_batches SET quantity_remaining = quantity_remaining + $2 WHERE id = $1
+		`, batch.BatchID, give); err != nil {
+			return fmt.Errorf("failed to return to spare part batch: %w", err)
+		}
+
+		remaining -= give
+	}
+
+	return postBatchAdjustment(ctx, tx, partReturn.SparePartID, locationID, remaining,
+		fmt.Sprintf("RETURN-%d", workOrderPartID), domain.ReferenceTypeWorkOrder, &partReturn.WorkOrderID, partReturn.ReturnedAt)
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type sparePartFitmentRepository struct {
+	db *sqlx.DB
+}
+
+// NewSparePartFitmentRepository creates a new spare part fitment repository
+func NewSparePartFitmentRepository(db *sqlx.DB) SparePartFitmentRepository {
+	return &sparePartFitmentRepository{db: db}
+}
+
+const sparePartFitmentColumns = `
+	id, spare_part_id, brand, model, year_from, year_to, engine_code, notes, created_by,
+	deleted_at, deleted_by, created_at, updated_at
+`
+
+// compatiblePartsWhere matches parts with at least one fitment covering the
+// vehicle. Brand and model compare case-insensitively; a vehicle without a
+// year or engine number is not filtered on them. Engine numbers are passed
+// normalized (see domain.NormalizeEngineCode).
+const compatiblePartsWhere = `
+	WHERE sp.deleted_at IS NULL
+	  AND (NOT $5 OR sp.stock_quantity > 0)
+	  AND ($6 = '' OR sp.part_code ILIKE '%' || $6 || '%' OR sp.name ILIKE '%' || $6 || '%' OR sp.brand ILIKE '%' || $6 || '%')
+	  AND EXISTS (
+		SELECT 1 FROM spare_part_fitments f
+		WHERE f.spare_part_id = sp.id AND f.deleted_at IS NULL
+		  AND ($7 OR f.brand IS NOT NULL)
+		  AND (f.brand IS NULL OR LOWER(TRIM(f.brand)) = LOWER(TRIM($1)))
+		  AND (f.model IS NULL OR LOWER(TRIM(f.model)) = LOWER(TRIM($2)))
+		  AND ($3::int IS NULL OR ((f.year_from IS NULL OR f.year_from <= $3) AND (f.year_to IS NULL OR f.year_to >= $3)))
+		  AND (f.engine_code IS NULL OR $4::text IS NULL
+			OR $4 LIKE REPLACE(REPLACE(UPPER(f.engine_code), ' ', ''), '-', '') || '%')
+	  )
+`
+
+func compatiblePartsArgs(filter *domain.CompatiblePartFilter) []interface{} {
+	var engineNumber *string
+	if filter.Vehicle.EngineNumber != nil && *filter.Vehicle.EngineNumber != "" {
+		normalized := domain.NormalizeEngineCode(*filter.Vehicle.EngineNumber)
+		engineNumber = &normalized
+	}
+
+	return []interface{}{
+		filter.Vehicle.Brand, filter.Vehicle.Model, filter.Vehicle.Year, engineNumber,
+		filter.InStockOnly, filter.Search, filter.IncludeUniversal,
+	}
+}
+
+func (r *sparePartFitmentRepository) Create(ctx context.Context, fitment *domain.SparePartFitment) error {
+	query := `
+		INSERT INTO spare_part_fitments (spare_part_id, brand, model, year_from, year_to, engine_code, notes, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		fitment.SparePartID, fitment.Brand, fitment.Model, fitment.YearFrom, fitment.YearTo,
+		fitment.EngineCode, fitment.Notes, fitment.CreatedBy,
+	).Scan(&fitment.ID, &fitment.CreatedAt, &fitment.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create spare part fitment: %w", err)
+	}
+
+	return nil
+}
+
+func (r *sparePartFitmentRepository) GetByID(ctx context.Context, id int) (*domain.SparePartFitment, error) {
+	var fitment domain.SparePartFitment
+	query := `SELECT ` + sparePartFitmentColumns + ` FROM spare_part_fitments WHERE id = $1 AND deleted_at IS NULL`
+
+	err := r.db.GetContext(ctx, &fitment, query, id)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get spare part fitment: %w", err)
+	}
+
+	return &fitment, nil
+}
+
+func (r *sparePartFitmentRepository) ListBySparePart(ctx context.Context, sparePartID int) ([]*domain.SparePartFitment, error) {
+	var fitments []*domain.SparePartFitment
+	query := `
+		SELECT ` + sparePartFitmentColumns + `
+		FROM spare_part_fitments
+		WHERE spare_part_id = $1 AND deleted_at IS NULL
+		ORDER BY brand NULLS FIRST, model NULLS FIRST, year_from NULLS FIRST, id
+	`
+
+	err := r.db.SelectContext(ctx, &fitments, query, sparePartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list spare part fitments: %w", err)
+	}
+
+	return fitments, nil
+}
+
+func (r *sparePartFitmentRepository) Update(ctx context.Context, fitment *domain.SparePartFitment) error {
+	query := `
+		UPDATE spare_part_fitments SET
+			brand = $2, model = $3, year_from = $4, year_to = $5, engine_code = $6, notes = $7,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		fitment.ID, fitment.Brand, fitment.Model, fitment.YearFrom, fitment.YearTo, fitment.EngineCode, fitment.Notes,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update spare part fitment: %w", err)
+	}
+
+	return nil
+}
+
+func (r *sparePartFitmentRepository) SoftDelete(ctx context.Context, id int, deletedBy int) error {
+	query := `
+		UPDATE spare_part_fitments SET
+			deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, deletedBy)
+	if err != nil {
+		return fmt.Errorf("failed to delete spare part fitment: %w", err)
+	}
+
+	return nil
+}
+
+func (r *sparePartFitmentRepository) ListCompatibleParts(ctx context.Context, filter *domain.CompatiblePartFilter, offset, limit int) ([]*domain.SparePart, error) {
+	var spareParts []*domain.SparePart
+	query := `
+		SELECT sp.id, sp.part_code, sp.barcode, sp.name, sp.brand, sp.category, sp.description,
+			   sp.cost_price, sp.selling_price, sp.stock_quantity, sp.min_stock_level, sp.unit,
+			   sp.deleted_at, sp.deleted_by, sp.created_at, sp.updated_at
+		FROM spare_parts sp
+	` + compatiblePartsWhere + `
+		ORDER BY sp.name, sp.part_code
+		LIMIT $8 OFFSET $9
+	`
+
+	args := append(compatiblePartsArgs(filter), limit, offset)
+	err := r.db.SelectContext(ctx, &spareParts, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list compatible spare parts: %w", err)
+	}
+
+	return spareParts, nil
+}
+
+func (r *sparePartFitmentRepository) CountCompatibleParts(ctx context.Context, filter *domain.CompatiblePartFilter) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM spare_parts sp ` + compatiblePartsWhere
+
+	err := r.db.GetContext(ctx, &count, query, compatiblePartsArgs(filter)...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count compatible spare parts: %w", err)
+	}
+
+	return count, nil
+}
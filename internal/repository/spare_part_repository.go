@@ -18,7 +18,15 @@ func NewSparePartRepository(db *sqlx.DB) SparePartRepository {
 	return &sparePartRepository{db: db}
 }
 
-func (r *sparePartRepository) Create(ctx context.Context, sparePart *domain.SparePart) error {
+// Create inserts the part with its opening stock at the default location. The
+// opening stock becomes the first cost layer and an `in` stock movement.
+func (r *sparePartRepository) Create(ctx context.Context, sparePart *domain.SparePart, createdBy int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	
 	query := `
 		INSERT INTO spare_parts (
 			part_code, barcode, name, brand, category, description,
@@ -28,7 +36,7 @@ func (r *sparePartRepository) Create(ctx context.Context, sparePart *domain.Spar
 		RETURNING id, created_at, updated_at
 	`
 	
-	err := r.db.QueryRowContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		sparePart.PartCode, sparePart.Barcode, sparePart.Name, sparePart.Brand,
 		sparePart.Category, sparePart.Description, sparePart.CostPrice,
 		sparePart.SellingPrice, sparePart.StockQuantity, sparePart.MinStockLevel,
@@ -39,6 +47,43 @@ func (r *sparePartRepository) Create(ctx context.Context, sparePart *domain.Spar
 		return fmt.Errorf("failed to create spare part: %w", err)
 	}
 	
+	locationID, err := adjustLocationStock(ctx, tx, sparePart.ID, nil, sparePart.StockQuantity)
+	if err != nil {
+		return err
+	}
+	
+	if sparePart.StockQuantity > 0 {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO stock_cost_layers (
+				spare_part_id, reference_type, received_date,
+				quantity_received, quantity_remaining, unit_cost
+			)
+			VALUES ($1, $2, $3, $4, $4, $5)
+		`, sparePart.ID, domain.ReferenceTypeAdjustment, sparePart.CreatedAt, sparePart.StockQuantity, sparePart.CostPrice)
+		if err != nil {
+			return fmt.Errorf("failed to create stock cost layer: %w", err)
+		}
+	
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO stock_movements (
+				spare_part_id, movement_type, quantity, reference_type, location_id,
+				notes, created_by, movement_date, unit_cost, total_value
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, ROUND($9::numeric, 2), ROUND($10::numeric, 2))
+		`,
+			sparePart.ID, domain.MovementTypeIn, sparePart.StockQuantity, domain.ReferenceTypeAdjustment, locationID,
+			"Opening stock", createdBy, sparePart.CreatedAt, sparePart.CostPrice,
+			float64(sparePart.StockQuantity)*sparePart.CostPrice,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create stock movement: %w", err)
+		}
+	}
+	
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit spare part: %w", err)
+	}
+	
 	return nil
 }
 
@@ -46,7 +91,7 @@ func (r *sparePartRepository) GetByID(ctx context.Context, id int) (*domain.Spar
 	var sparePart domain.SparePart
 	query := `
 		SELECT id, part_code, barcode, name, brand, category, description,
-			   cost_price, selling_price, stock_quantity, min_stock_level, unit,
+			   cost_price, selling_price, stock_quantity, min_stock_level, unit, track_batches, track_serials,
 			   deleted_at, deleted_by, created_at, updated_at
 		FROM spare_parts
 		WHERE id = $1 AND deleted_at IS NULL
@@ -64,7 +109,7 @@ func (r *sparePartRepository) GetByPartCode(ctx context.Context, partCode string
 	var sparePart domain.SparePart
 	query := `
 		SELECT id, part_code, barcode, name, brand, category, description,
-			   cost_price, selling_price, stock_quantity, min_stock_level, unit,
+			   cost_price, selling_price, stock_quantity, min_stock_level, unit, track_batches, track_serials,
 			   deleted_at, deleted_by, created_at, updated_at
 		FROM spare_parts
 		WHERE part_code = $1 AND deleted_at IS NULL
@@ -82,7 +127,7 @@ func (r *sparePartRepository) GetByBarcode(ctx context.Context, barcode string)
 	var sparePart domain.SparePart
 	query := `
 		SELECT id, part_code, barcode, name, brand, category, description,
-			   cost_price, selling_price, stock_quantity, min_stock_level, unit,
+			   cost_price, selling_price, stock_quantity, min_stock_level, unit, track_batches, track_serials,
 			   deleted_at, deleted_by, created_at, updated_at
 		FROM spare_parts
 		WHERE barcode = $1 AND deleted_at IS NULL
@@ -100,7 +145,7 @@ func (r *sparePartRepository) List(ctx context.Context, offset, limit int) ([]*d
 	var spareParts []*domain.SparePart
 	query := `
 		SELECT id, part_code, barcode, name, brand, category, description,
-			   cost_price, selling_price, stock_quantity, min_stock_level, unit,
+			   cost_price, selling_price, stock_quantity, min_stock_level, unit, track_batches, track_serials,
 			   deleted_at, deleted_by, created_at, updated_at
 		FROM spare_parts
 		WHERE deleted_at IS NULL
@@ -120,7 +165,7 @@ func (r *sparePartRepository) ListLowStock(ctx context.Context, offset, limit in
 	var spareParts []*domain.SparePart
 	query := `
 		SELECT id, part_code, barcode, name, brand, category, description,
-			   cost_price, selling_price, stock_quantity, min_stock_level, unit,
+			   cost_price, selling_price, stock_quantity, min_stock_level, unit, track_batches, track_serials,
 			   deleted_at, deleted_by, created_at, updated_at
 		FROM spare_parts
 		WHERE deleted_at IS NULL AND stock_quantity <= min_stock_level
@@ -140,14 +185,14 @@ func (r *sparePartRepository) Update(ctx context.Context, sparePart *domain.Spar
 	query := `
 		UPDATE spare_parts SET
 			barcode = $2, name = $3, brand = $4, category = $5, description = $6,
-			cost_price = $7, selling_price = $8, min_stock_level = $9, unit = $10,
+			selling_price = $7, min_stock_level = $8, unit = $9,
 			updated_at = CURRENT_TIMESTAMP
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 	
 	_, err := r.db.ExecContext(ctx, query,
 		sparePart.ID, sparePart.Barcode, sparePart.Name, sparePart.Brand,
-		sparePart.Category, sparePart.Description, sparePart.CostPrice,
+		sparePart.Category, sparePart.Description,
 		sparePart.SellingPrice, sparePart.MinStockLevel, sparePart.Unit,
 	)
 	
@@ -201,7 +246,7 @@ func (r *sparePartRepository) Search(ctx context.Context, query string, offset,
 	var spareParts []*domain.SparePart
 	searchQuery := `
 		SELECT id, part_code, barcode, name, brand, category, description,
-			   cost_price, selling_price, stock_quantity, min_stock_level, unit,
+			   cost_price, selling_price, stock_quantity, min_stock_level, unit, track_batches, track_serials,
 			   deleted_at, deleted_by, created_at, updated_at
 		FROM spare_parts
 		WHERE deleted_at IS NULL AND (
@@ -237,32 +282,275 @@ func (r *sparePartRepository) GeneratePartCode(ctx context.Context) (string, err
 	return partCode, nil
 }
 
-func (r *sparePartRepository) UpdateStock(ctx context.Context, id int, quantity int) error {
+// AdjustStock posts a manual stock correction at one location (nil = default
+// location) in one transaction: the location and total stock, batches, cost
+// layers, the FIFO cost price and an adjustment stock movement. Gains enter at
+// the part's cost price; losses are costed from the layers they consume under
+// FIFO.
+func (r *sparePartRepository) AdjustStock(ctx context.Context, adjustment *domain.StockAdjustment, costingMethod domain.CostingMethod) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var stockQuantity int
+	var costPrice float64
+	err = tx.QueryRowContext(ctx, `
+		SELECT stock_quantity, cost_price FROM spare_parts WHERE id = $1 AND deleted_at IS NULL FOR UPDATE
+	`, adjustment.SparePartID).Scan(&stockQuantity, &costPrice)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return fmt.Errorf("spare part not found")
+		}
+		return fmt.Errorf("failed to lock spare part: %w", err)
+	}
+	if stockQuantity+adjustment.Quantity < 0 {
+		return fmt.Errorf("adjustment would result in negative stock (current: %d, adjustment: %d)", stockQuantity, adjustment.Quantity)
+	}
+
+	locationID, err := adjustLocationStock(ctx, tx, adjustment.SparePartID, adjustment.LocationID, adjustment.Quantity)
+	if err != nil {
+		return err
+	}
+	adjustment.LocationID = &locationID
+
+	batchNumber := "ADJ-" + adjustment.AdjustedAt.Format("20060102")
+	err = postBatchAdjustment(ctx, tx, adjustment.SparePartID, locationID, adjustment.Quantity,
+		batchNumber, domain.ReferenceTypeAdjustment, nil, adjustment.AdjustedAt)
+	if err != nil {
+		return err
+	}
+
+	// Serial-tracked parts name the units found or written off
+	if adjustment.Quantity > 0 {
+		err = receiveSerials(ctx, tx, adjustment.SparePartID, locationID, adjustment.Quantity,
+			adjustment.SerialNumbers, domain.ReferenceTypeAdjustment, nil, adjustment.AdjustedAt)
+	} else if adjustment.Quantity < 0 {
+		err = writeOffSerials(ctx, tx, adjustment.SparePartID, locationID, -adjustment.Quantity, adjustment.SerialNumbers)
+	}
+	if err != nil {
+		return err
+	}
+
+	movementType := domain.MovementTypeIn
+	quantity := adjustment.Quantity
+	unitCost := costPrice
+	totalValue := float64(quantity) * unitCost
+
+	if quantity > 0 {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO stock_cost_layers (
+				spare_part_id, reference_type, received_date,
+				quantity_received, quantity_remaining, unit_cost
+			)
+			VALUES ($1, $2, $3, $4, $4, $5)
+		`, adjustment.SparePartID, domain.ReferenceTypeAdjustment, adjustment.AdjustedAt, quantity, unitCost)
+		if err != nil {
+			return fmt.Errorf("failed to create stock cost layer: %w", err)
+		}
+	} else if quantity < 0 {
+		movementType = domain.MovementTypeOut
+		quantity = -quantity
+
+		layerCost, covered, err := consumeCostLayers(ctx, tx, adjustment.SparePartID, quantity)
+		if err != nil {
+			return err
+		}
+
+		totalValue = float64(quantity) * unitCost
+		if costingMethod == domain.CostingMethodFIFO {
+			totalValue = layerCost + float64(quantity-covered)*costPrice
+			unitCost = totalValue / float64(quantity)
+		}
+	}
+
+	stockQuery := `
+		UPDATE spare_parts SET
+			stock_quantity = stock_quantity + $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+	if costingMethod == domain.CostingMethodFIFO {
+		// Cost price = value of open layers per unit
+		stockQuery = `
+			UPDATE spare_parts SET
+				stock_quantity = stock_quantity + $2,
+				cost_price = COALESCE((
+					SELECT ROUND(SUM(quantity_remaining * unit_cost) / NULLIF(SUM(quantity_remaining), 0), 2)
+					FROM stock_cost_layers
+					WHERE spare_part_id = $1 AND quantity_remaining > 0
+				), cost_price),
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+		`
+	}
+
+	if _, err := tx.ExecContext(ctx, stockQuery, adjustment.SparePartID, adjustment.Quantity); err != nil {
+		return fmt.Errorf("failed to adjust spare part stock: %w", err)
+	}
+
+	if quantity != 0 {
+		var notes *string
+		if adjustment.Notes != "" {
+			notes = &adjustment.Notes
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO stock_movements (
+				spare_part_id, movement_type, quantity, reference_type, location_id,
+				notes, created_by, movement_date, unit_cost, total_value
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, ROUND($9::numeric, 2), ROUND($10::numeric, 2))
+		`,
+			adjustment.SparePartID, movementType, quantity, domain.ReferenceTypeAdjustment, locationID,
+			notes, adjustment.AdjustedBy, adjustment.AdjustedAt, unitCost, totalValue,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create stock movement: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit stock adjustment: %w", err)
+	}
+
+	return nil
+}
+
+func (r *sparePartRepository) UpdateCostPrice(ctx context.Context, id int, costPrice float64) error {
 	query := `
 		UPDATE spare_parts SET 
-			stock_quantity = $2, updated_at = CURRENT_TIMESTAMP
+			cost_price = $2, updated_at = CURRENT_TIMESTAMP
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 	
-	_, err := r.db.ExecContext(ctx, query, id, quantity)
+	_, err := r.db.ExecContext(ctx, query, id, costPrice)
 	if err != nil {
-		return fmt.Errorf("failed to update spare part stock: %w", err)
+		return fmt.Errorf("failed to update spare part cost price: %w", err)
 	}
 	
 	return nil
 }
 
-func (r *sparePartRepository) AdjustStock(ctx context.Context, id int, adjustment int) error {
+// UpdateTracking switches batch and serial tracking of a part. Switching
+// batch tracking on puts stock already at each location into an opening
+// batch without expiry, so batches always add up to the location stock.
+func (r *sparePartRepository) UpdateTracking(ctx context.Context, id int, trackBatches, trackSerials bool) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var wasTrackingBatches bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT track_batches FROM spare_parts WHERE id = $1 AND deleted_at IS NULL FOR UPDATE
+	`, id).Scan(&wasTrackingBatches)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return fmt.Errorf("spare part not found")
+		}
+		return fmt.Errorf("failed to lock spare part: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE spare_parts SET
+			track_batches = $2, track_serials = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, id, trackBatches, trackSerials)
+	if err != nil {
+		return fmt.Errorf("failed to update spare part tracking: %w", err)
+	}
+
+	if trackBatches && !wasTrackingBatches {
+		// Batches left over from an earlier tracking period no longer match the stock
+		_, err = tx.ExecContext(ctx, `
+			UPDATE spare_part_batches SET quantity_remaining = 0
+			WHERE spare_part_id = $1 AND quantity_remaining > 0
+		`, id)
+		if err != nil {
+			return fmt.Errorf("failed to close old spare part batches: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO spare_part_batches (
+				spare_part_id, location_id, batch_number, received_date,
+				quantity_received, quantity_remaining, reference_type
+			)
+			SELECT spare_part_id, location_id, 'OPENING', CURRENT_TIMESTAMP, quantity, quantity, $2
+			FROM spare_part_stocks
+			WHERE spare_part_id = $1 AND quantity > 0
+		`, id, domain.ReferenceTypeAdjustment)
+		if err != nil {
+			return fmt.Errorf("failed to create opening spare part batches: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit spare part tracking: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateBarcode sets the barcode of a part. The partial unique index on
+// barcode rejects a code already used by another part.
+func (r *sparePartRepository) UpdateBarcode(ctx context.Context, id int, barcode string) error {
 	query := `
 		UPDATE spare_parts SET 
-			stock_quantity = stock_quantity + $2, updated_at = CURRENT_TIMESTAMP
+			barcode = $2, updated_at = CURRENT_TIMESTAMP
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 	
-	_, err := r.db.ExecContext(ctx, query, id, adjustment)
+	result, err := r.db.ExecContext(ctx, query, id, barcode)
 	if err != nil {
-		return fmt.Errorf("failed to adjust spare part stock: %w", err)
+		return fmt.Errorf("failed to update spare part barcode: %w", err)
+	}
+	
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update spare part barcode: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("spare part not found")
 	}
 	
 	return nil
-}
\ No newline at end of file
+}
+
+// BarcodeExists reports whether another active part already uses the barcode
+func (r *sparePartRepository) BarcodeExists(ctx context.Context, barcode string, excludeID int) (bool, error) {
+	var exists bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM spare_parts
+			WHERE barcode = $1 AND id <> $2 AND deleted_at IS NULL
+		)
+	`
+	
+	err := r.db.GetContext(ctx, &exists, query, barcode, excludeID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check spare part barcode: %w", err)
+	}
+	
+	return exists, nil
+}
+
+func (r *sparePartRepository) ListWithoutBarcode(ctx context.Context) ([]*domain.SparePart, error) {
+	var spareParts []*domain.SparePart
+	query := `
+		SELECT id, part_code, barcode, name, brand, category, description,
+			   cost_price, selling_price, stock_quantity, min_stock_level, unit, track_batches, track_serials,
+			   deleted_at, deleted_by, created_at, updated_at
+		FROM spare_parts
+		WHERE (barcode IS NULL OR barcode = '') AND deleted_at IS NULL
+		ORDER BY id
+	`
+	
+	err := r.db.SelectContext(ctx, &spareParts, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list spare parts without barcode: %w", err)
+	}
+	
+	return spareParts, nil
+}
@@ -0,0 +1,367 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type sparePartSerialRepository struct {
+	db *sqlx.DB
+}
+
+// NewSparePartSerialRepository creates a new spare part serial repository
+func NewSparePartSerialRepository(db *sqlx.DB) SparePartSerialRepository {
+	return &sparePartSerialRepository{db: db}
+}
+
+const sparePartSerialColumns = `
+	sn.id, sn.spare_part_id, sn.serial_number, sn.status, sn.reference_type, sn.reference_id,
+	sn.received_date, sn.location_id, sn.work_order_part_id, sn.issued_at, sn.created_at, sn.updated_at,
+	-- Work order and vehicle the unit was issued to
+	wo.id as work_order_id, wo.wo_number, wo.vehicle_id, wo.customer_vehicle_id,
+	COALESCE(v.plate_number, cv.plate_number) as plate_number,
+	COALESCE(v.brand, cv.brand) as vehicle_brand,
+	COALESCE(v.model, cv.model) as vehicle_model,
+	-- Spare part details
+	sp.id as "spare_part.id", sp.part_code as "spare_part.part_code",
+	sp.name as "spare_part.name", sp.unit as "spare_part.unit"
+`
+
+const sparePartSerialJoins = `
+	FROM spare_part_serials sn
+	JOIN spare_parts sp ON sn.spare_part_id = sp.id
+	LEFT JOIN work_order_parts wop ON sn.work_order_part_id = wop.id
+	LEFT JOIN work_orders wo ON wop.work_order_id = wo.id
+	LEFT JOIN vehicles v ON wo.vehicle_id = v.id
+	LEFT JOIN customer_vehicles cv ON wo.customer_vehicle_id = cv.id
+`
+
+// ListBySparePart returns a part's serials, optionally of one status
+func (r *sparePartSerialRepository) ListBySparePart(ctx context.Context, sparePartID int, status *domain.SerialStatus, offset, limit int) ([]*domain.SparePartSerial, error) {
+	var serials []*domain.SparePartSerial
+	query := `SELECT ` + sparePartSerialColumns + sparePartSerialJoins + `
+		WHERE sn.spare_part_id = $1 AND ($2::text IS NULL OR sn.status = $2)
+		ORDER BY sn.received_date, sn.serial_number
+		LIMIT $3 OFFSET $4
+	`
+
+	err := r.db.SelectContext(ctx, &serials, query, sparePartID, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list spare part serials: %w", err)
+	}
+
+	return serials, nil
+}
+
+func (r *sparePartSerialRepository) CountBySparePart(ctx context.Context, sparePartID int, status *domain.SerialStatus) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*) FROM spare_part_serials
+		WHERE spare_part_id = $1 AND ($2::text IS NULL OR status = $2)
+	`
+
+	err := r.db.QueryRowContext(ctx, query, sparePartID, status).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count spare part serials: %w", err)
+	}
+
+	return count, nil
+}
+
+// ListBySerialNumber finds a serial number across parts, with the work order
+// and vehicle it was issued to
+func (r *sparePartSerialRepository) ListBySerialNumber(ctx context.Context, serialNumber string) ([]*domain.SparePartSerial, error) {
+	var serials []*domain.SparePartSerial
+	query := `SELECT ` + sparePartSerialColumns + sparePartSerialJoins + `
+		WHERE sn.serial_number = $1
+		ORDER BY sp.part_code
+	`
+
+	err := r.db.SelectContext(ctx, &serials, query, serialNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find spare part serial: %w", err)
+	}
+
+	return serials, nil
+}
+
+func (r *sparePartSerialRepository) ListByWorkOrderPart(ctx context.Context, workOrderPartID int) ([]*domain.SparePartSerial, error) {
+	var serials []*domain.SparePartSerial
+	query := `SELECT ` + sparePartSerialColumns + sparePartSerialJoins + `
+		WHERE sn.work_order_part_id = $1 AND sn.status = $2
+		ORDER BY sn.serial_number
+	`
+
+	err := r.db.SelectContext(ctx, &serials, query, workOrderPartID, domain.SerialStatusIssued)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list work order part serials: %w", err)
+	}
+
+	return serials, nil
+}
+
+// Register records serial numbers of units already in stock at a location
+// (the default location when nil), for parts that had stock before serial
+// tracking was switched on. In-stock serials cannot outnumber the location's
+// stock.
+func (r *sparePartSerialRepository) Register(ctx context.Context, sparePartID int, locationID *int, serialNumbers []string, registeredAt time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var lockedID int
+	err = tx.QueryRowContext(ctx, `
+		SELECT id FROM spare_parts WHERE id = $1 AND deleted_at IS NULL FOR UPDATE
+	`, sparePartID).Scan(&lockedID)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return fmt.Errorf("spare part not found")
+		}
+		return fmt.Errorf("failed to lock spare part: %w", err)
+	}
+
+	resolvedLocationID, err := resolveLocationID(ctx, tx, locationID)
+	if err != nil {
+		return err
+	}
+
+	if err := insertSerials(ctx, tx, sparePartID, resolvedLocationID, serialNumbers, domain.ReferenceTypeAdjustment, nil, registeredAt); err != nil {
+		return err
+	}
+
+	var stockQuantity, inStock int
+	err = tx.QueryRowContext(ctx, `
+		SELECT
+			COALESCE((SELECT quantity FROM spare_part_stocks WHERE spare_part_id = $1 AND location_id = $2), 0),
+			(SELECT COUNT(*) FROM spare_part_serials WHERE spare_part_id = $1 AND location_id = $2 AND status = $3)
+	`, sparePartID, resolvedLocationID, domain.SerialStatusInStock).Scan(&stockQuantity, &inStock)
+	if err != nil {
+		return fmt.Errorf("failed to count spare part serials: %w", err)
+	}
+	if inStock > stockQuantity {
+		return fmt.Errorf("in-stock serial numbers at the location (%d) would exceed its stock (%d)", inStock, stockQuantity)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit spare part serials: %w", err)
+	}
+
+	return nil
+}
+
+// isSerialTracked reports whether a part's units carry serial numbers
+func isSerialTracked(ctx context.Context, tx *sqlx.Tx, sparePartID int) (bool, error) {
+	var tracked bool
+	err := tx.QueryRowContext(ctx, `SELECT track_serials FROM spare_parts WHERE id = $1`, sparePartID).Scan(&tracked)
+	if err != nil {
+		return false, fmt.Errorf("failed to get spare part tracking: %w", err)
+	}
+
+	return tracked, nil
+}
+
+// checkSerialCount requires one serial number per unit for serial-tracked
+// parts and none for other parts
+func checkSerialCount(ctx context.Context, tx *sqlx.Tx, sparePartID, quantity int, serialNumbers []string) (bool, error) {
+	tracked, err := isSerialTracked(ctx, tx, sparePartID)
+	if err != nil {
+		return false, err
+	}
+
+	if !tracked {
+		if len(serialNumbers) > 0 {
+			return false, fmt.Errorf("spare part %d does not track serial numbers", sparePartID)
+		}
+		return false, nil
+	}
+	if len(serialNumbers) != quantity {
+		return false, fmt.Errorf("spare part %d needs %d serial numbers, got %d", sparePartID, quantity, len(serialNumbers))
+	}
+
+	return true, nil
+}
+
+// receiveSerials records the serial numbers of units received into stock at a
+// location inside the caller's transaction
+func receiveSerials(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	sparePartID, locationID, quantity int,
+	serialNumbers []string,
+	referenceType domain.ReferenceType,
+	referenceID *int,
+	receivedAt time.Time,
+) error {
+	tracked, err := checkSerialCount(ctx, tx, sparePartID, quantity, serialNumbers)
+	if err != nil || !tracked {
+		return err
+	}
+
+	return insertSerials(ctx, tx, sparePartID, locationID, serialNumbers, referenceType, referenceID, receivedAt)
+}
+
+// insertSerials puts serial numbers in stock at a location. Serials written
+// off earlier come back into stock; any other known serial is rejected.
+func insertSerials(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	sparePartID, locationID int,
+	serialNumbers []string,
+	referenceType domain.ReferenceType,
+	referenceID *int,
+	receivedAt time.Time,
+) error {
+	var existing []string
+	err := tx.SelectContext(ctx, &existing, `
+		SELECT serial_number FROM spare_part_serials
+		WHERE spare_part_id = $1 AND serial_number = ANY($2) AND status <> $3
+	`, sparePartID, pq.Array(serialNumbers), domain.SerialStatusWrittenOff)
+	if err != nil {
+		return fmt.Errorf("failed to check spare part serials: %w", err)
+	}
+	if len(existing) > 0 {
+		return fmt.Errorf("serial numbers already registered: %s", strings.Join(existing, ", "))
+	}
+
+	for _, serialNumber := range serialNumbers {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO spare_part_serials (spare_part_id, serial_number, status, reference_type, reference_id, received_date, location_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (spare_part_id, serial_number) DO UPDATE SET
+				status = EXCLUDED.status, reference_type = EXCLUDED.reference_type,
+				reference_id = EXCLUDED.reference_id, received_date = EXCLUDED.received_date,
+				location_id = EXCLUDED.location_id
+		`, sparePartID, serialNumber, domain.SerialStatusInStock, referenceType, referenceID, receivedAt, locationID)
+		if err != nil {
+			return fmt.Errorf("failed to create spare part serial: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// issueSerials marks serials in stock at the part's location as issued to a
+// work order part inside the caller's transaction
+func issueSerials(ctx context.Context, tx *sqlx.Tx, part *domain.WorkOrderPart) error {
+	tracked, err := checkSerialCount(ctx, tx, part.SparePartID, part.QuantityUsed, part.SerialNumbers)
+	if err != nil || !tracked {
+		return err
+	}
+
+	var issued []string
+	err = tx.SelectContext(ctx, &issued, `
+		UPDATE spare_part_serials SET
+			status = $4, work_order_part_id = $3, issued_at = $5, location_id = NULL
+		WHERE spare_part_id = $1 AND serial_number = ANY($2) AND status = $6 AND location_id = $7
+		RETURNING serial_number
+	`, part.SparePartID, pq.Array(part.SerialNumbers), part.ID, domain.SerialStatusIssued, part.UsedAt, domain.SerialStatusInStock, part.LocationID)
+	if err != nil {
+		return fmt.Errorf("failed to issue spare part serials: %w", err)
+	}
+
+	if len(issued) != len(part.SerialNumbers) {
+		return fmt.Errorf("serial numbers not in stock at the location: %s", strings.Join(missingSerials(part.SerialNumbers, issued), ", "))
+	}
+
+	return nil
+}
+
+// returnSerials puts serials issued on a work order part back in stock at a
+// location inside the caller's transaction. They stay linked to the work
+// order part as their last use until issued again.
+func returnSerials(ctx context.Context, tx *sqlx.Tx, partReturn *domain.WorkOrderPartReturn, locationID int, serialNumbers []string) error {
+	tracked, err := checkSerialCount(ctx, tx, partReturn.SparePartID, partReturn.Quantity, serialNumbers)
+	if err != nil || !tracked {
+		return err
+	}
+
+	var returned []string
+	err = tx.SelectContext(ctx, &returned, `
+		UPDATE spare_part_serials SET status = $4, location_id = $6
+		WHERE spare_part_id = $1 AND serial_number = ANY($2) AND work_order_part_id = $3 AND status = $5
+		RETURNING serial_number
+	`, partReturn.SparePartID, pq.Array(serialNumbers), partReturn.WorkOrderPartID, domain.SerialStatusInStock, domain.SerialStatusIssued, locationID)
+	if err != nil {
+		return fmt.Errorf("failed to return spare part serials: %w", err)
+	}
+
+	if len(returned) != len(serialNumbers) {
+		return fmt.Errorf("serial numbers not issued on this work order part: %s", strings.Join(missingSerials(serialNumbers, returned), ", "))
+	}
+
+	return nil
+}
+
+// moveSerials moves serials in stock from one location to another inside the
+// caller's transaction
+func moveSerials(ctx context.Context, tx *sqlx.Tx, sparePartID, fromLocationID, toLocationID, quantity int, serialNumbers []string) error {
+	tracked, err := checkSerialCount(ctx, tx, sparePartID, quantity, serialNumbers)
+	if err != nil || !tracked {
+		return err
+	}
+
+	var moved []string
+	err = tx.SelectContext(ctx, &moved, `
+		UPDATE spare_part_serials SET location_id = $4
+		WHERE spare_part_id = $1 AND serial_number = ANY($2) AND location_id = $3 AND status = $5
+		RETURNING serial_number
+	`, sparePartID, pq.Array(serialNumbers), fromLocationID, toLocationID, domain.SerialStatusInStock)
+	if err != nil {
+		return fmt.Errorf("failed to move spare part serials: %w", err)
+	}
+
+	if len(moved) != len(serialNumbers) {
+		return fmt.Errorf("serial numbers not in stock at the location: %s", strings.Join(missingSerials(serialNumbers, moved), ", "))
+	}
+
+	return nil
+}
+
+// writeOffSerials takes serials in stock at a location out of stock inside
+// the caller's transaction, for units lost or damaged
+func writeOffSerials(ctx context.Context, tx *sqlx.Tx, sparePartID, locationID, quantity int, serialNumbers []string) error {
+	tracked, err := checkSerialCount(ctx, tx, sparePartID, quantity, serialNumbers)
+	if err != nil || !tracked {
+		return err
+	}
+
+	var writtenOff []string
+	err = tx.SelectContext(ctx, &writtenOff, `
+		UPDATE spare_part_serials SET status = $4, location_id = NULL
+		WHERE spare_part_id = $1 AND serial_number = ANY($2) AND location_id = $3 AND status = $5
+		RETURNING serial_number
+	`, sparePartID, pq.Array(serialNumbers), locationID, domain.SerialStatusWrittenOff, domain.SerialStatusInStock)
+	if err != nil {
+		return fmt.Errorf("failed to write off spare part serials: %w", err)
+	}
+
+	if len(writtenOff) != len(serialNumbers) {
+		return fmt.Errorf("serial numbers not in stock at the location: %s", strings.Join(missingSerials(serialNumbers, writtenOff), ", "))
+	}
+
+	return nil
+}
+
+func missingSerials(wanted, found []string) []string {
+	seen := make(map[string]bool, len(found))
+	for _, serialNumber := range found {
+		seen[serialNumber] = true
+	}
+
+	var missing []string
+	for _, serialNumber := range wanted {
+		if !seen[serialNumber] {
+			missing = append(missing, serialNumber)
+		}
+	}
+
+	return missing
+}
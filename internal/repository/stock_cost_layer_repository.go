@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type stockCostLayerRepository struct {
+	db *sqlx.DB
+}
+
+// NewStockCostLayerRepository creates a new stock cost layer repository
+func NewStockCostLayerRepository(db *sqlx.DB) StockCostLayerRepository {
+	return &stockCostLayerRepository{db: db}
+}
+
+func (r *stockCostLayerRepository) Create(ctx context.Context, layer *domain.StockCostLayer) error {
+	query := `
+		INSERT INTO stock_cost_layers (
+			spare_part_id, reference_type, reference_id, received_date,
+			quantity_received, quantity_remaining, unit_cost
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		layer.SparePartID, layer.ReferenceType, layer.ReferenceID, layer.ReceivedDate,
+		layer.QuantityReceived, layer.QuantityRemaining, layer.UnitCost,
+	).Scan(&layer.ID, &layer.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create stock cost layer: %w", err)
+	}
+
+	return nil
+}
+
+func (r *stockCostLayerRepository) ListBySparePartID(ctx context.Context, sparePartID int, openOnly bool) ([]*domain.StockCostLayer, error) {
+	var layers []*domain.StockCostLayer
+	query := `
+		SELECT id, spare_part_id, reference_type, reference_id, received_date,
+			   quantity_received, quantity_remaining, unit_cost, created_at
+		FROM stock_cost_layers
+		WHERE spare_part_id = $1 AND (NOT $2 OR quantity_remaining > 0)
+		ORDER BY received_date, id
+	`
+
+	err := r.db.SelectContext(ctx, &layers, query, sparePartID, openOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stock cost layers: %w", err)
+	}
+
+	return layers, nil
+}
+
+// Consume takes quantity out of the oldest open layers first and returns the
+// cost of what was taken plus how much of the quantity the layers covered.
+func (r *stockCostLayerRepository) Consume(ctx context.Context, sparePartID int, quantity int) (float64, int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	totalCost, covered, err := consumeCostLayers(ctx, tx, sparePartID, quantity)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit stock cost layers: %w", err)
+	}
+
+	return totalCost, covered, nil
+}
+
+// consumeCostLayers is Consume inside a caller's transaction, for postings
+// that move stock and cost layers together
+func consumeCostLayers(ctx context.Context, tx *sqlx.Tx, sparePartID int, quantity int) (float64, int, error) {
+	var layers []*domain.StockCostLayer
+	query := `
+		SELECT id, spare_part_id, reference_type, reference_id, received_date,
+			   quantity_received, quantity_remaining, unit_cost, created_at
+		FROM stock_cost_layers
+		WHERE spare_part_id = $1 AND quantity_remaining > 0
+		ORDER BY received_date, id
+		FOR UPDATE
+	`
+
+	if err := tx.SelectContext(ctx, &layers, query, sparePartID); err != nil {
+		return 0, 0, fmt.Errorf("failed to lock stock cost layers: %w", err)
+	}
+
+	var totalCost float64
+	remaining := quantity
+
+	for _, layer := range layers {
+		if remaining == 0 {
+			break
+		}
+
+		take := layer.QuantityRemaining
+		if take > remaining {
+			take = remaining
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			UPDATE stock_cost_layers SET quantity_remaining = quantity_remaining - $2
+			WHERE id = $1
+		`, layer.ID, take)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to consume stock cost layer: %w", err)
+		}
+
+		totalCost += float64(take) * layer.UnitCost
+		remaining -= take
+	}
+
+	return totalCost, quantity - remaining, nil
+}
+
+func (r *stockCostLayerRepository) GetValuation(ctx context.Context, sparePartID int) (int, float64, error) {
+	var quantity int
+	var value float64
+	query := `
+		SELECT COALESCE(SUM(quantity_remaining), 0), COALESCE(SUM(quantity_remaining * unit_cost), 0)
+		FROM stock_cost_layers
+		WHERE spare_part_id = $1 AND quantity_remaining > 0
+	`
+
+	err := r.db.QueryRowContext(ctx, query, sparePartID).Scan(&quantity, &value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get stock cost layer valuation: %w", err)
+	}
+
+	return quantity, value, nil
+}
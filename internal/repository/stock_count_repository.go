@@ -0,0 +1,489 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type stockCountRepository struct {
+	db *sqlx.DB
+}
+
+// NewStockCountRepository creates a new stock count repository
+func NewStockCountRepository(db *sqlx.DB) StockCountRepository {
+	return &stockCountRepository{db: db}
+}
+
+const stockCountColumns = `
+	sc.id, sc.count_number, sc.status, sc.location_id, sc.location, sc.category, sc.notes, sc.created_by,
+	sc.submitted_by, sc.submitted_at, sc.approved_by, sc.approved_at,
+	sc.deleted_at, sc.deleted_by, sc.created_at, sc.updated_at,
+	-- Variance summary at snapshot cost
+	COALESCE(summary.total_items, 0) as total_items,
+	COALESCE(summary.counted_items, 0) as counted_items,
+	COALESCE(summary.variance_items, 0) as variance_items,
+	COALESCE(summary.gain_value, 0) as gain_value,
+	COALESCE(summary.loss_value, 0) as loss_value,
+	COALESCE(summary.gain_value, 0) - COALESCE(summary.loss_value, 0) as net_value,
+	-- Location details
+	sl.id as "stock_location.id", sl.code as "stock_location.code", sl.name as "stock_location.name",
+	sl.location_type as "stock_location.location_type"
+`
+
+const stockCountJoins = `
+	FROM stock_counts sc
+	JOIN stock_locations sl ON sc.location_id = sl.id
+	LEFT JOIN (
+		SELECT stock_count_id,
+			   COUNT(*) as total_items,
+			   COUNT(counted_quantity) as counted_items,
+			   COUNT(*) FILTER (WHERE counted_quantity <> expected_quantity) as variance_items,
+			   SUM((counted_quantity - expected_quantity) * unit_cost) FILTER (WHERE counted_quantity > expected_quantity) as gain_value,
+			   SUM((expected_quantity - counted_quantity) * unit_cost) FILTER (WHERE counted_quantity < expected_quantity) as loss_value
+		FROM stock_count_items
+		GROUP BY stock_count_id
+	) summary ON summary.stock_count_id = sc.id
+`
+
+const stockCountItemColumns = `
+	sci.id, sci.stock_count_id, sci.spare_part_id, sci.expected_quantity, sci.unit_cost,
+	sci.counted_quantity, sci.counted_by, sci.counted_at,
+	COALESCE(sci.counted_quantity - sci.expected_quantity, 0) as variance_quantity,
+	COALESCE((sci.counted_quantity - sci.expected_quantity) * sci.unit_cost, 0) as variance_value,
+	sci.posted_value, sci.notes, sci.created_at, sci.updated_at,
+	-- Spare part details
+	sp.id as "spare_part.id", sp.part_code as "spare_part.part_code", sp.barcode as "spare_part.barcode",
+	sp.name as "spare_part.name", sp.category as "spare_part.category", sp.unit as "spare_part.unit",
+	sp.stock_quantity as "spare_part.stock_quantity"
+`
+
+// Create opens a count session and snapshots the expected quantity at the
+// count's location and the cost of every part in scope. An empty
+// sparePartIDs list means every part stocked at the location, optionally
+// narrowed to the count's category.
+func (r *stockCountRepository) Create(ctx context.Context, count *domain.StockCount, sparePartIDs []int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO stock_counts (count_number, status, location_id, location, category, notes, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		count.CountNumber, count.Status, count.LocationID, count.Location, count.Category, count.Notes, count.CreatedBy,
+	).Scan(&count.ID, &count.CreatedAt, &count.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create stock count: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO stock_count_items (stock_count_id, spare_part_id, expected_quantity, unit_cost)
+		SELECT $1, sp.id, COALESCE(sps.quantity, 0), sp.cost_price
+		FROM spare_parts sp
+		LEFT JOIN spare_part_stocks sps ON sps.spare_part_id = sp.id AND sps.location_id = $4
+		WHERE sp.deleted_at IS NULL
+		  AND ($2::varchar IS NULL OR sp.category = $2)
+		  AND (sp.id = ANY($3) OR (cardinality($3::int[]) = 0 AND sps.id IS NOT NULL))
+	`, count.ID, count.Category, pq.Array(sparePartIDs), count.LocationID)
+	if err != nil {
+		return fmt.Errorf("failed to snapshot stock count items: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to snapshot stock count items: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("no spare parts match the count scope")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit stock count: %w", err)
+	}
+
+	return nil
+}
+
+func (r *stockCountRepository) GetByID(ctx context.Context, id int) (*domain.StockCount, error) {
+	var count domain.StockCount
+	query := `SELECT ` + stockCountColumns + stockCountJoins + `
+		WHERE sc.id = $1 AND sc.deleted_at IS NULL
+	`
+
+	err := r.db.GetContext(ctx, &count, query, id)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get stock count: %w", err)
+	}
+
+	return &count, nil
+}
+
+// List returns count sessions, newest first. An empty status lists all.
+func (r *stockCountRepository) List(ctx context.Context, status domain.StockCountStatus, offset, limit int) ([]*domain.StockCount, error) {
+	var counts []*domain.StockCount
+	query := `SELECT ` + stockCountColumns + stockCountJoins + `
+		WHERE sc.deleted_at IS NULL AND ($1 = '' OR sc.status = $1)
+		ORDER BY sc.created_at DESC, sc.id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	err := r.db.SelectContext(ctx, &counts, query, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stock counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+func (r *stockCountRepository) Count(ctx context.Context, status domain.StockCountStatus) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM stock_counts WHERE deleted_at IS NULL AND ($1 = '' OR status = $1)`
+
+	err := r.db.QueryRowContext(ctx, query, status).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count stock counts: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *stockCountRepository) GenerateCountNumber(ctx context.Context) (string, error) {
+	var count int
+	today := time.Now().Format("20060102")
+
+	query := `
+		SELECT COUNT(*) FROM stock_counts
+		WHERE count_number LIKE $1
+	`
+
+	err := r.db.QueryRowContext(ctx, query, fmt.Sprintf("SO-%s%%", today)).Scan(&count)
+	if err != nil {
+		return "", fmt.Errorf("failed to count stock counts for number generation: %w", err)
+	}
+
+	return fmt.Sprintf("SO-%s-%04d", today, count+1), nil
+}
+
+func (r *stockCountRepository) ListItems(ctx context.Context, stockCountID int) ([]*domain.StockCountItem, error) {
+	var items []*domain.StockCountItem
+	query := `
+		SELECT ` + stockCountItemColumns + `
+		FROM stock_count_items sci
+		JOIN spare_parts sp ON sci.spare_part_id = sp.id
+		WHERE sci.stock_count_id = $1
+		ORDER BY sp.part_code, sci.id
+	`
+
+	err := r.db.SelectContext(ctx, &items, query, stockCountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stock count items: %w", err)
+	}
+
+	return items, nil
+}
+
+func (r *stockCountRepository) GetItemByID(ctx context.Context, id int) (*domain.StockCountItem, error) {
+	var item domain.StockCountItem
+	query := `
+		SELECT ` + stockCountItemColumns + `
+		FROM stock_count_items sci
+		JOIN spare_parts sp ON sci.spare_part_id = sp.id
+		WHERE sci.id = $1
+	`
+
+	err := r.db.GetContext(ctx, &item, query, id)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get stock count item: %w", err)
+	}
+
+	return &item, nil
+}
+
+// GetItemByCode finds the count line for a scanned barcode, falling back to
+// the part code for parts without a barcode label
+func (r *stockCountRepository) GetItemByCode(ctx context.Context, stockCountID int, code string) (*domain.StockCountItem, error) {
+	var item domain.StockCountItem
+	query := `
+		SELECT ` + stockCountItemColumns + `
+		FROM stock_count_items sci
+		JOIN spare_parts sp ON sci.spare_part_id = sp.id
+		WHERE sci.stock_count_id = $1 AND (sp.barcode = $2 OR sp.part_code = $2)
+		ORDER BY CASE WHEN sp.barcode = $2 THEN 0 ELSE 1 END
+		LIMIT 1
+	`
+
+	err := r.db.GetContext(ctx, &item, query, stockCountID, code)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get stock count item by code: %w", err)
+	}
+
+	return &item, nil
+}
+
+// AddCount adds scanned quantity to a line. Counting is only possible while
+// the session is open.
+func (r *stockCountRepository) AddCount(ctx context.Context, itemID int, quantity int, countedBy int) error {
+	query := `
+		UPDATE stock_count_items SET
+			counted_quantity = COALESCE(counted_quantity, 0) + $2,
+			counted_by = $3, counted_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND COALESCE(counted_quantity, 0) + $2 >= 0
+		  AND stock_count_id IN (SELECT id FROM stock_counts WHERE status = 'open' AND deleted_at IS NULL)
+	`
+
+	return r.updateCount(ctx, query, itemID, quantity, countedBy)
+}
+
+// SetCount replaces a line's count, e.g. after a recount. A nil quantity
+// marks the line as not counted again.
+func (r *stockCountRepository) SetCount(ctx context.Context, itemID int, quantity *int, countedBy int, notes *string) error {
+	query := `
+		UPDATE stock_count_items SET
+			counted_quantity = $2, counted_by = $3, counted_at = CURRENT_TIMESTAMP,
+			notes = COALESCE($4, notes)
+		WHERE id = $1
+		  AND stock_count_id IN (SELECT id FROM stock_counts WHERE status = 'open' AND deleted_at IS NULL)
+	`
+
+	return r.updateCount(ctx, query, itemID, quantity, countedBy, notes)
+}
+
+func (r *stockCountRepository) updateCount(ctx context.Context, query string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to record stock count: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to record stock count: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("stock count is not open for counting")
+	}
+
+	return nil
+}
+
+// UpdateStatus moves a session between the review states. It fails if the
+// session is no longer in the from status.
+func (r *stockCountRepository) UpdateStatus(ctx context.Context, id int, from, to domain.StockCountStatus, userID int) error {
+	query := `
+		UPDATE stock_counts SET
+			status = $3,
+			submitted_by = CASE WHEN $3 = 'submitted' THEN $4::int WHEN $3 = 'open' THEN NULL ELSE submitted_by END,
+			submitted_at = CASE WHEN $3 = 'submitted' THEN CURRENT_TIMESTAMP WHEN $3 = 'open' THEN NULL ELSE submitted_at END
+		WHERE id = $1 AND status = $2 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, from, to, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update stock count status: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update stock count status: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("stock count is no longer %s", from)
+	}
+
+	return nil
+}
+
+type stockCountVariance struct {
+	ItemID           int     `db:"item_id"`
+	SparePartID      int     `db:"spare_part_id"`
+	PartCode         string  `db:"part_code"`
+	ExpectedQuantity int     `db:"expected_quantity"`
+	CountedQuantity  int     `db:"counted_quantity"`
+	CostPrice        float64 `db:"cost_price"`
+	TrackSerials     bool    `db:"track_serials"`
+}
+
+// Approve posts every counted variance at the count's location as a stock
+// movement referencing the session, in one transaction, and freezes the
+// session. Variances are applied to current stock so sales made while
+// counting are kept. Gains enter at the
+// part's cost price; losses are valued like any other issue of stock.
+// Variances on serial-tracked parts are rejected.
+func (r *stockCountRepository) Approve(ctx context.Context, count *domain.StockCount, costingMethod domain.CostingMethod) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE stock_counts SET status = 'approved', approved_by = $2, approved_at = $3
+		WHERE id = $1 AND status = 'submitted' AND deleted_at IS NULL
+	`, count.ID, count.ApprovedBy, count.ApprovedAt)
+	if err != nil {
+		return fmt.Errorf("failed to approve stock count: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to approve stock count: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("stock count is not awaiting approval")
+	}
+
+	var variances []*stockCountVariance
+	err = tx.SelectContext(ctx, &variances, `
+		SELECT sci.id as item_id, sci.spare_part_id, sp.part_code, sci.expected_quantity,
+			   sci.counted_quantity, sp.cost_price, sp.track_serials
+		FROM stock_count_items sci
+		JOIN spare_parts sp ON sci.spare_part_id = sp.id
+		WHERE sci.stock_count_id = $1
+		  AND sci.counted_quantity IS NOT NULL AND sci.counted_quantity <> sci.expected_quantity
+		ORDER BY sci.spare_part_id
+		FOR UPDATE OF sp
+	`, count.ID)
+	if err != nil {
+		return fmt.Errorf("failed to lock stock count variances: %w", err)
+	}
+
+	var stockQuery string
+	if costingMethod == domain.CostingMethodFIFO {
+		// Cost price = value of open layers per unit
+		stockQuery = `
+			UPDATE spare_parts SET
+				stock_quantity = stock_quantity + $2,
+				cost_price = COALESCE((
+					SELECT ROUND(SUM(quantity_remaining * unit_cost) / NULLIF(SUM(quantity_remaining), 0), 2)
+					FROM stock_cost_layers
+					WHERE spare_part_id = $1 AND quantity_remaining > 0
+				), cost_price),
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND stock_quantity + $2 >= 0
+		`
+	} else {
+		// Gains enter at cost price, so the average does not move
+		stockQuery = `
+			UPDATE spare_parts SET
+				stock_quantity = stock_quantity + $2,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND stock_quantity + $2 >= 0
+		`
+	}
+
+	for _, variance := range variances {
+		// A count does not say which serial numbers were found or missing
+		if variance.TrackSerials {
+			return fmt.Errorf("variance on %s: serial-tracked parts are corrected with a stock adjustment listing the serial numbers", variance.PartCode)
+		}
+
+		quantity := variance.CountedQuantity - variance.ExpectedQuantity
+		movementType := domain.MovementTypeIn
+		unitCost := variance.CostPrice
+		var totalValue float64
+
+		if quantity > 0 {
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO stock_cost_layers (
+					spare_part_id, reference_type, reference_id, received_date,
+					quantity_received, quantity_remaining, unit_cost
+				)
+				VALUES ($1, $2, $3, $4, $5, $5, $6)
+			`, variance.SparePartID, domain.ReferenceTypeStockCount, count.ID, count.ApprovedAt, quantity, unitCost)
+			if err != nil {
+				return fmt.Errorf("failed to create stock cost layer: %w", err)
+			}
+
+			totalValue = float64(quantity) * unitCost
+		} else {
+			movementType = domain.MovementTypeOut
+
+			layerCost, covered, err := consumeCostLayers(ctx, tx, variance.SparePartID, -quantity)
+			if err != nil {
+				return err
+			}
+
+			totalValue = -float64(quantity) * unitCost
+			if costingMethod == domain.CostingMethodFIFO {
+				totalValue = layerCost + float64(-quantity-covered)*variance.CostPrice
+				unitCost = totalValue / float64(-quantity)
+			}
+		}
+
+		result, err := tx.ExecContext(ctx, stockQuery, variance.SparePartID, quantity)
+		if err != nil {
+			return fmt.Errorf("failed to update spare part stock: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to update spare part stock: %w", err)
+		}
+		if rows == 0 {
+			return fmt.Errorf("variance on %s would leave negative stock", variance.PartCode)
+		}
+
+		if _, err := adjustLocationStock(ctx, tx, variance.SparePartID, &count.LocationID, quantity); err != nil {
+			return fmt.Errorf("variance on %s: %w", variance.PartCode, err)
+		}
+
+		err = postBatchAdjustment(ctx, tx, variance.SparePartID, count.LocationID, quantity,
+			count.CountNumber, domain.ReferenceTypeStockCount, &count.ID, *count.ApprovedAt)
+		if err != nil {
+			return fmt.Errorf("variance on %s: %w", variance.PartCode, err)
+		}
+
+		movementQuantity := quantity
+		postedValue := totalValue
+		if quantity < 0 {
+			movementQuantity = -quantity
+			postedValue = -totalValue
+		}
+
+		notes := fmt.Sprintf("Stock count %s: counted %d, expected %d", count.CountNumber, variance.CountedQuantity, variance.ExpectedQuantity)
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO stock_movements (
+				spare_part_id, movement_type, quantity, reference_type, reference_id, location_id,
+				notes, created_by, movement_date, unit_cost, total_value
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, ROUND($10::numeric, 2), ROUND($11::numeric, 2))
+		`,
+			variance.SparePartID, movementType, movementQuantity, domain.ReferenceTypeStockCount,
+			count.ID, count.LocationID, notes, count.ApprovedBy, count.ApprovedAt, unitCost, totalValue,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create stock movement: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE stock_count_items SET posted_value = ROUND($2::numeric, 2) WHERE id = $1
+		`, variance.ItemID, postedValue)
+		if err != nil {
+			return fmt.Errorf("failed to record posted variance: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit stock count approval: %w", err)
+	}
+
+	return nil
+}
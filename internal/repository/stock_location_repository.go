@@ -0,0 +1,322 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type stockLocationRepository struct {
+	db *sqlx.DB
+}
+
+// NewStockLocationRepository creates a new stock location repository
+func NewStockLocationRepository(db *sqlx.DB) StockLocationRepository {
+	return &stockLocationRepository{db: db}
+}
+
+const stockLocationColumns = `
+	id, code, name, location_type, is_default, is_active, notes,
+	deleted_at, deleted_by, created_at, updated_at
+`
+
+const sparePartStockColumns = `
+	sps.id, sps.spare_part_id, sps.location_id, sps.bin, sps.quantity, sps.min_stock_level,
+	sps.created_at, sps.updated_at,
+	-- Spare part details
+	sp.id as "spare_part.id", sp.part_code as "spare_part.part_code", sp.barcode as "spare_part.barcode",
+	sp.name as "spare_part.name", sp.unit as "spare_part.unit", sp.stock_quantity as "spare_part.stock_quantity",
+	-- Location details
+	sl.id as "location.id", sl.code as "location.code", sl.name as "location.name",
+	sl.location_type as "location.location_type"
+`
+
+const sparePartStockJoins = `
+	FROM spare_part_stocks sps
+	JOIN spare_parts sp ON sps.spare_part_id = sp.id
+	JOIN stock_locations sl ON sps.location_id = sl.id
+`
+
+func (r *stockLocationRepository) Create(ctx context.Context, location *domain.StockLocation) error {
+	query := `
+		INSERT INTO stock_locations (code, name, location_type, is_active, notes)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		location.Code, location.Name, location.LocationType, location.IsActive, location.Notes,
+	).Scan(&location.ID, &location.CreatedAt, &location.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create stock location: %w", err)
+	}
+
+	return nil
+}
+
+func (r *stockLocationRepository) GetByID(ctx context.Context, id int) (*domain.StockLocation, error) {
+	var location domain.StockLocation
+	query := `SELECT ` + stockLocationColumns + ` FROM stock_locations WHERE id = $1 AND deleted_at IS NULL`
+
+	err := r.db.GetContext(ctx, &location, query, id)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get stock location: %w", err)
+	}
+
+	return &location, nil
+}
+
+func (r *stockLocationRepository) GetDefault(ctx context.Context) (*domain.StockLocation, error) {
+	var location domain.StockLocation
+	query := `SELECT ` + stockLocationColumns + ` FROM stock_locations WHERE is_default AND deleted_at IS NULL`
+
+	err := r.db.GetContext(ctx, &location, query)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get default stock location: %w", err)
+	}
+
+	return &location, nil
+}
+
+func (r *stockLocationRepository) List(ctx context.Context, includeInactive bool) ([]*domain.StockLocation, error) {
+	var locations []*domain.StockLocation
+	query := `SELECT ` + stockLocationColumns + `
+		FROM stock_locations
+		WHERE deleted_at IS NULL AND ($1 OR is_active)
+		ORDER BY is_default DESC, code
+	`
+
+	err := r.db.SelectContext(ctx, &locations, query, includeInactive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stock locations: %w", err)
+	}
+
+	return locations, nil
+}
+
+func (r *stockLocationRepository) Update(ctx context.Context, location *domain.StockLocation) error {
+	query := `
+		UPDATE stock_locations SET
+			code = $2, name = $3, location_type = $4, is_active = $5, notes = $6
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		location.ID, location.Code, location.Name, location.LocationType, location.IsActive, location.Notes,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update stock location: %w", err)
+	}
+
+	return nil
+}
+
+// SetDefault makes the location the default for stock transactions that do
+// not name one
+func (r *stockLocationRepository) SetDefault(ctx context.Context, id int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `UPDATE stock_locations SET is_default = FALSE WHERE is_default`); err != nil {
+		return fmt.Errorf("failed to clear default stock location: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE stock_locations SET is_default = TRUE WHERE id = $1 AND deleted_at IS NULL`, id); err != nil {
+		return fmt.Errorf("failed to set default stock location: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit default stock location: %w", err)
+	}
+
+	return nil
+}
+
+func (r *stockLocationRepository) SoftDelete(ctx context.Context, id int, deletedBy int) error {
+	query := `
+		UPDATE stock_locations SET
+			deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, deletedBy)
+	if err != nil {
+		return fmt.Errorf("failed to delete stock location: %w", err)
+	}
+
+	return nil
+}
+
+func (r *stockLocationRepository) SumQuantity(ctx context.Context, locationID int) (int, error) {
+	var quantity int
+	query := `SELECT COALESCE(SUM(quantity), 0) FROM spare_part_stocks WHERE location_id = $1`
+
+	err := r.db.QueryRowContext(ctx, query, locationID).Scan(&quantity)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum stock location quantity: %w", err)
+	}
+
+	return quantity, nil
+}
+
+// GetStock returns a part's stock at a location, or nil if the part was
+// never stocked there
+func (r *stockLocationRepository) GetStock(ctx context.Context, sparePartID, locationID int) (*domain.SparePartStock, error) {
+	var stock domain.SparePartStock
+	query := `SELECT ` + sparePartStockColumns + sparePartStockJoins + `
+		WHERE sps.spare_part_id = $1 AND sps.location_id = $2
+	`
+
+	err := r.db.GetContext(ctx, &stock, query, sparePartID, locationID)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get spare part stock: %w", err)
+	}
+
+	return &stock, nil
+}
+
+func (r *stockLocationRepository) ListStockByLocation(ctx context.Context, locationID int) ([]*domain.SparePartStock, error) {
+	var stocks []*domain.SparePartStock
+	query := `SELECT ` + sparePartStockColumns + sparePartStockJoins + `
+		WHERE sps.location_id = $1 AND sp.deleted_at IS NULL
+		ORDER BY sps.bin NULLS LAST, sp.part_code
+	`
+
+	err := r.db.SelectContext(ctx, &stocks, query, locationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stock by location: %w", err)
+	}
+
+	return stocks, nil
+}
+
+func (r *stockLocationRepository) ListStockBySparePart(ctx context.Context, sparePartID int) ([]*domain.SparePartStock, error) {
+	var stocks []*domain.SparePartStock
+	query := `SELECT ` + sparePartStockColumns + sparePartStockJoins + `
+		WHERE sps.spare_part_id = $1 AND sl.deleted_at IS NULL
+		ORDER BY sl.is_default DESC, sl.code
+	`
+
+	err := r.db.SelectContext(ctx, &stocks, query, sparePartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stock by spare part: %w", err)
+	}
+
+	return stocks, nil
+}
+
+// ListLowStock returns stock at or below the location's own minimum. A nil
+// location lists every location.
+func (r *stockLocationRepository) ListLowStock(ctx context.Context, locationID *int) ([]*domain.SparePartStock, error) {
+	var stocks []*domain.SparePartStock
+	query := `SELECT ` + sparePartStockColumns + sparePartStockJoins + `
+		WHERE sps.min_stock_level IS NOT NULL AND sps.quantity <= sps.min_stock_level
+		  AND ($1::int IS NULL OR sps.location_id = $1)
+		  AND sp.deleted_at IS NULL AND sl.deleted_at IS NULL AND sl.is_active
+		ORDER BY sl.code, (sps.quantity - sps.min_stock_level), sp.part_code
+	`
+
+	err := r.db.SelectContext(ctx, &stocks, query, locationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list low stock by location: %w", err)
+	}
+
+	return stocks, nil
+}
+
+// UpdateStockSettings sets the bin and minimum stock of a part at a location,
+// creating the stock line if the part was never stocked there
+func (r *stockLocationRepository) UpdateStockSettings(ctx context.Context, stock *domain.SparePartStock) error {
+	query := `
+		INSERT INTO spare_part_stocks (spare_part_id, location_id, bin, min_stock_level)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (spare_part_id, location_id) DO UPDATE SET
+			bin = EXCLUDED.bin, min_stock_level = EXCLUDED.min_stock_level
+	`
+
+	_, err := r.db.ExecContext(ctx, query, stock.SparePartID, stock.LocationID, stock.Bin, stock.MinStockLevel)
+	if err != nil {
+		return fmt.Errorf("failed to update spare part stock settings: %w", err)
+	}
+
+	return nil
+}
+
+// adjustLocationStock changes a part's stock at one location inside the
+// caller's transaction and returns the location used. A nil location means
+// the default location. Stock at a location never goes below zero; callers
+// keep spare_parts.stock_quantity, the total across locations, in step.
+func adjustLocationStock(ctx context.Context, tx *sqlx.Tx, sparePartID int, locationID *int, quantity int) (int, error) {
+	resolvedID, err := resolveLocationID(ctx, tx, locationID)
+	if err != nil {
+		return 0, err
+	}
+
+	if quantity >= 0 {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO spare_part_stocks (spare_part_id, location_id, quantity)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (spare_part_id, location_id) DO UPDATE SET
+				quantity = spare_part_stocks.quantity + EXCLUDED.quantity
+		`, sparePartID, resolvedID, quantity)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update location stock: %w", err)
+		}
+
+		return resolvedID, nil
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE spare_part_stocks SET quantity = quantity + $3
+		WHERE spare_part_id = $1 AND location_id = $2 AND quantity + $3 >= 0
+	`, sparePartID, resolvedID, quantity)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update location stock: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to update location stock: %w", err)
+	}
+	if rows == 0 {
+		return 0, fmt.Errorf("insufficient stock at location for spare part %d", sparePartID)
+	}
+
+	return resolvedID, nil
+}
+
+// resolveLocationID returns the given location, or the default location
+// when none is given
+func resolveLocationID(ctx context.Context, tx *sqlx.Tx, locationID *int) (int, error) {
+	if locationID != nil {
+		return *locationID, nil
+	}
+
+	var id int
+	err := tx.QueryRowContext(ctx, `
+		SELECT id FROM stock_locations WHERE is_default AND deleted_at IS NULL
+	`).Scan(&id)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return 0, fmt.Errorf("no default stock location configured")
+		}
+		return 0, fmt.Errorf("failed to get default stock location: %w", err)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,389 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type stockTransferRepository struct {
+	db *sqlx.DB
+}
+
+// NewStockTransferRepository creates a new stock transfer repository
+func NewStockTransferRepository(db *sqlx.DB) StockTransferRepository {
+	return &stockTransferRepository{db: db}
+}
+
+const stockTransferColumns = `
+	st.id, st.transfer_number, st.from_location_id, st.to_location_id, st.status, st.notes,
+	st.created_by, st.shipped_by, st.shipped_at, st.received_by, st.received_at,
+	st.deleted_at, st.deleted_by, st.created_at, st.updated_at,
+	-- Location details
+	fl.id as "from_location.id", fl.code as "from_location.code", fl.name as "from_location.name",
+	fl.location_type as "from_location.location_type",
+	tl.id as "to_location.id", tl.code as "to_location.code", tl.name as "to_location.name",
+	tl.location_type as "to_location.location_type"
+`
+
+const stockTransferJoins = `
+	FROM stock_transfers st
+	JOIN stock_locations fl ON st.from_location_id = fl.id
+	JOIN stock_locations tl ON st.to_location_id = tl.id
+`
+
+// Create saves a transfer document with its lines. No stock moves until the
+// transfer is shipped.
+func (r *stockTransferRepository) Create(ctx context.Context, transfer *domain.StockTransfer) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO stock_transfers (transfer_number, from_location_id, to_location_id, status, notes, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		transfer.TransferNumber, transfer.FromLocationID, transfer.ToLocationID,
+		transfer.Status, transfer.Notes, transfer.CreatedBy,
+	).Scan(&transfer.ID, &transfer.CreatedAt, &transfer.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create stock transfer: %w", err)
+	}
+
+	for _, item := range transfer.Items {
+		item.StockTransferID = transfer.ID
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO stock_transfer_items (stock_transfer_id, spare_part_id, quantity, serial_numbers)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, created_at
+		`, item.StockTransferID, item.SparePartID, item.Quantity, pq.Array(item.SerialNumbers)).Scan(&item.ID, &item.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create stock transfer item: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit stock transfer: %w", err)
+	}
+
+	return nil
+}
+
+func (r *stockTransferRepository) GetByID(ctx context.Context, id int) (*domain.StockTransfer, error) {
+	var transfer domain.StockTransfer
+	query := `SELECT ` + stockTransferColumns + stockTransferJoins + `
+		WHERE st.id = $1 AND st.deleted_at IS NULL
+	`
+
+	err := r.db.GetContext(ctx, &transfer, query, id)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get stock transfer: %w", err)
+	}
+
+	return &transfer, nil
+}
+
+// List returns transfers, newest first. An empty status lists all; a
+// location matches transfers going out of or into it.
+func (r *stockTransferRepository) List(ctx context.Context, status domain.StockTransferStatus, locationID *int, offset, limit int) ([]*domain.StockTransfer, error) {
+	var transfers []*domain.StockTransfer
+	query := `SELECT ` + stockTransferColumns + stockTransferJoins + `
+		WHERE st.deleted_at IS NULL AND ($1 = '' OR st.status = $1)
+		  AND ($2::int IS NULL OR st.from_location_id = $2 OR st.to_location_id = $2)
+		ORDER BY st.created_at DESC, st.id DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	err := r.db.SelectContext(ctx, &transfers, query, status, locationID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stock transfers: %w", err)
+	}
+
+	return transfers, nil
+}
+
+func (r *stockTransferRepository) Count(ctx context.Context, status domain.StockTransferStatus, locationID *int) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*) FROM stock_transfers
+		WHERE deleted_at IS NULL AND ($1 = '' OR status = $1)
+		  AND ($2::int IS NULL OR from_location_id = $2 OR to_location_id = $2)
+	`
+
+	err := r.db.QueryRowContext(ctx, query, status, locationID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count stock transfers: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *stockTransferRepository) ListItems(ctx context.Context, transferID int) ([]*domain.StockTransferItem, error) {
+	var rows []struct {
+		domain.StockTransferItem
+		Serials pq.StringArray `db:"serial_numbers"`
+	}
+	query := `
+		SELECT sti.id, sti.stock_transfer_id, sti.spare_part_id, sti.quantity, sti.serial_numbers, sti.created_at,
+			   -- Spare part details
+			   sp.id as "spare_part.id", sp.part_code as "spare_part.part_code",
+			   sp.name as "spare_part.name", sp.unit as "spare_part.unit"
+		FROM stock_transfer_items sti
+		JOIN spare_parts sp ON sti.spare_part_id = sp.id
+		WHERE sti.stock_transfer_id = $1
+		ORDER BY sti.id
+	`
+
+	err := r.db.SelectContext(ctx, &rows, query, transferID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stock transfer items: %w", err)
+	}
+
+	items := make([]*domain.StockTransferItem, 0, len(rows))
+	for i := range rows {
+		item := &rows[i].StockTransferItem
+		item.SerialNumbers = rows[i].Serials
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (r *stockTransferRepository) GenerateTransferNumber(ctx context.Context) (string, error) {
+	var count int
+	today := time.Now().Format("20060102")
+
+	query := `
+		SELECT COUNT(*) FROM stock_transfers
+		WHERE transfer_number LIKE $1
+	`
+
+	err := r.db.QueryRowContext(ctx, query, fmt.Sprintf("TRF-%s%%", today)).Scan(&count)
+	if err != nil {
+		return "", fmt.Errorf("failed to count stock transfers for number generation: %w", err)
+	}
+
+	return fmt.Sprintf("TRF-%s-%04d", today, count+1), nil
+}
+
+// Ship takes the transfer's stock out of the source location and holds it at
+// the transit location until it is received
+func (r *stockTransferRepository) Ship(ctx context.Context, transfer *domain.StockTransfer) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = updateTransferStatus(ctx, tx, `
+		UPDATE stock_transfers SET status = 'in_transit', shipped_by = $2, shipped_at = $3
+		WHERE id = $1 AND status = 'draft' AND deleted_at IS NULL
+	`, transfer.ID, transfer.ShippedBy, transfer.ShippedAt)
+	if err != nil {
+		return err
+	}
+
+	transitID, err := transitLocationID(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	notes := fmt.Sprintf("Transfer %s shipped", transfer.TransferNumber)
+	err = moveTransferItems(ctx, tx, transfer, transfer.FromLocationID, transitID,
+		domain.MovementTypeOut, transfer.FromLocationID, notes, *transfer.ShippedBy, *transfer.ShippedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit stock transfer shipment: %w", err)
+	}
+
+	return nil
+}
+
+// Receive books the transfer's stock from the transit location into the
+// destination location
+func (r *stockTransferRepository) Receive(ctx context.Context, transfer *domain.StockTransfer) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = updateTransferStatus(ctx, tx, `
+		UPDATE stock_transfers SET status = 'received', received_by = $2, received_at = $3
+		WHERE id = $1 AND status = 'in_transit' AND deleted_at IS NULL
+	`, transfer.ID, transfer.ReceivedBy, transfer.ReceivedAt)
+	if err != nil {
+		return err
+	}
+
+	transitID, err := transitLocationID(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	notes := fmt.Sprintf("Transfer %s received", transfer.TransferNumber)
+	err = moveTransferItems(ctx, tx, transfer, transitID, transfer.ToLocationID,
+		domain.MovementTypeIn, transfer.ToLocationID, notes, *transfer.ReceivedBy, *transfer.ReceivedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit stock transfer receipt: %w", err)
+	}
+
+	return nil
+}
+
+// Cancel closes a draft or in-transit transfer. Stock already shipped goes
+// back to the source location.
+func (r *stockTransferRepository) Cancel(ctx context.Context, transfer *domain.StockTransfer, cancelledBy int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = updateTransferStatus(ctx, tx, `
+		UPDATE stock_transfers SET status = 'cancelled'
+		WHERE id = $1 AND status = $2 AND deleted_at IS NULL
+	`, transfer.ID, transfer.Status)
+	if err != nil {
+		return err
+	}
+
+	if transfer.Status == domain.StockTransferStatusInTransit {
+		transitID, err := transitLocationID(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		notes := fmt.Sprintf("Transfer %s cancelled, returned to source", transfer.TransferNumber)
+		err = moveTransferItems(ctx, tx, transfer, transitID, transfer.FromLocationID,
+			domain.MovementTypeIn, transfer.FromLocationID, notes, cancelledBy, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit stock transfer cancellation: %w", err)
+	}
+
+	return nil
+}
+
+func updateTransferStatus(ctx context.Context, tx *sqlx.Tx, query string, args ...interface{}) error {
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update stock transfer status: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update stock transfer status: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("stock transfer status has changed, reload and try again")
+	}
+
+	return nil
+}
+
+func transitLocationID(ctx context.Context, tx *sqlx.Tx) (int, error) {
+	var id int
+	err := tx.QueryRowContext(ctx, `
+		SELECT id FROM stock_locations
+		WHERE location_type = 'transit' AND deleted_at IS NULL
+		ORDER BY id
+		LIMIT 1
+	`).Scan(&id)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return 0, fmt.Errorf("no transit stock location configured")
+		}
+		return 0, fmt.Errorf("failed to get transit stock location: %w", err)
+	}
+
+	return id, nil
+}
+
+// moveTransferItems moves every line of a transfer, with its batches and
+// serial numbers, between two locations and writes one stock movement per
+// line at movementLocationID. The part totals do not change: stock on the way
+// stays owned at the transit location.
+func moveTransferItems(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	transfer *domain.StockTransfer,
+	fromLocationID, toLocationID int,
+	movementType domain.MovementType,
+	movementLocationID int,
+	notes string,
+	userID int,
+	movedAt time.Time,
+) error {
+	var lines []struct {
+		SparePartID   int            `db:"spare_part_id"`
+		PartCode      string         `db:"part_code"`
+		Quantity      int            `db:"quantity"`
+		SerialNumbers pq.StringArray `db:"serial_numbers"`
+		CostPrice     float64        `db:"cost_price"`
+	}
+	err := tx.SelectContext(ctx, &lines, `
+		SELECT sti.spare_part_id, sp.part_code, sti.quantity, sti.serial_numbers, sp.cost_price
+		FROM stock_transfer_items sti
+		JOIN spare_parts sp ON sti.spare_part_id = sp.id
+		WHERE sti.stock_transfer_id = $1
+		ORDER BY sti.spare_part_id
+	`, transfer.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get stock transfer items: %w", err)
+	}
+
+	for _, line := range lines {
+		if _, err := adjustLocationStock(ctx, tx, line.SparePartID, &fromLocationID, -line.Quantity); err != nil {
+			return fmt.Errorf("cannot move %s: %w", line.PartCode, err)
+		}
+		if _, err := adjustLocationStock(ctx, tx, line.SparePartID, &toLocationID, line.Quantity); err != nil {
+			return fmt.Errorf("cannot move %s: %w", line.PartCode, err)
+		}
+		if err := moveStockBatches(ctx, tx, line.SparePartID, fromLocationID, toLocationID, line.Quantity); err != nil {
+			return fmt.Errorf("cannot move %s: %w", line.PartCode, err)
+		}
+		if err := moveSerials(ctx, tx, line.SparePartID, fromLocationID, toLocationID, line.Quantity, line.SerialNumbers); err != nil {
+			return fmt.Errorf("cannot move %s: %w", line.PartCode, err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO stock_movements (
+				spare_part_id, movement_type, quantity, reference_type, reference_id, location_id,
+				notes, created_by, movement_date, unit_cost, total_value
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		`,
+			line.SparePartID, movementType, line.Quantity, domain.ReferenceTypeTransfer, transfer.ID,
+			movementLocationID, notes, userID, movedAt, line.CostPrice, float64(line.Quantity)*line.CostPrice,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create stock movement: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type supplierRepository struct {
+	db *sqlx.DB
+}
+
+// NewSupplierRepository creates a new supplier repository
+func NewSupplierRepository(db *sqlx.DB) SupplierRepository {
+	return &supplierRepository{db: db}
+}
+
+func (r *supplierRepository) Create(ctx context.Context, supplier *domain.Supplier) error {
+	query := `
+		INSERT INTO suppliers (supplier_code, name, contact_person, phone, email, address)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, lead_time_days, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		supplier.SupplierCode, supplier.Name, supplier.ContactPerson,
+		supplier.Phone, supplier.Email, supplier.Address,
+	).Scan(&supplier.ID, &supplier.LeadTimeDays, &supplier.CreatedAt, &supplier.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create supplier: %w", err)
+	}
+
+	return nil
+}
+
+func (r *supplierRepository) GetByID(ctx context.Context, id int) (*domain.Supplier, error) {
+	var supplier domain.Supplier
+	query := `
+		SELECT id, supplier_code, name, contact_person, phone, email, address, lead_time_days,
+			   deleted_at, deleted_by, created_at, updated_at
+		FROM suppliers
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	err := r.db.GetContext(ctx, &supplier, query, id)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get supplier by ID: %w", err)
+	}
+
+	return &supplier, nil
+}
+
+func (r *supplierRepository) GetBySupplierCode(ctx context.Context, supplierCode string) (*domain.Supplier, error) {
+	var supplier domain.Supplier
+	query := `
+		SELECT id, supplier_code, name, contact_person, phone, email, address, lead_time_days,
+			   deleted_at, deleted_by, created_at, updated_at
+		FROM suppliers
+		WHERE supplier_code = $1 AND deleted_at IS NULL
+	`
+
+	err := r.db.GetContext(ctx, &supplier, query, supplierCode)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get supplier by supplier code: %w", err)
+	}
+
+	return &supplier, nil
+}
+
+func (r *supplierRepository) List(ctx context.Context, offset, limit int) ([]*domain.Supplier, error) {
+	var suppliers []*domain.Supplier
+	query := `
+		SELECT id, supplier_code, name, contact_person, phone, email, address, lead_time_days,
+			   deleted_at, deleted_by, created_at, updated_at
+		FROM suppliers
+		WHERE deleted_at IS NULL
+		ORDER BY created_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	err := r.db.SelectContext(ctx, &suppliers, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list suppliers: %w", err)
+	}
+
+	return suppliers, nil
+}
+
+func (r *supplierRepository) Update(ctx context.Context, supplier *domain.Supplier) error {
+	query := `
+		UPDATE suppliers
+		SET name = $2, contact_person = $3, phone = $4, email = $5, address = $6,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		supplier.ID, supplier.Name, supplier.ContactPerson,
+		supplier.Phone, supplier.Email, supplier.Address,
+	).Scan(&supplier.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to update supplier: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateLeadTime sets how many days the supplier takes to deliver an order
+func (r *supplierRepository) UpdateLeadTime(ctx context.Context, id int, leadTimeDays int) error {
+	query := `
+		UPDATE suppliers
+		SET lead_time_days = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, leadTimeDays)
+	if err != nil {
+		return fmt.Errorf("failed to update supplier lead time: %w", err)
+	}
+
+	return nil
+}
+
+func (r *supplierRepository) SoftDelete(ctx context.Context, id int, deletedBy int) error {
+	query := `
+		UPDATE suppliers
+		SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, deletedBy)
+	if err != nil {
+		return fmt.Errorf("failed to delete supplier: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("supplier not found or already deleted")
+	}
+
+	return nil
+}
+
+func (r *supplierRepository) Count(ctx context.Context) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM suppliers WHERE deleted_at IS NULL`
+
+	err := r.db.GetContext(ctx, &count, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count suppliers: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *supplierRepository) Search(ctx context.Context, query string, offset, limit int) ([]*domain.Supplier, error) {
+	var suppliers []*domain.Supplier
+	searchQuery := `
+		SELECT id, supplier_code, name, contact_person, phone, email, address, lead_time_days,
+			   deleted_at, deleted_by, created_at, updated_at
+		FROM suppliers
+		WHERE deleted_at IS NULL
+		AND (
+			name ILIKE $1 OR
+			supplier_code ILIKE $1 OR
+			contact_person ILIKE $1 OR
+			phone ILIKE $1
+		)
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	searchTerm := "%" + strings.ToLower(query) + "%"
+	err := r.db.SelectContext(ctx, &suppliers, searchQuery, searchTerm, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search suppliers: %w", err)
+	}
+
+	return suppliers, nil
+}
+
+func (r *supplierRepository) GenerateSupplierCode(ctx context.Context) (string, error) {
+	var lastNumber int
+	query := `
+		SELECT COALESCE(MAX(CAST(SUBSTRING(supplier_code FROM 5) AS INTEGER)), 0)
+		FROM suppliers
+		WHERE supplier_code LIKE 'SUP-%'
+	`
+
+	err := r.db.GetContext(ctx, &lastNumber, query)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate supplier code: %w", err)
+	}
+
+	return fmt.Sprintf("SUP-%04d", lastNumber+1), nil
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type vehicleCostRepository struct {
+	db *sqlx.DB
+}
+
+// NewVehicleCostRepository creates a new vehicle cost repository
+func NewVehicleCostRepository(db *sqlx.DB) VehicleCostRepository {
+	return &vehicleCostRepository{db: db}
+}
+
+func (r *vehicleCostRepository) Create(ctx context.Context, cost *domain.VehicleCost) error {
+	query := `
+		INSERT INTO vehicle_costs (
+			vehicle_id, cost_type, amount, cost_date, description, reference, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		cost.VehicleID, cost.CostType, cost.Amount, cost.CostDate,
+		cost.Description, cost.Reference, cost.CreatedBy,
+	).Scan(&cost.ID, &cost.CreatedAt, &cost.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create vehicle cost: %w", err)
+	}
+
+	return nil
+}
+
+func (r *vehicleCostRepository) GetByID(ctx context.Context, id int) (*domain.VehicleCost, error) {
+	var cost domain.VehicleCost
+	query := `
+		SELECT id, vehicle_id, cost_type, amount, cost_date, description, reference, created_by,
+			   deleted_at, deleted_by, created_at, updated_at
+		FROM vehicle_costs
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	err := r.db.GetContext(ctx, &cost, query, id)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get vehicle cost: %w", err)
+	}
+
+	return &cost, nil
+}
+
+func (r *vehicleCostRepository) ListByVehicleID(ctx context.Context, vehicleID int) ([]*domain.VehicleCost, error) {
+	var costs []*domain.VehicleCost
+	query := `
+		SELECT id, vehicle_id, cost_type, amount, cost_date, description, reference, created_by,
+			   deleted_at, deleted_by, created_at, updated_at
+		FROM vehicle_costs
+		WHERE vehicle_id = $1 AND deleted_at IS NULL
+		ORDER BY cost_date, id
+	`
+
+	err := r.db.SelectContext(ctx, &costs, query, vehicleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list vehicle costs: %w", err)
+	}
+
+	return costs, nil
+}
+
+func (r *vehicleCostRepository) SoftDelete(ctx context.Context, id int, deletedBy int) error {
+	query := `
+		UPDATE vehicle_costs SET
+			deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, deletedBy)
+	if err != nil {
+		return fmt.Errorf("failed to delete vehicle cost: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("vehicle cost not found or already deleted")
+	}
+
+	return nil
+}
+
+// SumByType totals the vehicle's ledger costs and its completed
+// reconditioning work orders per cost type
+func (r *vehicleCostRepository) SumByType(ctx context.Context, vehicleID int) ([]*domain.VehicleCostComponent, error) {
+	var components []*domain.VehicleCostComponent
+	query := `
+		SELECT 'reconditioning' as cost_type, COALESCE(SUM(total_cost), 0) as amount, COUNT(*) as entries
+		FROM work_orders
+		WHERE vehicle_id = $1 AND status = 'completed' AND deleted_at IS NULL
+		  AND NOT COALESCE(is_warranty, FALSE) AND order_type = 'reconditioning'
+		UNION ALL
+		SELECT cost_type, SUM(amount) as amount, COUNT(*) as entries
+		FROM vehicle_costs
+		WHERE vehicle_id = $1 AND deleted_at IS NULL
+		GROUP BY cost_type
+	`
+
+	err := r.db.SelectContext(ctx, &components, query, vehicleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum vehicle costs: %w", err)
+	}
+
+	return components, nil
+}
+
+// ListReconditioningWorkOrders returns the completed work orders whose cost
+// is part of the vehicle's HPP
+func (r *vehicleCostRepository) ListReconditioningWorkOrders(ctx context.Context, vehicleID int) ([]*domain.VehicleCostWorkOrder, error) {
+	var workOrders []*domain.VehicleCostWorkOrder
+	query := `
+		SELECT id, wo_number, total_cost, completed_at
+		FROM work_orders
+		WHERE vehicle_id = $1 AND status = 'completed' AND deleted_at IS NULL
+		  AND NOT COALESCE(is_warranty, FALSE) AND order_type = 'reconditioning'
+		ORDER BY completed_at, id
+	`
+
+	err := r.db.SelectContext(ctx, &workOrders, query, vehicleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list vehicle work orders: %w", err)
+	}
+
+	return workOrders, nil
+}
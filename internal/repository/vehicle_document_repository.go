@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type vehicleDocumentRepository struct {
+	db *sqlx.DB
+}
+
+// NewVehicleDocumentRepository creates a new vehicle document repository
+func NewVehicleDocumentRepository(db *sqlx.DB) VehicleDocumentRepository {
+	return &vehicleDocumentRepository{db: db}
+}
+
+func (r *vehicleDocumentRepository) Create(ctx context.Context, document *domain.VehicleDocument) error {
+	query := `
+		INSERT INTO vehicle_documents (
+			vehicle_id, document_type, document_number, custody_status, custody_location,
+			expiry_date, tax_due_date, received_date, notes, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		document.VehicleID, document.DocumentType, document.DocumentNumber,
+		document.CustodyStatus, document.CustodyLocation, document.ExpiryDate,
+		document.TaxDueDate, document.ReceivedDate, document.Notes, document.CreatedBy,
+	).Scan(&document.ID, &document.CreatedAt, &document.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create vehicle document: %w", err)
+	}
+
+	return nil
+}
+
+func (r *vehicleDocumentRepository) GetByID(ctx context.Context, id int) (*domain.VehicleDocument, error) {
+	var document domain.VehicleDocument
+	query := `
+		SELECT id, vehicle_id, document_type, document_number, custody_status, custody_location,
+			   expiry_date, tax_due_date, scan_file, received_date, sales_invoice_id,
+			   handed_over_at, handed_over_to, handed_over_by, notes, created_by,
+			   deleted_at, deleted_by, created_at, updated_at
+		FROM vehicle_documents
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	err := r.db.GetContext(ctx, &document, query, id)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get vehicle document: %w", err)
+	}
+
+	return &document, nil
+}
+
+func (r *vehicleDocumentRepository) Update(ctx context.Context, document *domain.VehicleDocument) error {
+	query := `
+		UPDATE vehicle_documents SET
+			document_number = $2, custody_status = $3, custody_location = $4,
+			expiry_date = $5, tax_due_date = $6, received_date = $7, notes = $8
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		document.ID, document.DocumentNumber, document.CustodyStatus, document.CustodyLocation,
+		document.ExpiryDate, document.TaxDueDate, document.ReceivedDate, document.Notes,
+	).Scan(&document.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to update vehicle document: %w", err)
+	}
+
+	return nil
+}
+
+func (r *vehicleDocumentRepository) Delete(ctx context.Context, id int, deletedBy int) error {
+	query := `
+		UPDATE vehicle_documents SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, deletedBy)
+	if err != nil {
+		return fmt.Errorf("failed to delete vehicle document: %w", err)
+	}
+
+	return nil
+}
+
+func (r *vehicleDocumentRepository) ListByVehicleID(ctx context.Context, vehicleID int) ([]*domain.VehicleDocument, error) {
+	var documents []*domain.VehicleDocument
+	query := `
+		SELECT id, vehicle_id, document_type, document_number, custody_status, custody_location,
+			   expiry_date, tax_due_date, scan_file, received_date, sales_invoice_id,
+			   handed_over_at, handed_over_to, handed_over_by, notes, created_by,
+			   deleted_at, deleted_by, created_at, updated_at
+		FROM vehicle_documents
+		WHERE vehicle_id = $1 AND deleted_at IS NULL
+		ORDER BY document_type, id
+	`
+
+	err := r.db.SelectContext(ctx, &documents, query, vehicleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list vehicle documents: %w", err)
+	}
+
+	return documents, nil
+}
+
+// ListExpiring returns documents still held by the dealer whose validity or
+// annual tax falls due before the given date, including already lapsed ones
+func (r *vehicleDocumentRepository) ListExpiring(ctx context.Context, before time.Time) ([]*domain.VehicleDocument, error) {
+	var documents []*domain.VehicleDocument
+	query := `
+		SELECT d.id, d.vehicle_id, d.document_type, d.document_number, d.custody_status,
+			   d.custody_location, d.expiry_date, d.tax_due_date, d.scan_file, d.received_date,
+			   d.sales_invoice_id, d.handed_over_at, d.handed_over_to, d.handed_over_by,
+			   d.notes, d.created_by, d.deleted_at, d.deleted_by, d.created_at, d.updated_at,
+			   -- Vehicle details
+			   v.id as "vehicle.id", v.vehicle_code as "vehicle.vehicle_code",
+			   v.brand as "vehicle.brand", v.model as "vehicle.model", v.year as "vehicle.year",
+			   v.plate_number as "vehicle.plate_number", v.status as "vehicle.status"
+		FROM vehicle_documents d
+		JOIN vehicles v ON d.vehicle_id = v.id
+		WHERE d.deleted_at IS NULL AND v.deleted_at IS NULL
+		  AND d.custody_status <> 'handed_over'
+		  AND (d.expiry_date < $1 OR d.tax_due_date < $1)
+		ORDER BY LEAST(COALESCE(d.expiry_date, d.tax_due_date), COALESCE(d.tax_due_date, d.expiry_date)), d.id
+	`
+
+	err := r.db.SelectContext(ctx, &documents, query, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expiring vehicle documents: %w", err)
+	}
+
+	return documents, nil
+}
+
+func (r *vehicleDocumentRepository) UpdateScanFile(ctx context.Context, id int, scanFile string) error {
+	query := `
+		UPDATE vehicle_documents SET scan_file = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, scanFile)
+	if err != nil {
+		return fmt.Errorf("failed to update vehicle document scan: %w", err)
+	}
+
+	return nil
+}
+
+// HandOver records the handover of a document to the buyer of the vehicle
+func (r *vehicleDocumentRepository) HandOver(ctx context.Context, document *domain.VehicleDocument) error {
+	query := `
+		UPDATE vehicle_documents SET
+			custody_status = 'handed_over', sales_invoice_id = $2, handed_over_at = $3,
+			handed_over_to = $4, handed_over_by = $5
+		WHERE id = $1 AND deleted_at IS NULL AND custody_status = 'in_custody'
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		document.ID, document.SalesInvoiceID, document.HandedOverAt,
+		document.HandedOverTo, document.HandedOverBy,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to hand over vehicle document: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to hand over vehicle document: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("document is not in custody")
+	}
+
+	return nil
+}
@@ -19,22 +19,27 @@ func NewVehicleRepository(db *sqlx.DB) VehicleRepository {
 }
 
 func (r *vehicleRepository) Create(ctx context.Context, vehicle *domain.Vehicle) error {
+	return insertVehicle(ctx, r.db, vehicle)
+}
+
+func insertVehicle(ctx context.Context, q sqlx.QueryerContext, vehicle *domain.Vehicle) error {
 	query := `
 		INSERT INTO vehicles (
 			vehicle_code, category_id, brand, model, year, chassis_number, engine_number,
 			plate_number, color, fuel_type, transmission, purchase_price, repair_cost,
-			hpp, selling_price, status, condition_notes, primary_photo, purchased_date
+			hpp, selling_price, status, condition_notes, primary_photo, purchased_date,
+			is_consignment
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
 		RETURNING id, created_at, updated_at
 	`
 	
-	err := r.db.QueryRowContext(ctx, query,
+	err := q.QueryRowxContext(ctx, query,
 		vehicle.VehicleCode, vehicle.CategoryID, vehicle.Brand, vehicle.Model, vehicle.Year,
 		vehicle.ChassisNumber, vehicle.EngineNumber, vehicle.PlateNumber, vehicle.Color,
 		vehicle.FuelType, vehicle.Transmission, vehicle.PurchasePrice, vehicle.RepairCost,
 		vehicle.HPP, vehicle.SellingPrice, vehicle.Status, vehicle.ConditionNotes,
-		vehicle.PrimaryPhoto, vehicle.PurchasedDate,
+		vehicle.PrimaryPhoto, vehicle.PurchasedDate, vehicle.IsConsignment,
 	).Scan(&vehicle.ID, &vehicle.CreatedAt, &vehicle.UpdatedAt)
 	
 	if err != nil {
@@ -50,7 +55,7 @@ func (r *vehicleRepository) GetByID(ctx context.Context, id int) (*domain.Vehicl
 		SELECT v.id, v.vehicle_code, v.category_id, v.brand, v.model, v.year,
 			   v.chassis_number, v.engine_number, v.plate_number, v.color, v.fuel_type,
 			   v.transmission, v.purchase_price, v.repair_cost, v.hpp, v.selling_price,
-			   v.status, v.condition_notes, v.primary_photo, v.purchased_date, v.sold_date,
+			   v.status, v.condition_notes, v.primary_photo, v.purchased_date, v.sold_date, v.is_consignment,
 			   v.deleted_at, v.deleted_by, v.created_at, v.updated_at,
 			   vc.id as "category.id", vc.name as "category.name", vc.description as "category.description"
 		FROM vehicles v
@@ -75,7 +80,7 @@ func (r *vehicleRepository) GetByVehicleCode(ctx context.Context, vehicleCode st
 		SELECT v.id, v.vehicle_code, v.category_id, v.brand, v.model, v.year,
 			   v.chassis_number, v.engine_number, v.plate_number, v.color, v.fuel_type,
 			   v.transmission, v.purchase_price, v.repair_cost, v.hpp, v.selling_price,
-			   v.status, v.condition_notes, v.primary_photo, v.purchased_date, v.sold_date,
+			   v.status, v.condition_notes, v.primary_photo, v.purchased_date, v.sold_date, v.is_consignment,
 			   v.deleted_at, v.deleted_by, v.created_at, v.updated_at,
 			   vc.id as "category.id", vc.name as "category.name", vc.description as "category.description"
 		FROM vehicles v
@@ -100,7 +105,7 @@ func (r *vehicleRepository) List(ctx context.Context, offset, limit int) ([]*dom
 		SELECT v.id, v.vehicle_code, v.category_id, v.brand, v.model, v.year,
 			   v.chassis_number, v.engine_number, v.plate_number, v.color, v.fuel_type,
 			   v.transmission, v.purchase_price, v.repair_cost, v.hpp, v.selling_price,
-			   v.status, v.condition_notes, v.primary_photo, v.purchased_date, v.sold_date,
+			   v.status, v.condition_notes, v.primary_photo, v.purchased_date, v.sold_date, v.is_consignment,
 			   v.deleted_at, v.deleted_by, v.created_at, v.updated_at,
 			   vc.id as "category.id", vc.name as "category.name", vc.description as "category.description"
 		FROM vehicles v
@@ -124,7 +129,7 @@ func (r *vehicleRepository) ListByStatus(ctx context.Context, status domain.Vehi
 		SELECT v.id, v.vehicle_code, v.category_id, v.brand, v.model, v.year,
 			   v.chassis_number, v.engine_number, v.plate_number, v.color, v.fuel_type,
 			   v.transmission, v.purchase_price, v.repair_cost, v.hpp, v.selling_price,
-			   v.status, v.condition_notes, v.primary_photo, v.purchased_date, v.sold_date,
+			   v.status, v.condition_notes, v.primary_photo, v.purchased_date, v.sold_date, v.is_consignment,
 			   v.deleted_at, v.deleted_by, v.created_at, v.updated_at,
 			   vc.id as "category.id", vc.name as "category.name", vc.description as "category.description"
 		FROM vehicles v
@@ -148,7 +153,7 @@ func (r *vehicleRepository) ListByCategory(ctx context.Context, categoryID int,
 		SELECT v.id, v.vehicle_code, v.category_id, v.brand, v.model, v.year,
 			   v.chassis_number, v.engine_number, v.plate_number, v.color, v.fuel_type,
 			   v.transmission, v.purchase_price, v.repair_cost, v.hpp, v.selling_price,
-			   v.status, v.condition_notes, v.primary_photo, v.purchased_date, v.sold_date,
+			   v.status, v.condition_notes, v.primary_photo, v.purchased_date, v.sold_date, v.is_consignment,
 			   v.deleted_at, v.deleted_by, v.created_at, v.updated_at,
 			   vc.id as "category.id", vc.name as "category.name", vc.description as "category.description"
 		FROM vehicles v
@@ -247,7 +252,7 @@ func (r *vehicleRepository) Search(ctx context.Context, query string, offset, li
 		SELECT v.id, v.vehicle_code, v.category_id, v.brand, v.model, v.year,
 			   v.chassis_number, v.engine_number, v.plate_number, v.color, v.fuel_type,
 			   v.transmission, v.purchase_price, v.repair_cost, v.hpp, v.selling_price,
-			   v.status, v.condition_notes, v.primary_photo, v.purchased_date, v.sold_date,
+			   v.status, v.condition_notes, v.primary_photo, v.purchased_date, v.sold_date, v.is_consignment,
 			   v.deleted_at, v.deleted_by, v.created_at, v.updated_at,
 			   vc.id as "category.id", vc.name as "category.name", vc.description as "category.description"
 		FROM vehicles v
@@ -313,4 +318,30 @@ func (r *vehicleRepository) UpdateStatus(ctx context.Context, id int, status dom
 	}
 	
 	return nil
-}
\ No newline at end of file
+}
+
+// UpdateCosts stores the rolled-up repair cost and HPP without touching the
+// rest of the vehicle
+func (r *vehicleRepository) UpdateCosts(ctx context.Context, id int, repairCost float64, hpp float64) error {
+	query := `
+		UPDATE vehicles
+		SET repair_cost = $2, hpp = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, repairCost, hpp)
+	if err != nil {
+		return fmt.Errorf("failed to update vehicle costs: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("vehicle not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,358 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type warrantyRepository struct {
+	db *sqlx.DB
+}
+
+// NewWarrantyRepository creates a new warranty repository
+func NewWarrantyRepository(db *sqlx.DB) WarrantyRepository {
+	return &warrantyRepository{db: db}
+}
+
+func (r *warrantyRepository) Create(ctx context.Context, warranty *domain.Warranty) error {
+	query := `
+		INSERT INTO warranties (
+			warranty_number, sales_invoice_id, vehicle_id, customer_id, duration_months,
+			mileage_limit, start_mileage, coverage, start_date, end_date, status,
+			notes, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		warranty.WarrantyNumber, warranty.SalesInvoiceID, warranty.VehicleID,
+		warranty.CustomerID, warranty.DurationMonths, warranty.MileageLimit,
+		warranty.StartMileage, warranty.Coverage, warranty.StartDate, warranty.EndDate,
+		warranty.Status, warranty.Notes, warranty.CreatedBy,
+	).Scan(&warranty.ID, &warranty.CreatedAt, &warranty.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create warranty: %w", err)
+	}
+
+	return nil
+}
+
+func (r *warrantyRepository) GetByID(ctx context.Context, id int) (*domain.Warranty, error) {
+	var warranty domain.Warranty
+	query := `
+		SELECT w.id, w.warranty_number, w.sales_invoice_id, w.vehicle_id, w.customer_id,
+			   w.duration_months, w.mileage_limit, w.start_mileage, w.coverage,
+			   w.start_date, w.end_date, w.status, w.notes, w.created_by,
+			   w.deleted_at, w.deleted_by, w.created_at, w.updated_at,
+			   -- Vehicle details
+			   v.id as "vehicle.id", v.vehicle_code as "vehicle.vehicle_code",
+			   v.brand as "vehicle.brand", v.model as "vehicle.model", v.year as "vehicle.year",
+			   v.plate_number as "vehicle.plate_number", v.chassis_number as "vehicle.chassis_number",
+			   -- Customer details
+			   c.id as "customer.id", c.customer_code as "customer.customer_code",
+			   c.name as "customer.name", c.phone as "customer.phone"
+		FROM warranties w
+		JOIN vehicles v ON w.vehicle_id = v.id
+		JOIN customers c ON w.customer_id = c.id
+		WHERE w.id = $1 AND w.deleted_at IS NULL
+	`
+
+	err := r.db.GetContext(ctx, &warranty, query, id)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get warranty: %w", err)
+	}
+
+	return &warranty, nil
+}
+
+func (r *warrantyRepository) GetBySalesInvoiceID(ctx context.Context, salesInvoiceID int) (*domain.Warranty, error) {
+	var warranty domain.Warranty
+	query := `
+		SELECT w.id, w.warranty_number, w.sales_invoice_id, w.vehicle_id, w.customer_id,
+			   w.duration_months, w.mileage_limit, w.start_mileage, w.coverage,
+			   w.start_date, w.end_date, w.status, w.notes, w.created_by,
+			   w.deleted_at, w.deleted_by, w.created_at, w.updated_at
+		FROM warranties w
+		WHERE w.sales_invoice_id = $1 AND w.deleted_at IS NULL
+	`
+
+	err := r.db.GetContext(ctx, &warranty, query, salesInvoiceID)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get warranty by sales invoice: %w", err)
+	}
+
+	return &warranty, nil
+}
+
+func (r *warrantyRepository) List(ctx context.Context, offset, limit int) ([]*domain.Warranty, error) {
+	var warranties []*domain.Warranty
+	query := `
+		SELECT w.id, w.warranty_number, w.sales_invoice_id, w.vehicle_id, w.customer_id,
+			   w.duration_months, w.mileage_limit, w.start_mileage, w.coverage,
+			   w.start_date, w.end_date, w.status, w.notes, w.created_by,
+			   w.deleted_at, w.deleted_by, w.created_at, w.updated_at,
+			   -- Vehicle details
+			   v.vehicle_code as "vehicle.vehicle_code", v.brand as "vehicle.brand",
+			   v.model as "vehicle.model", v.plate_number as "vehicle.plate_number",
+			   -- Customer details
+			   c.name as "customer.name", c.phone as "customer.phone"
+		FROM warranties w
+		JOIN vehicles v ON w.vehicle_id = v.id
+		JOIN customers c ON w.customer_id = c.id
+		WHERE w.deleted_at IS NULL
+		ORDER BY w.created_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	err := r.db.SelectContext(ctx, &warranties, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list warranties: %w", err)
+	}
+
+	return warranties, nil
+}
+
+func (r *warrantyRepository) SearchByVehicle(ctx context.Context, plateNumber, chassisNumber string) ([]*domain.Warranty, error) {
+	var warranties []*domain.Warranty
+	query := `
+		SELECT w.id, w.warranty_number, w.sales_invoice_id, w.vehicle_id, w.customer_id,
+			   w.duration_months, w.mileage_limit, w.start_mileage, w.coverage,
+			   w.start_date, w.end_date, w.status, w.notes, w.created_by,
+			   w.deleted_at, w.deleted_by, w.created_at, w.updated_at,
+			   -- Vehicle details
+			   v.id as "vehicle.id", v.vehicle_code as "vehicle.vehicle_code",
+			   v.brand as "vehicle.brand", v.model as "vehicle.model", v.year as "vehicle.year",
+			   v.plate_number as "vehicle.plate_number", v.chassis_number as "vehicle.chassis_number",
+			   -- Customer details
+			   c.id as "customer.id", c.name as "customer.name", c.phone as "customer.phone"
+		FROM warranties w
+		JOIN vehicles v ON w.vehicle_id = v.id
+		JOIN customers c ON w.customer_id = c.id
+		WHERE w.deleted_at IS NULL
+		  AND (($1::text <> '' AND REPLACE(UPPER(v.plate_number), ' ', '') = REPLACE(UPPER($1::text), ' ', ''))
+		    OR ($2::text <> '' AND UPPER(v.chassis_number) = UPPER($2::text)))
+		ORDER BY w.start_date DESC
+	`
+
+	err := r.db.SelectContext(ctx, &warranties, query, plateNumber, chassisNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search warranties by vehicle: %w", err)
+	}
+
+	return warranties, nil
+}
+
+func (r *warrantyRepository) UpdateStatus(ctx context.Context, id int, status domain.WarrantyStatus) error {
+	query := `
+		UPDATE warranties SET
+			status = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, status)
+	if err != nil {
+		return fmt.Errorf("failed to update warranty status: %w", err)
+	}
+
+	return nil
+}
+
+func (r *warrantyRepository) ExpireOverdue(ctx context.Context) (int, error) {
+	query := `
+		UPDATE warranties SET
+			status = 'expired', updated_at = CURRENT_TIMESTAMP
+		WHERE status = 'active' AND end_date < CURRENT_DATE AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire overdue warranties: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+func (r *warrantyRepository) Count(ctx context.Context) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM warranties WHERE deleted_at IS NULL`
+
+	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count warranties: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *warrantyRepository) GenerateWarrantyNumber(ctx context.Context) (string, error) {
+	var count int
+	today := time.Now().Format("20060102")
+
+	query := `
+		SELECT COUNT(*) FROM warranties
+		WHERE warranty_number LIKE $1
+	`
+
+	err := r.db.QueryRowContext(ctx, query, fmt.Sprintf("WAR-%s%%", today)).Scan(&count)
+	if err != nil {
+		return "", fmt.Errorf("failed to count warranties for number generation: %w", err)
+	}
+
+	warrantyNumber := fmt.Sprintf("WAR-%s-%04d", today, count+1)
+	return warrantyNumber, nil
+}
+
+type warrantyClaimRepository struct {
+	db *sqlx.DB
+}
+
+// NewWarrantyClaimRepository creates a new warranty claim repository
+func NewWarrantyClaimRepository(db *sqlx.DB) WarrantyClaimRepository {
+	return &warrantyClaimRepository{db: db}
+}
+
+func (r *warrantyClaimRepository) Create(ctx context.Context, claim *domain.WarrantyClaim) error {
+	query := `
+		INSERT INTO warranty_claims (
+			claim_number, warranty_id, work_order_id, claim_date, complaint,
+			current_mileage, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		claim.ClaimNumber, claim.WarrantyID, claim.WorkOrderID, claim.ClaimDate,
+		claim.Complaint, claim.CurrentMileage, claim.CreatedBy,
+	).Scan(&claim.ID, &claim.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create warranty claim: %w", err)
+	}
+
+	return nil
+}
+
+// CreateWithWorkOrder saves a claim together with the warranty work order it
+// opens, so neither exists without the other
+func (r *warrantyClaimRepository) CreateWithWorkOrder(ctx context.Context, claim *domain.WarrantyClaim, workOrder *domain.WorkOrder) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertWorkOrder(ctx, tx, workOrder); err != nil {
+		return err
+	}
+	claim.WorkOrderID = &workOrder.ID
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO warranty_claims (
+			claim_number, warranty_id, work_order_id, claim_date, complaint,
+			current_mileage, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`,
+		claim.ClaimNumber, claim.WarrantyID, claim.WorkOrderID, claim.ClaimDate,
+		claim.Complaint, claim.CurrentMileage, claim.CreatedBy,
+	).Scan(&claim.ID, &claim.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create warranty claim: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit warranty claim: %w", err)
+	}
+
+	return nil
+}
+
+func (r *warrantyClaimRepository) ListByWarrantyID(ctx context.Context, warrantyID int) ([]*domain.WarrantyClaim, error) {
+	var claims []*domain.WarrantyClaim
+	query := `
+		SELECT wc.id, wc.claim_number, wc.warranty_id, wc.work_order_id, wc.claim_date,
+			   wc.complaint, wc.current_mileage, wc.created_by,
+			   wc.deleted_at, wc.deleted_by, wc.created_at,
+			   -- Work order details
+			   wo.id as "work_order.id", wo.wo_number as "work_order.wo_number",
+			   wo.status as "work_order.status", wo.total_parts_cost as "work_order.total_parts_cost",
+			   wo.labor_cost as "work_order.labor_cost", wo.total_cost as "work_order.total_cost"
+		FROM warranty_claims wc
+		JOIN work_orders wo ON wc.work_order_id = wo.id
+		WHERE wc.warranty_id = $1 AND wc.deleted_at IS NULL
+		ORDER BY wc.claim_date DESC
+	`
+
+	err := r.db.SelectContext(ctx, &claims, query, warrantyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list warranty claims: %w", err)
+	}
+
+	return claims, nil
+}
+
+func (r *warrantyClaimRepository) ListByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*domain.WarrantyClaim, error) {
+	var claims []*domain.WarrantyClaim
+	query := `
+		SELECT wc.id, wc.claim_number, wc.warranty_id, wc.work_order_id, wc.claim_date,
+			   wc.complaint, wc.current_mileage, wc.created_by,
+			   wc.deleted_at, wc.deleted_by, wc.created_at,
+			   -- Warranty details
+			   w.warranty_number as "warranty.warranty_number", w.coverage as "warranty.coverage",
+			   -- Work order details
+			   wo.id as "work_order.id", wo.wo_number as "work_order.wo_number",
+			   wo.status as "work_order.status", wo.total_parts_cost as "work_order.total_parts_cost",
+			   wo.labor_cost as "work_order.labor_cost", wo.total_cost as "work_order.total_cost"
+		FROM warranty_claims wc
+		JOIN warranties w ON wc.warranty_id = w.id
+		JOIN work_orders wo ON wc.work_order_id = wo.id
+		WHERE wc.deleted_at IS NULL
+		  AND wc.claim_date >= $1
+		  AND wc.claim_date <= $2
+		ORDER BY wc.claim_date DESC
+	`
+
+	err := r.db.SelectContext(ctx, &claims, query, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list warranty claims by date range: %w", err)
+	}
+
+	return claims, nil
+}
+
+func (r *warrantyClaimRepository) GenerateClaimNumber(ctx context.Context) (string, error) {
+	var count int
+	today := time.Now().Format("20060102")
+
+	query := `
+		SELECT COUNT(*) FROM warranty_claims
+		WHERE claim_number LIKE $1
+	`
+
+	err := r.db.QueryRowContext(ctx, query, fmt.Sprintf("WCL-%s%%", today)).Scan(&count)
+	if err != nil {
+		return "", fmt.Errorf("failed to count warranty claims for number generation: %w", err)
+	}
+
+	claimNumber := fmt.Sprintf("WCL-%s-%04d", today, count+1)
+	return claimNumber, nil
+}
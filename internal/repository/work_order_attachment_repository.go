@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type workOrderAttachmentRepository struct {
+	db *sqlx.DB
+}
+
+// NewWorkOrderAttachmentRepository creates a new work order attachment repository
+func NewWorkOrderAttachmentRepository(db *sqlx.DB) WorkOrderAttachmentRepository {
+	return &workOrderAttachmentRepository{db: db}
+}
+
+const workOrderAttachmentColumns = `
+	a.id, a.work_order_id, a.work_order_task_id, a.stage, a.file_type, a.file_path,
+	a.caption, a.uploaded_by, a.deleted_at, a.deleted_by, a.created_at,
+	-- Uploader details
+	u.id as "uploader.id", u.username as "uploader.username", u.full_name as "uploader.full_name"
+`
+
+func (r *workOrderAttachmentRepository) Create(ctx context.Context, attachment *domain.WorkOrderAttachment) error {
+	query := `
+		INSERT INTO work_order_attachments (
+			work_order_id, work_order_task_id, stage, file_type, file_path, caption, uploaded_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		attachment.WorkOrderID, attachment.WorkOrderTaskID, attachment.Stage, attachment.FileType,
+		attachment.FilePath, attachment.Caption, attachment.UploadedBy,
+	).Scan(&attachment.ID, &attachment.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create work order attachment: %w", err)
+	}
+
+	return nil
+}
+
+func (r *workOrderAttachmentRepository) GetByID(ctx context.Context, id int) (*domain.WorkOrderAttachment, error) {
+	var attachment domain.WorkOrderAttachment
+	query := `
+		SELECT ` + workOrderAttachmentColumns + `
+		FROM work_order_attachments a
+		JOIN users u ON a.uploaded_by = u.id
+		WHERE a.id = $1 AND a.deleted_at IS NULL
+	`
+
+	err := r.db.GetContext(ctx, &attachment, query, id)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get work order attachment: %w", err)
+	}
+
+	return &attachment, nil
+}
+
+// ListByWorkOrderID returns the attachments in before, during, after order
+func (r *workOrderAttachmentRepository) ListByWorkOrderID(ctx context.Context, workOrderID int) ([]*domain.WorkOrderAttachment, error) {
+	var attachments []*domain.WorkOrderAttachment
+	query := `
+		SELECT ` + workOrderAttachmentColumns + `
+		FROM work_order_attachments a
+		JOIN users u ON a.uploaded_by = u.id
+		WHERE a.work_order_id = $1 AND a.deleted_at IS NULL
+		ORDER BY CASE a.stage WHEN 'before' THEN 1 WHEN 'during' THEN 2 ELSE 3 END, a.created_at, a.id
+	`
+
+	err := r.db.SelectContext(ctx, &attachments, query, workOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list work order attachments: %w", err)
+	}
+
+	return attachments, nil
+}
+
+func (r *workOrderAttachmentRepository) Delete(ctx context.Context, id int, deletedBy int) error {
+	query := `
+		UPDATE work_order_attachments SET
+			deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, deletedBy)
+	if err != nil {
+		return fmt.Errorf("failed to delete work order attachment: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete work order attachment: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("work order attachment not found or already deleted")
+	}
+
+	return nil
+}
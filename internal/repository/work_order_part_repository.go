@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"math"
 	"pos-final/internal/domain"
 	"time"
 
@@ -22,16 +23,17 @@ func (r *workOrderPartRepository) Create(ctx context.Context, workOrderPart *dom
 	query := `
 		INSERT INTO work_order_parts (
 			work_order_id, spare_part_id, quantity_used, unit_cost,
-			total_cost, used_by, usage_date, used_at
+			total_cost, used_by, usage_date, used_at, unit_price, location_id, service_kit_id
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id
 	`
 	
 	err := r.db.QueryRowContext(ctx, query,
 		workOrderPart.WorkOrderID, workOrderPart.SparePartID, workOrderPart.QuantityUsed,
 		workOrderPart.UnitCost, workOrderPart.TotalCost, workOrderPart.UsedBy,
-		workOrderPart.UsageDate, workOrderPart.UsedAt,
+		workOrderPart.UsageDate, workOrderPart.UsedAt, workOrderPart.UnitPrice, workOrderPart.LocationID,
+		workOrderPart.ServiceKitID,
 	).Scan(&workOrderPart.ID)
 	
 	if err != nil {
@@ -46,7 +48,7 @@ func (r *workOrderPartRepository) GetByID(ctx context.Context, id int) (*domain.
 	query := `
 		SELECT wop.id, wop.work_order_id, wop.spare_part_id, wop.quantity_used,
 			   wop.unit_cost, wop.total_cost, wop.used_by, wop.usage_date,
-			   wop.deleted_at, wop.deleted_by, wop.used_at,
+			   wop.deleted_at, wop.deleted_by, wop.used_at, wop.quantity_returned, wop.unit_price, wop.location_id, wop.service_kit_id,
 			   -- Spare part details
 			   sp.id as "spare_part.id", sp.part_code as "spare_part.part_code",
 			   sp.name as "spare_part.name", sp.brand as "spare_part.brand",
@@ -55,8 +57,8 @@ func (r *workOrderPartRepository) GetByID(ctx context.Context, id int) (*domain.
 			   u.id as "user.id", u.username as "user.username",
 			   u.full_name as "user.full_name"
 		FROM work_order_parts wop
-		LEFT JOIN spare_parts sp ON wop.spare_part_id = sp.id AND sp.deleted_at IS NULL
-		LEFT JOIN users u ON wop.used_by = u.id AND u.deleted_at IS NULL
+		JOIN spare_parts sp ON wop.spare_part_id = sp.id
+		JOIN users u ON wop.used_by = u.id
 		WHERE wop.id = $1 AND wop.deleted_at IS NULL
 	`
 	
@@ -73,7 +75,7 @@ func (r *workOrderPartRepository) ListByWorkOrderID(ctx context.Context, workOrd
 	query := `
 		SELECT wop.id, wop.work_order_id, wop.spare_part_id, wop.quantity_used,
 			   wop.unit_cost, wop.total_cost, wop.used_by, wop.usage_date,
-			   wop.deleted_at, wop.deleted_by, wop.used_at,
+			   wop.deleted_at, wop.deleted_by, wop.used_at, wop.quantity_returned, wop.unit_price, wop.location_id, wop.service_kit_id,
 			   -- Spare part details
 			   sp.id as "spare_part.id", sp.part_code as "spare_part.part_code",
 			   sp.name as "spare_part.name", sp.brand as "spare_part.brand",
@@ -82,8 +84,8 @@ func (r *workOrderPartRepository) ListByWorkOrderID(ctx context.Context, workOrd
 			   u.id as "user.id", u.username as "user.username",
 			   u.full_name as "user.full_name"
 		FROM work_order_parts wop
-		LEFT JOIN spare_parts sp ON wop.spare_part_id = sp.id AND sp.deleted_at IS NULL
-		LEFT JOIN users u ON wop.used_by = u.id AND u.deleted_at IS NULL
+		JOIN spare_parts sp ON wop.spare_part_id = sp.id
+		JOIN users u ON wop.used_by = u.id
 		WHERE wop.work_order_id = $1 AND wop.deleted_at IS NULL
 		ORDER BY wop.used_at DESC
 	`
@@ -93,20 +95,78 @@ func (r *workOrderPartRepository) ListByWorkOrderID(ctx context.Context, workOrd
 		return nil, fmt.Errorf("failed to list work order parts by work order ID: %w", err)
 	}
 	
+	if err := r.loadTracking(ctx, workOrderID, workOrderParts); err != nil {
+		return nil, err
+	}
+	
 	return workOrderParts, nil
 }
 
+// loadTracking attaches the batches each line was taken from and the serial
+// numbers still issued on it
+func (r *workOrderPartRepository) loadTracking(ctx context.Context, workOrderID int, workOrderParts []*domain.WorkOrderPart) error {
+	if len(workOrderParts) == 0 {
+		return nil
+	}
+
+	var batches []*domain.WorkOrderPartBatch
+	err := r.db.SelectContext(ctx, &batches, `
+		SELECT wopb.id, wopb.work_order_part_id, wopb.batch_id, wopb.quantity, wopb.quantity_returned,
+			   spb.batch_number, spb.expiry_date
+		FROM work_order_part_batches wopb
+		JOIN work_order_parts wop ON wopb.work_order_part_id = wop.id
+		JOIN spare_part_batches spb ON wopb.batch_id = spb.id
+		WHERE wop.work_order_id = $1
+		ORDER BY wopb.id
+	`, workOrderID)
+	if err != nil {
+		return fmt.Errorf("failed to list work order part batches: %w", err)
+	}
+
+	var serials []struct {
+		WorkOrderPartID int    `db:"work_order_part_id"`
+		SerialNumber    string `db:"serial_number"`
+	}
+	err = r.db.SelectContext(ctx, &serials, `
+		SELECT sn.work_order_part_id, sn.serial_number
+		FROM spare_part_serials sn
+		JOIN work_order_parts wop ON sn.work_order_part_id = wop.id
+		WHERE wop.work_order_id = $1 AND sn.status = $2
+		ORDER BY sn.serial_number
+	`, workOrderID, domain.SerialStatusIssued)
+	if err != nil {
+		return fmt.Errorf("failed to list work order part serials: %w", err)
+	}
+
+	byID := make(map[int]*domain.WorkOrderPart, len(workOrderParts))
+	for _, part := range workOrderParts {
+		byID[part.ID] = part
+	}
+	for _, batch := range batches {
+		if part, ok := byID[batch.WorkOrderPartID]; ok {
+			part.Batches = append(part.Batches, batch)
+		}
+	}
+	for _, serial := range serials {
+		if part, ok := byID[serial.WorkOrderPartID]; ok {
+			part.SerialNumbers = append(part.SerialNumbers, serial.SerialNumber)
+		}
+	}
+
+	return nil
+}
+
 func (r *workOrderPartRepository) ListBySparePartID(ctx context.Context, sparePartID int, offset, limit int) ([]*domain.WorkOrderPart, error) {
 	var workOrderParts []*domain.WorkOrderPart
 	query := `
 		SELECT wop.id, wop.work_order_id, wop.spare_part_id, wop.quantity_used,
 			   wop.unit_cost, wop.total_cost, wop.used_by, wop.usage_date,
-			   wop.deleted_at, wop.deleted_by, wop.used_at,
+			   wop.deleted_at, wop.deleted_by, wop.used_at, wop.quantity_returned, wop.unit_price, wop.location_id, wop.service_kit_id,
 			   -- User details
 			   u.id as "user.id", u.username as "user.username",
 			   u.full_name as "user.full_name"
 		FROM work_order_parts wop
-		LEFT JOIN users u ON wop.used_by = u.id AND u.deleted_at IS NULL
+		JOIN users u ON wop.used_by = u.id
 		WHERE wop.spare_part_id = $1 AND wop.deleted_at IS NULL
 		ORDER BY wop.used_at DESC
 		LIMIT $2 OFFSET $3
@@ -176,4 +236,339 @@ func (r *workOrderPartRepository) GetDailyUsage(ctx context.Context, date time.T
 	}
 	
 	return count, totalValue, nil
-}
\ No newline at end of file
+}
+
+// Return puts unused quantity of a work order part back into stock in one
+// transaction: the usage line's returned quantity and net cost, the return
+// record, a cost layer at the original unit cost, the spare part stock and
+// cost price, the batches and serials it was issued from, and an inbound
+// stock movement.
+func (r *workOrderPartRepository) Return(ctx context.Context, partReturn *domain.WorkOrderPartReturn, costingMethod domain.CostingMethod) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Parts go back to the location they were issued from
+	var locationID *int
+	err = tx.QueryRowContext(ctx, `
+		UPDATE work_order_parts SET
+			quantity_returned = quantity_returned + $2,
+			total_cost = ROUND(unit_cost * (quantity_used - quantity_returned - $2), 2)
+		WHERE id = $1 AND deleted_at IS NULL
+		  AND quantity_returned + $2 <= quantity_used
+		RETURNING location_id
+	`, partReturn.WorkOrderPartID, partReturn.Quantity).Scan(&locationID)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return fmt.Errorf("returned quantity exceeds quantity still used")
+		}
+		return fmt.Errorf("failed to update returned quantity: %w", err)
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO work_order_part_returns (
+			work_order_part_id, work_order_id, spare_part_id, quantity,
+			unit_cost, total_cost, reason, returned_by, returned_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`,
+		partReturn.WorkOrderPartID, partReturn.WorkOrderID, partReturn.SparePartID, partReturn.Quantity,
+		partReturn.UnitCost, partReturn.TotalCost, partReturn.Reason, partReturn.ReturnedBy, partReturn.ReturnedAt,
+	).Scan(&partReturn.ID, &partReturn.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create work order part return: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO stock_cost_layers (
+			spare_part_id, reference_type, reference_id, received_date,
+			quantity_received, quantity_remaining, unit_cost
+		)
+		VALUES ($1, $2, $3, $4, $5, $5, $6)
+	`, partReturn.SparePartID, domain.ReferenceTypeWorkOrder, partReturn.WorkOrderID, partReturn.ReturnedAt, partReturn.Quantity, partReturn.UnitCost)
+	if err != nil {
+		return fmt.Errorf("failed to create stock cost layer: %w", err)
+	}
+
+	var costQuery string
+	if costingMethod == domain.CostingMethodFIFO {
+		// Cost price = value of open layers per unit
+		costQuery = `
+			UPDATE spare_parts SET
+				stock_quantity = stock_quantity + $2,
+				cost_price = COALESCE((
+					SELECT ROUND(SUM(quantity_remaining * unit_cost) / NULLIF(SUM(quantity_remaining), 0), 2)
+					FROM stock_cost_layers
+					WHERE spare_part_id = $1 AND quantity_remaining > 0
+				), $3),
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+		`
+	} else {
+		// Moving weighted average over stock on hand
+		costQuery = `
+			UPDATE spare_parts SET
+				stock_quantity = stock_quantity + $2,
+				cost_price = CASE
+					WHEN GREATEST(stock_quantity, 0) + $2 > 0 THEN
+						ROUND((GREATEST(stock_quantity, 0) * cost_price + $2 * $3) / (GREATEST(stock_quantity, 0) + $2), 2)
+					ELSE $3
+				END,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+		`
+	}
+
+	_, err = tx.ExecContext(ctx, costQuery, partReturn.SparePartID, partReturn.Quantity, partReturn.UnitCost)
+	if err != nil {
+		return fmt.Errorf("failed to update spare part stock: %w", err)
+	}
+
+	resolvedLocationID, err := adjustLocationStock(ctx, tx, partReturn.SparePartID, locationID, partReturn.Quantity)
+	if err != nil {
+		return err
+	}
+
+	if err := returnWorkOrderPartBatches(ctx, tx, partReturn, resolvedLocationID); err != nil {
+		return err
+	}
+	if err := returnSerials(ctx, tx, partReturn, resolvedLocationID, partReturn.SerialNumbers); err != nil {
+		return err
+	}
+
+	notes := fmt.Sprintf("Returned from work order: %s", partReturn.Reason)
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO stock_movements (
+			spare_part_id, movement_type, quantity, reference_type, reference_id, location_id,
+			notes, created_by, movement_date, unit_cost, total_value
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`,
+		partReturn.SparePartID, domain.MovementTypeIn, partReturn.Quantity, domain.ReferenceTypeWorkOrder,
+		partReturn.WorkOrderPartID, resolvedLocationID, notes, partReturn.ReturnedBy, partReturn.ReturnedAt, partReturn.UnitCost, partReturn.TotalCost,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create stock movement: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// IssueBatch issues several parts to a work order in one transaction. If any
+// line is short of stock nothing is issued.
+func (r *workOrderPartRepository) IssueBatch(ctx context.Context, workOrderParts []*domain.WorkOrderPart, costingMethod domain.CostingMethod) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := issueWorkOrderParts(ctx, tx, workOrderParts, costingMethod); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// IssueServiceKit applies a service kit in one transaction: its parts are
+// issued, its labor task is added and its labor price is added to the work
+// order. If any step fails nothing is applied.
+func (r *workOrderPartRepository) IssueServiceKit(ctx context.Context, application *domain.ServiceKitApplication, costingMethod domain.CostingMethod) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := issueWorkOrderParts(ctx, tx, application.Parts, costingMethod); err != nil {
+		return err
+	}
+
+	if application.Task != nil {
+		if err := insertWorkOrderTask(ctx, tx, application.Task); err != nil {
+			return err
+		}
+	}
+
+	if application.LaborPriceAdded > 0 {
+		if err := addWorkOrderLaborPrice(ctx, tx, application.WorkOrderID, application.LaborPriceAdded); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// IssuePartRequest closes an approved part request and issues its parts in
+// one transaction. It fails without issuing if the request was already
+// reviewed.
+func (r *workOrderPartRepository) IssuePartRequest(ctx context.Context, request *domain.PartRequest, workOrderPart *domain.WorkOrderPart, costingMethod domain.CostingMethod) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := reviewPartRequest(ctx, tx, request); err != nil {
+		return err
+	}
+
+	if err := issueWorkOrderParts(ctx, tx, []*domain.WorkOrderPart{workOrderPart}, costingMethod); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// issueWorkOrderParts issues parts to a work order: for every line the
+// location and total stock are reduced, cost layers and stock batches are
+// consumed, serial numbers are marked issued, and the usage line is recorded
+// at the cost of the configured method with an outbound stock movement.
+func issueWorkOrderParts(ctx context.Context, tx *sqlx.Tx, workOrderParts []*domain.WorkOrderPart, costingMethod domain.CostingMethod) error {
+	for _, part := range workOrderParts {
+		var costPrice float64
+		var stockQuantity int
+		err := tx.QueryRowContext(ctx, `
+			SELECT cost_price, stock_quantity FROM spare_parts
+			WHERE id = $1 AND deleted_at IS NULL
+			FOR UPDATE
+		`, part.SparePartID).Scan(&costPrice, &stockQuantity)
+		if err != nil {
+			if IsNoRowsError(err) {
+				return fmt.Errorf("spare part %d not found", part.SparePartID)
+			}
+			return fmt.Errorf("failed to lock spare part: %w", err)
+		}
+		if stockQuantity < part.QuantityUsed {
+			return fmt.Errorf("insufficient stock for spare part %d: available %d, requested %d", part.SparePartID, stockQuantity, part.QuantityUsed)
+		}
+
+		resolvedLocationID, err := adjustLocationStock(ctx, tx, part.SparePartID, part.LocationID, -part.QuantityUsed)
+		if err != nil {
+			return err
+		}
+		part.LocationID = &resolvedLocationID
+
+		// Batch-tracked parts are picked first expiry first, skipping expired stock
+		part.Batches, err = takeStockBatches(ctx, tx, part.SparePartID, resolvedLocationID, part.QuantityUsed, false)
+		if err != nil {
+			return err
+		}
+
+		layerCost, covered, err := consumeCostLayers(ctx, tx, part.SparePartID, part.QuantityUsed)
+		if err != nil {
+			return err
+		}
+
+		part.UnitCost = costPrice
+		part.TotalCost = costPrice * float64(part.QuantityUsed)
+		costQuery := `
+			UPDATE spare_parts SET
+				stock_quantity = stock_quantity - $2, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+		`
+		if costingMethod == domain.CostingMethodFIFO {
+			// Charged at the consumed layers, cost price = value of open layers per unit
+			part.TotalCost = math.Round((layerCost+float64(part.QuantityUsed-covered)*costPrice)*100) / 100
+			part.UnitCost = math.Round(part.TotalCost/float64(part.QuantityUsed)*100) / 100
+			costQuery = `
+				UPDATE spare_parts SET
+					stock_quantity = stock_quantity - $2,
+					cost_price = COALESCE((
+						SELECT ROUND(SUM(quantity_remaining * unit_cost) / NULLIF(SUM(quantity_remaining), 0), 2)
+						FROM stock_cost_layers
+						WHERE spare_part_id = $1 AND quantity_remaining > 0
+					), cost_price),
+					updated_at = CURRENT_TIMESTAMP
+				WHERE id = $1
+			`
+		}
+
+		if _, err := tx.ExecContext(ctx, costQuery, part.SparePartID, part.QuantityUsed); err != nil {
+			return fmt.Errorf("failed to update spare part stock: %w", err)
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO work_order_parts (
+				work_order_id, spare_part_id, quantity_used, unit_cost,
+				total_cost, used_by, usage_date, used_at, unit_price, location_id, service_kit_id
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			RETURNING id
+		`,
+			part.WorkOrderID, part.SparePartID, part.QuantityUsed, part.UnitCost,
+			part.TotalCost, part.UsedBy, part.UsageDate, part.UsedAt, part.UnitPrice, part.LocationID, part.ServiceKitID,
+		).Scan(&part.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create work order part: %w", err)
+		}
+
+		if err := recordWorkOrderPartBatches(ctx, tx, part.ID, part.Batches); err != nil {
+			return err
+		}
+		if err := issueSerials(ctx, tx, part); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO stock_movements (
+				spare_part_id, movement_type, quantity, reference_type, reference_id, location_id,
+				notes, created_by, movement_date, unit_cost, total_value
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		`,
+			part.SparePartID, domain.MovementTypeOut, part.QuantityUsed, domain.ReferenceTypeWorkOrder,
+			part.ID, part.LocationID, "Issued to work order", part.UsedBy, part.UsedAt, part.UnitCost, part.TotalCost,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create stock movement: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *workOrderPartRepository) ListReturnsByWorkOrderID(ctx context.Context, workOrderID int) ([]*domain.WorkOrderPartReturn, error) {
+	var returns []*domain.WorkOrderPartReturn
+	query := `
+		SELECT ret.id, ret.work_order_part_id, ret.work_order_id, ret.spare_part_id, ret.quantity,
+			   ret.unit_cost, ret.total_cost, ret.reason, ret.returned_by, ret.returned_at, ret.created_at,
+			   -- Spare part details
+			   sp.id as "spare_part.id", sp.part_code as "spare_part.part_code",
+			   sp.name as "spare_part.name", sp.unit as "spare_part.unit",
+			   -- User details
+			   u.id as "returned_by_user.id", u.username as "returned_by_user.username",
+			   u.full_name as "returned_by_user.full_name"
+		FROM work_order_part_returns ret
+		JOIN spare_parts sp ON ret.spare_part_id = sp.id
+		JOIN users u ON ret.returned_by = u.id
+		WHERE ret.work_order_id = $1
+		ORDER BY ret.returned_at, ret.id
+	`
+
+	err := r.db.SelectContext(ctx, &returns, query, workOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list work order part returns: %w", err)
+	}
+
+	return returns, nil
+}
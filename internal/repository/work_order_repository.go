@@ -19,21 +19,31 @@ func NewWorkOrderRepository(db *sqlx.DB) WorkOrderRepository {
 }
 
 func (r *workOrderRepository) Create(ctx context.Context, workOrder *domain.WorkOrder) error {
+	return insertWorkOrder(ctx, r.db, workOrder)
+}
+
+// insertWorkOrder saves a new work order through the database or the
+// caller's transaction
+func insertWorkOrder(ctx context.Context, q sqlx.QueryerContext, workOrder *domain.WorkOrder) error {
 	query := `
 		INSERT INTO work_orders (
 			wo_number, vehicle_id, description, assigned_mechanic_id, status,
 			progress_percentage, total_parts_cost, labor_cost, total_cost,
-			notes, created_by, started_at, completed_at
+			notes, created_by, started_at, completed_at, is_warranty, assignment_reason,
+			order_type, customer_id, customer_vehicle_id, labor_price, sublet_cost
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
 		RETURNING id, created_at, updated_at
 	`
 	
-	err := r.db.QueryRowContext(ctx, query,
+	err := q.QueryRowxContext(ctx, query,
 		workOrder.WONumber, workOrder.VehicleID, workOrder.Description,
 		workOrder.AssignedMechanicID, workOrder.Status, workOrder.ProgressPercentage,
 		workOrder.TotalPartsCost, workOrder.LaborCost, workOrder.TotalCost,
 		workOrder.Notes, workOrder.CreatedBy, workOrder.StartedAt, workOrder.CompletedAt,
+		workOrder.IsWarranty, workOrder.AssignmentReason,
+		workOrder.OrderType, workOrder.CustomerID, workOrder.CustomerVehicleID, workOrder.LaborPrice,
+		workOrder.SubletCost,
 	).Scan(&workOrder.ID, &workOrder.CreatedAt, &workOrder.UpdatedAt)
 	
 	if err != nil {
@@ -47,13 +57,14 @@ func (r *workOrderRepository) GetByID(ctx context.Context, id int) (*domain.Work
 	var workOrder domain.WorkOrder
 	query := `
 		SELECT wo.id, wo.wo_number, wo.vehicle_id, wo.description, wo.assigned_mechanic_id,
-			   wo.status, wo.progress_percentage, wo.total_parts_cost, wo.labor_cost,
+			   wo.status, wo.progress_percentage, wo.total_parts_cost, wo.labor_cost, wo.sublet_cost,
 			   wo.total_cost, wo.notes, wo.created_by, wo.started_at, wo.completed_at,
-			   wo.deleted_at, wo.deleted_by, wo.created_at, wo.updated_at,
+			   wo.is_warranty, wo.order_type, wo.customer_id, wo.customer_vehicle_id, wo.labor_price,
+			   wo.estimated_completion_at, wo.assignment_reason, wo.deleted_at, wo.deleted_by, wo.created_at, wo.updated_at,
 			   -- Vehicle details
-			   v.id as "vehicle.id", v.vehicle_code as "vehicle.vehicle_code",
-			   v.brand as "vehicle.brand", v.model as "vehicle.model",
-			   v.year as "vehicle.year", v.status as "vehicle.status",
+			   COALESCE(v.id, 0) as "vehicle.id", COALESCE(v.vehicle_code, '') as "vehicle.vehicle_code",
+			   COALESCE(v.brand, '') as "vehicle.brand", COALESCE(v.model, '') as "vehicle.model",
+			   COALESCE(v.year, 0) as "vehicle.year", v.status as "vehicle.status",
 			   -- Assigned mechanic details
 			   m.id as "assigned_mechanic.id", m.username as "assigned_mechanic.username",
 			   m.full_name as "assigned_mechanic.full_name", m.role as "assigned_mechanic.role",
@@ -71,6 +82,7 @@ func (r *workOrderRepository) GetByID(ctx context.Context, id int) (*domain.Work
 	if err != nil {
 		return nil, fmt.Errorf("failed to get work order: %w", err)
 	}
+	detachMissingVehicle(&workOrder)
 	
 	return &workOrder, nil
 }
@@ -79,9 +91,10 @@ func (r *workOrderRepository) GetByWONumber(ctx context.Context, woNumber string
 	var workOrder domain.WorkOrder
 	query := `
 		SELECT wo.id, wo.wo_number, wo.vehicle_id, wo.description, wo.assigned_mechanic_id,
-			   wo.status, wo.progress_percentage, wo.total_parts_cost, wo.labor_cost,
+			   wo.status, wo.progress_percentage, wo.total_parts_cost, wo.labor_cost, wo.sublet_cost,
 			   wo.total_cost, wo.notes, wo.created_by, wo.started_at, wo.completed_at,
-			   wo.deleted_at, wo.deleted_by, wo.created_at, wo.updated_at
+			   wo.is_warranty, wo.order_type, wo.customer_id, wo.customer_vehicle_id, wo.labor_price,
+			   wo.estimated_completion_at, wo.deleted_at, wo.deleted_by, wo.created_at, wo.updated_at
 		FROM work_orders wo
 		WHERE wo.wo_number = $1 AND wo.deleted_at IS NULL
 	`
@@ -98,12 +111,13 @@ func (r *workOrderRepository) List(ctx context.Context, offset, limit int) ([]*d
 	var workOrders []*domain.WorkOrder
 	query := `
 		SELECT wo.id, wo.wo_number, wo.vehicle_id, wo.description, wo.assigned_mechanic_id,
-			   wo.status, wo.progress_percentage, wo.total_parts_cost, wo.labor_cost,
+			   wo.status, wo.progress_percentage, wo.total_parts_cost, wo.labor_cost, wo.sublet_cost,
 			   wo.total_cost, wo.notes, wo.created_by, wo.started_at, wo.completed_at,
-			   wo.deleted_at, wo.deleted_by, wo.created_at, wo.updated_at,
+			   wo.is_warranty, wo.order_type, wo.customer_id, wo.customer_vehicle_id, wo.labor_price,
+			   wo.estimated_completion_at, wo.deleted_at, wo.deleted_by, wo.created_at, wo.updated_at,
 			   -- Vehicle details
-			   v.vehicle_code as "vehicle.vehicle_code", v.brand as "vehicle.brand",
-			   v.model as "vehicle.model", v.status as "vehicle.status",
+			   COALESCE(v.vehicle_code, '') as "vehicle.vehicle_code", COALESCE(v.brand, '') as "vehicle.brand",
+			   COALESCE(v.model, '') as "vehicle.model", v.status as "vehicle.status",
 			   -- Assigned mechanic details
 			   m.username as "assigned_mechanic.username", m.full_name as "assigned_mechanic.full_name",
 			   -- Creator details
@@ -121,6 +135,7 @@ func (r *workOrderRepository) List(ctx context.Context, offset, limit int) ([]*d
 	if err != nil {
 		return nil, fmt.Errorf("failed to list work orders: %w", err)
 	}
+	detachMissingVehicle(workOrders...)
 	
 	return workOrders, nil
 }
@@ -129,12 +144,13 @@ func (r *workOrderRepository) ListByStatus(ctx context.Context, status domain.Wo
 	var workOrders []*domain.WorkOrder
 	query := `
 		SELECT wo.id, wo.wo_number, wo.vehicle_id, wo.description, wo.assigned_mechanic_id,
-			   wo.status, wo.progress_percentage, wo.total_parts_cost, wo.labor_cost,
+			   wo.status, wo.progress_percentage, wo.total_parts_cost, wo.labor_cost, wo.sublet_cost,
 			   wo.total_cost, wo.notes, wo.created_by, wo.started_at, wo.completed_at,
-			   wo.deleted_at, wo.deleted_by, wo.created_at, wo.updated_at,
+			   wo.is_warranty, wo.order_type, wo.customer_id, wo.customer_vehicle_id, wo.labor_price,
+			   wo.estimated_completion_at, wo.deleted_at, wo.deleted_by, wo.created_at, wo.updated_at,
 			   -- Vehicle details
-			   v.vehicle_code as "vehicle.vehicle_code", v.brand as "vehicle.brand",
-			   v.model as "vehicle.model", v.status as "vehicle.status",
+			   COALESCE(v.vehicle_code, '') as "vehicle.vehicle_code", COALESCE(v.brand, '') as "vehicle.brand",
+			   COALESCE(v.model, '') as "vehicle.model", v.status as "vehicle.status",
 			   -- Assigned mechanic details
 			   m.username as "assigned_mechanic.username", m.full_name as "assigned_mechanic.full_name",
 			   -- Creator details
@@ -152,6 +168,7 @@ func (r *workOrderRepository) ListByStatus(ctx context.Context, status domain.Wo
 	if err != nil {
 		return nil, fmt.Errorf("failed to list work orders by status: %w", err)
 	}
+	detachMissingVehicle(workOrders...)
 	
 	return workOrders, nil
 }
@@ -160,12 +177,13 @@ func (r *workOrderRepository) ListByMechanic(ctx context.Context, mechanicID int
 	var workOrders []*domain.WorkOrder
 	query := `
 		SELECT wo.id, wo.wo_number, wo.vehicle_id, wo.description, wo.assigned_mechanic_id,
-			   wo.status, wo.progress_percentage, wo.total_parts_cost, wo.labor_cost,
+			   wo.status, wo.progress_percentage, wo.total_parts_cost, wo.labor_cost, wo.sublet_cost,
 			   wo.total_cost, wo.notes, wo.created_by, wo.started_at, wo.completed_at,
-			   wo.deleted_at, wo.deleted_by, wo.created_at, wo.updated_at,
+			   wo.is_warranty, wo.order_type, wo.customer_id, wo.customer_vehicle_id, wo.labor_price,
+			   wo.estimated_completion_at, wo.deleted_at, wo.deleted_by, wo.created_at, wo.updated_at,
 			   -- Vehicle details
-			   v.vehicle_code as "vehicle.vehicle_code", v.brand as "vehicle.brand",
-			   v.model as "vehicle.model", v.status as "vehicle.status"
+			   COALESCE(v.vehicle_code, '') as "vehicle.vehicle_code", COALESCE(v.brand, '') as "vehicle.brand",
+			   COALESCE(v.model, '') as "vehicle.model", v.status as "vehicle.status"
 		FROM work_orders wo
 		LEFT JOIN vehicles v ON wo.vehicle_id = v.id AND v.deleted_at IS NULL
 		WHERE wo.deleted_at IS NULL AND wo.assigned_mechanic_id = $1
@@ -177,25 +195,28 @@ func (r *workOrderRepository) ListByMechanic(ctx context.Context, mechanicID int
 	if err != nil {
 		return nil, fmt.Errorf("failed to list work orders by mechanic: %w", err)
 	}
+	detachMissingVehicle(workOrders...)
 	
 	return workOrders, nil
 }
 
+// Update saves the editable fields of a work order. Status, progress and the
+// started/completed timestamps are only changed through TransitionStatus and
+// UpdateProgress.
 func (r *workOrderRepository) Update(ctx context.Context, workOrder *domain.WorkOrder) error {
 	query := `
 		UPDATE work_orders SET
-			description = $2, assigned_mechanic_id = $3, status = $4,
-			progress_percentage = $5, total_parts_cost = $6, labor_cost = $7,
-			total_cost = $8, notes = $9, started_at = $10, completed_at = $11,
-			updated_at = CURRENT_TIMESTAMP
+			description = $2, assigned_mechanic_id = $3, total_parts_cost = $4,
+			labor_cost = $5, total_cost = $6, notes = $7, assignment_reason = $8,
+			labor_price = $9, sublet_cost = $10, updated_at = CURRENT_TIMESTAMP
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 	
 	_, err := r.db.ExecContext(ctx, query,
 		workOrder.ID, workOrder.Description, workOrder.AssignedMechanicID,
-		workOrder.Status, workOrder.ProgressPercentage, workOrder.TotalPartsCost,
-		workOrder.LaborCost, workOrder.TotalCost, workOrder.Notes,
-		workOrder.StartedAt, workOrder.CompletedAt,
+		workOrder.TotalPartsCost, workOrder.LaborCost, workOrder.TotalCost,
+		workOrder.Notes, workOrder.AssignmentReason, workOrder.LaborPrice,
+		workOrder.SubletCost,
 	)
 	
 	if err != nil {
@@ -223,10 +244,12 @@ func (r *workOrderRepository) SoftDelete(ctx context.Context, id int, deletedBy
 func (r *workOrderRepository) ListByDateRange(ctx context.Context, startDate, endDate time.Time, offset, limit int) ([]*domain.WorkOrder, error) {
 	query := `
 		SELECT wo.id, wo.wo_number, wo.vehicle_id, wo.description, wo.assigned_mechanic_id, 
-		       wo.status, wo.progress_percentage, wo.total_parts_cost, wo.labor_cost, 
+		       wo.status, wo.progress_percentage, wo.total_parts_cost, wo.labor_cost, wo.sublet_cost,
 		       wo.total_cost, wo.notes, wo.created_by, wo.started_at, wo.completed_at, 
+		       wo.is_warranty, wo.order_type, wo.customer_id, wo.customer_vehicle_id, wo.labor_price,
 		       wo.created_at, wo.updated_at,
-		       v.id, v.vehicle_code, v.brand, v.model, v.year, v.plate_number,
+		       COALESCE(v.id, 0), COALESCE(v.vehicle_code, ''), COALESCE(v.brand, ''), COALESCE(v.model, ''),
+		       COALESCE(v.year, 0), v.plate_number,
 		       u.id, u.username, u.email,
 		       c.id, c.username, c.email
 		FROM work_orders wo
@@ -255,8 +278,9 @@ func (r *workOrderRepository) ListByDateRange(ctx context.Context, startDate, en
 		
 		err := rows.Scan(
 			&wo.ID, &wo.WONumber, &wo.VehicleID, &wo.Description, &wo.AssignedMechanicID,
-			&wo.Status, &wo.ProgressPercentage, &wo.TotalPartsCost, &wo.LaborCost,
+			&wo.Status, &wo.ProgressPercentage, &wo.TotalPartsCost, &wo.LaborCost, &wo.SubletCost,
 			&wo.TotalCost, &wo.Notes, &wo.CreatedBy, &wo.StartedAt, &wo.CompletedAt,
+			&wo.IsWarranty, &wo.OrderType, &wo.CustomerID, &wo.CustomerVehicleID, &wo.LaborPrice,
 			&wo.CreatedAt, &wo.UpdatedAt,
 			&vehicle.ID, &vehicle.VehicleCode, &vehicle.Brand, &vehicle.Model, &vehicle.Year, &vehicle.PlateNumber,
 			&mechanic.ID, &mechanic.Username, &mechanic.Email,
@@ -271,6 +295,7 @@ func (r *workOrderRepository) ListByDateRange(ctx context.Context, startDate, en
 		wo.Creator = &creator
 		workOrders = append(workOrders, &wo)
 	}
+	detachMissingVehicle(workOrders...)
 	
 	return workOrders, nil
 }
@@ -300,6 +325,12 @@ func (r *workOrderRepository) CountByStatus(ctx context.Context, status domain.W
 }
 
 func (r *workOrderRepository) GenerateWONumber(ctx context.Context) (string, error) {
+	return generateWONumber(ctx, r.db)
+}
+
+// generateWONumber numbers the next work order of the day through the
+// database or the caller's transaction
+func generateWONumber(ctx context.Context, q sqlx.QueryerContext) (string, error) {
 	var count int
 	today := time.Now().Format("20060102")
 	
@@ -308,7 +339,7 @@ func (r *workOrderRepository) GenerateWONumber(ctx context.Context) (string, err
 		WHERE wo_number LIKE $1 AND deleted_at IS NULL
 	`
 	
-	err := r.db.QueryRowContext(ctx, query, fmt.Sprintf("WO-%s%%", today)).Scan(&count)
+	err := q.QueryRowxContext(ctx, query, fmt.Sprintf("WO-%s%%", today)).Scan(&count)
 	if err != nil {
 		return "", fmt.Errorf("failed to count work orders for number generation: %w", err)
 	}
@@ -353,6 +384,22 @@ func (r *workOrderRepository) UpdateStatus(ctx context.Context, id int, status d
 	return nil
 }
 
+// UpdateEstimatedCompletion stores the completion date projected from the
+// work order schedule
+func (r *workOrderRepository) UpdateEstimatedCompletion(ctx context.Context, id int, estimatedCompletionAt *time.Time) error {
+	query := `
+		UPDATE work_orders SET estimated_completion_at = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, estimatedCompletionAt)
+	if err != nil {
+		return fmt.Errorf("failed to update work order estimated completion: %w", err)
+	}
+
+	return nil
+}
+
 func (r *workOrderRepository) UpdateProgress(ctx context.Context, id int, progress int) error {
 	query := `
 		UPDATE work_orders SET 
@@ -366,4 +413,170 @@ func (r *workOrderRepository) UpdateProgress(ctx context.Context, id int, progre
 	}
 	
 	return nil
-}
\ No newline at end of file
+}
+// AssignMechanic sets the mechanic a work order is assigned to
+func (r *workOrderRepository) AssignMechanic(ctx context.Context, id int, mechanicID int, reason string) error {
+	query := `
+		UPDATE work_orders SET
+			assigned_mechanic_id = $2, assignment_reason = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, mechanicID, reason)
+	if err != nil {
+		return fmt.Errorf("failed to assign work order mechanic: %w", err)
+	}
+
+	return nil
+}
+
+// UpdatePartsCost sets the parts cost of a work order and recalculates its
+// total cost from the stored labor and sublet costs
+func (r *workOrderRepository) UpdatePartsCost(ctx context.Context, id int, partsCost float64) error {
+	query := `
+		UPDATE work_orders SET
+			total_parts_cost = $2, total_cost = $2 + labor_cost + sublet_cost,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, partsCost)
+	if err != nil {
+		return fmt.Errorf("failed to update work order parts cost: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateLaborCost sets the labor cost of a work order and recalculates its
+// total cost from the stored parts and sublet costs
+func (r *workOrderRepository) UpdateLaborCost(ctx context.Context, id int, laborCost float64) error {
+	query := `
+		UPDATE work_orders SET
+			labor_cost = $2, total_cost = total_parts_cost + $2 + sublet_cost,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, laborCost)
+	if err != nil {
+		return fmt.Errorf("failed to update work order labor cost: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateSubletCost sets the sublet cost of a work order and recalculates its
+// total cost from the stored parts and labor costs
+func (r *workOrderRepository) UpdateSubletCost(ctx context.Context, id int, subletCost float64) error {
+	query := `
+		UPDATE work_orders SET
+			sublet_cost = $2, total_cost = total_parts_cost + labor_cost + $2,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, subletCost)
+	if err != nil {
+		return fmt.Errorf("failed to update work order sublet cost: %w", err)
+	}
+
+	return nil
+}
+
+// addWorkOrderLaborPrice adds to the labor price charged on a work order
+func addWorkOrderLaborPrice(ctx context.Context, tx *sqlx.Tx, id int, amount float64) error {
+	query := `
+		UPDATE work_orders SET
+			labor_price = labor_price + $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	_, err := tx.ExecContext(ctx, query, id, amount)
+	if err != nil {
+		return fmt.Errorf("failed to update work order labor price: %w", err)
+	}
+
+	return nil
+}
+
+// TransitionStatus moves a work order from one status to another and records
+// the change in the status history. The update only applies while the work
+// order is still in the expected from status.
+func (r *workOrderRepository) TransitionStatus(ctx context.Context, history *domain.WorkOrderStatusHistory) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE work_orders SET
+			status = $3,
+			started_at = CASE WHEN $3 = 'in_progress' THEN COALESCE(started_at, CURRENT_TIMESTAMP) ELSE started_at END,
+			completed_at = CASE WHEN $3 = 'completed' THEN CURRENT_TIMESTAMP ELSE completed_at END,
+			progress_percentage = CASE WHEN $3 = 'completed' THEN 100 ELSE progress_percentage END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = $2 AND deleted_at IS NULL
+	`
+
+	result, err := tx.ExecContext(ctx, query, history.WorkOrderID, history.FromStatus, history.ToStatus)
+	if err != nil {
+		return fmt.Errorf("failed to update work order status: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update work order status: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("work order is no longer %s", history.FromStatus)
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO work_order_status_history (work_order_id, from_status, to_status, reason, changed_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, changed_at
+	`,
+		history.WorkOrderID, history.FromStatus, history.ToStatus, history.Reason, history.ChangedBy,
+	).Scan(&history.ID, &history.ChangedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record work order status history: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *workOrderRepository) ListStatusHistory(ctx context.Context, workOrderID int) ([]*domain.WorkOrderStatusHistory, error) {
+	var history []*domain.WorkOrderStatusHistory
+	query := `
+		SELECT h.id, h.work_order_id, h.from_status, h.to_status, h.reason, h.changed_by, h.changed_at,
+			   u.id as "changed_by_user.id", u.username as "changed_by_user.username",
+			   u.full_name as "changed_by_user.full_name"
+		FROM work_order_status_history h
+		JOIN users u ON h.changed_by = u.id
+		WHERE h.work_order_id = $1
+		ORDER BY h.changed_at, h.id
+	`
+
+	err := r.db.SelectContext(ctx, &history, query, workOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list work order status history: %w", err)
+	}
+
+	return history, nil
+}
+
+// detachMissingVehicle drops the empty stock vehicle joined onto customer
+// service work orders, which repair a customer vehicle instead
+func detachMissingVehicle(workOrders ...*domain.WorkOrder) {
+	for _, workOrder := range workOrders {
+		if workOrder.VehicleID == nil {
+			workOrder.Vehicle = nil
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type workOrderSubletRepository struct {
+	db *sqlx.DB
+}
+
+// NewWorkOrderSubletRepository creates a new work order sublet repository
+func NewWorkOrderSubletRepository(db *sqlx.DB) WorkOrderSubletRepository {
+	return &workOrderSubletRepository{db: db}
+}
+
+const workOrderSubletSelect = `
+	SELECT ws.id, ws.work_order_id, ws.supplier_id, ws.description, ws.vendor_invoice_number,
+		   ws.cost, ws.status, ws.attachment_path, ws.notes, ws.created_by,
+		   ws.completed_at, ws.completed_by,
+		   ws.deleted_at, ws.deleted_by, ws.created_at, ws.updated_at,
+		   s.name as supplier_name, wo.wo_number
+	FROM work_order_sublets ws
+	JOIN suppliers s ON ws.supplier_id = s.id
+	JOIN work_orders wo ON ws.work_order_id = wo.id
+`
+
+func (r *workOrderSubletRepository) Create(ctx context.Context, sublet *domain.WorkOrderSublet) error {
+	query := `
+		INSERT INTO work_order_sublets (
+			work_order_id, supplier_id, description, vendor_invoice_number, cost,
+			status, notes, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		sublet.WorkOrderID, sublet.SupplierID, sublet.Description, sublet.VendorInvoiceNumber,
+		sublet.Cost, sublet.Status, sublet.Notes, sublet.CreatedBy,
+	).Scan(&sublet.ID, &sublet.CreatedAt, &sublet.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create work order sublet: %w", err)
+	}
+
+	return nil
+}
+
+func (r *workOrderSubletRepository) GetByID(ctx context.Context, id int) (*domain.WorkOrderSublet, error) {
+	var sublet domain.WorkOrderSublet
+	query := workOrderSubletSelect + `WHERE ws.id = $1 AND ws.deleted_at IS NULL`
+
+	err := r.db.GetContext(ctx, &sublet, query, id)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get work order sublet: %w", err)
+	}
+
+	return &sublet, nil
+}
+
+func (r *workOrderSubletRepository) ListByWorkOrderID(ctx context.Context, workOrderID int) ([]*domain.WorkOrderSublet, error) {
+	var sublets []*domain.WorkOrderSublet
+	query := workOrderSubletSelect + `
+		WHERE ws.work_order_id = $1 AND ws.deleted_at IS NULL
+		ORDER BY ws.created_at, ws.id
+	`
+
+	err := r.db.SelectContext(ctx, &sublets, query, workOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list work order sublets: %w", err)
+	}
+
+	return sublets, nil
+}
+
+// Complete records the vendor invoice on a sublet that is still with the
+// vendor. It fails if the sublet was already completed or cancelled.
+// Complete closes a sublet that is with the vendor and opens its vendor
+// payable in one transaction
+func (r *workOrderSubletRepository) Complete(ctx context.Context, sublet *domain.WorkOrderSublet) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE work_order_sublets SET
+			status = 'completed', vendor_invoice_number = $2, cost = $3,
+			completed_at = $4, completed_by = $5
+		WHERE id = $1 AND status = 'sent' AND deleted_at IS NULL
+		RETURNING updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		sublet.ID, sublet.VendorInvoiceNumber, sublet.Cost, sublet.CompletedAt, sublet.CompletedBy,
+	).Scan(&sublet.UpdatedAt)
+
+	if err != nil {
+		if IsNoRowsError(err) {
+			return fmt.Errorf("sublet is no longer with the vendor")
+		}
+		return fmt.Errorf("failed to complete work order sublet: %w", err)
+	}
+
+	if sublet.Payable != nil {
+		if err := insertPayable(ctx, tx, sublet.Payable); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	sublet.Status = domain.SubletStatusCompleted
+
+	return nil
+}
+
+func (r *workOrderSubletRepository) Cancel(ctx context.Context, id int) error {
+	query := `
+		UPDATE work_order_sublets SET status = 'cancelled'
+		WHERE id = $1 AND status = 'sent' AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to cancel work order sublet: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("sublet is no longer with the vendor")
+	}
+
+	return nil
+}
+
+func (r *workOrderSubletRepository) UpdateAttachment(ctx context.Context, id int, attachmentPath string) error {
+	query := `
+		UPDATE work_order_sublets SET attachment_path = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, attachmentPath)
+	if err != nil {
+		return fmt.Errorf("failed to update work order sublet attachment: %w", err)
+	}
+
+	return nil
+}
+
+// SumCostByWorkOrder totals the cost of the work order's sublets that are
+// not cancelled
+func (r *workOrderSubletRepository) SumCostByWorkOrder(ctx context.Context, workOrderID int) (float64, error) {
+	var total float64
+	query := `
+		SELECT COALESCE(SUM(cost), 0) FROM work_order_sublets
+		WHERE work_order_id = $1 AND status <> 'cancelled' AND deleted_at IS NULL
+	`
+
+	err := r.db.QueryRowContext(ctx, query, workOrderID).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum work order sublet cost: %w", err)
+	}
+
+	return total, nil
+}
+
+// CountSentByWorkOrder counts sublets still with the vendor
+func (r *workOrderSubletRepository) CountSentByWorkOrder(ctx context.Context, workOrderID int) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*) FROM work_order_sublets
+		WHERE work_order_id = $1 AND status = 'sent' AND deleted_at IS NULL
+	`
+
+	err := r.db.QueryRowContext(ctx, query, workOrderID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count open work order sublets: %w", err)
+	}
+
+	return count, nil
+}
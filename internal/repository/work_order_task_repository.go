@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type workOrderTaskRepository struct {
+	db *sqlx.DB
+}
+
+// NewWorkOrderTaskRepository creates a new work order task repository
+func NewWorkOrderTaskRepository(db *sqlx.DB) WorkOrderTaskRepository {
+	return &workOrderTaskRepository{db: db}
+}
+
+func (r *workOrderTaskRepository) Create(ctx context.Context, task *domain.WorkOrderTask) error {
+	return insertWorkOrderTask(ctx, r.db, task)
+}
+
+// insertWorkOrderTask inserts a task with the given database handle, so it can
+// also be written inside a transaction
+func insertWorkOrderTask(ctx context.Context, q sqlx.QueryerContext, task *domain.WorkOrderTask) error {
+	query := `
+		INSERT INTO work_order_tasks (
+			work_order_id, title, estimated_hours, assigned_mechanic_id, status,
+			notes, sort_order, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := q.QueryRowxContext(ctx, query,
+		task.WorkOrderID, task.Title, task.EstimatedHours, task.AssignedMechanicID,
+		task.Status, task.Notes, task.SortOrder, task.CreatedBy,
+	).Scan(&task.ID, &task.CreatedAt, &task.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create work order task: %w", err)
+	}
+
+	return nil
+}
+
+func (r *workOrderTaskRepository) GetByID(ctx context.Context, id int) (*domain.WorkOrderTask, error) {
+	var task domain.WorkOrderTask
+	query := `
+		SELECT id, work_order_id, title, estimated_hours, assigned_mechanic_id, status,
+			   notes, sort_order, completed_at, completed_by, created_by,
+			   deleted_at, deleted_by, created_at, updated_at
+		FROM work_order_tasks
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	err := r.db.GetContext(ctx, &task, query, id)
+	if err != nil {
+		if IsNoRowsError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get work order task: %w", err)
+	}
+
+	return &task, nil
+}
+
+func (r *workOrderTaskRepository) Update(ctx context.Context, task *domain.WorkOrderTask) error {
+	query := `
+		UPDATE work_order_tasks SET
+			title = $2, estimated_hours = $3, assigned_mechanic_id = $4, status = $5,
+			notes = $6, sort_order = $7, completed_at = $8, completed_by = $9
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		task.ID, task.Title, task.EstimatedHours, task.AssignedMechanicID, task.Status,
+		task.Notes, task.SortOrder, task.CompletedAt, task.CompletedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update work order task: %w", err)
+	}
+
+	return nil
+}
+
+func (r *workOrderTaskRepository) Delete(ctx context.Context, id int, deletedBy int) error {
+	query := `
+		UPDATE work_order_tasks SET
+			deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, deletedBy)
+	if err != nil {
+		return fmt.Errorf("failed to delete work order task: %w", err)
+	}
+
+	return nil
+}
+
+func (r *workOrderTaskRepository) ListByWorkOrderID(ctx context.Context, workOrderID int) ([]*domain.WorkOrderTask, error) {
+	var tasks []*domain.WorkOrderTask
+	query := `
+		SELECT id, work_order_id, title, estimated_hours, assigned_mechanic_id, status,
+			   notes, sort_order, completed_at, completed_by, created_by,
+			   deleted_at, deleted_by, created_at, updated_at
+		FROM work_order_tasks
+		WHERE work_order_id = $1 AND deleted_at IS NULL
+		ORDER BY sort_order, id
+	`
+
+	err := r.db.SelectContext(ctx, &tasks, query, workOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list work order tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// ListOpenByMechanic returns the pending and in-progress tasks assigned to a
+// mechanic on work orders that are still open
+func (r *workOrderTaskRepository) ListOpenByMechanic(ctx context.Context, mechanicID int) ([]*domain.WorkOrderTask, error) {
+	var tasks []*domain.WorkOrderTask
+	query := `
+		SELECT t.id, t.work_order_id, t.title, t.estimated_hours, t.assigned_mechanic_id, t.status,
+			   t.notes, t.sort_order, t.completed_at, t.completed_by, t.created_by,
+			   t.deleted_at, t.deleted_by, t.created_at, t.updated_at,
+			   -- Work order details
+			   wo.id as "work_order.id", wo.wo_number as "work_order.wo_number",
+			   wo.status as "work_order.status",
+			   -- Customer jobs show the customer vehicle in place of a stock vehicle
+			   COALESCE(v.vehicle_code, cv.plate_number) as "work_order.vehicle.vehicle_code",
+			   COALESCE(v.brand, cv.brand) as "work_order.vehicle.brand",
+			   COALESCE(v.model, cv.model) as "work_order.vehicle.model"
+		FROM work_order_tasks t
+		JOIN work_orders wo ON t.work_order_id = wo.id
+		LEFT JOIN vehicles v ON wo.vehicle_id = v.id
+		LEFT JOIN customer_vehicles cv ON wo.customer_vehicle_id = cv.id
+		WHERE t.assigned_mechanic_id = $1 AND t.deleted_at IS NULL
+		  AND t.status IN ('pending', 'in_progress')
+		  AND wo.deleted_at IS NULL AND wo.status NOT IN ('completed', 'cancelled')
+		ORDER BY wo.created_at, t.sort_order, t.id
+	`
+
+	err := r.db.SelectContext(ctx, &tasks, query, mechanicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open work order tasks: %w", err)
+	}
+
+	return tasks, nil
+}
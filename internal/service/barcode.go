@@ -0,0 +1,68 @@
+package service
+
+import (
+	"fmt"
+	"pos-final/internal/domain"
+	"strings"
+)
+
+// ean13CheckDigit computes the check digit for the first 12 digits of an
+// EAN-13 code: digits in even positions weigh 3, odd positions weigh 1.
+func ean13CheckDigit(digits string) int {
+	sum := 0
+	for i, r := range digits {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(r-'0') * weight
+	}
+	return (10 - sum%10) % 10
+}
+
+// isEAN13 reports whether code is 13 digits with a valid check digit
+func isEAN13(code string) bool {
+	if len(code) != 13 {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return ean13CheckDigit(code[:12]) == int(code[12]-'0')
+}
+
+// generateEAN13 builds an in-store EAN-13 code from the prefix and the part
+// ID, zero padded to 12 digits, followed by the check digit
+func generateEAN13(prefix string, partID int) (string, error) {
+	for _, r := range prefix {
+		if r < '0' || r > '9' {
+			return "", fmt.Errorf("EAN-13 prefix must be digits")
+		}
+	}
+
+	body := fmt.Sprintf("%s%0*d", prefix, 12-len(prefix), partID)
+	if len(body) != 12 {
+		return "", fmt.Errorf("part ID %d does not fit in an EAN-13 code with prefix %s", partID, prefix)
+	}
+
+	return fmt.Sprintf("%s%d", body, ean13CheckDigit(body)), nil
+}
+
+// generateBarcode returns the barcode for a part in the given symbology.
+// Code128 encodes the part code as is.
+func generateBarcode(symbology domain.BarcodeSymbology, prefix string, sparePart *domain.SparePart) (string, error) {
+	switch symbology {
+	case domain.BarcodeSymbologyEAN13:
+		return generateEAN13(prefix, sparePart.ID)
+	case domain.BarcodeSymbologyCode128:
+		code := strings.TrimSpace(sparePart.PartCode)
+		if code == "" {
+			return "", fmt.Errorf("spare part has no part code")
+		}
+		return code, nil
+	default:
+		return "", fmt.Errorf("invalid barcode symbology: %s", symbology)
+	}
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"pos-final/internal/repository"
+	"time"
+)
+
+// Days after the sale before the owner must be paid out
+const defaultConsignmentPayoutDays = 7
+
+type consignmentService struct {
+	consignmentRepo repository.ConsignmentRepository
+	customerRepo    repository.CustomerRepository
+	vehicleService  VehicleService
+	payableService  PayableService
+}
+
+// NewConsignmentService creates a new consignment service
+func NewConsignmentService(
+	consignmentRepo repository.ConsignmentRepository,
+	customerRepo repository.CustomerRepository,
+	vehicleService VehicleService,
+	payableService PayableService,
+) ConsignmentService {
+	return &consignmentService{
+		consignmentRepo: consignmentRepo,
+		customerRepo:    customerRepo,
+		vehicleService:  vehicleService,
+		payableService:  payableService,
+	}
+}
+
+// CreateConsignment takes a vehicle into stock on behalf of its owner. The
+// vehicle is created without a purchase cost, together with the consignment.
+func (s *consignmentService) CreateConsignment(ctx context.Context, consignment *domain.Consignment) error {
+	if consignment.Vehicle == nil {
+		return fmt.Errorf("vehicle details are required")
+	}
+	if consignment.AgreedNetPrice <= 0 {
+		return fmt.Errorf("agreed net price must be greater than 0")
+	}
+
+	owner, err := s.customerRepo.GetByID(ctx, consignment.OwnerID)
+	if err != nil {
+		return fmt.Errorf("failed to get owner: %w", err)
+	}
+	if owner == nil {
+		return fmt.Errorf("owner not found")
+	}
+
+	vehicle := consignment.Vehicle
+	if vehicle.SellingPrice != nil && *vehicle.SellingPrice < consignment.AgreedNetPrice {
+		return fmt.Errorf("selling price cannot be below the agreed net price")
+	}
+
+	if consignment.PayoutDays <= 0 {
+		consignment.PayoutDays = defaultConsignmentPayoutDays
+	}
+	if consignment.IntakeDate.IsZero() {
+		consignment.IntakeDate = time.Now()
+	}
+
+	// Owner's vehicle: no purchase price, so no HPP-based value
+	vehicle.IsConsignment = true
+	vehicle.PurchasePrice = nil
+	vehicle.PurchasedDate = nil
+	vehicle.RepairCost = 0
+
+	if err := s.vehicleService.PrepareVehicle(ctx, vehicle); err != nil {
+		return fmt.Errorf("failed to prepare consignment vehicle: %w", err)
+	}
+
+	consignmentNumber, err := s.consignmentRepo.GenerateConsignmentNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to generate consignment number: %w", err)
+	}
+
+	consignment.ConsignmentNumber = consignmentNumber
+	consignment.Status = domain.ConsignmentStatusActive
+	consignment.Owner = owner
+
+	if err := s.consignmentRepo.Create(ctx, consignment); err != nil {
+		return fmt.Errorf("failed to create consignment: %w", err)
+	}
+
+	return nil
+}
+
+func (s *consignmentService) GetConsignmentByID(ctx context.Context, id int) (*domain.Consignment, error) {
+	consignment, err := s.consignmentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if consignment == nil {
+		return nil, fmt.Errorf("consignment not found")
+	}
+
+	if consignment.Status == domain.ConsignmentStatusSold {
+		payable, err := s.payableService.GetPayableByConsignmentID(ctx, consignment.ID)
+		if err != nil {
+			return nil, err
+		}
+		consignment.Payable = payable
+	}
+
+	return consignment, nil
+}
+
+func (s *consignmentService) ListConsignments(ctx context.Context, page, limit int) ([]*domain.Consignment, int, error) {
+	offset := (page - 1) * limit
+	consignments, err := s.consignmentRepo.List(ctx, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := s.consignmentRepo.Count(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return consignments, count, nil
+}
+
+func (s *consignmentService) ListConsignmentsByStatus(ctx context.Context, status domain.ConsignmentStatus, page, limit int) ([]*domain.Consignment, int, error) {
+	offset := (page - 1) * limit
+	consignments, err := s.consignmentRepo.ListByStatus(ctx, status, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := s.consignmentRepo.CountByStatus(ctx, status)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return consignments, count, nil
+}
+
+// CalculateCommission returns the dealer commission for selling a consignment
+// vehicle at the given price: everything above the owner's agreed net price
+func (s *consignmentService) CalculateCommission(ctx context.Context, vehicleID int, salePrice float64) (float64, error) {
+	consignment, err := s.getActiveByVehicleID(ctx, vehicleID)
+	if err != nil {
+		return 0, err
+	}
+
+	if salePrice < consignment.AgreedNetPrice {
+		return 0, fmt.Errorf("sale price %.2f is below the owner's agreed net price %.2f", salePrice, consignment.AgreedNetPrice)
+	}
+
+	return salePrice - consignment.AgreedNetPrice, nil
+}
+
+// PrepareSale settles the consignment of a vehicle being sold and opens the
+// payable to the owner, both to be saved with the sales invoice
+func (s *consignmentService) PrepareSale(ctx context.Context, invoice *domain.SalesInvoice) (*domain.Consignment, error) {
+	consignment, err := s.getActiveByVehicleID(ctx, invoice.VehicleID)
+	if err != nil {
+		return nil, err
+	}
+
+	salePrice := invoice.FinalPrice
+	commission := invoice.FinalPrice - consignment.AgreedNetPrice
+	consignment.SalePrice = &salePrice
+	consignment.CommissionAmount = &commission
+	consignment.Payable = newConsignmentPayable(consignment, invoice.TransactionDate)
+
+	return consignment, nil
+}
+
+func (s *consignmentService) getActiveByVehicleID(ctx context.Context, vehicleID int) (*domain.Consignment, error) {
+	consignment, err := s.consignmentRepo.GetByVehicleID(ctx, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	if consignment == nil {
+		return nil, fmt.Errorf("consignment not found for vehicle")
+	}
+	if consignment.Status != domain.ConsignmentStatusActive {
+		return nil, fmt.Errorf("consignment %s is already %s", consignment.ConsignmentNumber, consignment.Status)
+	}
+
+	return consignment, nil
+}
@@ -0,0 +1,7 @@
+package service
+
+import "math"
+
+func roundCost(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -9,13 +9,15 @@ import (
 )
 
 type customerService struct {
-	customerRepo repository.CustomerRepository
+	customerRepo        repository.CustomerRepository
+	customerVehicleRepo repository.CustomerVehicleRepository
 }
 
 // NewCustomerService creates a new customer service
-func NewCustomerService(customerRepo repository.CustomerRepository) CustomerService {
+func NewCustomerService(customerRepo repository.CustomerRepository, customerVehicleRepo repository.CustomerVehicleRepository) CustomerService {
 	return &customerService{
-		customerRepo: customerRepo,
+		customerRepo:        customerRepo,
+		customerVehicleRepo: customerVehicleRepo,
 	}
 }
 
@@ -190,6 +192,82 @@ func (s *customerService) DeleteCustomer(ctx context.Context, id int, deletedBy
 	return nil
 }
 
+// AddCustomerVehicle registers a vehicle the customer brings in for service
+func (s *customerService) AddCustomerVehicle(ctx context.Context, vehicle *domain.CustomerVehicle) error {
+	if _, err := s.GetCustomerByID(ctx, vehicle.CustomerID); err != nil {
+		return err
+	}
+
+	if err := s.validateCustomerVehicle(vehicle); err != nil {
+		return err
+	}
+
+	if err := s.customerVehicleRepo.Create(ctx, vehicle); err != nil {
+		return fmt.Errorf("failed to create customer vehicle: %w", err)
+	}
+
+	return nil
+}
+
+func (s *customerService) GetCustomerVehicleByID(ctx context.Context, id int) (*domain.CustomerVehicle, error) {
+	vehicle, err := s.customerVehicleRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer vehicle: %w", err)
+	}
+
+	if vehicle == nil {
+		return nil, fmt.Errorf("customer vehicle not found")
+	}
+
+	return vehicle, nil
+}
+
+func (s *customerService) ListCustomerVehicles(ctx context.Context, customerID int) ([]*domain.CustomerVehicle, error) {
+	if _, err := s.GetCustomerByID(ctx, customerID); err != nil {
+		return nil, err
+	}
+
+	return s.customerVehicleRepo.ListByCustomerID(ctx, customerID)
+}
+
+func (s *customerService) UpdateCustomerVehicle(ctx context.Context, vehicle *domain.CustomerVehicle) error {
+	existing, err := s.GetCustomerVehicleByID(ctx, vehicle.ID)
+	if err != nil {
+		return err
+	}
+	vehicle.CustomerID = existing.CustomerID
+
+	if err := s.validateCustomerVehicle(vehicle); err != nil {
+		return err
+	}
+
+	if err := s.customerVehicleRepo.Update(ctx, vehicle); err != nil {
+		return fmt.Errorf("failed to update customer vehicle: %w", err)
+	}
+
+	return nil
+}
+
+func (s *customerService) DeleteCustomerVehicle(ctx context.Context, id int, deletedBy int) error {
+	if _, err := s.GetCustomerVehicleByID(ctx, id); err != nil {
+		return err
+	}
+
+	return s.customerVehicleRepo.SoftDelete(ctx, id, deletedBy)
+}
+
+func (s *customerService) validateCustomerVehicle(vehicle *domain.CustomerVehicle) error {
+	if strings.TrimSpace(vehicle.PlateNumber) == "" {
+		return fmt.Errorf("plate number is required")
+	}
+
+	if strings.TrimSpace(vehicle.Brand) == "" || strings.TrimSpace(vehicle.Model) == "" {
+		return fmt.Errorf("vehicle brand and model are required")
+	}
+
+	return nil
+}
+
 func (s *customerService) validateCustomer(customer *domain.Customer) error {
 	if customer == nil {
 		return fmt.Errorf("customer is required")
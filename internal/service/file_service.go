@@ -185,4 +185,9 @@ func (s *fileService) GetFileURL(filePath string) string {
 	}
 	// Return URL path for serving static files
 	return "/static/uploads/" + filePath
-}
\ No newline at end of file
+}
+
+// GetFilePath returns the location of an uploaded file on disk
+func (s *fileService) GetFilePath(filePath string) string {
+	return filepath.Join(s.uploadDir, filePath)
+}
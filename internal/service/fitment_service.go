@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"pos-final/internal/repository"
+	"strings"
+)
+
+type fitmentService struct {
+	fitmentRepo         repository.SparePartFitmentRepository
+	sparePartRepo       repository.SparePartRepository
+	workOrderRepo       repository.WorkOrderRepository
+	vehicleRepo         repository.VehicleRepository
+	customerVehicleRepo repository.CustomerVehicleRepository
+}
+
+// NewFitmentService creates a new spare part fitment service
+func NewFitmentService(
+	fitmentRepo repository.SparePartFitmentRepository,
+	sparePartRepo repository.SparePartRepository,
+	workOrderRepo repository.WorkOrderRepository,
+	vehicleRepo repository.VehicleRepository,
+	customerVehicleRepo repository.CustomerVehicleRepository,
+) FitmentService {
+	return &fitmentService{
+		fitmentRepo:         fitmentRepo,
+		sparePartRepo:       sparePartRepo,
+		workOrderRepo:       workOrderRepo,
+		vehicleRepo:         vehicleRepo,
+		customerVehicleRepo: customerVehicleRepo,
+	}
+}
+
+func (s *fitmentService) ListFitments(ctx context.Context, sparePartID int) ([]*domain.SparePartFitment, error) {
+	if _, err := s.sparePartRepo.GetByID(ctx, sparePartID); err != nil {
+		return nil, fmt.Errorf("spare part not found")
+	}
+
+	return s.fitmentRepo.ListBySparePart(ctx, sparePartID)
+}
+
+func (s *fitmentService) AddFitment(ctx context.Context, fitment *domain.SparePartFitment) error {
+	if _, err := s.sparePartRepo.GetByID(ctx, fitment.SparePartID); err != nil {
+		return fmt.Errorf("spare part not found")
+	}
+
+	if err := validateFitment(fitment); err != nil {
+		return err
+	}
+
+	return s.fitmentRepo.Create(ctx, fitment)
+}
+
+func (s *fitmentService) UpdateFitment(ctx context.Context, fitment *domain.SparePartFitment) error {
+	existing, err := s.fitmentRepo.GetByID(ctx, fitment.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("fitment not found")
+	}
+
+	fitment.SparePartID = existing.SparePartID
+	fitment.CreatedBy = existing.CreatedBy
+	fitment.CreatedAt = existing.CreatedAt
+
+	if err := validateFitment(fitment); err != nil {
+		return err
+	}
+
+	return s.fitmentRepo.Update(ctx, fitment)
+}
+
+func (s *fitmentService) DeleteFitment(ctx context.Context, id int, deletedBy int) error {
+	existing, err := s.fitmentRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("fitment not found")
+	}
+
+	return s.fitmentRepo.SoftDelete(ctx, id, deletedBy)
+}
+
+// ListCompatibleParts lists the parts with a fitment covering the vehicle
+func (s *fitmentService) ListCompatibleParts(ctx context.Context, filter *domain.CompatiblePartFilter, page, limit int) ([]*domain.SparePart, int, error) {
+	if strings.TrimSpace(filter.Vehicle.Brand) == "" {
+		return nil, 0, fmt.Errorf("vehicle brand is required")
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	spareParts, err := s.fitmentRepo.ListCompatibleParts(ctx, filter, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.fitmentRepo.CountCompatibleParts(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return spareParts, total, nil
+}
+
+// GetWorkOrderVehicle returns the vehicle a work order is for
+func (s *fitmentService) GetWorkOrderVehicle(ctx context.Context, workOrderID int) (*domain.FitmentVehicle, error) {
+	workOrder, err := s.workOrderRepo.GetByID(ctx, workOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("work order not found")
+	}
+
+	vehicle, err := workOrderFitmentVehicle(ctx, s.vehicleRepo, s.customerVehicleRepo, workOrder)
+	if err != nil {
+		return nil, err
+	}
+	if vehicle == nil {
+		return nil, fmt.Errorf("work order has no vehicle")
+	}
+
+	return vehicle, nil
+}
+
+// workOrderFitmentVehicle resolves the stock or customer vehicle of a work
+// order. It returns nil when the work order has neither.
+func workOrderFitmentVehicle(
+	ctx context.Context,
+	vehicleRepo repository.VehicleRepository,
+	customerVehicleRepo repository.CustomerVehicleRepository,
+	workOrder *domain.WorkOrder,
+) (*domain.FitmentVehicle, error) {
+	if workOrder.VehicleID != nil {
+		vehicle, err := vehicleRepo.GetByID(ctx, *workOrder.VehicleID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get vehicle: %w", err)
+		}
+		if vehicle != nil {
+			year := vehicle.Year
+			return &domain.FitmentVehicle{
+				Brand:        vehicle.Brand,
+				Model:        vehicle.Model,
+				Year:         &year,
+				EngineNumber: vehicle.EngineNumber,
+			}, nil
+		}
+	}
+
+	if workOrder.CustomerVehicleID != nil {
+		vehicle, err := customerVehicleRepo.GetByID(ctx, *workOrder.CustomerVehicleID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get customer vehicle: %w", err)
+		}
+		if vehicle != nil {
+			return &domain.FitmentVehicle{
+				Brand:        vehicle.Brand,
+				Model:        vehicle.Model,
+				Year:         vehicle.Year,
+				EngineNumber: vehicle.EngineNumber,
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// checkPartFitment returns a warning when the part has fitments and none of
+// them covers the vehicle. Parts without fitment data are not warned about.
+func checkPartFitment(
+	ctx context.Context,
+	fitmentRepo repository.SparePartFitmentRepository,
+	sparePart *domain.SparePart,
+	vehicle *domain.FitmentVehicle,
+) (*string, error) {
+	if vehicle == nil {
+		return nil, nil
+	}
+
+	fitments, err := fitmentRepo.ListBySparePart(ctx, sparePart.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(fitments) == 0 {
+		return nil, nil
+	}
+
+	for _, fitment := range fitments {
+		if fitment.Fits(vehicle) {
+			return nil, nil
+		}
+	}
+
+	description := strings.TrimSpace(vehicle.Brand + " " + vehicle.Model)
+	if vehicle.Year != nil {
+		description = fmt.Sprintf("%s %d", description, *vehicle.Year)
+	}
+	warning := fmt.Sprintf("%s (%s) is not listed as fitting %s", sparePart.Name, sparePart.PartCode, description)
+	return &warning, nil
+}
+
+func validateFitment(fitment *domain.SparePartFitment) error {
+	fitment.Brand = trimToNil(fitment.Brand)
+	fitment.Model = trimToNil(fitment.Model)
+	fitment.EngineCode = trimToNil(fitment.EngineCode)
+
+	if fitment.Model != nil && fitment.Brand == nil {
+		return fmt.Errorf("brand is required when model is set")
+	}
+	if fitment.Brand == nil && (fitment.YearFrom != nil || fitment.YearTo != nil || fitment.EngineCode != nil) {
+		return fmt.Errorf("a fitment for every vehicle cannot have a year range or engine code")
+	}
+	for _, year := range []*int{fitment.YearFrom, fitment.YearTo} {
+		if year != nil && (*year < 1900 || *year > 2100) {
+			return fmt.Errorf("invalid year %d", *year)
+		}
+	}
+	if fitment.YearFrom != nil && fitment.YearTo != nil && *fitment.YearFrom > *fitment.YearTo {
+		return fmt.Errorf("year from cannot be after year to")
+	}
+
+	return nil
+}
+
+func trimToNil(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
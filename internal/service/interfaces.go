@@ -39,6 +39,11 @@ type CustomerService interface {
 	SearchCustomers(ctx context.Context, query string, page, limit int) ([]*domain.Customer, int, error)
 	UpdateCustomer(ctx context.Context, customer *domain.Customer) error
 	DeleteCustomer(ctx context.Context, id int, deletedBy int) error
+	AddCustomerVehicle(ctx context.Context, vehicle *domain.CustomerVehicle) error
+	GetCustomerVehicleByID(ctx context.Context, id int) (*domain.CustomerVehicle, error)
+	ListCustomerVehicles(ctx context.Context, customerID int) ([]*domain.CustomerVehicle, error)
+	UpdateCustomerVehicle(ctx context.Context, vehicle *domain.CustomerVehicle) error
+	DeleteCustomerVehicle(ctx context.Context, id int, deletedBy int) error
 }
 
 // SupplierService defines methods for supplier management
@@ -64,6 +69,7 @@ type VehicleCategoryService interface {
 // VehicleService defines methods for vehicle management
 type VehicleService interface {
 	CreateVehicle(ctx context.Context, vehicle *domain.Vehicle) error
+	PrepareVehicle(ctx context.Context, vehicle *domain.Vehicle) error
 	GetVehicleByID(ctx context.Context, id int) (*domain.Vehicle, error)
 	GetVehicleByCode(ctx context.Context, vehicleCode string) (*domain.Vehicle, error)
 	ListVehicles(ctx context.Context, page, limit int) ([]*domain.Vehicle, int, error)
@@ -74,6 +80,9 @@ type VehicleService interface {
 	DeleteVehicle(ctx context.Context, id int, deletedBy int) error
 	UpdateVehicleStatus(ctx context.Context, id int, status domain.VehicleStatus) error
 	CalculateHPP(ctx context.Context, vehicleID int) error
+	GetCostBreakdown(ctx context.Context, vehicleID int) (*domain.VehicleCostBreakdown, error)
+	AddCost(ctx context.Context, cost *domain.VehicleCost) error
+	DeleteCost(ctx context.Context, id int, deletedBy int) error
 }
 
 // VehiclePhotoService defines methods for vehicle photo management
@@ -116,6 +125,7 @@ type SalesService interface {
 // WorkOrderService defines methods for work order management
 type WorkOrderService interface {
 	CreateWorkOrder(ctx context.Context, workOrder *domain.WorkOrder) error
+	PrepareWorkOrder(ctx context.Context, workOrder *domain.WorkOrder) error
 	GetWorkOrderByID(ctx context.Context, id int) (*domain.WorkOrder, error)
 	GetWorkOrderByNumber(ctx context.Context, woNumber string) (*domain.WorkOrder, error)
 	ListWorkOrders(ctx context.Context, page, limit int) ([]*domain.WorkOrder, int, error)
@@ -123,17 +133,27 @@ type WorkOrderService interface {
 	ListWorkOrdersByMechanic(ctx context.Context, mechanicID int, page, limit int) ([]*domain.WorkOrder, int, error)
 	UpdateWorkOrder(ctx context.Context, workOrder *domain.WorkOrder) error
 	DeleteWorkOrder(ctx context.Context, id int, deletedBy int) error
-	UpdateWorkOrderStatus(ctx context.Context, id int, status domain.WorkOrderStatus) error
+	UpdateWorkOrderStatus(ctx context.Context, id int, status domain.WorkOrderStatus, changedBy int, reason *string) error
+	GetStatusHistory(ctx context.Context, id int) ([]*domain.WorkOrderStatusHistory, error)
 	UpdateWorkOrderProgress(ctx context.Context, id int, progress int) error
-	StartWorkOrder(ctx context.Context, id int) error
-	CompleteWorkOrder(ctx context.Context, id int) error
+	StartWorkOrder(ctx context.Context, id int, changedBy int) error
+	CompleteWorkOrder(ctx context.Context, id int, changedBy int) error
 	AssignMechanic(ctx context.Context, id int, mechanicID int) error
-	UsePartInWorkOrder(ctx context.Context, workOrderID int, partID int, quantity int, usedBy int) error
+	UsePartInWorkOrder(ctx context.Context, workOrderID int, partID int, locationID *int, quantity int, serialNumbers []string, usedBy int) (*domain.WorkOrderPart, error)
+	ApplyServiceKit(ctx context.Context, workOrderID int, kitID int, locationID *int, usedBy int) (*domain.ServiceKitApplication, error)
+	IssuePartRequest(ctx context.Context, request *domain.PartRequest, locationID *int, serialNumbers []string) (*domain.WorkOrderPart, error)
+	ReturnPart(ctx context.Context, workOrderPartID int, quantity int, reason string, serialNumbers []string, returnedBy int) (*domain.WorkOrderPartReturn, error)
+	AddTask(ctx context.Context, task *domain.WorkOrderTask) error
+	ListTasks(ctx context.Context, workOrderID int) ([]*domain.WorkOrderTask, error)
+	ListOpenTasksByMechanic(ctx context.Context, mechanicID int) ([]*domain.WorkOrderTask, error)
+	UpdateTask(ctx context.Context, task *domain.WorkOrderTask) error
+	UpdateTaskStatus(ctx context.Context, id int, status domain.WorkOrderTaskStatus, changedBy int) (*domain.WorkOrderTask, error)
+	DeleteTask(ctx context.Context, id int, deletedBy int) error
 }
 
 // SparePartService defines methods for spare part management
 type SparePartService interface {
-	CreateSparePart(ctx context.Context, sparePart *domain.SparePart) error
+	CreateSparePart(ctx context.Context, sparePart *domain.SparePart, createdBy int) error
 	GetSparePartByID(ctx context.Context, id int) (*domain.SparePart, error)
 	GetSparePartByCode(ctx context.Context, partCode string) (*domain.SparePart, error)
 	GetSparePartByBarcode(ctx context.Context, barcode string) (*domain.SparePart, error)
@@ -142,8 +162,11 @@ type SparePartService interface {
 	SearchSpareParts(ctx context.Context, query string, page, limit int) ([]*domain.SparePart, int, error)
 	UpdateSparePart(ctx context.Context, sparePart *domain.SparePart) error
 	DeleteSparePart(ctx context.Context, id int, deletedBy int) error
-	AdjustStock(ctx context.Context, partID int, adjustment int, notes string, adjustedBy int) error
+	AdjustStock(ctx context.Context, partID int, locationID *int, adjustment int, serialNumbers []string, notes string, adjustedBy int) error
 	CheckLowStock(ctx context.Context) ([]*domain.SparePart, error)
+	GetCostLayers(ctx context.Context, partID int, includeClosed bool) (map[string]interface{}, error)
+	AssignBarcode(ctx context.Context, id int, symbology domain.BarcodeSymbology, replace bool) (*domain.SparePart, error)
+	AssignMissingBarcodes(ctx context.Context, symbology domain.BarcodeSymbology) ([]*domain.SparePart, error)
 }
 
 // StockMovementService defines methods for stock movement management
@@ -168,6 +191,10 @@ type NotificationService interface {
 	NotifyWorkOrderAssigned(ctx context.Context, workOrderID int, mechanicID int) error
 	NotifyLowStock(ctx context.Context, partID int) error
 	NotifyWorkOrderUpdate(ctx context.Context, workOrderID int, message string) error
+	NotifyPayableOverdue(ctx context.Context, payable *domain.Payable) error
+	NotifyPartRequestReviewed(ctx context.Context, request *domain.PartRequest) error
+	NotifyReorderDraft(ctx context.Context, po *domain.PurchaseOrder) error
+	NotifyExpiringStock(ctx context.Context, expired, expiring int, days int) error
 	GetUnreadCount(ctx context.Context, userID int) (int, error)
 }
 
@@ -192,13 +219,15 @@ type FileService interface {
 	ValidateImage(file *multipart.FileHeader) error
 	ValidateDocument(file *multipart.FileHeader) error
 	GetFileURL(filePath string) string
+	GetFilePath(filePath string) string
 }
 
 // InvoiceService defines methods for invoice generation
 type InvoiceService interface {
 	GenerateSalesInvoicePDF(ctx context.Context, invoiceID int) ([]byte, error)
 	GeneratePurchaseInvoicePDF(ctx context.Context, invoiceID int) ([]byte, error)
-	GenerateWorkOrderPDF(ctx context.Context, workOrderID int) ([]byte, error)
+	GenerateWorkOrderPDF(ctx context.Context, workOrderID int, includePhotos bool) ([]byte, error)
+	GenerateConsignmentStatementPDF(ctx context.Context, consignmentID int) ([]byte, error)
 	GenerateReportPDF(ctx context.Context, reportType string, data interface{}) ([]byte, error)
 	SendInvoiceEmail(ctx context.Context, invoiceID int, email string) error
 }
@@ -209,4 +238,231 @@ type DashboardService interface {
 	GetRecentActivities(ctx context.Context, userID int, role domain.UserRole, limit int) ([]map[string]interface{}, error)
 	GetPerformanceMetrics(ctx context.Context, startDate, endDate time.Time) (map[string]interface{}, error)
 	GetInventoryAlerts(ctx context.Context) (map[string]interface{}, error)
-}
\ No newline at end of file
+}
+// WarrantyService defines methods for post-sale warranty management
+type WarrantyService interface {
+	CreateWarranty(ctx context.Context, warranty *domain.Warranty) error
+	GetWarrantyByID(ctx context.Context, id int) (*domain.Warranty, error)
+	ListWarranties(ctx context.Context, page, limit int) ([]*domain.Warranty, int, error)
+	LookupWarranties(ctx context.Context, plateNumber, chassisNumber string) ([]*domain.Warranty, error)
+	VoidWarranty(ctx context.Context, id int) error
+	ExpireOverdueWarranties(ctx context.Context) (int, error)
+	CreateClaim(ctx context.Context, claim *domain.WarrantyClaim, mechanicID int) error
+	ListClaims(ctx context.Context, warrantyID int) ([]*domain.WarrantyClaim, error)
+	GetWarrantyCostReport(ctx context.Context, startDate, endDate time.Time) (map[string]interface{}, error)
+}
+
+// PurchaseOrderService defines methods for spare part purchasing and goods receipt
+type PurchaseOrderService interface {
+	CreatePurchaseOrder(ctx context.Context, po *domain.PurchaseOrder) error
+	GetPurchaseOrderByID(ctx context.Context, id int) (*domain.PurchaseOrder, error)
+	ListPurchaseOrders(ctx context.Context, page, limit int) ([]*domain.PurchaseOrder, int, error)
+	ListPurchaseOrdersByStatus(ctx context.Context, status domain.PurchaseOrderStatus, page, limit int) ([]*domain.PurchaseOrder, int, error)
+	SubmitPurchaseOrder(ctx context.Context, id int) error
+	CancelPurchaseOrder(ctx context.Context, id int) error
+	ReceiveGoods(ctx context.Context, receipt *domain.GoodsReceipt) error
+	ListGoodsReceipts(ctx context.Context, purchaseOrderID int) ([]*domain.GoodsReceipt, error)
+	ListBackorders(ctx context.Context) ([]*domain.PurchaseOrderItem, error)
+}
+
+// PayableService defines methods for accounts payable management
+type PayableService interface {
+	PreparePayable(ctx context.Context, invoice *domain.PurchaseInvoice) error
+	GetPayableByID(ctx context.Context, id int) (*domain.Payable, error)
+	GetPayableByPurchaseInvoiceID(ctx context.Context, purchaseInvoiceID int) (*domain.Payable, error)
+	GetPayableByConsignmentID(ctx context.Context, consignmentID int) (*domain.Payable, error)
+	GetPayableByWorkOrderSubletID(ctx context.Context, subletID int) (*domain.Payable, error)
+	ListPayables(ctx context.Context, page, limit int) ([]*domain.Payable, int, error)
+	ListPayablesByStatus(ctx context.Context, status domain.PayableStatus, page, limit int) ([]*domain.Payable, int, error)
+	RecordPayment(ctx context.Context, payment *domain.PayablePayment) error
+	GetPaymentByID(ctx context.Context, id int) (*domain.PayablePayment, error)
+	AttachTransferProof(ctx context.Context, paymentID int, transferProof string) error
+	NotifyOverduePayables(ctx context.Context) (int, error)
+	GetAgingReport(ctx context.Context, asOf time.Time, counterpartyType string) (map[string]interface{}, error)
+}
+
+// ConsignmentService defines methods for consignment (titip jual) vehicles
+type ConsignmentService interface {
+	CreateConsignment(ctx context.Context, consignment *domain.Consignment) error
+	GetConsignmentByID(ctx context.Context, id int) (*domain.Consignment, error)
+	ListConsignments(ctx context.Context, page, limit int) ([]*domain.Consignment, int, error)
+	ListConsignmentsByStatus(ctx context.Context, status domain.ConsignmentStatus, page, limit int) ([]*domain.Consignment, int, error)
+	CalculateCommission(ctx context.Context, vehicleID int, salePrice float64) (float64, error)
+	PrepareSale(ctx context.Context, invoice *domain.SalesInvoice) (*domain.Consignment, error)
+}
+
+// VehicleDocumentService defines methods for vehicle legal document custody
+type VehicleDocumentService interface {
+	CreateDocument(ctx context.Context, document *domain.VehicleDocument) error
+	GetDocumentByID(ctx context.Context, id int) (*domain.VehicleDocument, error)
+	ListDocumentsByVehicle(ctx context.Context, vehicleID int) ([]*domain.VehicleDocument, error)
+	UpdateDocument(ctx context.Context, document *domain.VehicleDocument) error
+	DeleteDocument(ctx context.Context, id int, deletedBy int) error
+	AttachScan(ctx context.Context, id int, scanFile string) error
+	HandOverDocument(ctx context.Context, document *domain.VehicleDocument) error
+	ListExpiringDocuments(ctx context.Context, days int) ([]*domain.VehicleDocument, error)
+	CheckSaleDocuments(ctx context.Context, vehicleID int) ([]string, error)
+}
+
+// MechanicService defines methods for mechanic availability and automatic work order assignment
+type MechanicService interface {
+	AssignMechanic(ctx context.Context, vehicle *domain.Vehicle) (*domain.MechanicAssignment, error)
+	CheckAvailable(ctx context.Context, mechanicID int) error
+	ListWorkloads(ctx context.Context) ([]*domain.MechanicWorkload, error)
+	SetSkills(ctx context.Context, mechanicID int, brands []string) error
+	CreateLeave(ctx context.Context, leave *domain.MechanicLeave) error
+	ListLeaves(ctx context.Context, mechanicID int) ([]*domain.MechanicLeave, error)
+	DeleteLeave(ctx context.Context, id int, deletedBy int) error
+	GetWorkingHours(ctx context.Context, mechanicID int) ([]*domain.MechanicWorkingHours, error)
+	SetWorkingHours(ctx context.Context, mechanicID int, hours []*domain.MechanicWorkingHours) error
+}
+
+// LaborService defines methods for mechanic time tracking and labor rates
+type LaborService interface {
+	StartTimer(ctx context.Context, entry *domain.LaborTimeEntry) error
+	PauseTimer(ctx context.Context, id int, userID int, role domain.UserRole) (*domain.LaborTimeEntry, error)
+	ResumeTimer(ctx context.Context, id int, userID int, role domain.UserRole) (*domain.LaborTimeEntry, error)
+	StopTimer(ctx context.Context, id int, userID int, role domain.UserRole) (*domain.LaborTimeEntry, error)
+	ListWorkOrderEntries(ctx context.Context, workOrderID int) ([]*domain.LaborTimeEntry, error)
+	GetTimesheet(ctx context.Context, mechanicID int, startDate, endDate time.Time) (map[string]interface{}, error)
+	ListRates(ctx context.Context) ([]*domain.LaborRate, error)
+	SetRate(ctx context.Context, rate *domain.LaborRate) error
+	GetMechanicProfile(ctx context.Context, mechanicID int) (*domain.MechanicLaborProfile, error)
+	SetMechanicProfile(ctx context.Context, profile *domain.MechanicLaborProfile) error
+}
+
+// PartRequestService defines methods for mechanic part requests and their approval
+type PartRequestService interface {
+	CreateRequest(ctx context.Context, request *domain.PartRequest) error
+	GetRequestByID(ctx context.Context, id int) (*domain.PartRequest, error)
+	ListRequestsByWorkOrder(ctx context.Context, workOrderID int) ([]*domain.PartRequest, error)
+	ListRequestsByStatus(ctx context.Context, status domain.PartRequestStatus, page, limit int) ([]*domain.PartRequest, int, error)
+	ApproveRequest(ctx context.Context, id int, locationID *int, quantity int, serialNumbers []string, reviewedBy int, notes *string) (*domain.PartRequest, error)
+	RejectRequest(ctx context.Context, id int, reviewedBy int, notes *string) (*domain.PartRequest, error)
+}
+
+// ServiceInvoiceService defines methods for billing customer service work orders
+type ServiceInvoiceService interface {
+	CreateInvoice(ctx context.Context, workOrderID int, laborAmount *float64, discountAmount float64, dueDate *time.Time, notes *string, createdBy int) (*domain.ServiceInvoice, error)
+	GetInvoiceByID(ctx context.Context, id int) (*domain.ServiceInvoice, error)
+	ListInvoices(ctx context.Context, page, limit int) ([]*domain.ServiceInvoice, int, error)
+	ListInvoicesByStatus(ctx context.Context, status domain.ServiceInvoiceStatus, page, limit int) ([]*domain.ServiceInvoice, int, error)
+	RecordPayment(ctx context.Context, payment *domain.ServiceInvoicePayment) error
+}
+
+// WorkOrderAttachmentService defines methods for work order photos and documents
+type WorkOrderAttachmentService interface {
+	AddAttachment(ctx context.Context, attachment *domain.WorkOrderAttachment, uploaderRole domain.UserRole) error
+	ListAttachments(ctx context.Context, workOrderID int) ([]*domain.WorkOrderAttachment, error)
+	DeleteAttachment(ctx context.Context, id int, deletedBy int, role domain.UserRole) error
+}
+
+// ScheduleService defines methods for workshop bays, planned work and estimated completion
+type ScheduleService interface {
+	CreateBay(ctx context.Context, bay *domain.WorkshopBay) error
+	ListBays(ctx context.Context) ([]*domain.WorkshopBay, error)
+	UpdateBay(ctx context.Context, bay *domain.WorkshopBay) error
+	DeleteBay(ctx context.Context, id int, deletedBy int) error
+	ScheduleWork(ctx context.Context, schedule *domain.WorkOrderSchedule) error
+	UpdateSchedule(ctx context.Context, schedule *domain.WorkOrderSchedule) error
+	DeleteSchedule(ctx context.Context, id int, deletedBy int) error
+	ListWorkOrderSchedule(ctx context.Context, workOrderID int) ([]*domain.WorkOrderSchedule, error)
+	GetCalendar(ctx context.Context, view string, date time.Time, bayID, mechanicID *int) (*domain.ScheduleCalendar, error)
+	RecalculateEstimates(ctx context.Context) (int, error)
+}
+
+// WorkOrderSubletService defines methods for sublet repairs done by outside vendors
+type WorkOrderSubletService interface {
+	CreateSublet(ctx context.Context, sublet *domain.WorkOrderSublet) error
+	GetSubletByID(ctx context.Context, id int) (*domain.WorkOrderSublet, error)
+	ListSublets(ctx context.Context, workOrderID int) ([]*domain.WorkOrderSublet, error)
+	CompleteSublet(ctx context.Context, id int, vendorInvoiceNumber string, cost *float64, dueDate *time.Time, completedBy int) (*domain.WorkOrderSublet, error)
+	CancelSublet(ctx context.Context, id int) error
+	AttachInvoice(ctx context.Context, id int, attachmentPath string) (*domain.WorkOrderSublet, error)
+}
+
+// StockCountService defines methods for stock opname (physical count) sessions
+type StockCountService interface {
+	CreateCount(ctx context.Context, count *domain.StockCount, sparePartIDs []int) error
+	GetCountByID(ctx context.Context, id int) (*domain.StockCount, error)
+	ListCounts(ctx context.Context, status domain.StockCountStatus, page, limit int) ([]*domain.StockCount, int, error)
+	ListItems(ctx context.Context, id int, filter string) ([]*domain.StockCountItem, error)
+	ScanItem(ctx context.Context, id int, code string, quantity int, countedBy int) (*domain.StockCountItem, error)
+	SetItemCount(ctx context.Context, id int, itemID int, quantity *int, countedBy int, notes *string) (*domain.StockCountItem, error)
+	SubmitCount(ctx context.Context, id int, submittedBy int) (*domain.StockCount, error)
+	ReopenCount(ctx context.Context, id int, userID int) (*domain.StockCount, error)
+	ApproveCount(ctx context.Context, id int, approvedBy int) (*domain.StockCount, error)
+	CancelCount(ctx context.Context, id int, userID int) (*domain.StockCount, error)
+}
+
+// StockLocationService defines methods for stock locations, bins and per-location stock
+type StockLocationService interface {
+	CreateLocation(ctx context.Context, location *domain.StockLocation) error
+	GetLocationByID(ctx context.Context, id int) (*domain.StockLocation, error)
+	ListLocations(ctx context.Context, includeInactive bool) ([]*domain.StockLocation, error)
+	UpdateLocation(ctx context.Context, location *domain.StockLocation) error
+	SetDefaultLocation(ctx context.Context, id int) error
+	DeleteLocation(ctx context.Context, id int, deletedBy int) error
+	GetLocationStock(ctx context.Context, id int) ([]*domain.SparePartStock, error)
+	GetSparePartStock(ctx context.Context, sparePartID int) ([]*domain.SparePartStock, error)
+	UpdateStockSettings(ctx context.Context, stock *domain.SparePartStock) (*domain.SparePartStock, error)
+	ListLowStock(ctx context.Context, locationID *int) ([]*domain.SparePartStock, error)
+}
+
+// StockTransferService defines methods for stock transfers between locations
+type StockTransferService interface {
+	CreateTransfer(ctx context.Context, transfer *domain.StockTransfer) error
+	GetTransferByID(ctx context.Context, id int) (*domain.StockTransfer, error)
+	ListTransfers(ctx context.Context, status domain.StockTransferStatus, locationID *int, page, limit int) ([]*domain.StockTransfer, int, error)
+	ShipTransfer(ctx context.Context, id int, shippedBy int) (*domain.StockTransfer, error)
+	ReceiveTransfer(ctx context.Context, id int, receivedBy int) (*domain.StockTransfer, error)
+	CancelTransfer(ctx context.Context, id int, cancelledBy int) (*domain.StockTransfer, error)
+}
+
+// ReplenishmentService defines methods for consumption-based reorder suggestions
+type ReplenishmentService interface {
+	GetPolicy() domain.ReplenishmentPolicy
+	ListSuggestions(ctx context.Context, supplierID *int, includeAll bool) ([]*domain.ReorderSuggestion, error)
+	UpdateSettings(ctx context.Context, settings *domain.ReorderSettings) error
+	UpdateSupplierLeadTime(ctx context.Context, supplierID int, leadTimeDays int) error
+	GenerateDraftPurchaseOrders(ctx context.Context, supplierID *int, createdBy int) (*domain.ReorderRun, error)
+	RunScheduledReorder(ctx context.Context) (*domain.ReorderRun, error)
+}
+
+// LabelService defines methods for barcode and QR label printing
+type LabelService interface {
+	ListFormats() []*domain.LabelFormat
+	GenerateSparePartLabels(ctx context.Context, req *domain.LabelPrintRequest) ([]byte, error)
+	GenerateVehicleLabels(ctx context.Context, labelType domain.VehicleLabelType, req *domain.LabelPrintRequest) ([]byte, error)
+}
+
+// FitmentService defines methods for the part-to-vehicle fitment catalog
+type FitmentService interface {
+	ListFitments(ctx context.Context, sparePartID int) ([]*domain.SparePartFitment, error)
+	AddFitment(ctx context.Context, fitment *domain.SparePartFitment) error
+	UpdateFitment(ctx context.Context, fitment *domain.SparePartFitment) error
+	DeleteFitment(ctx context.Context, id int, deletedBy int) error
+	ListCompatibleParts(ctx context.Context, filter *domain.CompatiblePartFilter, page, limit int) ([]*domain.SparePart, int, error)
+	GetWorkOrderVehicle(ctx context.Context, workOrderID int) (*domain.FitmentVehicle, error)
+}
+
+// ServiceKitService defines methods for service kit management
+type ServiceKitService interface {
+	CreateKit(ctx context.Context, kit *domain.ServiceKit) error
+	GetKit(ctx context.Context, id int) (*domain.ServiceKit, error)
+	ListKits(ctx context.Context, activeOnly bool, page, limit int) ([]*domain.ServiceKit, int, error)
+	UpdateKit(ctx context.Context, kit *domain.ServiceKit) error
+	DeleteKit(ctx context.Context, id int, deletedBy int) error
+}
+
+// SparePartTrackingService defines business logic for spare part batch,
+// expiry and serial number tracking
+type SparePartTrackingService interface {
+	UpdateTracking(ctx context.Context, sparePartID int, trackBatches, trackSerials bool) (*domain.SparePart, error)
+	ListBatches(ctx context.Context, sparePartID int, locationID *int, openOnly bool) ([]*domain.SparePartBatch, error)
+	ListExpiringBatches(ctx context.Context, days int, locationID *int) ([]*domain.SparePartBatch, error)
+	ListSerials(ctx context.Context, sparePartID int, status *domain.SerialStatus, page, limit int) ([]*domain.SparePartSerial, int, error)
+	FindSerial(ctx context.Context, serialNumber string) ([]*domain.SparePartSerial, error)
+	RegisterSerials(ctx context.Context, sparePartID int, locationID *int, serialNumbers []string) error
+	NotifyExpiringStock(ctx context.Context) (int, error)
+}
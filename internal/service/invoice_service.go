@@ -4,22 +4,32 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"pos-final/internal/domain"
+	"strings"
 	"time"
 
 	"github.com/jung-kurt/gofpdf"
 )
 
 type invoiceServiceImpl struct {
-	salesService     SalesService
-	purchaseService  PurchaseService
-	workOrderService WorkOrderService
+	salesService       SalesService
+	purchaseService    PurchaseService
+	workOrderService   WorkOrderService
+	consignmentService ConsignmentService
+	attachmentService  WorkOrderAttachmentService
+	fileService        FileService
 }
 
-func NewInvoiceService(salesService SalesService, purchaseService PurchaseService, workOrderService WorkOrderService) InvoiceService {
+func NewInvoiceService(salesService SalesService, purchaseService PurchaseService, workOrderService WorkOrderService, consignmentService ConsignmentService, attachmentService WorkOrderAttachmentService, fileService FileService) InvoiceService {
 	return &invoiceServiceImpl{
-		salesService:     salesService,
-		purchaseService:  purchaseService,
-		workOrderService: workOrderService,
+		salesService:       salesService,
+		purchaseService:    purchaseService,
+		workOrderService:   workOrderService,
+		consignmentService: consignmentService,
+		attachmentService:  attachmentService,
+		fileService:        fileService,
 	}
 }
 
@@ -180,12 +190,40 @@ func (s *invoiceServiceImpl) GeneratePurchaseInvoicePDF(ctx context.Context, inv
 	pdf.Cell(190, 10, "Purchase Details")
 	pdf.Ln(10)
 
+	// Per-vehicle breakdown for bulk purchases
+	if len(invoice.Items) > 1 {
+		pdf.SetFont("Arial", "B", 10)
+		pdf.Cell(70, 7, "Vehicle")
+		pdf.Cell(40, 7, "Price")
+		pdf.Cell(40, 7, "Shared Cost")
+		pdf.Cell(40, 7, "Total")
+		pdf.Ln(7)
+
+		pdf.SetFont("Arial", "", 10)
+		for _, item := range invoice.Items {
+			pdf.Cell(70, 6, getVehicleDescription(item.Vehicle))
+			pdf.Cell(40, 6, fmt.Sprintf("Rp %s", formatCurrency(item.Price)))
+			pdf.Cell(40, 6, fmt.Sprintf("Rp %s", formatCurrency(item.AllocatedCost)))
+			pdf.Cell(40, 6, fmt.Sprintf("Rp %s", formatCurrency(item.TotalCost)))
+			pdf.Ln(6)
+		}
+		pdf.Ln(4)
+	}
+
 	pdf.SetFont("Arial", "B", 11)
 	pdf.Cell(70, 8, "Purchase Price:")
 	pdf.SetFont("Arial", "", 11)
 	pdf.Cell(60, 8, fmt.Sprintf("Rp %s", formatCurrency(invoice.PurchasePrice)))
 	pdf.Ln(8)
 
+	if invoice.SharedCost > 0 {
+		pdf.SetFont("Arial", "B", 11)
+		pdf.Cell(70, 8, "Shared Costs:")
+		pdf.SetFont("Arial", "", 11)
+		pdf.Cell(60, 8, fmt.Sprintf("Rp %s", formatCurrency(invoice.SharedCost)))
+		pdf.Ln(8)
+	}
+
 	pdf.SetFont("Arial", "B", 11)
 	pdf.Cell(70, 8, "Final Price:")
 	pdf.SetFont("Arial", "", 11)
@@ -231,7 +269,7 @@ func (s *invoiceServiceImpl) GeneratePurchaseInvoicePDF(ctx context.Context, inv
 	return buf.Bytes(), nil
 }
 
-func (s *invoiceServiceImpl) GenerateWorkOrderPDF(ctx context.Context, workOrderID int) ([]byte, error) {
+func (s *invoiceServiceImpl) GenerateWorkOrderPDF(ctx context.Context, workOrderID int, includePhotos bool) ([]byte, error) {
 	// Get work order data
 	workOrder, err := s.workOrderService.GetWorkOrderByID(ctx, workOrderID)
 	if err != nil {
@@ -284,6 +322,49 @@ func (s *invoiceServiceImpl) GenerateWorkOrderPDF(ctx context.Context, workOrder
 	pdf.MultiCell(190, 6, workOrder.Description, "", "", false)
 	pdf.Ln(10)
 
+	// Parts used; quantities and costs are net of returns
+	if len(workOrder.Parts) > 0 {
+		pdf.SetFont("Arial", "B", 14)
+		pdf.Cell(190, 10, "Parts Used")
+		pdf.Ln(10)
+
+		pdf.SetFont("Arial", "B", 10)
+		pdf.Cell(70, 7, "Part")
+		pdf.Cell(20, 7, "Used")
+		pdf.Cell(20, 7, "Returned")
+		pdf.Cell(40, 7, "Unit Cost")
+		pdf.Cell(40, 7, "Total")
+		pdf.Ln(7)
+
+		pdf.SetFont("Arial", "", 10)
+		for _, part := range workOrder.Parts {
+			pdf.Cell(70, 6, fmt.Sprintf("%s - %s", part.SparePart.PartCode, part.SparePart.Name))
+			pdf.Cell(20, 6, fmt.Sprintf("%d", part.QuantityUsed))
+			pdf.Cell(20, 6, fmt.Sprintf("%d", part.QuantityReturned))
+			pdf.Cell(40, 6, fmt.Sprintf("Rp %s", formatCurrency(part.UnitCost)))
+			pdf.Cell(40, 6, fmt.Sprintf("Rp %s", formatCurrency(part.TotalCost)))
+			pdf.Ln(6)
+		}
+		pdf.Ln(4)
+	}
+
+	if len(workOrder.PartReturns) > 0 {
+		pdf.SetFont("Arial", "B", 14)
+		pdf.Cell(190, 10, "Returned Parts")
+		pdf.Ln(10)
+
+		pdf.SetFont("Arial", "", 10)
+		for _, partReturn := range workOrder.PartReturns {
+			pdf.Cell(30, 6, partReturn.ReturnedAt.Format("2006-01-02"))
+			pdf.Cell(60, 6, partReturn.SparePart.Name)
+			pdf.Cell(20, 6, fmt.Sprintf("%d", partReturn.Quantity))
+			pdf.Cell(40, 6, fmt.Sprintf("Rp %s", formatCurrency(partReturn.TotalCost)))
+			pdf.Ln(6)
+			pdf.MultiCell(190, 5, fmt.Sprintf("Reason: %s", partReturn.Reason), "", "", false)
+		}
+		pdf.Ln(4)
+	}
+
 	// Cost summary
 	pdf.SetFont("Arial", "B", 14)
 	pdf.Cell(190, 10, "Cost Summary")
@@ -332,6 +413,210 @@ func (s *invoiceServiceImpl) GenerateWorkOrderPDF(ctx context.Context, workOrder
 	pdf.Ln(4)
 	pdf.Cell(190, 6, fmt.Sprintf("Generated on: %s", time.Now().Format("2006-01-02 15:04:05")))
 
+	if includePhotos {
+		attachments, err := s.attachmentService.ListAttachments(ctx, workOrderID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get work order attachments: %w", err)
+		}
+		s.addWorkOrderPhotos(pdf, attachments)
+	}
+
+	var buf bytes.Buffer
+	err = pdf.Output(&buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// addWorkOrderPhotos appends the photo attachments on their own pages, one
+// per row with the stage and caption underneath. Formats the PDF library
+// cannot embed and files missing from disk are skipped.
+func (s *invoiceServiceImpl) addWorkOrderPhotos(pdf *gofpdf.Fpdf, attachments []*domain.WorkOrderAttachment) {
+	const maxWidth, maxHeight = 120.0, 90.0
+	started := false
+
+	for _, attachment := range attachments {
+		if attachment.FileType != domain.AttachmentFileTypePhoto {
+			continue
+		}
+
+		imageType := strings.TrimPrefix(strings.ToLower(filepath.Ext(attachment.FilePath)), ".")
+		if imageType != "jpg" && imageType != "jpeg" && imageType != "png" && imageType != "gif" {
+			continue
+		}
+
+		path := s.fileService.GetFilePath(attachment.FilePath)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+
+		options := gofpdf.ImageOptions{ImageType: imageType, ReadDpi: true}
+		info := pdf.RegisterImageOptions(path, options)
+		if !pdf.Ok() || info == nil {
+			pdf.ClearError()
+			continue
+		}
+
+		width, height := maxWidth, maxWidth*info.Height()/info.Width()
+		if height > maxHeight {
+			width, height = maxHeight*info.Width()/info.Height(), maxHeight
+		}
+
+		if !started || pdf.GetY()+height+15 > 270 {
+			pdf.AddPage()
+			if !started {
+				pdf.SetFont("Arial", "B", 14)
+				pdf.Cell(190, 10, "Photos")
+				pdf.Ln(12)
+				started = true
+			}
+		}
+
+		pdf.ImageOptions(path, 10, pdf.GetY(), width, height, false, options, 0, "")
+		pdf.SetY(pdf.GetY() + height + 2)
+
+		label := strings.ToUpper(attachment.Stage.String())
+		if attachment.Caption != nil && *attachment.Caption != "" {
+			label = fmt.Sprintf("%s - %s", label, *attachment.Caption)
+		}
+		pdf.SetFont("Arial", "", 9)
+		pdf.MultiCell(190, 5, label, "", "", false)
+		pdf.Ln(6)
+	}
+}
+
+func (s *invoiceServiceImpl) GenerateConsignmentStatementPDF(ctx context.Context, consignmentID int) ([]byte, error) {
+	// Get consignment data
+	consignment, err := s.consignmentService.GetConsignmentByID(ctx, consignmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consignment: %w", err)
+	}
+	if consignment.Status != domain.ConsignmentStatusSold {
+		return nil, fmt.Errorf("consignment vehicle has not been sold yet")
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+
+	// Set font
+	pdf.SetFont("Arial", "B", 16)
+
+	// Header
+	pdf.Cell(190, 10, "CONSIGNMENT SETTLEMENT STATEMENT")
+	pdf.Ln(15)
+
+	// Company info
+	pdf.SetFont("Arial", "B", 12)
+	pdf.Cell(50, 8, "POS Vehicle System")
+	pdf.Ln(6)
+	pdf.SetFont("Arial", "", 10)
+	pdf.Cell(50, 6, "Vehicle Sales & Repair Center")
+	pdf.Ln(15)
+
+	// Consignment details
+	pdf.SetFont("Arial", "B", 12)
+	pdf.Cell(40, 8, "Consignment #:")
+	pdf.SetFont("Arial", "", 12)
+	pdf.Cell(60, 8, consignment.ConsignmentNumber)
+	pdf.Ln(8)
+
+	pdf.SetFont("Arial", "B", 12)
+	pdf.Cell(40, 8, "Intake Date:")
+	pdf.SetFont("Arial", "", 12)
+	pdf.Cell(60, 8, consignment.IntakeDate.Format("2006-01-02"))
+	pdf.Ln(8)
+
+	pdf.SetFont("Arial", "B", 12)
+	pdf.Cell(40, 8, "Owner:")
+	pdf.SetFont("Arial", "", 12)
+	pdf.Cell(60, 8, consignment.Owner.Name)
+	pdf.Ln(8)
+
+	vehicle := consignment.Vehicle
+	plateNumber := "N/A"
+	if vehicle.PlateNumber != nil {
+		plateNumber = *vehicle.PlateNumber
+	}
+	pdf.SetFont("Arial", "B", 12)
+	pdf.Cell(40, 8, "Vehicle:")
+	pdf.SetFont("Arial", "", 12)
+	pdf.Cell(60, 8, fmt.Sprintf("%s %s %d (%s)", vehicle.Brand, vehicle.Model, vehicle.Year, plateNumber))
+	pdf.Ln(15)
+
+	// Settlement details
+	pdf.SetFont("Arial", "B", 14)
+	pdf.Cell(190, 10, "Settlement Details")
+	pdf.Ln(10)
+
+	var salePrice, commission float64
+	if consignment.SalePrice != nil {
+		salePrice = *consignment.SalePrice
+	}
+	if consignment.CommissionAmount != nil {
+		commission = *consignment.CommissionAmount
+	}
+
+	pdf.SetFont("Arial", "B", 11)
+	pdf.Cell(70, 8, "Sale Price:")
+	pdf.SetFont("Arial", "", 11)
+	pdf.Cell(60, 8, fmt.Sprintf("Rp %s", formatCurrency(salePrice)))
+	pdf.Ln(8)
+
+	pdf.SetFont("Arial", "B", 11)
+	pdf.Cell(70, 8, "Dealer Commission:")
+	pdf.SetFont("Arial", "", 11)
+	pdf.Cell(60, 8, fmt.Sprintf("Rp %s", formatCurrency(commission)))
+	pdf.Ln(8)
+
+	pdf.SetFont("Arial", "B", 12)
+	pdf.Cell(70, 8, "Net Payable to Owner:")
+	pdf.SetFont("Arial", "B", 12)
+	pdf.Cell(60, 8, fmt.Sprintf("Rp %s", formatCurrency(consignment.AgreedNetPrice)))
+	pdf.Ln(15)
+
+	// Payout status
+	if payable := consignment.Payable; payable != nil {
+		pdf.SetFont("Arial", "B", 14)
+		pdf.Cell(190, 10, "Payout")
+		pdf.Ln(10)
+
+		pdf.SetFont("Arial", "B", 11)
+		pdf.Cell(70, 8, "Due Date:")
+		pdf.SetFont("Arial", "", 11)
+		pdf.Cell(60, 8, payable.DueDate.Format("2006-01-02"))
+		pdf.Ln(8)
+
+		for _, payment := range payable.Payments {
+			pdf.SetFont("Arial", "", 10)
+			pdf.Cell(40, 6, payment.PaymentDate.Format("2006-01-02"))
+			pdf.Cell(50, 6, payment.PaymentNumber)
+			pdf.Cell(30, 6, string(payment.PaymentMethod))
+			pdf.Cell(50, 6, fmt.Sprintf("Rp %s", formatCurrency(payment.Amount)))
+			pdf.Ln(6)
+		}
+
+		pdf.SetFont("Arial", "B", 11)
+		pdf.Cell(70, 8, "Paid:")
+		pdf.SetFont("Arial", "", 11)
+		pdf.Cell(60, 8, fmt.Sprintf("Rp %s", formatCurrency(payable.PaidAmount)))
+		pdf.Ln(8)
+
+		pdf.SetFont("Arial", "B", 11)
+		pdf.Cell(70, 8, "Outstanding:")
+		pdf.SetFont("Arial", "", 11)
+		pdf.Cell(60, 8, fmt.Sprintf("Rp %s", formatCurrency(payable.OutstandingAmount())))
+		pdf.Ln(15)
+	}
+
+	// Footer
+	pdf.SetY(-30)
+	pdf.SetFont("Arial", "", 9)
+	pdf.Cell(190, 6, "Consignment Settlement for Vehicle Owner")
+	pdf.Ln(4)
+	pdf.Cell(190, 6, fmt.Sprintf("Generated on: %s", time.Now().Format("2006-01-02 15:04:05")))
+
 	var buf bytes.Buffer
 	err = pdf.Output(&buf)
 	if err != nil {
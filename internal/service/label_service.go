@@ -0,0 +1,452 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"pos-final/internal/domain"
+	"pos-final/internal/repository"
+	"strconv"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/ean"
+	"github.com/boombuler/barcode/qr"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Label stock the shop can print on. A4 sheets follow the common adhesive
+// label layouts; thermal formats are one label per page.
+var labelFormats = []*domain.LabelFormat{
+	{Code: "a4-24", Name: "A4 sheet, 24 labels 70 x 37 mm", PageWidth: 210, PageHeight: 297, LabelWidth: 70, LabelHeight: 37, Columns: 3, Rows: 8, MarginTop: 0.5},
+	{Code: "a4-40", Name: "A4 sheet, 40 labels 52.5 x 29.7 mm", PageWidth: 210, PageHeight: 297, LabelWidth: 52.5, LabelHeight: 29.7, Columns: 4, Rows: 10},
+	{Code: "a4-65", Name: "A4 sheet, 65 labels 38.1 x 21.2 mm", PageWidth: 210, PageHeight: 297, LabelWidth: 38.1, LabelHeight: 21.2, Columns: 5, Rows: 13, MarginLeft: 4.75, MarginTop: 10.7, GapX: 2.5},
+	{Code: "a4-2", Name: "A4 sheet, 2 labels 210 x 148.5 mm", PageWidth: 210, PageHeight: 297, LabelWidth: 210, LabelHeight: 148.5, Columns: 1, Rows: 2},
+	{Code: "thermal-40x30", Name: "Thermal label 40 x 30 mm", PageWidth: 40, PageHeight: 30, LabelWidth: 40, LabelHeight: 30, Columns: 1, Rows: 1},
+	{Code: "thermal-50x30", Name: "Thermal label 50 x 30 mm", PageWidth: 50, PageHeight: 30, LabelWidth: 50, LabelHeight: 30, Columns: 1, Rows: 1},
+	{Code: "thermal-58x40", Name: "Thermal label 58 x 40 mm", PageWidth: 58, PageHeight: 40, LabelWidth: 58, LabelHeight: 40, Columns: 1, Rows: 1},
+	{Code: "thermal-100x50", Name: "Thermal label 100 x 50 mm", PageWidth: 100, PageHeight: 50, LabelWidth: 100, LabelHeight: 50, Columns: 1, Rows: 1},
+}
+
+// customThermalFormat is a thermal label of any size given with the request
+const customThermalFormat = "thermal"
+
+const maxLabelCopies = 500
+
+type labelService struct {
+	sparePartRepo repository.SparePartRepository
+	vehicleRepo   repository.VehicleRepository
+	vehicleURL    string
+}
+
+// NewLabelService creates a new label service. vehicleURL is the base URL
+// vehicle QR codes point to; the vehicle ID is appended.
+func NewLabelService(
+	sparePartRepo repository.SparePartRepository,
+	vehicleRepo repository.VehicleRepository,
+	vehicleURL string,
+) LabelService {
+	return &labelService{
+		sparePartRepo: sparePartRepo,
+		vehicleRepo:   vehicleRepo,
+		vehicleURL:    vehicleURL,
+	}
+}
+
+func (s *labelService) ListFormats() []*domain.LabelFormat {
+	return labelFormats
+}
+
+// GenerateSparePartLabels prints each part's name, barcode, part code and
+// selling price. Parts without a barcode get their part code as Code128.
+func (s *labelService) GenerateSparePartLabels(ctx context.Context, req *domain.LabelPrintRequest) ([]byte, error) {
+	if req.Format == "" {
+		req.Format = "a4-24"
+	}
+
+	format, err := s.resolveFormat(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []func(pdf *gofpdf.Fpdf, x, y float64) error
+	for _, item := range req.Items {
+		sparePart, err := s.sparePartRepo.GetByID(ctx, item.ID)
+		if err != nil || sparePart == nil {
+			return nil, fmt.Errorf("spare part %d not found", item.ID)
+		}
+
+		draw := func(pdf *gofpdf.Fpdf, x, y float64) error {
+			return drawSparePartLabel(pdf, format, sparePart, x, y)
+		}
+		for i := 0; i < item.Copies; i++ {
+			labels = append(labels, draw)
+		}
+	}
+
+	return renderLabels(format, req.StartPosition, labels)
+}
+
+// GenerateVehicleLabels prints key tags or windshield labels with a QR code
+// that links to the vehicle record
+func (s *labelService) GenerateVehicleLabels(ctx context.Context, labelType domain.VehicleLabelType, req *domain.LabelPrintRequest) ([]byte, error) {
+	var drawVehicle func(pdf *gofpdf.Fpdf, format *domain.LabelFormat, vehicle *domain.Vehicle, link string, x, y float64) error
+	switch labelType {
+	case domain.VehicleLabelTypeKeyTag:
+		drawVehicle = drawKeyTagLabel
+		if req.Format == "" {
+			req.Format = "thermal-50x30"
+		}
+	case domain.VehicleLabelTypeWindshield:
+		drawVehicle = drawWindshieldLabel
+		if req.Format == "" {
+			req.Format = "a4-2"
+		}
+	default:
+		return nil, fmt.Errorf("invalid vehicle label type: %s", labelType)
+	}
+
+	format, err := s.resolveFormat(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []func(pdf *gofpdf.Fpdf, x, y float64) error
+	for _, item := range req.Items {
+		vehicle, err := s.vehicleRepo.GetByID(ctx, item.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get vehicle: %w", err)
+		}
+		if vehicle == nil {
+			return nil, fmt.Errorf("vehicle %d not found", item.ID)
+		}
+
+		link := vehicle.VehicleCode
+		if s.vehicleURL != "" {
+			link = s.vehicleURL + strconv.Itoa(vehicle.ID)
+		}
+
+		draw := func(pdf *gofpdf.Fpdf, x, y float64) error {
+			return drawVehicle(pdf, format, vehicle, link, x, y)
+		}
+		for i := 0; i < item.Copies; i++ {
+			labels = append(labels, draw)
+		}
+	}
+
+	return renderLabels(format, req.StartPosition, labels)
+}
+
+// resolveFormat looks up the requested label format and checks the items
+func (s *labelService) resolveFormat(req *domain.LabelPrintRequest) (*domain.LabelFormat, error) {
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("at least one item is required")
+	}
+
+	total := 0
+	for i := range req.Items {
+		if req.Items[i].Copies <= 0 {
+			req.Items[i].Copies = 1
+		}
+		total += req.Items[i].Copies
+	}
+	if total > maxLabelCopies {
+		return nil, fmt.Errorf("cannot print more than %d labels at once", maxLabelCopies)
+	}
+
+	var format *domain.LabelFormat
+	if req.Format == customThermalFormat {
+		if req.LabelWidth < 20 || req.LabelHeight < 10 || req.LabelWidth > 200 || req.LabelHeight > 200 {
+			return nil, fmt.Errorf("thermal label size must be between 20 x 10 and 200 x 200 mm")
+		}
+		format = &domain.LabelFormat{
+			Code:        customThermalFormat,
+			Name:        fmt.Sprintf("Thermal label %g x %g mm", req.LabelWidth, req.LabelHeight),
+			PageWidth:   req.LabelWidth,
+			PageHeight:  req.LabelHeight,
+			LabelWidth:  req.LabelWidth,
+			LabelHeight: req.LabelHeight,
+			Columns:     1,
+			Rows:        1,
+		}
+	} else {
+		for _, f := range labelFormats {
+			if f.Code == req.Format {
+				format = f
+				break
+			}
+		}
+		if format == nil {
+			return nil, fmt.Errorf("unknown label format: %s", req.Format)
+		}
+	}
+
+	if req.StartPosition < 1 {
+		req.StartPosition = 1
+	}
+	if req.StartPosition > format.LabelsPerPage() {
+		return nil, fmt.Errorf("start position must be between 1 and %d", format.LabelsPerPage())
+	}
+
+	return format, nil
+}
+
+// renderLabels lays the labels out row by row, skipping the labels already
+// used on the first sheet
+func renderLabels(format *domain.LabelFormat, startPosition int, labels []func(pdf *gofpdf.Fpdf, x, y float64) error) ([]byte, error) {
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		OrientationStr: "P",
+		UnitStr:        "mm",
+		Size:           gofpdf.SizeType{Wd: format.PageWidth, Ht: format.PageHeight},
+	})
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.AddPage()
+
+	perPage := format.LabelsPerPage()
+	for i, draw := range labels {
+		slot := startPosition - 1 + i
+		if slot > 0 && slot%perPage == 0 {
+			pdf.AddPage()
+		}
+
+		pos := slot % perPage
+		x := format.MarginLeft + float64(pos%format.Columns)*(format.LabelWidth+format.GapX)
+		y := format.MarginTop + float64(pos/format.Columns)*(format.LabelHeight+format.GapY)
+		if err := draw(pdf, x, y); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to generate label PDF: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func drawSparePartLabel(pdf *gofpdf.Fpdf, format *domain.LabelFormat, sparePart *domain.SparePart, x, y float64) error {
+	w, h := format.LabelWidth, format.LabelHeight
+	pad := math.Min(2, h*0.08)
+	fontSize := math.Max(5, math.Min(9, h/4))
+	lineHeight := fontSize * 0.3528 * 1.2
+	innerWidth := w - 2*pad
+
+	code := sparePart.PartCode
+	if sparePart.Barcode != nil && *sparePart.Barcode != "" {
+		code = *sparePart.Barcode
+	}
+	symbol, err := encodeBarcode(code)
+	if err != nil {
+		return err
+	}
+
+	pdf.SetFont("Arial", "B", fontSize)
+	pdf.SetXY(x+pad, y+pad)
+	pdf.CellFormat(innerWidth, lineHeight, fitText(pdf, sparePart.Name, innerWidth), "", 0, "L", false, 0, "")
+
+	barcodeTop := y + pad + lineHeight
+	barcodeHeight := h - 2*pad - 3*lineHeight
+	drawLinearBarcode(pdf, symbol, x+pad, barcodeTop, innerWidth, barcodeHeight)
+
+	pdf.SetFont("Arial", "", fontSize-1)
+	pdf.SetXY(x+pad, barcodeTop+barcodeHeight)
+	pdf.CellFormat(innerWidth, lineHeight, code, "", 0, "C", false, 0, "")
+
+	pdf.SetFont("Arial", "B", fontSize)
+	pdf.SetXY(x+pad, barcodeTop+barcodeHeight+lineHeight)
+	pdf.CellFormat(innerWidth/2, lineHeight, fitText(pdf, sparePart.PartCode, innerWidth/2), "", 0, "L", false, 0, "")
+	pdf.CellFormat(innerWidth/2, lineHeight, "Rp "+formatCurrency(sparePart.SellingPrice), "", 0, "R", false, 0, "")
+
+	return nil
+}
+
+// drawKeyTagLabel prints a small tag for the key ring: QR code on the left,
+// stock number and plate on the right
+func drawKeyTagLabel(pdf *gofpdf.Fpdf, format *domain.LabelFormat, vehicle *domain.Vehicle, link string, x, y float64) error {
+	w, h := format.LabelWidth, format.LabelHeight
+	pad := math.Min(2, h*0.08)
+
+	symbol, err := qr.Encode(link, qr.M, qr.Auto)
+	if err != nil {
+		return fmt.Errorf("failed to encode QR code: %w", err)
+	}
+
+	size := math.Min(h-2*pad, w/2)
+	drawMatrixBarcode(pdf, symbol, x+pad, y+(h-size)/2, size)
+
+	textX := x + 2*pad + size
+	textWidth := w - 3*pad - size
+	fontSize := math.Max(5, math.Min(12, h/4))
+	lineHeight := fontSize * 0.3528 * 1.3
+
+	lines := []struct {
+		style string
+		text  string
+	}{
+		{"B", vehicle.VehicleCode},
+		{"", stringValue(vehicle.PlateNumber)},
+		{"", fmt.Sprintf("%s %s", vehicle.Brand, vehicle.Model)},
+		{"", fmt.Sprintf("%d %s", vehicle.Year, stringValue(vehicle.Color))},
+	}
+
+	textY := y + pad
+	for _, line := range lines {
+		if textY+lineHeight > y+h-pad {
+			break
+		}
+		pdf.SetFont("Arial", line.style, fontSize)
+		pdf.SetXY(textX, textY)
+		pdf.CellFormat(textWidth, lineHeight, fitText(pdf, line.text, textWidth), "", 0, "L", false, 0, "")
+		textY += lineHeight
+	}
+
+	return nil
+}
+
+// drawWindshieldLabel prints the display card for the windshield: make and
+// model, specification, price and a QR code to the vehicle record
+func drawWindshieldLabel(pdf *gofpdf.Fpdf, format *domain.LabelFormat, vehicle *domain.Vehicle, link string, x, y float64) error {
+	w, h := format.LabelWidth, format.LabelHeight
+	pad := math.Min(10, h*0.06)
+	innerWidth := w - 2*pad
+	scale := h / 148.5
+
+	symbol, err := qr.Encode(link, qr.M, qr.Auto)
+	if err != nil {
+		return fmt.Errorf("failed to encode QR code: %w", err)
+	}
+
+	titleSize := math.Max(8, 28*scale)
+	pdf.SetFont("Arial", "B", titleSize)
+	pdf.SetXY(x+pad, y+pad)
+	pdf.CellFormat(innerWidth, titleSize*0.3528*1.3, fitText(pdf, fmt.Sprintf("%s %s", vehicle.Brand, vehicle.Model), innerWidth), "", 0, "C", false, 0, "")
+
+	size := math.Min(h*0.45, innerWidth/3)
+	qrX := x + w - pad - size
+	qrY := y + h - pad - size
+	drawMatrixBarcode(pdf, symbol, qrX, qrY, size)
+
+	textSize := math.Max(6, 14*scale)
+	lineHeight := textSize * 0.3528 * 1.5
+	textWidth := innerWidth - size - pad
+	textY := y + pad + titleSize*0.3528*1.3 + lineHeight
+
+	specs := [][2]string{
+		{"Tahun", strconv.Itoa(vehicle.Year)},
+		{"Warna", stringValue(vehicle.Color)},
+		{"Transmisi", stringValue(vehicle.Transmission)},
+		{"Bahan Bakar", stringValue(vehicle.FuelType)},
+		{"No. Polisi", stringValue(vehicle.PlateNumber)},
+		{"Kode", vehicle.VehicleCode},
+	}
+	for _, spec := range specs {
+		if spec[1] == "" {
+			continue
+		}
+		pdf.SetFont("Arial", "", textSize)
+		pdf.SetXY(x+pad, textY)
+		pdf.CellFormat(textWidth*0.4, lineHeight, spec[0], "", 0, "L", false, 0, "")
+		pdf.SetFont("Arial", "B", textSize)
+		pdf.CellFormat(textWidth*0.6, lineHeight, fitText(pdf, spec[1], textWidth*0.6), "", 0, "L", false, 0, "")
+		textY += lineHeight
+	}
+
+	if vehicle.SellingPrice != nil {
+		priceSize := math.Max(8, 24*scale)
+		pdf.SetFont("Arial", "B", priceSize)
+		pdf.SetXY(x+pad, y+h-pad-priceSize*0.3528*1.3)
+		pdf.CellFormat(textWidth, priceSize*0.3528*1.3, "Rp "+formatCurrency(*vehicle.SellingPrice), "", 0, "L", false, 0, "")
+	}
+
+	return nil
+}
+
+// encodeBarcode uses EAN-13 for valid 13 digit codes and Code128 otherwise
+func encodeBarcode(code string) (barcode.Barcode, error) {
+	if isEAN13(code) {
+		symbol, err := ean.Encode(code)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode EAN-13 barcode %s: %w", code, err)
+		}
+		return symbol, nil
+	}
+
+	symbol, err := code128.Encode(code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Code128 barcode %s: %w", code, err)
+	}
+	return symbol, nil
+}
+
+// drawLinearBarcode draws the bars as filled rectangles so they stay sharp
+// at any printer resolution
+func drawLinearBarcode(pdf *gofpdf.Fpdf, symbol barcode.Barcode, x, y, width, height float64) {
+	modules := symbol.Bounds().Dx()
+	if modules == 0 || height <= 0 {
+		return
+	}
+	moduleWidth := width / float64(modules)
+
+	pdf.SetFillColor(0, 0, 0)
+	for start := 0; start < modules; {
+		if !isDarkModule(symbol, start, 0) {
+			start++
+			continue
+		}
+		end := start
+		for end < modules && isDarkModule(symbol, end, 0) {
+			end++
+		}
+		pdf.Rect(x+float64(start)*moduleWidth, y, float64(end-start)*moduleWidth, height, "F")
+		start = end
+	}
+}
+
+// drawMatrixBarcode draws a QR code as a square of the given size
+func drawMatrixBarcode(pdf *gofpdf.Fpdf, symbol barcode.Barcode, x, y, size float64) {
+	modules := symbol.Bounds().Dx()
+	if modules == 0 {
+		return
+	}
+	moduleSize := size / float64(modules)
+
+	pdf.SetFillColor(0, 0, 0)
+	for row := 0; row < modules; row++ {
+		for col := 0; col < modules; col++ {
+			if isDarkModule(symbol, col, row) {
+				pdf.Rect(x+float64(col)*moduleSize, y+float64(row)*moduleSize, moduleSize, moduleSize, "F")
+			}
+		}
+	}
+}
+
+func isDarkModule(symbol barcode.Barcode, x, y int) bool {
+	bounds := symbol.Bounds()
+	r, _, _, _ := symbol.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+	return r < 0x8000
+}
+
+// fitText shortens text with an ellipsis until it fits the width
+func fitText(pdf *gofpdf.Fpdf, text string, width float64) string {
+	text = strings.TrimSpace(text)
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"...") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -0,0 +1,381 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"pos-final/internal/repository"
+	"time"
+)
+
+type laborService struct {
+	laborRepo         repository.LaborRepository
+	workOrderRepo     repository.WorkOrderRepository
+	workOrderTaskRepo repository.WorkOrderTaskRepository
+	userRepo          repository.UserRepository
+	defaultRate       float64
+}
+
+// NewLaborService creates a new labor service
+func NewLaborService(
+	laborRepo repository.LaborRepository,
+	workOrderRepo repository.WorkOrderRepository,
+	workOrderTaskRepo repository.WorkOrderTaskRepository,
+	userRepo repository.UserRepository,
+	defaultRate float64,
+) LaborService {
+	return &laborService{
+		laborRepo:         laborRepo,
+		workOrderRepo:     workOrderRepo,
+		workOrderTaskRepo: workOrderTaskRepo,
+		userRepo:          userRepo,
+		defaultRate:       defaultRate,
+	}
+}
+
+// StartTimer clocks a mechanic in on an in-progress work order, optionally
+// on one of its tasks. A mechanic can only have one running timer.
+func (s *laborService) StartTimer(ctx context.Context, entry *domain.LaborTimeEntry) error {
+	if err := s.validateMechanic(ctx, entry.MechanicID); err != nil {
+		return err
+	}
+	if err := s.requireWorkOrderInProgress(ctx, entry.WorkOrderID); err != nil {
+		return err
+	}
+
+	if entry.WorkOrderTaskID != nil {
+		task, err := s.workOrderTaskRepo.GetByID(ctx, *entry.WorkOrderTaskID)
+		if err != nil {
+			return err
+		}
+		if task == nil || task.WorkOrderID != entry.WorkOrderID {
+			return fmt.Errorf("task does not belong to this work order")
+		}
+		if !task.Status.IsOpen() {
+			return fmt.Errorf("task is already %s", task.Status)
+		}
+	}
+
+	if err := s.ensureNoRunningTimer(ctx, entry.MechanicID); err != nil {
+		return err
+	}
+
+	rate, err := s.resolveHourlyRate(ctx, entry.MechanicID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	entry.Status = domain.LaborTimerStatusRunning
+	entry.StartedAt = now
+	entry.SegmentStartedAt = &now
+	entry.HourlyRate = rate
+
+	if err := s.laborRepo.CreateEntry(ctx, entry); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *laborService) PauseTimer(ctx context.Context, id int, userID int, role domain.UserRole) (*domain.LaborTimeEntry, error) {
+	entry, err := s.getOwnEntry(ctx, id, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Status != domain.LaborTimerStatusRunning {
+		return nil, fmt.Errorf("timer is %s, not running", entry.Status)
+	}
+
+	entry.WorkedSeconds = entry.ElapsedSeconds(time.Now())
+	entry.SegmentStartedAt = nil
+	entry.Status = domain.LaborTimerStatusPaused
+
+	if err := s.laborRepo.UpdateEntry(ctx, entry, domain.LaborTimerStatusRunning); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (s *laborService) ResumeTimer(ctx context.Context, id int, userID int, role domain.UserRole) (*domain.LaborTimeEntry, error) {
+	entry, err := s.getOwnEntry(ctx, id, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Status != domain.LaborTimerStatusPaused {
+		return nil, fmt.Errorf("timer is %s, not paused", entry.Status)
+	}
+	if err := s.requireWorkOrderInProgress(ctx, entry.WorkOrderID); err != nil {
+		return nil, err
+	}
+	if err := s.ensureNoRunningTimer(ctx, entry.MechanicID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	entry.SegmentStartedAt = &now
+	entry.Status = domain.LaborTimerStatusRunning
+
+	if err := s.laborRepo.UpdateEntry(ctx, entry, domain.LaborTimerStatusPaused); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// StopTimer clocks the mechanic out, prices the worked time at the rate
+// captured when the timer started and recomputes the work order labor cost
+func (s *laborService) StopTimer(ctx context.Context, id int, userID int, role domain.UserRole) (*domain.LaborTimeEntry, error) {
+	entry, err := s.getOwnEntry(ctx, id, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Status == domain.LaborTimerStatusStopped {
+		return nil, fmt.Errorf("timer is already stopped")
+	}
+
+	fromStatus := entry.Status
+	now := time.Now()
+	entry.WorkedSeconds = entry.ElapsedSeconds(now)
+	entry.SegmentStartedAt = nil
+	entry.EndedAt = &now
+	entry.Status = domain.LaborTimerStatusStopped
+	entry.LaborCost = roundCost(float64(entry.WorkedSeconds) / 3600 * entry.HourlyRate)
+
+	if err := s.laborRepo.UpdateEntry(ctx, entry, fromStatus); err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshWorkOrderLaborCost(ctx, entry.WorkOrderID); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (s *laborService) ListWorkOrderEntries(ctx context.Context, workOrderID int) ([]*domain.LaborTimeEntry, error) {
+	return s.laborRepo.ListByWorkOrderID(ctx, workOrderID)
+}
+
+// GetTimesheet lists a mechanic's timers started between the two dates
+// (inclusive) with worked hours and labor cost per day. Running timers are
+// counted up to now.
+func (s *laborService) GetTimesheet(ctx context.Context, mechanicID int, startDate, endDate time.Time) (map[string]interface{}, error) {
+	if endDate.Before(startDate) {
+		return nil, fmt.Errorf("end date cannot be before start date")
+	}
+
+	entries, err := s.laborRepo.ListByMechanic(ctx, mechanicID, startDate, endDate.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	days := make(map[string]map[string]interface{})
+	var dayOrder []string
+	totalSeconds := 0
+	var totalCost float64
+
+	for _, entry := range entries {
+		seconds := entry.ElapsedSeconds(now)
+		cost := entry.LaborCost
+		if entry.Status != domain.LaborTimerStatusStopped {
+			cost = roundCost(float64(seconds) / 3600 * entry.HourlyRate)
+		}
+
+		date := entry.StartedAt.Format("2006-01-02")
+		day, exists := days[date]
+		if !exists {
+			day = map[string]interface{}{
+				"date":       date,
+				"seconds":    0,
+				"labor_cost": float64(0),
+				"entries":    0,
+			}
+			days[date] = day
+			dayOrder = append(dayOrder, date)
+		}
+		day["seconds"] = day["seconds"].(int) + seconds
+		day["labor_cost"] = day["labor_cost"].(float64) + cost
+		day["entries"] = day["entries"].(int) + 1
+
+		totalSeconds += seconds
+		totalCost += cost
+	}
+
+	var daily []map[string]interface{}
+	for _, date := range dayOrder {
+		day := days[date]
+		day["hours"] = roundCost(float64(day["seconds"].(int)) / 3600)
+		daily = append(daily, day)
+	}
+
+	return map[string]interface{}{
+		"mechanic_id": mechanicID,
+		"start_date":  startDate.Format("2006-01-02"),
+		"end_date":    endDate.Format("2006-01-02"),
+		"summary": map[string]interface{}{
+			"entries":    len(entries),
+			"seconds":    totalSeconds,
+			"hours":      roundCost(float64(totalSeconds) / 3600),
+			"labor_cost": roundCost(totalCost),
+		},
+		"daily":   daily,
+		"entries": entries,
+	}, nil
+}
+
+func (s *laborService) ListRates(ctx context.Context) ([]*domain.LaborRate, error) {
+	return s.laborRepo.ListRates(ctx)
+}
+
+func (s *laborService) SetRate(ctx context.Context, rate *domain.LaborRate) error {
+	if rate.SkillLevel == "" {
+		return fmt.Errorf("skill level is required")
+	}
+	if rate.HourlyRate < 0 {
+		return fmt.Errorf("hourly rate cannot be negative")
+	}
+
+	return s.laborRepo.UpsertRate(ctx, rate)
+}
+
+func (s *laborService) GetMechanicProfile(ctx context.Context, mechanicID int) (*domain.MechanicLaborProfile, error) {
+	profile, err := s.laborRepo.GetProfileByMechanicID(ctx, mechanicID)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, fmt.Errorf("labor profile not found")
+	}
+
+	return profile, nil
+}
+
+func (s *laborService) SetMechanicProfile(ctx context.Context, profile *domain.MechanicLaborProfile) error {
+	if err := s.validateMechanic(ctx, profile.MechanicID); err != nil {
+		return err
+	}
+	if profile.HourlyRate != nil && *profile.HourlyRate < 0 {
+		return fmt.Errorf("hourly rate cannot be negative")
+	}
+	if profile.SkillLevel != nil {
+		rate, err := s.laborRepo.GetRateBySkillLevel(ctx, *profile.SkillLevel)
+		if err != nil {
+			return err
+		}
+		if rate == nil {
+			return fmt.Errorf("no labor rate defined for skill level %s", *profile.SkillLevel)
+		}
+	}
+
+	return s.laborRepo.UpsertProfile(ctx, profile)
+}
+
+// resolveHourlyRate uses the mechanic's own rate, then the rate of their
+// skill level, then the configured default
+func (s *laborService) resolveHourlyRate(ctx context.Context, mechanicID int) (float64, error) {
+	profile, err := s.laborRepo.GetProfileByMechanicID(ctx, mechanicID)
+	if err != nil {
+		return 0, err
+	}
+	if profile == nil {
+		return s.defaultRate, nil
+	}
+	if profile.HourlyRate != nil {
+		return *profile.HourlyRate, nil
+	}
+
+	if profile.SkillLevel != nil {
+		rate, err := s.laborRepo.GetRateBySkillLevel(ctx, *profile.SkillLevel)
+		if err != nil {
+			return 0, err
+		}
+		if rate != nil {
+			return rate.HourlyRate, nil
+		}
+	}
+
+	return s.defaultRate, nil
+}
+
+// refreshWorkOrderLaborCost sets the work order labor cost to the total of
+// its stopped timers
+func (s *laborService) refreshWorkOrderLaborCost(ctx context.Context, workOrderID int) error {
+	laborCost, err := s.laborRepo.SumLaborCostByWorkOrder(ctx, workOrderID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.workOrderRepo.UpdateLaborCost(ctx, workOrderID, laborCost); err != nil {
+		return fmt.Errorf("failed to update work order labor cost: %w", err)
+	}
+
+	return nil
+}
+
+func (s *laborService) ensureNoRunningTimer(ctx context.Context, mechanicID int) error {
+	running, err := s.laborRepo.GetRunningByMechanic(ctx, mechanicID)
+	if err != nil {
+		return err
+	}
+	if running != nil {
+		return fmt.Errorf("mechanic already has a running timer on work order %s", running.WONumber)
+	}
+
+	return nil
+}
+
+func (s *laborService) requireWorkOrderInProgress(ctx context.Context, workOrderID int) error {
+	workOrder, err := s.workOrderRepo.GetByID(ctx, workOrderID)
+	if err != nil {
+		return fmt.Errorf("failed to get work order: %w", err)
+	}
+	if workOrder.Status != domain.WorkOrderStatusInProgress {
+		return fmt.Errorf("work order is %s; start it before logging time", workOrder.Status)
+	}
+
+	return nil
+}
+
+func (s *laborService) getEntry(ctx context.Context, id int) (*domain.LaborTimeEntry, error) {
+	entry, err := s.laborRepo.GetEntryByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("labor time entry not found")
+	}
+
+	return entry, nil
+}
+
+// getOwnEntry returns the timer if the caller may change it. Mechanics can
+// only change their own timers.
+func (s *laborService) getOwnEntry(ctx context.Context, id int, userID int, role domain.UserRole) (*domain.LaborTimeEntry, error) {
+	entry, err := s.getEntry(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if role != domain.RoleAdmin && entry.MechanicID != userID {
+		return nil, fmt.Errorf("mechanics can only change their own timers")
+	}
+
+	return entry, nil
+}
+
+func (s *laborService) validateMechanic(ctx context.Context, mechanicID int) error {
+	mechanic, err := s.userRepo.GetByID(ctx, mechanicID)
+	if err != nil {
+		return fmt.Errorf("failed to get mechanic: %w", err)
+	}
+	if mechanic == nil {
+		return fmt.Errorf("mechanic not found")
+	}
+	if mechanic.Role != domain.RoleMekanik {
+		return fmt.Errorf("user is not a mechanic")
+	}
+
+	return nil
+}
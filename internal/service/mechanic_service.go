@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"pos-final/internal/repository"
+	"strings"
+	"time"
+)
+
+// assignmentStrategy picks one mechanic out of the available candidates and
+// explains why
+type assignmentStrategy interface {
+	choose(candidates []*domain.MechanicWorkload, vehicle *domain.Vehicle) (*domain.MechanicWorkload, string)
+}
+
+// leastWorkloadStrategy picks the mechanic with the fewest open work orders,
+// preferring whoever waited longest for a new one on a tie
+type leastWorkloadStrategy struct{}
+
+func (leastWorkloadStrategy) choose(candidates []*domain.MechanicWorkload, vehicle *domain.Vehicle) (*domain.MechanicWorkload, string) {
+	var chosen *domain.MechanicWorkload
+	for _, candidate := range candidates {
+		if chosen == nil || candidate.OpenWorkOrders < chosen.OpenWorkOrders ||
+			(candidate.OpenWorkOrders == chosen.OpenWorkOrders && assignedBefore(candidate, chosen)) {
+			chosen = candidate
+		}
+	}
+
+	return chosen, fmt.Sprintf("least open workload (%d open work orders)", chosen.OpenWorkOrders)
+}
+
+// roundRobinStrategy rotates through the mechanics, picking whoever was
+// assigned a work order longest ago
+type roundRobinStrategy struct{}
+
+func (roundRobinStrategy) choose(candidates []*domain.MechanicWorkload, vehicle *domain.Vehicle) (*domain.MechanicWorkload, string) {
+	var chosen *domain.MechanicWorkload
+	for _, candidate := range candidates {
+		if chosen == nil || assignedBefore(candidate, chosen) {
+			chosen = candidate
+		}
+	}
+
+	if chosen.LastAssignedAt == nil {
+		return chosen, "round robin (no previous work orders)"
+	}
+	return chosen, fmt.Sprintf("round robin (last assigned %s)", chosen.LastAssignedAt.Format("2006-01-02 15:04"))
+}
+
+// skillMatchStrategy picks the least loaded mechanic who specialises in the
+// vehicle's brand, falling back to least workload when nobody does
+type skillMatchStrategy struct{}
+
+func (skillMatchStrategy) choose(candidates []*domain.MechanicWorkload, vehicle *domain.Vehicle) (*domain.MechanicWorkload, string) {
+	var matching []*domain.MechanicWorkload
+	if vehicle != nil {
+		for _, candidate := range candidates {
+			for _, brand := range candidate.Brands {
+				if strings.EqualFold(brand, vehicle.Brand) {
+					matching = append(matching, candidate)
+					break
+				}
+			}
+		}
+	}
+
+	if len(matching) == 0 {
+		chosen, reason := leastWorkloadStrategy{}.choose(candidates, vehicle)
+		return chosen, "no brand specialist available, " + reason
+	}
+
+	chosen, _ := leastWorkloadStrategy{}.choose(matching, vehicle)
+	return chosen, fmt.Sprintf("%s specialist (%d open work orders)", vehicle.Brand, chosen.OpenWorkOrders)
+}
+
+// assignedBefore reports whether a was last assigned before b; never
+// assigned counts as earliest
+func assignedBefore(a, b *domain.MechanicWorkload) bool {
+	if a.LastAssignedAt == nil {
+		return b.LastAssignedAt != nil
+	}
+	return b.LastAssignedAt != nil && a.LastAssignedAt.Before(*b.LastAssignedAt)
+}
+
+func newAssignmentStrategy(strategy domain.AssignmentStrategy) assignmentStrategy {
+	switch strategy {
+	case domain.AssignmentStrategyRoundRobin:
+		return roundRobinStrategy{}
+	case domain.AssignmentStrategySkillMatch:
+		return skillMatchStrategy{}
+	default:
+		return leastWorkloadStrategy{}
+	}
+}
+
+type mechanicService struct {
+	mechanicRepo repository.MechanicRepository
+	userRepo     repository.UserRepository
+	strategyName domain.AssignmentStrategy
+	strategy     assignmentStrategy
+	defaultHours []*domain.MechanicWorkingHours
+}
+
+// NewMechanicService creates a new mechanic service
+func NewMechanicService(
+	mechanicRepo repository.MechanicRepository,
+	userRepo repository.UserRepository,
+	strategy domain.AssignmentStrategy,
+	defaultHours []*domain.MechanicWorkingHours,
+) MechanicService {
+	if strategy != domain.AssignmentStrategyRoundRobin && strategy != domain.AssignmentStrategySkillMatch {
+		strategy = domain.AssignmentStrategyLeastWorkload
+	}
+
+	return &mechanicService{
+		mechanicRepo: mechanicRepo,
+		userRepo:     userRepo,
+		strategyName: strategy,
+		strategy:     newAssignmentStrategy(strategy),
+		defaultHours: defaultHours,
+	}
+}
+
+// AssignMechanic picks a mechanic for a new work order on the vehicle using
+// the configured strategy. Inactive mechanics and mechanics on leave today
+// are never picked.
+func (s *mechanicService) AssignMechanic(ctx context.Context, vehicle *domain.Vehicle) (*domain.MechanicAssignment, error) {
+	workloads, err := s.ListWorkloads(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []*domain.MechanicWorkload
+	for _, workload := range workloads {
+		if !workload.OnLeave {
+			candidates = append(candidates, workload)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no mechanics available to assign work order")
+	}
+
+	chosen, reason := s.strategy.choose(candidates, vehicle)
+
+	return &domain.MechanicAssignment{
+		MechanicID: chosen.MechanicID,
+		Strategy:   s.strategyName,
+		Reason:     fmt.Sprintf("auto-assigned by %s: %s", s.strategyName, reason),
+	}, nil
+}
+
+// CheckAvailable applies the auto-assignment rules to a manually chosen
+// mechanic: inactive mechanics and mechanics on leave today are refused
+func (s *mechanicService) CheckAvailable(ctx context.Context, mechanicID int) error {
+	workloads, err := s.ListWorkloads(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, workload := range workloads {
+		if workload.MechanicID != mechanicID {
+			continue
+		}
+		if workload.OnLeave {
+			return fmt.Errorf("mechanic %s is on leave today", workload.FullName)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("mechanic is not active")
+}
+
+// ListWorkloads returns the active mechanics with their open work orders,
+// leave status for today and brand skills
+func (s *mechanicService) ListWorkloads(ctx context.Context) ([]*domain.MechanicWorkload, error) {
+	workloads, err := s.mechanicRepo.ListWorkloads(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	skills, err := s.mechanicRepo.ListSkills(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	brands := make(map[int][]string)
+	for _, skill := range skills {
+		brands[skill.MechanicID] = append(brands[skill.MechanicID], skill.Brand)
+	}
+
+	for _, workload := range workloads {
+		workload.Brands = brands[workload.MechanicID]
+		if workload.Brands == nil {
+			workload.Brands = []string{}
+		}
+	}
+
+	return workloads, nil
+}
+
+func (s *mechanicService) SetSkills(ctx context.Context, mechanicID int, brands []string) error {
+	if err := s.validateMechanic(ctx, mechanicID); err != nil {
+		return err
+	}
+
+	var cleaned []string
+	for _, brand := range brands {
+		if brand = strings.TrimSpace(brand); brand != "" {
+			cleaned = append(cleaned, brand)
+		}
+	}
+
+	return s.mechanicRepo.ReplaceSkills(ctx, mechanicID, cleaned)
+}
+
+func (s *mechanicService) CreateLeave(ctx context.Context, leave *domain.MechanicLeave) error {
+	if err := s.validateMechanic(ctx, leave.MechanicID); err != nil {
+		return err
+	}
+	if leave.EndDate.Before(leave.StartDate) {
+		return fmt.Errorf("end date cannot be before start date")
+	}
+
+	return s.mechanicRepo.CreateLeave(ctx, leave)
+}
+
+func (s *mechanicService) ListLeaves(ctx context.Context, mechanicID int) ([]*domain.MechanicLeave, error) {
+	return s.mechanicRepo.ListLeavesByMechanicID(ctx, mechanicID)
+}
+
+func (s *mechanicService) DeleteLeave(ctx context.Context, id int, deletedBy int) error {
+	leave, err := s.mechanicRepo.GetLeaveByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if leave == nil {
+		return fmt.Errorf("mechanic leave not found")
+	}
+
+	return s.mechanicRepo.DeleteLeave(ctx, id, deletedBy)
+}
+
+// GetWorkingHours returns the mechanic's weekly shifts, falling back to the
+// workshop's default hours when none are configured
+func (s *mechanicService) GetWorkingHours(ctx context.Context, mechanicID int) ([]*domain.MechanicWorkingHours, error) {
+	hours, err := s.mechanicRepo.ListWorkingHours(ctx, mechanicID)
+	if err != nil {
+		return nil, err
+	}
+	if len(hours) > 0 {
+		return hours, nil
+	}
+
+	defaults := make([]*domain.MechanicWorkingHours, 0, len(s.defaultHours))
+	for _, shift := range s.defaultHours {
+		defaults = append(defaults, &domain.MechanicWorkingHours{
+			MechanicID: mechanicID,
+			DayOfWeek:  shift.DayOfWeek,
+			StartTime:  shift.StartTime,
+			EndTime:    shift.EndTime,
+		})
+	}
+
+	return defaults, nil
+}
+
+func (s *mechanicService) SetWorkingHours(ctx context.Context, mechanicID int, hours []*domain.MechanicWorkingHours) error {
+	if err := s.validateMechanic(ctx, mechanicID); err != nil {
+		return err
+	}
+
+	seen := make(map[int]bool)
+	for _, shift := range hours {
+		if shift.DayOfWeek < 0 || shift.DayOfWeek > 6 {
+			return fmt.Errorf("day of week must be between 0 (Sunday) and 6")
+		}
+		if seen[shift.DayOfWeek] {
+			return fmt.Errorf("day of week %d is listed more than once", shift.DayOfWeek)
+		}
+		seen[shift.DayOfWeek] = true
+
+		start, err := time.Parse("15:04", shift.StartTime)
+		if err != nil {
+			return fmt.Errorf("invalid start time %q, use HH:MM", shift.StartTime)
+		}
+		end, err := time.Parse("15:04", shift.EndTime)
+		if err != nil {
+			return fmt.Errorf("invalid end time %q, use HH:MM", shift.EndTime)
+		}
+		if !end.After(start) {
+			return fmt.Errorf("end time must be after start time")
+		}
+	}
+
+	return s.mechanicRepo.ReplaceWorkingHours(ctx, mechanicID, hours)
+}
+
+func (s *mechanicService) validateMechanic(ctx context.Context, mechanicID int) error {
+	mechanic, err := s.userRepo.GetByID(ctx, mechanicID)
+	if err != nil {
+		return fmt.Errorf("failed to get mechanic: %w", err)
+	}
+	if mechanic == nil {
+		return fmt.Errorf("mechanic not found")
+	}
+	if mechanic.Role != domain.RoleMekanik {
+		return fmt.Errorf("user is not a mechanic")
+	}
+
+	return nil
+}
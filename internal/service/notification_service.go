@@ -183,6 +183,124 @@ func (s *notificationService) NotifyDailyReport(ctx context.Context, userID int,
 	return s.CreateNotification(ctx, notification)
 }
 
+func (s *notificationService) NotifyPayableOverdue(ctx context.Context, payable *domain.Payable) error {
+	// Overdue payables are followed up by admins
+	admins, err := s.userRepo.GetByRole(ctx, domain.RoleAdmin)
+	if err != nil {
+		return fmt.Errorf("failed to get admin users: %w", err)
+	}
+	
+	for _, admin := range admins {
+		notification := &domain.Notification{
+			UserID:        admin.ID,
+			Type:          domain.NotificationTypePayableOverdue,
+			Title:         "Payable Overdue",
+			Message:       fmt.Sprintf("Payable for invoice %s to %s was due on %s, outstanding %.2f", payable.InvoiceNumber, payable.CounterpartyName, payable.DueDate.Format("2006-01-02"), payable.OutstandingAmount()),
+			ReferenceType: stringPtr("payable"),
+			ReferenceID:   &payable.ID,
+		}
+		
+		if err := s.CreateNotification(ctx, notification); err != nil {
+			return err
+		}
+	}
+	
+	return nil
+}
+
+func (s *notificationService) NotifyReorderDraft(ctx context.Context, po *domain.PurchaseOrder) error {
+	// Draft purchase orders are reviewed and submitted by admins
+	admins, err := s.userRepo.GetByRole(ctx, domain.RoleAdmin)
+	if err != nil {
+		return fmt.Errorf("failed to get admin users: %w", err)
+	}
+
+	supplierName := ""
+	if po.Supplier != nil {
+		supplierName = po.Supplier.Name
+	}
+
+	for _, admin := range admins {
+		notification := &domain.Notification{
+			UserID:        admin.ID,
+			Type:          domain.NotificationTypeReorderDraft,
+			Title:         "Reorder Draft Created",
+			Message:       fmt.Sprintf("Draft purchase order %s to %s was created from reorder suggestions (%d items, %.2f)", po.PONumber, supplierName, len(po.Items), po.TotalAmount),
+			ReferenceType: stringPtr("purchase_order"),
+			ReferenceID:   &po.ID,
+		}
+
+		if err := s.CreateNotification(ctx, notification); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NotifyExpiringStock tells admins and kasir how many stock batches have
+// expired or expire within the alert window
+func (s *notificationService) NotifyExpiringStock(ctx context.Context, expired, expiring int, days int) error {
+	var recipients []*domain.User
+	for _, role := range []domain.UserRole{domain.RoleAdmin, domain.RoleKasir} {
+		users, err := s.userRepo.GetByRole(ctx, role)
+		if err != nil {
+			return fmt.Errorf("failed to get %s users: %w", role, err)
+		}
+		recipients = append(recipients, users...)
+	}
+
+	message := fmt.Sprintf("%d stock batches expire within %d days", expiring, days)
+	if expired > 0 {
+		message = fmt.Sprintf("%d stock batches have expired and %s", expired, message)
+	}
+
+	for _, user := range recipients {
+		notification := &domain.Notification{
+			UserID:        user.ID,
+			Type:          domain.NotificationTypeExpiringStock,
+			Title:         "Expiring Stock",
+			Message:       message,
+			ReferenceType: stringPtr("spare_part_batch"),
+		}
+
+		if err := s.CreateNotification(ctx, notification); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *notificationService) NotifyPartRequestReviewed(ctx context.Context, request *domain.PartRequest) error {
+	var title, message string
+	switch request.Status {
+	case domain.PartRequestStatusRejected:
+		title = "Part Request Rejected"
+		message = fmt.Sprintf("Part request %s was rejected", request.RequestNumber)
+	case domain.PartRequestStatusPartiallyApproved:
+		title = "Part Request Partially Approved"
+		message = fmt.Sprintf("Part request %s was partially approved: %d of %d issued", request.RequestNumber, request.QuantityIssued, request.QuantityRequested)
+	default:
+		title = "Part Request Approved"
+		message = fmt.Sprintf("Part request %s was approved: %d issued", request.RequestNumber, request.QuantityIssued)
+	}
+	if request.ReviewNotes != nil && *request.ReviewNotes != "" {
+		message = fmt.Sprintf("%s (%s)", message, *request.ReviewNotes)
+	}
+
+	notification := &domain.Notification{
+		UserID:        request.RequestedBy,
+		Type:          domain.NotificationTypePartRequestReviewed,
+		Title:         title,
+		Message:       message,
+		ReferenceType: stringPtr("part_request"),
+		ReferenceID:   &request.ID,
+	}
+
+	return s.CreateNotification(ctx, notification)
+}
+
 // Broadcast notifications to multiple users
 func (s *notificationService) BroadcastNotification(ctx context.Context, userIDs []int, notificationType domain.NotificationType, title, message string) error {
 	if len(userIDs) == 0 {
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"pos-final/internal/domain"
+	"pos-final/internal/repository"
+	"time"
+)
+
+type partRequestService struct {
+	partRequestRepo     repository.PartRequestRepository
+	workOrderRepo       repository.WorkOrderRepository
+	sparePartRepo       repository.SparePartRepository
+	workOrderService    WorkOrderService
+	notificationService NotificationService
+}
+
+// NewPartRequestService creates a new part request service
+func NewPartRequestService(
+	partRequestRepo repository.PartRequestRepository,
+	workOrderRepo repository.WorkOrderRepository,
+	sparePartRepo repository.SparePartRepository,
+	workOrderService WorkOrderService,
+	notificationService NotificationService,
+) PartRequestService {
+	return &partRequestService{
+		partRequestRepo:     partRequestRepo,
+		workOrderRepo:       workOrderRepo,
+		sparePartRepo:       sparePartRepo,
+		workOrderService:    workOrderService,
+		notificationService: notificationService,
+	}
+}
+
+// CreateRequest queues a mechanic's request for parts on an open work order.
+// No stock moves until the request is approved.
+func (s *partRequestService) CreateRequest(ctx context.Context, request *domain.PartRequest) error {
+	if request.QuantityRequested <= 0 {
+		return fmt.Errorf("quantity must be greater than 0")
+	}
+
+	if err := s.requireOpenWorkOrder(ctx, request.WorkOrderID); err != nil {
+		return err
+	}
+
+	if _, err := s.sparePartRepo.GetByID(ctx, request.SparePartID); err != nil {
+		return fmt.Errorf("spare part not found: %w", err)
+	}
+
+	requestNumber, err := s.partRequestRepo.GenerateRequestNumber(ctx)
+	if err != nil {
+		return err
+	}
+
+	request.RequestNumber = requestNumber
+	request.Status = domain.PartRequestStatusPending
+	request.QuantityIssued = 0
+
+	return s.partRequestRepo.Create(ctx, request)
+}
+
+func (s *partRequestService) GetRequestByID(ctx context.Context, id int) (*domain.PartRequest, error) {
+	request, err := s.partRequestRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if request == nil {
+		return nil, fmt.Errorf("part request not found")
+	}
+
+	return request, nil
+}
+
+func (s *partRequestService) ListRequestsByWorkOrder(ctx context.Context, workOrderID int) ([]*domain.PartRequest, error) {
+	return s.partRequestRepo.ListByWorkOrderID(ctx, workOrderID)
+}
+
+func (s *partRequestService) ListRequestsByStatus(ctx context.Context, status domain.PartRequestStatus, page, limit int) ([]*domain.PartRequest, int, error) {
+	offset := (page - 1) * limit
+
+	requests, err := s.partRequestRepo.ListByStatus(ctx, status, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.partRequestRepo.CountByStatus(ctx, status)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return requests, total, nil
+}
+
+// ApproveRequest issues up to the requested quantity to the work order.
+// Issuing less than requested marks the request partially approved. The
+// issued parts are booked as used by the requesting mechanic, taken from
+// the given location (default location when nil). Serial-tracked parts need
+// the serial number of every unit issued.
+func (s *partRequestService) ApproveRequest(ctx context.Context, id int, locationID *int, quantity int, serialNumbers []string, reviewedBy int, notes *string) (*domain.PartRequest, error) {
+	request, err := s.getPendingRequest(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if quantity <= 0 {
+		return nil, fmt.Errorf("quantity must be greater than 0")
+	}
+	if quantity > request.QuantityRequested {
+		return nil, fmt.Errorf("cannot issue more than requested (%d)", request.QuantityRequested)
+	}
+
+	if err := s.requireOpenWorkOrder(ctx, request.WorkOrderID); err != nil {
+		return nil, err
+	}
+
+	status := domain.PartRequestStatusApproved
+	if quantity < request.QuantityRequested {
+		status = domain.PartRequestStatusPartiallyApproved
+	}
+
+	now := time.Now()
+	request.Status = status
+	request.QuantityIssued = quantity
+	request.ReviewedBy = &reviewedBy
+	request.ReviewedAt = &now
+	request.ReviewNotes = notes
+
+	// The request is closed and its parts issued together, so two
+	// storekeepers cannot issue it twice and a failed issue keeps it pending
+	workOrderPart, err := s.workOrderService.IssuePartRequest(ctx, request, locationID, serialNumbers)
+	if err != nil {
+		return nil, err
+	}
+	request.FitmentWarning = workOrderPart.FitmentWarning
+
+	if err := s.notificationService.NotifyPartRequestReviewed(ctx, request); err != nil {
+		log.Printf("failed to notify part request %s: %v", request.RequestNumber, err)
+	}
+
+	return request, nil
+}
+
+// RejectRequest closes a pending request without issuing any stock
+func (s *partRequestService) RejectRequest(ctx context.Context, id int, reviewedBy int, notes *string) (*domain.PartRequest, error) {
+	request, err := s.getPendingRequest(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	request.Status = domain.PartRequestStatusRejected
+	request.QuantityIssued = 0
+	request.ReviewedBy = &reviewedBy
+	request.ReviewedAt = &now
+	request.ReviewNotes = notes
+
+	if err := s.partRequestRepo.Review(ctx, request); err != nil {
+		return nil, err
+	}
+
+	if err := s.notificationService.NotifyPartRequestReviewed(ctx, request); err != nil {
+		log.Printf("failed to notify part request %s: %v", request.RequestNumber, err)
+	}
+
+	return request, nil
+}
+
+func (s *partRequestService) getPendingRequest(ctx context.Context, id int) (*domain.PartRequest, error) {
+	request, err := s.GetRequestByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != domain.PartRequestStatusPending {
+		return nil, fmt.Errorf("part request is already %s", request.Status)
+	}
+
+	return request, nil
+}
+
+func (s *partRequestService) requireOpenWorkOrder(ctx context.Context, workOrderID int) error {
+	workOrder, err := s.workOrderRepo.GetByID(ctx, workOrderID)
+	if err != nil {
+		return fmt.Errorf("failed to get work order: %w", err)
+	}
+	if workOrder.Status == domain.WorkOrderStatusCompleted || workOrder.Status == domain.WorkOrderStatusCancelled {
+		return fmt.Errorf("work order is %s", workOrder.Status)
+	}
+
+	return nil
+}
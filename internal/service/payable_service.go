@@ -0,0 +1,402 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"pos-final/internal/domain"
+	"pos-final/internal/repository"
+	"sort"
+	"time"
+)
+
+// Aging buckets by days past due
+var payableAgingBuckets = []string{"current", "1_30", "31_60", "61_90", "over_90"}
+
+type payableService struct {
+	payableRepo         repository.PayableRepository
+	paymentRepo         repository.PayablePaymentRepository
+	notificationService NotificationService
+}
+
+// NewPayableService creates a new payable service
+func NewPayableService(
+	payableRepo repository.PayableRepository,
+	paymentRepo repository.PayablePaymentRepository,
+	notificationService NotificationService,
+) PayableService {
+	return &payableService{
+		payableRepo:         payableRepo,
+		paymentRepo:         paymentRepo,
+		notificationService: notificationService,
+	}
+}
+
+// PreparePayable sets up the payable of a new purchase invoice, which is saved
+// with the invoice. Without a due date the invoice is treated as paid in full
+// on the transaction date; with one, any initial paid amount becomes the
+// first payment.
+func (s *payableService) PreparePayable(ctx context.Context, invoice *domain.PurchaseInvoice) error {
+	payable := invoice.Payable
+	if payable == nil {
+		payable = &domain.Payable{}
+	}
+
+	initialPayment := payable.PaidAmount
+	if payable.DueDate.IsZero() {
+		if initialPayment > 0 && initialPayment < invoice.FinalPrice {
+			return fmt.Errorf("due date is required when the initial payment is less than %.2f", invoice.FinalPrice)
+		}
+		payable.DueDate = invoice.TransactionDate
+		initialPayment = invoice.FinalPrice
+	}
+
+	if initialPayment < 0 || initialPayment > invoice.FinalPrice {
+		return fmt.Errorf("initial payment must be between 0 and %.2f", invoice.FinalPrice)
+	}
+	if payable.DueDate.Before(invoice.TransactionDate) {
+		return fmt.Errorf("due date cannot be before the transaction date")
+	}
+
+	payable.Amount = invoice.FinalPrice
+	payable.PaidAmount = 0
+	payable.Status = domain.PayableStatusUnpaid
+	payable.Payments = nil
+
+	if initialPayment > 0 {
+		paymentNumber, err := s.paymentRepo.GeneratePaymentNumber(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to generate payment number: %w", err)
+		}
+
+		paymentMethod := invoice.PaymentMethod
+		if paymentMethod == "" {
+			paymentMethod = domain.PaymentMethodTransfer
+		}
+
+		payable.Payments = []*domain.PayablePayment{{
+			PaymentNumber: paymentNumber,
+			Amount:        initialPayment,
+			PaymentDate:   invoice.TransactionDate,
+			PaymentMethod: paymentMethod,
+			TransferProof: invoice.TransferProof,
+			CreatedBy:     invoice.CreatedBy,
+		}}
+	}
+	invoice.Payable = payable
+
+	return nil
+}
+
+// newConsignmentPayable is the payable to the owner of a sold consignment
+// vehicle for the agreed net price
+func newConsignmentPayable(consignment *domain.Consignment, saleDate time.Time) *domain.Payable {
+	return &domain.Payable{
+		ConsignmentID: &consignment.ID,
+		Amount:        consignment.AgreedNetPrice,
+		DueDate:       saleDate.AddDate(0, 0, consignment.PayoutDays),
+		Status:        domain.PayableStatusUnpaid,
+	}
+}
+
+// newSubletPayable is the payable to the vendor of a completed sublet repair
+func newSubletPayable(sublet *domain.WorkOrderSublet, dueDate time.Time) *domain.Payable {
+	return &domain.Payable{
+		WorkOrderSubletID: &sublet.ID,
+		Amount:            sublet.Cost,
+		DueDate:           dueDate,
+		Status:            domain.PayableStatusUnpaid,
+	}
+}
+
+func (s *payableService) GetPayableByID(ctx context.Context, id int) (*domain.Payable, error) {
+	payable, err := s.payableRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if payable == nil {
+		return nil, fmt.Errorf("payable not found")
+	}
+
+	payable.Payments, err = s.paymentRepo.ListByPayableID(ctx, payable.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return payable, nil
+}
+
+func (s *payableService) GetPayableByPurchaseInvoiceID(ctx context.Context, purchaseInvoiceID int) (*domain.Payable, error) {
+	payable, err := s.payableRepo.GetByPurchaseInvoiceID(ctx, purchaseInvoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if payable == nil {
+		return nil, fmt.Errorf("payable not found")
+	}
+
+	payable.Payments, err = s.paymentRepo.ListByPayableID(ctx, payable.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return payable, nil
+}
+
+func (s *payableService) GetPayableByConsignmentID(ctx context.Context, consignmentID int) (*domain.Payable, error) {
+	payable, err := s.payableRepo.GetByConsignmentID(ctx, consignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if payable == nil {
+		return nil, fmt.Errorf("payable not found")
+	}
+
+	payable.Payments, err = s.paymentRepo.ListByPayableID(ctx, payable.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return payable, nil
+}
+
+func (s *payableService) GetPayableByWorkOrderSubletID(ctx context.Context, subletID int) (*domain.Payable, error) {
+	payable, err := s.payableRepo.GetByWorkOrderSubletID(ctx, subletID)
+	if err != nil {
+		return nil, err
+	}
+	if payable == nil {
+		return nil, fmt.Errorf("payable not found")
+	}
+
+	payable.Payments, err = s.paymentRepo.ListByPayableID(ctx, payable.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return payable, nil
+}
+
+func (s *payableService) ListPayables(ctx context.Context, page, limit int) ([]*domain.Payable, int, error) {
+	offset := (page - 1) * limit
+	payables, err := s.payableRepo.List(ctx, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := s.payableRepo.Count(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return payables, count, nil
+}
+
+func (s *payableService) ListPayablesByStatus(ctx context.Context, status domain.PayableStatus, page, limit int) ([]*domain.Payable, int, error) {
+	offset := (page - 1) * limit
+	payables, err := s.payableRepo.ListByStatus(ctx, status, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := s.payableRepo.CountByStatus(ctx, status)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return payables, count, nil
+}
+
+func (s *payableService) RecordPayment(ctx context.Context, payment *domain.PayablePayment) error {
+	if payment.Amount <= 0 {
+		return fmt.Errorf("payment amount must be greater than 0")
+	}
+
+	payable, err := s.payableRepo.GetByID(ctx, payment.PayableID)
+	if err != nil {
+		return fmt.Errorf("failed to get payable: %w", err)
+	}
+	if payable == nil {
+		return fmt.Errorf("payable not found")
+	}
+	if payable.Status == domain.PayableStatusPaid {
+		return fmt.Errorf("payable is already paid")
+	}
+	if payment.Amount > payable.OutstandingAmount() {
+		return fmt.Errorf("payment %.2f exceeds outstanding balance %.2f", payment.Amount, payable.OutstandingAmount())
+	}
+
+	if payment.PaymentDate.IsZero() {
+		payment.PaymentDate = time.Now()
+	}
+	if payment.PaymentMethod == "" {
+		payment.PaymentMethod = domain.PaymentMethodTransfer
+	}
+
+	paymentNumber, err := s.paymentRepo.GeneratePaymentNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to generate payment number: %w", err)
+	}
+	payment.PaymentNumber = paymentNumber
+
+	if err := s.paymentRepo.Create(ctx, payment); err != nil {
+		return fmt.Errorf("failed to record payment: %w", err)
+	}
+
+	return nil
+}
+
+func (s *payableService) GetPaymentByID(ctx context.Context, id int) (*domain.PayablePayment, error) {
+	payment, err := s.paymentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if payment == nil {
+		return nil, fmt.Errorf("payment not found")
+	}
+
+	return payment, nil
+}
+
+func (s *payableService) AttachTransferProof(ctx context.Context, paymentID int, transferProof string) error {
+	if _, err := s.GetPaymentByID(ctx, paymentID); err != nil {
+		return err
+	}
+
+	return s.paymentRepo.UpdateTransferProof(ctx, paymentID, transferProof)
+}
+
+// NotifyOverduePayables sends one overdue notification per payable past its
+// due date and returns how many payables were notified
+func (s *payableService) NotifyOverduePayables(ctx context.Context) (int, error) {
+	today := time.Now().Truncate(24 * time.Hour)
+
+	payables, err := s.payableRepo.ListOverdueUnnotified(ctx, today)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, payable := range payables {
+		if err := s.notificationService.NotifyPayableOverdue(ctx, payable); err != nil {
+			return 0, fmt.Errorf("failed to notify overdue payable: %w", err)
+		}
+		if err := s.payableRepo.MarkOverdueNotified(ctx, payable.ID); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(payables), nil
+}
+
+// StartOverduePayableScheduler sends overdue payable notifications every
+// interval until the context is done
+func StartOverduePayableScheduler(ctx context.Context, payableService PayableService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			notified, err := payableService.NotifyOverduePayables(ctx)
+			if err != nil {
+				log.Printf("overdue payable check failed: %v", err)
+				continue
+			}
+			log.Printf("overdue payable check notified %d payables", notified)
+		}
+	}
+}
+
+// GetAgingReport buckets outstanding balances by days past due and groups
+// them per supplier or customer-seller
+func (s *payableService) GetAgingReport(ctx context.Context, asOf time.Time, counterpartyType string) (map[string]interface{}, error) {
+	payables, err := s.payableRepo.ListOutstanding(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outstanding payables: %w", err)
+	}
+
+	totals := make(map[string]float64)
+	for _, bucket := range payableAgingBuckets {
+		totals[bucket] = 0
+	}
+
+	groups := make(map[string]map[string]interface{})
+	var groupOrder []string
+	var totalOutstanding float64
+	count := 0
+
+	for _, payable := range payables {
+		if counterpartyType != "" && string(payable.CounterpartyType) != counterpartyType {
+			continue
+		}
+
+		bucket := payableAgingBucket(asOf, payable.DueDate)
+		outstanding := payable.OutstandingAmount()
+
+		key := fmt.Sprintf("%s-%d", payable.CounterpartyType, payable.CounterpartyID)
+		group, exists := groups[key]
+		if !exists {
+			buckets := make(map[string]float64)
+			for _, b := range payableAgingBuckets {
+				buckets[b] = 0
+			}
+			group = map[string]interface{}{
+				"counterparty_type": payable.CounterpartyType,
+				"counterparty_id":   payable.CounterpartyID,
+				"counterparty_name": payable.CounterpartyName,
+				"buckets":           buckets,
+				"total_outstanding": float64(0),
+				"payable_count":     0,
+			}
+			groups[key] = group
+			groupOrder = append(groupOrder, key)
+		}
+
+		group["buckets"].(map[string]float64)[bucket] += outstanding
+		group["total_outstanding"] = group["total_outstanding"].(float64) + outstanding
+		group["payable_count"] = group["payable_count"].(int) + 1
+
+		totals[bucket] += outstanding
+		totalOutstanding += outstanding
+		count++
+	}
+
+	// Largest balances first
+	sort.SliceStable(groupOrder, func(i, j int) bool {
+		return groups[groupOrder[i]]["total_outstanding"].(float64) > groups[groupOrder[j]]["total_outstanding"].(float64)
+	})
+
+	var breakdown []map[string]interface{}
+	for _, key := range groupOrder {
+		breakdown = append(breakdown, groups[key])
+	}
+
+	return map[string]interface{}{
+		"as_of": asOf.Format("2006-01-02"),
+		"summary": map[string]interface{}{
+			"payable_count":     count,
+			"total_outstanding": totalOutstanding,
+			"buckets":           totals,
+		},
+		"breakdown": breakdown,
+	}, nil
+}
+
+func payableAgingBucket(asOf, dueDate time.Time) string {
+	daysPastDue := int(asOf.Sub(dueDate).Hours() / 24)
+
+	switch {
+	case daysPastDue <= 0:
+		return "current"
+	case daysPastDue <= 30:
+		return "1_30"
+	case daysPastDue <= 60:
+		return "31_60"
+	case daysPastDue <= 90:
+		return "61_90"
+	default:
+		return "over_90"
+	}
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"pos-final/internal/repository"
+	"time"
+)
+
+type purchaseOrderService struct {
+	purchaseOrderRepo repository.PurchaseOrderRepository
+	goodsReceiptRepo  repository.GoodsReceiptRepository
+	supplierRepo      repository.SupplierRepository
+	sparePartRepo     repository.SparePartRepository
+	stockLocationRepo repository.StockLocationRepository
+	costingMethod     domain.CostingMethod
+}
+
+// NewPurchaseOrderService creates a new purchase order service
+func NewPurchaseOrderService(
+	purchaseOrderRepo repository.PurchaseOrderRepository,
+	goodsReceiptRepo repository.GoodsReceiptRepository,
+	supplierRepo repository.SupplierRepository,
+	sparePartRepo repository.SparePartRepository,
+	stockLocationRepo repository.StockLocationRepository,
+	costingMethod domain.CostingMethod,
+) PurchaseOrderService {
+	return &purchaseOrderService{
+		purchaseOrderRepo: purchaseOrderRepo,
+		goodsReceiptRepo:  goodsReceiptRepo,
+		supplierRepo:      supplierRepo,
+		sparePartRepo:     sparePartRepo,
+		stockLocationRepo: stockLocationRepo,
+		costingMethod:     costingMethod,
+	}
+}
+
+func (s *purchaseOrderService) CreatePurchaseOrder(ctx context.Context, po *domain.PurchaseOrder) error {
+	if len(po.Items) == 0 {
+		return fmt.Errorf("purchase order must have at least one item")
+	}
+
+	supplier, err := s.supplierRepo.GetByID(ctx, po.SupplierID)
+	if err != nil {
+		return fmt.Errorf("failed to get supplier: %w", err)
+	}
+	if supplier == nil {
+		return fmt.Errorf("supplier not found")
+	}
+
+	if po.PONumber == "" {
+		poNumber, err := s.purchaseOrderRepo.GeneratePONumber(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to generate purchase order number: %w", err)
+		}
+		po.PONumber = poNumber
+	}
+
+	// Validate lines and calculate expected total
+	po.TotalAmount = 0
+	for _, item := range po.Items {
+		if item.QuantityOrdered <= 0 {
+			return fmt.Errorf("quantity must be greater than zero")
+		}
+
+		sparePart, err := s.sparePartRepo.GetByID(ctx, item.SparePartID)
+		if err != nil {
+			return fmt.Errorf("failed to get spare part %d: %w", item.SparePartID, err)
+		}
+
+		// Default expected cost to the current cost price
+		if item.UnitCost <= 0 {
+			item.UnitCost = sparePart.CostPrice
+		}
+		item.QuantityReceived = 0
+		item.TotalCost = item.UnitCost * float64(item.QuantityOrdered)
+		po.TotalAmount += item.TotalCost
+	}
+
+	if po.Status == "" {
+		po.Status = domain.PurchaseOrderStatusDraft
+	}
+	if po.Status != domain.PurchaseOrderStatusDraft && po.Status != domain.PurchaseOrderStatusOrdered {
+		return fmt.Errorf("new purchase order must be draft or ordered")
+	}
+	if po.Status == domain.PurchaseOrderStatusOrdered {
+		now := time.Now()
+		po.OrderDate = &now
+	}
+
+	po.Supplier = supplier
+
+	if err := s.purchaseOrderRepo.Create(ctx, po); err != nil {
+		return fmt.Errorf("failed to create purchase order: %w", err)
+	}
+
+	return nil
+}
+
+func (s *purchaseOrderService) GetPurchaseOrderByID(ctx context.Context, id int) (*domain.PurchaseOrder, error) {
+	po, err := s.purchaseOrderRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if po == nil {
+		return nil, fmt.Errorf("purchase order not found")
+	}
+
+	items, err := s.purchaseOrderRepo.ListItems(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	po.Items = items
+
+	return po, nil
+}
+
+func (s *purchaseOrderService) ListPurchaseOrders(ctx context.Context, page, limit int) ([]*domain.PurchaseOrder, int, error) {
+	offset := (page - 1) * limit
+	orders, err := s.purchaseOrderRepo.List(ctx, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := s.purchaseOrderRepo.Count(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return orders, count, nil
+}
+
+func (s *purchaseOrderService) ListPurchaseOrdersByStatus(ctx context.Context, status domain.PurchaseOrderStatus, page, limit int) ([]*domain.PurchaseOrder, int, error) {
+	offset := (page - 1) * limit
+	orders, err := s.purchaseOrderRepo.ListByStatus(ctx, status, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := s.purchaseOrderRepo.CountByStatus(ctx, status)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return orders, count, nil
+}
+
+func (s *purchaseOrderService) SubmitPurchaseOrder(ctx context.Context, id int) error {
+	po, err := s.purchaseOrderRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get purchase order: %w", err)
+	}
+	if po == nil {
+		return fmt.Errorf("purchase order not found")
+	}
+
+	if po.Status != domain.PurchaseOrderStatusDraft {
+		return fmt.Errorf("only draft purchase orders can be ordered (current status: %s)", po.Status)
+	}
+
+	return s.purchaseOrderRepo.UpdateStatus(ctx, id, domain.PurchaseOrderStatusOrdered)
+}
+
+// CancelPurchaseOrder cancels an open order. For a partially received order
+// this closes the remaining backorder; stock already received is kept.
+func (s *purchaseOrderService) CancelPurchaseOrder(ctx context.Context, id int) error {
+	po, err := s.purchaseOrderRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get purchase order: %w", err)
+	}
+	if po == nil {
+		return fmt.Errorf("purchase order not found")
+	}
+
+	if po.Status == domain.PurchaseOrderStatusReceived || po.Status == domain.PurchaseOrderStatusCancelled {
+		return fmt.Errorf("purchase order cannot be cancelled (current status: %s)", po.Status)
+	}
+
+	return s.purchaseOrderRepo.UpdateStatus(ctx, id, domain.PurchaseOrderStatusCancelled)
+}
+
+func (s *purchaseOrderService) ReceiveGoods(ctx context.Context, receipt *domain.GoodsReceipt) error {
+	if len(receipt.Items) == 0 {
+		return fmt.Errorf("goods receipt must have at least one item")
+	}
+
+	po, err := s.GetPurchaseOrderByID(ctx, receipt.PurchaseOrderID)
+	if err != nil {
+		return err
+	}
+
+	if po.Status != domain.PurchaseOrderStatusOrdered && po.Status != domain.PurchaseOrderStatusPartiallyReceived {
+		return fmt.Errorf("goods can only be received for ordered purchase orders (current status: %s)", po.Status)
+	}
+
+	// Goods are booked into one location (default location when none is given)
+	location, err := resolveStockLocation(ctx, s.stockLocationRepo, receipt.LocationID)
+	if err != nil {
+		return err
+	}
+	receipt.LocationID = &location.ID
+
+	poItems := make(map[int]*domain.PurchaseOrderItem)
+	for _, item := range po.Items {
+		poItems[item.ID] = item
+	}
+
+	// Validate against outstanding (backordered) quantities
+	receiving := make(map[int]int)
+	for _, item := range receipt.Items {
+		poItem, ok := poItems[item.PurchaseOrderItemID]
+		if !ok {
+			return fmt.Errorf("item %d does not belong to purchase order %s", item.PurchaseOrderItemID, po.PONumber)
+		}
+		if item.Quantity <= 0 {
+			return fmt.Errorf("received quantity must be greater than zero")
+		}
+
+		receiving[poItem.ID] += item.Quantity
+		outstanding := poItem.QuantityOrdered - poItem.QuantityReceived
+		if receiving[poItem.ID] > outstanding {
+			return fmt.Errorf("received quantity for %s exceeds outstanding quantity %d", poItem.SparePart.PartCode, outstanding)
+		}
+
+		// Actual cost defaults to the expected PO cost
+		if item.UnitCost <= 0 {
+			item.UnitCost = poItem.UnitCost
+		}
+		item.SparePartID = poItem.SparePartID
+		item.TotalCost = item.UnitCost * float64(item.Quantity)
+
+		// Batch-tracked parts record their lot and expiry, serial-tracked
+		// parts the serial number of every unit
+		sparePart, err := s.sparePartRepo.GetByID(ctx, item.SparePartID)
+		if err != nil {
+			return fmt.Errorf("failed to get spare part: %w", err)
+		}
+		item.BatchNumber = trimToNil(item.BatchNumber)
+		if !sparePart.TrackBatches && (item.BatchNumber != nil || item.ExpiryDate != nil) {
+			return fmt.Errorf("%s is not batch-tracked", sparePart.PartCode)
+		}
+		item.SerialNumbers, err = normalizeSerialNumbers(sparePart, item.Quantity, item.SerialNumbers)
+		if err != nil {
+			return err
+		}
+	}
+
+	if receipt.ReceiptNumber == "" {
+		receiptNumber, err := s.goodsReceiptRepo.GenerateReceiptNumber(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to generate receipt number: %w", err)
+		}
+		receipt.ReceiptNumber = receiptNumber
+	}
+
+	if receipt.ReceivedDate.IsZero() {
+		receipt.ReceivedDate = time.Now()
+	}
+
+	if err := s.goodsReceiptRepo.Create(ctx, receipt, s.costingMethod); err != nil {
+		return fmt.Errorf("failed to receive goods: %w", err)
+	}
+
+	return nil
+}
+
+func (s *purchaseOrderService) ListGoodsReceipts(ctx context.Context, purchaseOrderID int) ([]*domain.GoodsReceipt, error) {
+	return s.goodsReceiptRepo.ListByPurchaseOrderID(ctx, purchaseOrderID)
+}
+
+func (s *purchaseOrderService) ListBackorders(ctx context.Context) ([]*domain.PurchaseOrderItem, error) {
+	return s.purchaseOrderRepo.ListOutstandingItems(ctx)
+}
@@ -12,22 +12,22 @@ import (
 type purchaseService struct {
 	purchaseRepo repository.PurchaseInvoiceRepository
 	vehicleRepo  repository.VehicleRepository
-	workOrderRepo repository.WorkOrderRepository
-	userRepo     repository.UserRepository
+	mechanicService MechanicService
+	payableService PayableService
 }
 
 // NewPurchaseService creates a new purchase service
 func NewPurchaseService(
 	purchaseRepo repository.PurchaseInvoiceRepository,
 	vehicleRepo repository.VehicleRepository,
-	workOrderRepo repository.WorkOrderRepository,
-	userRepo repository.UserRepository,
+	mechanicService MechanicService,
+	payableService PayableService,
 ) PurchaseService {
 	return &purchaseService{
 		purchaseRepo: purchaseRepo,
 		vehicleRepo:  vehicleRepo,
-		workOrderRepo: workOrderRepo,
-		userRepo:     userRepo,
+		mechanicService: mechanicService,
+		payableService: payableService,
 	}
 }
 
@@ -46,66 +46,154 @@ func (s *purchaseService) CreatePurchaseInvoice(ctx context.Context, invoice *do
 		invoice.TransactionDate = time.Now()
 	}
 
-	// Set final price if not calculated
-	if invoice.FinalPrice == 0 {
-		if invoice.NegotiatedPrice != nil {
-			invoice.FinalPrice = *invoice.NegotiatedPrice
-		} else {
-			invoice.FinalPrice = invoice.PurchasePrice
+	if len(invoice.Items) > 0 {
+		// Bulk purchase: price every vehicle and spread the shared costs
+		if err := allocatePurchaseItems(invoice); err != nil {
+			return err
+		}
+	} else {
+		if invoice.VehicleID == nil {
+			return fmt.Errorf("vehicle is required")
+		}
+
+		// Set final price if not calculated
+		if invoice.FinalPrice == 0 {
+			if invoice.NegotiatedPrice != nil {
+				invoice.FinalPrice = *invoice.NegotiatedPrice
+			} else {
+				invoice.FinalPrice = invoice.PurchasePrice
+			}
 		}
+
+		invoice.AllocationMethod = domain.AllocationMethodValue
+		invoice.Items = []*domain.PurchaseInvoiceItem{{
+			VehicleID: *invoice.VehicleID,
+			Price:     invoice.FinalPrice,
+			TotalCost: invoice.FinalPrice,
+		}}
 	}
 
-	// Create the purchase invoice
-	if err := s.purchaseRepo.Create(ctx, invoice); err != nil {
-		return fmt.Errorf("failed to create purchase invoice: %w", err)
+	// Load every vehicle up front so a bad ID fails before anything is written.
+	// A vehicle is bought once: sold, consignment and already purchased
+	// vehicles would count their purchase cost in HPP twice.
+	vehicles := make(map[int]*domain.Vehicle)
+	for _, item := range invoice.Items {
+		if _, exists := vehicles[item.VehicleID]; exists {
+			return fmt.Errorf("vehicle %d appears more than once on the invoice", item.VehicleID)
+		}
+		vehicle, err := s.vehicleRepo.GetByID(ctx, item.VehicleID)
+		if err != nil {
+			return fmt.Errorf("failed to get vehicle: %w", err)
+		}
+		if vehicle == nil {
+			return fmt.Errorf("vehicle %d not found", item.VehicleID)
+		}
+		if vehicle.Status == domain.VehicleStatusSold {
+			return fmt.Errorf("vehicle %s is already sold", vehicle.VehicleCode)
+		}
+		if vehicle.IsConsignment {
+			return fmt.Errorf("vehicle %s is a consignment vehicle and cannot be purchased", vehicle.VehicleCode)
+		}
+		existing, err := s.purchaseRepo.GetByVehicleID(ctx, vehicle.ID)
+		if err != nil {
+			return fmt.Errorf("failed to check existing purchase: %w", err)
+		}
+		if existing != nil {
+			return fmt.Errorf("vehicle %s was already purchased on invoice %s", vehicle.VehicleCode, existing.InvoiceNumber)
+		}
+		vehicles[item.VehicleID] = vehicle
 	}
 
-	// Update vehicle information
-	vehicle, err := s.vehicleRepo.GetByID(ctx, invoice.VehicleID)
-	if err != nil {
-		return fmt.Errorf("failed to get vehicle: %w", err)
+	// Open the payable (paid in full unless payment terms were given)
+	if err := s.payableService.PreparePayable(ctx, invoice); err != nil {
+		return err
 	}
 
-	// Update vehicle with purchase information
-	vehicle.PurchasePrice = &invoice.FinalPrice
-	vehicle.Status = domain.VehicleStatusInRepair
-	vehicle.PurchasedDate = &invoice.TransactionDate
-	
-	if err := s.vehicleRepo.Update(ctx, vehicle); err != nil {
-		return fmt.Errorf("failed to update vehicle: %w", err)
+	for _, item := range invoice.Items {
+		vehicle := vehicles[item.VehicleID]
+		item.Vehicle = vehicle
+
+		// Update vehicle with purchase information
+		purchasePrice := item.TotalCost
+		vehicle.PurchasePrice = &purchasePrice
+		vehicle.Status = domain.VehicleStatusInRepair
+		vehicle.PurchasedDate = &invoice.TransactionDate
+
+		// Auto-create work order for the purchased vehicle
+		workOrder, err := s.newPurchasedVehicleWorkOrder(ctx, invoice, vehicle)
+		if err != nil {
+			return fmt.Errorf("failed to prepare work order: %w", err)
+		}
+		item.WorkOrder = workOrder
 	}
 
-	// Auto-create work order for the purchased vehicle
-	if err := s.createWorkOrderForPurchasedVehicle(ctx, invoice); err != nil {
-		return fmt.Errorf("failed to create work order: %w", err)
+	// The invoice, payable, vehicle updates and work orders are saved
+	// together, so a failure leaves nothing half purchased
+	if err := s.purchaseRepo.Create(ctx, invoice); err != nil {
+		return fmt.Errorf("failed to create purchase invoice: %w", err)
 	}
 
 	return nil
 }
 
-func (s *purchaseService) createWorkOrderForPurchasedVehicle(ctx context.Context, invoice *domain.PurchaseInvoice) error {
-	// Get available mechanics
-	mechanics, err := s.userRepo.GetByRole(ctx, domain.RoleMekanik)
-	if err != nil {
-		return fmt.Errorf("failed to get mechanics: %w", err)
+// allocatePurchaseItems totals a bulk purchase and spreads its shared costs
+// over the vehicles, either pro rata to price or equally. Rounding leftovers
+// go to the last vehicle so the items always add up to the invoice total.
+func allocatePurchaseItems(invoice *domain.PurchaseInvoice) error {
+	if invoice.SharedCost < 0 {
+		return fmt.Errorf("shared cost cannot be negative")
+	}
+	if invoice.AllocationMethod == "" {
+		invoice.AllocationMethod = domain.AllocationMethodValue
+	}
+	if invoice.AllocationMethod != domain.AllocationMethodValue && invoice.AllocationMethod != domain.AllocationMethodEqual {
+		return fmt.Errorf("invalid allocation method: %s", invoice.AllocationMethod)
 	}
 
-	if len(mechanics) == 0 {
-		return fmt.Errorf("no mechanics available to assign work order")
+	var totalPrice float64
+	for _, item := range invoice.Items {
+		if item.Price <= 0 {
+			return fmt.Errorf("price for vehicle %d must be greater than 0", item.VehicleID)
+		}
+		totalPrice += item.Price
 	}
 
-	// Generate work order number
-	woNumber, err := s.workOrderRepo.GenerateWONumber(ctx)
+	remaining := invoice.SharedCost
+	for i, item := range invoice.Items {
+		if i == len(invoice.Items)-1 {
+			item.AllocatedCost = roundCost(remaining)
+		} else if invoice.AllocationMethod == domain.AllocationMethodEqual {
+			item.AllocatedCost = roundCost(invoice.SharedCost / float64(len(invoice.Items)))
+		} else {
+			item.AllocatedCost = roundCost(invoice.SharedCost * item.Price / totalPrice)
+		}
+		remaining -= item.AllocatedCost
+		item.TotalCost = item.Price + item.AllocatedCost
+	}
+
+	invoice.VehicleID = nil
+	invoice.PurchasePrice = totalPrice
+	invoice.NegotiatedPrice = nil
+	invoice.FinalPrice = totalPrice + invoice.SharedCost
+
+	return nil
+}
+
+// newPurchasedVehicleWorkOrder prepares the inspection work order of a
+// purchased vehicle. It is numbered when saved with the invoice.
+func (s *purchaseService) newPurchasedVehicleWorkOrder(ctx context.Context, invoice *domain.PurchaseInvoice, vehicle *domain.Vehicle) (*domain.WorkOrder, error) {
+	// Pick a mechanic with the configured assignment strategy
+	assignment, err := s.mechanicService.AssignMechanic(ctx, vehicle)
 	if err != nil {
-		return fmt.Errorf("failed to generate work order number: %w", err)
+		return nil, err
 	}
 
-	// Create work order
 	workOrder := &domain.WorkOrder{
-		WONumber:            woNumber,
-		VehicleID:           invoice.VehicleID,
-		Description:         fmt.Sprintf("Initial inspection and repair assessment for purchased vehicle %s", getVehicleDescription(invoice)),
-		AssignedMechanicID:  mechanics[0].ID, // Assign to first available mechanic
+		OrderType:           domain.WorkOrderTypeReconditioning,
+		VehicleID:           &vehicle.ID,
+		Description:         fmt.Sprintf("Initial inspection and repair assessment for purchased vehicle %s (%s)", getVehicleDescription(vehicle), invoice.InvoiceNumber),
+		AssignedMechanicID:  assignment.MechanicID,
+		AssignmentReason:    &assignment.Reason,
 		Status:              domain.WorkOrderStatusPending,
 		ProgressPercentage:  0,
 		TotalPartsCost:      0,
@@ -114,22 +202,28 @@ func (s *purchaseService) createWorkOrderForPurchasedVehicle(ctx context.Context
 		CreatedBy:           invoice.CreatedBy,
 	}
 
-	if err := s.workOrderRepo.Create(ctx, workOrder); err != nil {
-		return fmt.Errorf("failed to create work order: %w", err)
-	}
-
-	return nil
+	return workOrder, nil
 }
 
-func getVehicleDescription(invoice *domain.PurchaseInvoice) string {
-	if invoice.Vehicle != nil {
-		return fmt.Sprintf("%s %s %d", invoice.Vehicle.Brand, invoice.Vehicle.Model, invoice.Vehicle.Year)
+func getVehicleDescription(vehicle *domain.Vehicle) string {
+	if vehicle != nil {
+		return fmt.Sprintf("%s %s %d", vehicle.Brand, vehicle.Model, vehicle.Year)
 	}
 	return "Unknown Vehicle"
 }
 
 func (s *purchaseService) GetPurchaseInvoiceByID(ctx context.Context, id int) (*domain.PurchaseInvoice, error) {
-	return s.purchaseRepo.GetByID(ctx, id)
+	invoice, err := s.purchaseRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	invoice.Items, err = s.purchaseRepo.ListItemsByInvoiceID(ctx, invoice.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return invoice, nil
 }
 
 func (s *purchaseService) GetPurchaseInvoiceByNumber(ctx context.Context, invoiceNumber string) (*domain.PurchaseInvoice, error) {
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"pos-final/internal/domain"
+	"pos-final/internal/repository"
+	"time"
+)
+
+type replenishmentService struct {
+	replenishmentRepo    repository.ReplenishmentRepository
+	supplierRepo         repository.SupplierRepository
+	userRepo             repository.UserRepository
+	purchaseOrderService PurchaseOrderService
+	notificationService  NotificationService
+	policy               domain.ReplenishmentPolicy
+}
+
+// NewReplenishmentService creates a new replenishment service
+func NewReplenishmentService(
+	replenishmentRepo repository.ReplenishmentRepository,
+	supplierRepo repository.SupplierRepository,
+	userRepo repository.UserRepository,
+	purchaseOrderService PurchaseOrderService,
+	notificationService NotificationService,
+	policy domain.ReplenishmentPolicy,
+) ReplenishmentService {
+	if policy.UsageWindowDays <= 0 {
+		policy.UsageWindowDays = 90
+	}
+
+	return &replenishmentService{
+		replenishmentRepo:    replenishmentRepo,
+		supplierRepo:         supplierRepo,
+		userRepo:             userRepo,
+		purchaseOrderService: purchaseOrderService,
+		notificationService:  notificationService,
+		policy:               policy,
+	}
+}
+
+func (s *replenishmentService) GetPolicy() domain.ReplenishmentPolicy {
+	return s.policy
+}
+
+// ListSuggestions derives each part's reorder point and quantity from its
+// average daily usage. Only parts that need reordering are returned unless
+// includeAll is set.
+func (s *replenishmentService) ListSuggestions(ctx context.Context, supplierID *int, includeAll bool) ([]*domain.ReorderSuggestion, error) {
+	today := time.Now().Truncate(24 * time.Hour)
+	since := today.AddDate(0, 0, -s.policy.UsageWindowDays)
+
+	rows, err := s.replenishmentRepo.ListUsage(ctx, since, s.policy, supplierID)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]*domain.ReorderSuggestion, 0, len(rows))
+	for _, suggestion := range rows {
+		s.applyPolicy(suggestion)
+		if includeAll || suggestion.NeedsReorder {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	return suggestions, nil
+}
+
+// applyPolicy fills in the derived figures:
+//
+//	safety stock  = daily usage x safety stock days
+//	reorder point = daily usage x lead time + safety stock, never below the static minimum
+//	order-up-to   = reorder point + daily usage x cover days
+//
+// Stock on order counts towards the position, so parts already on a draft
+// or open purchase order are not suggested twice.
+func (s *replenishmentService) applyPolicy(suggestion *domain.ReorderSuggestion) {
+	dailyUsage := 0.0
+	if suggestion.UsageQuantity > 0 {
+		dailyUsage = float64(suggestion.UsageQuantity) / float64(s.policy.UsageWindowDays)
+	}
+
+	safetyStock := int(math.Ceil(dailyUsage * float64(suggestion.SafetyStockDays)))
+	reorderPoint := int(math.Ceil(dailyUsage*float64(suggestion.LeadTimeDays))) + safetyStock
+	if reorderPoint < suggestion.MinStockLevel {
+		reorderPoint = suggestion.MinStockLevel
+	}
+	orderUpTo := reorderPoint + int(math.Ceil(dailyUsage*float64(s.policy.CoverDays)))
+
+	position := suggestion.StockQuantity + suggestion.OnOrderQuantity
+	reorderQuantity := 0
+	if position <= reorderPoint && orderUpTo > position {
+		reorderQuantity = orderUpTo - position
+	}
+
+	suggestion.AverageDailyUsage = math.Round(dailyUsage*100) / 100
+	suggestion.SafetyStock = safetyStock
+	suggestion.ReorderPoint = reorderPoint
+	suggestion.ReorderQuantity = reorderQuantity
+	suggestion.NeedsReorder = reorderQuantity > 0
+}
+
+func (s *replenishmentService) UpdateSettings(ctx context.Context, settings *domain.ReorderSettings) error {
+	if settings.SafetyStockDays != nil && *settings.SafetyStockDays < 0 {
+		return fmt.Errorf("safety stock days cannot be negative")
+	}
+
+	if settings.PreferredSupplierID != nil {
+		supplier, err := s.supplierRepo.GetByID(ctx, *settings.PreferredSupplierID)
+		if err != nil {
+			return fmt.Errorf("failed to get supplier: %w", err)
+		}
+		if supplier == nil {
+			return fmt.Errorf("supplier not found")
+		}
+	}
+
+	return s.replenishmentRepo.UpdateSettings(ctx, settings)
+}
+
+func (s *replenishmentService) UpdateSupplierLeadTime(ctx context.Context, supplierID int, leadTimeDays int) error {
+	if leadTimeDays < 0 {
+		return fmt.Errorf("lead time cannot be negative")
+	}
+
+	supplier, err := s.supplierRepo.GetByID(ctx, supplierID)
+	if err != nil {
+		return fmt.Errorf("failed to get supplier: %w", err)
+	}
+	if supplier == nil {
+		return fmt.Errorf("supplier not found")
+	}
+
+	return s.supplierRepo.UpdateLeadTime(ctx, supplierID, leadTimeDays)
+}
+
+// GenerateDraftPurchaseOrders turns the current suggestions into one draft
+// purchase order per supplier. Parts with auto reorder switched off are
+// skipped; parts without a supplier are returned as unassigned.
+func (s *replenishmentService) GenerateDraftPurchaseOrders(ctx context.Context, supplierID *int, createdBy int) (*domain.ReorderRun, error) {
+	suggestions, err := s.ListSuggestions(ctx, supplierID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &domain.ReorderRun{
+		PurchaseOrders: []*domain.PurchaseOrder{},
+		Unassigned:     []*domain.ReorderSuggestion{},
+		Failed:         []*domain.ReorderFailure{},
+	}
+
+	groups := make(map[int][]*domain.ReorderSuggestion)
+	var supplierIDs []int
+	for _, suggestion := range suggestions {
+		if !suggestion.AutoReorder {
+			continue
+		}
+		if suggestion.SupplierID == nil {
+			run.Unassigned = append(run.Unassigned, suggestion)
+			continue
+		}
+
+		id := *suggestion.SupplierID
+		if _, ok := groups[id]; !ok {
+			supplierIDs = append(supplierIDs, id)
+		}
+		groups[id] = append(groups[id], suggestion)
+	}
+
+	for _, id := range supplierIDs {
+		lines := groups[id]
+		expectedDate := time.Now().AddDate(0, 0, lines[0].LeadTimeDays)
+		notes := "Generated from reorder suggestions"
+
+		po := &domain.PurchaseOrder{
+			SupplierID:    id,
+			Status:        domain.PurchaseOrderStatusDraft,
+			ExpectedDate:  &expectedDate,
+			Notes:         &notes,
+			AutoGenerated: true,
+			CreatedBy:     createdBy,
+		}
+		for _, line := range lines {
+			po.Items = append(po.Items, &domain.PurchaseOrderItem{
+				SparePartID:     line.SparePartID,
+				QuantityOrdered: line.ReorderQuantity,
+				UnitCost:        line.CostPrice,
+			})
+		}
+
+		// Each draft is saved on its own; a failed supplier is reported and
+		// does not discard the drafts already created
+		if err := s.purchaseOrderService.CreatePurchaseOrder(ctx, po); err != nil {
+			run.Failed = append(run.Failed, &domain.ReorderFailure{
+				SupplierID:   id,
+				SupplierName: lines[0].SupplierName,
+				Error:        err.Error(),
+			})
+			continue
+		}
+
+		run.PurchaseOrders = append(run.PurchaseOrders, po)
+	}
+
+	return run, nil
+}
+
+// RunScheduledReorder generates draft purchase orders on behalf of the first
+// admin and notifies admins of each draft
+func (s *replenishmentService) RunScheduledReorder(ctx context.Context) (*domain.ReorderRun, error) {
+	admins, err := s.userRepo.GetByRole(ctx, domain.RoleAdmin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get admin users: %w", err)
+	}
+	if len(admins) == 0 {
+		return nil, fmt.Errorf("no admin user to create purchase orders as")
+	}
+
+	run, err := s.GenerateDraftPurchaseOrders(ctx, nil, admins[0].ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, po := range run.PurchaseOrders {
+		if err := s.notificationService.NotifyReorderDraft(ctx, po); err != nil {
+			log.Printf("failed to notify reorder draft %s: %v", po.PONumber, err)
+		}
+	}
+
+	return run, nil
+}
+
+// StartReorderScheduler runs the scheduled reorder every interval until the
+// context is done
+func StartReorderScheduler(ctx context.Context, replenishmentService ReplenishmentService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			run, err := replenishmentService.RunScheduledReorder(ctx)
+			if err != nil {
+				log.Printf("scheduled reorder failed: %v", err)
+				continue
+			}
+			for _, failure := range run.Failed {
+				log.Printf("scheduled reorder failed for supplier %d: %s", failure.SupplierID, failure.Error)
+			}
+			log.Printf("scheduled reorder created %d draft purchase orders, %d parts without supplier, %d suppliers failed", len(run.PurchaseOrders), len(run.Unassigned), len(run.Failed))
+		}
+	}
+}
@@ -253,9 +253,15 @@ func (s *reportService) GetInventoryReport(ctx context.Context) (map[string]inte
 	// Calculate vehicle metrics
 	var vehiclesByStatus = make(map[string]int)
 	var totalVehicleValue float64
+	var consignmentVehicles int
 
 	for _, vehicle := range vehicles {
 		vehiclesByStatus[string(vehicle.Status)]++
+		// Consignment vehicles belong to their owners
+		if vehicle.IsConsignment {
+			consignmentVehicles++
+			continue
+		}
 		if vehicle.HPP != nil {
 			totalVehicleValue += *vehicle.HPP
 		}
@@ -273,6 +279,7 @@ func (s *reportService) GetInventoryReport(ctx context.Context) (map[string]inte
 			"total_vehicles":     len(vehicles),
 			"total_value":        totalVehicleValue,
 			"vehicles_by_status": vehiclesByStatus,
+			"consignment_count":  consignmentVehicles,
 		},
 		"alerts": map[string]interface{}{
 			"low_stock_parts":  lowStockCount,
@@ -348,7 +355,8 @@ func (s *reportService) GetVehicleReport(ctx context.Context) (map[string]interf
 		brandBreakdown[vehicle.Brand]++
 		yearBreakdown[vehicle.Year]++
 		
-		if vehicle.HPP != nil {
+		// Consignment vehicles are not dealer inventory
+		if vehicle.HPP != nil && !vehicle.IsConsignment {
 			totalInventoryValue += *vehicle.HPP
 		}
 	}
@@ -10,18 +10,27 @@ import (
 )
 
 type salesService struct {
-	salesRepo   repository.SalesInvoiceRepository
-	vehicleRepo repository.VehicleRepository
+	salesRepo          repository.SalesInvoiceRepository
+	vehicleRepo        repository.VehicleRepository
+	warrantyRepo       repository.WarrantyRepository
+	consignmentService ConsignmentService
+	documentService    VehicleDocumentService
 }
 
 // NewSalesService creates a new sales service
 func NewSalesService(
 	salesRepo repository.SalesInvoiceRepository,
 	vehicleRepo repository.VehicleRepository,
+	warrantyRepo repository.WarrantyRepository,
+	consignmentService ConsignmentService,
+	documentService VehicleDocumentService,
 ) SalesService {
 	return &salesService{
-		salesRepo:   salesRepo,
-		vehicleRepo: vehicleRepo,
+		salesRepo:          salesRepo,
+		vehicleRepo:        vehicleRepo,
+		warrantyRepo:       warrantyRepo,
+		consignmentService: consignmentService,
+		documentService:    documentService,
 	}
 }
 
@@ -51,6 +60,13 @@ func (s *salesService) CreateSalesInvoice(ctx context.Context, invoice *domain.S
 		return fmt.Errorf("vehicle is not available for sale (current status: %s)", vehicle.Status)
 	}
 
+	// Check BPKB/STNK custody before handing the vehicle over
+	warnings, err := s.documentService.CheckSaleDocuments(ctx, vehicle.ID)
+	if err != nil {
+		return err
+	}
+	invoice.DocumentWarnings = warnings
+
 	// Calculate discount amount if percentage is provided
 	if invoice.DiscountPercentage > 0 {
 		invoice.DiscountAmount = invoice.SellingPrice * (invoice.DiscountPercentage / 100)
@@ -59,8 +75,15 @@ func (s *salesService) CreateSalesInvoice(ctx context.Context, invoice *domain.S
 	// Calculate final price
 	invoice.FinalPrice = invoice.SellingPrice - invoice.DiscountAmount
 
-	// Calculate profit (Final Price - HPP)
-	if vehicle.HPP != nil {
+	// Calculate profit (Final Price - HPP). A consignment sale only earns
+	// the dealer commission; the rest is owed to the owner.
+	if vehicle.IsConsignment {
+		commission, err := s.consignmentService.CalculateCommission(ctx, vehicle.ID, invoice.FinalPrice)
+		if err != nil {
+			return err
+		}
+		invoice.ProfitAmount = commission
+	} else if vehicle.HPP != nil {
 		invoice.ProfitAmount = invoice.FinalPrice - *vehicle.HPP
 	} else {
 		// If HPP not set, profit is selling price minus purchase price and repair cost
@@ -72,8 +95,17 @@ func (s *salesService) CreateSalesInvoice(ctx context.Context, invoice *domain.S
 		invoice.ProfitAmount = profit
 	}
 
-	// Create the sales invoice
-	if err := s.salesRepo.Create(ctx, invoice); err != nil {
+	// Create the sales invoice. A consignment sale settles the consignment and
+	// opens the owner payable in the same transaction.
+	if vehicle.IsConsignment {
+		consignment, err := s.consignmentService.PrepareSale(ctx, invoice)
+		if err != nil {
+			return fmt.Errorf("failed to settle consignment: %w", err)
+		}
+		if err := s.salesRepo.CreateConsignmentSale(ctx, invoice, consignment); err != nil {
+			return fmt.Errorf("failed to create sales invoice: %w", err)
+		}
+	} else if err := s.salesRepo.Create(ctx, invoice); err != nil {
 		return fmt.Errorf("failed to create sales invoice: %w", err)
 	}
 
@@ -158,6 +190,19 @@ func (s *salesService) UpdateSalesInvoice(ctx context.Context, invoice *domain.S
 		return fmt.Errorf("failed to get vehicle: %w", err)
 	}
 
+	// The owner's payable is fixed once a consignment vehicle is sold
+	if vehicle.IsConsignment {
+		existing, err := s.salesRepo.GetByID(ctx, invoice.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get sales invoice: %w", err)
+		}
+		if existing.FinalPrice != invoice.FinalPrice {
+			return fmt.Errorf("consignment sales cannot be repriced")
+		}
+		invoice.ProfitAmount = existing.ProfitAmount
+		return s.salesRepo.Update(ctx, invoice)
+	}
+
 	if vehicle.HPP != nil {
 		invoice.ProfitAmount = invoice.FinalPrice - *vehicle.HPP
 	}
@@ -172,13 +217,38 @@ func (s *salesService) DeleteSalesInvoice(ctx context.Context, id int, deletedBy
 		return fmt.Errorf("failed to get sales invoice: %w", err)
 	}
 
+	vehicle, err := s.vehicleRepo.GetByID(ctx, invoice.VehicleID)
+	if err != nil {
+		return fmt.Errorf("failed to get vehicle: %w", err)
+	}
+
+	// A consignment sale reopens the consignment and drops the unpaid owner
+	// payable; it cannot be deleted once the owner has been paid
+	if vehicle.IsConsignment {
+		if err := s.salesRepo.DeleteConsignmentSale(ctx, id, deletedBy); err != nil {
+			return err
+		}
+	} else if err := s.salesRepo.SoftDelete(ctx, id, deletedBy); err != nil {
+		return err
+	}
+
+	// Claims cannot be filed against a sale that no longer exists
+	warranty, err := s.warrantyRepo.GetBySalesInvoiceID(ctx, invoice.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get warranty: %w", err)
+	}
+	if warranty != nil && warranty.Status != domain.WarrantyStatusVoid {
+		if err := s.warrantyRepo.UpdateStatus(ctx, warranty.ID, domain.WarrantyStatusVoid); err != nil {
+			return fmt.Errorf("failed to void warranty: %w", err)
+		}
+	}
+
 	// Update vehicle status back to available
 	if err := s.vehicleRepo.UpdateStatus(ctx, invoice.VehicleID, domain.VehicleStatusAvailable); err != nil {
 		return fmt.Errorf("failed to update vehicle status: %w", err)
 	}
 
-	// Delete the sales invoice
-	return s.salesRepo.SoftDelete(ctx, id, deletedBy)
+	return nil
 }
 
 func (s *salesService) UploadTransferProof(ctx context.Context, invoiceID int, file *multipart.FileHeader) error {
@@ -0,0 +1,457 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"pos-final/internal/domain"
+	"pos-final/internal/repository"
+	"strings"
+	"time"
+)
+
+type scheduleService struct {
+	bayRepo         repository.WorkshopBayRepository
+	scheduleRepo    repository.WorkOrderScheduleRepository
+	workOrderRepo   repository.WorkOrderRepository
+	taskRepo        repository.WorkOrderTaskRepository
+	mechanicRepo    repository.MechanicRepository
+	mechanicService MechanicService
+}
+
+// NewScheduleService creates a new schedule service
+func NewScheduleService(
+	bayRepo repository.WorkshopBayRepository,
+	scheduleRepo repository.WorkOrderScheduleRepository,
+	workOrderRepo repository.WorkOrderRepository,
+	taskRepo repository.WorkOrderTaskRepository,
+	mechanicRepo repository.MechanicRepository,
+	mechanicService MechanicService,
+) ScheduleService {
+	return &scheduleService{
+		bayRepo:         bayRepo,
+		scheduleRepo:    scheduleRepo,
+		workOrderRepo:   workOrderRepo,
+		taskRepo:        taskRepo,
+		mechanicRepo:    mechanicRepo,
+		mechanicService: mechanicService,
+	}
+}
+
+func (s *scheduleService) CreateBay(ctx context.Context, bay *domain.WorkshopBay) error {
+	bay.Code = strings.TrimSpace(bay.Code)
+	bay.Name = strings.TrimSpace(bay.Name)
+	if bay.Code == "" || bay.Name == "" {
+		return fmt.Errorf("bay code and name are required")
+	}
+
+	return s.bayRepo.Create(ctx, bay)
+}
+
+func (s *scheduleService) ListBays(ctx context.Context) ([]*domain.WorkshopBay, error) {
+	return s.bayRepo.List(ctx)
+}
+
+func (s *scheduleService) UpdateBay(ctx context.Context, bay *domain.WorkshopBay) error {
+	existing, err := s.bayRepo.GetByID(ctx, bay.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("workshop bay not found")
+	}
+
+	bay.Code = strings.TrimSpace(bay.Code)
+	bay.Name = strings.TrimSpace(bay.Name)
+	if bay.Code == "" || bay.Name == "" {
+		return fmt.Errorf("bay code and name are required")
+	}
+
+	return s.bayRepo.Update(ctx, bay)
+}
+
+func (s *scheduleService) DeleteBay(ctx context.Context, id int, deletedBy int) error {
+	return s.bayRepo.SoftDelete(ctx, id, deletedBy)
+}
+
+// ScheduleWork plans a work order, or one of its tasks, on a bay and/or a
+// mechanic. Double booking, leave and time outside the mechanic's working
+// hours are rejected with the list of conflicts.
+func (s *scheduleService) ScheduleWork(ctx context.Context, schedule *domain.WorkOrderSchedule) error {
+	workOrder, err := s.validateSchedule(ctx, schedule)
+	if err != nil {
+		return err
+	}
+
+	if err := s.scheduleRepo.Create(ctx, schedule); err != nil {
+		return err
+	}
+
+	return s.recalculateWorkOrder(ctx, workOrder)
+}
+
+func (s *scheduleService) UpdateSchedule(ctx context.Context, schedule *domain.WorkOrderSchedule) error {
+	existing, err := s.getSchedule(ctx, schedule.ID)
+	if err != nil {
+		return err
+	}
+	schedule.WorkOrderID = existing.WorkOrderID
+
+	workOrder, err := s.validateSchedule(ctx, schedule)
+	if err != nil {
+		return err
+	}
+
+	if err := s.scheduleRepo.Update(ctx, schedule); err != nil {
+		return err
+	}
+
+	return s.recalculateWorkOrder(ctx, workOrder)
+}
+
+func (s *scheduleService) DeleteSchedule(ctx context.Context, id int, deletedBy int) error {
+	existing, err := s.getSchedule(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.scheduleRepo.Delete(ctx, id, deletedBy); err != nil {
+		return err
+	}
+
+	workOrder, err := s.workOrderRepo.GetByID(ctx, existing.WorkOrderID)
+	if err != nil {
+		return fmt.Errorf("failed to get work order: %w", err)
+	}
+
+	return s.recalculateWorkOrder(ctx, workOrder)
+}
+
+// ListWorkOrderSchedule returns the planned work of a work order. Reading it
+// does not refresh the estimate; the estimate scheduler does.
+func (s *scheduleService) ListWorkOrderSchedule(ctx context.Context, workOrderID int) ([]*domain.WorkOrderSchedule, error) {
+	if _, err := s.workOrderRepo.GetByID(ctx, workOrderID); err != nil {
+		return nil, fmt.Errorf("failed to get work order: %w", err)
+	}
+
+	schedules, err := s.scheduleRepo.ListByWorkOrderID(ctx, workOrderID)
+	if err != nil {
+		return nil, err
+	}
+	markDelayed(schedules, time.Now())
+
+	return schedules, nil
+}
+
+// GetCalendar returns the planned work for the day of date, or for the
+// Monday-to-Sunday week containing it
+func (s *scheduleService) GetCalendar(ctx context.Context, view string, date time.Time, bayID, mechanicID *int) (*domain.ScheduleCalendar, error) {
+	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local)
+	days := 1
+
+	switch view {
+	case "", "day":
+		view = "day"
+	case "week":
+		offset := (int(start.Weekday()) + 6) % 7
+		start = start.AddDate(0, 0, -offset)
+		days = 7
+	default:
+		return nil, fmt.Errorf("invalid calendar view %q, use day or week", view)
+	}
+	end := start.AddDate(0, 0, days)
+
+	schedules, err := s.scheduleRepo.ListInRange(ctx, start, end, bayID, mechanicID)
+	if err != nil {
+		return nil, err
+	}
+	markDelayed(schedules, time.Now())
+
+	calendar := &domain.ScheduleCalendar{
+		View:      view,
+		StartDate: start,
+		EndDate:   end.AddDate(0, 0, -1),
+		Days:      make([]*domain.ScheduleCalendarDay, 0, days),
+	}
+	for i := 0; i < days; i++ {
+		dayStart := start.AddDate(0, 0, i)
+		day := &domain.ScheduleCalendarDay{Date: dayStart, Entries: []*domain.WorkOrderSchedule{}}
+		for _, schedule := range schedules {
+			if schedule.Overlaps(dayStart, dayStart.AddDate(0, 0, 1)) {
+				day.Entries = append(day.Entries, schedule)
+			}
+		}
+		calendar.Days = append(calendar.Days, day)
+	}
+
+	return calendar, nil
+}
+
+// RecalculateEstimates projects the completion time of every scheduled open
+// work order and returns how many estimates changed
+func (s *scheduleService) RecalculateEstimates(ctx context.Context) (int, error) {
+	schedules, err := s.scheduleRepo.ListOpen(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	byWorkOrder := make(map[int][]*domain.WorkOrderSchedule)
+	var order []int
+	for _, schedule := range schedules {
+		if _, ok := byWorkOrder[schedule.WorkOrderID]; !ok {
+			order = append(order, schedule.WorkOrderID)
+		}
+		byWorkOrder[schedule.WorkOrderID] = append(byWorkOrder[schedule.WorkOrderID], schedule)
+	}
+
+	updated := 0
+	now := time.Now()
+	for _, workOrderID := range order {
+		workOrder, err := s.workOrderRepo.GetByID(ctx, workOrderID)
+		if err != nil {
+			return updated, fmt.Errorf("failed to get work order: %w", err)
+		}
+
+		changed, err := s.storeEstimate(ctx, workOrder, estimateCompletion(byWorkOrder[workOrderID], now))
+		if err != nil {
+			return updated, err
+		}
+		if changed {
+			updated++
+		}
+	}
+
+	return updated, nil
+}
+
+func (s *scheduleService) recalculateWorkOrder(ctx context.Context, workOrder *domain.WorkOrder) error {
+	if workOrder.Status == domain.WorkOrderStatusCompleted || workOrder.Status == domain.WorkOrderStatusCancelled {
+		return nil
+	}
+
+	schedules, err := s.scheduleRepo.ListByWorkOrderID(ctx, workOrder.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.storeEstimate(ctx, workOrder, estimateCompletion(schedules, time.Now()))
+	return err
+}
+
+func (s *scheduleService) storeEstimate(ctx context.Context, workOrder *domain.WorkOrder, estimate *time.Time) (bool, error) {
+	current := workOrder.EstimatedCompletionAt
+	if current == nil && estimate == nil {
+		return false, nil
+	}
+	if current != nil && estimate != nil && current.Equal(*estimate) {
+		return false, nil
+	}
+
+	if err := s.workOrderRepo.UpdateEstimatedCompletion(ctx, workOrder.ID, estimate); err != nil {
+		return false, err
+	}
+	workOrder.EstimatedCompletionAt = estimate
+
+	return true, nil
+}
+
+// estimateCompletion walks a work order's open planned work in order. Work
+// that overran its slot is projected to finish after now for the share still
+// to do, and that delay pushes back everything planned after it.
+func estimateCompletion(schedules []*domain.WorkOrderSchedule, now time.Time) *time.Time {
+	var estimate *time.Time
+	var delay time.Duration
+
+	for _, schedule := range schedules {
+		if !schedule.IsOpen() {
+			continue
+		}
+
+		start := schedule.PlannedStart.Add(delay)
+		end := schedule.PlannedEnd.Add(delay)
+		if end.Before(now) {
+			remaining := schedule.PlannedEnd.Sub(schedule.PlannedStart)
+			if schedule.WorkOrderTaskID == nil {
+				remaining = remaining * time.Duration(100-schedule.ProgressPercentage) / 100
+			}
+			if start.Before(now) {
+				start = now
+			}
+			projected := start.Add(remaining)
+			delay += projected.Sub(end)
+			end = projected
+		}
+
+		if estimate == nil || end.After(*estimate) {
+			projectedEnd := end.Truncate(time.Minute)
+			estimate = &projectedEnd
+		}
+	}
+
+	return estimate
+}
+
+func markDelayed(schedules []*domain.WorkOrderSchedule, now time.Time) {
+	for _, schedule := range schedules {
+		schedule.IsDelayed = schedule.IsOpen() && schedule.PlannedEnd.Before(now)
+	}
+}
+
+func (s *scheduleService) getSchedule(ctx context.Context, id int) (*domain.WorkOrderSchedule, error) {
+	schedule, err := s.scheduleRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil {
+		return nil, fmt.Errorf("work order schedule not found")
+	}
+
+	return schedule, nil
+}
+
+// validateSchedule checks the work order, task and bay, fills in the mechanic
+// when not given and rejects conflicting plans
+func (s *scheduleService) validateSchedule(ctx context.Context, schedule *domain.WorkOrderSchedule) (*domain.WorkOrder, error) {
+	if !schedule.PlannedEnd.After(schedule.PlannedStart) {
+		return nil, fmt.Errorf("planned end must be after planned start")
+	}
+
+	workOrder, err := s.workOrderRepo.GetByID(ctx, schedule.WorkOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get work order: %w", err)
+	}
+	if workOrder.Status == domain.WorkOrderStatusCompleted || workOrder.Status == domain.WorkOrderStatusCancelled {
+		return nil, fmt.Errorf("work order is %s", workOrder.Status)
+	}
+
+	if schedule.WorkOrderTaskID != nil {
+		task, err := s.taskRepo.GetByID(ctx, *schedule.WorkOrderTaskID)
+		if err != nil {
+			return nil, err
+		}
+		if task == nil || task.WorkOrderID != workOrder.ID {
+			return nil, fmt.Errorf("task does not belong to this work order")
+		}
+		if !task.Status.IsOpen() {
+			return nil, fmt.Errorf("task is %s", task.Status)
+		}
+		if schedule.MechanicID == nil {
+			schedule.MechanicID = task.AssignedMechanicID
+		}
+	}
+	if schedule.MechanicID == nil && workOrder.AssignedMechanicID != 0 {
+		mechanicID := workOrder.AssignedMechanicID
+		schedule.MechanicID = &mechanicID
+	}
+
+	if schedule.BayID != nil {
+		bay, err := s.bayRepo.GetByID(ctx, *schedule.BayID)
+		if err != nil {
+			return nil, err
+		}
+		if bay == nil {
+			return nil, fmt.Errorf("workshop bay not found")
+		}
+		if !bay.IsActive {
+			return nil, fmt.Errorf("workshop bay %s is not active", bay.Code)
+		}
+	}
+
+	conflicts, err := s.findConflicts(ctx, schedule)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("schedule conflicts: %s", strings.Join(conflicts, "; "))
+	}
+
+	return workOrder, nil
+}
+
+func (s *scheduleService) findConflicts(ctx context.Context, schedule *domain.WorkOrderSchedule) ([]string, error) {
+	var conflicts []string
+
+	overlapping, err := s.scheduleRepo.ListConflicts(ctx, schedule)
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range overlapping {
+		resource := "mechanic"
+		if schedule.BayID != nil && other.BayID != nil && *other.BayID == *schedule.BayID {
+			resource = "bay"
+		}
+		conflicts = append(conflicts, fmt.Sprintf("%s already booked for %s from %s to %s",
+			resource, other.WONumber,
+			other.PlannedStart.Format("2006-01-02 15:04"), other.PlannedEnd.Format("2006-01-02 15:04")))
+	}
+
+	if schedule.MechanicID == nil {
+		return conflicts, nil
+	}
+
+	leaves, err := s.mechanicRepo.ListLeavesByMechanicID(ctx, *schedule.MechanicID)
+	if err != nil {
+		return nil, err
+	}
+	for _, leave := range leaves {
+		// Leave dates are whole days, inclusive of the end date
+		if schedule.PlannedStart.Before(leave.EndDate.AddDate(0, 0, 1)) && schedule.PlannedEnd.After(leave.StartDate) {
+			conflicts = append(conflicts, fmt.Sprintf("mechanic is on leave from %s to %s",
+				leave.StartDate.Format("2006-01-02"), leave.EndDate.Format("2006-01-02")))
+		}
+	}
+
+	hours, err := s.mechanicService.GetWorkingHours(ctx, *schedule.MechanicID)
+	if err != nil {
+		return nil, err
+	}
+	if !withinWorkingHours(hours, schedule.PlannedStart, schedule.PlannedEnd) {
+		conflicts = append(conflicts, "planned time is outside the mechanic's working hours")
+	}
+
+	return conflicts, nil
+}
+
+// withinWorkingHours reports whether the work starts and ends inside the
+// mechanic's shift on those days
+func withinWorkingHours(hours []*domain.MechanicWorkingHours, start, end time.Time) bool {
+	if len(hours) == 0 {
+		return true
+	}
+
+	shifts := make(map[int]*domain.MechanicWorkingHours)
+	for _, shift := range hours {
+		shifts[shift.DayOfWeek] = shift
+	}
+
+	startShift, ok := shifts[int(start.Weekday())]
+	if !ok || !startShift.Covers(start) {
+		return false
+	}
+
+	last := end.Add(-time.Minute)
+	endShift, ok := shifts[int(last.Weekday())]
+	if !ok || !endShift.Covers(end) {
+		return false
+	}
+
+	return true
+}
+
+// StartEstimateScheduler refreshes work order completion estimates every
+// interval until the context is done
+func StartEstimateScheduler(ctx context.Context, scheduleService ScheduleService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := scheduleService.RecalculateEstimates(ctx); err != nil {
+				log.Printf("completion estimate refresh failed: %v", err)
+			}
+		}
+	}
+}
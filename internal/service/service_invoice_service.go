@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"pos-final/internal/repository"
+	"time"
+)
+
+type serviceInvoiceService struct {
+	serviceInvoiceRepo repository.ServiceInvoiceRepository
+	paymentRepo        repository.ServiceInvoicePaymentRepository
+	workOrderRepo      repository.WorkOrderRepository
+	workOrderPartRepo  repository.WorkOrderPartRepository
+}
+
+// NewServiceInvoiceService creates a new service invoice service
+func NewServiceInvoiceService(
+	serviceInvoiceRepo repository.ServiceInvoiceRepository,
+	paymentRepo repository.ServiceInvoicePaymentRepository,
+	workOrderRepo repository.WorkOrderRepository,
+	workOrderPartRepo repository.WorkOrderPartRepository,
+) ServiceInvoiceService {
+	return &serviceInvoiceService{
+		serviceInvoiceRepo: serviceInvoiceRepo,
+		paymentRepo:        paymentRepo,
+		workOrderRepo:      workOrderRepo,
+		workOrderPartRepo:  workOrderPartRepo,
+	}
+}
+
+// CreateInvoice bills a completed customer service work order. Parts are
+// charged at the selling price recorded when they were used, net of returns.
+// Labor defaults to the price agreed on the work order. Without a due date
+// the invoice is due on the day it is issued.
+func (s *serviceInvoiceService) CreateInvoice(ctx context.Context, workOrderID int, laborAmount *float64, discountAmount float64, dueDate *time.Time, notes *string, createdBy int) (*domain.ServiceInvoice, error) {
+	workOrder, err := s.workOrderRepo.GetByID(ctx, workOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get work order: %w", err)
+	}
+	if !workOrder.IsCustomerService() {
+		return nil, fmt.Errorf("only customer service work orders can be invoiced")
+	}
+	if workOrder.Status != domain.WorkOrderStatusCompleted {
+		return nil, fmt.Errorf("work order must be completed before invoicing")
+	}
+	if workOrder.CustomerID == nil {
+		return nil, fmt.Errorf("work order has no customer")
+	}
+
+	existing, err := s.serviceInvoiceRepo.GetByWorkOrderID(ctx, workOrderID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("work order is already invoiced as %s", existing.InvoiceNumber)
+	}
+
+	parts, err := s.workOrderPartRepo.ListByWorkOrderID(ctx, workOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get work order parts: %w", err)
+	}
+
+	var items []*domain.ServiceInvoiceItem
+	var partsAmount float64
+	for _, part := range parts {
+		quantity := part.QuantityUsed - part.QuantityReturned
+		if quantity <= 0 {
+			continue
+		}
+
+		description := fmt.Sprintf("Spare part #%d", part.SparePartID)
+		if part.SparePart != nil {
+			description = part.SparePart.Name
+		}
+
+		sparePartID := part.SparePartID
+		totalPrice := roundCost(float64(quantity) * part.UnitPrice)
+		items = append(items, &domain.ServiceInvoiceItem{
+			ItemType:    domain.ServiceInvoiceItemTypePart,
+			SparePartID: &sparePartID,
+			Description: description,
+			Quantity:    quantity,
+			UnitPrice:   part.UnitPrice,
+			TotalPrice:  totalPrice,
+		})
+		partsAmount += totalPrice
+	}
+
+	labor := workOrder.LaborPrice
+	if laborAmount != nil {
+		labor = *laborAmount
+	}
+	if labor < 0 {
+		return nil, fmt.Errorf("labor amount cannot be negative")
+	}
+	if labor > 0 {
+		items = append(items, &domain.ServiceInvoiceItem{
+			ItemType:    domain.ServiceInvoiceItemTypeLabor,
+			Description: fmt.Sprintf("Service labor %s", workOrder.WONumber),
+			Quantity:    1,
+			UnitPrice:   labor,
+			TotalPrice:  labor,
+		})
+	}
+
+	if len(items) == 0 {
+		return nil, fmt.Errorf("work order has nothing to invoice")
+	}
+
+	subtotal := roundCost(partsAmount + labor)
+	if discountAmount < 0 || discountAmount > subtotal {
+		return nil, fmt.Errorf("discount must be between 0 and %.2f", subtotal)
+	}
+
+	invoiceDate := time.Now()
+	due := invoiceDate
+	if dueDate != nil {
+		if dueDate.Before(invoiceDate.Truncate(24 * time.Hour)) {
+			return nil, fmt.Errorf("due date cannot be before the invoice date")
+		}
+		due = *dueDate
+	}
+
+	invoiceNumber, err := s.serviceInvoiceRepo.GenerateInvoiceNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invoice number: %w", err)
+	}
+
+	invoice := &domain.ServiceInvoice{
+		InvoiceNumber:  invoiceNumber,
+		WorkOrderID:    workOrder.ID,
+		CustomerID:     *workOrder.CustomerID,
+		InvoiceDate:    invoiceDate,
+		DueDate:        due,
+		PartsAmount:    roundCost(partsAmount),
+		LaborAmount:    labor,
+		DiscountAmount: discountAmount,
+		TotalAmount:    roundCost(subtotal - discountAmount),
+		PaidAmount:     0,
+		Status:         domain.ServiceInvoiceStatusUnpaid,
+		Notes:          notes,
+		CreatedBy:      createdBy,
+		WONumber:       workOrder.WONumber,
+		Items:          items,
+	}
+
+	if err := s.serviceInvoiceRepo.Create(ctx, invoice); err != nil {
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+// GetInvoiceByID returns the invoice with its lines and payments
+func (s *serviceInvoiceService) GetInvoiceByID(ctx context.Context, id int) (*domain.ServiceInvoice, error) {
+	invoice, err := s.serviceInvoiceRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice == nil {
+		return nil, fmt.Errorf("service invoice not found")
+	}
+
+	invoice.Items, err = s.serviceInvoiceRepo.ListItems(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	invoice.Payments, err = s.paymentRepo.ListByInvoiceID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+func (s *serviceInvoiceService) ListInvoices(ctx context.Context, page, limit int) ([]*domain.ServiceInvoice, int, error) {
+	offset := (page - 1) * limit
+	invoices, err := s.serviceInvoiceRepo.List(ctx, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := s.serviceInvoiceRepo.Count(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return invoices, count, nil
+}
+
+func (s *serviceInvoiceService) ListInvoicesByStatus(ctx context.Context, status domain.ServiceInvoiceStatus, page, limit int) ([]*domain.ServiceInvoice, int, error) {
+	offset := (page - 1) * limit
+	invoices, err := s.serviceInvoiceRepo.ListByStatus(ctx, status, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := s.serviceInvoiceRepo.CountByStatus(ctx, status)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return invoices, count, nil
+}
+
+// RecordPayment takes a full or partial customer payment against an invoice
+func (s *serviceInvoiceService) RecordPayment(ctx context.Context, payment *domain.ServiceInvoicePayment) error {
+	if payment.Amount <= 0 {
+		return fmt.Errorf("payment amount must be greater than 0")
+	}
+
+	invoice, err := s.serviceInvoiceRepo.GetByID(ctx, payment.ServiceInvoiceID)
+	if err != nil {
+		return fmt.Errorf("failed to get service invoice: %w", err)
+	}
+	if invoice == nil {
+		return fmt.Errorf("service invoice not found")
+	}
+	if invoice.Status == domain.ServiceInvoiceStatusPaid {
+		return fmt.Errorf("service invoice is already paid")
+	}
+	if payment.Amount > invoice.OutstandingAmount() {
+		return fmt.Errorf("payment %.2f exceeds outstanding balance %.2f", payment.Amount, invoice.OutstandingAmount())
+	}
+
+	if payment.PaymentDate.IsZero() {
+		payment.PaymentDate = time.Now()
+	}
+	if payment.PaymentMethod == "" {
+		payment.PaymentMethod = domain.PaymentMethodCash
+	}
+
+	paymentNumber, err := s.paymentRepo.GeneratePaymentNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to generate payment number: %w", err)
+	}
+	payment.PaymentNumber = paymentNumber
+
+	if err := s.paymentRepo.Create(ctx, payment); err != nil {
+		return fmt.Errorf("failed to record payment: %w", err)
+	}
+
+	return nil
+}
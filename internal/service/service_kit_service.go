@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"pos-final/internal/repository"
+	"strings"
+)
+
+type serviceKitService struct {
+	serviceKitRepo repository.ServiceKitRepository
+	sparePartRepo  repository.SparePartRepository
+}
+
+// NewServiceKitService creates a new service kit service
+func NewServiceKitService(
+	serviceKitRepo repository.ServiceKitRepository,
+	sparePartRepo repository.SparePartRepository,
+) ServiceKitService {
+	return &serviceKitService{
+		serviceKitRepo: serviceKitRepo,
+		sparePartRepo:  sparePartRepo,
+	}
+}
+
+func (s *serviceKitService) CreateKit(ctx context.Context, kit *domain.ServiceKit) error {
+	if err := s.validateKit(ctx, kit); err != nil {
+		return err
+	}
+
+	kit.KitCode = strings.TrimSpace(kit.KitCode)
+	if kit.KitCode == "" {
+		kitCode, err := s.serviceKitRepo.GenerateKitCode(ctx)
+		if err != nil {
+			return err
+		}
+		kit.KitCode = kitCode
+	} else {
+		existing, err := s.serviceKitRepo.GetByCode(ctx, kit.KitCode)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return fmt.Errorf("kit code already exists")
+		}
+	}
+
+	return s.serviceKitRepo.Create(ctx, kit)
+}
+
+func (s *serviceKitService) GetKit(ctx context.Context, id int) (*domain.ServiceKit, error) {
+	kit, err := s.serviceKitRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if kit == nil {
+		return nil, fmt.Errorf("service kit not found")
+	}
+
+	kit.Items, err = s.serviceKitRepo.ListItems(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return kit, nil
+}
+
+func (s *serviceKitService) ListKits(ctx context.Context, activeOnly bool, page, limit int) ([]*domain.ServiceKit, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	kits, err := s.serviceKitRepo.List(ctx, activeOnly, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.serviceKitRepo.Count(ctx, activeOnly)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return kits, total, nil
+}
+
+// UpdateKit saves the kit details and replaces its items. The kit code
+// cannot change.
+func (s *serviceKitService) UpdateKit(ctx context.Context, kit *domain.ServiceKit) error {
+	existing, err := s.serviceKitRepo.GetByID(ctx, kit.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("service kit not found")
+	}
+
+	if err := s.validateKit(ctx, kit); err != nil {
+		return err
+	}
+
+	kit.KitCode = existing.KitCode
+	kit.CreatedBy = existing.CreatedBy
+	kit.CreatedAt = existing.CreatedAt
+
+	return s.serviceKitRepo.Update(ctx, kit)
+}
+
+func (s *serviceKitService) DeleteKit(ctx context.Context, id int, deletedBy int) error {
+	existing, err := s.serviceKitRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("service kit not found")
+	}
+
+	return s.serviceKitRepo.SoftDelete(ctx, id, deletedBy)
+}
+
+func (s *serviceKitService) validateKit(ctx context.Context, kit *domain.ServiceKit) error {
+	if strings.TrimSpace(kit.Name) == "" {
+		return fmt.Errorf("kit name is required")
+	}
+	if kit.LaborHours < 0 {
+		return fmt.Errorf("labor hours cannot be negative")
+	}
+	if kit.LaborPrice < 0 {
+		return fmt.Errorf("labor price cannot be negative")
+	}
+	if len(kit.Items) == 0 {
+		return fmt.Errorf("kit must contain at least one part")
+	}
+
+	seen := make(map[int]bool)
+	for _, item := range kit.Items {
+		if item.Quantity <= 0 {
+			return fmt.Errorf("quantity must be greater than 0")
+		}
+		if seen[item.SparePartID] {
+			return fmt.Errorf("spare part %d is listed more than once", item.SparePartID)
+		}
+		seen[item.SparePartID] = true
+
+		if _, err := s.sparePartRepo.GetByID(ctx, item.SparePartID); err != nil {
+			return fmt.Errorf("spare part %d not found", item.SparePartID)
+		}
+	}
+
+	return nil
+}
@@ -6,20 +6,39 @@ import (
 	"pos-final/internal/domain"
 	"pos-final/internal/repository"
 	"strings"
+	"time"
 )
 
 type sparePartService struct {
-	sparePartRepo repository.SparePartRepository
+	sparePartRepo      repository.SparePartRepository
+	stockCostLayerRepo repository.StockCostLayerRepository
+	stockLocationRepo  repository.StockLocationRepository
+	costingMethod      domain.CostingMethod
+	barcodeSettings    domain.BarcodeSettings
 }
 
 // NewSparePartService creates a new spare part service
-func NewSparePartService(sparePartRepo repository.SparePartRepository) SparePartService {
+func NewSparePartService(
+	sparePartRepo repository.SparePartRepository,
+	stockCostLayerRepo repository.StockCostLayerRepository,
+	stockLocationRepo repository.StockLocationRepository,
+	costingMethod domain.CostingMethod,
+	barcodeSettings domain.BarcodeSettings,
+) SparePartService {
+	if barcodeSettings.Symbology == "" {
+		barcodeSettings.Symbology = domain.BarcodeSymbologyEAN13
+	}
+
 	return &sparePartService{
-		sparePartRepo: sparePartRepo,
+		sparePartRepo:      sparePartRepo,
+		stockCostLayerRepo: stockCostLayerRepo,
+		stockLocationRepo:  stockLocationRepo,
+		costingMethod:      costingMethod,
+		barcodeSettings:    barcodeSettings,
 	}
 }
 
-func (s *sparePartService) CreateSparePart(ctx context.Context, sparePart *domain.SparePart) error {
+func (s *sparePartService) CreateSparePart(ctx context.Context, sparePart *domain.SparePart, createdBy int) error {
 	// Validate required fields
 	if err := s.validateSparePart(sparePart); err != nil {
 		return err
@@ -62,11 +81,84 @@ func (s *sparePartService) CreateSparePart(ctx context.Context, sparePart *domai
 		sparePart.MinStockLevel = 5 // Default minimum stock level
 	}
 
-	// Create spare part
-	if err := s.sparePartRepo.Create(ctx, sparePart); err != nil {
+	// Create spare part with its opening stock
+	if err := s.sparePartRepo.Create(ctx, sparePart, createdBy); err != nil {
 		return fmt.Errorf("failed to create spare part: %w", err)
 	}
 
+	// EAN-13 codes are derived from the ID, so they are assigned after insert
+	if s.barcodeSettings.AutoAssign && (sparePart.Barcode == nil || *sparePart.Barcode == "") {
+		if err := s.assignBarcode(ctx, sparePart, s.barcodeSettings.Symbology); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AssignBarcode generates a barcode for a part. A part that already has one
+// keeps it unless replace is set.
+func (s *sparePartService) AssignBarcode(ctx context.Context, id int, symbology domain.BarcodeSymbology, replace bool) (*domain.SparePart, error) {
+	sparePart, err := s.GetSparePartByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if sparePart.Barcode != nil && *sparePart.Barcode != "" && !replace {
+		return nil, fmt.Errorf("spare part already has barcode %s", *sparePart.Barcode)
+	}
+
+	if symbology == "" {
+		symbology = s.barcodeSettings.Symbology
+	}
+
+	if err := s.assignBarcode(ctx, sparePart, symbology); err != nil {
+		return nil, err
+	}
+
+	return sparePart, nil
+}
+
+// AssignMissingBarcodes gives every part without a barcode one and returns
+// the parts that were updated
+func (s *sparePartService) AssignMissingBarcodes(ctx context.Context, symbology domain.BarcodeSymbology) ([]*domain.SparePart, error) {
+	if symbology == "" {
+		symbology = s.barcodeSettings.Symbology
+	}
+
+	spareParts, err := s.sparePartRepo.ListWithoutBarcode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sparePart := range spareParts {
+		if err := s.assignBarcode(ctx, sparePart, symbology); err != nil {
+			return nil, fmt.Errorf("failed to assign barcode to %s: %w", sparePart.PartCode, err)
+		}
+	}
+
+	return spareParts, nil
+}
+
+func (s *sparePartService) assignBarcode(ctx context.Context, sparePart *domain.SparePart, symbology domain.BarcodeSymbology) error {
+	barcode, err := generateBarcode(symbology, s.barcodeSettings.EAN13Prefix, sparePart)
+	if err != nil {
+		return err
+	}
+
+	exists, err := s.sparePartRepo.BarcodeExists(ctx, barcode, sparePart.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("barcode %s is already used by another spare part", barcode)
+	}
+
+	if err := s.sparePartRepo.UpdateBarcode(ctx, sparePart.ID, barcode); err != nil {
+		return err
+	}
+
+	sparePart.Barcode = &barcode
 	return nil
 }
 
@@ -224,6 +316,9 @@ func (s *sparePartService) UpdateSparePart(ctx context.Context, sparePart *domai
 		}
 	}
 
+	// Cost price follows receipts and cost layers, not manual edits
+	sparePart.CostPrice = existing.CostPrice
+
 	// Update spare part
 	if err := s.sparePartRepo.Update(ctx, sparePart); err != nil {
 		return fmt.Errorf("failed to update spare part: %w", err)
@@ -260,7 +355,7 @@ func (s *sparePartService) DeleteSparePart(ctx context.Context, id int, deletedB
 	return nil
 }
 
-func (s *sparePartService) AdjustStock(ctx context.Context, partID int, adjustment int, notes string, adjustedBy int) error {
+func (s *sparePartService) AdjustStock(ctx context.Context, partID int, locationID *int, quantity int, serialNumbers []string, notes string, adjustedBy int) error {
 	if partID <= 0 {
 		return fmt.Errorf("invalid spare part ID")
 	}
@@ -269,27 +364,44 @@ func (s *sparePartService) AdjustStock(ctx context.Context, partID int, adjustme
 		return fmt.Errorf("invalid adjusted by user ID")
 	}
 
-	// Check if spare part exists
-	existing, err := s.sparePartRepo.GetByID(ctx, partID)
+	sparePart, err := s.sparePartRepo.GetByID(ctx, partID)
 	if err != nil {
-		return fmt.Errorf("failed to check existing spare part: %w", err)
+		return fmt.Errorf("failed to get spare part: %w", err)
 	}
-	if existing == nil {
+	if sparePart == nil {
 		return fmt.Errorf("spare part not found")
 	}
 
-	// Check if adjustment would result in negative stock
-	newStock := existing.StockQuantity + adjustment
-	if newStock < 0 {
-		return fmt.Errorf("adjustment would result in negative stock (current: %d, adjustment: %d)", existing.StockQuantity, adjustment)
+	// Serial-tracked parts list the units added or written off
+	units := quantity
+	if units < 0 {
+		units = -units
+	}
+	serialNumbers, err = normalizeSerialNumbers(sparePart, units, serialNumbers)
+	if err != nil {
+		return err
 	}
 
-	// Adjust stock
-	if err := s.sparePartRepo.AdjustStock(ctx, partID, adjustment); err != nil {
-		return fmt.Errorf("failed to adjust stock: %w", err)
+	location, err := resolveStockLocation(ctx, s.stockLocationRepo, locationID)
+	if err != nil {
+		return err
 	}
 
-	// TODO: Create stock movement record for audit trail
+	// Stock, batches, cost layers and the stock movement are posted together;
+	// the location cannot go below zero
+	adjustment := &domain.StockAdjustment{
+		SparePartID:   partID,
+		LocationID:    &location.ID,
+		Quantity:      quantity,
+		SerialNumbers: serialNumbers,
+		Notes:         notes,
+		AdjustedBy:    adjustedBy,
+		AdjustedAt:    time.Now(),
+	}
+
+	if err := s.sparePartRepo.AdjustStock(ctx, adjustment, s.costingMethod); err != nil {
+		return fmt.Errorf("failed to adjust stock: %w", err)
+	}
 
 	return nil
 }
@@ -304,6 +416,32 @@ func (s *sparePartService) CheckLowStock(ctx context.Context) ([]*domain.SparePa
 	return spareParts, nil
 }
 
+// GetCostLayers returns the cost layers behind a spare part's stock value
+func (s *sparePartService) GetCostLayers(ctx context.Context, partID int, includeClosed bool) (map[string]interface{}, error) {
+	sparePart, err := s.GetSparePartByID(ctx, partID)
+	if err != nil {
+		return nil, err
+	}
+
+	layers, err := s.stockCostLayerRepo.ListBySparePartID(ctx, partID, !includeClosed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cost layers: %w", err)
+	}
+
+	layerQuantity, layerValue, err := s.stockCostLayerRepo.GetValuation(ctx, partID)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"spare_part":     sparePart,
+		"costing_method": s.costingMethod,
+		"layers":         layers,
+		"layer_quantity": layerQuantity,
+		"layer_value":    layerValue,
+	}, nil
+}
+
 func (s *sparePartService) validateSparePart(sparePart *domain.SparePart) error {
 	if sparePart == nil {
 		return fmt.Errorf("spare part is required")
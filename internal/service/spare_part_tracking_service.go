@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"pos-final/internal/domain"
+	"pos-final/internal/repository"
+	"strings"
+	"time"
+)
+
+type sparePartTrackingService struct {
+	sparePartRepo       repository.SparePartRepository
+	batchRepo           repository.SparePartBatchRepository
+	serialRepo          repository.SparePartSerialRepository
+	notificationService NotificationService
+	expiryAlertDays     int
+}
+
+// NewSparePartTrackingService creates a new spare part batch and serial
+// tracking service
+func NewSparePartTrackingService(
+	sparePartRepo repository.SparePartRepository,
+	batchRepo repository.SparePartBatchRepository,
+	serialRepo repository.SparePartSerialRepository,
+	notificationService NotificationService,
+	expiryAlertDays int,
+) SparePartTrackingService {
+	if expiryAlertDays <= 0 {
+		expiryAlertDays = 30
+	}
+
+	return &sparePartTrackingService{
+		sparePartRepo:       sparePartRepo,
+		batchRepo:           batchRepo,
+		serialRepo:          serialRepo,
+		notificationService: notificationService,
+		expiryAlertDays:     expiryAlertDays,
+	}
+}
+
+// UpdateTracking switches batch and serial tracking of a part
+func (s *sparePartTrackingService) UpdateTracking(ctx context.Context, sparePartID int, trackBatches, trackSerials bool) (*domain.SparePart, error) {
+	if _, err := s.sparePartRepo.GetByID(ctx, sparePartID); err != nil {
+		return nil, fmt.Errorf("spare part not found")
+	}
+
+	if err := s.sparePartRepo.UpdateTracking(ctx, sparePartID, trackBatches, trackSerials); err != nil {
+		return nil, err
+	}
+
+	return s.sparePartRepo.GetByID(ctx, sparePartID)
+}
+
+// ListBatches lists a part's batches in the order they are picked
+func (s *sparePartTrackingService) ListBatches(ctx context.Context, sparePartID int, locationID *int, openOnly bool) ([]*domain.SparePartBatch, error) {
+	if _, err := s.sparePartRepo.GetByID(ctx, sparePartID); err != nil {
+		return nil, fmt.Errorf("spare part not found")
+	}
+
+	return s.batchRepo.ListBySparePart(ctx, sparePartID, locationID, openOnly)
+}
+
+// ListExpiringBatches lists open batches that have expired or expire within
+// the given number of days (the configured alert window when 0)
+func (s *sparePartTrackingService) ListExpiringBatches(ctx context.Context, days int, locationID *int) ([]*domain.SparePartBatch, error) {
+	if days <= 0 {
+		days = s.expiryAlertDays
+	}
+
+	now := time.Now()
+	before := time.Date(now.Year(), now.Month(), now.Day()+days+1, 0, 0, 0, 0, now.Location())
+
+	return s.batchRepo.ListExpiring(ctx, before, locationID)
+}
+
+func (s *sparePartTrackingService) ListSerials(ctx context.Context, sparePartID int, status *domain.SerialStatus, page, limit int) ([]*domain.SparePartSerial, int, error) {
+	if status != nil && *status != domain.SerialStatusInStock && *status != domain.SerialStatusIssued && *status != domain.SerialStatusWrittenOff {
+		return nil, 0, fmt.Errorf("invalid serial status: %s", *status)
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	serials, err := s.serialRepo.ListBySparePart(ctx, sparePartID, status, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.serialRepo.CountBySparePart(ctx, sparePartID, status)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return serials, total, nil
+}
+
+// FindSerial looks a serial number up across parts, with the work order and
+// vehicle it went into
+func (s *sparePartTrackingService) FindSerial(ctx context.Context, serialNumber string) ([]*domain.SparePartSerial, error) {
+	serialNumber = strings.TrimSpace(serialNumber)
+	if serialNumber == "" {
+		return nil, fmt.Errorf("serial number is required")
+	}
+
+	serials, err := s.serialRepo.ListBySerialNumber(ctx, serialNumber)
+	if err != nil {
+		return nil, err
+	}
+	if len(serials) == 0 {
+		return nil, fmt.Errorf("serial number not found")
+	}
+
+	return serials, nil
+}
+
+// RegisterSerials records the serial numbers of units already in stock at a
+// location (the default location when nil)
+func (s *sparePartTrackingService) RegisterSerials(ctx context.Context, sparePartID int, locationID *int, serialNumbers []string) error {
+	sparePart, err := s.sparePartRepo.GetByID(ctx, sparePartID)
+	if err != nil {
+		return fmt.Errorf("spare part not found")
+	}
+	if !sparePart.TrackSerials {
+		return fmt.Errorf("%s does not track serial numbers", sparePart.PartCode)
+	}
+	if len(serialNumbers) == 0 {
+		return fmt.Errorf("at least one serial number is required")
+	}
+
+	serialNumbers, err = normalizeSerialNumbers(sparePart, len(serialNumbers), serialNumbers)
+	if err != nil {
+		return err
+	}
+
+	return s.serialRepo.Register(ctx, sparePartID, locationID, serialNumbers, time.Now())
+}
+
+// NotifyExpiringStock notifies admins and kasir when any batch has expired or
+// expires within the alert window, and returns the number of such batches
+func (s *sparePartTrackingService) NotifyExpiringStock(ctx context.Context) (int, error) {
+	batches, err := s.ListExpiringBatches(ctx, s.expiryAlertDays, nil)
+	if err != nil {
+		return 0, err
+	}
+	if len(batches) == 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+	expired := 0
+	for _, batch := range batches {
+		if batch.IsExpired(now) {
+			expired++
+		}
+	}
+
+	if err := s.notificationService.NotifyExpiringStock(ctx, expired, len(batches)-expired, s.expiryAlertDays); err != nil {
+		return 0, err
+	}
+
+	return len(batches), nil
+}
+
+// StartExpiryAlertScheduler notifies expiring stock every interval until the
+// context is done
+func StartExpiryAlertScheduler(ctx context.Context, trackingService SparePartTrackingService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count, err := trackingService.NotifyExpiringStock(ctx)
+			if err != nil {
+				log.Printf("expiring stock alert failed: %v", err)
+				continue
+			}
+			log.Printf("expiring stock alert found %d batches", count)
+		}
+	}
+}
+
+// normalizeSerialNumbers trims the serial numbers given for quantity units of
+// a part. Serial-tracked parts need one distinct serial number per unit,
+// other parts none.
+func normalizeSerialNumbers(sparePart *domain.SparePart, quantity int, serialNumbers []string) ([]string, error) {
+	if !sparePart.TrackSerials {
+		if len(serialNumbers) > 0 {
+			return nil, fmt.Errorf("%s does not track serial numbers", sparePart.PartCode)
+		}
+		return nil, nil
+	}
+
+	if len(serialNumbers) != quantity {
+		return nil, fmt.Errorf("%s needs %d serial numbers, got %d", sparePart.PartCode, quantity, len(serialNumbers))
+	}
+
+	seen := make(map[string]bool, len(serialNumbers))
+	normalized := make([]string, 0, len(serialNumbers))
+	for _, serialNumber := range serialNumbers {
+		serialNumber = strings.TrimSpace(serialNumber)
+		if serialNumber == "" {
+			return nil, fmt.Errorf("serial numbers cannot be empty")
+		}
+		if seen[serialNumber] {
+			return nil, fmt.Errorf("serial number %s is listed more than once", serialNumber)
+		}
+		seen[serialNumber] = true
+		normalized = append(normalized, serialNumber)
+	}
+
+	return normalized, nil
+}
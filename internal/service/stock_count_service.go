@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"pos-final/internal/repository"
+	"strings"
+	"time"
+)
+
+// Item filters for reviewing a count
+const (
+	stockCountFilterCounted   = "counted"
+	stockCountFilterUncounted = "uncounted"
+	stockCountFilterVariance  = "variance"
+)
+
+type stockCountService struct {
+	stockCountRepo    repository.StockCountRepository
+	stockLocationRepo repository.StockLocationRepository
+	costingMethod     domain.CostingMethod
+}
+
+// NewStockCountService creates a new stock count service
+func NewStockCountService(
+	stockCountRepo repository.StockCountRepository,
+	stockLocationRepo repository.StockLocationRepository,
+	costingMethod domain.CostingMethod,
+) StockCountService {
+	return &stockCountService{
+		stockCountRepo:    stockCountRepo,
+		stockLocationRepo: stockLocationRepo,
+		costingMethod:     costingMethod,
+	}
+}
+
+// CreateCount opens a session and snapshots expected stock for the parts in
+// scope at the count's location (default location when zero): the given
+// parts, else the count's category, else every part stocked there
+func (s *stockCountService) CreateCount(ctx context.Context, count *domain.StockCount, sparePartIDs []int) error {
+	if count.CreatedBy <= 0 {
+		return fmt.Errorf("invalid created by user ID")
+	}
+
+	var locationID *int
+	if count.LocationID != 0 {
+		locationID = &count.LocationID
+	}
+	location, err := resolveStockLocation(ctx, s.stockLocationRepo, locationID)
+	if err != nil {
+		return err
+	}
+	count.LocationID = location.ID
+
+	countNumber, err := s.stockCountRepo.GenerateCountNumber(ctx)
+	if err != nil {
+		return err
+	}
+
+	count.CountNumber = countNumber
+	count.Status = domain.StockCountStatusOpen
+
+	if err := s.stockCountRepo.Create(ctx, count, sparePartIDs); err != nil {
+		return err
+	}
+
+	created, err := s.GetCountByID(ctx, count.ID)
+	if err != nil {
+		return err
+	}
+	*count = *created
+
+	return nil
+}
+
+func (s *stockCountService) GetCountByID(ctx context.Context, id int) (*domain.StockCount, error) {
+	count, err := s.stockCountRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if count == nil {
+		return nil, fmt.Errorf("stock count not found")
+	}
+
+	return count, nil
+}
+
+func (s *stockCountService) ListCounts(ctx context.Context, status domain.StockCountStatus, page, limit int) ([]*domain.StockCount, int, error) {
+	offset := (page - 1) * limit
+
+	counts, err := s.stockCountRepo.List(ctx, status, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.stockCountRepo.Count(ctx, status)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return counts, total, nil
+}
+
+// ListItems returns the count sheet. The filter narrows it to counted or
+// uncounted lines, or to lines whose count differs from the snapshot.
+func (s *stockCountService) ListItems(ctx context.Context, id int, filter string) ([]*domain.StockCountItem, error) {
+	if _, err := s.GetCountByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	items, err := s.stockCountRepo.ListItems(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter == "" {
+		return items, nil
+	}
+
+	filtered := make([]*domain.StockCountItem, 0, len(items))
+	for _, item := range items {
+		switch filter {
+		case stockCountFilterCounted:
+			if item.CountedQuantity == nil {
+				continue
+			}
+		case stockCountFilterUncounted:
+			if item.CountedQuantity != nil {
+				continue
+			}
+		case stockCountFilterVariance:
+			if item.VarianceQuantity == 0 {
+				continue
+			}
+		default:
+			return nil, fmt.Errorf("invalid filter %q (use counted, uncounted or variance)", filter)
+		}
+		filtered = append(filtered, item)
+	}
+
+	return filtered, nil
+}
+
+// ScanItem adds a scanned quantity to the line for a barcode or part code.
+// A negative quantity takes back a mis-scan.
+func (s *stockCountService) ScanItem(ctx context.Context, id int, code string, quantity int, countedBy int) (*domain.StockCountItem, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return nil, fmt.Errorf("barcode is required")
+	}
+	if quantity == 0 {
+		return nil, fmt.Errorf("quantity must not be 0")
+	}
+
+	if _, err := s.getOpenCount(ctx, id); err != nil {
+		return nil, err
+	}
+
+	item, err := s.stockCountRepo.GetItemByCode(ctx, id, code)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, fmt.Errorf("no spare part with barcode %s in this count", code)
+	}
+
+	counted := 0
+	if item.CountedQuantity != nil {
+		counted = *item.CountedQuantity
+	}
+	if counted+quantity < 0 {
+		return nil, fmt.Errorf("counted quantity cannot go below 0")
+	}
+
+	if err := s.stockCountRepo.AddCount(ctx, item.ID, quantity, countedBy); err != nil {
+		return nil, err
+	}
+
+	return s.stockCountRepo.GetItemByID(ctx, item.ID)
+}
+
+// SetItemCount overwrites a line's count, e.g. after a recount. A nil
+// quantity clears it back to not counted.
+func (s *stockCountService) SetItemCount(ctx context.Context, id int, itemID int, quantity *int, countedBy int, notes *string) (*domain.StockCountItem, error) {
+	if quantity != nil && *quantity < 0 {
+		return nil, fmt.Errorf("counted quantity cannot be negative")
+	}
+
+	if _, err := s.getOpenCount(ctx, id); err != nil {
+		return nil, err
+	}
+
+	item, err := s.stockCountRepo.GetItemByID(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil || item.StockCountID != id {
+		return nil, fmt.Errorf("stock count item not found")
+	}
+
+	if err := s.stockCountRepo.SetCount(ctx, itemID, quantity, countedBy, notes); err != nil {
+		return nil, err
+	}
+
+	return s.stockCountRepo.GetItemByID(ctx, itemID)
+}
+
+// SubmitCount closes counting and sends the variances for review. Lines
+// left uncounted are not adjusted.
+func (s *stockCountService) SubmitCount(ctx context.Context, id int, submittedBy int) (*domain.StockCount, error) {
+	count, err := s.getOpenCount(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if count.CountedItems == 0 {
+		return nil, fmt.Errorf("nothing has been counted yet")
+	}
+
+	if err := s.stockCountRepo.UpdateStatus(ctx, id, domain.StockCountStatusOpen, domain.StockCountStatusSubmitted, submittedBy); err != nil {
+		return nil, err
+	}
+
+	return s.GetCountByID(ctx, id)
+}
+
+// ReopenCount sends a submitted count back for recounting
+func (s *stockCountService) ReopenCount(ctx context.Context, id int, userID int) (*domain.StockCount, error) {
+	if err := s.stockCountRepo.UpdateStatus(ctx, id, domain.StockCountStatusSubmitted, domain.StockCountStatusOpen, userID); err != nil {
+		return nil, err
+	}
+
+	return s.GetCountByID(ctx, id)
+}
+
+// ApproveCount posts the reviewed variances to stock. The session cannot be
+// changed afterwards.
+func (s *stockCountService) ApproveCount(ctx context.Context, id int, approvedBy int) (*domain.StockCount, error) {
+	count, err := s.GetCountByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if count.Status != domain.StockCountStatusSubmitted {
+		return nil, fmt.Errorf("only submitted stock counts can be approved (status: %s)", count.Status)
+	}
+
+	now := time.Now()
+	count.ApprovedBy = &approvedBy
+	count.ApprovedAt = &now
+
+	if err := s.stockCountRepo.Approve(ctx, count, s.costingMethod); err != nil {
+		return nil, err
+	}
+
+	return s.GetCountByID(ctx, id)
+}
+
+// CancelCount abandons an open or submitted count without touching stock
+func (s *stockCountService) CancelCount(ctx context.Context, id int, userID int) (*domain.StockCount, error) {
+	count, err := s.GetCountByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if count.Status != domain.StockCountStatusOpen && count.Status != domain.StockCountStatusSubmitted {
+		return nil, fmt.Errorf("cannot cancel a stock count with status %s", count.Status)
+	}
+
+	if err := s.stockCountRepo.UpdateStatus(ctx, id, count.Status, domain.StockCountStatusCancelled, userID); err != nil {
+		return nil, err
+	}
+
+	return s.GetCountByID(ctx, id)
+}
+
+func (s *stockCountService) getOpenCount(ctx context.Context, id int) (*domain.StockCount, error) {
+	count, err := s.GetCountByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if count.Status != domain.StockCountStatusOpen {
+		return nil, fmt.Errorf("stock count is %s and can no longer be counted", count.Status)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"pos-final/internal/repository"
+	"strings"
+)
+
+type stockLocationService struct {
+	stockLocationRepo repository.StockLocationRepository
+	sparePartRepo     repository.SparePartRepository
+}
+
+// NewStockLocationService creates a new stock location service
+func NewStockLocationService(
+	stockLocationRepo repository.StockLocationRepository,
+	sparePartRepo repository.SparePartRepository,
+) StockLocationService {
+	return &stockLocationService{
+		stockLocationRepo: stockLocationRepo,
+		sparePartRepo:     sparePartRepo,
+	}
+}
+
+func (s *stockLocationService) CreateLocation(ctx context.Context, location *domain.StockLocation) error {
+	if err := s.validateLocation(location); err != nil {
+		return err
+	}
+
+	return s.stockLocationRepo.Create(ctx, location)
+}
+
+func (s *stockLocationService) GetLocationByID(ctx context.Context, id int) (*domain.StockLocation, error) {
+	location, err := s.stockLocationRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if location == nil {
+		return nil, fmt.Errorf("stock location not found")
+	}
+
+	return location, nil
+}
+
+func (s *stockLocationService) ListLocations(ctx context.Context, includeInactive bool) ([]*domain.StockLocation, error) {
+	return s.stockLocationRepo.List(ctx, includeInactive)
+}
+
+func (s *stockLocationService) UpdateLocation(ctx context.Context, location *domain.StockLocation) error {
+	existing, err := s.GetLocationByID(ctx, location.ID)
+	if err != nil {
+		return err
+	}
+
+	if existing.LocationType == domain.StockLocationTypeTransit {
+		return fmt.Errorf("the transit location cannot be edited")
+	}
+
+	if err := s.validateLocation(location); err != nil {
+		return err
+	}
+
+	if existing.IsDefault && !location.IsActive {
+		return fmt.Errorf("the default location cannot be deactivated")
+	}
+
+	return s.stockLocationRepo.Update(ctx, location)
+}
+
+// SetDefaultLocation picks the location used when a stock transaction does
+// not name one
+func (s *stockLocationService) SetDefaultLocation(ctx context.Context, id int) error {
+	location, err := s.GetLocationByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if location.LocationType == domain.StockLocationTypeTransit {
+		return fmt.Errorf("the transit location cannot be the default")
+	}
+	if !location.IsActive {
+		return fmt.Errorf("an inactive location cannot be the default")
+	}
+
+	return s.stockLocationRepo.SetDefault(ctx, id)
+}
+
+// DeleteLocation removes an empty location
+func (s *stockLocationService) DeleteLocation(ctx context.Context, id int, deletedBy int) error {
+	location, err := s.GetLocationByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if location.IsDefault {
+		return fmt.Errorf("the default location cannot be deleted")
+	}
+	if location.LocationType == domain.StockLocationTypeTransit {
+		return fmt.Errorf("the transit location cannot be deleted")
+	}
+
+	quantity, err := s.stockLocationRepo.SumQuantity(ctx, id)
+	if err != nil {
+		return err
+	}
+	if quantity > 0 {
+		return fmt.Errorf("location still holds %d units of stock; transfer it out first", quantity)
+	}
+
+	return s.stockLocationRepo.SoftDelete(ctx, id, deletedBy)
+}
+
+func (s *stockLocationService) GetLocationStock(ctx context.Context, id int) ([]*domain.SparePartStock, error) {
+	if _, err := s.GetLocationByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.stockLocationRepo.ListStockByLocation(ctx, id)
+}
+
+func (s *stockLocationService) GetSparePartStock(ctx context.Context, sparePartID int) ([]*domain.SparePartStock, error) {
+	if _, err := s.sparePartRepo.GetByID(ctx, sparePartID); err != nil {
+		return nil, fmt.Errorf("spare part not found: %w", err)
+	}
+
+	return s.stockLocationRepo.ListStockBySparePart(ctx, sparePartID)
+}
+
+// UpdateStockSettings sets where a part is shelved at a location and the
+// minimum that location should hold
+func (s *stockLocationService) UpdateStockSettings(ctx context.Context, stock *domain.SparePartStock) (*domain.SparePartStock, error) {
+	if stock.MinStockLevel != nil && *stock.MinStockLevel < 0 {
+		return nil, fmt.Errorf("minimum stock level cannot be negative")
+	}
+
+	if _, err := s.sparePartRepo.GetByID(ctx, stock.SparePartID); err != nil {
+		return nil, fmt.Errorf("spare part not found: %w", err)
+	}
+
+	if _, err := resolveStockLocation(ctx, s.stockLocationRepo, &stock.LocationID); err != nil {
+		return nil, err
+	}
+
+	if err := s.stockLocationRepo.UpdateStockSettings(ctx, stock); err != nil {
+		return nil, err
+	}
+
+	return s.stockLocationRepo.GetStock(ctx, stock.SparePartID, stock.LocationID)
+}
+
+// ListLowStock applies each location's own minimum. A nil location checks
+// every location.
+func (s *stockLocationService) ListLowStock(ctx context.Context, locationID *int) ([]*domain.SparePartStock, error) {
+	return s.stockLocationRepo.ListLowStock(ctx, locationID)
+}
+
+func (s *stockLocationService) validateLocation(location *domain.StockLocation) error {
+	location.Code = strings.ToUpper(strings.TrimSpace(location.Code))
+	location.Name = strings.TrimSpace(location.Name)
+
+	if location.Code == "" {
+		return fmt.Errorf("location code is required")
+	}
+	if location.Name == "" {
+		return fmt.Errorf("location name is required")
+	}
+
+	switch location.LocationType {
+	case "":
+		location.LocationType = domain.StockLocationTypeWarehouse
+	case domain.StockLocationTypeWarehouse, domain.StockLocationTypeWorkshop, domain.StockLocationTypeBranch:
+	case domain.StockLocationTypeTransit:
+		return fmt.Errorf("there is only one transit location")
+	default:
+		return fmt.Errorf("invalid location type: %s", location.LocationType)
+	}
+
+	return nil
+}
+
+// resolveStockLocation returns the location stock should move at: the given
+// one, or the default location when none is given. Stock only moves at
+// active, non-transit locations.
+func resolveStockLocation(ctx context.Context, stockLocationRepo repository.StockLocationRepository, locationID *int) (*domain.StockLocation, error) {
+	var location *domain.StockLocation
+	var err error
+
+	if locationID != nil {
+		location, err = stockLocationRepo.GetByID(ctx, *locationID)
+	} else {
+		location, err = stockLocationRepo.GetDefault(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if location == nil {
+		if locationID == nil {
+			return nil, fmt.Errorf("no default stock location configured")
+		}
+		return nil, fmt.Errorf("stock location not found")
+	}
+
+	if location.LocationType == domain.StockLocationTypeTransit {
+		return nil, fmt.Errorf("stock cannot be booked directly at the transit location")
+	}
+	if !location.IsActive {
+		return nil, fmt.Errorf("stock location %s is inactive", location.Code)
+	}
+
+	return location, nil
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"pos-final/internal/repository"
+	"time"
+)
+
+type stockTransferService struct {
+	stockTransferRepo repository.StockTransferRepository
+	stockLocationRepo repository.StockLocationRepository
+	sparePartRepo     repository.SparePartRepository
+}
+
+// NewStockTransferService creates a new stock transfer service
+func NewStockTransferService(
+	stockTransferRepo repository.StockTransferRepository,
+	stockLocationRepo repository.StockLocationRepository,
+	sparePartRepo repository.SparePartRepository,
+) StockTransferService {
+	return &stockTransferService{
+		stockTransferRepo: stockTransferRepo,
+		stockLocationRepo: stockLocationRepo,
+		sparePartRepo:     sparePartRepo,
+	}
+}
+
+// CreateTransfer saves a draft transfer. Lines for the same part are merged.
+func (s *stockTransferService) CreateTransfer(ctx context.Context, transfer *domain.StockTransfer) error {
+	if transfer.FromLocationID == transfer.ToLocationID {
+		return fmt.Errorf("source and destination must be different locations")
+	}
+	if len(transfer.Items) == 0 {
+		return fmt.Errorf("transfer must have at least one item")
+	}
+
+	if _, err := resolveStockLocation(ctx, s.stockLocationRepo, &transfer.FromLocationID); err != nil {
+		return fmt.Errorf("source: %w", err)
+	}
+	if _, err := resolveStockLocation(ctx, s.stockLocationRepo, &transfer.ToLocationID); err != nil {
+		return fmt.Errorf("destination: %w", err)
+	}
+
+	merged := make(map[int]*domain.StockTransferItem)
+	spareParts := make(map[int]*domain.SparePart)
+	var items []*domain.StockTransferItem
+	for _, item := range transfer.Items {
+		if item.Quantity <= 0 {
+			return fmt.Errorf("quantity must be greater than 0")
+		}
+
+		if existing, ok := merged[item.SparePartID]; ok {
+			existing.Quantity += item.Quantity
+			existing.SerialNumbers = append(existing.SerialNumbers, item.SerialNumbers...)
+			continue
+		}
+
+		sparePart, err := s.sparePartRepo.GetByID(ctx, item.SparePartID)
+		if err != nil {
+			return fmt.Errorf("spare part %d not found: %w", item.SparePartID, err)
+		}
+		if sparePart == nil {
+			return fmt.Errorf("spare part %d not found", item.SparePartID)
+		}
+
+		merged[item.SparePartID] = item
+		spareParts[item.SparePartID] = sparePart
+		items = append(items, item)
+	}
+
+	// Serial-tracked parts list the units that move
+	for _, item := range items {
+		serialNumbers, err := normalizeSerialNumbers(spareParts[item.SparePartID], item.Quantity, item.SerialNumbers)
+		if err != nil {
+			return err
+		}
+		item.SerialNumbers = serialNumbers
+	}
+
+	transferNumber, err := s.stockTransferRepo.GenerateTransferNumber(ctx)
+	if err != nil {
+		return err
+	}
+
+	transfer.TransferNumber = transferNumber
+	transfer.Status = domain.StockTransferStatusDraft
+	transfer.Items = items
+
+	return s.stockTransferRepo.Create(ctx, transfer)
+}
+
+func (s *stockTransferService) GetTransferByID(ctx context.Context, id int) (*domain.StockTransfer, error) {
+	transfer, err := s.stockTransferRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer == nil {
+		return nil, fmt.Errorf("stock transfer not found")
+	}
+
+	items, err := s.stockTransferRepo.ListItems(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	transfer.Items = items
+
+	return transfer, nil
+}
+
+func (s *stockTransferService) ListTransfers(ctx context.Context, status domain.StockTransferStatus, locationID *int, page, limit int) ([]*domain.StockTransfer, int, error) {
+	offset := (page - 1) * limit
+
+	transfers, err := s.stockTransferRepo.List(ctx, status, locationID, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.stockTransferRepo.Count(ctx, status, locationID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return transfers, total, nil
+}
+
+// ShipTransfer takes the stock out of the source location. It stays in
+// transit, still counted in the part totals, until the destination receives it.
+func (s *stockTransferService) ShipTransfer(ctx context.Context, id int, shippedBy int) (*domain.StockTransfer, error) {
+	transfer, err := s.GetTransferByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if transfer.Status != domain.StockTransferStatusDraft {
+		return nil, fmt.Errorf("only draft transfers can be shipped (status: %s)", transfer.Status)
+	}
+
+	if _, err := resolveStockLocation(ctx, s.stockLocationRepo, &transfer.FromLocationID); err != nil {
+		return nil, fmt.Errorf("source: %w", err)
+	}
+
+	now := time.Now()
+	transfer.ShippedBy = &shippedBy
+	transfer.ShippedAt = &now
+
+	if err := s.stockTransferRepo.Ship(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	return s.GetTransferByID(ctx, id)
+}
+
+// ReceiveTransfer books the stock in transit into the destination location
+func (s *stockTransferService) ReceiveTransfer(ctx context.Context, id int, receivedBy int) (*domain.StockTransfer, error) {
+	transfer, err := s.GetTransferByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if transfer.Status != domain.StockTransferStatusInTransit {
+		return nil, fmt.Errorf("only transfers in transit can be received (status: %s)", transfer.Status)
+	}
+
+	if _, err := resolveStockLocation(ctx, s.stockLocationRepo, &transfer.ToLocationID); err != nil {
+		return nil, fmt.Errorf("destination: %w", err)
+	}
+
+	now := time.Now()
+	transfer.ReceivedBy = &receivedBy
+	transfer.ReceivedAt = &now
+
+	if err := s.stockTransferRepo.Receive(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	return s.GetTransferByID(ctx, id)
+}
+
+// CancelTransfer closes a draft or in-transit transfer; shipped stock goes
+// back to the source location
+func (s *stockTransferService) CancelTransfer(ctx context.Context, id int, cancelledBy int) (*domain.StockTransfer, error) {
+	transfer, err := s.GetTransferByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if transfer.Status != domain.StockTransferStatusDraft && transfer.Status != domain.StockTransferStatusInTransit {
+		return nil, fmt.Errorf("cannot cancel a transfer with status %s", transfer.Status)
+	}
+
+	if err := s.stockTransferRepo.Cancel(ctx, transfer, cancelledBy); err != nil {
+		return nil, err
+	}
+
+	return s.GetTransferByID(ctx, id)
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"pos-final/internal/repository"
+	"strings"
+	"time"
+)
+
+type vehicleDocumentService struct {
+	documentRepo    repository.VehicleDocumentRepository
+	vehicleRepo     repository.VehicleRepository
+	salesRepo       repository.SalesInvoiceRepository
+	requiredForSale []domain.VehicleDocumentType
+	blockMissing    bool
+}
+
+// NewVehicleDocumentService creates a new vehicle document service
+func NewVehicleDocumentService(
+	documentRepo repository.VehicleDocumentRepository,
+	vehicleRepo repository.VehicleRepository,
+	salesRepo repository.SalesInvoiceRepository,
+	requiredForSale []domain.VehicleDocumentType,
+	blockMissing bool,
+) VehicleDocumentService {
+	return &vehicleDocumentService{
+		documentRepo:    documentRepo,
+		vehicleRepo:     vehicleRepo,
+		salesRepo:       salesRepo,
+		requiredForSale: requiredForSale,
+		blockMissing:    blockMissing,
+	}
+}
+
+func (s *vehicleDocumentService) CreateDocument(ctx context.Context, document *domain.VehicleDocument) error {
+	vehicle, err := s.vehicleRepo.GetByID(ctx, document.VehicleID)
+	if err != nil {
+		return fmt.Errorf("failed to get vehicle: %w", err)
+	}
+	if vehicle == nil {
+		return fmt.Errorf("vehicle not found")
+	}
+
+	if document.CustodyStatus == "" {
+		document.CustodyStatus = domain.DocumentCustodyStatusInCustody
+	}
+	if document.CustodyStatus == domain.DocumentCustodyStatusHandedOver {
+		return fmt.Errorf("use the handover endpoint to hand documents to the buyer")
+	}
+	if document.CustodyStatus == domain.DocumentCustodyStatusInCustody && document.ReceivedDate == nil {
+		now := time.Now()
+		document.ReceivedDate = &now
+	}
+
+	if err := s.documentRepo.Create(ctx, document); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *vehicleDocumentService) GetDocumentByID(ctx context.Context, id int) (*domain.VehicleDocument, error) {
+	document, err := s.documentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return nil, fmt.Errorf("vehicle document not found")
+	}
+
+	return document, nil
+}
+
+func (s *vehicleDocumentService) ListDocumentsByVehicle(ctx context.Context, vehicleID int) ([]*domain.VehicleDocument, error) {
+	return s.documentRepo.ListByVehicleID(ctx, vehicleID)
+}
+
+func (s *vehicleDocumentService) UpdateDocument(ctx context.Context, document *domain.VehicleDocument) error {
+	existing, err := s.GetDocumentByID(ctx, document.ID)
+	if err != nil {
+		return err
+	}
+	if existing.CustodyStatus == domain.DocumentCustodyStatusHandedOver {
+		return fmt.Errorf("document has already been handed over")
+	}
+	if document.CustodyStatus == domain.DocumentCustodyStatusHandedOver {
+		return fmt.Errorf("use the handover endpoint to hand documents to the buyer")
+	}
+
+	if document.CustodyStatus == domain.DocumentCustodyStatusInCustody && document.ReceivedDate == nil {
+		document.ReceivedDate = existing.ReceivedDate
+		if document.ReceivedDate == nil {
+			now := time.Now()
+			document.ReceivedDate = &now
+		}
+	}
+
+	return s.documentRepo.Update(ctx, document)
+}
+
+func (s *vehicleDocumentService) DeleteDocument(ctx context.Context, id int, deletedBy int) error {
+	if _, err := s.GetDocumentByID(ctx, id); err != nil {
+		return err
+	}
+
+	return s.documentRepo.Delete(ctx, id, deletedBy)
+}
+
+func (s *vehicleDocumentService) AttachScan(ctx context.Context, id int, scanFile string) error {
+	if _, err := s.GetDocumentByID(ctx, id); err != nil {
+		return err
+	}
+
+	return s.documentRepo.UpdateScanFile(ctx, id, scanFile)
+}
+
+// HandOverDocument records that a document held by the dealer was given to
+// the buyer under the sales invoice of its vehicle
+func (s *vehicleDocumentService) HandOverDocument(ctx context.Context, document *domain.VehicleDocument) error {
+	existing, err := s.GetDocumentByID(ctx, document.ID)
+	if err != nil {
+		return err
+	}
+	if existing.CustodyStatus != domain.DocumentCustodyStatusInCustody {
+		return fmt.Errorf("document is not in custody (current status: %s)", existing.CustodyStatus)
+	}
+	if document.SalesInvoiceID == nil {
+		return fmt.Errorf("sales invoice is required")
+	}
+
+	invoice, err := s.salesRepo.GetByID(ctx, *document.SalesInvoiceID)
+	if err != nil {
+		return fmt.Errorf("sales invoice not found: %w", err)
+	}
+	if invoice.VehicleID != existing.VehicleID {
+		return fmt.Errorf("sales invoice %s is not for this vehicle", invoice.InvoiceNumber)
+	}
+
+	if document.HandedOverAt == nil {
+		now := time.Now()
+		document.HandedOverAt = &now
+	}
+
+	if err := s.documentRepo.HandOver(ctx, document); err != nil {
+		return err
+	}
+
+	existing.CustodyStatus = domain.DocumentCustodyStatusHandedOver
+	existing.SalesInvoiceID = document.SalesInvoiceID
+	existing.HandedOverAt = document.HandedOverAt
+	existing.HandedOverTo = document.HandedOverTo
+	existing.HandedOverBy = document.HandedOverBy
+	*document = *existing
+
+	return nil
+}
+
+// ListExpiringDocuments returns held documents whose validity or annual tax
+// runs out within the given number of days
+func (s *vehicleDocumentService) ListExpiringDocuments(ctx context.Context, days int) ([]*domain.VehicleDocument, error) {
+	before := time.Now().Truncate(24*time.Hour).AddDate(0, 0, days+1)
+	return s.documentRepo.ListExpiring(ctx, before)
+}
+
+// CheckSaleDocuments verifies the vehicle's required documents are in
+// custody. Problems are returned as warnings, or as an error when missing
+// documents are configured to block the sale.
+func (s *vehicleDocumentService) CheckSaleDocuments(ctx context.Context, vehicleID int) ([]string, error) {
+	documents, err := s.documentRepo.ListByVehicleID(ctx, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+
+	inCustody := make(map[domain.VehicleDocumentType]bool)
+	var warnings []string
+	today := time.Now().Truncate(24 * time.Hour)
+
+	for _, document := range documents {
+		if document.CustodyStatus != domain.DocumentCustodyStatusInCustody {
+			continue
+		}
+		inCustody[document.DocumentType] = true
+
+		if document.ExpiryDate != nil && document.ExpiryDate.Before(today) {
+			warnings = append(warnings, fmt.Sprintf("%s expired on %s", strings.ToUpper(string(document.DocumentType)), document.ExpiryDate.Format("2006-01-02")))
+		}
+		if document.TaxDueDate != nil && document.TaxDueDate.Before(today) {
+			warnings = append(warnings, fmt.Sprintf("%s tax was due on %s", strings.ToUpper(string(document.DocumentType)), document.TaxDueDate.Format("2006-01-02")))
+		}
+	}
+
+	var missing []string
+	for _, documentType := range s.requiredForSale {
+		if !inCustody[documentType] {
+			missing = append(missing, strings.ToUpper(string(documentType)))
+		}
+	}
+
+	if len(missing) > 0 {
+		if s.blockMissing {
+			return nil, fmt.Errorf("required documents not in custody: %s", strings.Join(missing, ", "))
+		}
+		warnings = append(warnings, fmt.Sprintf("required documents not in custody: %s", strings.Join(missing, ", ")))
+	}
+
+	return warnings, nil
+}
@@ -6,20 +6,54 @@ import (
 	"pos-final/internal/domain"
 	"pos-final/internal/repository"
 	"strings"
+	"time"
 )
 
 type vehicleService struct {
-	vehicleRepo repository.VehicleRepository
+	vehicleRepo     repository.VehicleRepository
+	vehicleCostRepo repository.VehicleCostRepository
 }
 
 // NewVehicleService creates a new vehicle service
-func NewVehicleService(vehicleRepo repository.VehicleRepository) VehicleService {
+func NewVehicleService(vehicleRepo repository.VehicleRepository, vehicleCostRepo repository.VehicleCostRepository) VehicleService {
 	return &vehicleService{
-		vehicleRepo: vehicleRepo,
+		vehicleRepo:     vehicleRepo,
+		vehicleCostRepo: vehicleCostRepo,
 	}
 }
 
 func (s *vehicleService) CreateVehicle(ctx context.Context, vehicle *domain.Vehicle) error {
+	if err := s.PrepareVehicle(ctx, vehicle); err != nil {
+		return err
+	}
+
+	// Create vehicle
+	if err := s.vehicleRepo.Create(ctx, vehicle); err != nil {
+		return fmt.Errorf("failed to create vehicle: %w", err)
+	}
+
+	// Keep a repair cost entered up front in the cost ledger so recalculating
+	// HPP does not drop it
+	if vehicle.RepairCost > 0 {
+		description := "Biaya perbaikan awal"
+		opening := &domain.VehicleCost{
+			VehicleID:   vehicle.ID,
+			CostType:    domain.VehicleCostTypeOther,
+			Amount:      vehicle.RepairCost,
+			CostDate:    time.Now(),
+			Description: &description,
+		}
+		if err := s.vehicleCostRepo.Create(ctx, opening); err != nil {
+			return fmt.Errorf("failed to record opening repair cost: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// PrepareVehicle validates a new vehicle and fills in its code, status and
+// HPP without saving it
+func (s *vehicleService) PrepareVehicle(ctx context.Context, vehicle *domain.Vehicle) error {
 	// Validate required fields
 	if err := s.validateVehicle(vehicle); err != nil {
 		return err
@@ -58,11 +92,6 @@ func (s *vehicleService) CreateVehicle(ctx context.Context, vehicle *domain.Vehi
 		vehicle.HPP = &hpp
 	}
 
-	// Create vehicle
-	if err := s.vehicleRepo.Create(ctx, vehicle); err != nil {
-		return fmt.Errorf("failed to create vehicle: %w", err)
-	}
-
 	return nil
 }
 
@@ -216,6 +245,9 @@ func (s *vehicleService) UpdateVehicle(ctx context.Context, vehicle *domain.Vehi
 		return fmt.Errorf("vehicle not found")
 	}
 
+	// Repair cost is rolled up from the cost ledger, not edited directly
+	vehicle.RepairCost = existing.RepairCost
+
 	// Recalculate HPP
 	hpp := 0.0
 	if vehicle.PurchasePrice != nil {
@@ -292,34 +324,137 @@ func (s *vehicleService) UpdateVehicleStatus(ctx context.Context, id int, status
 	return nil
 }
 
+// CalculateHPP rolls the vehicle's cost ledger up into its repair cost and
+// HPP (purchase price + completed reconditioning work orders + landed costs)
 func (s *vehicleService) CalculateHPP(ctx context.Context, vehicleID int) error {
+	breakdown, err := s.GetCostBreakdown(ctx, vehicleID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.vehicleRepo.UpdateCosts(ctx, vehicleID, breakdown.RepairCost, breakdown.HPP); err != nil {
+		return fmt.Errorf("failed to update vehicle HPP: %w", err)
+	}
+
+	return nil
+}
+
+// GetCostBreakdown returns the components of the vehicle's HPP as they are in
+// the ledger now
+func (s *vehicleService) GetCostBreakdown(ctx context.Context, vehicleID int) (*domain.VehicleCostBreakdown, error) {
 	if vehicleID <= 0 {
-		return fmt.Errorf("invalid vehicle ID")
+		return nil, fmt.Errorf("invalid vehicle ID")
 	}
 
-	// Get vehicle
 	vehicle, err := s.vehicleRepo.GetByID(ctx, vehicleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vehicle: %w", err)
+	}
+	if vehicle == nil {
+		return nil, fmt.Errorf("vehicle not found")
+	}
+
+	totals, err := s.vehicleCostRepo.SumByType(ctx, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+
+	workOrders, err := s.vehicleCostRepo.ListReconditioningWorkOrders(ctx, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+
+	costs, err := s.vehicleCostRepo.ListByVehicleID(ctx, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+
+	breakdown := &domain.VehicleCostBreakdown{
+		VehicleID:   vehicle.ID,
+		VehicleCode: vehicle.VehicleCode,
+		WorkOrders:  workOrders,
+		Costs:       costs,
+	}
+	if vehicle.PurchasePrice != nil {
+		breakdown.PurchasePrice = *vehicle.PurchasePrice
+	}
+
+	breakdown.Components = append(breakdown.Components, &domain.VehicleCostComponent{
+		CostType: domain.VehicleCostTypePurchase,
+		Amount:   breakdown.PurchasePrice,
+		Entries:  1,
+	})
+	for _, total := range totals {
+		if total.Entries == 0 {
+			continue
+		}
+		breakdown.Components = append(breakdown.Components, total)
+		breakdown.RepairCost += total.Amount
+	}
+
+	breakdown.RepairCost = roundCost(breakdown.RepairCost)
+	breakdown.HPP = roundCost(breakdown.PurchasePrice + breakdown.RepairCost)
+
+	return breakdown, nil
+}
+
+// AddCost records a landed cost such as transport or registration fees and
+// recalculates the vehicle's HPP
+func (s *vehicleService) AddCost(ctx context.Context, cost *domain.VehicleCost) error {
+	if !cost.CostType.IsLedgerType() {
+		return fmt.Errorf("invalid cost type: %s", cost.CostType)
+	}
+	if cost.Amount <= 0 {
+		return fmt.Errorf("amount must be greater than 0")
+	}
+
+	vehicle, err := s.vehicleRepo.GetByID(ctx, cost.VehicleID)
 	if err != nil {
 		return fmt.Errorf("failed to get vehicle: %w", err)
 	}
 	if vehicle == nil {
 		return fmt.Errorf("vehicle not found")
 	}
+	if vehicle.Status == domain.VehicleStatusSold {
+		return fmt.Errorf("cannot add costs to a sold vehicle")
+	}
 
-	// Calculate HPP (Purchase Price + Repair Cost)
-	hpp := 0.0
-	if vehicle.PurchasePrice != nil {
-		hpp += *vehicle.PurchasePrice
+	if cost.CostDate.IsZero() {
+		cost.CostDate = time.Now()
 	}
-	hpp += vehicle.RepairCost
-	vehicle.HPP = &hpp
 
-	// Update vehicle
-	if err := s.vehicleRepo.Update(ctx, vehicle); err != nil {
-		return fmt.Errorf("failed to update vehicle HPP: %w", err)
+	if err := s.vehicleCostRepo.Create(ctx, cost); err != nil {
+		return err
 	}
 
-	return nil
+	return s.CalculateHPP(ctx, cost.VehicleID)
+}
+
+func (s *vehicleService) DeleteCost(ctx context.Context, id int, deletedBy int) error {
+	cost, err := s.vehicleCostRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if cost == nil {
+		return fmt.Errorf("vehicle cost not found")
+	}
+
+	vehicle, err := s.vehicleRepo.GetByID(ctx, cost.VehicleID)
+	if err != nil {
+		return fmt.Errorf("failed to get vehicle: %w", err)
+	}
+	if vehicle == nil {
+		return fmt.Errorf("vehicle not found")
+	}
+	if vehicle.Status == domain.VehicleStatusSold {
+		return fmt.Errorf("cannot delete costs of a sold vehicle")
+	}
+
+	if err := s.vehicleCostRepo.SoftDelete(ctx, id, deletedBy); err != nil {
+		return err
+	}
+
+	return s.CalculateHPP(ctx, cost.VehicleID)
 }
 
 func (s *vehicleService) validateVehicle(vehicle *domain.Vehicle) error {
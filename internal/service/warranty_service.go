@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"pos-final/internal/domain"
+	"pos-final/internal/repository"
+	"time"
+)
+
+// Default warranty terms for used vehicles
+const (
+	defaultWarrantyMonths   = 3
+	defaultWarrantyCoverage = "engine"
+)
+
+type warrantyService struct {
+	warrantyRepo      repository.WarrantyRepository
+	warrantyClaimRepo repository.WarrantyClaimRepository
+	salesRepo         repository.SalesInvoiceRepository
+	workOrderService  WorkOrderService
+}
+
+// NewWarrantyService creates a new warranty service
+func NewWarrantyService(
+	warrantyRepo repository.WarrantyRepository,
+	warrantyClaimRepo repository.WarrantyClaimRepository,
+	salesRepo repository.SalesInvoiceRepository,
+	workOrderService WorkOrderService,
+) WarrantyService {
+	return &warrantyService{
+		warrantyRepo:      warrantyRepo,
+		warrantyClaimRepo: warrantyClaimRepo,
+		salesRepo:         salesRepo,
+		workOrderService:  workOrderService,
+	}
+}
+
+func (s *warrantyService) CreateWarranty(ctx context.Context, warranty *domain.Warranty) error {
+	// Warranty terms are tied to a single sale
+	invoice, err := s.salesRepo.GetByID(ctx, warranty.SalesInvoiceID)
+	if err != nil {
+		return fmt.Errorf("failed to get sales invoice: %w", err)
+	}
+
+	existing, err := s.warrantyRepo.GetBySalesInvoiceID(ctx, invoice.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check existing warranty: %w", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("sales invoice already has warranty %s", existing.WarrantyNumber)
+	}
+
+	if warranty.WarrantyNumber == "" {
+		warrantyNumber, err := s.warrantyRepo.GenerateWarrantyNumber(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to generate warranty number: %w", err)
+		}
+		warranty.WarrantyNumber = warrantyNumber
+	}
+
+	if warranty.DurationMonths <= 0 {
+		warranty.DurationMonths = defaultWarrantyMonths
+	}
+	if warranty.Coverage == "" {
+		warranty.Coverage = defaultWarrantyCoverage
+	}
+	// Mileage is measured from the odometer reading at handover
+	if warranty.MileageLimit != nil && warranty.StartMileage == nil {
+		return fmt.Errorf("start mileage is required when a mileage limit is set")
+	}
+	if warranty.StartDate.IsZero() {
+		warranty.StartDate = invoice.TransactionDate
+	}
+
+	warranty.VehicleID = invoice.VehicleID
+	warranty.CustomerID = invoice.CustomerID
+	warranty.EndDate = warranty.StartDate.AddDate(0, warranty.DurationMonths, 0)
+	warranty.Status = domain.WarrantyStatusActive
+
+	if err := s.warrantyRepo.Create(ctx, warranty); err != nil {
+		return fmt.Errorf("failed to create warranty: %w", err)
+	}
+
+	return nil
+}
+
+func (s *warrantyService) GetWarrantyByID(ctx context.Context, id int) (*domain.Warranty, error) {
+	warranty, err := s.warrantyRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if warranty == nil {
+		return nil, fmt.Errorf("warranty not found")
+	}
+
+	return warranty, nil
+}
+
+func (s *warrantyService) ListWarranties(ctx context.Context, page, limit int) ([]*domain.Warranty, int, error) {
+	offset := (page - 1) * limit
+	warranties, err := s.warrantyRepo.List(ctx, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := s.warrantyRepo.Count(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return warranties, count, nil
+}
+
+func (s *warrantyService) LookupWarranties(ctx context.Context, plateNumber, chassisNumber string) ([]*domain.Warranty, error) {
+	if plateNumber == "" && chassisNumber == "" {
+		return nil, fmt.Errorf("plate number or chassis number is required")
+	}
+
+	return s.warrantyRepo.SearchByVehicle(ctx, plateNumber, chassisNumber)
+}
+
+// ExpireOverdueWarranties marks active warranties past their end date as
+// expired and returns how many changed
+func (s *warrantyService) ExpireOverdueWarranties(ctx context.Context) (int, error) {
+	return s.warrantyRepo.ExpireOverdue(ctx)
+}
+
+// StartWarrantyExpiryScheduler expires overdue warranties every interval
+// until the context is done
+func StartWarrantyExpiryScheduler(ctx context.Context, warrantyService WarrantyService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := warrantyService.ExpireOverdueWarranties(ctx)
+			if err != nil {
+				log.Printf("warranty expiry check failed: %v", err)
+				continue
+			}
+			log.Printf("warranty expiry check expired %d warranties", expired)
+		}
+	}
+}
+
+func (s *warrantyService) VoidWarranty(ctx context.Context, id int) error {
+	warranty, err := s.warrantyRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get warranty: %w", err)
+	}
+	if warranty == nil {
+		return fmt.Errorf("warranty not found")
+	}
+
+	return s.warrantyRepo.UpdateStatus(ctx, id, domain.WarrantyStatusVoid)
+}
+
+func (s *warrantyService) CreateClaim(ctx context.Context, claim *domain.WarrantyClaim, mechanicID int) error {
+	warranty, err := s.GetWarrantyByID(ctx, claim.WarrantyID)
+	if err != nil {
+		return err
+	}
+
+	if claim.ClaimDate.IsZero() {
+		claim.ClaimDate = time.Now()
+	}
+
+	// Validate warranty terms
+	if warranty.Status != domain.WarrantyStatusActive {
+		return fmt.Errorf("warranty is not active (current status: %s)", warranty.Status)
+	}
+	if claim.ClaimDate.After(warranty.EndDate.AddDate(0, 0, 1)) {
+		return fmt.Errorf("warranty expired on %s", warranty.EndDate.Format("2006-01-02"))
+	}
+	if warranty.MileageLimit != nil {
+		if warranty.StartMileage == nil {
+			return fmt.Errorf("warranty has a mileage limit but no start mileage")
+		}
+		if claim.CurrentMileage == nil {
+			return fmt.Errorf("current mileage is required for this warranty")
+		}
+		if *claim.CurrentMileage-*warranty.StartMileage > *warranty.MileageLimit {
+			return fmt.Errorf("mileage limit exceeded: %d km driven, limit %d km",
+				*claim.CurrentMileage-*warranty.StartMileage, *warranty.MileageLimit)
+		}
+	}
+
+	claimNumber, err := s.warrantyClaimRepo.GenerateClaimNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to generate claim number: %w", err)
+	}
+	claim.ClaimNumber = claimNumber
+
+	// Every claim opens a warranty work order
+	workOrder := &domain.WorkOrder{
+		OrderType:          domain.WorkOrderTypeReconditioning,
+		VehicleID:          &warranty.VehicleID,
+		Description:        fmt.Sprintf("Warranty claim %s: %s", claim.ClaimNumber, claim.Complaint),
+		AssignedMechanicID: mechanicID,
+		Status:             domain.WorkOrderStatusPending,
+		CreatedBy:          claim.CreatedBy,
+		IsWarranty:         true,
+	}
+
+	if err := s.workOrderService.PrepareWorkOrder(ctx, workOrder); err != nil {
+		return fmt.Errorf("failed to prepare warranty work order: %w", err)
+	}
+
+	// The claim and its work order are saved together
+	if err := s.warrantyClaimRepo.CreateWithWorkOrder(ctx, claim, workOrder); err != nil {
+		return err
+	}
+	claim.WorkOrder = workOrder
+
+	return nil
+}
+
+func (s *warrantyService) ListClaims(ctx context.Context, warrantyID int) ([]*domain.WarrantyClaim, error) {
+	return s.warrantyClaimRepo.ListByWarrantyID(ctx, warrantyID)
+}
+
+func (s *warrantyService) GetWarrantyCostReport(ctx context.Context, startDate, endDate time.Time) (map[string]interface{}, error) {
+	claims, err := s.warrantyClaimRepo.ListByDateRange(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get warranty claims: %w", err)
+	}
+
+	var totalPartsCost, totalLaborCost, totalExpense float64
+	var completedClaims, openClaims int
+	coverageBreakdown := make(map[string]float64)
+
+	for _, claim := range claims {
+		if claim.WorkOrder == nil || claim.WorkOrder.Status == domain.WorkOrderStatusCancelled {
+			continue
+		}
+
+		totalPartsCost += claim.WorkOrder.TotalPartsCost
+		totalLaborCost += claim.WorkOrder.LaborCost
+		totalExpense += claim.WorkOrder.TotalCost
+
+		if claim.WorkOrder.Status == domain.WorkOrderStatusCompleted {
+			completedClaims++
+		} else {
+			openClaims++
+		}
+
+		if claim.Warranty != nil {
+			coverageBreakdown[claim.Warranty.Coverage] += claim.WorkOrder.TotalCost
+		}
+	}
+
+	totalClaims := completedClaims + openClaims
+	avgCostPerClaim := float64(0)
+	if totalClaims > 0 {
+		avgCostPerClaim = totalExpense / float64(totalClaims)
+	}
+
+	return map[string]interface{}{
+		"period": map[string]interface{}{
+			"start_date": startDate.Format("2006-01-02"),
+			"end_date":   endDate.Format("2006-01-02"),
+		},
+		"summary": map[string]interface{}{
+			"total_claims":           totalClaims,
+			"completed_claims":       completedClaims,
+			"open_claims":            openClaims,
+			"total_parts_cost":       totalPartsCost,
+			"total_labor_cost":       totalLaborCost,
+			"total_warranty_expense": totalExpense,
+			"avg_cost_per_claim":     avgCostPerClaim,
+		},
+		"breakdown": map[string]interface{}{
+			"by_coverage": coverageBreakdown,
+		},
+		"claims": claims,
+	}, nil
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"pos-final/internal/repository"
+)
+
+type workOrderAttachmentService struct {
+	attachmentRepo    repository.WorkOrderAttachmentRepository
+	workOrderRepo     repository.WorkOrderRepository
+	workOrderTaskRepo repository.WorkOrderTaskRepository
+	fileService       FileService
+}
+
+// NewWorkOrderAttachmentService creates a new work order attachment service
+func NewWorkOrderAttachmentService(
+	attachmentRepo repository.WorkOrderAttachmentRepository,
+	workOrderRepo repository.WorkOrderRepository,
+	workOrderTaskRepo repository.WorkOrderTaskRepository,
+	fileService FileService,
+) WorkOrderAttachmentService {
+	return &workOrderAttachmentService{
+		attachmentRepo:    attachmentRepo,
+		workOrderRepo:     workOrderRepo,
+		workOrderTaskRepo: workOrderTaskRepo,
+		fileService:       fileService,
+	}
+}
+
+// AddAttachment records an uploaded file against a work order or one of its
+// tasks. Mechanics may only attach to work they are assigned to.
+func (s *workOrderAttachmentService) AddAttachment(ctx context.Context, attachment *domain.WorkOrderAttachment, uploaderRole domain.UserRole) error {
+	switch attachment.Stage {
+	case domain.AttachmentStageBefore, domain.AttachmentStageDuring, domain.AttachmentStageAfter:
+	default:
+		return fmt.Errorf("invalid stage: %s", attachment.Stage)
+	}
+
+	workOrder, err := s.workOrderRepo.GetByID(ctx, attachment.WorkOrderID)
+	if err != nil {
+		return fmt.Errorf("failed to get work order: %w", err)
+	}
+	assigned := workOrder.AssignedMechanicID == attachment.UploadedBy
+
+	if attachment.WorkOrderTaskID != nil {
+		task, err := s.workOrderTaskRepo.GetByID(ctx, *attachment.WorkOrderTaskID)
+		if err != nil {
+			return err
+		}
+		if task == nil || task.WorkOrderID != attachment.WorkOrderID {
+			return fmt.Errorf("task does not belong to this work order")
+		}
+		if task.AssignedMechanicID != nil && *task.AssignedMechanicID == attachment.UploadedBy {
+			assigned = true
+		}
+	}
+
+	if uploaderRole == domain.RoleMekanik && !assigned {
+		return fmt.Errorf("mechanics can only attach files to work assigned to them")
+	}
+
+	if err := s.attachmentRepo.Create(ctx, attachment); err != nil {
+		return err
+	}
+	attachment.FileURL = s.fileService.GetFileURL(attachment.FilePath)
+
+	return nil
+}
+
+func (s *workOrderAttachmentService) ListAttachments(ctx context.Context, workOrderID int) ([]*domain.WorkOrderAttachment, error) {
+	attachments, err := s.attachmentRepo.ListByWorkOrderID(ctx, workOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range attachments {
+		attachment.FileURL = s.fileService.GetFileURL(attachment.FilePath)
+	}
+
+	return attachments, nil
+}
+
+// DeleteAttachment removes an attachment from the work order. Mechanics may
+// only remove their own uploads.
+func (s *workOrderAttachmentService) DeleteAttachment(ctx context.Context, id int, deletedBy int, role domain.UserRole) error {
+	attachment, err := s.attachmentRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if attachment == nil {
+		return fmt.Errorf("attachment not found")
+	}
+	if role == domain.RoleMekanik && attachment.UploadedBy != deletedBy {
+		return fmt.Errorf("mechanics can only delete their own attachments")
+	}
+
+	return s.attachmentRepo.Delete(ctx, id, deletedBy)
+}
@@ -5,35 +5,87 @@ import (
 	"fmt"
 	"pos-final/internal/domain"
 	"pos-final/internal/repository"
+	"strings"
 	"time"
 )
 
 type workOrderService struct {
-	workOrderRepo     repository.WorkOrderRepository
-	vehicleRepo       repository.VehicleRepository
-	sparePartRepo     repository.SparePartRepository
-	workOrderPartRepo repository.WorkOrderPartRepository
-	userRepo          repository.UserRepository
+	workOrderRepo       repository.WorkOrderRepository
+	vehicleRepo         repository.VehicleRepository
+	customerRepo        repository.CustomerRepository
+	customerVehicleRepo repository.CustomerVehicleRepository
+	sparePartRepo       repository.SparePartRepository
+	workOrderPartRepo   repository.WorkOrderPartRepository
+	workOrderTaskRepo   repository.WorkOrderTaskRepository
+	laborRepo           repository.LaborRepository
+	userRepo            repository.UserRepository
+	stockCostLayerRepo  repository.StockCostLayerRepository
+	subletRepo          repository.WorkOrderSubletRepository
+	stockLocationRepo   repository.StockLocationRepository
+	fitmentRepo         repository.SparePartFitmentRepository
+	serviceKitRepo      repository.ServiceKitRepository
+	mechanicService     MechanicService
+	vehicleService      VehicleService
+	costingMethod       domain.CostingMethod
 }
 
 // NewWorkOrderService creates a new work order service
 func NewWorkOrderService(
 	workOrderRepo repository.WorkOrderRepository,
 	vehicleRepo repository.VehicleRepository,
+	customerRepo repository.CustomerRepository,
+	customerVehicleRepo repository.CustomerVehicleRepository,
 	sparePartRepo repository.SparePartRepository,
 	workOrderPartRepo repository.WorkOrderPartRepository,
+	workOrderTaskRepo repository.WorkOrderTaskRepository,
+	laborRepo repository.LaborRepository,
 	userRepo repository.UserRepository,
+	stockCostLayerRepo repository.StockCostLayerRepository,
+	subletRepo repository.WorkOrderSubletRepository,
+	stockLocationRepo repository.StockLocationRepository,
+	fitmentRepo repository.SparePartFitmentRepository,
+	serviceKitRepo repository.ServiceKitRepository,
+	mechanicService MechanicService,
+	vehicleService VehicleService,
+	costingMethod domain.CostingMethod,
 ) WorkOrderService {
 	return &workOrderService{
-		workOrderRepo:     workOrderRepo,
-		vehicleRepo:       vehicleRepo,
-		sparePartRepo:     sparePartRepo,
-		workOrderPartRepo: workOrderPartRepo,
-		userRepo:          userRepo,
+		workOrderRepo:       workOrderRepo,
+		vehicleRepo:         vehicleRepo,
+		customerRepo:        customerRepo,
+		customerVehicleRepo: customerVehicleRepo,
+		sparePartRepo:       sparePartRepo,
+		workOrderPartRepo:   workOrderPartRepo,
+		workOrderTaskRepo:   workOrderTaskRepo,
+		laborRepo:           laborRepo,
+		userRepo:            userRepo,
+		stockCostLayerRepo:  stockCostLayerRepo,
+		subletRepo:          subletRepo,
+		stockLocationRepo:   stockLocationRepo,
+		fitmentRepo:         fitmentRepo,
+		serviceKitRepo:      serviceKitRepo,
+		mechanicService:     mechanicService,
+		vehicleService:      vehicleService,
+		costingMethod:       costingMethod,
 	}
 }
 
 func (s *workOrderService) CreateWorkOrder(ctx context.Context, workOrder *domain.WorkOrder) error {
+	if err := s.PrepareWorkOrder(ctx, workOrder); err != nil {
+		return err
+	}
+
+	// Create the work order
+	if err := s.workOrderRepo.Create(ctx, workOrder); err != nil {
+		return fmt.Errorf("failed to create work order: %w", err)
+	}
+
+	return nil
+}
+
+// PrepareWorkOrder numbers and validates a new work order and assigns its
+// mechanic without saving it, for callers that save it with other records
+func (s *workOrderService) PrepareWorkOrder(ctx context.Context, workOrder *domain.WorkOrder) error {
 	// Generate WO number if not provided
 	if workOrder.WONumber == "" {
 		woNumber, err := s.workOrderRepo.GenerateWONumber(ctx)
@@ -43,30 +95,142 @@ func (s *workOrderService) CreateWorkOrder(ctx context.Context, workOrder *domai
 		workOrder.WONumber = woNumber
 	}
 
-	// Validate assigned mechanic
+	// Resolve what is being repaired: a stock vehicle, or a customer's vehicle
+	// whose owner is billed for the job
+	if workOrder.OrderType == "" {
+		workOrder.OrderType = domain.WorkOrderTypeReconditioning
+	}
+
+	var vehicle *domain.Vehicle
+	switch workOrder.OrderType {
+	case domain.WorkOrderTypeCustomerService:
+		if workOrder.CustomerVehicleID == nil {
+			return fmt.Errorf("customer vehicle is required for a customer service work order")
+		}
+		customerVehicle, err := s.customerVehicleRepo.GetByID(ctx, *workOrder.CustomerVehicleID)
+		if err != nil {
+			return err
+		}
+		if customerVehicle == nil {
+			return fmt.Errorf("customer vehicle not found")
+		}
+		workOrder.CustomerID = &customerVehicle.CustomerID
+		workOrder.VehicleID = nil
+		workOrder.IsWarranty = false
+		// Only the brand and model matter for picking a mechanic
+		vehicle = &domain.Vehicle{Brand: customerVehicle.Brand, Model: customerVehicle.Model}
+	case domain.WorkOrderTypeReconditioning:
+		if workOrder.VehicleID == nil {
+			return fmt.Errorf("vehicle is required for a reconditioning work order")
+		}
+		// Warranty work is done on vehicles already sold; anything else
+		// reconditions stock
+		if !workOrder.IsWarranty {
+			var err error
+			vehicle, err = s.vehicleRepo.GetByID(ctx, *workOrder.VehicleID)
+			if err != nil {
+				return fmt.Errorf("failed to get vehicle: %w", err)
+			}
+			if vehicle == nil {
+				return fmt.Errorf("vehicle not found")
+			}
+			if vehicle.Status == domain.VehicleStatusSold {
+				return fmt.Errorf("vehicle %s is already sold", vehicle.VehicleCode)
+			}
+		}
+		workOrder.CustomerID = nil
+		workOrder.CustomerVehicleID = nil
+		workOrder.LaborPrice = 0
+	default:
+		return fmt.Errorf("invalid work order type: %s", workOrder.OrderType)
+	}
+	if workOrder.LaborPrice < 0 {
+		return fmt.Errorf("labor price cannot be negative")
+	}
+
+	// Validate assigned mechanic, or pick one automatically
 	if workOrder.AssignedMechanicID > 0 {
 		mechanic, err := s.userRepo.GetByID(ctx, workOrder.AssignedMechanicID)
 		if err != nil {
 			return fmt.Errorf("failed to get assigned mechanic: %w", err)
 		}
+		if mechanic == nil {
+			return fmt.Errorf("assigned mechanic not found")
+		}
 		if mechanic.Role != domain.RoleMekanik {
 			return fmt.Errorf("assigned user is not a mechanic")
 		}
+		if err := s.mechanicService.CheckAvailable(ctx, mechanic.ID); err != nil {
+			return err
+		}
+		if workOrder.AssignmentReason == nil {
+			reason := "manually assigned"
+			workOrder.AssignmentReason = &reason
+		}
+	} else {
+		if vehicle == nil {
+			var err error
+			vehicle, err = s.vehicleRepo.GetByID(ctx, *workOrder.VehicleID)
+			if err != nil {
+				return fmt.Errorf("failed to get vehicle: %w", err)
+			}
+			if vehicle == nil {
+				return fmt.Errorf("vehicle not found")
+			}
+		}
+
+		assignment, err := s.mechanicService.AssignMechanic(ctx, vehicle)
+		if err != nil {
+			return err
+		}
+		workOrder.AssignedMechanicID = assignment.MechanicID
+		workOrder.AssignmentReason = &assignment.Reason
 	}
 
 	// Calculate total cost
-	workOrder.TotalCost = workOrder.TotalPartsCost + workOrder.LaborCost
-
-	// Create the work order
-	if err := s.workOrderRepo.Create(ctx, workOrder); err != nil {
-		return fmt.Errorf("failed to create work order: %w", err)
-	}
+	workOrder.TotalCost = workOrder.TotalPartsCost + workOrder.LaborCost + workOrder.SubletCost
 
 	return nil
 }
 
 func (s *workOrderService) GetWorkOrderByID(ctx context.Context, id int) (*domain.WorkOrder, error) {
-	return s.workOrderRepo.GetByID(ctx, id)
+	workOrder, err := s.workOrderRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	workOrder.Tasks, err = s.workOrderTaskRepo.ListByWorkOrderID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	workOrder.Parts, err = s.workOrderPartRepo.ListByWorkOrderID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	workOrder.PartReturns, err = s.workOrderPartRepo.ListReturnsByWorkOrderID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	workOrder.Sublets, err = s.subletRepo.ListByWorkOrderID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if workOrder.IsCustomerService() {
+		workOrder.Customer, err = s.customerRepo.GetByID(ctx, *workOrder.CustomerID)
+		if err != nil {
+			return nil, err
+		}
+		workOrder.CustomerVehicle, err = s.customerVehicleRepo.GetByID(ctx, *workOrder.CustomerVehicleID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return workOrder, nil
 }
 
 func (s *workOrderService) GetWorkOrderByNumber(ctx context.Context, woNumber string) (*domain.WorkOrder, error) {
@@ -121,7 +285,7 @@ func (s *workOrderService) ListWorkOrdersByMechanic(ctx context.Context, mechani
 
 func (s *workOrderService) UpdateWorkOrder(ctx context.Context, workOrder *domain.WorkOrder) error {
 	// Recalculate total cost
-	workOrder.TotalCost = workOrder.TotalPartsCost + workOrder.LaborCost
+	workOrder.TotalCost = workOrder.TotalPartsCost + workOrder.LaborCost + workOrder.SubletCost
 
 	if err := s.workOrderRepo.Update(ctx, workOrder); err != nil {
 		return fmt.Errorf("failed to update work order: %w", err)
@@ -134,87 +298,135 @@ func (s *workOrderService) DeleteWorkOrder(ctx context.Context, id int, deletedB
 	return s.workOrderRepo.SoftDelete(ctx, id, deletedBy)
 }
 
-func (s *workOrderService) UpdateWorkOrderStatus(ctx context.Context, id int, status domain.WorkOrderStatus) error {
-	return s.workOrderRepo.UpdateStatus(ctx, id, status)
+// UpdateWorkOrderStatus moves a work order along the status state machine and
+// records who made the change and why
+func (s *workOrderService) UpdateWorkOrderStatus(ctx context.Context, id int, status domain.WorkOrderStatus, changedBy int, reason *string) error {
+	workOrder, err := s.workOrderRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get work order: %w", err)
+	}
+
+	if !workOrder.Status.CanTransitionTo(status) {
+		return fmt.Errorf("cannot change work order status from %s to %s", workOrder.Status, status)
+	}
+
+	if status == domain.WorkOrderStatusCompleted {
+		tasks, err := s.workOrderTaskRepo.ListByWorkOrderID(ctx, id)
+		if err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			if task.Status.IsOpen() {
+				return fmt.Errorf("task %q is still %s", task.Title, task.Status)
+			}
+		}
+	}
+
+	// Logged time must be closed before the labor cost is final
+	if status == domain.WorkOrderStatusCompleted || status == domain.WorkOrderStatusCancelled {
+		openTimers, err := s.laborRepo.CountOpenByWorkOrder(ctx, id)
+		if err != nil {
+			return err
+		}
+		if openTimers > 0 {
+			return fmt.Errorf("stop the %d running or paused labor timers first", openTimers)
+		}
+
+		// Sublet work still with a vendor has no final cost yet
+		openSublets, err := s.subletRepo.CountSentByWorkOrder(ctx, id)
+		if err != nil {
+			return err
+		}
+		if openSublets > 0 {
+			return fmt.Errorf("complete or cancel the %d sublets still with vendors first", openSublets)
+		}
+	}
+
+	history := &domain.WorkOrderStatusHistory{
+		WorkOrderID: id,
+		FromStatus:  workOrder.Status,
+		ToStatus:    status,
+		Reason:      reason,
+		ChangedBy:   changedBy,
+	}
+
+	if err := s.workOrderRepo.TransitionStatus(ctx, history); err != nil {
+		return err
+	}
+
+	if status == domain.WorkOrderStatusCompleted {
+		return s.finishCompletedWorkOrder(ctx, workOrder)
+	}
+
+	return nil
+}
+
+func (s *workOrderService) GetStatusHistory(ctx context.Context, id int) ([]*domain.WorkOrderStatusHistory, error) {
+	if _, err := s.workOrderRepo.GetByID(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to get work order: %w", err)
+	}
+
+	return s.workOrderRepo.ListStatusHistory(ctx, id)
 }
 
 func (s *workOrderService) UpdateWorkOrderProgress(ctx context.Context, id int, progress int) error {
 	if progress < 0 || progress > 100 {
 		return fmt.Errorf("progress must be between 0 and 100")
 	}
+
+	tasks, err := s.workOrderTaskRepo.ListByWorkOrderID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(tasks) > 0 {
+		return fmt.Errorf("progress is derived from the work order tasks")
+	}
+
 	return s.workOrderRepo.UpdateProgress(ctx, id, progress)
 }
 
-func (s *workOrderService) StartWorkOrder(ctx context.Context, id int) error {
-	// Update status to in progress
-	if err := s.workOrderRepo.UpdateStatus(ctx, id, domain.WorkOrderStatusInProgress); err != nil {
+func (s *workOrderService) StartWorkOrder(ctx context.Context, id int, changedBy int) error {
+	if err := s.UpdateWorkOrderStatus(ctx, id, domain.WorkOrderStatusInProgress, changedBy, nil); err != nil {
 		return fmt.Errorf("failed to start work order: %w", err)
 	}
 
 	return nil
 }
 
-func (s *workOrderService) CompleteWorkOrder(ctx context.Context, id int) error {
-	// Get the work order
-	workOrder, err := s.workOrderRepo.GetByID(ctx, id)
-	if err != nil {
-		return fmt.Errorf("failed to get work order: %w", err)
+func (s *workOrderService) CompleteWorkOrder(ctx context.Context, id int, changedBy int) error {
+	if err := s.UpdateWorkOrderStatus(ctx, id, domain.WorkOrderStatusCompleted, changedBy, nil); err != nil {
+		return fmt.Errorf("failed to complete work order: %w", err)
 	}
 
-	// Update status to completed
-	if err := s.workOrderRepo.UpdateStatus(ctx, id, domain.WorkOrderStatusCompleted); err != nil {
-		return fmt.Errorf("failed to complete work order: %w", err)
+	return nil
+}
+
+// finishCompletedWorkOrder books the repair cost into the vehicle once its
+// work order is completed
+func (s *workOrderService) finishCompletedWorkOrder(ctx context.Context, workOrder *domain.WorkOrder) error {
+	// Warranty repairs are booked as warranty expense; the vehicle is already
+	// sold. Customer jobs are billed through a service invoice instead.
+	if !workOrder.CountsTowardHPP() {
+		return nil
 	}
 
 	// Update vehicle HPP (Harga Pokok Penjualan)
-	if err := s.updateVehicleHPP(ctx, workOrder.VehicleID); err != nil {
+	if err := s.updateVehicleHPP(ctx, *workOrder.VehicleID); err != nil {
 		return fmt.Errorf("failed to update vehicle HPP: %w", err)
 	}
 
 	// Update vehicle status to available
-	if err := s.vehicleRepo.UpdateStatus(ctx, workOrder.VehicleID, domain.VehicleStatusAvailable); err != nil {
+	if err := s.vehicleRepo.UpdateStatus(ctx, *workOrder.VehicleID, domain.VehicleStatusAvailable); err != nil {
 		return fmt.Errorf("failed to update vehicle status: %w", err)
 	}
 
 	return nil
 }
 
+// updateVehicleHPP recalculates the vehicle's HPP from its cost ledger, which
+// includes every completed reconditioning work order
 func (s *workOrderService) updateVehicleHPP(ctx context.Context, vehicleID int) error {
-	// Get vehicle
-	vehicle, err := s.vehicleRepo.GetByID(ctx, vehicleID)
-	if err != nil {
-		return fmt.Errorf("failed to get vehicle: %w", err)
-	}
-
-	// Calculate total repair cost from all completed work orders for this vehicle
-	totalRepairCost := float64(0)
-	
-	// Get all work orders for this vehicle
-	allWorkOrders, err := s.workOrderRepo.ListByStatus(ctx, domain.WorkOrderStatusCompleted, 0, 1000)
-	if err != nil {
-		return fmt.Errorf("failed to get completed work orders: %w", err)
-	}
-
-	for _, wo := range allWorkOrders {
-		if wo.VehicleID == vehicleID {
-			totalRepairCost += wo.TotalCost
-		}
-	}
-
-	// Update vehicle repair cost and HPP
-	vehicle.RepairCost = totalRepairCost
-	
-	// HPP = Purchase Price + Total Repair Cost
-	if vehicle.PurchasePrice != nil {
-		hpp := *vehicle.PurchasePrice + totalRepairCost
-		vehicle.HPP = &hpp
-	}
-
-	if err := s.vehicleRepo.Update(ctx, vehicle); err != nil {
-		return fmt.Errorf("failed to update vehicle: %w", err)
-	}
-
-	return nil
+	return s.vehicleService.CalculateHPP(ctx, vehicleID)
 }
 
 func (s *workOrderService) AssignMechanic(ctx context.Context, id int, mechanicID int) error {
@@ -223,69 +435,325 @@ func (s *workOrderService) AssignMechanic(ctx context.Context, id int, mechanicI
 	if err != nil {
 		return fmt.Errorf("failed to get mechanic: %w", err)
 	}
+	if mechanic == nil {
+		return fmt.Errorf("mechanic not found")
+	}
 	if mechanic.Role != domain.RoleMekanik {
 		return fmt.Errorf("user is not a mechanic")
 	}
 
 	// Get work order
-	workOrder, err := s.workOrderRepo.GetByID(ctx, id)
-	if err != nil {
+	if _, err := s.workOrderRepo.GetByID(ctx, id); err != nil {
 		return fmt.Errorf("failed to get work order: %w", err)
 	}
 
 	// Update assigned mechanic
-	workOrder.AssignedMechanicID = mechanicID
-	if err := s.workOrderRepo.Update(ctx, workOrder); err != nil {
+	if err := s.workOrderRepo.AssignMechanic(ctx, id, mechanicID, "manually reassigned"); err != nil {
 		return fmt.Errorf("failed to assign mechanic: %w", err)
 	}
 
 	return nil
 }
 
-func (s *workOrderService) UsePartInWorkOrder(ctx context.Context, workOrderID int, partID int, quantity int, usedBy int) error {
+func (s *workOrderService) UsePartInWorkOrder(ctx context.Context, workOrderID int, partID int, locationID *int, quantity int, serialNumbers []string, usedBy int) (*domain.WorkOrderPart, error) {
+	workOrderPart, err := s.prepareWorkOrderPart(ctx, workOrderID, partID, locationID, quantity, serialNumbers, usedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	// Stock, cost layers, batches and serials move with the usage line, costed
+	// according to the configured costing method
+	if err := s.workOrderPartRepo.IssueBatch(ctx, []*domain.WorkOrderPart{workOrderPart}, s.costingMethod); err != nil {
+		return nil, fmt.Errorf("failed to issue spare part: %w", err)
+	}
+
+	// Update work order total parts cost
+	if err := s.updateWorkOrderPartsCost(ctx, workOrderID); err != nil {
+		return nil, fmt.Errorf("failed to update work order parts cost: %w", err)
+	}
+
+	return workOrderPart, nil
+}
+
+// IssuePartRequest issues the parts approved on a mechanic's request. The
+// request review and the stock issue are written in one transaction, so a
+// failed issue leaves the request pending.
+func (s *workOrderService) IssuePartRequest(ctx context.Context, request *domain.PartRequest, locationID *int, serialNumbers []string) (*domain.WorkOrderPart, error) {
+	workOrderPart, err := s.prepareWorkOrderPart(ctx, request.WorkOrderID, request.SparePartID, locationID, request.QuantityIssued, serialNumbers, request.RequestedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.workOrderPartRepo.IssuePartRequest(ctx, request, workOrderPart, s.costingMethod); err != nil {
+		return nil, fmt.Errorf("failed to issue parts: %w", err)
+	}
+
+	if err := s.updateWorkOrderPartsCost(ctx, request.WorkOrderID); err != nil {
+		return nil, fmt.Errorf("failed to update work order parts cost: %w", err)
+	}
+
+	return workOrderPart, nil
+}
+
+// prepareWorkOrderPart checks stock, fitment and serial numbers for issuing a
+// part to an open work order and builds the usage line without writing it
+func (s *workOrderService) prepareWorkOrderPart(ctx context.Context, workOrderID int, partID int, locationID *int, quantity int, serialNumbers []string, usedBy int) (*domain.WorkOrderPart, error) {
+	// Parts can only be issued to work orders that are still open
+	workOrder, err := s.getOpenWorkOrder(ctx, workOrderID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get spare part
 	sparePart, err := s.sparePartRepo.GetByID(ctx, partID)
 	if err != nil {
-		return fmt.Errorf("failed to get spare part: %w", err)
+		return nil, fmt.Errorf("failed to get spare part: %w", err)
+	}
+
+	// Parts not listed as fitting the vehicle are issued with a warning
+	vehicle, err := workOrderFitmentVehicle(ctx, s.vehicleRepo, s.customerVehicleRepo, workOrder)
+	if err != nil {
+		return nil, err
+	}
+	fitmentWarning, err := checkPartFitment(ctx, s.fitmentRepo, sparePart, vehicle)
+	if err != nil {
+		return nil, err
 	}
 
 	// Check if enough stock
 	if sparePart.StockQuantity < quantity {
-		return fmt.Errorf("insufficient stock: available %d, requested %d", sparePart.StockQuantity, quantity)
+		return nil, fmt.Errorf("insufficient stock: available %d, requested %d", sparePart.StockQuantity, quantity)
 	}
 
-	// Calculate costs
-	unitCost := sparePart.CostPrice
-	totalCost := unitCost * float64(quantity)
+	// Parts are issued from one location (default location when none is given)
+	location, err := resolveStockLocation(ctx, s.stockLocationRepo, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	stock, err := s.stockLocationRepo.GetStock(ctx, partID, location.ID)
+	if err != nil {
+		return nil, err
+	}
+	if stock == nil || stock.Quantity < quantity {
+		available := 0
+		if stock != nil {
+			available = stock.Quantity
+		}
+		return nil, fmt.Errorf("insufficient stock at %s: available %d, requested %d", location.Code, available, quantity)
+	}
+
+	serialNumbers, err = normalizeSerialNumbers(sparePart, quantity, serialNumbers)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create work order part record
 	workOrderPart := &domain.WorkOrderPart{
-		WorkOrderID:  workOrderID,
-		SparePartID:  partID,
-		QuantityUsed: quantity,
-		UnitCost:     unitCost,
-		TotalCost:    totalCost,
-		UsedBy:       usedBy,
-		UsageDate:    time.Now(),
-		UsedAt:       time.Now(),
+		WorkOrderID:    workOrderID,
+		SparePartID:    partID,
+		QuantityUsed:   quantity,
+		UnitPrice:      sparePart.SellingPrice,
+		LocationID:     &location.ID,
+		UsedBy:         usedBy,
+		UsageDate:      time.Now(),
+		UsedAt:         time.Now(),
+		FitmentWarning: fitmentWarning,
+		SerialNumbers:  serialNumbers,
+	}
+
+	return workOrderPart, nil
+}
+
+// ApplyServiceKit issues every part of a kit to the work order from one
+// location. The kit's standard labor becomes a task, and on customer jobs its
+// labor price is added to the labor billed. Stock is checked for all lines up
+// front and the parts, task and labor price are written in one transaction,
+// so either the whole kit is applied or nothing is.
+func (s *workOrderService) ApplyServiceKit(ctx context.Context, workOrderID int, kitID int, locationID *int, usedBy int) (*domain.ServiceKitApplication, error) {
+	workOrder, err := s.getOpenWorkOrder(ctx, workOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	kit, err := s.serviceKitRepo.GetByID(ctx, kitID)
+	if err != nil {
+		return nil, err
+	}
+	if kit == nil {
+		return nil, fmt.Errorf("service kit not found")
+	}
+	if !kit.IsActive {
+		return nil, fmt.Errorf("service kit %s is inactive", kit.KitCode)
 	}
 
-	if err := s.workOrderPartRepo.Create(ctx, workOrderPart); err != nil {
-		return fmt.Errorf("failed to create work order part record: %w", err)
+	kit.Items, err = s.serviceKitRepo.ListItems(ctx, kit.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(kit.Items) == 0 {
+		return nil, fmt.Errorf("service kit %s has no parts", kit.KitCode)
 	}
 
-	// Reduce stock
-	newStock := sparePart.StockQuantity - quantity
-	if err := s.sparePartRepo.UpdateStock(ctx, partID, newStock); err != nil {
-		return fmt.Errorf("failed to update spare part stock: %w", err)
+	location, err := resolveStockLocation(ctx, s.stockLocationRepo, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	vehicle, err := workOrderFitmentVehicle(ctx, s.vehicleRepo, s.customerVehicleRepo, workOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	application := &domain.ServiceKitApplication{
+		WorkOrderID: workOrderID,
+		ServiceKit:  kit,
+	}
+
+	// Check every line before issuing anything
+	var shortages []string
+	now := time.Now()
+	for _, item := range kit.Items {
+		sparePart, err := s.sparePartRepo.GetByID(ctx, item.SparePartID)
+		if err != nil {
+			return nil, fmt.Errorf("spare part %d not found", item.SparePartID)
+		}
+		if sparePart.TrackSerials {
+			return nil, fmt.Errorf("%s is serial-tracked; issue it on its own with its serial numbers", sparePart.PartCode)
+		}
+
+		stock, err := s.stockLocationRepo.GetStock(ctx, sparePart.ID, location.ID)
+		if err != nil {
+			return nil, err
+		}
+		available := 0
+		if stock != nil {
+			available = stock.Quantity
+		}
+		if available < item.Quantity {
+			shortages = append(shortages, fmt.Sprintf("%s (available %d, required %d)", sparePart.PartCode, available, item.Quantity))
+			continue
+		}
+
+		fitmentWarning, err := checkPartFitment(ctx, s.fitmentRepo, sparePart, vehicle)
+		if err != nil {
+			return nil, err
+		}
+		if fitmentWarning != nil {
+			application.FitmentWarnings = append(application.FitmentWarnings, *fitmentWarning)
+		}
+
+		application.Parts = append(application.Parts, &domain.WorkOrderPart{
+			WorkOrderID:    workOrderID,
+			SparePartID:    sparePart.ID,
+			QuantityUsed:   item.Quantity,
+			UnitPrice:      sparePart.SellingPrice,
+			LocationID:     &location.ID,
+			ServiceKitID:   &kit.ID,
+			UsedBy:         usedBy,
+			UsageDate:      now,
+			UsedAt:         now,
+			FitmentWarning: fitmentWarning,
+			SparePart:      sparePart,
+		})
+	}
+	if len(shortages) > 0 {
+		return nil, fmt.Errorf("insufficient stock at %s: %s", location.Code, strings.Join(shortages, ", "))
+	}
+
+	// The task and labor price are settled before anything is written, so
+	// the parts, task and labor price are applied together or not at all
+	if kit.LaborHours > 0 {
+		task := &domain.WorkOrderTask{
+			WorkOrderID:    workOrderID,
+			Title:          kit.Name,
+			EstimatedHours: kit.LaborHours,
+			Notes:          kit.Description,
+			CreatedBy:      usedBy,
+		}
+		if err := s.prepareTask(ctx, workOrder, task); err != nil {
+			return nil, fmt.Errorf("invalid service kit task: %w", err)
+		}
+		application.Task = task
+	}
+	if kit.LaborPrice > 0 && workOrder.OrderType == domain.WorkOrderTypeCustomerService {
+		application.LaborPriceAdded = kit.LaborPrice
+	}
+
+	if err := s.workOrderPartRepo.IssueServiceKit(ctx, application, s.costingMethod); err != nil {
+		return nil, fmt.Errorf("failed to issue service kit: %w", err)
 	}
 
-	// Update work order total parts cost
 	if err := s.updateWorkOrderPartsCost(ctx, workOrderID); err != nil {
-		return fmt.Errorf("failed to update work order parts cost: %w", err)
+		return nil, fmt.Errorf("failed to update work order parts cost: %w", err)
 	}
 
-	return nil
+	if application.Task != nil {
+		if err := s.refreshTaskProgress(ctx, workOrderID); err != nil {
+			return nil, err
+		}
+	}
+
+	return application, nil
+}
+
+// ReturnPart puts unused parts from a work order back into stock at the cost
+// they were issued at. The work order parts cost is recomputed, and so is the
+// vehicle HPP when the work order is already completed.
+func (s *workOrderService) ReturnPart(ctx context.Context, workOrderPartID int, quantity int, reason string, serialNumbers []string, returnedBy int) (*domain.WorkOrderPartReturn, error) {
+	if quantity <= 0 {
+		return nil, fmt.Errorf("quantity must be greater than 0")
+	}
+	if reason == "" {
+		return nil, fmt.Errorf("return reason is required")
+	}
+
+	workOrderPart, err := s.workOrderPartRepo.GetByID(ctx, workOrderPartID)
+	if err != nil {
+		return nil, fmt.Errorf("work order part not found: %w", err)
+	}
+
+	remaining := workOrderPart.QuantityUsed - workOrderPart.QuantityReturned
+	if quantity > remaining {
+		return nil, fmt.Errorf("cannot return more than still used: %d", remaining)
+	}
+
+	workOrder, err := s.getOpenWorkOrder(ctx, workOrderPart.WorkOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	sparePart, err := s.sparePartRepo.GetByID(ctx, workOrderPart.SparePartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spare part: %w", err)
+	}
+	serialNumbers, err = normalizeSerialNumbers(sparePart, quantity, serialNumbers)
+	if err != nil {
+		return nil, err
+	}
+
+	partReturn := &domain.WorkOrderPartReturn{
+		WorkOrderPartID: workOrderPart.ID,
+		WorkOrderID:     workOrderPart.WorkOrderID,
+		SparePartID:     workOrderPart.SparePartID,
+		Quantity:        quantity,
+		UnitCost:        workOrderPart.UnitCost,
+		TotalCost:       roundCost(workOrderPart.UnitCost * float64(quantity)),
+		Reason:          reason,
+		ReturnedBy:      returnedBy,
+		ReturnedAt:      time.Now(),
+		SerialNumbers:   serialNumbers,
+	}
+
+	if err := s.workOrderPartRepo.Return(ctx, partReturn, s.costingMethod); err != nil {
+		return nil, err
+	}
+
+	if err := s.updateWorkOrderPartsCost(ctx, workOrder.ID); err != nil {
+		return nil, fmt.Errorf("failed to update work order parts cost: %w", err)
+	}
+
+	return partReturn, nil
 }
 
 func (s *workOrderService) updateWorkOrderPartsCost(ctx context.Context, workOrderID int) error {
@@ -301,18 +769,221 @@ func (s *workOrderService) updateWorkOrderPartsCost(ctx context.Context, workOrd
 		totalPartsCost += part.TotalCost
 	}
 
-	// Get work order and update
-	workOrder, err := s.workOrderRepo.GetByID(ctx, workOrderID)
+	if err := s.workOrderRepo.UpdatePartsCost(ctx, workOrderID, totalPartsCost); err != nil {
+		return fmt.Errorf("failed to update work order: %w", err)
+	}
+
+	return nil
+}
+
+// AddTask adds a job to the work order checklist. Without an assigned
+// mechanic the task goes to the work order's mechanic.
+func (s *workOrderService) AddTask(ctx context.Context, task *domain.WorkOrderTask) error {
+	workOrder, err := s.getOpenWorkOrder(ctx, task.WorkOrderID)
 	if err != nil {
-		return fmt.Errorf("failed to get work order: %w", err)
+		return err
 	}
 
-	workOrder.TotalPartsCost = totalPartsCost
-	workOrder.TotalCost = workOrder.TotalPartsCost + workOrder.LaborCost
+	if err := s.prepareTask(ctx, workOrder, task); err != nil {
+		return err
+	}
 
-	if err := s.workOrderRepo.Update(ctx, workOrder); err != nil {
-		return fmt.Errorf("failed to update work order: %w", err)
+	if err := s.workOrderTaskRepo.Create(ctx, task); err != nil {
+		return err
+	}
+
+	return s.refreshTaskProgress(ctx, task.WorkOrderID)
+}
+
+// prepareTask validates a new task and fills in its mechanic and status
+func (s *workOrderService) prepareTask(ctx context.Context, workOrder *domain.WorkOrder, task *domain.WorkOrderTask) error {
+	if task.EstimatedHours < 0 {
+		return fmt.Errorf("estimated hours cannot be negative")
+	}
+
+	if task.AssignedMechanicID == nil {
+		task.AssignedMechanicID = &workOrder.AssignedMechanicID
+	} else if err := s.validateTaskMechanic(ctx, *task.AssignedMechanicID); err != nil {
+		return err
 	}
 
+	task.Status = domain.WorkOrderTaskStatusPending
+
 	return nil
-}
\ No newline at end of file
+}
+
+func (s *workOrderService) ListTasks(ctx context.Context, workOrderID int) ([]*domain.WorkOrderTask, error) {
+	return s.workOrderTaskRepo.ListByWorkOrderID(ctx, workOrderID)
+}
+
+func (s *workOrderService) ListOpenTasksByMechanic(ctx context.Context, mechanicID int) ([]*domain.WorkOrderTask, error) {
+	return s.workOrderTaskRepo.ListOpenByMechanic(ctx, mechanicID)
+}
+
+// UpdateTask changes the details of a task; its status is changed with
+// UpdateTaskStatus
+func (s *workOrderService) UpdateTask(ctx context.Context, task *domain.WorkOrderTask) error {
+	existing, err := s.getTask(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.getOpenWorkOrder(ctx, existing.WorkOrderID); err != nil {
+		return err
+	}
+
+	if task.EstimatedHours < 0 {
+		return fmt.Errorf("estimated hours cannot be negative")
+	}
+	if task.AssignedMechanicID != nil && (existing.AssignedMechanicID == nil || *task.AssignedMechanicID != *existing.AssignedMechanicID) {
+		if err := s.validateTaskMechanic(ctx, *task.AssignedMechanicID); err != nil {
+			return err
+		}
+	}
+
+	existing.Title = task.Title
+	existing.EstimatedHours = task.EstimatedHours
+	existing.AssignedMechanicID = task.AssignedMechanicID
+	existing.Notes = task.Notes
+	existing.SortOrder = task.SortOrder
+
+	if err := s.workOrderTaskRepo.Update(ctx, existing); err != nil {
+		return err
+	}
+	*task = *existing
+
+	return s.refreshTaskProgress(ctx, existing.WorkOrderID)
+}
+
+// UpdateTaskStatus moves a task along the checklist and recomputes the work
+// order progress
+func (s *workOrderService) UpdateTaskStatus(ctx context.Context, id int, status domain.WorkOrderTaskStatus, changedBy int) (*domain.WorkOrderTask, error) {
+	task, err := s.getTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.getOpenWorkOrder(ctx, task.WorkOrderID); err != nil {
+		return nil, err
+	}
+
+	task.Status = status
+	task.CompletedAt = nil
+	task.CompletedBy = nil
+	if status == domain.WorkOrderTaskStatusCompleted {
+		now := time.Now()
+		task.CompletedAt = &now
+		task.CompletedBy = &changedBy
+	}
+
+	if err := s.workOrderTaskRepo.Update(ctx, task); err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshTaskProgress(ctx, task.WorkOrderID); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+func (s *workOrderService) DeleteTask(ctx context.Context, id int, deletedBy int) error {
+	task, err := s.getTask(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, err := s.getOpenWorkOrder(ctx, task.WorkOrderID); err != nil {
+		return err
+	}
+
+	if err := s.workOrderTaskRepo.Delete(ctx, id, deletedBy); err != nil {
+		return err
+	}
+
+	return s.refreshTaskProgress(ctx, task.WorkOrderID)
+}
+
+func (s *workOrderService) getTask(ctx context.Context, id int) (*domain.WorkOrderTask, error) {
+	task, err := s.workOrderTaskRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, fmt.Errorf("work order task not found")
+	}
+
+	return task, nil
+}
+
+// getOpenWorkOrder returns the work order if its checklist and parts can
+// still change
+func (s *workOrderService) getOpenWorkOrder(ctx context.Context, id int) (*domain.WorkOrder, error) {
+	workOrder, err := s.workOrderRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get work order: %w", err)
+	}
+	if workOrder.Status == domain.WorkOrderStatusCompleted || workOrder.Status == domain.WorkOrderStatusCancelled {
+		return nil, fmt.Errorf("work order is already %s", workOrder.Status)
+	}
+
+	return workOrder, nil
+}
+
+func (s *workOrderService) validateTaskMechanic(ctx context.Context, mechanicID int) error {
+	mechanic, err := s.userRepo.GetByID(ctx, mechanicID)
+	if err != nil {
+		return fmt.Errorf("failed to get mechanic: %w", err)
+	}
+	if mechanic == nil {
+		return fmt.Errorf("mechanic not found")
+	}
+	if mechanic.Role != domain.RoleMekanik {
+		return fmt.Errorf("user is not a mechanic")
+	}
+
+	return nil
+}
+
+// refreshTaskProgress derives the work order progress from its tasks.
+// Skipped tasks don't count; with no counted tasks left progress is 0. Tasks
+// are weighted by estimated hours when every task has an estimate, otherwise
+// each task counts the same.
+func (s *workOrderService) refreshTaskProgress(ctx context.Context, workOrderID int) error {
+	tasks, err := s.workOrderTaskRepo.ListByWorkOrderID(ctx, workOrderID)
+	if err != nil {
+		return err
+	}
+
+	weighted := true
+	var counted []*domain.WorkOrderTask
+	for _, task := range tasks {
+		if task.Status == domain.WorkOrderTaskStatusSkipped {
+			continue
+		}
+		counted = append(counted, task)
+		if task.EstimatedHours <= 0 {
+			weighted = false
+		}
+	}
+
+	// With every task skipped or deleted there is nothing left to derive from
+	progress := 0
+	if len(counted) > 0 {
+		var total, done float64
+		for _, task := range counted {
+			weight := 1.0
+			if weighted {
+				weight = task.EstimatedHours
+			}
+			total += weight
+			if task.Status == domain.WorkOrderTaskStatusCompleted {
+				done += weight
+			}
+		}
+		progress = int(done * 100 / total)
+	}
+
+	if err := s.workOrderRepo.UpdateProgress(ctx, workOrderID, progress); err != nil {
+		return fmt.Errorf("failed to update work order progress: %w", err)
+	}
+
+	return nil
+}
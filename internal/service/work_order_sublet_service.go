@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"pos-final/internal/domain"
+	"pos-final/internal/repository"
+	"strings"
+	"time"
+)
+
+// Vendors are paid this many days after their sublet invoice unless a due
+// date is given
+const defaultSubletPaymentDays = 30
+
+type workOrderSubletService struct {
+	subletRepo     repository.WorkOrderSubletRepository
+	workOrderRepo  repository.WorkOrderRepository
+	supplierRepo   repository.SupplierRepository
+	payableService PayableService
+	fileService    FileService
+}
+
+// NewWorkOrderSubletService creates a new work order sublet service
+func NewWorkOrderSubletService(
+	subletRepo repository.WorkOrderSubletRepository,
+	workOrderRepo repository.WorkOrderRepository,
+	supplierRepo repository.SupplierRepository,
+	payableService PayableService,
+	fileService FileService,
+) WorkOrderSubletService {
+	return &workOrderSubletService{
+		subletRepo:     subletRepo,
+		workOrderRepo:  workOrderRepo,
+		supplierRepo:   supplierRepo,
+		payableService: payableService,
+		fileService:    fileService,
+	}
+}
+
+// CreateSublet records work sent to an outside vendor. The quoted cost is
+// added to the work order cost straight away.
+func (s *workOrderSubletService) CreateSublet(ctx context.Context, sublet *domain.WorkOrderSublet) error {
+	sublet.Description = strings.TrimSpace(sublet.Description)
+	if sublet.Description == "" {
+		return fmt.Errorf("description is required")
+	}
+	if sublet.Cost < 0 {
+		return fmt.Errorf("cost cannot be negative")
+	}
+
+	if err := s.requireOpenWorkOrder(ctx, sublet.WorkOrderID); err != nil {
+		return err
+	}
+
+	supplier, err := s.supplierRepo.GetByID(ctx, sublet.SupplierID)
+	if err != nil {
+		return fmt.Errorf("failed to get supplier: %w", err)
+	}
+	if supplier == nil {
+		return fmt.Errorf("supplier not found")
+	}
+
+	sublet.Status = domain.SubletStatusSent
+	if err := s.subletRepo.Create(ctx, sublet); err != nil {
+		return err
+	}
+	sublet.SupplierName = supplier.Name
+
+	return s.refreshWorkOrderSubletCost(ctx, sublet.WorkOrderID)
+}
+
+func (s *workOrderSubletService) GetSubletByID(ctx context.Context, id int) (*domain.WorkOrderSublet, error) {
+	sublet, err := s.subletRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sublet == nil {
+		return nil, fmt.Errorf("work order sublet not found")
+	}
+
+	if sublet.Status == domain.SubletStatusCompleted {
+		sublet.Payable, err = s.payableService.GetPayableByWorkOrderSubletID(ctx, sublet.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	s.setAttachmentURL(sublet)
+
+	return sublet, nil
+}
+
+func (s *workOrderSubletService) ListSublets(ctx context.Context, workOrderID int) ([]*domain.WorkOrderSublet, error) {
+	sublets, err := s.subletRepo.ListByWorkOrderID(ctx, workOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sublet := range sublets {
+		s.setAttachmentURL(sublet)
+	}
+
+	return sublets, nil
+}
+
+// CompleteSublet records the vendor's invoice once the work is back. The
+// invoiced cost replaces the quote and a payable to the vendor is opened.
+func (s *workOrderSubletService) CompleteSublet(ctx context.Context, id int, vendorInvoiceNumber string, cost *float64, dueDate *time.Time, completedBy int) (*domain.WorkOrderSublet, error) {
+	vendorInvoiceNumber = strings.TrimSpace(vendorInvoiceNumber)
+	if vendorInvoiceNumber == "" {
+		return nil, fmt.Errorf("vendor invoice number is required")
+	}
+
+	sublet, err := s.GetSubletByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sublet.Status != domain.SubletStatusSent {
+		return nil, fmt.Errorf("sublet is already %s", sublet.Status)
+	}
+
+	if cost != nil {
+		if *cost < 0 {
+			return nil, fmt.Errorf("cost cannot be negative")
+		}
+		sublet.Cost = *cost
+	}
+
+	now := time.Now()
+	due := now.AddDate(0, 0, defaultSubletPaymentDays)
+	if dueDate != nil {
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		if dueDate.Before(today) {
+			return nil, fmt.Errorf("due date cannot be in the past")
+		}
+		due = *dueDate
+	}
+
+	sublet.VendorInvoiceNumber = &vendorInvoiceNumber
+	sublet.CompletedAt = &now
+	sublet.CompletedBy = &completedBy
+	sublet.Payable = newSubletPayable(sublet, due)
+
+	// The sublet is closed and its vendor payable opened together
+	if err := s.subletRepo.Complete(ctx, sublet); err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshWorkOrderSubletCost(ctx, sublet.WorkOrderID); err != nil {
+		return nil, err
+	}
+
+	return sublet, nil
+}
+
+// CancelSublet drops a sublet that is still with the vendor and takes its
+// cost off the work order
+func (s *workOrderSubletService) CancelSublet(ctx context.Context, id int) error {
+	sublet, err := s.GetSubletByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.subletRepo.Cancel(ctx, id); err != nil {
+		return err
+	}
+
+	return s.refreshWorkOrderSubletCost(ctx, sublet.WorkOrderID)
+}
+
+// AttachInvoice stores the scanned vendor invoice of a sublet
+func (s *workOrderSubletService) AttachInvoice(ctx context.Context, id int, attachmentPath string) (*domain.WorkOrderSublet, error) {
+	sublet, err := s.GetSubletByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.subletRepo.UpdateAttachment(ctx, id, attachmentPath); err != nil {
+		return nil, err
+	}
+
+	if sublet.AttachmentPath != nil {
+		_ = s.fileService.DeleteFile(ctx, *sublet.AttachmentPath)
+	}
+	sublet.AttachmentPath = &attachmentPath
+	s.setAttachmentURL(sublet)
+
+	return sublet, nil
+}
+
+// refreshWorkOrderSubletCost sets the work order sublet cost to the total of
+// its sublets that are not cancelled
+func (s *workOrderSubletService) refreshWorkOrderSubletCost(ctx context.Context, workOrderID int) error {
+	subletCost, err := s.subletRepo.SumCostByWorkOrder(ctx, workOrderID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.workOrderRepo.UpdateSubletCost(ctx, workOrderID, subletCost); err != nil {
+		return fmt.Errorf("failed to update work order sublet cost: %w", err)
+	}
+
+	return nil
+}
+
+func (s *workOrderSubletService) requireOpenWorkOrder(ctx context.Context, workOrderID int) error {
+	workOrder, err := s.workOrderRepo.GetByID(ctx, workOrderID)
+	if err != nil {
+		return fmt.Errorf("failed to get work order: %w", err)
+	}
+	if workOrder.Status == domain.WorkOrderStatusCompleted || workOrder.Status == domain.WorkOrderStatusCancelled {
+		return fmt.Errorf("work order is %s", workOrder.Status)
+	}
+
+	return nil
+}
+
+func (s *workOrderSubletService) setAttachmentURL(sublet *domain.WorkOrderSublet) {
+	if sublet.AttachmentPath == nil {
+		return
+	}
+	url := s.fileService.GetFileURL(*sublet.AttachmentPath)
+	sublet.AttachmentURL = &url
+}
@@ -0,0 +1,68 @@
+-- Post-sale warranty tracking and warranty claim work orders
+
+-- Tabel Warranties (garansi purna jual per penjualan)
+CREATE TABLE IF NOT EXISTS warranties (
+    id SERIAL PRIMARY KEY,
+    warranty_number VARCHAR(30) UNIQUE NOT NULL, -- WAR-20250724-0001
+    sales_invoice_id INTEGER NOT NULL,
+    vehicle_id INTEGER NOT NULL,
+    customer_id INTEGER NOT NULL,
+    duration_months INTEGER NOT NULL DEFAULT 3,
+    mileage_limit INTEGER, -- batas km sejak serah terima, NULL = tanpa batas
+    start_mileage INTEGER, -- odometer saat serah terima
+    coverage TEXT NOT NULL, -- "mesin", "mesin + transmisi"
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    status VARCHAR(20) CHECK (status IN ('active', 'expired', 'void')) DEFAULT 'active',
+    notes TEXT,
+    created_by INTEGER NOT NULL,
+
+    -- Soft Delete
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_by INTEGER NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (sales_invoice_id) REFERENCES sales_invoices(id),
+    FOREIGN KEY (vehicle_id) REFERENCES vehicles(id),
+    FOREIGN KEY (customer_id) REFERENCES customers(id),
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (deleted_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_warranties_deleted_at ON warranties(deleted_at);
+CREATE INDEX idx_warranties_vehicle ON warranties(vehicle_id);
+CREATE UNIQUE INDEX idx_warranties_sales_invoice ON warranties(sales_invoice_id) WHERE deleted_at IS NULL;
+
+-- Tabel Warranty Claims (klaim garansi, selalu membuka work order)
+CREATE TABLE IF NOT EXISTS warranty_claims (
+    id SERIAL PRIMARY KEY,
+    claim_number VARCHAR(30) UNIQUE NOT NULL, -- WCL-20250724-0001
+    warranty_id INTEGER NOT NULL,
+    work_order_id INTEGER,
+    claim_date DATE NOT NULL,
+    complaint TEXT NOT NULL, -- keluhan customer
+    current_mileage INTEGER,
+    created_by INTEGER NOT NULL,
+
+    -- Soft Delete
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_by INTEGER NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (warranty_id) REFERENCES warranties(id),
+    FOREIGN KEY (work_order_id) REFERENCES work_orders(id),
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (deleted_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_warranty_claims_deleted_at ON warranty_claims(deleted_at);
+CREATE INDEX idx_warranty_claims_warranty ON warranty_claims(warranty_id);
+CREATE INDEX idx_warranty_claims_claim_date ON warranty_claims(claim_date);
+
+-- Work order garansi: biaya masuk beban garansi, bukan HPP kendaraan
+ALTER TABLE work_orders ADD COLUMN IF NOT EXISTS is_warranty BOOLEAN DEFAULT FALSE;
+
+CREATE TRIGGER update_warranties_updated_at BEFORE UPDATE ON warranties FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,101 @@
+-- Spare-part purchase orders and goods receipt
+
+-- Tabel Purchase Orders (pemesanan spare part ke supplier)
+CREATE TABLE IF NOT EXISTS purchase_orders (
+    id SERIAL PRIMARY KEY,
+    po_number VARCHAR(30) UNIQUE NOT NULL, -- PO-20250724-0001
+    supplier_id INTEGER NOT NULL,
+    status VARCHAR(20) CHECK (status IN ('draft', 'ordered', 'partially_received', 'received', 'cancelled')) DEFAULT 'draft',
+    order_date DATE, -- diisi saat PO dikirim ke supplier
+    expected_date DATE,
+    total_amount DECIMAL(15,2) DEFAULT 0, -- estimasi nilai PO
+    notes TEXT,
+    created_by INTEGER NOT NULL,
+
+    -- Soft Delete
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_by INTEGER NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (supplier_id) REFERENCES suppliers(id),
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (deleted_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_purchase_orders_deleted_at ON purchase_orders(deleted_at);
+CREATE INDEX idx_purchase_orders_status ON purchase_orders(status);
+CREATE INDEX idx_purchase_orders_supplier ON purchase_orders(supplier_id);
+
+-- Tabel Purchase Order Items
+CREATE TABLE IF NOT EXISTS purchase_order_items (
+    id SERIAL PRIMARY KEY,
+    purchase_order_id INTEGER NOT NULL,
+    spare_part_id INTEGER NOT NULL,
+    quantity_ordered INTEGER NOT NULL CHECK (quantity_ordered > 0),
+    quantity_received INTEGER NOT NULL DEFAULT 0, -- sisa = backorder
+    unit_cost DECIMAL(12,2) NOT NULL, -- harga yang diharapkan
+    total_cost DECIMAL(15,2) NOT NULL,
+
+    -- Soft Delete
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_by INTEGER NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (purchase_order_id) REFERENCES purchase_orders(id),
+    FOREIGN KEY (spare_part_id) REFERENCES spare_parts(id),
+    FOREIGN KEY (deleted_by) REFERENCES users(id),
+    CHECK (quantity_received <= quantity_ordered)
+);
+
+CREATE INDEX idx_purchase_order_items_deleted_at ON purchase_order_items(deleted_at);
+CREATE INDEX idx_purchase_order_items_po ON purchase_order_items(purchase_order_id);
+
+-- Tabel Goods Receipts (penerimaan barang, bisa beberapa kali per PO)
+CREATE TABLE IF NOT EXISTS goods_receipts (
+    id SERIAL PRIMARY KEY,
+    receipt_number VARCHAR(30) UNIQUE NOT NULL, -- GR-20250724-0001
+    purchase_order_id INTEGER NOT NULL,
+    supplier_invoice_number VARCHAR(50),
+    received_date DATE NOT NULL,
+    notes TEXT,
+    received_by INTEGER NOT NULL,
+
+    -- Soft Delete
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_by INTEGER NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (purchase_order_id) REFERENCES purchase_orders(id),
+    FOREIGN KEY (received_by) REFERENCES users(id),
+    FOREIGN KEY (deleted_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_goods_receipts_deleted_at ON goods_receipts(deleted_at);
+CREATE INDEX idx_goods_receipts_po ON goods_receipts(purchase_order_id);
+
+-- Tabel Goods Receipt Items
+CREATE TABLE IF NOT EXISTS goods_receipt_items (
+    id SERIAL PRIMARY KEY,
+    goods_receipt_id INTEGER NOT NULL,
+    purchase_order_item_id INTEGER NOT NULL,
+    spare_part_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_cost DECIMAL(12,2) NOT NULL, -- harga aktual dari supplier
+    total_cost DECIMAL(15,2) NOT NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (goods_receipt_id) REFERENCES goods_receipts(id),
+    FOREIGN KEY (purchase_order_item_id) REFERENCES purchase_order_items(id),
+    FOREIGN KEY (spare_part_id) REFERENCES spare_parts(id)
+);
+
+CREATE INDEX idx_goods_receipt_items_receipt ON goods_receipt_items(goods_receipt_id);
+
+CREATE TRIGGER update_purchase_orders_updated_at BEFORE UPDATE ON purchase_orders FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_purchase_order_items_updated_at BEFORE UPDATE ON purchase_order_items FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
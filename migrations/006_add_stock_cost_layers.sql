@@ -0,0 +1,26 @@
+-- Weighted-average and FIFO inventory costing for spare parts
+
+-- Tabel Stock Cost Layers (lapisan biaya per penerimaan barang, dipakai FIFO)
+CREATE TABLE IF NOT EXISTS stock_cost_layers (
+    id SERIAL PRIMARY KEY,
+    spare_part_id INTEGER NOT NULL,
+    reference_type VARCHAR(20) CHECK (reference_type IN ('work_order', 'purchase', 'adjustment')) NOT NULL,
+    reference_id INTEGER, -- goods_receipt, work_order (retur), dll
+    received_date DATE NOT NULL,
+    quantity_received INTEGER NOT NULL CHECK (quantity_received > 0),
+    quantity_remaining INTEGER NOT NULL CHECK (quantity_remaining >= 0),
+    unit_cost DECIMAL(12,2) NOT NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (spare_part_id) REFERENCES spare_parts(id),
+    CHECK (quantity_remaining <= quantity_received)
+);
+
+CREATE INDEX idx_stock_cost_layers_part_open ON stock_cost_layers(spare_part_id, received_date, id) WHERE quantity_remaining > 0;
+
+-- Opening layer untuk stok yang sudah ada, dinilai dengan cost_price saat ini
+INSERT INTO stock_cost_layers (spare_part_id, reference_type, received_date, quantity_received, quantity_remaining, unit_cost)
+SELECT id, 'adjustment', CURRENT_DATE, stock_quantity, stock_quantity, cost_price
+FROM spare_parts
+WHERE stock_quantity > 0 AND deleted_at IS NULL;
@@ -0,0 +1,71 @@
+-- Accounts payable for suppliers and vehicle sellers
+
+-- Tabel Payables (hutang per purchase invoice)
+CREATE TABLE IF NOT EXISTS payables (
+    id SERIAL PRIMARY KEY,
+    purchase_invoice_id INTEGER UNIQUE NOT NULL,
+    amount DECIMAL(15,2) NOT NULL, -- = final_price purchase invoice
+    paid_amount DECIMAL(15,2) NOT NULL DEFAULT 0,
+    due_date DATE NOT NULL,
+    status VARCHAR(20) CHECK (status IN ('unpaid', 'partially_paid', 'paid')) DEFAULT 'unpaid',
+    overdue_notified_at TIMESTAMP NULL, -- notifikasi jatuh tempo hanya dikirim sekali
+    notes TEXT,
+
+    -- Soft Delete
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_by INTEGER NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (purchase_invoice_id) REFERENCES purchase_invoices(id),
+    FOREIGN KEY (deleted_by) REFERENCES users(id),
+    CHECK (paid_amount <= amount)
+);
+
+CREATE INDEX idx_payables_deleted_at ON payables(deleted_at);
+CREATE INDEX idx_payables_status ON payables(status);
+CREATE INDEX idx_payables_due_date ON payables(due_date);
+
+-- Tabel Payable Payments (cicilan pembayaran, masing-masing dengan bukti transfer)
+CREATE TABLE IF NOT EXISTS payable_payments (
+    id SERIAL PRIMARY KEY,
+    payment_number VARCHAR(30) UNIQUE NOT NULL, -- PAY-20250724-0001
+    payable_id INTEGER NOT NULL,
+    amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+    payment_date DATE NOT NULL,
+    payment_method VARCHAR(20) CHECK (payment_method IN ('cash', 'transfer')) NOT NULL,
+    transfer_proof VARCHAR(255),
+    notes TEXT,
+    created_by INTEGER NOT NULL,
+
+    -- Soft Delete
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_by INTEGER NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (payable_id) REFERENCES payables(id),
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (deleted_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_payable_payments_deleted_at ON payable_payments(deleted_at);
+CREATE INDEX idx_payable_payments_payable ON payable_payments(payable_id);
+
+CREATE TRIGGER update_payables_updated_at BEFORE UPDATE ON payables FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_payable_payments_updated_at BEFORE UPDATE ON payable_payments FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Purchase invoice lama dianggap sudah lunas saat transaksi
+INSERT INTO payables (purchase_invoice_id, amount, paid_amount, due_date, status)
+SELECT id, final_price, final_price, transaction_date, 'paid'
+FROM purchase_invoices
+WHERE deleted_at IS NULL;
+
+-- Tipe notifikasi baru untuk hutang jatuh tempo
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_type_check;
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS chk_notification_type;
+ALTER TABLE notifications
+ADD CONSTRAINT chk_notification_type
+CHECK (type IN ('work_order_assigned', 'low_stock', 'work_order_update', 'daily_report', 'payable_overdue'));
@@ -0,0 +1,46 @@
+-- Consignment (titip jual) vehicles
+
+-- Kendaraan titipan: milik pemilik, bukan stok dealer
+ALTER TABLE vehicles ADD COLUMN IF NOT EXISTS is_consignment BOOLEAN DEFAULT FALSE;
+
+-- Tabel Consignments (perjanjian titip jual)
+CREATE TABLE IF NOT EXISTS consignments (
+    id SERIAL PRIMARY KEY,
+    consignment_number VARCHAR(30) UNIQUE NOT NULL, -- CSG-20250724-0001
+    vehicle_id INTEGER UNIQUE NOT NULL,
+    owner_id INTEGER NOT NULL, -- pemilik kendaraan (customer)
+    agreed_net_price DECIMAL(15,2) NOT NULL, -- harga bersih yang diterima pemilik
+    payout_days INTEGER NOT NULL DEFAULT 7, -- jatuh tempo pembayaran ke pemilik setelah terjual
+    status VARCHAR(20) CHECK (status IN ('active', 'sold')) DEFAULT 'active',
+    intake_date DATE NOT NULL,
+    sales_invoice_id INTEGER,
+    sale_price DECIMAL(15,2),
+    commission_amount DECIMAL(15,2), -- harga jual - harga bersih pemilik
+    notes TEXT,
+    created_by INTEGER NOT NULL,
+
+    -- Soft Delete
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_by INTEGER NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (vehicle_id) REFERENCES vehicles(id),
+    FOREIGN KEY (owner_id) REFERENCES customers(id),
+    FOREIGN KEY (sales_invoice_id) REFERENCES sales_invoices(id),
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (deleted_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_consignments_deleted_at ON consignments(deleted_at);
+CREATE INDEX idx_consignments_status ON consignments(status);
+CREATE INDEX idx_consignments_owner ON consignments(owner_id);
+
+CREATE TRIGGER update_consignments_updated_at BEFORE UPDATE ON consignments FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Hutang ke pemilik kendaraan titipan setelah terjual
+ALTER TABLE payables ALTER COLUMN purchase_invoice_id DROP NOT NULL;
+ALTER TABLE payables ADD COLUMN IF NOT EXISTS consignment_id INTEGER UNIQUE REFERENCES consignments(id);
+ALTER TABLE payables ADD CONSTRAINT chk_payables_source
+CHECK ((purchase_invoice_id IS NULL) <> (consignment_id IS NULL));
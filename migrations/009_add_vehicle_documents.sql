@@ -0,0 +1,41 @@
+-- Vehicle legal documents (BPKB, STNK, faktur)
+
+-- Tabel Vehicle Documents (dokumen legal kendaraan yang disimpan dealer)
+CREATE TABLE IF NOT EXISTS vehicle_documents (
+    id SERIAL PRIMARY KEY,
+    vehicle_id INTEGER NOT NULL,
+    document_type VARCHAR(20) CHECK (document_type IN ('bpkb', 'stnk', 'faktur', 'other')) NOT NULL,
+    document_number VARCHAR(100),
+    custody_status VARCHAR(20) CHECK (custody_status IN ('pending', 'in_custody', 'handed_over')) DEFAULT 'pending',
+    custody_location VARCHAR(100), -- lemari arsip, brankas, leasing, dll
+    expiry_date DATE, -- masa berlaku STNK (5 tahunan)
+    tax_due_date DATE, -- jatuh tempo pajak tahunan STNK
+    scan_file VARCHAR(255), -- hasil scan dokumen
+    received_date DATE,
+    sales_invoice_id INTEGER, -- serah terima ke pembeli
+    handed_over_at TIMESTAMP,
+    handed_over_to VARCHAR(100),
+    handed_over_by INTEGER,
+    notes TEXT,
+    created_by INTEGER NOT NULL,
+
+    -- Soft Delete
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_by INTEGER NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (vehicle_id) REFERENCES vehicles(id),
+    FOREIGN KEY (sales_invoice_id) REFERENCES sales_invoices(id),
+    FOREIGN KEY (handed_over_by) REFERENCES users(id),
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (deleted_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_vehicle_documents_deleted_at ON vehicle_documents(deleted_at);
+CREATE INDEX idx_vehicle_documents_vehicle ON vehicle_documents(vehicle_id);
+CREATE INDEX idx_vehicle_documents_expiry_date ON vehicle_documents(expiry_date);
+CREATE INDEX idx_vehicle_documents_tax_due_date ON vehicle_documents(tax_due_date);
+
+CREATE TRIGGER update_vehicle_documents_updated_at BEFORE UPDATE ON vehicle_documents FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,31 @@
+-- Bulk vehicle purchase: satu invoice untuk banyak kendaraan
+
+-- Invoice borongan tidak terikat ke satu kendaraan
+ALTER TABLE purchase_invoices ALTER COLUMN vehicle_id DROP NOT NULL;
+ALTER TABLE purchase_invoices ADD COLUMN IF NOT EXISTS shared_cost DECIMAL(15,2) DEFAULT 0; -- biaya bersama (transport, lelang, dll)
+ALTER TABLE purchase_invoices ADD COLUMN IF NOT EXISTS allocation_method VARCHAR(20) DEFAULT 'value'
+CHECK (allocation_method IN ('value', 'equal'));
+
+-- Tabel Purchase Invoice Items (harga per kendaraan + alokasi biaya bersama)
+CREATE TABLE IF NOT EXISTS purchase_invoice_items (
+    id SERIAL PRIMARY KEY,
+    purchase_invoice_id INTEGER NOT NULL,
+    vehicle_id INTEGER NOT NULL,
+    price DECIMAL(15,2) NOT NULL, -- harga kendaraan
+    allocated_cost DECIMAL(15,2) NOT NULL DEFAULT 0, -- bagian biaya bersama
+    total_cost DECIMAL(15,2) NOT NULL, -- harga beli kendaraan (price + allocated_cost)
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (purchase_invoice_id) REFERENCES purchase_invoices(id),
+    FOREIGN KEY (vehicle_id) REFERENCES vehicles(id),
+    UNIQUE (purchase_invoice_id, vehicle_id)
+);
+
+CREATE INDEX idx_purchase_invoice_items_invoice ON purchase_invoice_items(purchase_invoice_id);
+CREATE INDEX idx_purchase_invoice_items_vehicle ON purchase_invoice_items(vehicle_id);
+
+-- Invoice lama: satu item per invoice
+INSERT INTO purchase_invoice_items (purchase_invoice_id, vehicle_id, price, allocated_cost, total_cost)
+SELECT id, vehicle_id, final_price, 0, final_price
+FROM purchase_invoices
+WHERE vehicle_id IS NOT NULL;
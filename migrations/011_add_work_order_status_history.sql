@@ -0,0 +1,24 @@
+-- Work order state machine with transition history
+
+-- Status tambahan: ditunda dan menunggu sparepart
+ALTER TABLE work_orders DROP CONSTRAINT IF EXISTS work_orders_status_check;
+ALTER TABLE work_orders DROP CONSTRAINT IF EXISTS chk_work_order_status;
+ALTER TABLE work_orders
+ADD CONSTRAINT chk_work_order_status
+CHECK (status IN ('pending', 'in_progress', 'on_hold', 'waiting_parts', 'completed', 'cancelled'));
+
+-- Tabel Work Order Status History (siapa mengubah status, kapan dan kenapa)
+CREATE TABLE IF NOT EXISTS work_order_status_history (
+    id SERIAL PRIMARY KEY,
+    work_order_id INTEGER NOT NULL,
+    from_status VARCHAR(20) NOT NULL,
+    to_status VARCHAR(20) NOT NULL,
+    reason TEXT,
+    changed_by INTEGER NOT NULL,
+    changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (work_order_id) REFERENCES work_orders(id),
+    FOREIGN KEY (changed_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_work_order_status_history_work_order ON work_order_status_history(work_order_id);
@@ -0,0 +1,44 @@
+-- Mechanic auto-assignment: skills per brand, leave and assignment reason
+
+-- Tabel Mechanic Skills (merek kendaraan yang dikuasai mekanik)
+CREATE TABLE IF NOT EXISTS mechanic_skills (
+    id SERIAL PRIMARY KEY,
+    mechanic_id INTEGER NOT NULL,
+    brand VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (mechanic_id) REFERENCES users(id),
+    UNIQUE (mechanic_id, brand)
+);
+
+CREATE INDEX idx_mechanic_skills_mechanic ON mechanic_skills(mechanic_id);
+
+-- Tabel Mechanic Leaves (cuti mekanik, tidak menerima work order baru)
+CREATE TABLE IF NOT EXISTS mechanic_leaves (
+    id SERIAL PRIMARY KEY,
+    mechanic_id INTEGER NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    reason TEXT,
+    created_by INTEGER NOT NULL,
+
+    -- Soft Delete
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_by INTEGER NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (mechanic_id) REFERENCES users(id),
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (deleted_by) REFERENCES users(id),
+    CONSTRAINT chk_mechanic_leave_dates CHECK (end_date >= start_date)
+);
+
+CREATE INDEX idx_mechanic_leaves_deleted_at ON mechanic_leaves(deleted_at);
+CREATE INDEX idx_mechanic_leaves_mechanic ON mechanic_leaves(mechanic_id);
+
+CREATE TRIGGER update_mechanic_leaves_updated_at BEFORE UPDATE ON mechanic_leaves FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Alasan penugasan mekanik (otomatis atau manual)
+ALTER TABLE work_orders ADD COLUMN IF NOT EXISTS assignment_reason TEXT;
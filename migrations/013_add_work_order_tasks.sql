@@ -0,0 +1,36 @@
+-- Work order task checklist
+
+-- Tabel Work Order Tasks (daftar pekerjaan per work order)
+CREATE TABLE IF NOT EXISTS work_order_tasks (
+    id SERIAL PRIMARY KEY,
+    work_order_id INTEGER NOT NULL,
+    title VARCHAR(200) NOT NULL,
+    estimated_hours DECIMAL(6,2) DEFAULT 0 CHECK (estimated_hours >= 0), -- juga dipakai sebagai bobot progress
+    assigned_mechanic_id INTEGER,
+    status VARCHAR(20) CHECK (status IN ('pending', 'in_progress', 'completed', 'skipped')) DEFAULT 'pending',
+    notes TEXT,
+    sort_order INTEGER DEFAULT 0,
+    completed_at TIMESTAMP,
+    completed_by INTEGER,
+    created_by INTEGER NOT NULL,
+
+    -- Soft Delete
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_by INTEGER NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (work_order_id) REFERENCES work_orders(id),
+    FOREIGN KEY (assigned_mechanic_id) REFERENCES users(id),
+    FOREIGN KEY (completed_by) REFERENCES users(id),
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (deleted_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_work_order_tasks_deleted_at ON work_order_tasks(deleted_at);
+CREATE INDEX idx_work_order_tasks_work_order ON work_order_tasks(work_order_id);
+CREATE INDEX idx_work_order_tasks_assigned_mechanic ON work_order_tasks(assigned_mechanic_id);
+CREATE INDEX idx_work_order_tasks_status ON work_order_tasks(status);
+
+CREATE TRIGGER update_work_order_tasks_updated_at BEFORE UPDATE ON work_order_tasks FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,63 @@
+-- Mechanic labor time tracking and labor rates
+
+-- Tabel Labor Rates (tarif jasa per jam per level keahlian)
+CREATE TABLE IF NOT EXISTS labor_rates (
+    id SERIAL PRIMARY KEY,
+    skill_level VARCHAR(20) UNIQUE NOT NULL,
+    hourly_rate DECIMAL(15,2) NOT NULL CHECK (hourly_rate >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_labor_rates_updated_at BEFORE UPDATE ON labor_rates FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO labor_rates (skill_level, hourly_rate) VALUES
+('junior', 50000),
+('senior', 75000),
+('master', 100000)
+ON CONFLICT (skill_level) DO NOTHING;
+
+-- Tabel Mechanic Labor Profiles (level keahlian dan tarif khusus per mekanik)
+CREATE TABLE IF NOT EXISTS mechanic_labor_profiles (
+    id SERIAL PRIMARY KEY,
+    mechanic_id INTEGER UNIQUE NOT NULL,
+    skill_level VARCHAR(20),
+    hourly_rate DECIMAL(15,2) CHECK (hourly_rate >= 0), -- mengganti tarif level keahlian
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (mechanic_id) REFERENCES users(id)
+);
+
+CREATE TRIGGER update_mechanic_labor_profiles_updated_at BEFORE UPDATE ON mechanic_labor_profiles FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Tabel Labor Time Entries (timer kerja mekanik per work order / task)
+CREATE TABLE IF NOT EXISTS labor_time_entries (
+    id SERIAL PRIMARY KEY,
+    work_order_id INTEGER NOT NULL,
+    work_order_task_id INTEGER,
+    mechanic_id INTEGER NOT NULL,
+    status VARCHAR(20) CHECK (status IN ('running', 'paused', 'stopped')) DEFAULT 'running',
+    started_at TIMESTAMP NOT NULL,
+    segment_started_at TIMESTAMP, -- awal segmen yang sedang berjalan, NULL saat pause/stop
+    ended_at TIMESTAMP,
+    worked_seconds INTEGER DEFAULT 0, -- akumulasi waktu kerja tanpa pause
+    hourly_rate DECIMAL(15,2) NOT NULL DEFAULT 0, -- tarif saat timer dimulai
+    labor_cost DECIMAL(15,2) DEFAULT 0,
+    notes TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (work_order_id) REFERENCES work_orders(id),
+    FOREIGN KEY (work_order_task_id) REFERENCES work_order_tasks(id),
+    FOREIGN KEY (mechanic_id) REFERENCES users(id)
+);
+
+CREATE INDEX idx_labor_time_entries_work_order ON labor_time_entries(work_order_id);
+CREATE INDEX idx_labor_time_entries_mechanic ON labor_time_entries(mechanic_id);
+CREATE INDEX idx_labor_time_entries_started_at ON labor_time_entries(started_at);
+
+-- Satu mekanik hanya boleh punya satu timer berjalan
+CREATE UNIQUE INDEX idx_labor_time_entries_one_running ON labor_time_entries(mechanic_id) WHERE status = 'running';
+
+CREATE TRIGGER update_labor_time_entries_updated_at BEFORE UPDATE ON labor_time_entries FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
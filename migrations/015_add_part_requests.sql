@@ -0,0 +1,42 @@
+-- Part requests from mechanics, approved by the parts counter
+
+-- Tabel Part Requests (permintaan sparepart mekanik untuk work order)
+CREATE TABLE IF NOT EXISTS part_requests (
+    id SERIAL PRIMARY KEY,
+    request_number VARCHAR(50) UNIQUE NOT NULL,
+    work_order_id INTEGER NOT NULL,
+    spare_part_id INTEGER NOT NULL,
+    quantity_requested INTEGER NOT NULL CHECK (quantity_requested > 0),
+    quantity_issued INTEGER DEFAULT 0 CHECK (quantity_issued >= 0),
+    status VARCHAR(20) CHECK (status IN ('pending', 'approved', 'partially_approved', 'rejected')) DEFAULT 'pending',
+    notes TEXT,
+    requested_by INTEGER NOT NULL,
+    reviewed_by INTEGER,
+    reviewed_at TIMESTAMP,
+    review_notes TEXT,
+
+    -- Soft Delete
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_by INTEGER NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (work_order_id) REFERENCES work_orders(id),
+    FOREIGN KEY (spare_part_id) REFERENCES spare_parts(id),
+    FOREIGN KEY (requested_by) REFERENCES users(id),
+    FOREIGN KEY (reviewed_by) REFERENCES users(id),
+    FOREIGN KEY (deleted_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_part_requests_deleted_at ON part_requests(deleted_at);
+CREATE INDEX idx_part_requests_work_order ON part_requests(work_order_id);
+CREATE INDEX idx_part_requests_status ON part_requests(status);
+
+CREATE TRIGGER update_part_requests_updated_at BEFORE UPDATE ON part_requests FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Tipe notifikasi baru untuk hasil permintaan sparepart
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS chk_notification_type;
+ALTER TABLE notifications
+ADD CONSTRAINT chk_notification_type
+CHECK (type IN ('work_order_assigned', 'low_stock', 'work_order_update', 'daily_report', 'payable_overdue', 'part_request_reviewed'));
@@ -0,0 +1,29 @@
+-- Return unused work order parts to stock
+
+-- Jumlah sparepart yang sudah dikembalikan; total_cost menjadi biaya bersih
+ALTER TABLE work_order_parts ADD COLUMN IF NOT EXISTS quantity_returned INTEGER DEFAULT 0 CHECK (quantity_returned >= 0);
+ALTER TABLE work_order_parts ADD CONSTRAINT chk_work_order_parts_returned CHECK (quantity_returned <= quantity_used);
+
+-- Tabel Work Order Part Returns (riwayat pengembalian sparepart ke stok)
+CREATE TABLE IF NOT EXISTS work_order_part_returns (
+    id SERIAL PRIMARY KEY,
+    work_order_part_id INTEGER NOT NULL,
+    work_order_id INTEGER NOT NULL,
+    spare_part_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_cost DECIMAL(12,2) NOT NULL, -- biaya satuan saat sparepart dipakai
+    total_cost DECIMAL(12,2) NOT NULL,
+    reason TEXT NOT NULL,
+    returned_by INTEGER NOT NULL,
+    returned_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (work_order_part_id) REFERENCES work_order_parts(id),
+    FOREIGN KEY (work_order_id) REFERENCES work_orders(id),
+    FOREIGN KEY (spare_part_id) REFERENCES spare_parts(id),
+    FOREIGN KEY (returned_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_work_order_part_returns_work_order ON work_order_part_returns(work_order_id);
+CREATE INDEX idx_work_order_part_returns_work_order_part ON work_order_part_returns(work_order_part_id);
@@ -0,0 +1,134 @@
+-- Customer service work orders (repairs on customer-owned vehicles) with billing
+
+-- Tabel Customer Vehicles (kendaraan milik customer yang diservis di bengkel)
+CREATE TABLE IF NOT EXISTS customer_vehicles (
+    id SERIAL PRIMARY KEY,
+    customer_id INTEGER NOT NULL,
+    plate_number VARCHAR(20) NOT NULL,
+    brand VARCHAR(50) NOT NULL,
+    model VARCHAR(100) NOT NULL,
+    year INTEGER,
+    color VARCHAR(30),
+    chassis_number VARCHAR(50),
+    engine_number VARCHAR(50),
+    notes TEXT,
+
+    -- Soft Delete
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_by INTEGER NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (customer_id) REFERENCES customers(id),
+    FOREIGN KEY (deleted_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_customer_vehicles_deleted_at ON customer_vehicles(deleted_at);
+CREATE INDEX idx_customer_vehicles_customer ON customer_vehicles(customer_id);
+CREATE INDEX idx_customer_vehicles_plate_number ON customer_vehicles(plate_number);
+
+CREATE TRIGGER update_customer_vehicles_updated_at BEFORE UPDATE ON customer_vehicles FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Work order customer: tidak memakai kendaraan stok dan tidak masuk HPP
+ALTER TABLE work_orders ADD COLUMN IF NOT EXISTS order_type VARCHAR(20) DEFAULT 'reconditioning';
+ALTER TABLE work_orders ADD COLUMN IF NOT EXISTS customer_id INTEGER REFERENCES customers(id);
+ALTER TABLE work_orders ADD COLUMN IF NOT EXISTS customer_vehicle_id INTEGER REFERENCES customer_vehicles(id);
+ALTER TABLE work_orders ADD COLUMN IF NOT EXISTS labor_price DECIMAL(15,2) DEFAULT 0; -- harga jasa ke customer
+ALTER TABLE work_orders ALTER COLUMN vehicle_id DROP NOT NULL;
+
+ALTER TABLE work_orders ADD CONSTRAINT chk_work_order_type CHECK (order_type IN ('reconditioning', 'customer_service'));
+ALTER TABLE work_orders ADD CONSTRAINT chk_work_order_subject CHECK (
+    (order_type = 'reconditioning' AND vehicle_id IS NOT NULL)
+    OR (order_type = 'customer_service' AND customer_id IS NOT NULL AND customer_vehicle_id IS NOT NULL)
+);
+
+CREATE INDEX idx_work_orders_order_type ON work_orders(order_type);
+CREATE INDEX idx_work_orders_customer ON work_orders(customer_id);
+
+-- Harga jual sparepart saat dipakai (untuk ditagihkan ke customer)
+ALTER TABLE work_order_parts ADD COLUMN IF NOT EXISTS unit_price DECIMAL(12,2) DEFAULT 0;
+
+-- Tabel Service Invoices (tagihan servis customer, satu per work order)
+CREATE TABLE IF NOT EXISTS service_invoices (
+    id SERIAL PRIMARY KEY,
+    invoice_number VARCHAR(50) UNIQUE NOT NULL, -- SVC-20250724-0001
+    work_order_id INTEGER UNIQUE NOT NULL,
+    customer_id INTEGER NOT NULL,
+    invoice_date DATE NOT NULL,
+    due_date DATE NOT NULL,
+    parts_amount DECIMAL(15,2) NOT NULL DEFAULT 0,
+    labor_amount DECIMAL(15,2) NOT NULL DEFAULT 0,
+    discount_amount DECIMAL(15,2) NOT NULL DEFAULT 0,
+    total_amount DECIMAL(15,2) NOT NULL,
+    paid_amount DECIMAL(15,2) NOT NULL DEFAULT 0,
+    status VARCHAR(20) CHECK (status IN ('unpaid', 'partially_paid', 'paid')) DEFAULT 'unpaid',
+    notes TEXT,
+    created_by INTEGER NOT NULL,
+
+    -- Soft Delete
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_by INTEGER NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (work_order_id) REFERENCES work_orders(id),
+    FOREIGN KEY (customer_id) REFERENCES customers(id),
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (deleted_by) REFERENCES users(id),
+    CHECK (paid_amount <= total_amount)
+);
+
+CREATE INDEX idx_service_invoices_deleted_at ON service_invoices(deleted_at);
+CREATE INDEX idx_service_invoices_customer ON service_invoices(customer_id);
+CREATE INDEX idx_service_invoices_status ON service_invoices(status);
+
+-- Tabel Service Invoice Items (baris sparepart dan jasa)
+CREATE TABLE IF NOT EXISTS service_invoice_items (
+    id SERIAL PRIMARY KEY,
+    service_invoice_id INTEGER NOT NULL,
+    item_type VARCHAR(20) CHECK (item_type IN ('part', 'labor')) NOT NULL,
+    spare_part_id INTEGER,
+    description VARCHAR(200) NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price DECIMAL(15,2) NOT NULL,
+    total_price DECIMAL(15,2) NOT NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (service_invoice_id) REFERENCES service_invoices(id),
+    FOREIGN KEY (spare_part_id) REFERENCES spare_parts(id)
+);
+
+CREATE INDEX idx_service_invoice_items_invoice ON service_invoice_items(service_invoice_id);
+
+-- Tabel Service Invoice Payments (pembayaran customer, bisa dicicil)
+CREATE TABLE IF NOT EXISTS service_invoice_payments (
+    id SERIAL PRIMARY KEY,
+    payment_number VARCHAR(30) UNIQUE NOT NULL, -- SVP-20250724-0001
+    service_invoice_id INTEGER NOT NULL,
+    amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+    payment_date DATE NOT NULL,
+    payment_method VARCHAR(20) CHECK (payment_method IN ('cash', 'transfer')) NOT NULL,
+    transfer_proof VARCHAR(255),
+    notes TEXT,
+    created_by INTEGER NOT NULL,
+
+    -- Soft Delete
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_by INTEGER NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (service_invoice_id) REFERENCES service_invoices(id),
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (deleted_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_service_invoice_payments_deleted_at ON service_invoice_payments(deleted_at);
+CREATE INDEX idx_service_invoice_payments_invoice ON service_invoice_payments(service_invoice_id);
+
+CREATE TRIGGER update_service_invoices_updated_at BEFORE UPDATE ON service_invoices FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_service_invoice_payments_updated_at BEFORE UPDATE ON service_invoice_payments FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
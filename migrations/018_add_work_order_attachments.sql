@@ -0,0 +1,28 @@
+-- Photo and document attachments on work orders and tasks
+
+-- Tabel Work Order Attachments (foto/dokumen sebelum, selama, dan sesudah pengerjaan)
+CREATE TABLE IF NOT EXISTS work_order_attachments (
+    id SERIAL PRIMARY KEY,
+    work_order_id INTEGER NOT NULL,
+    work_order_task_id INTEGER, -- NULL = lampiran untuk work order secara umum
+    stage VARCHAR(20) CHECK (stage IN ('before', 'during', 'after')) NOT NULL,
+    file_type VARCHAR(20) CHECK (file_type IN ('photo', 'document')) NOT NULL,
+    file_path VARCHAR(255) NOT NULL,
+    caption TEXT,
+    uploaded_by INTEGER NOT NULL,
+
+    -- Soft Delete
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_by INTEGER NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (work_order_id) REFERENCES work_orders(id),
+    FOREIGN KEY (work_order_task_id) REFERENCES work_order_tasks(id),
+    FOREIGN KEY (uploaded_by) REFERENCES users(id),
+    FOREIGN KEY (deleted_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_work_order_attachments_deleted_at ON work_order_attachments(deleted_at);
+CREATE INDEX idx_work_order_attachments_work_order ON work_order_attachments(work_order_id);
+CREATE INDEX idx_work_order_attachments_task ON work_order_attachments(work_order_task_id);
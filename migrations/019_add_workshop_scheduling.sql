@@ -0,0 +1,87 @@
+-- Workshop scheduling: bays, mechanic working hours, planned work and estimated completion
+
+-- Tabel Workshop Bays (tempat/lift pengerjaan kendaraan)
+CREATE TABLE IF NOT EXISTS workshop_bays (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(20) UNIQUE NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    is_active BOOLEAN DEFAULT TRUE,
+    notes TEXT,
+
+    -- Soft Delete
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_by INTEGER NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (deleted_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_workshop_bays_deleted_at ON workshop_bays(deleted_at);
+
+CREATE TRIGGER update_workshop_bays_updated_at BEFORE UPDATE ON workshop_bays FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Tabel Mechanic Working Hours (jam kerja mekanik per hari; tanpa baris = jam bengkel default)
+CREATE TABLE IF NOT EXISTS mechanic_working_hours (
+    id SERIAL PRIMARY KEY,
+    mechanic_id INTEGER NOT NULL,
+    day_of_week INTEGER NOT NULL CHECK (day_of_week BETWEEN 0 AND 6), -- 0 = Minggu
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (mechanic_id) REFERENCES users(id),
+    UNIQUE (mechanic_id, day_of_week),
+    CONSTRAINT chk_mechanic_working_hours CHECK (end_time > start_time)
+);
+
+CREATE INDEX idx_mechanic_working_hours_mechanic ON mechanic_working_hours(mechanic_id);
+
+-- Tabel Work Order Schedules (rencana pengerjaan per work order atau per task)
+CREATE TABLE IF NOT EXISTS work_order_schedules (
+    id SERIAL PRIMARY KEY,
+    work_order_id INTEGER NOT NULL,
+    work_order_task_id INTEGER, -- NULL = seluruh work order
+    bay_id INTEGER,
+    mechanic_id INTEGER,
+    planned_start TIMESTAMP NOT NULL,
+    planned_end TIMESTAMP NOT NULL,
+    notes TEXT,
+    created_by INTEGER NOT NULL,
+
+    -- Soft Delete
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_by INTEGER NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (work_order_id) REFERENCES work_orders(id),
+    FOREIGN KEY (work_order_task_id) REFERENCES work_order_tasks(id),
+    FOREIGN KEY (bay_id) REFERENCES workshop_bays(id),
+    FOREIGN KEY (mechanic_id) REFERENCES users(id),
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (deleted_by) REFERENCES users(id),
+    CONSTRAINT chk_work_order_schedule_dates CHECK (planned_end > planned_start)
+);
+
+CREATE INDEX idx_work_order_schedules_deleted_at ON work_order_schedules(deleted_at);
+CREATE INDEX idx_work_order_schedules_work_order ON work_order_schedules(work_order_id);
+CREATE INDEX idx_work_order_schedules_bay ON work_order_schedules(bay_id);
+CREATE INDEX idx_work_order_schedules_mechanic ON work_order_schedules(mechanic_id);
+CREATE INDEX idx_work_order_schedules_planned ON work_order_schedules(planned_start, planned_end);
+
+CREATE TRIGGER update_work_order_schedules_updated_at BEFORE UPDATE ON work_order_schedules FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Perkiraan selesai, dihitung ulang dari jadwal saat pengerjaan terlambat
+ALTER TABLE work_orders ADD COLUMN IF NOT EXISTS estimated_completion_at TIMESTAMP;
+
+-- Data awal: empat bay bengkel
+INSERT INTO workshop_bays (code, name) VALUES
+('BAY-1', 'Bay 1'),
+('BAY-2', 'Bay 2'),
+('BAY-3', 'Bay 3'),
+('BAY-4', 'Bay 4')
+ON CONFLICT (code) DO NOTHING;
@@ -0,0 +1,45 @@
+-- Per-vehicle cost ledger: landed costs outside work orders, rolled up into HPP
+
+-- Tabel Vehicle Costs (biaya tambahan kendaraan di luar harga beli dan work order)
+CREATE TABLE IF NOT EXISTS vehicle_costs (
+    id SERIAL PRIMARY KEY,
+    vehicle_id INTEGER NOT NULL,
+    cost_type VARCHAR(30) CHECK (cost_type IN ('transport', 'document_fee', 'registration_fee', 'broker_fee', 'sublet', 'other')) NOT NULL,
+    amount DECIMAL(15,2) NOT NULL CHECK (amount >= 0),
+    cost_date DATE NOT NULL DEFAULT CURRENT_DATE,
+    description TEXT,
+    reference VARCHAR(100), -- nomor kwitansi/nota vendor
+    created_by INTEGER, -- NULL = dicatat sistem (saldo awal)
+
+    -- Soft Delete
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_by INTEGER NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (vehicle_id) REFERENCES vehicles(id),
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (deleted_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_vehicle_costs_deleted_at ON vehicle_costs(deleted_at);
+CREATE INDEX idx_vehicle_costs_vehicle ON vehicle_costs(vehicle_id);
+
+CREATE TRIGGER update_vehicle_costs_updated_at BEFORE UPDATE ON vehicle_costs FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Index untuk menjumlahkan biaya work order per kendaraan
+CREATE INDEX idx_work_orders_vehicle_status ON work_orders(vehicle_id, status);
+
+-- Biaya perbaikan lama yang tidak berasal dari work order dipindahkan ke ledger
+-- supaya HPP tidak berubah saat dihitung ulang
+INSERT INTO vehicle_costs (vehicle_id, cost_type, amount, description)
+SELECT v.id, 'other', v.repair_cost - COALESCE(wo.total, 0), 'Saldo biaya perbaikan sebelum ledger biaya'
+FROM vehicles v
+LEFT JOIN (
+    SELECT vehicle_id, SUM(total_cost) as total
+    FROM work_orders
+    WHERE status = 'completed' AND deleted_at IS NULL AND NOT COALESCE(is_warranty, FALSE) AND order_type = 'reconditioning'
+    GROUP BY vehicle_id
+) wo ON wo.vehicle_id = v.id
+WHERE v.deleted_at IS NULL AND v.repair_cost > COALESCE(wo.total, 0);
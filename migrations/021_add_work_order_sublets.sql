@@ -0,0 +1,46 @@
+-- Sublet (outsourced) repairs on work orders, billed by outside vendors
+
+-- Tabel Work Order Sublets (pekerjaan yang dikirim ke bengkel luar, mis. body paint, jok)
+CREATE TABLE IF NOT EXISTS work_order_sublets (
+    id SERIAL PRIMARY KEY,
+    work_order_id INTEGER NOT NULL,
+    supplier_id INTEGER NOT NULL,
+    description TEXT NOT NULL,
+    vendor_invoice_number VARCHAR(100),
+    cost DECIMAL(15,2) NOT NULL CHECK (cost >= 0),
+    status VARCHAR(20) CHECK (status IN ('sent', 'completed', 'cancelled')) NOT NULL DEFAULT 'sent',
+    attachment_path VARCHAR(255), -- scan nota/invoice vendor
+    notes TEXT,
+    created_by INTEGER NOT NULL,
+    completed_at TIMESTAMP NULL,
+    completed_by INTEGER NULL,
+
+    -- Soft Delete
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_by INTEGER NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (work_order_id) REFERENCES work_orders(id),
+    FOREIGN KEY (supplier_id) REFERENCES suppliers(id),
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (completed_by) REFERENCES users(id),
+    FOREIGN KEY (deleted_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_work_order_sublets_deleted_at ON work_order_sublets(deleted_at);
+CREATE INDEX idx_work_order_sublets_work_order ON work_order_sublets(work_order_id);
+CREATE INDEX idx_work_order_sublets_supplier ON work_order_sublets(supplier_id);
+CREATE INDEX idx_work_order_sublets_status ON work_order_sublets(status);
+
+CREATE TRIGGER update_work_order_sublets_updated_at BEFORE UPDATE ON work_order_sublets FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Biaya sublet masuk ke total biaya work order
+ALTER TABLE work_orders ADD COLUMN IF NOT EXISTS sublet_cost DECIMAL(15,2) DEFAULT 0;
+
+-- Hutang ke vendor sublet setelah invoice vendor diterima
+ALTER TABLE payables ADD COLUMN IF NOT EXISTS work_order_sublet_id INTEGER UNIQUE REFERENCES work_order_sublets(id);
+ALTER TABLE payables DROP CONSTRAINT IF EXISTS chk_payables_source;
+ALTER TABLE payables ADD CONSTRAINT chk_payables_source
+CHECK (num_nonnulls(purchase_invoice_id, consignment_id, work_order_sublet_id) = 1);
@@ -0,0 +1,70 @@
+-- Stock opname (physical count) sessions with variance posting
+
+-- Tabel Stock Counts (sesi stock opname)
+CREATE TABLE IF NOT EXISTS stock_counts (
+    id SERIAL PRIMARY KEY,
+    count_number VARCHAR(50) UNIQUE NOT NULL,
+    status VARCHAR(20) CHECK (status IN ('open', 'submitted', 'approved', 'cancelled')) NOT NULL DEFAULT 'open',
+    location VARCHAR(100), -- rak/area yang dihitung, kosong = seluruh gudang
+    category VARCHAR(50), -- hitung sebagian: hanya kategori ini
+    notes TEXT,
+    created_by INTEGER NOT NULL,
+    submitted_by INTEGER NULL,
+    submitted_at TIMESTAMP NULL,
+    approved_by INTEGER NULL,
+    approved_at TIMESTAMP NULL,
+
+    -- Soft Delete
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_by INTEGER NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (submitted_by) REFERENCES users(id),
+    FOREIGN KEY (approved_by) REFERENCES users(id),
+    FOREIGN KEY (deleted_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_stock_counts_deleted_at ON stock_counts(deleted_at);
+CREATE INDEX idx_stock_counts_status ON stock_counts(status);
+
+CREATE TRIGGER update_stock_counts_updated_at BEFORE UPDATE ON stock_counts FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Tabel Stock Count Items (snapshot stok sistem + hasil hitung fisik per sparepart)
+CREATE TABLE IF NOT EXISTS stock_count_items (
+    id SERIAL PRIMARY KEY,
+    stock_count_id INTEGER NOT NULL,
+    spare_part_id INTEGER NOT NULL,
+    expected_quantity INTEGER NOT NULL, -- stok sistem saat sesi dibuka
+    unit_cost DECIMAL(12,2) NOT NULL, -- cost price saat sesi dibuka, untuk nilai selisih
+    counted_quantity INTEGER NULL CHECK (counted_quantity >= 0), -- NULL = belum dihitung
+    counted_by INTEGER NULL,
+    counted_at TIMESTAMP NULL,
+    posted_value DECIMAL(15,2) NULL, -- nilai selisih yang diposting saat approval
+    notes TEXT,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (stock_count_id) REFERENCES stock_counts(id),
+    FOREIGN KEY (spare_part_id) REFERENCES spare_parts(id),
+    FOREIGN KEY (counted_by) REFERENCES users(id),
+    UNIQUE (stock_count_id, spare_part_id)
+);
+
+CREATE INDEX idx_stock_count_items_stock_count ON stock_count_items(stock_count_id);
+
+CREATE TRIGGER update_stock_count_items_updated_at BEFORE UPDATE ON stock_count_items FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Penyesuaian hasil opname dicatat dengan referensi ke sesi stock count
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_reference_type_check;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_reference_type_check
+CHECK (reference_type IN ('work_order', 'purchase', 'adjustment', 'stock_count'));
+
+ALTER TABLE stock_cost_layers DROP CONSTRAINT IF EXISTS stock_cost_layers_reference_type_check;
+ALTER TABLE stock_cost_layers ADD CONSTRAINT stock_cost_layers_reference_type_check
+CHECK (reference_type IN ('work_order', 'purchase', 'adjustment', 'stock_count'));
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_reference ON stock_movements(reference_type, reference_id);
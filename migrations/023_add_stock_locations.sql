@@ -0,0 +1,128 @@
+-- Multi-location inventory: stock per location and bin, transfers between locations
+
+-- Tabel Stock Locations (gudang utama, lantai bengkel, cabang, dll)
+CREATE TABLE IF NOT EXISTS stock_locations (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(20) UNIQUE NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    location_type VARCHAR(20) CHECK (location_type IN ('warehouse', 'workshop', 'branch', 'transit')) NOT NULL DEFAULT 'warehouse',
+    is_default BOOLEAN NOT NULL DEFAULT FALSE, -- lokasi untuk transaksi tanpa lokasi eksplisit
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    notes TEXT,
+
+    -- Soft Delete
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_by INTEGER NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (deleted_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_stock_locations_deleted_at ON stock_locations(deleted_at);
+CREATE UNIQUE INDEX idx_stock_locations_default ON stock_locations(is_default) WHERE is_default AND deleted_at IS NULL;
+
+CREATE TRIGGER update_stock_locations_updated_at BEFORE UPDATE ON stock_locations FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Lokasi awal: gudang utama (default) dan lokasi transit untuk barang yang sedang ditransfer
+INSERT INTO stock_locations (code, name, location_type, is_default) VALUES
+('MAIN', 'Gudang Utama', 'warehouse', TRUE),
+('TRANSIT', 'Dalam Perjalanan', 'transit', FALSE)
+ON CONFLICT (code) DO NOTHING;
+
+-- Tabel Spare Part Stocks (stok per sparepart per lokasi; spare_parts.stock_quantity = total semua lokasi)
+CREATE TABLE IF NOT EXISTS spare_part_stocks (
+    id SERIAL PRIMARY KEY,
+    spare_part_id INTEGER NOT NULL,
+    location_id INTEGER NOT NULL,
+    bin VARCHAR(50), -- rak/bin di lokasi
+    quantity INTEGER NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    min_stock_level INTEGER NULL CHECK (min_stock_level >= 0), -- batas low stock per lokasi, NULL = tidak dipantau
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (spare_part_id) REFERENCES spare_parts(id),
+    FOREIGN KEY (location_id) REFERENCES stock_locations(id),
+    UNIQUE (spare_part_id, location_id)
+);
+
+CREATE INDEX idx_spare_part_stocks_location ON spare_part_stocks(location_id);
+
+CREATE TRIGGER update_spare_part_stocks_updated_at BEFORE UPDATE ON spare_part_stocks FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Stok yang sudah ada dipindahkan ke gudang utama
+INSERT INTO spare_part_stocks (spare_part_id, location_id, quantity, min_stock_level)
+SELECT sp.id, sl.id, GREATEST(sp.stock_quantity, 0), sp.min_stock_level
+FROM spare_parts sp
+JOIN stock_locations sl ON sl.code = 'MAIN'
+WHERE sp.deleted_at IS NULL
+ON CONFLICT (spare_part_id, location_id) DO NOTHING;
+
+-- Tabel Stock Transfers (dokumen transfer stok antar lokasi)
+CREATE TABLE IF NOT EXISTS stock_transfers (
+    id SERIAL PRIMARY KEY,
+    transfer_number VARCHAR(50) UNIQUE NOT NULL,
+    from_location_id INTEGER NOT NULL,
+    to_location_id INTEGER NOT NULL,
+    status VARCHAR(20) CHECK (status IN ('draft', 'in_transit', 'received', 'cancelled')) NOT NULL DEFAULT 'draft',
+    notes TEXT,
+    created_by INTEGER NOT NULL,
+    shipped_by INTEGER NULL,
+    shipped_at TIMESTAMP NULL,
+    received_by INTEGER NULL,
+    received_at TIMESTAMP NULL,
+
+    -- Soft Delete
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_by INTEGER NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (from_location_id) REFERENCES stock_locations(id),
+    FOREIGN KEY (to_location_id) REFERENCES stock_locations(id),
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (shipped_by) REFERENCES users(id),
+    FOREIGN KEY (received_by) REFERENCES users(id),
+    FOREIGN KEY (deleted_by) REFERENCES users(id),
+    CHECK (from_location_id <> to_location_id)
+);
+
+CREATE INDEX idx_stock_transfers_deleted_at ON stock_transfers(deleted_at);
+CREATE INDEX idx_stock_transfers_status ON stock_transfers(status);
+
+CREATE TRIGGER update_stock_transfers_updated_at BEFORE UPDATE ON stock_transfers FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Tabel Stock Transfer Items
+CREATE TABLE IF NOT EXISTS stock_transfer_items (
+    id SERIAL PRIMARY KEY,
+    stock_transfer_id INTEGER NOT NULL,
+    spare_part_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (stock_transfer_id) REFERENCES stock_transfers(id),
+    FOREIGN KEY (spare_part_id) REFERENCES spare_parts(id),
+    UNIQUE (stock_transfer_id, spare_part_id)
+);
+
+CREATE INDEX idx_stock_transfer_items_transfer ON stock_transfer_items(stock_transfer_id);
+
+-- Lokasi asal/tujuan pada transaksi stok (NULL = lokasi default)
+ALTER TABLE work_order_parts ADD COLUMN IF NOT EXISTS location_id INTEGER REFERENCES stock_locations(id);
+ALTER TABLE goods_receipts ADD COLUMN IF NOT EXISTS location_id INTEGER REFERENCES stock_locations(id);
+ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS location_id INTEGER REFERENCES stock_locations(id);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_location ON stock_movements(location_id);
+
+-- Stock opname dilakukan per lokasi
+ALTER TABLE stock_counts ADD COLUMN IF NOT EXISTS location_id INTEGER REFERENCES stock_locations(id);
+UPDATE stock_counts SET location_id = (SELECT id FROM stock_locations WHERE code = 'MAIN') WHERE location_id IS NULL;
+ALTER TABLE stock_counts ALTER COLUMN location_id SET NOT NULL;
+
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_reference_type_check;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_reference_type_check
+CHECK (reference_type IN ('work_order', 'purchase', 'adjustment', 'stock_count', 'transfer'));
@@ -0,0 +1,22 @@
+-- Replenishment: reorder point dan jumlah order dari rata-rata pemakaian harian
+
+-- Lead time supplier (hari dari PO dikirim sampai barang diterima)
+ALTER TABLE suppliers ADD COLUMN IF NOT EXISTS lead_time_days INTEGER NOT NULL DEFAULT 7 CHECK (lead_time_days >= 0);
+
+-- Pengaturan replenishment per sparepart
+ALTER TABLE spare_parts ADD COLUMN IF NOT EXISTS preferred_supplier_id INTEGER REFERENCES suppliers(id);
+ALTER TABLE spare_parts ADD COLUMN IF NOT EXISTS safety_stock_days INTEGER NULL CHECK (safety_stock_days >= 0); -- NULL = pakai setting global
+ALTER TABLE spare_parts ADD COLUMN IF NOT EXISTS auto_reorder BOOLEAN NOT NULL DEFAULT TRUE; -- FALSE = tidak pernah dibuatkan draft PO
+
+CREATE INDEX IF NOT EXISTS idx_spare_parts_preferred_supplier ON spare_parts(preferred_supplier_id);
+
+-- PO yang dibuat otomatis dari saran reorder
+ALTER TABLE purchase_orders ADD COLUMN IF NOT EXISTS auto_generated BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Index untuk menghitung pemakaian per periode
+CREATE INDEX IF NOT EXISTS idx_work_order_parts_usage ON work_order_parts(spare_part_id, usage_date);
+
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS chk_notification_type;
+ALTER TABLE notifications
+ADD CONSTRAINT chk_notification_type
+CHECK (type IN ('work_order_assigned', 'low_stock', 'work_order_update', 'daily_report', 'payable_overdue', 'part_request_reviewed', 'reorder_draft'));
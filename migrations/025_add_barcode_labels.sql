@@ -0,0 +1,6 @@
+-- Barcode sparepart: satu barcode hanya boleh dipakai satu sparepart aktif
+-- (barcode dibuat otomatis sebagai EAN-13 dengan prefix toko atau Code128 dari kode part)
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_spare_parts_barcode_unique
+ON spare_parts(barcode)
+WHERE barcode IS NOT NULL AND barcode <> '' AND deleted_at IS NULL;
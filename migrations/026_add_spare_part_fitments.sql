@@ -0,0 +1,36 @@
+-- Fitment sparepart: kendaraan yang cocok untuk tiap sparepart
+
+-- Tabel Spare Part Fitments (merk, model, rentang tahun dan kode mesin)
+-- brand NULL = cocok untuk semua kendaraan (oli, busi umum, dll)
+-- model NULL = semua model dari merk tsb, year_from/year_to NULL = tanpa batas tahun
+CREATE TABLE IF NOT EXISTS spare_part_fitments (
+    id SERIAL PRIMARY KEY,
+    spare_part_id INTEGER NOT NULL,
+    brand VARCHAR(50),
+    model VARCHAR(50),
+    year_from INTEGER,
+    year_to INTEGER,
+    engine_code VARCHAR(30), -- awalan nomor mesin, mis. 'K15B' atau '1NR'
+    notes TEXT,
+    created_by INTEGER NOT NULL,
+
+    -- Soft Delete
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_by INTEGER NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (spare_part_id) REFERENCES spare_parts(id),
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (deleted_by) REFERENCES users(id),
+
+    CONSTRAINT chk_fitment_model_brand CHECK (model IS NULL OR brand IS NOT NULL),
+    CONSTRAINT chk_fitment_years CHECK (year_from IS NULL OR year_to IS NULL OR year_from <= year_to)
+);
+
+CREATE INDEX idx_spare_part_fitments_spare_part ON spare_part_fitments(spare_part_id);
+CREATE INDEX idx_spare_part_fitments_vehicle ON spare_part_fitments(LOWER(brand), LOWER(model));
+CREATE INDEX idx_spare_part_fitments_deleted_at ON spare_part_fitments(deleted_at);
+
+CREATE TRIGGER update_spare_part_fitments_updated_at BEFORE UPDATE ON spare_part_fitments FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,45 @@
+-- Paket servis: kumpulan sparepart (dan jasa standar) untuk pekerjaan rutin seperti tune-up atau paket rem
+
+-- Tabel Service Kits
+CREATE TABLE IF NOT EXISTS service_kits (
+    id SERIAL PRIMARY KEY,
+    kit_code VARCHAR(20) UNIQUE NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    labor_hours DECIMAL(6,2) NOT NULL DEFAULT 0 CHECK (labor_hours >= 0), -- estimasi jam kerja, dibuat sebagai task di work order
+    labor_price DECIMAL(15,2) NOT NULL DEFAULT 0 CHECK (labor_price >= 0), -- jasa standar yang ditagihkan ke customer
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by INTEGER NOT NULL,
+
+    -- Soft Delete
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    deleted_by INTEGER NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (deleted_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_service_kits_deleted_at ON service_kits(deleted_at);
+
+CREATE TRIGGER update_service_kits_updated_at BEFORE UPDATE ON service_kits FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Tabel Service Kit Items (sparepart dan jumlah per paket)
+CREATE TABLE IF NOT EXISTS service_kit_items (
+    id SERIAL PRIMARY KEY,
+    service_kit_id INTEGER NOT NULL,
+    spare_part_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+
+    FOREIGN KEY (service_kit_id) REFERENCES service_kits(id) ON DELETE CASCADE,
+    FOREIGN KEY (spare_part_id) REFERENCES spare_parts(id),
+
+    UNIQUE (service_kit_id, spare_part_id)
+);
+
+CREATE INDEX idx_service_kit_items_spare_part ON service_kit_items(spare_part_id);
+
+-- Pemakaian sparepart yang berasal dari paket servis
+ALTER TABLE work_order_parts ADD COLUMN IF NOT EXISTS service_kit_id INTEGER REFERENCES service_kits(id);
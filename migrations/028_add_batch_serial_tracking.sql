@@ -0,0 +1,81 @@
+-- Pelacakan batch/lot dengan tanggal kedaluwarsa (FEFO) dan nomor seri sparepart
+
+-- Sparepart yang dilacak per batch (oli, coolant, aki) dan/atau per nomor seri (aki, komponen bergaransi)
+ALTER TABLE spare_parts ADD COLUMN IF NOT EXISTS track_batches BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE spare_parts ADD COLUMN IF NOT EXISTS track_serials BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Tabel Spare Part Batches (sisa stok per batch per lokasi; jumlah sisa = stok lokasi untuk sparepart yang dilacak)
+CREATE TABLE IF NOT EXISTS spare_part_batches (
+    id SERIAL PRIMARY KEY,
+    spare_part_id INTEGER NOT NULL,
+    location_id INTEGER NOT NULL,
+    batch_number VARCHAR(50) NOT NULL,
+    expiry_date DATE NULL, -- NULL = tidak kedaluwarsa, diambil paling akhir
+    received_date TIMESTAMP NOT NULL,
+    quantity_received INTEGER NOT NULL CHECK (quantity_received > 0),
+    quantity_remaining INTEGER NOT NULL CHECK (quantity_remaining >= 0),
+    reference_type VARCHAR(20) CHECK (reference_type IN ('work_order', 'purchase', 'adjustment', 'stock_count', 'transfer')) NOT NULL,
+    reference_id INTEGER NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (spare_part_id) REFERENCES spare_parts(id),
+    FOREIGN KEY (location_id) REFERENCES stock_locations(id),
+    CHECK (quantity_remaining <= quantity_received)
+);
+
+CREATE INDEX idx_spare_part_batches_fefo ON spare_part_batches(spare_part_id, location_id, expiry_date) WHERE quantity_remaining > 0;
+CREATE INDEX idx_spare_part_batches_expiry ON spare_part_batches(expiry_date) WHERE quantity_remaining > 0;
+
+CREATE TRIGGER update_spare_part_batches_updated_at BEFORE UPDATE ON spare_part_batches FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Batch yang diambil untuk setiap pemakaian sparepart di work order
+CREATE TABLE IF NOT EXISTS work_order_part_batches (
+    id SERIAL PRIMARY KEY,
+    work_order_part_id INTEGER NOT NULL,
+    batch_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    quantity_returned INTEGER NOT NULL DEFAULT 0 CHECK (quantity_returned >= 0),
+
+    FOREIGN KEY (work_order_part_id) REFERENCES work_order_parts(id),
+    FOREIGN KEY (batch_id) REFERENCES spare_part_batches(id),
+    CHECK (quantity_returned <= quantity)
+);
+
+CREATE INDEX idx_work_order_part_batches_part ON work_order_part_batches(work_order_part_id);
+
+-- Tabel Spare Part Serials (nomor seri dicatat saat penerimaan dan saat dipakai di work order)
+CREATE TABLE IF NOT EXISTS spare_part_serials (
+    id SERIAL PRIMARY KEY,
+    spare_part_id INTEGER NOT NULL,
+    serial_number VARCHAR(100) NOT NULL,
+    status VARCHAR(20) CHECK (status IN ('in_stock', 'issued')) NOT NULL DEFAULT 'in_stock',
+    reference_type VARCHAR(20) CHECK (reference_type IN ('work_order', 'purchase', 'adjustment', 'stock_count', 'transfer')) NOT NULL, -- asal penerimaan
+    reference_id INTEGER NULL,
+    received_date TIMESTAMP NOT NULL,
+    work_order_part_id INTEGER NULL, -- pemakaian terakhir; kendaraan diambil dari work order
+    issued_at TIMESTAMP NULL,
+
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (spare_part_id) REFERENCES spare_parts(id),
+    FOREIGN KEY (work_order_part_id) REFERENCES work_order_parts(id),
+    UNIQUE (spare_part_id, serial_number)
+);
+
+CREATE INDEX idx_spare_part_serials_serial_number ON spare_part_serials(serial_number);
+CREATE INDEX idx_spare_part_serials_work_order_part ON spare_part_serials(work_order_part_id);
+
+CREATE TRIGGER update_spare_part_serials_updated_at BEFORE UPDATE ON spare_part_serials FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Batch dan tanggal kedaluwarsa yang diterima per baris goods receipt
+ALTER TABLE goods_receipt_items ADD COLUMN IF NOT EXISTS batch_number VARCHAR(50);
+ALTER TABLE goods_receipt_items ADD COLUMN IF NOT EXISTS expiry_date DATE;
+
+-- Notifikasi stok yang akan kedaluwarsa
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS chk_notification_type;
+ALTER TABLE notifications
+ADD CONSTRAINT chk_notification_type
+CHECK (type IN ('work_order_assigned', 'low_stock', 'work_order_update', 'daily_report', 'payable_overdue', 'part_request_reviewed', 'reorder_draft', 'expiring_stock'));
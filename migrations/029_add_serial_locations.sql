@@ -0,0 +1,21 @@
+-- Lokasi stok per nomor seri, supaya nomor seri yang tersedia selalu sesuai dengan stok per lokasi
+
+-- Lokasi unit yang masih di stok; NULL untuk unit yang sudah dipakai di work order
+ALTER TABLE spare_part_serials ADD COLUMN IF NOT EXISTS location_id INTEGER NULL REFERENCES stock_locations(id);
+
+-- Nomor seri yang sudah ada dianggap berada di lokasi default
+UPDATE spare_part_serials SET location_id = (
+    SELECT id FROM stock_locations WHERE is_default AND deleted_at IS NULL LIMIT 1
+)
+WHERE status = 'in_stock' AND location_id IS NULL;
+
+CREATE INDEX idx_spare_part_serials_location ON spare_part_serials(spare_part_id, location_id) WHERE status = 'in_stock';
+
+-- Unit yang dikeluarkan lewat penyesuaian stok (hilang/rusak) tidak lagi di stok
+ALTER TABLE spare_part_serials DROP CONSTRAINT IF EXISTS spare_part_serials_status_check;
+ALTER TABLE spare_part_serials
+ADD CONSTRAINT spare_part_serials_status_check
+CHECK (status IN ('in_stock', 'issued', 'written_off'));
+
+-- Nomor seri yang dipindahkan per baris transfer stok
+ALTER TABLE stock_transfer_items ADD COLUMN IF NOT EXISTS serial_numbers TEXT[] NOT NULL DEFAULT '{}';